@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+)
+
+// historyBackend answers slash commands from the snapshot repository. Like
+// the rest of the API, it reads snapshots from their commits rather than
+// checking them out.
+type historyBackend struct {
+	cfg   *config.Config
+	scope string
+}
+
+func (b historyBackend) versioner() (*versioner.Versioner, error) {
+	ver, err := versioner.New(b.cfg.Snapshot.OutputDir, &b.cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	return ver, nil
+}
+
+// Drift compares the last snapshot taken at least since ago (or the
+// oldest, if history is shorter) with the latest, within a namespace.
+func (b historyBackend) Drift(ctx context.Context, namespace string, since time.Duration) (*types.DriftReport, error) {
+	ver, err := b.versioner()
+	if err != nil {
+		return nil, err
+	}
+	history, err := ver.HistoryIn(b.scope, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w yet", types.ErrNoSnapshots)
+	}
+
+	latest := history[0]
+	base := history[len(history)-1]
+	cutoff := time.Now().Add(-since)
+	for _, entry := range history {
+		if !entry.Timestamp.After(cutoff) {
+			base = entry
+			break
+		}
+	}
+
+	baseSnapshot, err := timetravel.ReadCommit(ctx, ver, base, b.scope, nil)
+	if err != nil {
+		return nil, err
+	}
+	targetSnapshot, err := timetravel.ReadCommit(ctx, ver, latest, b.scope, nil)
+	if err != nil {
+		return nil, err
+	}
+	keepNamespace(baseSnapshot, namespace)
+	keepNamespace(targetSnapshot, namespace)
+
+	report, err := compareSnapshots(ctx, b.cfg, baseSnapshot, targetSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	report.Timestamp = latest.Timestamp
+	report.BaseRef = base.CommitHash
+	report.TargetRef = latest.CommitHash
+	linkReport(b.cfg, report)
+	if err := annotateReport(b.cfg, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Resource finds a resource in the latest snapshot, or the snapshot at a
+// time. Kinds match case-insensitively, as they are typed in chat.
+func (b historyBackend) Resource(ctx context.Context, namespace, kind, name string, at time.Time) (*types.Resource, string, error) {
+	ver, err := b.versioner()
+	if err != nil {
+		return nil, "", err
+	}
+	ref := "HEAD"
+	if !at.IsZero() {
+		if ref, err = ver.FindCommitByTime(ctx, at); err != nil {
+			return nil, "", err
+		}
+	}
+	entry, err := ver.Entry(ref, b.scope)
+	if err != nil {
+		return nil, "", err
+	}
+	snapshot, err := timetravel.ReadCommit(ctx, ver, entry, b.scope, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, res := range snapshot.Resources {
+		if res.Namespace == namespace && res.Name == name && strings.EqualFold(res.Kind, kind) {
+			return &snapshot.Resources[i], entry.CommitHash, nil
+		}
+	}
+	return nil, entry.CommitHash, nil
+}
+
+// History returns the latest snapshots, newest first.
+func (b historyBackend) History(ctx context.Context, limit int) ([]types.HistoryEntry, error) {
+	ver, err := b.versioner()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ver.HistoryIn(b.scope, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// keepNamespace drops the resources outside a namespace from a snapshot.
+func keepNamespace(snapshot *types.ResourceSnapshot, namespace string) {
+	kept := snapshot.Resources[:0]
+	for _, res := range snapshot.Resources {
+		if res.Namespace == namespace {
+			kept = append(kept, res)
+		}
+	}
+	snapshot.Resources = kept
+	snapshot.Metadata.ResourceCount = len(kept)
+}
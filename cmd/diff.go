@@ -1,22 +1,18 @@
 package cmd
 
 import (
-	"fmt"
-	"time"
-
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
 	"github.com/spf13/cobra"
 )
 
 var (
-	diffFrom   string
-	diffTo     string
-	diffCommit string
+	diffSelection snapshotSelection
+	diffGroupBy   string
+	diffExpand    bool
+	diffWide      bool
+	diffOutput    reportOutput
 )
 
 var diffCmd = &cobra.Command{
@@ -24,75 +20,79 @@ var diffCmd = &cobra.Command{
 	Short: "Show differences between two snapshots",
 	Long: `Compare infrastructure state between two points in time or 
 two specific commits. Shows added, removed, and modified resources 
-with field-level detail.`,
+with field-level detail.
+
+--format selects the output: text for the terminal, markdown or html for
+sharing (e.g. as a pull request comment), json, sarif for code scanning
+dashboards, or junit to show each changed resource as a failed test in CI.
+Markdown, HTML, and JUnit mask secret values as notifications do.
+-o json|yaml|table is a shorthand for the machine-readable formats, and
+--output-file writes the report to a file instead of stdout.
+
+With --exit-code, diff exits with code 6 if any resource drifted, so that
+scripts can branch on drift without parsing the report. Drift in a
+maintenance window does not fail. With image_policy enabled, the target's
+images are checked too: violations are listed after the drift, and fail
+--exit-code with code 7.`,
 	Example: `  # Compare by timestamps
   gitops-time-machine diff --from "2024-01-01T00:00:00Z" --to "2024-01-02T00:00:00Z"
   
   # Compare current state with a specific commit
-  gitops-time-machine diff --commit abc1234`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := getConfig()
+  gitops-time-machine diff --commit abc1234
 
-		printer.Banner()
-		printer.Info("Analyzing infrastructure differences...")
+  # Compare two commits (relative refs are accepted)
+  gitops-time-machine diff --from-commit HEAD~3 --to-commit abc1234
 
-		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
-		if err != nil {
-			return fmt.Errorf("failed to initialize versioner: %w", err)
-		}
+  # Compare commits of a repository pushed from the cluster
+  gitops-time-machine diff --repo git@github.com:acme/snapshots.git --from-commit HEAD~1 --to-commit HEAD
 
-		snap := snapshotter.New(cfg.Snapshot.OutputDir)
-		tt := timetravel.New(ver, snap, cfg.Snapshot.OutputDir)
+  # Upload the last day's drift to a code scanning dashboard
+  gitops-time-machine diff --from-commit HEAD~24 --to-commit HEAD --format sarif > drift.sarif
 
-		var fromSnapshot *types.ResourceSnapshot
-		var toSnapshot *types.ResourceSnapshot
+  # Fail a CI step on drift since the last release, keeping the report
+  gitops-time-machine diff --commit v1.4.0 -o json --output-file drift.json --exit-code`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+		opts := render.Options{Expand: diffExpand, Width: outputWidth(diffWide), GroupBy: diffGroupBy}
+		if err := diffOutput.resolve(cmd, opts); err != nil {
+			return err
+		}
 
-		if diffCommit != "" {
-			// Compare specific commit with latest
-			fromSnap, err := tt.SnapshotByCommit(diffCommit)
-			if err != nil {
-				return fmt.Errorf("failed to get snapshot for commit %s: %w", diffCommit, err)
-			}
-			fromSnapshot = fromSnap
+		if diffOutput.text() {
+			printer.Banner()
+			printer.Info("Analyzing infrastructure differences...")
+		}
 
-			// Get latest snapshot
-			toSnap, err := snap.Read()
-			if err != nil {
-				return fmt.Errorf("failed to read current snapshot: %w", err)
-			}
-			toSnapshot = toSnap
-		} else if diffFrom != "" && diffTo != "" {
-			fromTime, err := time.Parse(time.RFC3339, diffFrom)
-			if err != nil {
-				return fmt.Errorf("invalid --from time format (use RFC3339): %w", err)
-			}
-			toTime, err := time.Parse(time.RFC3339, diffTo)
-			if err != nil {
-				return fmt.Errorf("invalid --to time format (use RFC3339): %w", err)
-			}
+		fromSnapshot, toSnapshot, err := diffSelection.load(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
 
-			fromSnap, toSnap, err := tt.CompareTimeRange(fromTime, toTime)
-			if err != nil {
-				return fmt.Errorf("failed to compare time range: %w", err)
-			}
-			fromSnapshot = fromSnap
-			toSnapshot = toSnap
+		// Run drift analysis. Only --commit compares with the working tree;
+		// every other selection reads both snapshots from commits.
+		var report *types.DriftReport
+		if diffSelection.commit != "" {
+			report, err = compareSnapshots(cmd.Context(), cfg, fromSnapshot, toSnapshot)
 		} else {
-			return fmt.Errorf("specify either --commit or both --from and --to")
+			report, err = compareCommits(cmd.Context(), cfg, fromSnapshot, toSnapshot)
 		}
-
-		// Run drift analysis
-		report := analyzer.New().Compare(fromSnapshot, toSnapshot)
-		printer.DriftSummary(report)
-
-		return nil
+		if err != nil {
+			return err
+		}
+		if err := diffOutput.write(cfg, report, opts); err != nil {
+			return err
+		}
+		return diffOutput.check(cmd, report)
 	},
 }
 
 func init() {
-	diffCmd.Flags().StringVar(&diffFrom, "from", "", "start time (RFC3339 format)")
-	diffCmd.Flags().StringVar(&diffTo, "to", "", "end time (RFC3339 format)")
-	diffCmd.Flags().StringVar(&diffCommit, "commit", "", "compare with specific commit hash")
+	diffSelection.addFlags(diffCmd)
+	diffOutput.addFlags(diffCmd)
+	diffCmd.Flags().StringVar(&diffGroupBy, "group-by", "", "group drift entries by: team or release")
+	diffCmd.Flags().BoolVar(&diffExpand, "expand", false, "show field changes even for large reports")
+	diffCmd.Flags().BoolVar(&diffWide, "wide", false, "print values in full instead of fitting the terminal width")
+	addRepoFlag(diffCmd)
 
 	rootCmd.AddCommand(diffCmd)
 }
@@ -7,10 +7,21 @@ import (
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+var (
+	driftGroupBy string
+	driftExpand  bool
+	driftWide    bool
+	driftOutput  reportOutput
+)
+
 var driftCmd = &cobra.Command{
 	Use:   "drift",
 	Short: "Detect drift between live state and last snapshot",
@@ -19,16 +30,36 @@ against the last committed snapshot. Shows any resources that have
 been added, removed, or modified since the last snapshot.
 
 This is useful for detecting manual changes, unauthorized 
-modifications, or configuration drift.`,
+modifications, or configuration drift.
+
+--format selects the output as for diff: text, markdown, html, json, yaml,
+sarif, or junit; -o json|yaml|table is a shorthand, and --output-file
+writes the report to a file. With --exit-code, drift exits with code 6 if
+the cluster drifted outside a maintenance window, or 7 if its images break
+image_policy.
+
+With notifiers.on_drift set, drift outside a maintenance window is also
+sent to the configured notifiers.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := getConfig()
+		opts := render.Options{Expand: driftExpand, Width: outputWidth(driftWide), GroupBy: driftGroupBy}
+		if err := driftOutput.resolve(cmd, opts); err != nil {
+			return err
+		}
+		text := driftOutput.text()
 
-		printer.Banner()
-		printer.Info("Checking for infrastructure drift...")
+		if text {
+			printer.Banner()
+			printer.Info("Checking for infrastructure drift...")
+		}
 
 		// Read the last committed snapshot
-		snap := snapshotter.New(cfg.Snapshot.OutputDir)
-		lastSnapshot, err := snap.Read()
+		snap, err := scopedSnapshotter(cfg)
+		if err != nil {
+			return err
+		}
+		ctx := cmd.Context()
+		lastSnapshot, err := snap.ReadContext(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to read last snapshot (run 'snapshot' first): %w", err)
 		}
@@ -39,29 +70,65 @@ modifications, or configuration drift.`,
 			return fmt.Errorf("failed to create collector: %w", err)
 		}
 
-		ctx := context.Background()
 		liveSnapshot, err := coll.Collect(ctx)
-		if err != nil {
+		if err = allowPartial(err); err != nil {
 			return fmt.Errorf("failed to collect live state: %w", err)
 		}
 
+		filterToTeam(cfg, liveSnapshot)
+
 		// Compare
-		report := analyzer.New().Compare(lastSnapshot, liveSnapshot)
+		report, err := compareSnapshots(ctx, cfg, lastSnapshot, liveSnapshot)
+		if err != nil {
+			return err
+		}
+		report.BaseRef = "HEAD"
+		report.TargetRef = refLive
 
 		// Print results
-		printer.DriftSummary(report)
+		if err := driftOutput.write(cfg, report, opts); err != nil {
+			return err
+		}
+		notifyDrift(ctx, cfg, "drift", report)
 
-		if analyzer.HasDrift(report) {
+		if !text {
+			return driftOutput.check(cmd, report)
+		}
+		if analyzer.HasDrift(report) && report.SuppressedBy != "" {
+			printer.Info(fmt.Sprintf("Drift recorded during maintenance window %q; alerting is suppressed.", report.SuppressedBy))
+		} else if analyzer.HasDrift(report) {
 			printer.Info("Drift detected! Review the changes above.")
 			printer.Info("Run 'gitops-time-machine snapshot' to capture the current state.")
 		} else {
 			printer.Success("No drift detected — infrastructure matches the last snapshot.")
 		}
 
-		return nil
+		return driftOutput.check(cmd, report)
 	},
 }
 
+// notifyDrift sends drift found by source to the configured notifiers when
+// notifiers.on_drift is set. Reports without drift or from a maintenance
+// window are not sent, and failures are logged rather than returned.
+func notifyDrift(ctx context.Context, cfg *config.Config, source string, report *types.DriftReport) {
+	if !cfg.Notifiers.OnDrift || report == nil || !analyzer.HasDrift(report) || report.SuppressedBy != "" {
+		return
+	}
+	msg, err := notifier.DriftMessage(&cfg.Notifiers, source, report)
+	if err != nil {
+		log.WithError(err).Warn("failed to build drift notification")
+		return
+	}
+	if err := notifier.SendAll(ctx, notifier.New(&cfg.Notifiers), msg); err != nil {
+		log.WithError(err).Warn("failed to send drift notification")
+	}
+}
+
 func init() {
+	driftOutput.addFlags(driftCmd)
+	driftCmd.Flags().StringVar(&driftGroupBy, "group-by", "", "group drift entries by: team or release")
+	driftCmd.Flags().BoolVar(&driftExpand, "expand", false, "show field changes even for large reports")
+	driftCmd.Flags().BoolVar(&driftWide, "wide", false, "print values in full instead of fitting the terminal width")
+
 	rootCmd.AddCommand(driftCmd)
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for the failures scripts commonly branch on. Any other error
+// exits with 1.
+const (
+	exitError             = 1
+	exitNoSnapshots       = 2
+	exitCommitNotFound    = 3
+	exitRepoLocked        = 4
+	exitPartialCollection = 5
+	exitDriftDetected     = 6
+	exitPolicyViolation   = 7
+	exitSnapshotRejected  = 8
+	// exitInterrupted follows the shell convention for SIGINT.
+	exitInterrupted = 130
+)
+
+// exitCodes maps the errors in package types to their exit codes.
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{types.ErrNoSnapshots, exitNoSnapshots},
+	{types.ErrCommitNotFound, exitCommitNotFound},
+	{types.ErrRepoLocked, exitRepoLocked},
+	{types.ErrPartialCollection, exitPartialCollection},
+	{types.ErrDriftDetected, exitDriftDetected},
+	{types.ErrPolicyViolation, exitPolicyViolation},
+	{types.ErrSnapshotRejected, exitSnapshotRejected},
+	{context.Canceled, exitInterrupted},
+}
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return exitError
+}
+
+// interrupted returns the error of the command's context, which is
+// cancelled on Ctrl+C or SIGTERM, for long-running commands that stop
+// cleanly when it is. Being interrupted is not a misuse, so usage is not
+// printed.
+func interrupted(cmd *cobra.Command) error {
+	err := cmd.Context().Err()
+	if err != nil {
+		cmd.SilenceUsage = true
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/expiry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expiringCommit string
+	expiringAt     string
+	expiringWithin time.Duration
+	expiringAll    bool
+	expiringOutput string
+)
+
+var expiringCmd = &cobra.Command{
+	Use:   "expiring",
+	Short: "List certificates that have expired or expire soon",
+	Long: `Lists the certificates captured in a snapshot that have expired or expire
+within expiry.warn_within (or --within) from now: Secrets of type
+kubernetes.io/tls, whose tls.crt is parsed, and cert-manager Certificates
+whose status records notAfter (when status is not stripped).
+
+Each snapshot also records how many certificates were expiring when it was
+taken, shown in the snapshot summary.`,
+	Example: `  # Certificates expiring in the next 30 days (the default)
+  gitops-time-machine expiring
+
+  # Every certificate and its expiry, as JSON
+  gitops-time-machine expiring --all -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(expiringOutput) && expiringOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", expiringOutput)
+		}
+		within := cfg.Expiry.WarnWithin
+		if cmd.Flags().Changed("within") {
+			within = expiringWithin
+		}
+
+		snapshot, err := loadSnapshot(cmd.Context(), cfg, expiringCommit, expiringAt)
+		if err != nil {
+			return err
+		}
+		certs := expiry.Find(snapshot)
+		if !expiringAll {
+			certs = expiry.Expiring(certs, time.Now(), within)
+		}
+
+		if isStructuredOutput(expiringOutput) {
+			if certs == nil {
+				certs = []expiry.Certificate{}
+			}
+			return printStructured(expiringOutput, certs)
+		}
+		if len(certs) == 0 {
+			printer.Success(fmt.Sprintf("No certificates expire within %s.", within))
+			return nil
+		}
+		printer.ExpiringCertificates(certs, within)
+		return nil
+	},
+}
+
+func init() {
+	expiringCmd.Flags().StringVar(&expiringCommit, "commit", "", "check the snapshot at a commit, branch, tag, or revision")
+	expiringCmd.Flags().StringVar(&expiringAt, "at", "", "check the snapshot at a point in time (RFC3339 format)")
+	expiringCmd.Flags().DurationVar(&expiringWithin, "within", 0, "report certificates expiring within this duration (default: expiry.warn_within)")
+	expiringCmd.Flags().BoolVar(&expiringAll, "all", false, "list every certificate, not only expiring ones")
+	expiringCmd.Flags().StringVarP(&expiringOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(expiringCmd)
+}
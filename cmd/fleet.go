@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ci"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// fleetSnapshot is one fleet-mode capture: a snapshot per successfully
+// collected cluster, keyed by cluster name, and the combined totals recorded
+// at the repository root.
+type fleetSnapshot struct {
+	clusters map[string]*types.ResourceSnapshot
+	total    *types.ResourceSnapshot
+}
+
+// failed returns the names of the clusters that could not be collected.
+func (f *fleetSnapshot) failed() []string {
+	var names []string
+	for _, status := range f.total.Metadata.Clusters {
+		if status.Failed() {
+			names = append(names, status.Name)
+		}
+	}
+	return names
+}
+
+// selectCluster points the kube client at the --cluster cluster, if any.
+func selectCluster(cfg *config.Config) error {
+	if cluster == "" {
+		return nil
+	}
+	for _, c := range cfg.Clusters {
+		if c.Name == cluster {
+			cfg.Context = c.Context
+			if c.Kubeconfig != "" {
+				cfg.Kubeconfig = c.Kubeconfig
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("--cluster %q is not one of the configured clusters", cluster)
+}
+
+// clusterConfig returns cfg narrowed to one cluster of the fleet: its context
+// and kubeconfig, its snapshot directory, and its own anomaly statistics.
+func clusterConfig(cfg *config.Config, c config.ClusterConfig) *config.Config {
+	narrowed := *cfg
+	narrowed.Clusters = nil
+	narrowed.Context = c.Context
+	if c.Kubeconfig != "" {
+		narrowed.Kubeconfig = c.Kubeconfig
+	}
+	narrowed.Snapshot.OutputDir = filepath.Join(cfg.Snapshot.OutputDir, c.Name)
+	if cfg.Watch.Anomaly.StateFile == "" {
+		narrowed.Watch.Anomaly.StateFile = filepath.Join(cfg.Snapshot.OutputDir, ".git", "gitops-time-machine", "anomaly-"+c.Name+".json")
+	}
+	return &narrowed
+}
+
+// collectFleet collects every configured cluster concurrently, each with its
+// own timeout. A cluster that fails is recorded in the fleet metadata and
+// its previous snapshot is kept; collection only fails if every cluster does.
+func collectFleet(ctx context.Context, cfg *config.Config, progress *printer.Progress) (*fleetSnapshot, error) {
+	snapshots := make([]*types.ResourceSnapshot, len(cfg.Clusters))
+	statuses := make([]types.ClusterStatus, len(cfg.Clusters))
+
+	var mu sync.Mutex
+	done := 0
+	progress.Update("collecting", 0, len(cfg.Clusters), "clusters")
+
+	var wg sync.WaitGroup
+	for i, c := range cfg.Clusters {
+		wg.Add(1)
+		go func(i int, c config.ClusterConfig) {
+			defer wg.Done()
+
+			clusterCtx, cancel := context.WithCancel(ctx)
+			if cfg.ClusterTimeout > 0 {
+				clusterCtx, cancel = context.WithTimeout(ctx, cfg.ClusterTimeout)
+			}
+			defer cancel()
+
+			start := time.Now()
+			// Per-type progress would interleave between clusters; report per cluster instead
+			snapshot, err := collectSnapshot(clusterCtx, clusterConfig(cfg, c), nil)
+			err = allowPartial(err)
+			status := types.ClusterStatus{Name: c.Name, Context: c.Context, Duration: time.Since(start)}
+			if err != nil {
+				status.Error = err.Error()
+				log.WithError(err).WithField("cluster", c.Name).Warn("failed to collect cluster, keeping its previous snapshot")
+			} else {
+				status.ResourceCount = snapshot.Metadata.ResourceCount
+				snapshots[i] = snapshot
+			}
+			statuses[i] = status
+
+			mu.Lock()
+			done++
+			progress.Update("collecting", done, len(cfg.Clusters), c.Name)
+			mu.Unlock()
+		}(i, c)
+	}
+	wg.Wait()
+	progress.Done()
+
+	fleet := &fleetSnapshot{clusters: make(map[string]*types.ResourceSnapshot, len(cfg.Clusters))}
+	var kept []*types.ResourceSnapshot
+	for i, c := range cfg.Clusters {
+		if snapshots[i] != nil {
+			fleet.clusters[c.Name] = snapshots[i]
+			continue
+		}
+		// Count the kept snapshot so the fleet totals don't dip
+		previous, err := newSnapshotter(cfg, filepath.Join(cfg.Snapshot.OutputDir, c.Name)).Read()
+		if err == nil {
+			kept = append(kept, previous)
+		}
+	}
+	if len(fleet.clusters) == 0 {
+		return nil, fmt.Errorf("failed to collect any cluster: %s", statuses[0].Error)
+	}
+
+	fleet.total = combineFleet(fleet.clusters, kept)
+	fleet.total.Metadata.Clusters = statuses
+	fleet.total.Metadata.CI = ci.Detect()
+	return fleet, nil
+}
+
+// combineFleet builds the fleet totals from the freshly collected cluster
+// snapshots and the kept snapshots of clusters that failed.
+func combineFleet(clusters map[string]*types.ResourceSnapshot, kept []*types.ResourceSnapshot) *types.ResourceSnapshot {
+	total := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{
+			Timestamp: time.Now().UTC(),
+			Timings:   &types.PhaseTimings{},
+		},
+	}
+
+	namespaces := make(map[string]bool)
+	add := func(snapshot *types.ResourceSnapshot) {
+		total.Resources = append(total.Resources, snapshot.Resources...)
+		total.Metadata.ExpiringCertificates += snapshot.Metadata.ExpiringCertificates
+		total.Metadata.PolicyViolations += snapshot.Metadata.PolicyViolations
+		if e := snapshot.Metadata.RBACExposure; e != nil {
+			if total.Metadata.RBACExposure == nil {
+				total.Metadata.RBACExposure = &types.RBACExposure{}
+			}
+			// Subjects of different clusters are different subjects
+			total.Metadata.RBACExposure.Subjects += e.Subjects
+			total.Metadata.RBACExposure.Permissions += e.Permissions
+			total.Metadata.RBACExposure.ClusterWide += e.ClusterWide
+			total.Metadata.RBACExposure.Wildcards += e.Wildcards
+		}
+		for _, ns := range snapshot.Metadata.Namespaces {
+			namespaces[ns] = true
+		}
+	}
+	for _, snapshot := range clusters {
+		add(snapshot)
+		if t := snapshot.Metadata.Timings; t != nil {
+			total.Metadata.Timings.Collection = max(total.Metadata.Timings.Collection, t.Collection)
+		}
+	}
+	for _, snapshot := range kept {
+		add(snapshot)
+	}
+	for ns := range namespaces {
+		total.Metadata.Namespaces = append(total.Metadata.Namespaces, ns)
+	}
+	sort.Strings(total.Metadata.Namespaces)
+
+	total.UpdateCounts()
+	return total
+}
+
+// commitFleet writes every cluster's snapshot to its directory and commits
+// them together to branch, or to the configured branch if branch is empty.
+func commitFleet(cfg *config.Config, fleet *fleetSnapshot, branch string, progress *printer.Progress) error {
+	opts := snapshotOptions(cfg)
+	written := 0
+	opts.OnWrite = func() {
+		written++
+		progress.Update("writing", written, len(fleet.total.Resources), "files")
+	}
+
+	if err := checkEmpty(cfg, fleet.total); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := snapshotter.NewWithOptions(cfg.Snapshot.OutputDir, opts).WriteFleet(fleet.total, fleet.clusters, fleet.failed())
+	progress.Done()
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	fleet.total.Metadata.Timings.Serialization = time.Since(start)
+
+	if err := commitWritten(cfg, fleet.total, branch); err != nil {
+		return err
+	}
+	for _, snapshot := range fleet.clusters {
+		snapshot.Metadata.CommitHash = fleet.total.Metadata.CommitHash
+		snapshot.Metadata.CommitURL = fleet.total.Metadata.CommitURL
+	}
+	return nil
+}
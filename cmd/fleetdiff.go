@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/fleet"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetDiffReference string
+	fleetDiffNamespace string
+	fleetDiffKind      string
+	fleetDiffName      string
+	fleetDiffOutput    string
+)
+
+var fleetDiffCmd = &cobra.Command{
+	Use:   "fleet-diff",
+	Short: "Compare the same resources across all clusters of the fleet",
+	Long: `Compares the latest snapshot of every configured cluster against a
+reference cluster and prints a matrix of the resources that deviate: which
+clusters differ, which are missing a resource the reference has, and which
+have extra resources. Differing fields are listed below the matrix.
+
+UIDs are ignored, so the same resource deployed to two clusters compares
+as identical. The reference defaults to the first configured cluster.`,
+	Example: `  # Are all regions identical?
+  gitops-time-machine fleet-diff
+
+  # Compare the prod namespace against us-east
+  gitops-time-machine fleet-diff --reference us-east --namespace prod
+
+  # One Deployment across the fleet, as JSON
+  gitops-time-machine fleet-diff --kind Deployment --name api --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if len(cfg.Clusters) < 2 {
+			return fmt.Errorf("fleet-diff needs at least two clusters in config")
+		}
+		if !isStructuredOutput(fleetDiffOutput) && fleetDiffOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", fleetDiffOutput)
+		}
+
+		reference := fleetDiffReference
+		if reference == "" {
+			reference = cfg.Clusters[0].Name
+		}
+
+		names := make([]string, 0, len(cfg.Clusters))
+		snapshots := make(map[string]*types.ResourceSnapshot, len(cfg.Clusters))
+		for _, c := range cfg.Clusters {
+			snapshot, err := newSnapshotter(cfg, filepath.Join(cfg.Snapshot.OutputDir, c.Name)).Read()
+			if err != nil {
+				return fmt.Errorf("failed to read snapshot of cluster %s (run 'snapshot' first): %w", c.Name, err)
+			}
+			snapshot.Resources = filterFleetDiff(snapshot.Resources)
+			names = append(names, c.Name)
+			snapshots[c.Name] = snapshot
+		}
+		if _, ok := snapshots[reference]; !ok {
+			return fmt.Errorf("--reference %q is not one of the configured clusters", reference)
+		}
+
+		matrix, err := fleet.Compare(&cfg.Ignore, reference, names, snapshots)
+		if err != nil {
+			return err
+		}
+
+		if isStructuredOutput(fleetDiffOutput) {
+			return printStructured(fleetDiffOutput, matrix)
+		}
+		printer.Banner()
+		printer.FleetMatrix(matrix)
+		return nil
+	},
+}
+
+// filterFleetDiff keeps the resources matching --namespace, --kind, and --name.
+func filterFleetDiff(resources []types.Resource) []types.Resource {
+	var kept []types.Resource
+	for _, res := range resources {
+		if fleetDiffNamespace != "" && res.Namespace != fleetDiffNamespace {
+			continue
+		}
+		if fleetDiffKind != "" && res.Kind != fleetDiffKind {
+			continue
+		}
+		if fleetDiffName != "" && res.Name != fleetDiffName {
+			continue
+		}
+		kept = append(kept, res)
+	}
+	return kept
+}
+
+func init() {
+	fleetDiffCmd.Flags().StringVar(&fleetDiffReference, "reference", "", "cluster to compare the others against (default: first configured cluster)")
+	fleetDiffCmd.Flags().StringVar(&fleetDiffNamespace, "namespace", "", "only compare resources in this namespace")
+	fleetDiffCmd.Flags().StringVar(&fleetDiffKind, "kind", "", "only compare resources of this kind (e.g. Deployment)")
+	fleetDiffCmd.Flags().StringVar(&fleetDiffName, "name", "", "only compare resources with this name")
+	fleetDiffCmd.Flags().StringVarP(&fleetDiffOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(fleetDiffCmd)
+}
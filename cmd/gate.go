@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/anomaly"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// snapshotReview is the outcome of checking a new snapshot before it is committed.
+type snapshotReview struct {
+	// result is nil when the watch gate is disabled.
+	result    *policy.Result
+	anomalies []types.Anomaly
+	// report is the drift since the previous snapshot, nil if nothing was
+	// compared.
+	report *types.DriftReport
+}
+
+// drift is the number of drift entries since the previous snapshot.
+func (r *snapshotReview) drift() int {
+	if r.report == nil {
+		return 0
+	}
+	return len(r.report.Entries)
+}
+
+// rejected reports whether the snapshot belongs on the quarantine branch.
+func (r *snapshotReview) rejected() bool {
+	return r.result != nil && r.result.Rejected
+}
+
+// reviewSnapshot diffs a freshly collected snapshot against the one currently
+// checked out, scores the delta for anomalies, and evaluates the watch gate.
+// Nothing is checked when there is no previous snapshot, and nothing is
+// diffed unless the gate, anomaly detection, remediation, drift
+// notifications, or watch metrics need it.
+func reviewSnapshot(ctx context.Context, cfg *config.Config, snapshot *types.ResourceSnapshot) (*snapshotReview, error) {
+	review := &snapshotReview{}
+	if !cfg.Watch.Gate.Enabled && !cfg.Watch.Anomaly.Enabled && !cfg.Notifiers.OnDrift && remediator == nil && watchMetrics == nil {
+		return review, nil
+	}
+
+	previous, err := newSnapshotter(cfg, cfg.Snapshot.OutputDir).ReadContext(ctx)
+	if err != nil {
+		log.WithError(err).Debug("no previous snapshot, skipping review")
+		return review, nil
+	}
+
+	report, err := compareSnapshots(ctx, cfg, previous, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	report.BaseRef = "HEAD"
+	report.TargetRef = refLive
+	review.report = report
+
+	if cfg.Watch.Anomaly.Enabled {
+		detector, err := anomaly.Load(&cfg.Watch.Anomaly, anomalyStateFile(cfg))
+		if err != nil {
+			return nil, err
+		}
+		review.anomalies = detector.Observe(report)
+		report.Anomalies = review.anomalies
+		if err := detector.Save(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Watch.Gate.Enabled {
+		result, err := policy.Evaluate(ctx, &cfg.Watch.Gate, report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate gate: %w", err)
+		}
+		if len(review.anomalies) > 0 {
+			addAnomalyViolations(cfg, result, review.anomalies)
+		}
+		review.result = result
+	}
+
+	return review, nil
+}
+
+// addAnomalyViolations reports anomalies as gate violations at the configured severity.
+func addAnomalyViolations(cfg *config.Config, result *policy.Result, anomalies []types.Anomaly) {
+	severity, _ := policy.ParseSeverity(cfg.Watch.Anomaly.Severity)
+	failOn, _ := policy.ParseSeverity(cfg.Watch.Gate.FailOn)
+
+	for _, a := range anomalies {
+		result.Violations = append(result.Violations, policy.Violation{
+			Rule:     "anomaly",
+			Severity: severity,
+			Message:  fmt.Sprintf("%s: %d changes (mean %.1f, score %.1f)", a.Series, a.Changes, a.Mean, a.Score),
+		})
+	}
+	if severity >= failOn {
+		result.Rejected = true
+	}
+}
+
+// anomalyStateFile returns where the anomaly detector keeps its statistics.
+func anomalyStateFile(cfg *config.Config) string {
+	if cfg.Watch.Anomaly.StateFile != "" {
+		return cfg.Watch.Anomaly.StateFile
+	}
+	// Inside .git so it survives snapshot rewrites without being committed.
+	return filepath.Join(cfg.Snapshot.OutputDir, ".git", "gitops-time-machine", "anomaly.json")
+}
@@ -1,25 +1,57 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/gaps"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
 	"github.com/spf13/cobra"
 )
 
-var historyLimit int
+var (
+	historyLimit       int
+	historyOutput      string
+	historyComposition string
+	historyColumns     []string
+	historyWide        bool
+)
+
+// compositionColumns caps the per-group columns of `history --composition`.
+const compositionColumns = 8
 
 var historyCmd = &cobra.Command{
 	Use:   "history",
 	Short: "List all infrastructure snapshots",
 	Long: `Shows a chronological list of all committed snapshots, 
-including timestamps, commit hashes, and resource counts.`,
+including timestamps, commit hashes, and resource counts.
+
+Windows in which ticks of watch.schedule produced no snapshot (the tool
+was down or the cluster unreachable) are flagged below the table, once
+watch.gaps.min_missed ticks in a row are missing.`,
 	Example: `  # Show last 10 snapshots
   gitops-time-machine history --limit 10
   
   # Show all snapshots
-  gitops-time-machine history`,
+  gitops-time-machine history
+
+  # Emit machine-readable history
+  gitops-time-machine history --output json
+
+  # Pick columns, printed in full for a file
+  gitops-time-machine history --columns commit,timestamp,author,message --wide > history.txt
+
+  # Show how many resources of each kind every snapshot held
+  gitops-time-machine history --composition kind
+
+  # List the snapshots of a repository pushed from the cluster
+  gitops-time-machine history --repo https://github.com/acme/snapshots.git`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := getConfig()
 
@@ -28,11 +60,26 @@ including timestamps, commit hashes, and resource counts.`,
 			return fmt.Errorf("failed to initialize versioner: %w", err)
 		}
 
-		entries, err := ver.History(historyLimit)
+		scope, err := snapshotScope(cfg)
+		if err != nil {
+			return err
+		}
+
+		entries, err := ver.HistoryIn(scope, historyLimit)
 		if err != nil {
 			return fmt.Errorf("failed to get history: %w", err)
 		}
 
+		if isStructuredOutput(historyOutput) {
+			return printStructured(historyOutput, entries)
+		}
+		if historyOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", historyOutput)
+		}
+		if err := printer.ValidateColumns(historyColumns, printer.HistoryColumns); err != nil {
+			return err
+		}
+
 		commitCount, _ := ver.GetCommitCount()
 
 		printer.Banner()
@@ -41,14 +88,119 @@ including timestamps, commit hashes, and resource counts.`,
 			printer.Info(fmt.Sprintf("Showing last %d of %d total snapshots", historyLimit, commitCount))
 		}
 
-		printer.HistoryTable(entries)
+		switch historyComposition {
+		case "":
+			printer.HistoryTable(entries, printer.TableOptions{Columns: historyColumns, Width: outputWidth(historyWide)})
+		case "kind":
+			printer.CompositionTable(entries, "Kind", func(e types.HistoryEntry) map[string]int {
+				return e.KindCounts
+			}, compositionColumns)
+		case "namespace":
+			printer.CompositionTable(entries, "Namespace", func(e types.HistoryEntry) map[string]int {
+				return e.NamespaceCounts
+			}, compositionColumns)
+		default:
+			return fmt.Errorf("unsupported composition %q (use kind or namespace)", historyComposition)
+		}
 
+		found, err := historyGaps(cfg, entries)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			fmt.Println()
+			printer.Warning(fmt.Sprintf("%d scheduled snapshots are missing (schedule %q):", gaps.Missed(found), cfg.Watch.Schedule))
+			printer.Gaps(found)
+		}
 		return nil
 	},
 }
 
+// historyGaps finds the missing snapshot windows from the oldest entry to
+// now. Snapshots taken while nothing changed are recorded as checks.
+func historyGaps(cfg *config.Config, entries []types.HistoryEntry) ([]gaps.Gap, error) {
+	checks, err := gaps.LoadChecks(gaps.ChecksFile(cfg.Snapshot.OutputDir))
+	if err != nil {
+		return nil, err
+	}
+	return gaps.Find(&cfg.Watch, gaps.SnapshotTimes(entries), checks, time.Now())
+}
+
+// parsePeriod parses the --from and --to of a command that follows history
+// over a period. An empty from is the zero time; an empty to is now.
+func parsePeriod(fromFlag, toFlag string) (from, to time.Time, err error) {
+	if fromFlag != "" {
+		if from, err = time.Parse(time.RFC3339, fromFlag); err != nil {
+			return from, to, fmt.Errorf("invalid --from time format (use RFC3339): %w", err)
+		}
+	}
+	to = time.Now()
+	if toFlag != "" {
+		if to, err = time.Parse(time.RFC3339, toFlag); err != nil {
+			return from, to, fmt.Errorf("invalid --to time format (use RFC3339): %w", err)
+		}
+	}
+	if !from.IsZero() && !to.After(from) {
+		return from, to, fmt.Errorf("--to must be after --from")
+	}
+	return from, to, nil
+}
+
+// walkHistory reads the resources of the given kinds from every snapshot of
+// the --cluster or --team slice between from and to, and returns what
+// extract makes of each, oldest first. The last snapshot at or before from
+// is the baseline. label describes the reading in the progress output.
+func walkHistory[T any](ctx context.Context, cfg *config.Config, from, to time.Time, kinds []string, label string, showProgress bool, extract func(types.HistoryEntry, *types.ResourceSnapshot) T) ([]T, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	history, err := ver.HistoryIn(scope, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var entries []types.HistoryEntry
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		if entry.Timestamp.After(to) {
+			break
+		}
+		if !entry.Timestamp.After(from) {
+			entries = entries[:0]
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no snapshots before %s", to.UTC().Format(time.RFC3339))
+	}
+
+	progress := printer.NewProgress(showProgress)
+	points := make([]T, 0, len(entries))
+	for i, entry := range entries {
+		progress.Update(label, i, len(entries), entry.CommitHash[:8])
+		snapshot, err := timetravel.ReadCommitKinds(ctx, ver, entry, scope, kinds)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, extract(entry, snapshot))
+	}
+	progress.Done()
+	return points, nil
+}
+
 func init() {
 	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "maximum number of entries to show (0 = all)")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+	historyCmd.Flags().StringVar(&historyComposition, "composition", "", "show resource counts per snapshot by kind or namespace")
+
+	historyCmd.Flags().StringSliceVar(&historyColumns, "columns", nil, "columns to show: "+strings.Join(printer.HistoryColumns, ", "))
+	historyCmd.Flags().BoolVar(&historyWide, "wide", false, "print values in full instead of fitting the terminal width")
+	addRepoFlag(historyCmd)
 
 	rootCmd.AddCommand(historyCmd)
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/install"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	installPrint       bool
+	installNamespace   string
+	installName        string
+	installImage       string
+	installStorageSize string
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Generate manifests for running watch mode in-cluster",
+	Long: `Prints a ServiceAccount, a ClusterRole and binding granting exactly the
+read access needed for the configured resource types, a ConfigMap holding
+the configuration, a PersistentVolumeClaim for the snapshot repository, and
+a single-replica Deployment running watch mode.
+
+The ConfigMap embeds the config file in use, or a minimal configuration
+derived from the defaults if none was found. Snapshots are written to the
+PersistentVolumeClaim regardless of snapshot.output_dir.`,
+	Example: `  # Review the manifests
+  gitops-time-machine install --print
+
+  # Install into the ops namespace
+  kubectl create namespace ops
+  gitops-time-machine install --print --namespace ops | kubectl apply -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !installPrint {
+			return fmt.Errorf("install only prints manifests; run with --print and pipe to kubectl apply -f -")
+		}
+
+		configYAML, err := installConfig(cfg)
+		if err != nil {
+			return err
+		}
+
+		manifests := install.Manifests(install.Options{
+			Name:          installName,
+			Namespace:     installNamespace,
+			Image:         installImage,
+			StorageSize:   installStorageSize,
+			ResourceTypes: cfg.Snapshot.ResourceTypes,
+			Discovery:     cfg.Snapshot.Discovery.Enabled,
+			Watch:         cfg.Watch.EnableWatchEvents,
+			Config:        configYAML,
+		})
+
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		for _, m := range manifests {
+			if err := enc.Encode(m); err != nil {
+				return fmt.Errorf("failed to encode manifest: %w", err)
+			}
+		}
+		return nil
+	},
+}
+
+// installConfig returns the config file in use, or a minimal config built
+// from the effective settings when no file was found.
+func installConfig(cfg *config.Config) (string, error) {
+	if path := config.FileUsed(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read config file: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"snapshot": map[string]interface{}{
+			"resource_types":     cfg.Snapshot.ResourceTypes,
+			"discovery":          cfg.Snapshot.Discovery,
+			"exclude_namespaces": cfg.Snapshot.ExcludeNamespaces,
+		},
+		"watch": map[string]interface{}{
+			"schedule": cfg.Watch.Schedule,
+		},
+		"log": map[string]interface{}{
+			"level":  cfg.Log.Level,
+			"format": "json",
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode config: %w", err)
+	}
+	return string(data), nil
+}
+
+func init() {
+	installCmd.Flags().BoolVar(&installPrint, "print", false, "print the manifests to stdout")
+	installCmd.Flags().StringVarP(&installNamespace, "namespace", "n", "gitops-time-machine", "namespace to deploy into")
+	installCmd.Flags().StringVar(&installName, "name", "gitops-time-machine", "name of the generated objects")
+	installCmd.Flags().StringVar(&installImage, "image", "gitops-time-machine:latest", "container image to run")
+	installCmd.Flags().StringVar(&installStorageSize, "storage-size", "10Gi", "size of the snapshot volume")
+
+	rootCmd.AddCommand(installCmd)
+}
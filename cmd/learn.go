@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/learn"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	learnSnapshots  int
+	learnThreshold  float64
+	learnMinChanges int
+	learnAccept     bool
+	learnOutput     string
+)
+
+var learnCmd = &cobra.Command{
+	Use:   "learn",
+	Short: "Propose ignore rules for fields that change on nearly every snapshot",
+	Long: `Analyzes the recent snapshot history for fields that change on nearly
+every snapshot, such as timestamps written by controllers or certificates
+rotated more often than snapshots are taken, and proposes ignore rules for
+them.
+
+A field is proposed for a kind when, in some resource of that kind, it
+changed in at least --threshold of the consecutive snapshots in which it
+was present, and at least --min-changes times. Fields inside lists are not
+proposed, since a rule can only ignore a list as a whole.
+
+The proposals are printed with the ignore.fields section that accepts
+them; --accept adds them to the config file. Ignored fields are left out of
+diff, drift, and gate reports but are still captured in snapshots.`,
+	Example: `  # Propose rules from the last 50 snapshots
+  gitops-time-machine learn
+
+  # Look further back, and accept the proposals into config.yaml
+  gitops-time-machine learn --snapshots 200 --accept`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(learnOutput) && learnOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", learnOutput)
+		}
+		if learnSnapshots < 2 {
+			return fmt.Errorf("--snapshots must be at least 2")
+		}
+		if learnThreshold <= 0 || learnThreshold > 1 {
+			return fmt.Errorf("--threshold must be in (0, 1]")
+		}
+		configFile := config.FileUsed()
+		if learnAccept && configFile == "" {
+			return fmt.Errorf("--accept needs a config file; pass --config")
+		}
+
+		snapshots, err := recentSnapshots(cmd.Context(), cfg, learnSnapshots)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) < 2 {
+			return fmt.Errorf("need at least 2 snapshots to learn from, found %d", len(snapshots))
+		}
+		proposals := learn.Learn(snapshots, learn.Options{
+			Threshold:  learnThreshold,
+			MinChanges: learnMinChanges,
+			Ignore:     &cfg.Ignore,
+		})
+
+		if isStructuredOutput(learnOutput) {
+			if proposals == nil {
+				proposals = []learn.Proposal{}
+			}
+			if err := printStructured(learnOutput, proposals); err != nil {
+				return err
+			}
+		} else if len(proposals) == 0 {
+			printer.Success(fmt.Sprintf("No flapping fields in the last %d snapshots.", len(snapshots)))
+		} else {
+			printer.FlappingFields(proposals, len(snapshots))
+		}
+		if len(proposals) == 0 {
+			return nil
+		}
+
+		if !learnAccept {
+			if learnOutput == outputTable {
+				fmt.Println("Add to your config to ignore them (or rerun with --accept):")
+				fmt.Println()
+				enc := yaml.NewEncoder(os.Stdout)
+				enc.SetIndent(2)
+				rules := map[string]interface{}{"ignore": map[string]interface{}{"fields": learn.Rules(proposals)}}
+				if err := enc.Encode(rules); err != nil {
+					return fmt.Errorf("failed to encode rules: %w", err)
+				}
+			}
+			return nil
+		}
+		added, err := learn.Accept(configFile, learn.Rules(proposals))
+		if err != nil {
+			return err
+		}
+		printer.Success(fmt.Sprintf("Added %d ignore rule(s) to %s", added, configFile))
+		return nil
+	},
+}
+
+// recentSnapshots reads up to limit of the latest snapshots, oldest first,
+// from their commits.
+func recentSnapshots(ctx context.Context, cfg *config.Config, limit int) ([]*types.ResourceSnapshot, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	history, err := ver.HistoryIn(scope, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	snapshots := make([]*types.ResourceSnapshot, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		snapshot, err := timetravel.ReadCommit(ctx, ver, history[i], scope, nil)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func init() {
+	learnCmd.Flags().IntVar(&learnSnapshots, "snapshots", 50, "number of recent snapshots to analyze")
+	learnCmd.Flags().Float64Var(&learnThreshold, "threshold", 0.9, "share of snapshots in which a field must change to be proposed")
+	learnCmd.Flags().IntVar(&learnMinChanges, "min-changes", 5, "number of changes a field must have had to be proposed")
+	learnCmd.Flags().BoolVar(&learnAccept, "accept", false, "add the proposed rules to the config file")
+	learnCmd.Flags().StringVarP(&learnOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(learnCmd)
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/helm"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/index"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/managedby"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/orphans"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ownership"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/suppression"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported values for --output flags.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// isStructuredOutput reports whether the format is machine-readable.
+func isStructuredOutput(format string) bool {
+	return format == outputJSON || format == outputYAML
+}
+
+// printStructured writes v to stdout as JSON or YAML.
+func printStructured(format string, v interface{}) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(v)
+	default:
+		return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", format)
+	}
+}
+
+// outputWidth returns the width tables and reports should fit: the
+// terminal width, or 0 (no limit) with --wide or when stdout is not a
+// terminal, e.g. when piping to a file.
+func outputWidth(wide bool) int {
+	fd := int(os.Stdout.Fd())
+	if wide || !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// refLive is the TargetRef of reports against live cluster state.
+const refLive = "live"
+
+// compareSnapshots produces the drift report between two snapshots, leaving
+// out resources owned by the controllers configured in ignore_managed and
+// the resources and fields configured in ignore.
+func compareSnapshots(ctx context.Context, cfg *config.Config, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+	base, target, ignored := managedby.Exclude(&cfg.IgnoreManaged, base, target)
+	if ignored > 0 {
+		log.WithField("resources", ignored).Debug("ignoring resources owned by configured controllers")
+	}
+	report, err := newAnalyzer(cfg).CompareContext(ctx, base, target)
+	if err != nil {
+		return nil, err
+	}
+	reportUnmanaged(cfg, report, target)
+	report.PolicyViolations = policy.CheckImages(&cfg.ImagePolicy, target)
+	return report, nil
+}
+
+// newAnalyzer creates an analyzer that leaves out the resources and fields
+// configured in ignore.
+func newAnalyzer(cfg *config.Config) *analyzer.Analyzer {
+	return analyzer.NewWithOptions(analyzer.Options{Ignore: &cfg.Ignore})
+}
+
+// reportUnmanaged lists the target's unmanaged resources in the report,
+// when orphans reporting is enabled.
+func reportUnmanaged(cfg *config.Config, report *types.DriftReport, target *types.ResourceSnapshot) {
+	if !cfg.Orphans.Enabled {
+		return
+	}
+	finder, err := orphanFinder(cfg)
+	if err != nil {
+		log.WithError(err).Warn("failed to load desired state, not reporting unmanaged resources")
+		return
+	}
+	report.Unmanaged = finder.Find(target)
+}
+
+// driftSettings are the settings that change what compareSnapshots reports
+// for the same two commits, and so key the drift cache.
+type driftSettings struct {
+	Scope         string                     `json:"scope"`
+	IgnoreManaged config.IgnoreManagedConfig `json:"ignoreManaged"`
+	Ignore        config.IgnoreConfig        `json:"ignore"`
+}
+
+// compareCommits is compareSnapshots for two snapshots read unchanged from
+// their commits. Commits never change, so the report is cached per pair
+// and reused, e.g. when the same diff is requested again. Unmanaged
+// resources depend on the desired-state manifests rather than the commits
+// and are always looked up afresh.
+func compareCommits(ctx context.Context, cfg *config.Config, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+	scope, err := snapshotScope(cfg)
+	if err != nil || base.Metadata.CommitHash == "" || target.Metadata.CommitHash == "" {
+		return compareSnapshots(ctx, cfg, base, target)
+	}
+	key, err := index.DriftKey(base.Metadata.CommitHash, target.Metadata.CommitHash,
+		driftSettings{Scope: scope, IgnoreManaged: cfg.IgnoreManaged, Ignore: cfg.Ignore})
+	if err != nil {
+		return compareSnapshots(ctx, cfg, base, target)
+	}
+
+	cache := index.OpenDriftCache(index.DriftDir(cfg.Snapshot.OutputDir))
+	if report, ok := cache.Get(key); ok {
+		log.WithFields(log.Fields{
+			"base":   base.Metadata.CommitHash[:8],
+			"target": target.Metadata.CommitHash[:8],
+		}).Debug("using cached drift report")
+		report.Timestamp = time.Now().UTC()
+		reportUnmanaged(cfg, report, target)
+		report.PolicyViolations = policy.CheckImages(&cfg.ImagePolicy, target)
+		return report, nil
+	}
+
+	report, err := compareSnapshots(ctx, cfg, base, target)
+	if err != nil {
+		return nil, err
+	}
+	cached := *report
+	cached.Unmanaged = nil
+	cached.PolicyViolations = nil
+	if err := cache.Put(key, &cached); err != nil {
+		log.WithError(err).Warn("failed to cache drift report")
+	}
+	return report, nil
+}
+
+// cachedOrphanFinder is shared by every report of a command, so the
+// desired-state manifests are read only once.
+var cachedOrphanFinder *orphans.Finder
+
+// orphanFinder returns the Finder for the configured desired state.
+func orphanFinder(cfg *config.Config) (*orphans.Finder, error) {
+	if cachedOrphanFinder == nil {
+		finder, err := orphans.New(&cfg.Orphans)
+		if err != nil {
+			return nil, err
+		}
+		cachedOrphanFinder = finder
+	}
+	return cachedOrphanFinder, nil
+}
+
+// printDriftReport annotates the report and prints it in the given
+// --format (see render.Formats).
+func printDriftReport(cfg *config.Config, report *types.DriftReport, format string, opts render.Options) error {
+	return writeDriftReport(cfg, os.Stdout, report, format, opts)
+}
+
+// writeDriftReport annotates the report and writes it to w in the given
+// --format.
+func writeDriftReport(cfg *config.Config, w io.Writer, report *types.DriftReport, format string, opts render.Options) error {
+	renderer, err := render.New(format, opts)
+	if err != nil {
+		return err
+	}
+	linkReport(cfg, report)
+	if err := annotateReport(cfg, report); err != nil {
+		return err
+	}
+	return renderer.Render(w, report)
+}
+
+// reportOutput holds the flags of diff and drift that choose how and where
+// the drift report is written, and whether drift fails the command.
+type reportOutput struct {
+	format   string
+	output   string
+	file     string
+	exitCode bool
+}
+
+// addFlags registers the output flags on a command.
+func (o *reportOutput) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.format, "format", render.FormatText, "output format: "+strings.Join(render.Formats(), ", "))
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "output format: table, json, or yaml (same as --format text, json, or yaml)")
+	cmd.Flags().StringVar(&o.file, "output-file", "", "write the report to this file instead of stdout")
+	cmd.Flags().BoolVar(&o.exitCode, "exit-code", false, "exit with code 6 if drift is found outside a maintenance window, or 7 if the image policy is broken")
+}
+
+// resolve folds --output into --format and checks that the format and
+// options are supported.
+func (o *reportOutput) resolve(cmd *cobra.Command, opts render.Options) error {
+	if o.output != "" {
+		format := o.output
+		switch {
+		case format == outputTable:
+			format = render.FormatText
+		case !isStructuredOutput(format):
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", o.output)
+		}
+		if cmd.Flags().Changed("format") && o.format != format {
+			return fmt.Errorf("--output %s and --format %s disagree; use one of them", o.output, o.format)
+		}
+		o.format = format
+	}
+	_, err := render.New(o.format, opts)
+	return err
+}
+
+// text reports whether the report is printed as text to the terminal, so
+// that the banner and progress messages can go to stdout too.
+func (o *reportOutput) text() bool {
+	return o.format == render.FormatText && o.file == ""
+}
+
+// write annotates the report and writes it to stdout or --output-file.
+func (o *reportOutput) write(cfg *config.Config, report *types.DriftReport, opts render.Options) error {
+	if o.file == "" {
+		return printDriftReport(cfg, report, o.format, opts)
+	}
+
+	f, err := os.Create(o.file)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer f.Close()
+	printer.DisableColor()
+	if err := writeDriftReport(cfg, f, report, o.format, opts); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	log.WithField("file", o.file).Info("Wrote drift report")
+	return nil
+}
+
+// check returns types.ErrPolicyViolation under --exit-code if the target
+// breaks a configured policy, or else types.ErrDriftDetected if the report
+// has drift that is not suppressed by a maintenance window. Either is the
+// outcome asked for rather than a misuse, so usage is not printed.
+func (o *reportOutput) check(cmd *cobra.Command, report *types.DriftReport) error {
+	if !o.exitCode {
+		return nil
+	}
+	switch {
+	case len(report.PolicyViolations) > 0:
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d policy violations: %w", len(report.PolicyViolations), types.ErrPolicyViolation)
+	case analyzer.HasDrift(report) && report.SuppressedBy == "":
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d resources drifted: %w", len(report.Entries), types.ErrDriftDetected)
+	}
+	return nil
+}
+
+// annotateReport rates entries, attributes them to owning teams and Helm
+// releases, and marks the report if it falls in a maintenance window.
+func annotateReport(cfg *config.Config, report *types.DriftReport) error {
+	policy.Annotate(&cfg.Watch.Gate, report)
+	helm.Annotate(report)
+	resolver := ownership.New(&cfg.Ownership)
+	if resolver.Enabled() {
+		resolver.Annotate(report)
+	}
+	return suppression.Apply(&cfg.Suppression, report)
+}
+
+// linkReport links the report's commits and resource files in the snapshot
+// repository's forge, if links are configured or derived from the remote.
+func linkReport(cfg *config.Config, report *types.DriftReport) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		log.WithError(err).Debug("Could not open snapshot repository for links")
+		return
+	}
+	l := newLinks(cfg, ver)
+	if !l.Enabled() {
+		return
+	}
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return
+	}
+	l.Annotate(report, resolveLinkRef(ver, report.BaseRef), resolveLinkRef(ver, report.TargetRef), scope)
+}
+
+// resolveLinkRef resolves a report ref to a commit hash, or "" if it does
+// not name a commit (e.g. live state).
+func resolveLinkRef(ver *versioner.Versioner, ref string) string {
+	if ref == "" || ref == refLive {
+		return ""
+	}
+	hash, err := ver.ResolveRef(ref)
+	if err != nil {
+		return ""
+	}
+	return hash
+}
@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ci"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/expiry"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/gaps"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/helm"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/hooks"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/links"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ownership"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/rbac"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/spool"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// tenancyDirectory writes each team's resources into its own top-level directory.
+	tenancyDirectory = "directory"
+	// tenancyBranch is tenancyDirectory plus a branch per team holding only
+	// the team's directory.
+	tenancyBranch = "branch"
+	// unownedPartition holds resources no team claims in directory tenancy mode.
+	unownedPartition = "_unowned"
+)
+
+// captureSnapshot collects live state, writes it to disk, and commits it.
+// The returned snapshot has an empty CommitHash when nothing changed. A
+// partial collection is still committed; it is reported by returning the
+// snapshot together with the types.ErrPartialCollection error. If writing
+// or committing fails, the collected snapshot is spooled for
+// resumeSnapshot.
+//
+// In fleet mode every cluster is captured; the snapshot of the --cluster
+// cluster is returned if one is selected, otherwise the fleet totals.
+func captureSnapshot(ctx context.Context, cfg *config.Config, progress *printer.Progress) (*types.ResourceSnapshot, error) {
+	if err := hooks.Run(ctx, &cfg.Hooks, hooks.PreSnapshot, hooks.Env(cfg, nil, "")); err != nil {
+		return nil, err
+	}
+	if len(cfg.Clusters) > 0 {
+		fleet, err := collectFleet(ctx, cfg, progress)
+		if err != nil {
+			return nil, err
+		}
+		if err := commitFleet(cfg, fleet, "", progress); err != nil {
+			return nil, err
+		}
+		if cluster != "" {
+			snapshot, ok := fleet.clusters[cluster]
+			if !ok {
+				return nil, fmt.Errorf("failed to collect cluster %s", cluster)
+			}
+			return snapshot, nil
+		}
+		return fleet.total, nil
+	}
+
+	snapshot, collectErr := collectSnapshot(ctx, cfg, progress)
+	if snapshot == nil {
+		return nil, collectErr
+	}
+	path := spool.Path(cfg.Snapshot.OutputDir)
+	if err := commitSnapshot(cfg, snapshot, "", progress); err != nil {
+		if errors.Is(err, types.ErrSnapshotRejected) {
+			return nil, err
+		}
+		if spoolErr := spool.Save(path, snapshot); spoolErr != nil {
+			log.WithError(spoolErr).Warn("failed to keep the collected snapshot")
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w (the collected snapshot was kept: retry with snapshot --resume)", err)
+	}
+	if err := spool.Remove(path); err != nil {
+		log.WithError(err).Warn("failed to discard the kept snapshot")
+	}
+	return snapshot, collectErr
+}
+
+// resumeSnapshot writes and commits the snapshot kept by a capture that
+// failed to, without collecting again. A kept snapshot older than the last
+// commit is refused rather than committed over newer history.
+func resumeSnapshot(cfg *config.Config, progress *printer.Progress) (*types.ResourceSnapshot, error) {
+	if len(cfg.Clusters) > 0 {
+		return nil, fmt.Errorf("--resume is not supported in fleet mode")
+	}
+	path := spool.Path(cfg.Snapshot.OutputDir)
+	snapshot, err := spool.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no snapshot to resume: the last capture did not fail")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	if last, err := ver.Entry(cfg.Git.Branch, ""); err == nil && last.Timestamp.After(snapshot.Metadata.Timestamp) {
+		return nil, fmt.Errorf("the kept snapshot from %s is older than the last commit %s; delete %s to discard it",
+			snapshot.Metadata.Timestamp.Format(time.RFC3339), last.CommitHash[:8], path)
+	}
+
+	if err := commitSnapshot(cfg, snapshot, "", progress); err != nil {
+		return nil, err
+	}
+	if err := spool.Remove(path); err != nil {
+		log.WithError(err).Warn("failed to discard the kept snapshot")
+	}
+	return snapshot, nil
+}
+
+// collectSnapshot gathers the live state of the cluster. If some resource
+// types fail, the snapshot of the others is returned with the
+// types.ErrPartialCollection error; see allowPartial.
+func collectSnapshot(ctx context.Context, cfg *config.Config, progress *printer.Progress) (*types.ResourceSnapshot, error) {
+	if len(cfg.Clusters) > 0 && cluster == "" {
+		return nil, fmt.Errorf("clusters are configured: select one with --cluster")
+	}
+	coll, err := collector.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector: %w", err)
+	}
+	coll.SetProgress(func(resourceType string, typesDone, typesTotal, resources int) {
+		progress.Update("collecting", typesDone, typesTotal, fmt.Sprintf("%d resources (%s)", resources, resourceType))
+	})
+
+	start := time.Now()
+	snapshot, err := coll.Collect(ctx)
+	progress.Done()
+	if err != nil && !errors.Is(err, types.ErrPartialCollection) {
+		return nil, fmt.Errorf("failed to collect resources: %w", err)
+	}
+	snapshot.Metadata.Timings = &types.PhaseTimings{Collection: time.Since(start)}
+	snapshot.Metadata.Scope = partialScope
+	certs := expiry.Expiring(expiry.Find(snapshot), snapshot.Metadata.Timestamp, cfg.Expiry.WarnWithin)
+	snapshot.Metadata.ExpiringCertificates = len(certs)
+	snapshot.Metadata.RBACExposure = rbac.Exposure(rbac.Permissions(snapshot))
+	snapshot.Metadata.HelmReleases = helm.Releases(snapshot)
+	snapshot.Metadata.PolicyViolations = len(policy.CheckImages(&cfg.ImagePolicy, snapshot))
+	snapshot.Metadata.CI = ci.Detect()
+	return snapshot, err
+}
+
+// allowPartial clears a partial collection error, logging it, for callers
+// that carry on with the resource types that were collected.
+func allowPartial(err error) error {
+	if errors.Is(err, types.ErrPartialCollection) {
+		log.WithError(err).Warn("continuing with a partial snapshot")
+		return nil
+	}
+	return err
+}
+
+// commitSnapshot writes a snapshot to disk and commits it to branch, or to
+// the configured branch if branch is empty. It sets the snapshot's
+// CommitHash, which is left empty when nothing changed.
+func commitSnapshot(cfg *config.Config, snapshot *types.ResourceSnapshot, branch string, progress *printer.Progress) error {
+	start := time.Now()
+	if unchangedSinceLast(cfg, snapshot, branch) {
+		recordCheck(cfg, snapshot.Metadata.Timestamp)
+		return nil
+	}
+	if err := checkEmpty(cfg, snapshot); err != nil {
+		return err
+	}
+	if err := writeSnapshot(cfg, snapshot, progress); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if snapshot.Metadata.Timings != nil {
+		snapshot.Metadata.Timings.Serialization = time.Since(start)
+	}
+	return commitWritten(cfg, snapshot, branch)
+}
+
+// checkEmpty refuses a snapshot without resources unless
+// snapshot.allow_empty is set. It is checked before writing, which would
+// clear the working tree.
+func checkEmpty(cfg *config.Config, snapshot *types.ResourceSnapshot) error {
+	if len(snapshot.Resources) > 0 || cfg.Snapshot.AllowEmpty {
+		return nil
+	}
+	return fmt.Errorf("%w: it has no resources, which usually means the collector lost access (set snapshot.allow_empty or pass --allow-empty to commit it)", types.ErrSnapshotRejected)
+}
+
+// checkWritten refuses a written snapshot with missing metadata, files
+// outside the snapshot layout, or more than snapshot.max_size_bytes of
+// files.
+func checkWritten(cfg *config.Config) error {
+	size, err := newSnapshotter(cfg, cfg.Snapshot.OutputDir).Check()
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrSnapshotRejected, err)
+	}
+	if max := cfg.Snapshot.MaxSizeBytes; max > 0 && size > max {
+		return fmt.Errorf("%w: its files add up to %d bytes, more than snapshot.max_size_bytes (%d)", types.ErrSnapshotRejected, size, max)
+	}
+	return nil
+}
+
+// recordCheck records that a snapshot taken at t committed nothing, so
+// that it is not reported as a missing snapshot.
+func recordCheck(cfg *config.Config, t time.Time) {
+	if err := gaps.RecordCheck(gaps.ChecksFile(cfg.Snapshot.OutputDir), t); err != nil {
+		log.WithError(err).Warn("failed to record snapshot check")
+	}
+}
+
+// unchangedSinceLast records the snapshot's content hash in its metadata
+// and reports whether the last commit on branch (or the configured branch)
+// recorded the same hash, in which case writing the snapshot would change
+// nothing but its timestamp. With snapshot.skip_unchanged off it is never
+// unchanged.
+func unchangedSinceLast(cfg *config.Config, snapshot *types.ResourceSnapshot, branch string) bool {
+	var partitionOf func(types.Resource) string
+	if partitioned(cfg) {
+		partitionOf = teamPartition(cfg)
+	}
+	hash, err := newSnapshotter(cfg, cfg.Snapshot.OutputDir).ContentHash(snapshot, partitionOf)
+	if err != nil {
+		log.WithError(err).Warn("failed to hash snapshot")
+		return false
+	}
+	snapshot.Metadata.ContentHash = hash
+	if !cfg.Snapshot.SkipUnchanged {
+		return false
+	}
+
+	if branch == "" {
+		branch = cfg.Git.Branch
+	}
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return false
+	}
+	last, err := ver.Entry(branch, "")
+	if err != nil || last.ContentHash != hash {
+		return false
+	}
+	log.WithField("commit", last.CommitHash[:8]).Info("no changes since the last snapshot, skipping write")
+	return true
+}
+
+// commitWritten commits a snapshot that is already on disk and runs the
+// post-commit hook. A snapshot refused by checkWritten is discarded, so the
+// working tree is back on the last commit for the next review to diff
+// against.
+func commitWritten(cfg *config.Config, snapshot *types.ResourceSnapshot, branch string) error {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	if err := checkWritten(cfg); err != nil {
+		if discardErr := ver.Discard(); discardErr != nil {
+			log.WithError(discardErr).Warn("failed to discard the rejected snapshot")
+		}
+		return err
+	}
+
+	var commitHash string
+	if branch == "" {
+		commitHash, err = ver.Commit(&snapshot.Metadata)
+	} else {
+		commitHash, err = ver.CommitToBranch(branch, &snapshot.Metadata)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+
+	snapshot.Metadata.CommitHash = commitHash
+	snapshot.Metadata.CommitURL = newLinks(cfg, ver).Commit(commitHash)
+	if commitHash != "" {
+		if branch == "" && cfg.Tenancy.Mode == tenancyBranch {
+			mirrorTeams(cfg, ver, snapshot)
+		}
+		env := hooks.Env(cfg, &snapshot.Metadata, branch)
+		if err := hooks.Run(context.Background(), &cfg.Hooks, hooks.PostCommit, env); err != nil {
+			log.WithError(err).Warn("post-commit hook failed")
+		}
+		if cfg.Git.Push && branch == "" {
+			if watchPusher != nil {
+				watchPusher.Committed()
+			} else {
+				pushSnapshots(cfg, ver)
+			}
+		}
+	} else {
+		recordCheck(cfg, snapshot.Metadata.Timestamp)
+	}
+	if t := snapshot.Metadata.Timings; t != nil {
+		log.WithFields(log.Fields{
+			"collection":    t.Collection,
+			"serialization": t.Serialization,
+			"staging":       t.Staging,
+			"commit":        t.Commit,
+		}).Info("snapshot phase timings")
+	}
+	return nil
+}
+
+// pushSnapshots pushes the configured branch, within watch.push.timeout.
+// A failed push leaves the commit in place; it is pushed with the next
+// snapshot.
+func pushSnapshots(cfg *config.Config, ver *versioner.Versioner) {
+	ctx := context.Background()
+	if cfg.Watch.Push.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Watch.Push.Timeout)
+		defer cancel()
+	}
+	if err := ver.Push(ctx, tenantBranches(cfg)...); err != nil {
+		log.WithError(err).Warn("failed to push snapshots; they will be pushed with the next snapshot")
+	}
+}
+
+// mirrorTeams commits each team's directory of the snapshot just committed
+// to the team's branch in branch tenancy mode. A team whose branch misses
+// a snapshot catches up with the next one.
+func mirrorTeams(cfg *config.Config, ver *versioner.Versioner, snapshot *types.ResourceSnapshot) {
+	teams := make(map[string]bool)
+	for name := range cfg.Ownership.Teams {
+		teams[snapshotter.PartitionDir(name)] = true
+	}
+	partitionOf := teamPartition(cfg)
+	for _, res := range snapshot.Resources {
+		teams[snapshotter.PartitionDir(partitionOf(res))] = true
+	}
+	delete(teams, unownedPartition)
+
+	for dir := range teams {
+		branch := cfg.Tenancy.BranchPrefix + dir
+		if _, err := ver.MirrorDir(snapshot.Metadata.CommitHash, dir, branch); err != nil {
+			log.WithError(err).WithField("branch", branch).Warn("failed to commit the team's snapshot to its branch")
+		}
+	}
+}
+
+// tenantBranches returns the branches pushed along with the configured
+// branch: the per-team branches in branch tenancy mode.
+func tenantBranches(cfg *config.Config) []string {
+	if cfg.Tenancy.Mode != tenancyBranch {
+		return nil
+	}
+	return []string{cfg.Tenancy.BranchPrefix + "*"}
+}
+
+// writeSnapshot persists a snapshot using the configured tenancy layout.
+func writeSnapshot(cfg *config.Config, snapshot *types.ResourceSnapshot, progress *printer.Progress) error {
+	opts := snapshotOptions(cfg)
+	written := 0
+	opts.OnWrite = func() {
+		written++
+		progress.Update("writing", written, len(snapshot.Resources), "files")
+	}
+	defer progress.Done()
+	snap := snapshotter.NewWithOptions(cfg.Snapshot.OutputDir, opts)
+
+	switch cfg.Tenancy.Mode {
+	case "":
+		return snap.Write(snapshot)
+	case tenancyDirectory, tenancyBranch:
+		return snap.WritePartitioned(snapshot, teamPartition(cfg))
+	default:
+		return fmt.Errorf("unsupported tenancy mode %q (use %q or %q)", cfg.Tenancy.Mode, tenancyDirectory, tenancyBranch)
+	}
+}
+
+// partitioned reports whether snapshots are written one directory per team,
+// as in the directory and branch tenancy modes.
+func partitioned(cfg *config.Config) bool {
+	return cfg.Tenancy.Mode == tenancyDirectory || cfg.Tenancy.Mode == tenancyBranch
+}
+
+// teamPartition returns the partition function used in the directory and
+// branch tenancy modes.
+func teamPartition(cfg *config.Config) func(types.Resource) string {
+	resolver := ownership.New(&cfg.Ownership)
+	return func(res types.Resource) string {
+		if team := resolver.TeamFor(res); team != "" {
+			return team
+		}
+		return unownedPartition
+	}
+}
+
+// snapshotScope returns the repo-relative directory selected by --cluster or
+// --team, or "" for the whole repository. Team names are mapped to their
+// directory as WritePartitioned does.
+func snapshotScope(cfg *config.Config) (string, error) {
+	if cluster != "" {
+		return cluster, nil
+	}
+	if team == "" {
+		return "", nil
+	}
+	if !partitioned(cfg) {
+		return "", fmt.Errorf("--team requires tenancy.mode: %s or %s", tenancyDirectory, tenancyBranch)
+	}
+	return snapshotter.PartitionDir(team), nil
+}
+
+// scopedSnapshotter returns a Snapshotter rooted at the --cluster or --team
+// directory, if any.
+func scopedSnapshotter(cfg *config.Config) (*snapshotter.Snapshotter, error) {
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newSnapshotter(cfg, filepath.Join(cfg.Snapshot.OutputDir, scope)), nil
+}
+
+// newSnapshotter creates a Snapshotter for dir using the configured serialization options.
+func newSnapshotter(cfg *config.Config, dir string) *snapshotter.Snapshotter {
+	return snapshotter.NewWithOptions(dir, snapshotOptions(cfg))
+}
+
+// snapshotOptions returns the configured serialization options, with the
+// redacted configuration if snapshot.include_config is set.
+func snapshotOptions(cfg *config.Config) snapshotter.Options {
+	opts := snapshotter.Options{
+		Compression:          cfg.Snapshot.Compression.Algorithm,
+		CompressionThreshold: cfg.Snapshot.Compression.ThresholdBytes,
+		BlobThreshold:        cfg.Snapshot.BlobThresholdBytes,
+	}
+	if cfg.Snapshot.IncludeConfig {
+		data, err := config.RedactedYAML(cfg)
+		if err != nil {
+			log.WithError(err).Warn("failed to render configuration, leaving it out of the snapshot")
+		}
+		opts.Config = data
+	}
+	return opts
+}
+
+// newLinks returns the forge links of the snapshot repository. Links are
+// left out, rather than failing the command, if the remote can't be read.
+func newLinks(cfg *config.Config, ver *versioner.Versioner) *links.Links {
+	remoteURL, err := ver.RemoteURL(cfg.Git.Links.Remote)
+	if err != nil {
+		log.WithError(err).Debug("Could not read snapshot repository remote")
+	}
+	return links.New(&cfg.Git.Links, remoteURL)
+}
+
+// filterToTeam keeps only the resources that belong to the --team slice.
+func filterToTeam(cfg *config.Config, snapshot *types.ResourceSnapshot) {
+	if team == "" {
+		return
+	}
+	partitionOf := teamPartition(cfg)
+
+	var kept []types.Resource
+	for _, res := range snapshot.Resources {
+		if partitionOf(res) == team {
+			kept = append(kept, res)
+		}
+	}
+	snapshot.Resources = kept
+	snapshot.UpdateCounts()
+}
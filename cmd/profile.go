@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// selectProfile narrows the config to the profile named by --profile: its
+// context, kubeconfig, output directory, and remote.
+func selectProfile(cfg *config.Config) error {
+	if profile == "" {
+		return nil
+	}
+	for _, p := range cfg.Profiles {
+		if p.Name != profile {
+			continue
+		}
+		if p.Context != "" {
+			cfg.Context = p.Context
+		}
+		if p.Kubeconfig != "" {
+			cfg.Kubeconfig = p.Kubeconfig
+		}
+		cfg.Snapshot.OutputDir = p.OutputDir
+		if p.RemoteURL != "" {
+			cfg.Git.RemoteURL = p.RemoteURL
+		}
+		return nil
+	}
+	return fmt.Errorf("--profile %q is not one of the configured profiles", profile)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+)
+
+var quarantineOutput string
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Review snapshots held back by the watch gate",
+	Long: `Snapshots that fail the watch gate (policy violations or unusually large
+changes) are committed to the quarantine branch instead of the main
+history. Use these subcommands to review them and either accept them into
+the main history or discard them.`,
+}
+
+var quarantineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List quarantined snapshots awaiting review",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+
+		entries, err := ver.Pending(cfg.Watch.Gate.QuarantineBranch)
+		if err != nil {
+			return fmt.Errorf("failed to list quarantined snapshots: %w", err)
+		}
+
+		if isStructuredOutput(quarantineOutput) {
+			return printStructured(quarantineOutput, entries)
+		}
+		if quarantineOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", quarantineOutput)
+		}
+
+		if len(entries) == 0 {
+			printer.Success("No quarantined snapshots.")
+			return nil
+		}
+		printer.HistoryTable(entries, printer.TableOptions{Width: outputWidth(false)})
+		return nil
+	},
+}
+
+var quarantineShowCmd = &cobra.Command{
+	Use:   "show <commit>",
+	Short: "Show how a quarantined snapshot differs from the main history",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+		engine := timetravel.New(ver, newSnapshotter(cfg, cfg.Snapshot.OutputDir), cfg.Snapshot.OutputDir)
+
+		base, err := engine.SnapshotByCommit(cmd.Context(), cfg.Git.Branch)
+		if err != nil {
+			return fmt.Errorf("failed to load current snapshot: %w", err)
+		}
+		target, err := engine.SnapshotByCommit(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load quarantined snapshot: %w", err)
+		}
+
+		report, err := compareSnapshots(cmd.Context(), cfg, base, target)
+		if err != nil {
+			return err
+		}
+		report.BaseRef = cfg.Git.Branch
+		report.TargetRef = args[0]
+		return printDriftReport(cfg, report, render.FormatText, render.Options{Expand: true, Width: outputWidth(false)})
+	},
+}
+
+var quarantineAcceptCmd = &cobra.Command{
+	Use:   "accept <commit>",
+	Short: "Accept a quarantined snapshot into the main history",
+	Long: `Records the quarantined snapshot as the latest state of the main branch
+with a merge commit. The snapshot and any older quarantined snapshots it
+builds on are no longer pending.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+
+		hash, err := ver.ResolveRef(args[0])
+		if err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s accept quarantined snapshot %s", cfg.Git.CommitMessagePrefix, hash[:8])
+		merged, err := ver.Merge(hash, message)
+		if err != nil {
+			return fmt.Errorf("failed to accept snapshot: %w", err)
+		}
+		if cfg.Git.Push {
+			pushSnapshots(cfg, ver)
+		}
+
+		printer.Success(fmt.Sprintf("Accepted %s into %s as %s", hash[:8], cfg.Git.Branch, merged[:8]))
+		return nil
+	},
+}
+
+var quarantineDiscardCmd = &cobra.Command{
+	Use:   "discard",
+	Short: "Discard all quarantined snapshots",
+	Long: `Acknowledges every pending quarantined snapshot without accepting it,
+by resetting the quarantine branch to the main branch. If the live state
+still fails the gate, the next watch run quarantines it again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+
+		pending, err := ver.Pending(cfg.Watch.Gate.QuarantineBranch)
+		if err != nil {
+			return fmt.Errorf("failed to list quarantined snapshots: %w", err)
+		}
+		if len(pending) == 0 {
+			printer.Info("No quarantined snapshots to discard.")
+			return nil
+		}
+
+		if err := ver.ResetBranch(cfg.Watch.Gate.QuarantineBranch); err != nil {
+			return err
+		}
+		printer.Success(fmt.Sprintf("Discarded %d quarantined snapshot(s)", len(pending)))
+		return nil
+	},
+}
+
+func init() {
+	quarantineListCmd.Flags().StringVarP(&quarantineOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	quarantineCmd.AddCommand(quarantineListCmd, quarantineShowCmd, quarantineAcceptCmd, quarantineDiscardCmd)
+	rootCmd.AddCommand(quarantineCmd)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/rbac"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rbacDiffSelection snapshotSelection
+	rbacDiffSubject   string
+	rbacDiffOutput    string
+)
+
+var rbacDiffCmd = &cobra.Command{
+	Use:   "rbac-diff",
+	Short: "Show effective RBAC permission changes between two snapshots",
+	Long: `Flattens Roles, ClusterRoles, and their bindings into effective
+permissions and reports what each subject gained or lost, e.g.
+"ServiceAccount ci/deployer gained create on secrets in prod", instead of
+raw rule array diffs.`,
+	Example: `  # Permission changes since a commit
+  gitops-time-machine rbac-diff --commit HEAD~5
+
+  # Changes for one service account over a time range
+  gitops-time-machine rbac-diff --from "2024-01-01T00:00:00Z" --to "2024-01-08T00:00:00Z" --subject ci/deployer`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		fromSnapshot, toSnapshot, err := rbacDiffSelection.load(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
+
+		changes := rbac.FilterSubject(rbac.Diff(fromSnapshot, toSnapshot), rbacDiffSubject)
+
+		if isStructuredOutput(rbacDiffOutput) {
+			return printStructured(rbacDiffOutput, changes)
+		}
+		if rbacDiffOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", rbacDiffOutput)
+		}
+
+		printer.Banner()
+		printer.RBACChanges(changes)
+		return nil
+	},
+}
+
+func init() {
+	rbacDiffSelection.addFlags(rbacDiffCmd)
+	rbacDiffCmd.Flags().StringVar(&rbacDiffSubject, "subject", "", "only show subjects containing this text (e.g. ci/deployer)")
+	rbacDiffCmd.Flags().StringVarP(&rbacDiffOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(rbacDiffCmd)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// repoFlag is the --repo of the commands that read snapshot history, and
+// depthFlag and pathsFlag the --depth and --paths its URL is cloned with.
+var (
+	repoFlag  string
+	depthFlag int
+	pathsFlag []string
+)
+
+// addRepoFlag adds --repo, --depth, and --paths to a command that reads
+// snapshot history.
+func addRepoFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&repoFlag, "repo", "", "read snapshots from this repository (path or Git URL) instead of snapshot.output_dir")
+	cmd.Flags().IntVar(&depthFlag, "depth", 0, "fetch only the latest N commits of a --repo URL (default git.clone.depth)")
+	cmd.Flags().StringSliceVar(&pathsFlag, "paths", nil, "check out only these directories of a --repo URL (default git.clone.paths)")
+}
+
+// selectRepo points the config at the --repo repository, if any. A URL is
+// cloned into the user's cache directory, or fetched if it was cloned
+// before, so that a repository pushed from a cluster can be analysed
+// elsewhere. Either way the repository was chosen explicitly, so
+// git.remote_url is not enforced on it.
+func selectRepo(ctx context.Context, cfg *config.Config) error {
+	if repoFlag == "" {
+		return nil
+	}
+	cfg.Git.RemoteURL = ""
+	if !versioner.IsURL(repoFlag) {
+		if _, err := os.Stat(filepath.Join(repoFlag, ".git")); err != nil {
+			return fmt.Errorf("--repo %s is not a Git repository", repoFlag)
+		}
+		cfg.Snapshot.OutputDir = repoFlag
+		return nil
+	}
+
+	if depthFlag < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
+	if depthFlag > 0 {
+		cfg.Git.Clone.Depth = depthFlag
+	}
+	if len(pathsFlag) > 0 {
+		cfg.Git.Clone.Paths = pathsFlag
+		if err := versioner.ValidateClone(&cfg.Git.Clone); err != nil {
+			return fmt.Errorf("invalid --paths: %w", err)
+		}
+	}
+	dir := repoCacheDir(repoFlag)
+	log.WithFields(log.Fields{"url": repoFlag, "path": dir}).Info("updating local copy of snapshot repository")
+	if err := versioner.Clone(ctx, repoFlag, dir, &cfg.Git); err != nil {
+		return err
+	}
+	cfg.Snapshot.OutputDir = dir
+	return nil
+}
+
+// repoCacheDir returns where the repository at url is cloned: in the
+// user's cache directory if there is one, one directory per URL.
+func repoCacheDir(url string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, "gitops-time-machine", "repos", hex.EncodeToString(sum[:8]))
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/report"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSince   time.Duration
+	reportFormat  string
+	reportOut     string
+	reportDeliver bool
+
+	siteOut   string
+	siteLimit int
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate the drift and trend report for recent history",
+	Long: `Generates a report of how the cluster changed over recent history
+(report.period, or --since): snapshots and resource counts per day, the
+change in each kind's count, and the drift between the snapshot at the
+start of the period and the latest, most severe changes first.
+
+The report is rendered as Markdown or HTML (report.format, or --format)
+and printed, written to --out, or sent with --deliver to the configured
+notifiers: by email as an attachment, or uploaded to Slack as a file. The
+message itself lists the most severe changes and their field diffs, with
+secrets masked (see notifiers.detail).
+
+With report.schedule set, watch generates and delivers the report on that
+schedule without manual invocation.
+
+--format also accepts the other drift formats of diff (text, json, yaml, sarif,
+and junit), which render only the period's drift, e.g. for CI.`,
+	Example: `  # Print the last week's report as Markdown
+  gitops-time-machine report
+
+  # Write the last 30 days as HTML
+  gitops-time-machine report --since 720h --format html --out report.html
+
+  # Send the report to the configured notifiers now
+  gitops-time-machine report --deliver`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		period := cfg.Report.Period
+		if cmd.Flags().Changed("since") {
+			period = reportSince
+		}
+		if period <= 0 {
+			return fmt.Errorf("--since must be positive")
+		}
+		format := cfg.Report.Format
+		if reportFormat != "" {
+			format = reportFormat
+		}
+		trend := format == report.FormatMarkdown || format == report.FormatHTML
+		if !trend {
+			if _, err := render.New(format, render.Options{}); err != nil {
+				return err
+			}
+			if reportDeliver {
+				return fmt.Errorf("--deliver supports the markdown and html formats only")
+			}
+		}
+
+		now := time.Now()
+		r, err := generateReport(cmd.Context(), cfg, now.Add(-period), now)
+		if err != nil {
+			return err
+		}
+		data, err := renderReport(r, format)
+		if err != nil {
+			return err
+		}
+
+		if reportDeliver {
+			if err := deliverReport(cmd.Context(), cfg, r, format, data); err != nil {
+				return err
+			}
+			printer.Success("Report delivered.")
+		}
+		if reportOut != "" {
+			if err := os.WriteFile(reportOut, data, 0644); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+			printer.Success(fmt.Sprintf("Wrote %s", reportOut))
+		} else if !reportDeliver {
+			os.Stdout.Write(data)
+		}
+		return nil
+	},
+}
+
+var reportSiteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Export the snapshot history as a static website",
+	Long: `Renders the snapshot history as a static HTML site in --out: a timeline
+of snapshots with their changes, a page per snapshot with the drift from
+the one before it and its field diffs, and a page per resource with every
+change to it and its manifest in the latest snapshot.
+
+The pages only link to each other relatively, so the directory can be
+published as is to GitHub Pages, S3, or any web server, for stakeholders
+who should not need the CLI or access to the cluster. Values of Secrets,
+and of fields and env vars whose names look secret, are masked.
+
+Only the last --limit snapshots are included (0 for all of them). Files
+already in --out are overwritten but not removed.`,
+	Example: `  # Export the last 100 snapshots to ./public
+  gitops-time-machine report site --out ./public
+
+  # Export the whole history of another snapshot repository
+  gitops-time-machine report site --repo https://github.com/acme/snapshots.git --out ./public --limit 0`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if siteOut == "" {
+			return fmt.Errorf("--out is required")
+		}
+		if siteLimit < 0 {
+			return fmt.Errorf("--limit must not be negative")
+		}
+		site, err := generateSite(cmd.Context(), cfg, siteLimit)
+		if err != nil {
+			return err
+		}
+		if err := report.WriteSite(siteOut, site); err != nil {
+			return err
+		}
+		printer.Success(fmt.Sprintf("Wrote %d snapshots to %s", len(site.Snapshots), siteOut))
+		return nil
+	},
+}
+
+// renderReport renders the report as Markdown or HTML, or its drift in
+// any other format of the render package.
+func renderReport(r *report.Report, format string) ([]byte, error) {
+	if format == report.FormatMarkdown || format == report.FormatHTML {
+		return report.Render(r, format)
+	}
+	renderer, err := render.New(format, render.Options{})
+	if err != nil {
+		return nil, err
+	}
+	drift := r.Drift
+	if drift == nil {
+		drift = &types.DriftReport{}
+	}
+	var b bytes.Buffer
+	if err := renderer.Render(&b, drift); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// generateSite builds the site of the last limit snapshots (0 for all),
+// each with its drift from the snapshot before it.
+func generateSite(ctx context.Context, cfg *config.Config, limit int) (*report.Site, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// One more snapshot than shown, to compare the oldest shown with
+	read := 0
+	if limit > 0 {
+		read = limit + 1
+	}
+	history, err := ver.HistoryIn(scope, read)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	site := &report.Site{Title: "Snapshot history", Generated: time.Now()}
+	if len(history) > 0 && history[0].ClusterName != "" {
+		site.Title += " of " + history[0].ClusterName
+	}
+	var previous *types.ResourceSnapshot
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		snapshot, err := timetravel.ReadCommit(ctx, ver, entry, scope, nil)
+		if err != nil {
+			return nil, err
+		}
+		shown := limit == 0 || i < limit
+		if shown {
+			s := report.SiteSnapshot{Entry: entry}
+			if previous != nil {
+				if s.Drift, err = compareCommits(ctx, cfg, previous, snapshot); err != nil {
+					return nil, err
+				}
+				s.Drift.Timestamp = entry.Timestamp
+				s.Drift.BaseRef = previous.Metadata.CommitHash
+				s.Drift.TargetRef = entry.CommitHash
+				linkReport(cfg, s.Drift)
+				if err := annotateReport(cfg, s.Drift); err != nil {
+					return nil, err
+				}
+			}
+			site.Snapshots = append([]report.SiteSnapshot{s}, site.Snapshots...)
+		}
+		previous = snapshot
+	}
+	site.Latest = previous
+	return site, nil
+}
+
+// generateReport builds the report for the period [from, to) from the
+// snapshot history. Snapshots are read from their commits, so this is safe
+// to run while watch is writing new ones.
+func generateReport(ctx context.Context, cfg *config.Config, from, to time.Time) (*report.Report, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	scope, err := snapshotScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+	history, err := ver.HistoryIn(scope, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	// The drift compares the state at the start of the period (the last
+	// snapshot before it, or failing that its first) with the latest.
+	var base, latest *types.HistoryEntry
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := &history[i]
+		if !entry.Timestamp.Before(to) {
+			break
+		}
+		if base == nil || entry.Timestamp.Before(from) {
+			base = entry
+		}
+		latest = entry
+	}
+
+	var drift *types.DriftReport
+	if base != nil && latest != nil && base.CommitHash != latest.CommitHash && !latest.Timestamp.Before(from) {
+		baseSnapshot, err := timetravel.ReadCommit(ctx, ver, *base, scope, nil)
+		if err != nil {
+			return nil, err
+		}
+		targetSnapshot, err := timetravel.ReadCommit(ctx, ver, *latest, scope, nil)
+		if err != nil {
+			return nil, err
+		}
+		drift, err = compareCommits(ctx, cfg, baseSnapshot, targetSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		drift.Timestamp = latest.Timestamp
+		drift.BaseRef = base.CommitHash
+		drift.TargetRef = latest.CommitHash
+		linkReport(cfg, drift)
+		if err := annotateReport(cfg, drift); err != nil {
+			return nil, err
+		}
+	}
+	return report.Build(history, from, to, drift), nil
+}
+
+// deliverReport sends a rendered report to every configured notifier, with
+// the most severe changes inline as notifiers.detail asks.
+func deliverReport(ctx context.Context, cfg *config.Config, r *report.Report, format string, data []byte) error {
+	notifiers := notifier.New(&cfg.Notifiers)
+	if len(notifiers) == 0 {
+		return fmt.Errorf("no notifiers configured (see notifiers.email, notifiers.slack, and notifiers.webhook)")
+	}
+
+	name, contentType := "drift-report-"+r.To.UTC().Format("2006-01-02"), ""
+	switch format {
+	case report.FormatHTML:
+		name, contentType = name+".html", "text/html; charset=utf-8"
+	default:
+		name, contentType = name+".md", "text/markdown; charset=utf-8"
+	}
+	body := r.Headline()
+	if detail := notifier.DriftDetail(&cfg.Notifiers, r.Drift); detail != "" {
+		body += "\n\n" + detail
+	}
+	return notifier.SendAll(ctx, notifiers, &notifier.Message{
+		Subject:     r.Title(),
+		Body:        body,
+		Attachments: []notifier.Attachment{{Name: name, ContentType: contentType, Data: data}},
+	})
+}
+
+// scheduledReport generates and delivers the report for the configured
+// period ending now; it runs on report.schedule while watch runs.
+func scheduledReport(ctx context.Context, cfg *config.Config) error {
+	now := time.Now()
+	r, err := generateReport(ctx, cfg, now.Add(-cfg.Report.Period), now)
+	if err != nil {
+		return err
+	}
+	data, err := report.Render(r, cfg.Report.Format)
+	if err != nil {
+		return err
+	}
+	if err := deliverReport(ctx, cfg, r, cfg.Report.Format, data); err != nil {
+		return err
+	}
+	log.WithField("snapshots", r.Snapshots).Info("scheduled report delivered")
+	return nil
+}
+
+func init() {
+	reportCmd.Flags().DurationVar(&reportSince, "since", 0, "how much recent history to cover (default: report.period)")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "", "report format: markdown or html, or a drift format of diff (default: report.format)")
+	reportCmd.Flags().StringVar(&reportOut, "out", "", "write the report to a file instead of printing it")
+	reportCmd.Flags().BoolVar(&reportDeliver, "deliver", false, "send the report to the configured notifiers")
+
+	reportSiteCmd.Flags().StringVar(&siteOut, "out", "", "directory to write the site to")
+	reportSiteCmd.Flags().IntVar(&siteLimit, "limit", 100, "number of most recent snapshots to include (0 for all)")
+	addRepoFlag(reportSiteCmd)
+	reportCmd.AddCommand(reportSiteCmd)
+
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resourceHistoryLimit  int
+	resourceHistoryOutput string
+	resourceHistoryWide   bool
+)
+
+var resourceHistoryCmd = &cobra.Command{
+	Use:   "resource-history <namespace/Kind/name>",
+	Short: "Show every change to one resource, with field diffs",
+	Long: `Walks the history of one resource's file in the snapshot repository and
+lists every snapshot in which it was added, changed, or removed, with the
+fields that changed since the version before. Cluster-scoped resources
+are named Kind/name.
+
+Only the commits touching the resource's file are read. Fields ignored by
+the ignore settings are not compared, so versions that differ only in
+them are not listed.`,
+	Example: `  # Every change to a Deployment
+  gitops-time-machine resource-history default/Deployment/api
+
+  # The last 5 changes to a ClusterRole, as JSON
+  gitops-time-machine resource-history ClusterRole/admin --limit 5 -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(resourceHistoryOutput) && resourceHistoryOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", resourceHistoryOutput)
+		}
+		if resourceHistoryLimit < 0 {
+			return fmt.Errorf("--limit must not be negative")
+		}
+		namespace, kind, name, err := types.ParseFullName(args[0])
+		if err != nil {
+			return err
+		}
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+		scope, err := snapshotScope(cfg)
+		if err != nil {
+			return err
+		}
+		tt := timetravel.New(ver, newSnapshotter(cfg, filepath.Join(cfg.Snapshot.OutputDir, scope)), cfg.Snapshot.OutputDir)
+
+		versions, err := tt.Timeline(cmd.Context(), scope, namespace, kind, name)
+		if err != nil {
+			return err
+		}
+		changes := analyzer.History(withoutIgnored(cfg.Ignore, versions))
+		if len(changes) == 0 {
+			return fmt.Errorf("%s does not appear in any snapshot", args[0])
+		}
+		if resourceHistoryLimit > 0 && len(changes) > resourceHistoryLimit {
+			changes = changes[len(changes)-resourceHistoryLimit:]
+		}
+
+		if isStructuredOutput(resourceHistoryOutput) {
+			return printStructured(resourceHistoryOutput, changes)
+		}
+		printer.Banner()
+		printer.ResourceHistory(args[0], changes, outputWidth(resourceHistoryWide))
+		return nil
+	},
+}
+
+// withoutIgnored returns the versions with the fields of the ignore
+// settings removed, as compareSnapshots removes them before diffing.
+func withoutIgnored(cfg config.IgnoreConfig, versions []types.ResourceVersion) []types.ResourceVersion {
+	var present types.ResourceSnapshot
+	for _, v := range versions {
+		if v.Resource != nil {
+			present.Resources = append(present.Resources, *v.Resource)
+		}
+	}
+	stripped, _, n := ignore.Apply(&cfg, &present, &types.ResourceSnapshot{})
+	if n == 0 {
+		return versions
+	}
+
+	out := make([]types.ResourceVersion, len(versions))
+	i := 0
+	for j, v := range versions {
+		if v.Resource != nil {
+			v.Resource = &stripped.Resources[i]
+			i++
+		}
+		out[j] = v
+	}
+	return out
+}
+
+func init() {
+	resourceHistoryCmd.Flags().IntVar(&resourceHistoryLimit, "limit", 0, "show only the latest N changes (0 for all)")
+	resourceHistoryCmd.Flags().StringVarP(&resourceHistoryOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+	resourceHistoryCmd.Flags().BoolVar(&resourceHistoryWide, "wide", false, "print values in full instead of fitting the terminal width")
+	addRepoFlag(resourceHistoryCmd)
+
+	rootCmd.AddCommand(resourceHistoryCmd)
+}
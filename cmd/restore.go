@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/internal/prompt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/audit"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/render"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreCommit      string
+	restoreAt          string
+	restoreNamespace   string
+	restoreKind        string
+	restoreName        string
+	restoreOutput      string
+	restoreInteractive bool
+	restoreNoVerify    bool
+	restoreYes         bool
+	restoreOpts        = restorer.Options{FieldManager: restorer.DefaultFieldManager}
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Re-apply resources from a historical snapshot to the cluster",
+	Long: `Restores resources as they were in a past snapshot using server-side
+apply with the field manager "gitops-time-machine". Server-owned fields
+such as uid, resourceVersion, and clusterIP are removed first; immutable
+fields that are kept are reported as warnings.
+
+When a field is owned by another manager (e.g. ArgoCD or Flux), the apply
+fails with a conflict. Use --force-conflicts to take ownership of those
+fields, or --skip-conflicts to leave such resources untouched.
+
+Before restoring, the live state is collected and compared with the
+snapshot, the changes the restore would make are shown, and you are asked
+to confirm; only the resources that differ are applied. Pass --yes to skip
+this (required when stdin is not a terminal). With --interactive, you pick
+which of the differing resources to restore instead. --kind takes a kind
+or its resource name, e.g. Deployment or deployments.
+
+After a successful restore a fresh snapshot is taken and committed, and
+every applied resource is compared with the restore target. Resources that
+did not converge (e.g. mutated back by an admission webhook, or an
+immutable field that could not change) are reported and the command exits
+non-zero. Use --no-verify to skip this step.
+
+Resources that did not exist in the snapshot are not deleted. Every
+restore, including dry runs, is recorded in the audit log (audit.file).
+The same restore is available over the API as POST /api/restore (serve).`,
+	Example: `  # Preview restoring one namespace to a commit
+  gitops-time-machine restore --commit HEAD~3 --namespace prod --dry-run
+
+  # Restore a single Deployment as of a point in time
+  gitops-time-machine restore --at "2024-01-15T10:00:00Z" --kind Deployment --name web --namespace prod
+
+  # Roll back every Deployment in prod from a script, without the prompt
+  gitops-time-machine restore --commit abc1234 --namespace prod --kind deployments --yes
+
+  # Choose which drifted resources in prod to roll back
+  gitops-time-machine restore --commit HEAD~1 --namespace prod --interactive
+
+  # Restore, leaving fields managed by a GitOps controller alone
+  gitops-time-machine restore --commit a1b2c3d --skip-conflicts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if err := restoreOpts.Validate(); err != nil {
+			return err
+		}
+		if !isStructuredOutput(restoreOutput) && restoreOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", restoreOutput)
+		}
+
+		if restoreCommit == "" && restoreAt == "" {
+			return fmt.Errorf("specify --commit or --at")
+		}
+		target, err := loadSnapshot(cmd.Context(), cfg, restoreCommit, restoreAt)
+		if err != nil {
+			return err
+		}
+		resources := filterRestore(target.Resources)
+		if len(resources) == 0 {
+			return fmt.Errorf("no resources in snapshot %s match the filters", target.Metadata.CommitHash[:8])
+		}
+
+		structured := isStructuredOutput(restoreOutput)
+		switch {
+		case restoreInteractive:
+			resources, err = selectRestore(cmd.Context(), cfg, resources)
+			if err != nil {
+				return err
+			}
+			if len(resources) == 0 {
+				printer.Info("Nothing selected; no resources were restored.")
+				return nil
+			}
+		case !restoreOpts.DryRun && !restoreYes:
+			if !prompt.IsTerminal() {
+				return fmt.Errorf("restore changes the cluster and needs confirmation: run it in a terminal, or pass --yes")
+			}
+			resources, err = confirmRestore(cmd.Context(), cfg, resources, target.Metadata.CommitHash[:8], !structured)
+			if err != nil || len(resources) == 0 {
+				return err
+			}
+		}
+
+		if !structured {
+			printer.Banner()
+			printer.Info(fmt.Sprintf("Restoring %d resource(s) from snapshot %s", len(resources), target.Metadata.CommitHash[:8]))
+		}
+
+		report, err := runRestore(cmd.Context(), cfg, resources, restoreOpts, !restoreNoVerify, !structured)
+		if report != nil {
+			report.Commit = target.Metadata.CommitHash
+		}
+		request := restorer.Request{
+			Commit:         restoreCommit,
+			At:             restoreAt,
+			Namespace:      restoreNamespace,
+			Kind:           restoreKind,
+			Name:           restoreName,
+			DryRun:         restoreOpts.DryRun,
+			ForceConflicts: restoreOpts.ForceConflicts,
+			SkipConflicts:  restoreOpts.SkipConflicts,
+			NoVerify:       restoreNoVerify,
+		}
+		auditRestore(cfg, audit.CurrentUser(), audit.SourceCLI, request, report, err)
+		if err != nil {
+			return err
+		}
+
+		if structured {
+			if err := printStructured(restoreOutput, report); err != nil {
+				return err
+			}
+		} else {
+			printer.RestoreResults(report.Results, restoreOpts.DryRun)
+			if report.Verified {
+				printer.RestoreVerification(report.Unconverged, report.Count(restorer.StatusApplied))
+			}
+		}
+
+		if failed := report.Count(restorer.StatusFailed); failed > 0 {
+			return fmt.Errorf("%d resource(s) failed to restore", failed)
+		}
+		if len(report.Unconverged) > 0 {
+			return fmt.Errorf("%d restored resource(s) did not converge", len(report.Unconverged))
+		}
+		return nil
+	},
+}
+
+// runRestore applies resources and, if verify is set and a resource was
+// applied for real, takes a verification snapshot and compares the applied
+// resources with it. The restore command and the API share it.
+func runRestore(ctx context.Context, cfg *config.Config, resources []types.Resource, opts restorer.Options, verify, showProgress bool) (*restorer.Report, error) {
+	r, err := restorer.New(cfg, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restorer: %w", err)
+	}
+	results, err := r.Restore(ctx, resources)
+	if err != nil {
+		return nil, fmt.Errorf("restore interrupted: %w", err)
+	}
+
+	report := &restorer.Report{Results: results}
+	var applied []types.Resource
+	for i, result := range results {
+		if result.Status == restorer.StatusApplied {
+			applied = append(applied, resources[i])
+		}
+	}
+	if opts.DryRun || !verify || len(applied) == 0 {
+		return report, nil
+	}
+
+	if showProgress {
+		printer.Info("Taking a verification snapshot...")
+	}
+	live, err := captureSnapshot(ctx, cfg, printer.NewProgress(!noProgress && showProgress))
+	if err = allowPartial(err); err != nil {
+		return nil, fmt.Errorf("failed to take verification snapshot: %w", err)
+	}
+	report.Unconverged = restorer.Verify(&cfg.Ignore, applied, live)
+	report.Verified = true
+	return report, nil
+}
+
+// restoreAudit is the audit log entry of a restore.
+type restoreAudit struct {
+	Request     restorer.Request `json:"request"`
+	Commit      string           `json:"commit,omitempty"`
+	Applied     int              `json:"applied"`
+	Skipped     int              `json:"skipped"`
+	Failed      int              `json:"failed"`
+	Unconverged int              `json:"unconverged"`
+}
+
+// auditRestore records a restore, or an attempt that failed, in the audit
+// log. Failing to record it is logged, since the restore already happened.
+func auditRestore(cfg *config.Config, actor, source string, req restorer.Request, report *restorer.Report, restoreErr error) {
+	details := restoreAudit{Request: req}
+	if report != nil {
+		details.Commit = report.Commit
+		details.Applied = report.Count(restorer.StatusApplied)
+		details.Skipped = report.Count(restorer.StatusSkipped)
+		details.Failed = report.Count(restorer.StatusFailed)
+		details.Unconverged = len(report.Unconverged)
+	}
+	event := audit.Event{Action: "restore", Actor: actor, Source: source, Details: details}
+	if restoreErr != nil {
+		event.Error = restoreErr.Error()
+	}
+	if err := audit.Record(&cfg.Audit, event); err != nil {
+		log.WithError(err).Error("failed to record restore in the audit log")
+	}
+}
+
+// selectRestore shows how the live state differs from the restore target and
+// lets the user pick which resources to restore. Resources that already match
+// the live state are not offered.
+func selectRestore(ctx context.Context, cfg *config.Config, resources []types.Resource) ([]types.Resource, error) {
+	report, err := restoreDrift(ctx, cfg, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]types.Resource, len(resources))
+	for _, res := range resources {
+		byName[res.FullName()] = res
+	}
+
+	var candidates []types.Resource
+	var items []string
+	for _, entry := range report.Entries {
+		res := byName[entry.Resource.FullName()]
+		label := fmt.Sprintf("%-9s %s", entry.Type, res.FullName())
+		if n := len(entry.FieldDiffs); n > 0 {
+			label += fmt.Sprintf(" (%d field(s))", n)
+		}
+		candidates = append(candidates, res)
+		items = append(items, label)
+	}
+
+	if len(candidates) == 0 {
+		printer.Success("The live state already matches the snapshot for the selected resources.")
+		return nil, nil
+	}
+
+	chosen, err := prompt.Checklist("Select resources to restore (changes relative to the live state):", items)
+	if errors.Is(err, prompt.ErrAborted) {
+		return nil, fmt.Errorf("restore cancelled")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]types.Resource, 0, len(chosen))
+	for _, i := range chosen {
+		selected = append(selected, candidates[i])
+	}
+	return selected, nil
+}
+
+// confirmRestore shows how restoring the resources would change the live
+// state (if preview is set) and asks for confirmation. It returns the
+// resources that differ from the live state: none if all of them match.
+func confirmRestore(ctx context.Context, cfg *config.Config, resources []types.Resource, commit string, preview bool) ([]types.Resource, error) {
+	report, err := restoreDrift(ctx, cfg, resources)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Entries) == 0 {
+		printer.Success("The live state already matches the snapshot for the selected resources.")
+		return nil, nil
+	}
+	if preview {
+		report.BaseRef, report.TargetRef = "live", commit
+		if err := printDriftReport(cfg, report, render.FormatText, render.Options{Width: outputWidth(false)}); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := prompt.Confirm(fmt.Sprintf("Restore %d resource(s) to the cluster?", len(report.Entries)))
+	if errors.Is(err, prompt.ErrAborted) || (err == nil && !ok) {
+		return nil, fmt.Errorf("restore cancelled")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]types.Resource, len(resources))
+	for _, res := range resources {
+		byName[res.FullName()] = res
+	}
+	changed := make([]types.Resource, 0, len(report.Entries))
+	for _, entry := range report.Entries {
+		changed = append(changed, byName[entry.Resource.FullName()])
+	}
+	return changed, nil
+}
+
+// restoreDrift compares the live state of the resources with the state
+// they would be restored to. Live resources that are not being restored
+// are left out, since restore never deletes.
+func restoreDrift(ctx context.Context, cfg *config.Config, resources []types.Resource) (*types.DriftReport, error) {
+	live, err := collectSnapshot(ctx, cfg, printer.NewProgress(!noProgress))
+	if err = allowPartial(err); err != nil {
+		return nil, err
+	}
+	filterToTeam(cfg, live)
+
+	restoring := make(map[string]bool, len(resources))
+	for _, res := range resources {
+		restoring[res.FullName()] = true
+	}
+	var kept []types.Resource
+	for _, res := range live.Resources {
+		if restoring[res.FullName()] {
+			kept = append(kept, res)
+		}
+	}
+	live.Resources = kept
+
+	return newAnalyzer(cfg).CompareContext(ctx, live, &types.ResourceSnapshot{Resources: resources})
+}
+
+// filterRestore keeps the resources matching --namespace, --kind, and --name.
+func filterRestore(resources []types.Resource) []types.Resource {
+	scope := restorer.Request{Namespace: restoreNamespace, Kind: restoreKind, Name: restoreName}
+	var kept []types.Resource
+	for _, res := range resources {
+		if scope.Matches(res) {
+			kept = append(kept, res)
+		}
+	}
+	return kept
+}
+
+func init() {
+	restoreCmd.Flags().StringVar(&restoreCommit, "commit", "", "restore from a commit, branch, tag, or revision")
+	restoreCmd.Flags().StringVar(&restoreAt, "at", "", "restore from the snapshot at this time (RFC3339 format)")
+	restoreCmd.Flags().StringVar(&restoreNamespace, "namespace", "", "only restore resources in this namespace")
+	restoreCmd.Flags().StringVar(&restoreKind, "kind", "", "only restore resources of this kind (e.g. Deployment)")
+	restoreCmd.Flags().StringVar(&restoreName, "name", "", "only restore resources with this name")
+	restoreCmd.Flags().BoolVarP(&restoreInteractive, "interactive", "i", false, "pick the resources to restore from their drift against the live state")
+	restoreCmd.Flags().BoolVar(&restoreNoVerify, "no-verify", false, "skip the verification snapshot after restoring")
+	restoreCmd.Flags().BoolVarP(&restoreYes, "yes", "y", false, "restore without previewing the changes and asking for confirmation")
+	restoreCmd.Flags().BoolVar(&restoreOpts.DryRun, "dry-run", false, "validate the apply on the server without persisting changes")
+	restoreCmd.Flags().BoolVar(&restoreOpts.ForceConflicts, "force-conflicts", false, "take ownership of fields managed by other field managers")
+	restoreCmd.Flags().BoolVar(&restoreOpts.SkipConflicts, "skip-conflicts", false, "leave resources with conflicting fields untouched")
+	restoreCmd.Flags().StringVar(&restoreOpts.FieldManager, "field-manager", restorer.DefaultFieldManager, "server-side apply field manager")
+	restoreCmd.Flags().StringVarP(&restoreOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+	addRepoFlag(restoreCmd)
+
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planCommit    string
+	planAt        string
+	planNamespace string
+	planKind      string
+	planName      string
+	planOut       string
+	planSplit     bool
+	planScript    string
+)
+
+var rollbackPlanCmd = &cobra.Command{
+	Use:   "rollback-plan",
+	Short: "Write the manifests that would restore a past snapshot, without applying them",
+	Long: `Writes the resources of the snapshot at --commit (or --at a time) as
+apply-ready manifests, for review and apply by a human where the tool may
+not change the cluster itself. The cluster is not contacted.
+
+Manifests are prepared as restore prepares them: status and server-owned
+fields are removed, and immutable fields that were kept are flagged with a
+WARNING comment. They are ordered so that namespaces, CRDs, RBAC, and
+configuration come before the workloads that use them. Resources whose env
+values were redacted (snapshot.redact_env) cannot be restored from the
+snapshot and are left out.
+
+The plan is one multi-document YAML file (--out, or stdout), or with
+--split a directory of one file per resource, numbered in apply order.
+--script also writes a shell script that runs kubectl apply --server-side
+on the plan, after a server-side dry run.`,
+	Example: `  # Review the manifests of yesterday's prod namespace
+  gitops-time-machine rollback-plan --at 2026-03-01T09:00:00Z --namespace prod > plan.yaml
+
+  # A directory of manifests and the script to apply them
+  gitops-time-machine rollback-plan --commit a1b2c3d --out ./rollback --split --script ./rollback.sh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if planCommit == "" && planAt == "" {
+			return fmt.Errorf("specify --commit or --at")
+		}
+		if planSplit && planOut == "" {
+			return fmt.Errorf("--split needs --out for the directory")
+		}
+		if planScript != "" && planOut == "" {
+			return fmt.Errorf("--script needs --out for the plan it applies")
+		}
+
+		snapshot, err := loadSnapshot(cmd.Context(), cfg, planCommit, planAt)
+		if err != nil {
+			return err
+		}
+		scope := restorer.Request{Namespace: planNamespace, Kind: planKind, Name: planName}
+		var resources = snapshot.Resources[:0:0]
+		for _, res := range snapshot.Resources {
+			if scope.Matches(res) {
+				resources = append(resources, res)
+			}
+		}
+		if len(resources) == 0 {
+			return fmt.Errorf("no resources in snapshot %s match the filters", snapshot.Metadata.CommitHash[:8])
+		}
+		plan := restorer.NewPlan(snapshot.Metadata.CommitHash, resources)
+
+		switch {
+		case planSplit:
+			if entries, err := os.ReadDir(planOut); err == nil && len(entries) > 0 {
+				return fmt.Errorf("output directory %s is not empty", planOut)
+			}
+			err = plan.WriteDir(planOut)
+		default:
+			var bundle []byte
+			if bundle, err = plan.Bundle(); err != nil {
+				return err
+			}
+			if planOut == "" {
+				_, err = os.Stdout.Write(bundle)
+			} else {
+				err = os.WriteFile(planOut, bundle, 0644)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+
+		if planScript != "" {
+			target, err := filepath.Rel(filepath.Dir(planScript), planOut)
+			if err != nil {
+				return fmt.Errorf("failed to locate the plan from the script: %w", err)
+			}
+			script := plan.Script(target, cfg.Context, restorer.DefaultFieldManager)
+			if err := os.WriteFile(planScript, []byte(script), 0755); err != nil {
+				return fmt.Errorf("failed to write script: %w", err)
+			}
+		}
+
+		skipped := make([]string, 0, len(plan.Skipped))
+		for name := range plan.Skipped {
+			skipped = append(skipped, name)
+		}
+		sort.Strings(skipped)
+		for _, name := range skipped {
+			msg := fmt.Sprintf("Left out %s: %s", name, plan.Skipped[name])
+			if planOut == "" {
+				// Keep stdout for the plan itself
+				fmt.Fprintln(os.Stderr, "warning: "+msg)
+				continue
+			}
+			printer.Warning(msg)
+		}
+		if planOut != "" {
+			printer.Success(fmt.Sprintf("Wrote a plan of %d resources from snapshot %s to %s", len(plan.Manifests), snapshot.Metadata.CommitHash[:8], planOut))
+		}
+		return nil
+	},
+}
+
+func init() {
+	rollbackPlanCmd.Flags().StringVar(&planCommit, "commit", "", "plan the snapshot at a commit, branch, tag, or revision")
+	rollbackPlanCmd.Flags().StringVar(&planAt, "at", "", "plan the snapshot at this time (RFC3339 format)")
+	rollbackPlanCmd.Flags().StringVar(&planNamespace, "namespace", "", "only plan resources in this namespace")
+	rollbackPlanCmd.Flags().StringVar(&planKind, "kind", "", "only plan resources of this kind (e.g. Deployment)")
+	rollbackPlanCmd.Flags().StringVar(&planName, "name", "", "only plan resources with this name")
+	rollbackPlanCmd.Flags().StringVar(&planOut, "out", "", "write the plan to this file, or directory with --split (default: stdout)")
+	rollbackPlanCmd.Flags().BoolVar(&planSplit, "split", false, "write one file per resource to the --out directory")
+	rollbackPlanCmd.Flags().StringVar(&planScript, "script", "", "also write a kubectl apply script for the plan to this file")
+	addRepoFlag(rollbackPlanCmd)
+
+	rootCmd.AddCommand(rollbackPlanCmd)
+}
@@ -2,12 +2,34 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/internal/logger"
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/anomaly"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/debounce"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/gaps"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/links"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/managedby"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/metrics"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/orphans"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/pushbatch"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/remediation"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/report"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/server"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/storm"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/suppression"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
 	"github.com/spf13/cobra"
 )
 
@@ -15,6 +37,12 @@ var (
 	cfgFile    string
 	kubeconfig string
 	verbose    bool
+	team       string
+	cluster    string
+	profile    string
+	noProgress bool
+	useUTC     bool
+	useLocal   bool
 	cfg        *config.Config
 	version    string
 	buildTime  string
@@ -37,16 +65,116 @@ and drift analysis.
 Capture snapshots, detect drift, and travel back in time to see
 exactly what your infrastructure looked like at any point.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if useUTC && useLocal {
+			return fmt.Errorf("--utc and --local are mutually exclusive")
+		}
+		if useUTC {
+			printer.SetTimeLocation(time.UTC)
+		}
+
 		var err error
 		cfg, err = config.Load(cfgFile)
 		if err != nil {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
+		if err := suppression.Validate(&cfg.Suppression); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := policy.Validate(&cfg.Watch.Gate); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := anomaly.Validate(&cfg.Watch.Anomaly); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := storm.Validate(&cfg.Watch.Storm); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := debounce.Validate(&cfg.Watch); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := gaps.Validate(&cfg.Watch.Gaps); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := metrics.Validate(cfg.Watch.MetricsAddr); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := remediation.Validate(&cfg.Watch.Remediation); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := pushbatch.Validate(&cfg.Watch.Push); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := collector.ValidateRedactEnv(cfg.Snapshot.RedactEnv); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := collector.ValidateConcurrency(cfg.Snapshot.Concurrency); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := collector.ValidatePageSize(cfg.Snapshot.PageSize); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := collector.ValidatePrune(cfg.Snapshot.Prune); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := collector.ValidateDiscovery(&cfg.Snapshot.Discovery); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := managedby.Validate(&cfg.IgnoreManaged); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := ignore.Validate(&cfg.Ignore); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := orphans.Validate(&cfg.Orphans); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := policy.ValidateImages(&cfg.ImagePolicy); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := versioner.ValidateAuth(&cfg.Git.Auth); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := versioner.ValidateClone(&cfg.Git.Clone); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := links.Validate(&cfg.Git.Links); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := report.Validate(&cfg.Report); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := notifier.Validate(&cfg.Notifiers); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := server.Validate(&cfg.Serve); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := config.ValidateClusters(cfg.Clusters); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := config.ValidateProfiles(cfg.Profiles); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if err := config.ValidateTenancy(&cfg.Tenancy); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if len(cfg.Clusters) > 0 && cfg.Tenancy.Mode != "" {
+			return fmt.Errorf("invalid config: clusters cannot be combined with tenancy.mode")
+		}
+
+		// Apply the profile first so that --kubeconfig overrides it
+		if err := selectProfile(cfg); err != nil {
+			return err
+		}
+
 		// Override kubeconfig if provided via flag
 		if kubeconfig != "" {
 			cfg.Kubeconfig = kubeconfig
 		}
+		if err := selectCluster(cfg); err != nil {
+			return err
+		}
 
 		// Set log level
 		logLevel := cfg.Log.Level
@@ -54,8 +182,22 @@ exactly what your infrastructure looked like at any point.`,
 			logLevel = "debug"
 		}
 		logger.Init(logLevel, cfg.Log.Format)
+		if cfg.Log.File != "" {
+			if cfg.Log.MaxSizeMB < 0 || cfg.Log.MaxAgeDays < 0 || cfg.Log.MaxBackups < 0 {
+				return fmt.Errorf("invalid config: log rotation settings must not be negative")
+			}
+			rotation := logger.Rotation{
+				MaxSize:    int64(cfg.Log.MaxSizeMB) << 20,
+				MaxAge:     time.Duration(cfg.Log.MaxAgeDays) * 24 * time.Hour,
+				MaxBackups: cfg.Log.MaxBackups,
+			}
+			if err := logger.AddFile(cfg.Log.File, cfg.Log.Format, rotation); err != nil {
+				return fmt.Errorf("failed to set up log file: %w", err)
+			}
+		}
 
-		return nil
+		// After logging is set up, since cloning a --repo URL logs
+		return selectRepo(cmd.Context(), cfg)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		printer.Banner()
@@ -66,15 +208,29 @@ exactly what your infrastructure looked like at any point.`,
 	},
 }
 
-// Execute runs the root command.
+// Execute runs the root command. Commands run with a context that is
+// cancelled on Ctrl+C or SIGTERM, so that long diffs and restores stop at
+// the next file, commit, or API call; a second Ctrl+C exits immediately.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ./config.yaml)")
 	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to kubeconfig file")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose/debug output")
+	rootCmd.PersistentFlags().StringVar(&team, "team", "", "restrict to a team's slice (requires tenancy.mode: directory or branch)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "use a configured profile's context, output directory, and remote")
+	rootCmd.PersistentFlags().StringVar(&cluster, "cluster", "", "restrict to one cluster of the fleet (requires clusters in config)")
+	rootCmd.PersistentFlags().BoolVar(&useUTC, "utc", false, "print timestamps in UTC")
+	rootCmd.PersistentFlags().BoolVar(&useLocal, "local", false, "print timestamps in the local time zone (default)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "disable progress output for long snapshot runs (e.g. in CI)")
 
 	// Add version command
 	rootCmd.AddCommand(&cobra.Command{
@@ -98,5 +254,5 @@ func getConfig() *config.Config {
 // exitOnError prints an error message and exits.
 func exitOnError(err error) {
 	printer.Error(err.Error())
-	os.Exit(1)
+	os.Exit(ExitCode(err))
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/routing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	routesCommit string
+	routesAt     string
+	routesHost   string
+	routesOutput string
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "Show the traffic routing table of a snapshot",
+	Long: `Derives a routing table from the Ingresses and Gateway API HTTPRoutes
+in a snapshot: which host and path are sent to which Service, and which
+Deployments, StatefulSets, and DaemonSets that Service selects.
+
+HTTPRoutes are included when the snapshot captures them, e.g. by adding
+"httproutes" to snapshot.resource_types or by enabling discovery. Use
+routes-diff to compare the routing of two snapshots.`,
+	Example: `  # How was traffic routed on Tuesday morning?
+  gitops-time-machine routes --at 2024-06-04T09:00:00Z
+
+  # Routes of one host right now, as JSON
+  gitops-time-machine routes --host shop.example.com -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(routesOutput) && routesOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", routesOutput)
+		}
+
+		snapshot, err := loadSnapshot(cmd.Context(), cfg, routesCommit, routesAt)
+		if err != nil {
+			return err
+		}
+
+		routes := routing.FilterHost(routing.Table(snapshot), routesHost)
+		if isStructuredOutput(routesOutput) {
+			return printStructured(routesOutput, routes)
+		}
+		printer.Banner()
+		printer.RoutingTable(routes, snapshot.Metadata.CommitHash)
+		return nil
+	},
+}
+
+func init() {
+	routesCmd.Flags().StringVar(&routesCommit, "commit", "", "show the routing of the snapshot at a commit, branch, tag, or revision")
+	routesCmd.Flags().StringVar(&routesAt, "at", "", "show the routing at a point in time (RFC3339 format)")
+	routesCmd.Flags().StringVar(&routesHost, "host", "", "only show hosts containing this text")
+	routesCmd.Flags().StringVarP(&routesOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(routesCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/routing"
+	"github.com/spf13/cobra"
+)
+
+var (
+	routesDiffSelection snapshotSelection
+	routesDiffHost      string
+	routesDiffOutput    string
+)
+
+var routesDiffCmd = &cobra.Command{
+	Use:   "routes-diff",
+	Short: "Show traffic routing changes between two snapshots",
+	Long: `Compares the routing tables derived from the Ingresses and Gateway API
+HTTPRoutes of two snapshots and reports the host/path routes that were
+added, removed, or sent to a different Service or workload, e.g. when a
+Service selector change silently moved traffic to another Deployment.`,
+	Example: `  # What changed in routing between Monday and Tuesday?
+  gitops-time-machine routes-diff --from 2024-06-03T09:00:00Z --to 2024-06-04T09:00:00Z
+
+  # Routing changes of one host since a commit
+  gitops-time-machine routes-diff --commit HEAD~5 --host shop.example.com`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(routesDiffOutput) && routesDiffOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", routesDiffOutput)
+		}
+
+		fromSnapshot, toSnapshot, err := routesDiffSelection.load(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
+
+		changes := routing.FilterChanges(routing.Diff(fromSnapshot, toSnapshot), routesDiffHost)
+		if isStructuredOutput(routesDiffOutput) {
+			return printStructured(routesDiffOutput, changes)
+		}
+		printer.Banner()
+		printer.RoutingChanges(changes)
+		return nil
+	},
+}
+
+func init() {
+	routesDiffSelection.addFlags(routesDiffCmd)
+	routesDiffCmd.Flags().StringVar(&routesDiffHost, "host", "", "only show hosts containing this text")
+	routesDiffCmd.Flags().StringVarP(&routesDiffOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(routesDiffCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/index"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/search"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchQuery  search.Query
+	searchOutput string
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Find every snapshot and resource where a value appears",
+	Long: `Scans the whole snapshot history for a value, such as a container image,
+and lists each resource that held it with the snapshot where it first
+appeared, the last snapshot that still had it, and the snapshot where it
+was removed.
+
+Values are compared exactly against every scalar field of each resource.
+Use --field to restrict the match to a field path; list indexes may be
+omitted, so .spec.template.spec.containers.image matches every container.
+
+Decoded resources are cached in the snapshot repository's .git directory,
+so repeated searches only read files that changed since the last one.`,
+	Example: `  # When was a vulnerable image deployed, and when did it go away?
+  gitops-time-machine search --value "registry.internal/app:1.4.2"
+
+  # Only match container images
+  gitops-time-machine search --value "registry.internal/app:1.4.2" --field .spec.template.spec.containers.image
+
+  # Machine-readable output
+  gitops-time-machine search --value "10.0.0.12" --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if searchQuery.Value == "" {
+			return fmt.Errorf("--value is required")
+		}
+		if !isStructuredOutput(searchOutput) && searchOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", searchOutput)
+		}
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+		scope, err := snapshotScope(cfg)
+		if err != nil {
+			return err
+		}
+		idx, err := index.Open(index.File(cfg.Snapshot.OutputDir))
+		if err != nil {
+			return err
+		}
+
+		result, err := search.New(ver, idx, scope).Search(searchQuery)
+		if err != nil {
+			return fmt.Errorf("search failed: %w", err)
+		}
+
+		if isStructuredOutput(searchOutput) {
+			return printStructured(searchOutput, result)
+		}
+		printer.Banner()
+		printer.SearchResults(result)
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringVar(&searchQuery.Value, "value", "", "value to search for, e.g. an image reference")
+	searchCmd.Flags().StringVar(&searchQuery.Field, "field", "", "only match values under this field path, e.g. .spec.template.spec.containers.image")
+	searchCmd.Flags().StringVarP(&searchOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+)
+
+// snapshotSelection holds the flags that pick two snapshots to compare.
+type snapshotSelection struct {
+	from       string
+	to         string
+	commit     string
+	fromCommit string
+	toCommit   string
+}
+
+// addFlags registers the selection flags on a command.
+func (s *snapshotSelection) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.from, "from", "", "start time (RFC3339 format)")
+	cmd.Flags().StringVar(&s.to, "to", "", "end time (RFC3339 format)")
+	cmd.Flags().StringVar(&s.commit, "commit", "", "compare with a commit, branch, tag, or revision")
+	cmd.Flags().StringVar(&s.fromCommit, "from-commit", "", "base commit or revision (e.g. HEAD~3)")
+	cmd.Flags().StringVar(&s.toCommit, "to-commit", "", "target commit or revision (default: HEAD)")
+}
+
+// load returns the base and target snapshots chosen by the flags.
+func (s *snapshotSelection) load(ctx context.Context, cfg *config.Config) (*types.ResourceSnapshot, *types.ResourceSnapshot, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+
+	snap, err := scopedSnapshotter(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tt := timetravel.New(ver, snap, cfg.Snapshot.OutputDir)
+
+	switch {
+	case s.fromCommit != "":
+		toRef := s.toCommit
+		if toRef == "" {
+			toRef = "HEAD"
+		}
+
+		fromSnapshot, err := tt.SnapshotByCommit(ctx, s.fromCommit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get snapshot for commit %s: %w", s.fromCommit, err)
+		}
+		toSnapshot, err := tt.SnapshotByCommit(ctx, toRef)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get snapshot for commit %s: %w", toRef, err)
+		}
+		return fromSnapshot, toSnapshot, nil
+
+	case s.toCommit != "":
+		return nil, nil, fmt.Errorf("--to-commit requires --from-commit")
+
+	case s.commit != "":
+		// Compare specific commit with latest
+		fromSnapshot, err := tt.SnapshotByCommit(ctx, s.commit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get snapshot for commit %s: %w", s.commit, err)
+		}
+
+		// Get latest snapshot
+		toSnapshot, err := snap.ReadContext(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read current snapshot: %w", err)
+		}
+		return fromSnapshot, toSnapshot, nil
+
+	case s.from != "" && s.to != "":
+		fromTime, err := time.Parse(time.RFC3339, s.from)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --from time format (use RFC3339): %w", err)
+		}
+		toTime, err := time.Parse(time.RFC3339, s.to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --to time format (use RFC3339): %w", err)
+		}
+
+		fromSnapshot, toSnapshot, err := tt.CompareTimeRange(ctx, fromTime, toTime)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compare time range: %w", err)
+		}
+		return fromSnapshot, toSnapshot, nil
+
+	default:
+		return nil, nil, fmt.Errorf("specify --commit, --from-commit, or both --from and --to")
+	}
+}
+
+// loadSnapshot reads the snapshot at a commit or revision, or at an RFC3339
+// time, or the current snapshot if both are empty.
+func loadSnapshot(ctx context.Context, cfg *config.Config, commit, at string) (*types.ResourceSnapshot, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	snap, err := scopedSnapshotter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tt := timetravel.New(ver, snap, cfg.Snapshot.OutputDir)
+
+	switch {
+	case commit != "" && at != "":
+		return nil, fmt.Errorf("--commit and --at are mutually exclusive")
+	case commit != "":
+		snapshot, err := tt.SnapshotByCommit(ctx, commit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get snapshot for commit %s: %w", commit, err)
+		}
+		return snapshot, nil
+	case at != "":
+		target, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --at time format (use RFC3339): %w", err)
+		}
+		snapshot, err := tt.SnapshotAt(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get snapshot at %s: %w", at, err)
+		}
+		return snapshot, nil
+	default:
+		snapshot, err := snap.ReadContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read current snapshot: %w", err)
+		}
+		return snapshot, nil
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/audit"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/chatops"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/server"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// Default listen addresses: all interfaces when API tokens guard the
+// endpoints, and only loopback when the read endpoints are open.
+const (
+	defaultServeAddr      = ":8080"
+	defaultLocalServeAddr = "127.0.0.1:8080"
+)
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve snapshot history, snapshots, drift, and restores over a REST API",
+	Long: `Starts an HTTP server exposing the snapshot repository's history,
+snapshots, and drift as JSON.
+
+Endpoints:
+  GET /api/snapshots?limit=N (also /api/history)
+      Snapshots, newest first, with per-kind and per-namespace counts.
+  GET /api/snapshots/at?time=T or ?commit=REF
+      The snapshot at an RFC3339 time, or at a commit, branch, tag, or
+      revision (e.g. HEAD~1), with all its resources.
+  GET /api/drift?from=A&to=B
+      The drift report between two snapshots, as diff --format json
+      prints it. Each of from and to is a ref or an RFC3339 time; to
+      defaults to the latest snapshot.
+  GET /api/resources/{namespace}/{kind}/{name}/timeline
+      Every version of one resource across history with commit hashes and
+      timestamps. Use "_cluster" as the namespace for cluster-scoped resources.
+  POST /api/restore
+      Restore resources from a snapshot, as the restore command does. The
+      body is JSON: "commit" or "at" (RFC3339), optional "namespace",
+      "kind", and "name" to limit the scope, and "dryRun",
+      "forceConflicts", "skipConflicts", and "noVerify". The response is
+      the restore report. One restore runs at a time.
+  POST /slack/commands
+      Slack slash commands, when serve.slash_commands.signing_secret is set:
+      "drift <namespace> [since]", "get <namespace> <kind> <name> [at]",
+      and "history [n]". Point the slash command's request URL here;
+      requests are verified with the app's signing secret.
+
+Clients authenticate with a bearer token from serve.tokens. A token with
+the viewer role can read history, snapshots, and drift; the operator role
+can also restore. Without tokens, the read endpoints are open, restore is
+disabled, and the server listens only on loopback unless --addr says
+otherwise. Secret values and secret-looking fields are masked in snapshots,
+timelines, and drift.
+
+Every restore, through the API or the restore command, is recorded in the
+audit log (audit.file) with the token name or OS user that ran it.`,
+	Example: `  # Serve on the default address
+  gitops-time-machine serve
+
+  # Drift since a point in time
+  curl 'localhost:8080/api/drift?from=2026-03-01T09:00:00Z'
+
+  # Every version of a Deployment
+  curl localhost:8080/api/resources/prod/Deployment/web/timeline
+
+  # Preview restoring a namespace to the previous snapshot
+  curl -X POST -H "Authorization: Bearer $TOKEN" localhost:8080/api/restore \
+    -d '{"commit": "HEAD~1", "namespace": "prod", "dryRun": true}'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		scope, err := snapshotScope(cfg)
+		if err != nil {
+			return err
+		}
+
+		api := server.New(cfg, scope)
+		api.SetRestore(apiRestore(cfg, scope))
+		api.SetDrift(apiDrift(cfg))
+		if secret := cfg.Serve.SlashCommands.SigningSecret; secret != "" {
+			api.Mount("POST /slack/commands", chatops.NewHandler(secret, historyBackend{cfg: cfg, scope: scope}))
+		}
+		addr := serveAddr
+		if len(cfg.Serve.Tokens) == 0 {
+			if addr == "" {
+				addr = defaultLocalServeAddr
+			}
+			printer.Warning("No serve.tokens configured: the API is unauthenticated and restores are disabled.")
+		} else if addr == "" {
+			addr = defaultServeAddr
+		}
+
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           api.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		// Ctrl+C or SIGTERM cancels the command's context and shuts down
+		ctx, stop := context.WithCancel(cmd.Context())
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			log.Info("received shutdown signal")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				log.WithError(err).Warn("failed to shut down server cleanly")
+			}
+		}()
+
+		printer.Banner()
+		printer.Info(fmt.Sprintf("Serving snapshot history on %s", addr))
+
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return interrupted(cmd)
+	},
+}
+
+// apiRestore runs restores requested through the API, reading the snapshot
+// from its commit so that concurrent reads are not disturbed by a checkout.
+func apiRestore(cfg *config.Config, scope string) server.RestoreFunc {
+	return func(ctx context.Context, actor string, req restorer.Request) (*restorer.Report, error) {
+		report, err := restoreFromRequest(ctx, cfg, scope, req)
+		auditRestore(cfg, actor, audit.SourceAPI, req, report, err)
+		return report, err
+	}
+}
+
+// apiDrift compares snapshots requested through the API as the diff
+// command does, reusing its cache of reports between commits.
+func apiDrift(cfg *config.Config) server.DriftFunc {
+	return func(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+		report, err := compareCommits(ctx, cfg, base, target)
+		if err != nil {
+			return nil, err
+		}
+		linkReport(cfg, report)
+		if err := annotateReport(cfg, report); err != nil {
+			return nil, err
+		}
+		return report, nil
+	}
+}
+
+// restoreFromRequest restores the resources a request selects. Errors in
+// the request itself wrap server.ErrInvalidRestore.
+func restoreFromRequest(ctx context.Context, cfg *config.Config, scope string, req restorer.Request) (*restorer.Report, error) {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+
+	ref := req.Commit
+	if req.At != "" {
+		at, err := time.Parse(time.RFC3339, req.At)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", server.ErrInvalidRestore, err)
+		}
+		if ref, err = ver.FindCommitByTime(ctx, at); err != nil {
+			return nil, fmt.Errorf("%w: %v", server.ErrInvalidRestore, err)
+		}
+	}
+	entry, err := ver.Entry(ref, scope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", server.ErrInvalidRestore, err)
+	}
+	target, err := timetravel.ReadCommit(ctx, ver, entry, scope, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []types.Resource
+	for _, res := range target.Resources {
+		if req.Matches(res) {
+			resources = append(resources, res)
+		}
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("%w: no resources in snapshot %s match the scope", server.ErrInvalidRestore, entry.CommitHash[:8])
+	}
+
+	report, err := runRestore(ctx, cfg, resources, req.Options(), !req.NoVerify, false)
+	if err != nil {
+		return nil, err
+	}
+	report.Commit = entry.CommitHash
+	return report, nil
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "address to listen on (default \""+defaultServeAddr+"\", or \""+defaultLocalServeAddr+"\" without serve.tokens)")
+
+	rootCmd.AddCommand(serveCmd)
+}
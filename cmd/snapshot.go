@@ -3,11 +3,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -16,53 +17,184 @@ var snapshotCmd = &cobra.Command{
 	Short: "Capture a point-in-time snapshot of infrastructure state",
 	Long: `Connects to the configured Kubernetes cluster, captures the current 
 state of all configured resources, writes them as organized YAML files, 
-and commits the snapshot to the Git repository.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := getConfig()
+and commits the snapshot to the Git repository.
 
-		printer.Banner()
-		printer.Info("Starting infrastructure snapshot...")
+With --dry-run nothing is collected: each configured resource type is
+checked with a SelfSubjectAccessReview, and a minimal ClusterRole granting
+exactly the needed read permissions is printed.
 
-		// Create collector
-		coll, err := collector.New(cfg)
-		if err != nil {
-			return fmt.Errorf("failed to create collector: %w", err)
-		}
+If writing or committing a snapshot fails (e.g. the disk is full or the
+repository is locked), the collected resources are kept; --resume writes
+and commits them later without contacting the cluster again.
 
-		// Collect resources
-		ctx := context.Background()
-		snapshot, err := coll.Collect(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to collect resources: %w", err)
-		}
+Before committing, the snapshot is checked: it must have resources (unless
+--allow-empty), readable metadata, only files of the snapshot layout, and
+at most snapshot.max_size_bytes of files. A snapshot failing the checks is
+not committed, and the command exits with code 8.
 
-		// Write to disk
-		snap := snapshotter.New(cfg.Snapshot.OutputDir)
-		if err := snap.Write(snapshot); err != nil {
-			return fmt.Errorf("failed to write snapshot: %w", err)
-		}
+--namespace and --kinds take a partial snapshot of some namespaces or
+resource types only, e.g. before and after a change to one team's
+workloads. The scope is recorded in the snapshot's metadata, and drift
+against a partial snapshot compares only the resources it covers, so the
+rest of the cluster is not reported as removed. With --namespace,
+cluster-scoped resources are left out.`,
+	Example: `  # Capture and commit a snapshot
+  gitops-time-machine snapshot
 
-		// Commit to Git
-		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
-		if err != nil {
-			return fmt.Errorf("failed to initialize versioner: %w", err)
+  # Check collector permissions and print the ClusterRole it needs
+  gitops-time-machine snapshot --dry-run
+
+  # Commit the snapshot kept by a run that failed to
+  gitops-time-machine snapshot --resume
+
+  # Capture a snapshot and push it to the remote
+  gitops-time-machine snapshot --push
+
+  # Snapshot only team-a's Deployments and ConfigMaps
+  gitops-time-machine snapshot --namespace team-a --kinds deployments,configmaps`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+		if snapshotPush {
+			cfg.Git.Push = true
+		}
+		if allowEmpty {
+			cfg.Snapshot.AllowEmpty = true
 		}
 
-		commitHash, err := ver.Commit(&snapshot.Metadata)
-		if err != nil {
-			return fmt.Errorf("failed to commit snapshot: %w", err)
+		if snapshotDryRun && snapshotResume {
+			return fmt.Errorf("--dry-run and --resume are mutually exclusive")
+		}
+		if len(snapshotNamespaces) > 0 || len(snapshotKinds) > 0 {
+			if snapshotResume {
+				return fmt.Errorf("--namespace and --kinds cannot be used with --resume")
+			}
+			scope, err := scopeSnapshot(cfg, snapshotNamespaces, snapshotKinds)
+			if err != nil {
+				return err
+			}
+			partialScope = scope
+		}
+		if snapshotDryRun {
+			return checkCollectorAccess(cmd.Context(), cfg)
 		}
 
-		snapshot.Metadata.CommitHash = commitHash
+		printer.Banner()
+		var snapshot *types.ResourceSnapshot
+		var err error
+		if snapshotResume {
+			printer.Info("Resuming the kept snapshot...")
+			snapshot, err = resumeSnapshot(cfg, printer.NewProgress(!noProgress))
+		} else {
+			printer.Info("Starting infrastructure snapshot...")
+			snapshot, err = captureSnapshot(cmd.Context(), cfg, printer.NewProgress(!noProgress))
+		}
+		if snapshot == nil {
+			return err
+		}
 
 		// Print summary
 		printer.SnapshotSummary(&snapshot.Metadata)
+		if err != nil {
+			printer.Warning("Snapshot committed, but some resource types could not be collected.")
+			return err
+		}
+		if snapshot.Metadata.CommitHash == "" {
+			printer.Success("No changes since the last snapshot; nothing to commit.")
+			return nil
+		}
 		printer.Success("Snapshot captured and committed successfully!")
 
 		return nil
 	},
 }
 
+// collectorRoleName names the ClusterRole generated for the collector.
+const collectorRoleName = "gitops-time-machine"
+
+var (
+	snapshotDryRun     bool
+	snapshotResume     bool
+	snapshotPush       bool
+	snapshotNamespaces []string
+	snapshotKinds      []string
+	// allowEmpty is the --allow-empty of snapshot and watch.
+	allowEmpty bool
+	// partialScope is recorded in the metadata of the snapshot taken by
+	// snapshot --namespace or --kinds.
+	partialScope *types.SnapshotScope
+)
+
+// scopeSnapshot restricts the collection of cfg to namespaces and the
+// resource types kinds, and returns the resulting scope.
+func scopeSnapshot(cfg *config.Config, namespaces, kinds []string) (*types.SnapshotScope, error) {
+	if len(cfg.Clusters) > 0 {
+		return nil, fmt.Errorf("--namespace and --kinds are not supported in fleet mode")
+	}
+	scope := &types.SnapshotScope{}
+	if len(namespaces) > 0 {
+		cfg.Snapshot.Namespaces = namespaces
+		scope.Namespaces = append([]string(nil), namespaces...)
+		sort.Strings(scope.Namespaces)
+	}
+	if len(kinds) > 0 {
+		for _, name := range kinds {
+			kind, ok := collector.KindOf(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown resource type %q for --kinds (use a type of snapshot.resource_types, e.g. deployments)", name)
+			}
+			scope.Kinds = append(scope.Kinds, kind)
+		}
+		sort.Strings(scope.Kinds)
+		cfg.Snapshot.ResourceTypes = kinds
+		cfg.Snapshot.Discovery.Enabled = false
+	}
+	return scope, nil
+}
+
+// checkCollectorAccess reports which resource types the current identity can
+// collect and prints the minimal ClusterRole for the configured types.
+func checkCollectorAccess(ctx context.Context, cfg *config.Config) error {
+	coll, err := collector.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create collector: %w", err)
+	}
+
+	results, err := coll.CheckAccess(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check access: %w", err)
+	}
+
+	printer.Banner()
+	printer.AccessReport(results)
+
+	denied := 0
+	for _, a := range results {
+		if a.Known && !a.Allowed {
+			denied++
+		}
+	}
+	if denied > 0 {
+		printer.Warning(fmt.Sprintf("%d resource type(s) cannot be listed; grant the ClusterRole below", denied))
+	} else {
+		printer.Success("All known resource types can be listed")
+	}
+
+	fmt.Println()
+	if cfg.Snapshot.Discovery.Enabled {
+		fmt.Println("# ClusterRole for discovery mode")
+		return printStructured(outputYAML, collector.DiscoveryClusterRole(collectorRoleName, cfg.Watch.EnableWatchEvents))
+	}
+	fmt.Println("# Minimal ClusterRole for the configured resource types")
+	return printStructured(outputYAML, collector.ClusterRole(collectorRoleName, cfg.Snapshot.ResourceTypes, cfg.Watch.EnableWatchEvents))
+}
+
 func init() {
+	snapshotCmd.Flags().BoolVar(&snapshotResume, "resume", false, "write and commit the snapshot kept by a run that failed to, without collecting again")
+	snapshotCmd.Flags().BoolVar(&snapshotPush, "push", false, "push the snapshot to the remote after committing it (overrides config)")
+	snapshotCmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "commit the snapshot even if it has no resources (overrides config)")
+	snapshotCmd.Flags().StringSliceVar(&snapshotNamespaces, "namespace", nil, "take a partial snapshot of these namespaces only")
+	snapshotCmd.Flags().StringSliceVar(&snapshotKinds, "kinds", nil, "take a partial snapshot of these resource types only (e.g. deployments,configmaps)")
+	snapshotCmd.Flags().BoolVar(&snapshotDryRun, "dry-run", false, "check collector RBAC access and print the required ClusterRole instead of snapshotting")
+
 	rootCmd.AddCommand(snapshotCmd)
 }
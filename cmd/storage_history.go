@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/storage"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storageHistoryFrom      string
+	storageHistoryTo        string
+	storageHistoryNamespace string
+	storageHistoryOutput    string
+)
+
+var storageHistoryCmd = &cobra.Command{
+	Use:   "storage-history",
+	Short: "Show how requested storage grew per namespace over a period",
+	Long: `Follows the storage requested by PersistentVolumeClaims through every
+snapshot between --from and --to. For each namespace it shows the
+requested storage at the start and end of the period, the growth, and
+when the namespace's requested storage first doubled; then each snapshot
+at which a namespace's claims were added, removed, resized, or moved to
+another storage class.
+
+Requested storage is spec.resources.requests.storage, so
+persistentvolumeclaims must be among snapshot.resource_types. Claims
+without a storage class are shown as (default).
+
+--namespace follows a single namespace. Without --from the history starts
+at the first snapshot; without --to it runs to the latest.`,
+	Example: `  # When did the data namespace's requested storage double?
+  gitops-time-machine storage-history --namespace data
+
+  # Storage growth across all namespaces this year, as JSON
+  gitops-time-machine storage-history --from 2024-01-01T00:00:00Z -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !isStructuredOutput(storageHistoryOutput) && storageHistoryOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", storageHistoryOutput)
+		}
+		from, to, err := parsePeriod(storageHistoryFrom, storageHistoryTo)
+		if err != nil {
+			return err
+		}
+		showProgress := !noProgress && !isStructuredOutput(storageHistoryOutput)
+		points, err := walkHistory(cmd.Context(), cfg, from, to, storage.Kinds, "reading claims", showProgress,
+			func(entry types.HistoryEntry, snapshot *types.ResourceSnapshot) storage.Point {
+				return storage.Point{
+					CommitHash: entry.CommitHash,
+					Timestamp:  entry.Timestamp,
+					Claims:     storage.Claims(snapshot),
+				}
+			})
+		if err != nil {
+			return err
+		}
+
+		report := storage.History(points, storageHistoryNamespace)
+		if isStructuredOutput(storageHistoryOutput) {
+			return printStructured(storageHistoryOutput, report)
+		}
+		printer.Banner()
+		printer.StorageHistory(report, storageHistoryNamespace)
+		return nil
+	},
+}
+
+func init() {
+	storageHistoryCmd.Flags().StringVar(&storageHistoryFrom, "from", "", "start time (RFC3339 format, default: the first snapshot)")
+	storageHistoryCmd.Flags().StringVar(&storageHistoryTo, "to", "", "end time (RFC3339 format, default: now)")
+	storageHistoryCmd.Flags().StringVarP(&storageHistoryNamespace, "namespace", "n", "", "only follow this namespace")
+	storageHistoryCmd.Flags().StringVarP(&storageHistoryOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(storageHistoryCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyAgainstLive bool
+	verifyCommit      string
+	verifyAt          string
+	verifyAll         bool
+	verifyOutput      string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check how much of a past snapshot survives in the live state",
+	Long: `With --against-live, collects the live state and reports which resources
+of the snapshot at --commit (or --at a time) still exist unchanged, which
+changed, and which are gone: how much of Tuesday's state survives today.
+Resources created since are only counted.
+
+Unlike drift, which compares the live state with the latest snapshot, this
+answers for any snapshot. Ignore rules apply as for drift.`,
+	Example: `  # How much of a week-old snapshot is still live
+  gitops-time-machine verify --against-live --commit HEAD~2016
+
+  # Every resource of a snapshot and what became of it, as JSON
+  gitops-time-machine verify --against-live --commit a1b2c3d -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if !verifyAgainstLive {
+			return fmt.Errorf("specify --against-live")
+		}
+		if verifyCommit == "" && verifyAt == "" {
+			return fmt.Errorf("specify --commit or --at")
+		}
+		if !isStructuredOutput(verifyOutput) && verifyOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", verifyOutput)
+		}
+
+		ctx := cmd.Context()
+		past, err := loadSnapshot(ctx, cfg, verifyCommit, verifyAt)
+		if err != nil {
+			return err
+		}
+
+		coll, err := collector.New(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create collector: %w", err)
+		}
+		live, err := coll.Collect(ctx)
+		if err = allowPartial(err); err != nil {
+			return fmt.Errorf("failed to collect live state: %w", err)
+		}
+		filterToTeam(cfg, live)
+
+		report, err := compareSnapshots(ctx, cfg, past, live)
+		if err != nil {
+			return err
+		}
+		survival := analyzer.Survival(past, report)
+
+		if isStructuredOutput(verifyOutput) {
+			return printStructured(verifyOutput, survival)
+		}
+		printer.Survival(survival, verifyAll)
+		return nil
+	},
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyAgainstLive, "against-live", false, "compare the snapshot with the live state")
+	verifyCmd.Flags().StringVar(&verifyCommit, "commit", "", "verify the snapshot at a commit, branch, tag, or revision")
+	verifyCmd.Flags().StringVar(&verifyAt, "at", "", "verify the snapshot at a point in time (RFC3339 format)")
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "also list the unchanged resources")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -2,21 +2,49 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
+	"net"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/debounce"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/hooks"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/metrics"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/pushbatch"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/remediation"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/scheduler"
-	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/storm"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-var watchSchedule string
+var (
+	watchSchedule    string
+	watchTimezone    string
+	watchPush        bool
+	watchMetricsAddr string
+)
+
+// watchMetrics records the watch process's ticks for the metrics endpoint.
+// It is nil, and recording does nothing, when the endpoint is disabled.
+var watchMetrics *metrics.Watch
+
+// watchPusher batches the pushes of watch with git.push. It is nil when
+// snapshots are not pushed, or outside watch, where every commit is pushed.
+var watchPusher *pushbatch.Batcher
+
+// remediator triggers remediation workflows on persistent drift. It is nil
+// when none are configured.
+var remediator *remediation.Remediator
 
 var watchCmd = &cobra.Command{
 	Use:   "watch",
@@ -24,7 +52,68 @@ var watchCmd = &cobra.Command{
 	Long: `Starts a background process that takes infrastructure snapshots 
 at regular intervals using a cron schedule. Runs until interrupted.
 
-Default schedule: every 5 minutes (configured in config file or via --schedule flag).`,
+Default schedule: every 5 minutes (configured in config file or via --schedule flag).
+Schedules are evaluated in watch.timezone (or --timezone), or in a zone given
+by a CRON_TZ= prefix on the schedule itself.
+
+With watch.gate enabled, each snapshot is diffed against the previous one
+and checked against the gate rules (and optional external policy command)
+before committing. Snapshots that fail are committed to the quarantine
+branch instead, keeping the main history clean.
+
+With watch.anomaly enabled, the size of each delta (overall, per kind, and
+per namespace) is scored against its rolling history, and unusually large
+deltas are reported with an anomaly score.
+
+With clusters configured, all clusters are captured concurrently on each
+tick into their own directories, and the whole fleet is committed together.
+A cluster that cannot be collected within cluster_timeout keeps its
+previous snapshot and is marked as failed in the commit's metadata. The
+gate and anomaly checks run per cluster; if any cluster fails the gate,
+the tick is committed to the quarantine branch.
+
+If watch.storm.ticks consecutive ticks each commit changes, a commit storm
+is reported: a warning is logged and sent to the configured notifiers,
+since a field that changes on its own or controllers fighting over a
+resource is the usual cause. With watch.storm.backoff, ticks are then
+skipped to double the interval, up to watch.storm.max_backoff times the
+schedule's, until watch is restarted.
+
+With watch.enable_watch_events, the configured resource types are also
+watched, and a snapshot is taken as soon as they change: once changes have
+paused for watch.event_debounce, or at the latest watch.event_max_delay
+after the first, so that a rollout is one commit rather than many. Changes
+to stripped fields such as .status are ignored. The schedule keeps running
+as a safety net. Event-driven snapshots are not counted towards commit
+storms. Not supported with clusters configured.
+
+With report.schedule set, the drift and trend report for report.period is
+also generated on that schedule and sent to the configured notifiers (see
+the report command).
+
+With watch.metrics_addr (or --metrics-addr) set, Prometheus metrics of the
+watch process are served at /metrics on that address: ticks by result,
+snapshot duration, resources per kind, commits, the time of the last
+successful snapshot, drift entries since the previous snapshot, and
+collection errors.
+
+With watch.remediation webhooks or a GitHub workflow configured, a tick
+whose drift since the previous snapshot includes changes at or above
+watch.remediation.severity is a drifting check. Once watch.remediation.checks
+consecutive checks drift, the webhooks are called (e.g. to open a Jira or
+ServiceNow ticket) and the workflow is dispatched, once per streak. Drift
+during a maintenance window does not count, and in a fleet each cluster
+has its own streak.
+
+With notifiers.on_drift set, the changes of each committed snapshot are
+sent to the configured notifiers, outside maintenance windows.
+
+With git.push (or --push), commits are pushed after every snapshot, or
+with watch.push.interval or watch.push.commits set, at most every interval
+or once that many commits are waiting. A failed push does not hold up the
+next snapshot: it is retried after watch.push.retry_backoff, doubling up to
+watch.push.max_backoff, and pending commits are pushed once more when
+watch stops. Pushes and unpushed commits are exported as metrics.`,
 	Example: `  # Watch with default schedule (every 5 minutes)
   gitops-time-machine watch
   
@@ -32,75 +121,114 @@ Default schedule: every 5 minutes (configured in config file or via --schedule f
   gitops-time-machine watch --schedule "* * * * *"
   
   # Watch every hour
-  gitops-time-machine watch --schedule "0 * * * *"`,
+  gitops-time-machine watch --schedule "0 * * * *"
+
+  # Nightly at 02:00 Berlin time
+  gitops-time-machine watch --schedule "0 2 * * *" --timezone Europe/Berlin
+
+  # Expose Prometheus metrics on port 9090
+  gitops-time-machine watch --metrics-addr :9090`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg := getConfig()
+		if watchPush {
+			cfg.Git.Push = true
+		}
+		if allowEmpty {
+			cfg.Snapshot.AllowEmpty = true
+		}
 
 		schedule := cfg.Watch.Schedule
 		if watchSchedule != "" {
 			schedule = watchSchedule
 		}
 
-		printer.Banner()
-		printer.Info(fmt.Sprintf("Starting continuous watch with schedule: %s", schedule))
-		printer.Info("Press Ctrl+C to stop.")
-		fmt.Println()
+		timezone := cfg.Watch.Timezone
+		if watchTimezone != "" {
+			timezone = watchTimezone
+		}
 
-		// Create the snapshot function
-		snapshotFn := func(ctx context.Context) error {
-			coll, err := collector.New(cfg)
-			if err != nil {
-				return fmt.Errorf("failed to create collector: %w", err)
+		if watchMetricsAddr != "" {
+			if err := metrics.Validate(watchMetricsAddr); err != nil {
+				return err
 			}
+			cfg.Watch.MetricsAddr = watchMetricsAddr
+		}
 
-			snapshot, err := coll.Collect(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to collect resources: %w", err)
-			}
+		if cfg.Watch.EnableWatchEvents && len(cfg.Clusters) > 0 {
+			return fmt.Errorf("watch.enable_watch_events is not supported with clusters configured")
+		}
 
-			snap := snapshotter.New(cfg.Snapshot.OutputDir)
-			if err := snap.Write(snapshot); err != nil {
-				return fmt.Errorf("failed to write snapshot: %w", err)
-			}
+		if remediation.Enabled(&cfg.Watch.Remediation) {
+			remediator = remediation.New(&cfg.Watch.Remediation)
+		}
+		if cfg.Git.Push {
+			watchPusher = pushbatch.New(&cfg.Watch.Push, unpushedCommits(cfg))
+		}
 
-			ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
-			if err != nil {
-				return fmt.Errorf("failed to initialize versioner: %w", err)
+		// Create the snapshot function. Scheduled and event-driven ticks
+		// never run at once.
+		var tickMu sync.Mutex
+		detector := storm.New(&cfg.Watch.Storm)
+		snapshotFn := func(ctx context.Context) error {
+			tickMu.Lock()
+			defer tickMu.Unlock()
+			if detector.Skip() {
+				log.WithField("backoff", detector.Factor()).Info("commit storm backoff: skipping tick")
+				recordCheck(cfg, time.Now().UTC())
+				return nil
 			}
-
-			commitHash, err := ver.Commit(&snapshot.Metadata)
+			committed, err := watchTick(ctx, cfg)
 			if err != nil {
-				return fmt.Errorf("failed to commit: %w", err)
+				return err
 			}
-
-			if commitHash != "" {
-				snapshot.Metadata.CommitHash = commitHash
-				printer.SnapshotSummary(&snapshot.Metadata)
-			} else {
-				printer.Info("No changes detected, skipping commit.")
+			if event, ok := detector.Record(committed); ok {
+				reportStorm(ctx, cfg, event)
 			}
-
 			return nil
 		}
 
 		// Create scheduler
-		sched, err := scheduler.New(schedule, snapshotFn)
+		sched, err := scheduler.New(schedule, timezone, snapshotFn)
 		if err != nil {
 			return fmt.Errorf("failed to create scheduler: %w", err)
 		}
 
-		// Handle graceful shutdown
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
+		var reportSched *scheduler.Scheduler
+		if cfg.Report.Schedule != "" {
+			reportSched, err = scheduler.New(cfg.Report.Schedule, timezone, func(ctx context.Context) error {
+				return scheduledReport(ctx, cfg)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create report scheduler: %w", err)
+			}
+		}
 
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		printer.Banner()
+		printer.Info(fmt.Sprintf("Starting continuous watch with schedule: %s", schedule))
+		printer.Info(fmt.Sprintf("Next scheduled snapshot: %s", sched.Next(time.Now()).Format(time.RFC3339)))
+		if reportSched != nil {
+			printer.Info(fmt.Sprintf("Next scheduled report: %s", reportSched.Next(time.Now()).Format(time.RFC3339)))
+		}
+		printer.Info("Press Ctrl+C to stop.")
+		fmt.Println()
 
-		go func() {
-			<-sigCh
-			log.Info("received shutdown signal")
-			cancel()
-		}()
+		// Ctrl+C or SIGTERM cancels the command's context and stops the loop
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		if cfg.Watch.MetricsAddr != "" {
+			if err := serveWatchMetrics(ctx, cfg.Watch.MetricsAddr); err != nil {
+				return err
+			}
+		}
+		if reportSched != nil {
+			go reportSched.Start(ctx)
+		}
+		if cfg.Watch.EnableWatchEvents {
+			if err := watchEvents(ctx, cfg, &tickMu); err != nil {
+				return err
+			}
+		}
 
 		// Take an initial snapshot immediately
 		printer.Info("Taking initial snapshot...")
@@ -109,12 +237,348 @@ Default schedule: every 5 minutes (configured in config file or via --schedule f
 		}
 
 		// Start the scheduler (blocks until context is cancelled)
-		return sched.Start(ctx)
+		err = sched.Start(ctx)
+		if cmd.Context().Err() != nil {
+			log.Info("received shutdown signal")
+		}
+		flushPushes(cmd.Context(), cfg)
+		if err != nil {
+			return err
+		}
+		return interrupted(cmd)
 	},
 }
 
+// watchEvents starts watching the configured resource types, taking a
+// snapshot (while holding tickMu) after each burst of changes.
+func watchEvents(ctx context.Context, cfg *config.Config, tickMu *sync.Mutex) error {
+	coll, err := collector.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create collector: %w", err)
+	}
+	debouncer := debounce.New(cfg.Watch.EventDebounce, cfg.Watch.EventMaxDelay)
+	err = coll.Watch(ctx, func(ch collector.Change) {
+		log.WithFields(log.Fields{"change": ch.Type, "resource": ch.Resource.FullName()}).Debug("resource changed")
+		debouncer.Trigger()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch resources: %w", err)
+	}
+	go debouncer.Run(ctx, func(ctx context.Context) {
+		tickMu.Lock()
+		defer tickMu.Unlock()
+		log.Info("resources changed, taking snapshot")
+		if _, err := watchTick(ctx, cfg); err != nil {
+			log.WithError(err).Error("event-driven snapshot failed")
+		}
+	})
+	printer.Info(fmt.Sprintf("Snapshotting on resource changes (after %s without changes, at most %s after one)",
+		cfg.Watch.EventDebounce, cfg.Watch.EventMaxDelay))
+	return nil
+}
+
+// serveWatchMetrics starts serving watch metrics on addr until ctx is
+// cancelled.
+func serveWatchMetrics(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+	watchMetrics = metrics.New()
+	srv := &http.Server{
+		Handler:           watchMetrics.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Warn("failed to shut down metrics server cleanly")
+		}
+	}()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Error("metrics server failed")
+		}
+	}()
+	printer.Info(fmt.Sprintf("Serving metrics on http://%s/metrics", ln.Addr()))
+	return nil
+}
+
+// watchTick takes, reviews, and commits one watch snapshot, and reports
+// whether anything was committed.
+func watchTick(ctx context.Context, cfg *config.Config) (committed bool, err error) {
+	// Pushing is not part of the snapshot, so it runs after it is observed,
+	// also retrying pending pushes on ticks that commit nothing
+	defer pushPending(ctx, cfg)
+	start := time.Now()
+	defer func() { watchMetrics.ObserveTick(time.Since(start), committed, err) }()
+
+	if err := hooks.Run(ctx, &cfg.Hooks, hooks.PreSnapshot, hooks.Env(cfg, nil, "")); err != nil {
+		return false, err
+	}
+	if len(cfg.Clusters) > 0 {
+		return watchFleet(ctx, cfg)
+	}
+
+	progress := printer.NewProgress(!noProgress)
+	snapshot, err := collectSnapshot(ctx, cfg, progress)
+	if err != nil {
+		watchMetrics.CollectionError()
+	}
+	if err = allowPartial(err); err != nil {
+		return false, err
+	}
+	watchMetrics.SetResources(snapshot.Metadata.KindCounts)
+
+	review, err := reviewSnapshot(ctx, cfg, snapshot)
+	if err != nil {
+		return false, err
+	}
+	watchMetrics.AddDrift(review.drift())
+	remediate(ctx, "", review.report)
+
+	if len(review.anomalies) > 0 {
+		log.WithField("anomalies", len(review.anomalies)).Warn("anomalous snapshot delta detected")
+		printer.Warning("Unusually large changes in this snapshot:")
+		printer.Anomalies(review.anomalies)
+	}
+
+	branch := ""
+	if review.rejected() {
+		branch = cfg.Watch.Gate.QuarantineBranch
+	}
+	if err := commitSnapshot(cfg, snapshot, branch, progress); err != nil {
+		return false, err
+	}
+
+	if branch != "" && snapshot.Metadata.CommitHash != "" {
+		watchMetrics.Quarantined()
+		log.WithFields(log.Fields{
+			"branch":     branch,
+			"commit":     snapshot.Metadata.CommitHash[:8],
+			"violations": len(review.result.Violations),
+		}).Warn("snapshot failed watch gate, committed to quarantine branch")
+		printer.Warning(fmt.Sprintf("Snapshot failed the watch gate and was committed to branch %q (%s):",
+			branch, snapshot.Metadata.CommitHash[:8]))
+		printer.Violations(review.result.Violations)
+	} else if snapshot.Metadata.CommitHash != "" {
+		printer.SnapshotSummary(&snapshot.Metadata)
+	} else {
+		printer.Info("No changes detected, skipping commit.")
+	}
+
+	if snapshot.Metadata.CommitHash != "" {
+		notifyDrift(ctx, cfg, "watch", review.report)
+	}
+	return snapshot.Metadata.CommitHash != "", nil
+}
+
+// watchFleet captures every configured cluster for one watch tick, and
+// reports whether anything was committed.
+func watchFleet(ctx context.Context, cfg *config.Config) (bool, error) {
+	progress := printer.NewProgress(!noProgress)
+	fleet, err := collectFleet(ctx, cfg, progress)
+	if err != nil {
+		watchMetrics.CollectionError()
+		return false, err
+	}
+	for _, status := range fleet.total.Metadata.Clusters {
+		if status.Failed() {
+			watchMetrics.CollectionError()
+		}
+	}
+	watchMetrics.SetResources(fleet.total.Metadata.KindCounts)
+
+	var violations []policy.Violation
+	rejected := false
+	drift := 0
+	reports := make(map[string]*types.DriftReport)
+	for _, c := range cfg.Clusters {
+		snapshot, ok := fleet.clusters[c.Name]
+		if !ok {
+			continue
+		}
+		review, err := reviewSnapshot(ctx, clusterConfig(cfg, c), snapshot)
+		if err != nil {
+			return false, fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+		drift += review.drift()
+		reports[c.Name] = review.report
+		remediate(ctx, c.Name, review.report)
+		if len(review.anomalies) > 0 {
+			log.WithFields(log.Fields{"cluster": c.Name, "anomalies": len(review.anomalies)}).Warn("anomalous snapshot delta detected")
+			printer.Warning(fmt.Sprintf("Unusually large changes in cluster %s:", c.Name))
+			printer.Anomalies(review.anomalies)
+		}
+		if review.rejected() {
+			rejected = true
+			for _, v := range review.result.Violations {
+				v.Message = c.Name + ": " + v.Message
+				violations = append(violations, v)
+			}
+		}
+	}
+
+	branch := ""
+	watchMetrics.AddDrift(drift)
+	if rejected {
+		branch = cfg.Watch.Gate.QuarantineBranch
+	}
+	if err := commitFleet(cfg, fleet, branch, progress); err != nil {
+		return false, err
+	}
+
+	commitHash := fleet.total.Metadata.CommitHash
+	switch {
+	case branch != "" && commitHash != "":
+		watchMetrics.Quarantined()
+		log.WithFields(log.Fields{
+			"branch":     branch,
+			"commit":     commitHash[:8],
+			"violations": len(violations),
+		}).Warn("fleet snapshot failed watch gate, committed to quarantine branch")
+		printer.Warning(fmt.Sprintf("Fleet snapshot failed the watch gate and was committed to branch %q (%s):",
+			branch, commitHash[:8]))
+		printer.Violations(violations)
+	case commitHash != "":
+		printer.SnapshotSummary(&fleet.total.Metadata)
+	default:
+		printer.Info("No changes detected, skipping commit.")
+	}
+	if commitHash != "" {
+		for _, c := range cfg.Clusters {
+			notifyDrift(ctx, cfg, "watch in cluster "+c.Name, reports[c.Name])
+		}
+	}
+	return commitHash != "", nil
+}
+
+// unpushedCommits counts the commits a previous run left unpushed, so that
+// watch pushes them without waiting for a new commit.
+func unpushedCommits(cfg *config.Config) int {
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err != nil {
+		return 0
+	}
+	n, err := ver.Unpushed()
+	if err != nil {
+		log.WithError(err).Warn("failed to count unpushed snapshots")
+		return 0
+	}
+	return n
+}
+
+// pushPending pushes the snapshot commits waiting to be pushed, if
+// watch.push says they are due. A failed push is retried after a backoff;
+// the commits stay in place meanwhile.
+func pushPending(ctx context.Context, cfg *config.Config) {
+	if watchPusher == nil {
+		return
+	}
+	if !watchPusher.Due() {
+		watchMetrics.SetUnpushed(watchPusher.Pending())
+		return
+	}
+	pushNow(ctx, cfg)
+}
+
+// flushPushes makes a last attempt to push pending commits when watch
+// stops, regardless of watch.push. It runs after the interrupt that
+// stopped watch, so it is bounded by watch.push.timeout rather than by
+// ctx's cancellation; a second Ctrl+C exits immediately.
+func flushPushes(ctx context.Context, cfg *config.Config) {
+	if watchPusher == nil || watchPusher.Pending() == 0 {
+		return
+	}
+	log.WithField("commits", watchPusher.Pending()).Info("pushing pending snapshots before exiting")
+	pushNow(context.WithoutCancel(ctx), cfg)
+}
+
+// pushNow pushes the configured branch within watch.push.timeout and
+// records the outcome.
+func pushNow(ctx context.Context, cfg *config.Config) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Watch.Push.Timeout)
+	defer cancel()
+	pending := watchPusher.Pending()
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	if err == nil {
+		err = ver.Push(ctx, tenantBranches(cfg)...)
+	}
+	if err != nil {
+		backoff := watchPusher.Failed()
+		log.WithError(err).WithFields(log.Fields{
+			"unpushed": pending,
+			"retry_in": backoff,
+		}).Warn("failed to push snapshots")
+		watchMetrics.ObservePush(err, pending)
+		return
+	}
+	watchPusher.Pushed()
+	watchMetrics.ObservePush(nil, 0)
+}
+
+// remediate records a tick's drift and, once drift at or above
+// watch.remediation.severity has persisted for watch.remediation.checks
+// ticks, triggers the remediation workflows. Failures are logged; they do
+// not fail the tick.
+func remediate(ctx context.Context, cluster string, report *types.DriftReport) {
+	if remediator == nil {
+		return
+	}
+	event, ok := remediator.Observe(cluster, report)
+	if !ok {
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  cluster,
+		"checks":   event.Checks,
+		"entries":  len(event.Entries),
+		"severity": event.Severity,
+	}).Warn("persistent drift, triggering remediation")
+	printer.Warning(event.Summary + "; triggering remediation.")
+	if err := remediator.Trigger(ctx, event); err != nil {
+		log.WithError(err).Error("failed to trigger remediation")
+	}
+}
+
+// reportStorm warns that every recent tick committed changes and, when the
+// storm is first detected, notifies the configured notifiers.
+func reportStorm(ctx context.Context, cfg *config.Config, event storm.Event) {
+	log.WithFields(log.Fields{
+		"ticks":   event.Streak,
+		"backoff": event.Factor,
+	}).Warn("commit storm: every recent snapshot committed changes")
+	msg := fmt.Sprintf("The last %d snapshots each committed changes. This usually means a field that changes on its own "+
+		"is being captured (see the learn command and snapshot.strip_fields), or controllers are fighting over a resource.", event.Streak)
+	if event.Factor > 1 {
+		msg += fmt.Sprintf(" Snapshots are now taken every %d ticks of the schedule until watch is restarted.", event.Factor)
+	}
+	printer.Warning(msg)
+
+	if !event.First {
+		return
+	}
+	notifiers := notifier.New(&cfg.Notifiers)
+	if len(notifiers) == 0 {
+		return
+	}
+	err := notifier.SendAll(ctx, notifiers, &notifier.Message{
+		Subject: fmt.Sprintf("Commit storm in %s: %d consecutive snapshots changed", cfg.Snapshot.OutputDir, event.Streak),
+		Body:    msg,
+	})
+	if err != nil {
+		log.WithError(err).Warn("failed to send commit storm notification")
+	}
+}
+
 func init() {
 	watchCmd.Flags().StringVar(&watchSchedule, "schedule", "", "cron schedule (overrides config)")
+	watchCmd.Flags().StringVar(&watchTimezone, "timezone", "", "IANA time zone for the schedule, e.g. Europe/Berlin (overrides config)")
+	watchCmd.Flags().BoolVar(&watchPush, "push", false, "push every snapshot to the remote after committing it (overrides config)")
+	watchCmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "commit snapshots without resources (overrides config)")
+	watchCmd.Flags().StringVar(&watchMetricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address, e.g. :9090 (overrides config)")
 
 	rootCmd.AddCommand(watchCmd)
 }
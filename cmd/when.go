@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	whenResource string
+	whenOutput   string
+)
+
+var whenCmd = &cobra.Command{
+	Use:   "when",
+	Short: "Show when a resource was created and deleted",
+	Long: `Reports the first snapshot in which a resource appeared and, if it has
+since been deleted, the snapshot that removed it. A resource that was
+deleted and re-created is listed once per lifespan.
+
+Only the commits touching the resource's file are read, so the answer is
+immediate even for long histories.`,
+	Example: `  # When was this Deployment created?
+  gitops-time-machine when --resource prod/Deployment/api
+
+  # Cluster-scoped resources omit the namespace
+  gitops-time-machine when --resource ClusterRole/admin --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := getConfig()
+
+		if whenResource == "" {
+			return fmt.Errorf("--resource is required")
+		}
+		if !isStructuredOutput(whenOutput) && whenOutput != outputTable {
+			return fmt.Errorf("unsupported output format %q (use table, json, or yaml)", whenOutput)
+		}
+		namespace, kind, name, err := types.ParseFullName(whenResource)
+		if err != nil {
+			return err
+		}
+
+		ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+		if err != nil {
+			return fmt.Errorf("failed to initialize versioner: %w", err)
+		}
+		scope, err := snapshotScope(cfg)
+		if err != nil {
+			return err
+		}
+		tt := timetravel.New(ver, newSnapshotter(cfg, filepath.Join(cfg.Snapshot.OutputDir, scope)), cfg.Snapshot.OutputDir)
+
+		versions, err := tt.Timeline(cmd.Context(), scope, namespace, kind, name)
+		if err != nil {
+			return err
+		}
+		spans := timetravel.Lifespans(versions)
+		if len(spans) == 0 {
+			return fmt.Errorf("%s does not appear in any snapshot", whenResource)
+		}
+
+		if isStructuredOutput(whenOutput) {
+			return printStructured(whenOutput, spans)
+		}
+		printer.Banner()
+		printer.Lifespans(whenResource, spans)
+		return nil
+	},
+}
+
+func init() {
+	whenCmd.Flags().StringVar(&whenResource, "resource", "", "resource to look up: namespace/Kind/name, or Kind/name if cluster-scoped")
+	whenCmd.Flags().StringVarP(&whenOutput, "output", "o", outputTable, "output format: table, json, or yaml")
+	addRepoFlag(whenCmd)
+
+	rootCmd.AddCommand(whenCmd)
+}
@@ -0,0 +1,22 @@
+// Package httpstatus turns failed HTTP responses into errors.
+package httpstatus
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody is how much of a failed response is included in the error.
+const maxErrorBody = 512
+
+// Check fails on a non-2xx response, including the start of its body in
+// the error.
+func Check(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
@@ -0,0 +1,26 @@
+package httpstatus
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func response(code int, body string) *http.Response {
+	return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(response(204, "")))
+
+	err := Check(response(502, " bad gateway\n"))
+	assert.EqualError(t, err, "HTTP 502: bad gateway")
+}
+
+func TestCheck_TruncatesBody(t *testing.T) {
+	err := Check(response(500, strings.Repeat("x", 2*maxErrorBody)))
+	assert.Len(t, err.Error(), len("HTTP 500: ")+maxErrorBody)
+}
@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
@@ -34,9 +35,53 @@ func Init(level, format string) {
 		log.SetFormatter(&log.TextFormatter{
 			FullTimestamp:   true,
 			TimestampFormat: "15:04:05",
-			ForceColors:    true,
+			ForceColors:     true,
 		})
 	}
 
 	log.SetOutput(os.Stderr)
 }
+
+// AddFile copies every log entry to a rotating file at path, in addition to
+// stderr. Entries are written without colors, in the given format.
+func AddFile(path, format string, rotation Rotation) error {
+	file, err := OpenRotatingFile(path, rotation)
+	if err != nil {
+		return err
+	}
+
+	var formatter log.Formatter = &log.TextFormatter{
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02T15:04:05Z07:00",
+		DisableColors:   true,
+	}
+	if strings.ToLower(format) == "json" {
+		formatter = &log.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05Z",
+		}
+	}
+
+	log.AddHook(&fileHook{file: file, formatter: formatter})
+	return nil
+}
+
+// fileHook writes formatted log entries to a file.
+type fileHook struct {
+	file      *RotatingFile
+	formatter log.Formatter
+}
+
+// Levels returns all levels; filtering is done by the global log level.
+func (h *fileHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire writes a single entry to the file.
+func (h *fileHook) Fire(entry *log.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return fmt.Errorf("failed to format log entry: %w", err)
+	}
+	_, err = h.file.Write(line)
+	return err
+}
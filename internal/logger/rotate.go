@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files so that they sort chronologically.
+const backupTimeFormat = "20060102-150405.000"
+
+// Rotation limits how much log history a RotatingFile keeps.
+type Rotation struct {
+	// MaxSize is the size in bytes at which the file is rotated; 0 never rotates.
+	MaxSize int64
+	// MaxAge removes rotated files older than this; 0 keeps them regardless of age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files to keep; 0 keeps all of them.
+	MaxBackups int
+}
+
+// RotatingFile is an append-only log file that is renamed to
+// <path>.<timestamp> once it grows past Rotation.MaxSize.
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	rotation Rotation
+	file     *os.File
+	size     int64
+	now      func() time.Time
+}
+
+// OpenRotatingFile opens path for appending, creating it and its directory if needed.
+func OpenRotatingFile(path string, rotation Rotation) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f := &RotatingFile{path: path, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating the file first if p would push it past MaxSize.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.rotation.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.rotation.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// open opens the log file for appending and records its current size.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside, starts a new one, and prunes old backups.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	backup := f.path + "." + f.now().UTC().Format(backupTimeFormat)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// prune removes backups beyond MaxBackups or older than MaxAge.
+func (f *RotatingFile) prune() error {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return fmt.Errorf("failed to list log backups: %w", err)
+	}
+	// Newest first; the timestamp suffix sorts chronologically.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	cutoff := f.now().Add(-f.rotation.MaxAge)
+	for i, backup := range backups {
+		expired := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		if !expired && f.rotation.MaxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && info.ModTime().Before(cutoff) {
+				expired = true
+			}
+		}
+		if expired {
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old log backup: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// steppingClock returns a clock that advances one second per call.
+func steppingClock() func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestRotatingFile_RotatesAndKeepsBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "watch.log")
+	f, err := OpenRotatingFile(path, Rotation{MaxSize: 10, MaxBackups: 2})
+	require.NoError(t, err)
+	f.now = steppingClock()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth\n", string(current))
+
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "oldest backup is pruned")
+
+	newest, err := os.ReadFile(backups[1])
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(newest))
+}
+
+func TestRotatingFile_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0644))
+
+	f, err := OpenRotatingFile(path, Rotation{})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old\nnew\n", string(content))
+}
+
+func TestRotatingFile_PrunesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.log")
+	stale := path + ".20200101-000000.000"
+	require.NoError(t, os.WriteFile(stale, []byte("stale\n"), 0644))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(stale, old, old))
+
+	f, err := OpenRotatingFile(path, Rotation{MaxSize: 4, MaxAge: 24 * time.Hour})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("one\n"))
+	require.NoError(t, err)
+	_, err = f.Write([]byte("two\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.NoFileExists(t, stale)
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
@@ -3,11 +3,27 @@ package printer
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/expiry"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/fleet"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/gaps"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/learn"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/managers"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/rbac"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/routing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/search"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/storage"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 )
 
@@ -21,6 +37,12 @@ var (
 	dim    = color.New(color.Faint).SprintFunc()
 )
 
+// DisableColor turns colors off, e.g. when text output is written to a
+// file rather than a terminal.
+func DisableColor() {
+	color.NoColor = true
+}
+
 // Banner prints the application banner.
 func Banner() {
 	banner := `
@@ -31,23 +53,127 @@ func Banner() {
 	fmt.Println(cyan(banner))
 }
 
+// ciDescription describes the CI run that took a snapshot on one line.
+func ciDescription(ci *types.CIMetadata) string {
+	desc := ci.Provider
+	if ci.PipelineID != "" {
+		desc += " run " + ci.PipelineID
+	}
+	if ci.Actor != "" {
+		desc += " by " + ci.Actor
+	}
+	if ci.CommitSHA != "" {
+		commit := ci.CommitSHA
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		if ci.Repository != "" {
+			commit = ci.Repository + "@" + commit
+		}
+		desc += dim(" (" + commit + ")")
+	}
+	if ci.PipelineURL != "" {
+		desc += " " + cyan(ci.PipelineURL)
+	}
+	return desc
+}
+
+// collectionDescription summarizes the list calls of a snapshot run and
+// names the slowest resource type.
+func collectionDescription(stats []types.CollectionStats) string {
+	var pages, errs, retries int
+	slowest := stats[0]
+	for _, s := range stats {
+		pages += s.Pages
+		errs += s.Errors
+		retries += s.Retries
+		if s.Duration > slowest.Duration {
+			slowest = s
+		}
+	}
+	return fmt.Sprintf("%d list calls for %d types, %d errors, %d retries · slowest %s (%s)",
+		pages+errs, len(stats), errs, retries, slowest.Resource, roundDuration(slowest.Duration))
+}
+
 // SnapshotSummary prints a summary of a completed snapshot.
 func SnapshotSummary(metadata *types.SnapshotMetadata) {
 	fmt.Println()
 	fmt.Println(bold("📸 Snapshot Captured"))
 	fmt.Println(strings.Repeat("─", 45))
-	fmt.Printf("  ⏰  Time:       %s\n", metadata.Timestamp.Format("2006-01-02 15:04:05 UTC"))
-	fmt.Printf("  🏗️  Cluster:    %s\n", metadata.ClusterName)
+	fmt.Printf("  ⏰  Time:       %s\n", formatTimeAgo(metadata.Timestamp))
+	if len(metadata.Clusters) == 0 {
+		fmt.Printf("  🏗️  Cluster:    %s\n", metadata.ClusterName)
+	}
 	fmt.Printf("  📦  Resources:  %s\n", green(fmt.Sprintf("%d", metadata.ResourceCount)))
 	fmt.Printf("  🗂️  Namespaces: %s\n", cyan(fmt.Sprintf("%d", len(metadata.Namespaces))))
+	if metadata.Scope != nil {
+		fmt.Printf("  🔎  Scope:      %s\n", yellow("partial: "+metadata.Scope.String()))
+	}
 	if metadata.CommitHash != "" {
 		fmt.Printf("  🔗  Commit:     %s\n", dim(metadata.CommitHash[:8]))
 	}
+	if n := metadata.ExpiringCertificates; n > 0 {
+		fmt.Printf("  ⏳  Expiring:   %s\n", yellow(fmt.Sprintf("%d certificate(s) expired or expiring soon", n)))
+	}
+	if n := metadata.PolicyViolations; n > 0 {
+		fmt.Printf("  🚫  Policy:     %s\n", red(fmt.Sprintf("%d image policy violation(s)", n)))
+	}
+	if e := metadata.RBACExposure; e != nil && e.Permissions > 0 {
+		fmt.Printf("  🔐  RBAC:       %s\n", dim(fmt.Sprintf("%d permissions for %d subjects (%d cluster-wide, %d wildcard)",
+			e.Permissions, e.Subjects, e.ClusterWide, e.Wildcards)))
+	}
+	if n := len(metadata.HelmReleases); n > 0 {
+		fmt.Printf("  ⛵  Helm:       %s\n", dim(fmt.Sprintf("%d release(s)", n)))
+	}
+	if metadata.CommitURL != "" {
+		fmt.Printf("  🌐  Link:       %s\n", cyan(metadata.CommitURL))
+	}
+	if ci := metadata.CI; ci != nil {
+		fmt.Printf("  🚀  CI:         %s\n", ciDescription(ci))
+	}
+	if t := metadata.Timings; t != nil {
+		fmt.Printf("  ⏱️  Timings:    %s\n", dim(fmt.Sprintf(
+			"collect %s · serialize %s · stage %s · commit %s (total %s)",
+			roundDuration(t.Collection), roundDuration(t.Serialization),
+			roundDuration(t.Staging), roundDuration(t.Commit), roundDuration(t.Total()))))
+	}
+	if len(metadata.Collection) > 0 {
+		fmt.Printf("  📡  API:        %s\n", dim(collectionDescription(metadata.Collection)))
+	}
+	if len(metadata.Clusters) > 0 {
+		fmt.Printf("  🏗️  Clusters:\n")
+		for _, c := range metadata.Clusters {
+			if c.Failed() {
+				fmt.Printf("      %s %s %s\n", red("✗"), c.Name, dim("(kept previous snapshot: "+c.Error+")"))
+				continue
+			}
+			fmt.Printf("      %s %s %s\n", green("✓"), c.Name,
+				dim(fmt.Sprintf("%d resources in %s", c.ResourceCount, roundDuration(c.Duration))))
+		}
+	}
 	fmt.Println()
 }
 
-// HistoryTable prints the snapshot history as a formatted table.
-func HistoryTable(entries []types.HistoryEntry) {
+// roundDuration trims a duration to a readable precision.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// HistoryColumns are the columns HistoryTable can show, by --columns name.
+var HistoryColumns = []string{"num", "commit", "timestamp", "age", "resources", "message", "author", "cluster", "permissions"}
+
+// defaultHistoryColumns are shown when no columns are selected.
+var defaultHistoryColumns = []string{"num", "commit", "timestamp", "age", "resources", "message"}
+
+// historyShrink is the order in which history columns give up width: the
+// full commit hash goes first, then the message, then the timestamp.
+var historyShrink = []string{"commit", "message", "timestamp", "author", "cluster"}
+
+// HistoryTable prints a formatted table of snapshot history.
+func HistoryTable(entries []types.HistoryEntry, opts TableOptions) {
 	if len(entries) == 0 {
 		fmt.Println(yellow("No snapshots found."))
 		return
@@ -57,8 +183,80 @@ func HistoryTable(entries []types.HistoryEntry) {
 	fmt.Println(bold("📜 Snapshot History"))
 	fmt.Println()
 
+	num := &column{name: "num", header: "#"}
+	commit := &column{name: "commit", header: "Commit"}
+	timestamp := &column{name: "timestamp", header: "Timestamp"}
+	age := &column{name: "age", header: "Age"}
+	resources := &column{name: "resources", header: "Resources"}
+	message := &column{name: "message", header: "Message", minWidth: 20}
+	author := &column{name: "author", header: "Author", minWidth: 10}
+	cluster := &column{name: "cluster", header: "Cluster", minWidth: 10}
+	permissions := &column{name: "permissions", header: "Permissions"}
+
+	for i, entry := range entries {
+		num.add(fmt.Sprintf("%d", i+1))
+		commit.add(entry.CommitHash, entry.CommitHash[:min(8, len(entry.CommitHash))])
+		timestamp.add(formatTime(entry.Timestamp), formatTimeShort(entry.Timestamp))
+		age.add(relativeTime(entry.Timestamp, now()))
+		resources.add(fmt.Sprintf("%d", entry.ResourceCount))
+		message.add(strings.SplitN(strings.TrimSpace(entry.Message), "\n", 2)[0])
+		author.add(entry.Author)
+		cluster.add(entry.ClusterName)
+		if e := entry.RBACExposure; e != nil {
+			permissions.add(fmt.Sprintf("%d", e.Permissions))
+		} else {
+			permissions.add("-")
+		}
+	}
+
+	cols := selectColumns([]*column{num, commit, timestamp, age, resources, message, author, cluster, permissions}, opts.Columns, defaultHistoryColumns)
+	fitColumns(cols, opts.Width, historyShrink)
+	renderColumns(cols)
+	fmt.Println()
+}
+
+// CompositionTable prints how many resources of each group (kind or
+// namespace, as selected by countsOf) every snapshot contained. Only the
+// largest maxColumns groups get their own column; the rest are summed.
+func CompositionTable(entries []types.HistoryEntry, label string, countsOf func(types.HistoryEntry) map[string]int, maxColumns int) {
+	if len(entries) == 0 {
+		fmt.Println(yellow("No snapshots found."))
+		return
+	}
+
+	totals := make(map[string]int)
+	for _, entry := range entries {
+		for group, n := range countsOf(entry) {
+			totals[group] += n
+		}
+	}
+	groups := make([]string, 0, len(totals))
+	for group := range totals {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if totals[groups[i]] != totals[groups[j]] {
+			return totals[groups[i]] > totals[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	other := len(groups) > maxColumns
+	if other {
+		groups = groups[:maxColumns]
+	}
+
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("📊 Snapshot Composition by %s", label)))
+	fmt.Println()
+
+	header := append([]string{"Commit", "Timestamp"}, groups...)
+	if other {
+		header = append(header, "Other")
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"#", "Commit", "Timestamp", "Message"})
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
 	table.SetBorder(false)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
@@ -67,64 +265,930 @@ func HistoryTable(entries []types.HistoryEntry) {
 	table.SetRowSeparator("")
 	table.SetHeaderLine(true)
 
-	for i, entry := range entries {
+	for _, entry := range entries {
 		hash := entry.CommitHash
 		if len(hash) > 8 {
 			hash = hash[:8]
 		}
-		msg := entry.Message
-		if len(msg) > 50 {
-			msg = msg[:50] + "..."
+		counts := countsOf(entry)
+		row := []string{hash, formatTime(entry.Timestamp)}
+		rest := 0
+		for _, n := range counts {
+			rest += n
 		}
-		table.Append([]string{
-			fmt.Sprintf("%d", i+1),
-			hash,
-			entry.Timestamp.Format("2006-01-02 15:04:05"),
-			msg,
-		})
+		for _, group := range groups {
+			row = append(row, fmt.Sprintf("%d", counts[group]))
+			rest -= counts[group]
+		}
+		if other {
+			row = append(row, fmt.Sprintf("%d", rest))
+		}
+		table.Append(row)
 	}
 
 	table.Render()
 	fmt.Println()
 }
 
-// DriftSummary prints a summary of drift analysis.
-func DriftSummary(report *types.DriftReport) {
+// collapseAfter is the number of entries above which DriftSummary hides
+// field diffs unless asked to expand them.
+const collapseAfter = 50
+
+// DriftOptions controls how much of a drift report is printed.
+type DriftOptions struct {
+	// Expand shows field changes even for reports of more than
+	// collapseAfter entries.
+	Expand bool
+	// Width is the line width old and new values are cut to; 0 prints
+	// them in full.
+	Width int
+}
+
+// DriftSummary writes a summary of drift analysis, grouped by severity,
+// then namespace, then kind. Field diffs are hidden for reports of more
+// than collapseAfter entries unless opts.Expand is set.
+func DriftSummary(w io.Writer, report *types.DriftReport, opts DriftOptions) {
+	defer policySection(w, report)
+	defer unmanagedSection(w, report)
+	if !driftHeader(w, report) {
+		return
+	}
+
+	collapsed := !opts.Expand && len(report.Entries) > collapseAfter
+	for _, sg := range groupDrift(report.Entries) {
+		fmt.Fprintf(w, "  %s %s\n", severityLabel(sg.severity), dim(fmt.Sprintf("(%d)", sg.count)))
+		for _, ng := range sg.namespaces {
+			fmt.Fprintf(w, "    %s %s\n", bold(ng.namespace), dim(fmt.Sprintf("(%d)", ng.count)))
+			for _, ks := range ng.kinds {
+				fmt.Fprintf(w, "      %s %s\n", cyan(ks.kind), dim(fmt.Sprintf("(%d)", len(ks.entries))))
+				width := 0
+				for _, entry := range ks.entries {
+					width = max(width, len(entry.Resource.Name))
+				}
+				for _, entry := range ks.entries {
+					driftRow(w, entry, width, "        ", !collapsed, opts.Width)
+				}
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if collapsed {
+		fmt.Fprintln(w, dim(fmt.Sprintf("  Field changes hidden for %d entries; use --expand to show them.", len(report.Entries))))
+		fmt.Fprintln(w)
+	}
+}
+
+// severityGroup is the drift entries of one severity, by namespace.
+type severityGroup struct {
+	severity   string
+	count      int
+	namespaces []namespaceGroup
+}
+
+// namespaceGroup is the drift entries of one namespace, by kind.
+type namespaceGroup struct {
+	namespace string
+	count     int
+	kinds     []kindSection
+}
+
+// kindSection is the drift entries of one kind, sorted by name.
+type kindSection struct {
+	kind    string
+	entries []types.DriftEntry
+}
+
+// severityOrder ranks severities from most to least serious; entries
+// without a severity come last.
+var severityOrder = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+// groupDrift groups entries by severity (most serious first), namespace,
+// and kind, with cluster-scoped resources under "(cluster)".
+func groupDrift(entries []types.DriftEntry) []severityGroup {
+	sorted := make([]types.DriftEntry, len(entries))
+	copy(sorted, entries)
+	rank := func(severity string) int {
+		if r, ok := severityOrder[severity]; ok {
+			return r
+		}
+		return len(severityOrder)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if rank(a.Severity) != rank(b.Severity) {
+			return rank(a.Severity) < rank(b.Severity)
+		}
+		if a.Resource.Namespace != b.Resource.Namespace {
+			return a.Resource.Namespace < b.Resource.Namespace
+		}
+		if a.Resource.Kind != b.Resource.Kind {
+			return a.Resource.Kind < b.Resource.Kind
+		}
+		return a.Resource.Name < b.Resource.Name
+	})
+
+	var groups []severityGroup
+	for _, entry := range sorted {
+		namespace := entry.Resource.Namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+		if len(groups) == 0 || groups[len(groups)-1].severity != entry.Severity {
+			groups = append(groups, severityGroup{severity: entry.Severity})
+		}
+		sg := &groups[len(groups)-1]
+		sg.count++
+		if len(sg.namespaces) == 0 || sg.namespaces[len(sg.namespaces)-1].namespace != namespace {
+			sg.namespaces = append(sg.namespaces, namespaceGroup{namespace: namespace})
+		}
+		ng := &sg.namespaces[len(sg.namespaces)-1]
+		ng.count++
+		if len(ng.kinds) == 0 || ng.kinds[len(ng.kinds)-1].kind != entry.Resource.Kind {
+			ng.kinds = append(ng.kinds, kindSection{kind: entry.Resource.Kind})
+		}
+		ks := &ng.kinds[len(ng.kinds)-1]
+		ks.entries = append(ks.entries, entry)
+	}
+	return groups
+}
+
+// severityLabel returns the colored heading for a severity group.
+func severityLabel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return red("● " + strings.ToUpper(severity))
+	case "medium":
+		return yellow("● " + strings.ToUpper(severity))
+	case "low":
+		return dim("● " + strings.ToUpper(severity))
+	default:
+		return bold("● UNRATED")
+	}
+}
+
+// DriftSummaryGrouped writes a drift summary with entries grouped by the
+// key returned from groupOf (e.g. owning team).
+func DriftSummaryGrouped(w io.Writer, report *types.DriftReport, label string, groupOf func(types.DriftEntry) string, opts DriftOptions) {
+	defer policySection(w, report)
+	defer unmanagedSection(w, report)
+	if !driftHeader(w, report) {
+		return
+	}
+
+	groups := make(map[string][]types.DriftEntry)
+	var keys []string
+	for _, entry := range report.Entries {
+		key := groupOf(entry)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], entry)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s %s %s\n", bold(label+":"), cyan(key), dim(fmt.Sprintf("(%d)", len(groups[key]))))
+		for _, entry := range groups[key] {
+			driftEntry(w, entry, "    ", opts.Width)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// driftHeader prints the drift counters and reports whether there is drift to list.
+func driftHeader(w io.Writer, report *types.DriftReport) bool {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, bold("🔍 Drift Analysis"))
+	fmt.Fprintln(w, strings.Repeat("─", 45))
+
+	if len(report.Entries) == 0 {
+		fmt.Fprintln(w, green("  ✅ No drift detected — infrastructure matches!"))
+		if report.Scope != nil {
+			fmt.Fprintf(w, "  %s\n", yellow(report.Scope.Describe()))
+		}
+		fmt.Fprintln(w)
+		return false
+	}
+
+	fmt.Fprintf(w, "  Added:     %s\n", green(fmt.Sprintf("+%d", report.Summary.AddedResources)))
+	fmt.Fprintf(w, "  Removed:   %s\n", red(fmt.Sprintf("-%d", report.Summary.RemovedResources)))
+	fmt.Fprintf(w, "  Modified:  %s\n", yellow(fmt.Sprintf("~%d", report.Summary.ModifiedResources)))
+	if report.Summary.RecreatedResources > 0 {
+		fmt.Fprintf(w, "  Recreated: %s\n", cyan(fmt.Sprintf("*%d", report.Summary.RecreatedResources)))
+	}
+	if report.Summary.ScaledResources > 0 {
+		fmt.Fprintf(w, "  Scaled:    %s\n", cyan(fmt.Sprintf("^%d", report.Summary.ScaledResources)))
+	}
+	if report.Summary.OwnershipChanges > 0 {
+		fmt.Fprintf(w, "  Ownership: %s\n", cyan(fmt.Sprintf("@%d", report.Summary.OwnershipChanges)))
+	}
+	fmt.Fprintf(w, "  Unchanged: %s\n", dim(fmt.Sprintf("%d", report.Summary.UnchangedResources)))
+	if report.Scope != nil {
+		fmt.Fprintf(w, "  Scope:     %s\n", yellow(report.Scope.Describe()))
+	}
+	if report.BaseURL != "" {
+		fmt.Fprintf(w, "  Base:      %s\n", cyan(report.BaseURL))
+	}
+	if report.TargetURL != "" {
+		fmt.Fprintf(w, "  Target:    %s\n", cyan(report.TargetURL))
+	}
+	fmt.Fprintln(w)
+
+	if len(report.APIChanges) > 0 {
+		fmt.Fprintln(w, bold("  ⚠️  API Changes (cluster-wide impact)"))
+		for _, change := range report.APIChanges {
+			fmt.Fprintf(w, "    %s %s\n", driftMarker(change.Type), change.CRD)
+			if len(change.AddedVersions) > 0 {
+				fmt.Fprintf(w, "        versions added:   %s\n", green(strings.Join(change.AddedVersions, ", ")))
+			}
+			if len(change.RemovedVersions) > 0 {
+				fmt.Fprintf(w, "        versions removed: %s\n", red(strings.Join(change.RemovedVersions, ", ")))
+			}
+			if len(change.SchemaChanged) > 0 {
+				fmt.Fprintf(w, "        schema changed:   %s\n", yellow(strings.Join(change.SchemaChanged, ", ")))
+			}
+			if change.Type == types.DriftModified && change.OldStorage != change.NewStorage {
+				fmt.Fprintf(w, "        storage version:  %s → %s\n", change.OldStorage, change.NewStorage)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	return true
+}
+
+// unmanagedSection lists the resources no desired-state source accounts
+// for, by namespace.
+func unmanagedSection(w io.Writer, report *types.DriftReport) {
+	if len(report.Unmanaged) == 0 {
+		return
+	}
+	fmt.Fprintln(w, bold(fmt.Sprintf("  ⚠️  Unmanaged resources (%d)", len(report.Unmanaged))))
+	fmt.Fprintln(w, dim("  Not deployed by Helm, Argo CD, or Flux, not created by another resource, and not in the desired state"))
+	namespace := "\x00"
+	for _, ref := range report.Unmanaged {
+		if ref.Namespace != namespace {
+			namespace = ref.Namespace
+			label := namespace
+			if label == "" {
+				label = "(cluster)"
+			}
+			fmt.Fprintf(w, "    %s\n", cyan(label))
+		}
+		fmt.Fprintf(w, "      %s/%s\n", ref.Kind, ref.Name)
+	}
+	fmt.Fprintln(w)
+}
+
+// policySection lists the policy violations of the compared snapshot, by
+// resource.
+func policySection(w io.Writer, report *types.DriftReport) {
+	if len(report.PolicyViolations) == 0 {
+		return
+	}
+	fmt.Fprintln(w, bold(fmt.Sprintf("  🚫 Policy violations (%d)", len(report.PolicyViolations))))
+	for _, v := range report.PolicyViolations {
+		name := v.Resource.Kind + "/" + v.Resource.Name
+		if v.Resource.Namespace != "" {
+			name = v.Resource.Kind + "/" + v.Resource.Namespace + "/" + v.Resource.Name
+		}
+		fmt.Fprintf(w, "    %s %s %s\n", severityLabel(v.Severity), name, dim("("+v.Rule+")"))
+		fmt.Fprintf(w, "        %s\n", v.Message)
+	}
+	fmt.Fprintln(w)
+}
+
+// driftMarker returns the colored marker for a drift type.
+func driftMarker(t types.DriftType) string {
+	switch t {
+	case types.DriftAdded:
+		return green("[+]")
+	case types.DriftRemoved:
+		return red("[-]")
+	case types.DriftRecreated:
+		return cyan("[*]")
+	case types.DriftScaled:
+		return cyan("[^]")
+	case types.DriftOwnership:
+		return cyan("[@]")
+	default:
+		return yellow("[~]")
+	}
+}
+
+// driftEntry prints a single drift entry and its field diffs.
+func driftEntry(w io.Writer, entry types.DriftEntry, indent string, width int) {
+	name := entry.Resource.FullName()
+	if entry.Team != "" {
+		name += " " + dim("("+entry.Team+")")
+	}
+
+	fmt.Fprintf(w, "%s%s %s\n", indent, driftMarker(entry.Type), name)
+	driftDetails(w, entry, indent, true, width)
+}
+
+// driftRow prints a drift entry as an aligned row of its kind section:
+// marker, name padded to nameWidth, and the number of changed fields.
+func driftRow(w io.Writer, entry types.DriftEntry, nameWidth int, indent string, fields bool, width int) {
+	row := fmt.Sprintf("%-*s", nameWidth, entry.Resource.Name)
+	if n := len(entry.FieldDiffs); n > 0 {
+		row += "  " + dim(fmt.Sprintf("%d field(s)", n))
+	}
+	if entry.Team != "" {
+		row += "  " + dim("("+entry.Team+")")
+	}
+
+	fmt.Fprintf(w, "%s%s %s\n", indent, driftMarker(entry.Type), strings.TrimRight(row, " "))
+	driftDetails(w, entry, indent, fields, width)
+}
+
+// driftDetails prints the summaries of a drift entry and, if fields is
+// set, its field diffs with values cut to fit width.
+func driftDetails(w io.Writer, entry types.DriftEntry, indent string, fields bool, width int) {
+	if entry.Type == types.DriftRecreated && entry.Resource.CreationTimestamp != "" {
+		created := entry.Resource.CreationTimestamp
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			created = formatTimeAgo(t)
+		}
+		fmt.Fprintf(w, "%s    %s\n", indent, dim("recreated at "+created))
+	}
+
+	for _, summary := range entry.Summaries {
+		fmt.Fprintf(w, "%s    %s %s\n", indent, cyan("⇒"), summary)
+	}
+	if entry.URL != "" {
+		fmt.Fprintf(w, "%s    %s\n", indent, dim(entry.URL))
+	}
+
+	if !fields {
+		return
+	}
+	valueWidth := 0
+	if width > 0 {
+		// Keep at least a readable prefix on very narrow terminals
+		valueWidth = max(width-len(indent)-8, 20)
+	}
+	for _, diff := range entry.FieldDiffs {
+		fmt.Fprintf(w, "%s    %s %s\n", indent, dim("•"), diff.Path)
+		if diff.OldValue != nil {
+			fmt.Fprintf(w, "%s      %s %s\n", indent, red("-"), truncate(fmt.Sprintf("%v", diff.OldValue), valueWidth))
+		}
+		if diff.NewValue != nil {
+			fmt.Fprintf(w, "%s      %s %s\n", indent, green("+"), truncate(fmt.Sprintf("%v", diff.NewValue), valueWidth))
+		}
+	}
+}
+
+// RBACChanges prints effective permission changes grouped by subject.
+func RBACChanges(changes []rbac.Change) {
+	fmt.Println()
+	fmt.Println(bold("🔐 RBAC Permission Changes"))
+	fmt.Println(strings.Repeat("─", 45))
+
+	if len(changes) == 0 {
+		fmt.Println(green("  ✅ No effective permission changes"))
+		fmt.Println()
+		return
+	}
+
+	subject := ""
+	for _, change := range changes {
+		if change.Subject != subject {
+			subject = change.Subject
+			fmt.Printf("\n  %s\n", bold(subject))
+		}
+		if change.Gained {
+			fmt.Printf("    %s %s\n", green("+"), change.String())
+		} else {
+			fmt.Printf("    %s %s\n", red("-"), change.String())
+		}
+		fmt.Printf("      %s\n", dim("via "+change.Via))
+	}
+	fmt.Println()
+}
+
+// RBACHistory prints the snapshots at which effective permissions changed,
+// oldest first, with what was gained and lost.
+func RBACHistory(steps []rbac.Step, subject string) {
 	fmt.Println()
-	fmt.Println(bold("🔍 Drift Analysis"))
+	title := "🔐 RBAC Permission History"
+	if subject != "" {
+		title += " of " + subject
+	}
+	fmt.Println(bold(title))
 	fmt.Println(strings.Repeat("─", 45))
 
-	if len(report.Entries) == 0 {
-		fmt.Println(green("  ✅ No drift detected — infrastructure matches!"))
+	if len(steps) == 0 {
+		fmt.Println(green("  ✅ No effective permission changes in this period"))
 		fmt.Println()
 		return
 	}
 
-	fmt.Printf("  Added:     %s\n", green(fmt.Sprintf("+%d", report.Summary.AddedResources)))
-	fmt.Printf("  Removed:   %s\n", red(fmt.Sprintf("-%d", report.Summary.RemovedResources)))
-	fmt.Printf("  Modified:  %s\n", yellow(fmt.Sprintf("~%d", report.Summary.ModifiedResources)))
-	fmt.Printf("  Unchanged: %s\n", dim(fmt.Sprintf("%d", report.Summary.UnchangedResources)))
+	for _, step := range steps {
+		e := step.Exposure
+		fmt.Printf("\n  %s %s  %s\n", cyan(formatTime(step.Timestamp)), dim(step.CommitHash[:min(8, len(step.CommitHash))]),
+			dim(fmt.Sprintf("→ %d permissions for %d subjects (%d cluster-wide, %d wildcard)", e.Permissions, e.Subjects, e.ClusterWide, e.Wildcards)))
+		for _, change := range step.Changes {
+			marker := red("-")
+			if change.Gained {
+				marker = green("+")
+			}
+			fmt.Printf("    %s %s\n", marker, change.String())
+			fmt.Printf("      %s\n", dim("via "+change.Via))
+		}
+	}
 	fmt.Println()
+}
 
-	for _, entry := range report.Entries {
-		switch entry.Type {
+// RoutingTable prints the routes of a snapshot, one row per backend.
+func RoutingTable(routes []routing.Route, commitHash string) {
+	fmt.Println()
+	title := "🚦 Routing Table"
+	if commitHash != "" {
+		title += " @ " + commitHash[:min(8, len(commitHash))]
+	}
+	fmt.Println(bold(title))
+	fmt.Println()
+
+	if len(routes) == 0 {
+		fmt.Println(dim("  No Ingress or HTTPRoute routes in this snapshot"))
+		fmt.Println()
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Host/Path", "Service", "Workloads", "Source"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	for _, route := range routes {
+		for i, b := range route.Backends {
+			target, source := route.Target(), route.Source
+			if i > 0 {
+				target, source = "", ""
+			}
+			svc := b.Service
+			if b.Port != "" {
+				svc += ":" + b.Port
+			}
+			if b.Weight != nil {
+				svc += fmt.Sprintf(" (%d)", *b.Weight)
+			}
+			workloads := strings.Join(b.Workloads, ", ")
+			if workloads == "" {
+				workloads = dim("-")
+			}
+			table.Append([]string{target, svc, workloads, dim(source)})
+		}
+	}
+	table.Render()
+	fmt.Println()
+}
+
+// RoutingChanges prints the routes added, removed, or sent to different
+// backends between two snapshots.
+func RoutingChanges(changes []routing.Change) {
+	fmt.Println()
+	fmt.Println(bold("🚦 Routing Changes"))
+	fmt.Println(strings.Repeat("─", 45))
+
+	if len(changes) == 0 {
+		fmt.Println(green("  ✅ No routing changes"))
+		fmt.Println()
+		return
+	}
+
+	for _, change := range changes {
+		switch change.Type {
 		case types.DriftAdded:
-			fmt.Printf("  %s %s\n", green("[+]"), entry.Resource.FullName())
+			fmt.Printf("\n  %s %s %s\n", green("+"), bold(change.Target()), dim("via "+change.Source))
 		case types.DriftRemoved:
-			fmt.Printf("  %s %s\n", red("[-]"), entry.Resource.FullName())
-		case types.DriftModified:
-			fmt.Printf("  %s %s\n", yellow("[~]"), entry.Resource.FullName())
-			for _, diff := range entry.FieldDiffs {
-				fmt.Printf("      %s %s\n", dim("•"), diff.Path)
-				if diff.OldValue != nil {
-					fmt.Printf("        %s %v\n", red("-"), diff.OldValue)
-				}
-				if diff.NewValue != nil {
-					fmt.Printf("        %s %v\n", green("+"), diff.NewValue)
-				}
+			fmt.Printf("\n  %s %s %s\n", red("-"), bold(change.Target()), dim("via "+change.Source))
+		default:
+			fmt.Printf("\n  %s %s %s\n", yellow("~"), bold(change.Target()), dim("via "+change.Source))
+		}
+		for _, b := range change.Before {
+			fmt.Printf("      %s %s\n", red("-"), b.String())
+		}
+		for _, b := range change.After {
+			fmt.Printf("      %s %s\n", green("+"), b.String())
+		}
+	}
+	fmt.Println()
+}
+
+// StorageHistory prints the requested storage of each namespace over a
+// period, then each snapshot at which a namespace's claims changed.
+func StorageHistory(report storage.Report, namespace string) {
+	fmt.Println()
+	title := "💾 Storage History"
+	if namespace != "" {
+		title += " of " + namespace
+	}
+	fmt.Println(bold(title))
+	fmt.Println()
+
+	if len(report.Namespaces) == 0 {
+		fmt.Println(dim("  No PersistentVolumeClaims in this period"))
+		fmt.Println()
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Namespace", "Claims", "Start", "End", "Growth", "Doubled"})
+	table.SetBorder(false)
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+	for _, s := range report.Namespaces {
+		growth, doubled := "-", "-"
+		if s.Start > 0 {
+			growth = fmt.Sprintf("×%.2f", s.Growth())
+		}
+		if s.DoubledAt != nil {
+			doubled = formatTime(*s.DoubledAt) + " " + s.DoubledCommit[:min(8, len(s.DoubledCommit))]
+		}
+		table.Append([]string{s.Namespace, fmt.Sprintf("%d", s.Claims),
+			storage.FormatBytes(s.Start), storage.FormatBytes(s.End), growth, doubled})
+	}
+	table.Render()
+
+	if len(report.Steps) == 0 {
+		fmt.Println()
+		fmt.Println(green("  ✅ No claim changes in this period"))
+		fmt.Println()
+		return
+	}
+	for _, step := range report.Steps {
+		summary := fmt.Sprintf("%s: %s → %s", step.Namespace, storage.FormatBytes(step.Before), storage.FormatBytes(step.After))
+		if step.Doubled {
+			summary += " " + yellow("(doubled)")
+		}
+		fmt.Printf("\n  %s %s  %s\n", cyan(formatTime(step.Timestamp)), dim(step.CommitHash[:min(8, len(step.CommitHash))]), summary)
+		for _, c := range step.Changes {
+			switch {
+			case c.BeforeClass == "":
+				fmt.Printf("    %s %s %s (%s)\n", green("+"), c.Name, storage.FormatBytes(c.After), c.AfterClass)
+			case c.AfterClass == "":
+				fmt.Printf("    %s %s %s (%s)\n", red("-"), c.Name, storage.FormatBytes(c.Before), c.BeforeClass)
+			case c.BeforeClass != c.AfterClass:
+				fmt.Printf("    %s %s %s (%s) → %s (%s)\n", yellow("~"), c.Name,
+					storage.FormatBytes(c.Before), c.BeforeClass, storage.FormatBytes(c.After), c.AfterClass)
+			default:
+				fmt.Printf("    %s %s %s → %s (%s)\n", yellow("~"), c.Name,
+					storage.FormatBytes(c.Before), storage.FormatBytes(c.After), c.AfterClass)
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// AccessReport prints whether each configured resource type can be collected.
+func AccessReport(results []collector.Access) {
+	fmt.Println()
+	fmt.Println(bold("🔑 Collector Access"))
+	fmt.Println(strings.Repeat("─", 45))
+
+	for _, a := range results {
+		switch {
+		case !a.Known:
+			fmt.Printf("  %s %s %s\n", yellow("?"), a.ResourceType, dim("("+a.Reason+")"))
+		case a.Allowed:
+			fmt.Printf("  %s %s\n", green("✓"), a.ResourceType)
+		default:
+			line := fmt.Sprintf("  %s %s", red("✗"), a.ResourceType)
+			if a.Reason != "" {
+				line += " " + dim("("+a.Reason+")")
+			}
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+}
+
+// RestoreResults prints the outcome of a restore per resource.
+func RestoreResults(results []restorer.Result, dryRun bool) {
+	title := "♻️  Restore Results"
+	if dryRun {
+		title += " (dry run)"
+	}
+	fmt.Println()
+	fmt.Println(bold(title))
+	fmt.Println(strings.Repeat("─", 45))
+
+	counts := make(map[restorer.Status]int)
+	for _, r := range results {
+		counts[r.Status]++
+		switch r.Status {
+		case restorer.StatusApplied:
+			fmt.Printf("  %s %s\n", green("✓"), r.Resource)
+		case restorer.StatusSkipped:
+			fmt.Printf("  %s %s %s\n", yellow("↷"), r.Resource, dim("("+r.Message+")"))
+		default:
+			fmt.Printf("  %s %s\n", red("✗"), r.Resource)
+			fmt.Printf("      %s\n", red(r.Message))
+		}
+		for _, w := range r.Warnings {
+			fmt.Printf("      %s %s: %s\n", yellow("⚠"), w.Rule.Path, dim(w.Rule.Reason))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  Applied: %s  Skipped: %s  Failed: %s\n",
+		green(fmt.Sprintf("%d", counts[restorer.StatusApplied])),
+		yellow(fmt.Sprintf("%d", counts[restorer.StatusSkipped])),
+		red(fmt.Sprintf("%d", counts[restorer.StatusFailed])))
+	fmt.Println()
+}
+
+// RestoreVerification prints restored resources that did not converge.
+func RestoreVerification(divergences []restorer.Divergence, applied int) {
+	if len(divergences) == 0 {
+		Success(fmt.Sprintf("All %d restored resource(s) match the snapshot", applied))
+		return
+	}
+
+	Warning(fmt.Sprintf("%d of %d restored resource(s) did not converge:", len(divergences), applied))
+	for _, d := range divergences {
+		fmt.Printf("  %s %s\n", red("✗"), d.Resource)
+		fmt.Printf("      %s\n", dim(d.Reason))
+		for _, diff := range d.FieldDiffs {
+			fmt.Printf("      %s: %s → %s\n", diff.Path,
+				red(fmt.Sprintf("%v", diff.OldValue)), yellow(fmt.Sprintf("%v", diff.NewValue)))
+		}
+	}
+	fmt.Println()
+}
+
+// SearchResults prints where and when a searched value appeared.
+func SearchResults(result *search.Result) {
+	if len(result.Appearances) == 0 {
+		fmt.Println(yellow(fmt.Sprintf("%q not found in %d snapshot(s).", result.Query.Value, result.Snapshots)))
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("🔍 %q in %d resource(s)", result.Query.Value, len(result.Appearances))))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Resource", "Field", "First Seen", "Last Seen", "Removed"})
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	point := func(p search.Point) string {
+		return fmt.Sprintf("%s %s", p.CommitHash[:8], formatTime(p.Timestamp))
+	}
+	for _, app := range result.Appearances {
+		for i, span := range app.Spans {
+			resource, field := "", ""
+			if i == 0 {
+				resource, field = app.Resource, strings.Join(app.Paths, ", ")
+			}
+			removed := "still present"
+			if span.Removed != nil {
+				removed = point(*span.Removed)
+			}
+			table.Append([]string{resource, field, point(span.First), point(span.Last), removed})
+		}
+	}
+
+	table.Render()
+	fmt.Println()
+	fmt.Printf("  Scanned %d snapshot(s)\n", result.Snapshots)
+	fmt.Println()
+}
+
+// Lifespans prints when a resource appeared in and was removed from the snapshots.
+func Lifespans(resource string, spans []types.Lifespan) {
+	fmt.Println()
+	fmt.Println(bold("📅 " + resource))
+	fmt.Println(strings.Repeat("─", 45))
+
+	version := func(v types.ResourceVersion) string {
+		return fmt.Sprintf("%s  %s", formatTimeAgo(v.Timestamp), cyan(v.CommitHash[:8]))
+	}
+	for i, span := range spans {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("  %s %s\n", green("Appeared:"), version(span.Appeared))
+		if span.Removed != nil {
+			fmt.Printf("  %s  %s\n", red("Removed:"), version(*span.Removed))
+		} else {
+			fmt.Printf("  %s  %s\n", dim("Removed:"), dim("still present"))
+		}
+	}
+	fmt.Println()
+}
+
+// ResourceHistory prints the changes of one resource, oldest first, with
+// the field diffs of each against the version before, cut to fit width.
+func ResourceHistory(resource string, changes []analyzer.ResourceChange, width int) {
+	fmt.Println()
+	fmt.Println(bold("📜 " + resource))
+	fmt.Println(strings.Repeat("─", 45))
+
+	for _, c := range changes {
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		fmt.Printf("\n  %s %s  %s  %s\n", driftMarker(c.Type), cyan(c.CommitHash[:8]), formatTimeAgo(c.Timestamp), dim(subject))
+		driftDetails(os.Stdout, types.DriftEntry{Type: c.Type, FieldDiffs: c.FieldDiffs}, "  ", true, width)
+	}
+	fmt.Println()
+	fmt.Printf("  %s\n\n", dim(fmt.Sprintf("%d change(s)", len(changes))))
+}
+
+// FleetMatrix prints which clusters deviate from the reference cluster, one
+// FieldManagers prints which field managers own the resources of each namespace.
+func FieldManagers(report *managers.Report) {
+	fmt.Println()
+	title := "👥 Field Managers"
+	if report.CommitHash != "" {
+		title += " @ " + report.CommitHash[:8]
+	}
+	fmt.Println(bold(title))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Namespace", "Manager", "Resources"})
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	for _, ns := range report.Namespaces {
+		for i, usage := range ns.Managers {
+			namespace := ""
+			if i == 0 {
+				namespace = fmt.Sprintf("%s (%d)", ns.Namespace, ns.Resources)
 			}
+			manager := usage.Manager
+			if manager == managers.Unmanaged {
+				manager = dim(manager)
+			}
+			table.Append([]string{namespace, manager, fmt.Sprintf("%d", usage.Resources)})
+		}
+	}
+	table.Render()
+	fmt.Println()
+
+	fmt.Println(bold("  Totals"))
+	for _, usage := range report.Totals {
+		fmt.Printf("    %-40s %d\n", usage.Manager, usage.Resources)
+	}
+	fmt.Println()
+}
+
+// row per deviating resource, followed by the differing fields.
+func FleetMatrix(matrix *fleet.Matrix) {
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("🌍 Fleet Drift vs %s", matrix.Reference)))
+	fmt.Println()
+
+	if len(matrix.Rows) == 0 {
+		Success(fmt.Sprintf("All %d resource(s) are identical across %d cluster(s)", matrix.Identical, len(matrix.Clusters)+1))
+		fmt.Println()
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(append([]string{"Resource"}, matrix.Clusters...))
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	for _, row := range matrix.Rows {
+		line := []string{row.Resource}
+		for _, name := range matrix.Clusters {
+			cell := row.Clusters[name]
+			switch cell.State {
+			case fleet.StateIdentical:
+				line = append(line, "✓")
+			case fleet.StateDiffers:
+				line = append(line, fmt.Sprintf("~%d field(s)", len(cell.FieldDiffs)))
+			default:
+				line = append(line, string(cell.State))
+			}
+		}
+		table.Append(line)
+	}
+	table.Render()
+	fmt.Println()
+
+	for _, row := range matrix.Rows {
+		for _, name := range matrix.Clusters {
+			cell := row.Clusters[name]
+			if cell.State != fleet.StateDiffers {
+				continue
+			}
+			fmt.Printf("  %s %s\n", yellow("~"), bold(row.Resource+" @ "+name))
+			for _, diff := range cell.FieldDiffs {
+				fmt.Printf("      %s: %s → %s\n", diff.Path,
+					red(fmt.Sprintf("%v", diff.OldValue)), yellow(fmt.Sprintf("%v", diff.NewValue)))
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  Deviating: %s  Identical: %s\n",
+		yellow(fmt.Sprintf("%d", len(matrix.Rows))), green(fmt.Sprintf("%d", matrix.Identical)))
+	fmt.Println()
+}
+
+// Violations prints policy violations, most severe first.
+func Violations(violations []policy.Violation) {
+	sorted := append([]policy.Violation(nil), violations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Severity > sorted[j].Severity
+	})
+
+	for _, v := range sorted {
+		severity := fmt.Sprintf("[%s]", strings.ToUpper(v.Severity.String()))
+		if v.Severity >= policy.SeverityHigh {
+			severity = red(severity)
+		} else {
+			severity = yellow(severity)
+		}
+		line := fmt.Sprintf("  %s %s: %s", severity, v.Rule, v.Message)
+		if v.Resource != "" {
+			line += " " + dim(v.Resource)
+		}
+		fmt.Println(line)
+	}
+}
+
+// Anomalies prints anomalous deltas with their scores.
+func Anomalies(anomalies []types.Anomaly) {
+	for _, a := range anomalies {
+		fmt.Printf("  %s %s: %d changes %s\n",
+			yellow(fmt.Sprintf("[score %.1f]", a.Score)), a.Series, a.Changes,
+			dim(fmt.Sprintf("(usually %.1f ± %.1f)", a.Mean, a.StdDev)))
+	}
+}
+
+// Gaps prints the windows of the history in which scheduled snapshots are
+// missing.
+func Gaps(found []gaps.Gap) {
+	for _, g := range found {
+		to := formatTime(g.To)
+		if g.Ongoing {
+			to = "now"
 		}
+		fmt.Printf("  %s %s → %s %s\n",
+			yellow(fmt.Sprintf("[%d missed]", g.Missed)), formatTime(g.From), to,
+			dim("("+g.To.Sub(g.From).Round(time.Minute).String()+")"))
 	}
+}
+
+// FlappingFields prints the ignore rules proposed by learn.
+func FlappingFields(proposals []learn.Proposal, snapshots int) {
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("🔁 Flapping Fields (%d snapshots)", snapshots)))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Kind", "Field", "Changed", "Resources", "Example"})
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	for _, p := range proposals {
+		table.Append([]string{
+			p.Kind,
+			cyan(p.Path),
+			fmt.Sprintf("%d/%d (%.0f%%)", p.Changes, p.Observed, 100*p.Rate()),
+			fmt.Sprintf("%d of %d", p.Flapping, p.Resources),
+			dim(p.Example),
+		})
+	}
+	table.Render()
 	fmt.Println()
 }
 
@@ -138,7 +1202,92 @@ func Error(msg string) {
 	fmt.Printf("%s %s\n", red("✗"), msg)
 }
 
+// Warning prints a warning message.
+func Warning(msg string) {
+	fmt.Printf("%s %s\n", yellow("⚠"), msg)
+}
+
 // Info prints an info message.
 func Info(msg string) {
 	fmt.Printf("%s %s\n", cyan("ℹ"), msg)
 }
+
+// ExpiringCertificates prints certificates and when they expire, marking
+// those expired or expiring within the window.
+func ExpiringCertificates(certs []expiry.Certificate, within time.Duration) {
+	fmt.Println()
+	fmt.Println(bold("⏳ Certificate Expiry"))
+	fmt.Println()
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Status", "Namespace", "Resource", "Subject", "Expires"})
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	ref := now()
+	for _, cert := range certs {
+		status := green("ok")
+		switch {
+		case cert.Expired(ref):
+			status = red("expired")
+		case cert.NotAfter.Before(ref.Add(within)):
+			status = yellow("expiring")
+		}
+		table.Append([]string{
+			status,
+			cert.Namespace,
+			cert.Kind + "/" + cert.Name,
+			cert.Subject,
+			fmt.Sprintf("%s (%s)", formatTime(cert.NotAfter), relativeTime(cert.NotAfter, ref)),
+		})
+	}
+	table.Render()
+	fmt.Println()
+}
+
+// Survival prints how much of a past snapshot is still live: a summary
+// line, then the changed and gone resources. Unchanged resources are only
+// counted unless all is set.
+func Survival(r *analyzer.SurvivalReport, all bool) {
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("🧬 Snapshot %s (%s) against the live state", r.Commit[:8], formatTime(r.Timestamp))))
+	fmt.Println()
+
+	total := r.Total()
+	percent := 0.0
+	if total > 0 {
+		percent = 100 * float64(len(r.Unchanged)) / float64(total)
+	}
+	fmt.Printf("  %s unchanged, %s changed, %s gone of %d resources (%.0f%% survives unchanged)\n",
+		green(len(r.Unchanged)), yellow(len(r.Changed)), red(len(r.Gone)), total, percent)
+	if r.New > 0 {
+		fmt.Printf("  %s\n", dim(fmt.Sprintf("%d live resources were created since", r.New)))
+	}
+	fmt.Println()
+
+	changed := make([]string, 0, len(r.Changed))
+	for name := range r.Changed {
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+	for _, name := range changed {
+		fmt.Printf("  %s %s %s\n", yellow("~"), name, dim(strings.ToLower(string(r.Changed[name]))))
+	}
+	for _, name := range r.Gone {
+		fmt.Printf("  %s %s\n", red("-"), name)
+	}
+	if all {
+		for _, name := range r.Unchanged {
+			fmt.Printf("  %s %s\n", green("="), name)
+		}
+	}
+	if len(changed) > 0 || len(r.Gone) > 0 || (all && len(r.Unchanged) > 0) {
+		fmt.Println()
+	}
+}
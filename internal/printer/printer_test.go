@@ -0,0 +1,46 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func driftOf(severity, namespace, kind, name string) types.DriftEntry {
+	return types.DriftEntry{
+		Type:     types.DriftModified,
+		Severity: severity,
+		Resource: types.Resource{Kind: kind, Namespace: namespace, Name: name},
+	}
+}
+
+func TestGroupDrift(t *testing.T) {
+	groups := groupDrift([]types.DriftEntry{
+		driftOf("low", "prod", "Deployment", "web"),
+		driftOf("high", "prod", "Secret", "db"),
+		driftOf("", "prod", "ConfigMap", "x"),
+		driftOf("low", "prod", "Deployment", "api"),
+		driftOf("low", "", "ClusterRole", "viewer"),
+		driftOf("low", "prod", "ConfigMap", "app"),
+	})
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "high", groups[0].severity)
+	assert.Equal(t, "", groups[2].severity, "unrated entries come last")
+
+	low := groups[1]
+	assert.Equal(t, "low", low.severity)
+	assert.Equal(t, 4, low.count)
+	require.Len(t, low.namespaces, 2)
+	assert.Equal(t, "(cluster)", low.namespaces[0].namespace)
+
+	prod := low.namespaces[1]
+	assert.Equal(t, 3, prod.count)
+	require.Len(t, prod.kinds, 2)
+	assert.Equal(t, "ConfigMap", prod.kinds[0].kind)
+	assert.Equal(t, "Deployment", prod.kinds[1].kind)
+	require.Len(t, prod.kinds[1].entries, 2)
+	assert.Equal(t, "api", prod.kinds[1].entries[0].Resource.Name)
+}
@@ -0,0 +1,98 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval spaces out progress lines when stderr is not a terminal.
+const progressInterval = 10 * time.Second
+
+// progressBarWidth is the number of cells in the live progress bar.
+const progressBarWidth = 20
+
+// Progress reports the advance of long-running phases on stderr. On a
+// terminal it redraws a single status line; otherwise it prints a line at
+// most every progressInterval, so quick runs and CI logs stay quiet.
+// A nil or disabled Progress does nothing.
+type Progress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	enabled  bool
+	live     bool
+	lastLine time.Time
+	width    int
+}
+
+// NewProgress creates a Progress writing to stderr.
+func NewProgress(enabled bool) *Progress {
+	p := &Progress{out: os.Stderr, enabled: enabled, lastLine: time.Now()}
+	if info, err := os.Stderr.Stat(); err == nil {
+		p.live = info.Mode()&os.ModeCharDevice != 0
+	}
+	return p
+}
+
+// Update reports done of total units in phase. A zero total means the
+// total is unknown and no bar is drawn.
+func (p *Progress) Update(phase string, done, total int, detail string) {
+	if p == nil || !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line := formatProgress(phase, done, total, detail)
+	if p.live {
+		pad := p.width - len(line)
+		if pad < 0 {
+			pad = 0
+		}
+		fmt.Fprintf(p.out, "\r%s%s", line, strings.Repeat(" ", pad))
+		p.width = len(line)
+		return
+	}
+
+	if time.Since(p.lastLine) < progressInterval {
+		return
+	}
+	p.lastLine = time.Now()
+	fmt.Fprintln(p.out, line)
+}
+
+// Done clears the live status line so that regular output can follow.
+func (p *Progress) Done() {
+	if p == nil || !p.enabled {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.live && p.width > 0 {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", p.width))
+		p.width = 0
+	}
+}
+
+// formatProgress renders a single progress line.
+func formatProgress(phase string, done, total int, detail string) string {
+	line := fmt.Sprintf("⏳ %-10s", phase)
+	if total > 0 {
+		filled := done * progressBarWidth / total
+		if filled > progressBarWidth {
+			filled = progressBarWidth
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+		line += fmt.Sprintf(" %s %d/%d", bar, done, total)
+	} else {
+		line += fmt.Sprintf(" %d", done)
+	}
+	if detail != "" {
+		line += " · " + detail
+	}
+	return line
+}
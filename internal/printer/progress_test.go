@@ -0,0 +1,40 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatProgress(t *testing.T) {
+	assert.Equal(t, "⏳ collecting █████░░░░░░░░░░░░░░░ 1/4 · 120 resources",
+		formatProgress("collecting", 1, 4, "120 resources"))
+	assert.Equal(t, "⏳ writing    42", formatProgress("writing", 42, 0, ""))
+}
+
+func TestProgress_ThrottlesWhenNotLive(t *testing.T) {
+	var out bytes.Buffer
+	p := &Progress{out: &out, enabled: true, lastLine: time.Now()}
+
+	p.Update("collecting", 1, 4, "")
+	assert.Empty(t, out.String(), "no line before the interval elapses")
+
+	p.lastLine = time.Now().Add(-progressInterval)
+	p.Update("collecting", 2, 4, "")
+	assert.Contains(t, out.String(), "2/4")
+}
+
+func TestProgress_Disabled(t *testing.T) {
+	var out bytes.Buffer
+	p := &Progress{out: &out, live: true}
+
+	p.Update("collecting", 1, 4, "")
+	p.Done()
+	assert.Empty(t, out.String())
+
+	var nilProgress *Progress
+	nilProgress.Update("collecting", 1, 4, "")
+	nilProgress.Done()
+}
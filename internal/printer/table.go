@@ -0,0 +1,179 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// TableOptions selects the columns of a table and how wide it may be.
+type TableOptions struct {
+	// Columns names the columns to show, in order; empty means the defaults.
+	Columns []string
+	// Width is the line width to fit the table into by shortening values;
+	// 0 renders every value in full, e.g. when piping to a file.
+	Width int
+}
+
+// ValidateColumns checks that every selected column is one of available.
+func ValidateColumns(selected, available []string) error {
+	for _, name := range selected {
+		if !containsString(available, name) {
+			return fmt.Errorf("unknown column %q (use %s)", name, strings.Join(available, ", "))
+		}
+	}
+	return nil
+}
+
+// column is one column of a width-aware table.
+type column struct {
+	name   string
+	header string
+	// cells holds each row's value as variants from widest to narrowest;
+	// the table uses the widest variant that fits.
+	cells [][]string
+	// minWidth is how far the narrowest variant may be cut with "...";
+	// 0 means it is never cut.
+	minWidth int
+
+	variant int
+	limit   int
+}
+
+// add appends a row's value, given as variants from widest to narrowest.
+func (c *column) add(variants ...string) {
+	c.cells = append(c.cells, variants)
+}
+
+// value returns a row's value at the column's current variant and limit.
+func (c *column) value(row int) string {
+	variants := c.cells[row]
+	return truncate(variants[min(c.variant, len(variants)-1)], c.limit)
+}
+
+// width returns the column's current rendered width.
+func (c *column) width() int {
+	w := utf8.RuneCountInString(c.header)
+	for row := range c.cells {
+		w = max(w, utf8.RuneCountInString(c.value(row)))
+	}
+	return w
+}
+
+// tableWidth returns the line width tablewriter renders the columns at:
+// two spaces of indent, then each column padded by two spaces.
+func tableWidth(cols []*column) int {
+	w := 2
+	for _, c := range cols {
+		w += c.width() + 2
+	}
+	return w
+}
+
+// fitColumns shortens columns, in the order given by shrink, until the
+// table fits width: first by switching to narrower variants, then by
+// cutting columns that allow it down to their minimum width.
+func fitColumns(cols []*column, width int, shrink []string) {
+	if width <= 0 {
+		return
+	}
+	byName := make(map[string]*column, len(cols))
+	for _, c := range cols {
+		byName[c.name] = c
+	}
+
+	for _, name := range shrink {
+		c, ok := byName[name]
+		if !ok {
+			continue
+		}
+		for tableWidth(cols) > width && c.variant < maxVariants(c)-1 {
+			c.variant++
+		}
+		if excess := tableWidth(cols) - width; excess > 0 && c.minWidth > 0 {
+			c.limit = max(c.minWidth, c.width()-excess)
+		}
+		if tableWidth(cols) <= width {
+			return
+		}
+	}
+}
+
+// maxVariants returns the largest number of variants of any row.
+func maxVariants(c *column) int {
+	n := 1
+	for _, variants := range c.cells {
+		n = max(n, len(variants))
+	}
+	return n
+}
+
+// selectColumns returns the named columns in order, or the defaults.
+func selectColumns(all []*column, names, defaults []string) []*column {
+	if len(names) == 0 {
+		names = defaults
+	}
+	var selected []*column
+	for _, name := range names {
+		for _, c := range all {
+			if c.name == name {
+				selected = append(selected, c)
+			}
+		}
+	}
+	return selected
+}
+
+// renderColumns prints the columns in the style of the other tables.
+func renderColumns(cols []*column) {
+	table := tablewriter.NewWriter(os.Stdout)
+	var header []string
+	for _, c := range cols {
+		header = append(header, c.header)
+	}
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(true)
+
+	if len(cols) > 0 {
+		for row := range cols[0].cells {
+			line := make([]string, len(cols))
+			for i, c := range cols {
+				line[i] = c.value(row)
+			}
+			table.Append(line)
+		}
+	}
+	table.Render()
+}
+
+// truncate cuts s to width characters with "...", if width allows.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return strings.TrimRight(string(runes[:width-3]), " ") + "..."
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func historyColumns() []*column {
+	commit := &column{name: "commit", header: "Commit"}
+	commit.add("0123456789abcdef0123456789abcdef01234567", "01234567")
+	timestamp := &column{name: "timestamp", header: "Timestamp"}
+	timestamp.add("2024-05-01 10:00:00", "05-01 10:00")
+	message := &column{name: "message", header: "Message", minWidth: 20}
+	message.add("[snapshot] 120 resources across 14 namespaces, 3 changed")
+	return []*column{commit, timestamp, message}
+}
+
+func TestFitColumns(t *testing.T) {
+	cols := historyColumns()
+	fitColumns(cols, 0, historyShrink)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", cols[0].value(0), "no limit renders in full")
+
+	// The full hash goes first
+	cols = historyColumns()
+	fitColumns(cols, 100, historyShrink)
+	assert.Equal(t, "01234567", cols[0].value(0))
+	assert.Equal(t, "2024-05-01 10:00:00", cols[1].value(0))
+	assert.Equal(t, "[snapshot] 120 resources across 14 namespaces, 3 changed", cols[2].value(0))
+
+	// Then the message is cut
+	cols = historyColumns()
+	fitColumns(cols, 70, historyShrink)
+	assert.LessOrEqual(t, tableWidth(cols), 70)
+	assert.Equal(t, "2024-05-01 10:00:00", cols[1].value(0))
+	assert.Equal(t, "[snapshot] 120 resources across...", cols[2].value(0))
+
+	// And finally the timestamp is shortened
+	cols = historyColumns()
+	fitColumns(cols, 50, historyShrink)
+	assert.Equal(t, "05-01 10:00", cols[1].value(0))
+	assert.Len(t, cols[2].value(0), 20)
+}
+
+func TestSelectColumns(t *testing.T) {
+	cols := historyColumns()
+	selected := selectColumns(cols, []string{"message", "commit"}, nil)
+	assert.Equal(t, []*column{cols[2], cols[0]}, selected)
+	assert.Equal(t, []*column{cols[1]}, selectColumns(cols, nil, []string{"timestamp"}))
+}
+
+func TestValidateColumns(t *testing.T) {
+	assert.NoError(t, ValidateColumns([]string{"commit", "author"}, HistoryColumns))
+	assert.EqualError(t, ValidateColumns([]string{"sha"}, HistoryColumns),
+		`unknown column "sha" (use num, commit, timestamp, age, resources, message, author, cluster, permissions)`)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "hello", truncate("hello", 0))
+	assert.Equal(t, "hello", truncate("hello", 5))
+	assert.Equal(t, "he...", truncate("hello world", 5))
+	assert.Equal(t, "ü...", truncate("üüüüüü", 4))
+}
@@ -0,0 +1,66 @@
+package printer
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeLocation is the time zone timestamps are printed in.
+var timeLocation = time.Local
+
+// now is the reference for relative times; replaced in tests.
+var now = time.Now
+
+// SetTimeLocation sets the time zone timestamps are printed in, e.g.
+// time.UTC for --utc. The default is the local time zone.
+func SetTimeLocation(loc *time.Location) {
+	timeLocation = loc
+}
+
+// formatTime renders t in the configured time zone, with the zone's name.
+func formatTime(t time.Time) string {
+	return t.In(timeLocation).Format("2006-01-02 15:04:05 MST")
+}
+
+// formatTimeShort renders t without year, seconds, or zone, for narrow tables.
+func formatTimeShort(t time.Time) string {
+	return t.In(timeLocation).Format("01-02 15:04")
+}
+
+// formatTimeAgo renders t followed by how long ago it was.
+func formatTimeAgo(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", formatTime(t), relativeTime(t, now()))
+}
+
+// relativeTime describes t relative to ref, e.g. "12 minutes ago" or
+// "in 2 hours", in the largest whole unit.
+func relativeTime(t, ref time.Time) string {
+	d := ref.Sub(t)
+	suffix := " ago"
+	prefix := ""
+	if d < 0 {
+		d = -d
+		prefix, suffix = "in ", ""
+	}
+
+	var n int
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		n, unit = int(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		n, unit = int(d/time.Hour), "hour"
+	case d < 30*24*time.Hour:
+		n, unit = int(d/(24*time.Hour)), "day"
+	case d < 365*24*time.Hour:
+		n, unit = int(d/(30*24*time.Hour)), "month"
+	default:
+		n, unit = int(d/(365*24*time.Hour)), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%s%d %s%s", prefix, n, unit, suffix)
+}
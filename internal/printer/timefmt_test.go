@@ -0,0 +1,41 @@
+package printer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeTime(t *testing.T) {
+	ref := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[time.Duration]string{
+		-30 * time.Second:     "just now",
+		-time.Minute:          "1 minute ago",
+		-12 * time.Minute:     "12 minutes ago",
+		-3 * time.Hour:        "3 hours ago",
+		-49 * time.Hour:       "2 days ago",
+		-70 * 24 * time.Hour:  "2 months ago",
+		-800 * 24 * time.Hour: "2 years ago",
+		2 * time.Hour:         "in 2 hours",
+	}
+	for offset, want := range cases {
+		assert.Equal(t, want, relativeTime(ref.Add(offset), ref), offset.String())
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	defer SetTimeLocation(timeLocation)
+	defer func(n func() time.Time) { now = n }(now)
+
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return ts.Add(12 * time.Minute) }
+
+	SetTimeLocation(time.UTC)
+	assert.Equal(t, "2024-05-01 10:00:00 UTC (12 minutes ago)", formatTimeAgo(ts))
+
+	berlin := time.FixedZone("CEST", 2*60*60)
+	SetTimeLocation(berlin)
+	assert.Equal(t, "2024-05-01 12:00:00 CEST", formatTime(ts))
+	assert.Equal(t, "05-01 12:00", formatTimeShort(ts))
+}
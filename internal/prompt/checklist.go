@@ -0,0 +1,183 @@
+// Package prompt implements interactive terminal prompts.
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrAborted is returned when the user cancels a prompt.
+var ErrAborted = errors.New("aborted")
+
+// key is a decoded keypress.
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyToggle
+	keyToggleAll
+	keyConfirm
+	keyAbort
+)
+
+// checklist is the state of a multi-select list.
+type checklist struct {
+	title    string
+	items    []string
+	selected []bool
+	cursor   int
+	offset   int
+	// height is the number of items shown at once.
+	height int
+}
+
+// Checklist shows items with checkboxes on the terminal and returns the
+// indexes the user selected, in order. Stdin must be a terminal.
+func Checklist(title string, items []string) ([]int, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("interactive selection requires a terminal")
+	}
+
+	height := len(items)
+	if _, rows, err := term.GetSize(fd); err == nil && rows-4 < height {
+		height = rows - 4
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	c := &checklist{title: title, items: items, selected: make([]bool, len(items)), height: height}
+	return c.run(os.Stdin, os.Stderr)
+}
+
+// run draws the list and handles keys until the user confirms or aborts.
+func (c *checklist) run(in io.Reader, out io.Writer) ([]int, error) {
+	buf := make([]byte, 8)
+	lines := 0
+	for {
+		if lines > 0 {
+			fmt.Fprintf(out, "\x1b[%dA\x1b[J", lines)
+		}
+		view := c.render()
+		fmt.Fprint(out, strings.Join(view, "\r\n")+"\r\n")
+		lines = len(view)
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		switch c.handle(decode(buf[:n])) {
+		case keyConfirm:
+			return c.chosen(), nil
+		case keyAbort:
+			return nil, ErrAborted
+		}
+	}
+}
+
+// handle applies a keypress and returns it if it ends the prompt.
+func (c *checklist) handle(k key) key {
+	switch k {
+	case keyUp:
+		if c.cursor > 0 {
+			c.cursor--
+		}
+	case keyDown:
+		if c.cursor < len(c.items)-1 {
+			c.cursor++
+		}
+	case keyToggle:
+		if len(c.items) > 0 {
+			c.selected[c.cursor] = !c.selected[c.cursor]
+		}
+	case keyToggleAll:
+		all := len(c.chosen()) < len(c.items)
+		for i := range c.selected {
+			c.selected[i] = all
+		}
+	case keyConfirm, keyAbort:
+		return k
+	}
+
+	// Keep the cursor inside the visible window
+	if c.cursor < c.offset {
+		c.offset = c.cursor
+	}
+	if c.cursor >= c.offset+c.height {
+		c.offset = c.cursor - c.height + 1
+	}
+	return keyNone
+}
+
+// chosen returns the selected indexes in order.
+func (c *checklist) chosen() []int {
+	var out []int
+	for i, ok := range c.selected {
+		if ok {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// render returns the lines to draw for the current state.
+func (c *checklist) render() []string {
+	lines := []string{
+		c.title,
+		fmt.Sprintf("  ↑/↓ move · space toggle · a all · enter confirm · q cancel  (%d/%d selected)",
+			len(c.chosen()), len(c.items)),
+	}
+
+	end := c.offset + c.height
+	if end > len(c.items) {
+		end = len(c.items)
+	}
+	for i := c.offset; i < end; i++ {
+		cursor := " "
+		if i == c.cursor {
+			cursor = ">"
+		}
+		box := "[ ]"
+		if c.selected[i] {
+			box = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s", cursor, box, c.items[i]))
+	}
+	if end < len(c.items) || c.offset > 0 {
+		lines = append(lines, fmt.Sprintf("  … showing %d-%d of %d", c.offset+1, end, len(c.items)))
+	}
+	return lines
+}
+
+// decode maps raw terminal input to a key.
+func decode(b []byte) key {
+	switch string(b) {
+	case "\x1b[A", "\x1bOA", "k":
+		return keyUp
+	case "\x1b[B", "\x1bOB", "j":
+		return keyDown
+	case " ", "x":
+		return keyToggle
+	case "a":
+		return keyToggleAll
+	case "\r", "\n":
+		return keyConfirm
+	case "q", "\x1b", "\x03", "\x04":
+		return keyAbort
+	}
+	return keyNone
+}
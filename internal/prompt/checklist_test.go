@@ -0,0 +1,72 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChecklist(n, height int) *checklist {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = string(rune('a' + i))
+	}
+	return &checklist{title: "pick", items: items, selected: make([]bool, n), height: height}
+}
+
+func TestChecklist_ToggleAndConfirm(t *testing.T) {
+	c := newChecklist(3, 3)
+	c.handle(keyToggle)
+	c.handle(keyDown)
+	c.handle(keyDown)
+	c.handle(keyToggle)
+	assert.Equal(t, []int{0, 2}, c.chosen())
+
+	c.handle(keyToggleAll)
+	assert.Equal(t, []int{0, 1, 2}, c.chosen())
+	c.handle(keyToggleAll)
+	assert.Empty(t, c.chosen())
+}
+
+func TestChecklist_ScrollsWithCursor(t *testing.T) {
+	c := newChecklist(5, 2)
+	for i := 0; i < 4; i++ {
+		c.handle(keyDown)
+	}
+	assert.Equal(t, 4, c.cursor)
+	assert.Equal(t, 3, c.offset)
+
+	view := c.render()
+	assert.Equal(t, "> [ ] e", view[3])
+	assert.Contains(t, view[len(view)-1], "showing 4-5 of 5")
+
+	c.handle(keyDown)
+	assert.Equal(t, 4, c.cursor, "cursor stops at the last item")
+}
+
+func TestChecklist_Run(t *testing.T) {
+	c := newChecklist(3, 3)
+	var out bytes.Buffer
+	chosen, err := c.run(&keyReader{keys: []string{" ", "\x1b[B", "\x1b[B", " ", "\r"}}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, chosen)
+	assert.True(t, strings.Contains(out.String(), "[x] a"))
+
+	c = newChecklist(3, 3)
+	_, err = c.run(&keyReader{keys: []string{"q"}}, &out)
+	assert.ErrorIs(t, err, ErrAborted)
+}
+
+// keyReader returns one keypress per Read, like a raw-mode terminal.
+type keyReader struct {
+	keys []string
+}
+
+func (r *keyReader) Read(p []byte) (int, error) {
+	k := r.keys[0]
+	r.keys = r.keys[1:]
+	return copy(p, k), nil
+}
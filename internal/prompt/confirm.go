@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether stdin is a terminal that can answer prompts.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Confirm asks a yes/no question on the terminal and reports whether the
+// user answered yes; anything else, including just enter, is no. Stdin
+// must be a terminal.
+func Confirm(question string) (bool, error) {
+	if !IsTerminal() {
+		return false, fmt.Errorf("confirmation requires a terminal")
+	}
+	return confirm(os.Stdin, os.Stderr, question)
+}
+
+// confirm reads one line of input as the answer to question.
+func confirm(in io.Reader, out io.Writer, question string) (bool, error) {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return false, ErrAborted
+		}
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
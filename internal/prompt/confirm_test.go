@@ -0,0 +1,31 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	var out bytes.Buffer
+	ok, err := confirm(strings.NewReader("yes\n"), &out, "Restore 2 resources?")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "Restore 2 resources? [y/N] ", out.String())
+
+	for _, answer := range []string{"\n", "n\n", "maybe\n"} {
+		ok, err = confirm(strings.NewReader(answer), &out, "?")
+		require.NoError(t, err)
+		assert.False(t, ok, "answer %q", answer)
+	}
+
+	ok, err = confirm(strings.NewReader("Y"), &out, "?")
+	require.NoError(t, err)
+	assert.True(t, ok, "the last line needs no newline")
+
+	_, err = confirm(strings.NewReader(""), &out, "?")
+	assert.ErrorIs(t, err, ErrAborted)
+}
@@ -0,0 +1,24 @@
+// Package rawobj reads fields out of the decoded YAML captured for a resource.
+package rawobj
+
+import "github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+
+// Field returns a top-level field of the captured object.
+func Field(res types.Resource, field string) interface{} {
+	if res.Raw == nil {
+		return nil
+	}
+	return res.Raw[field]
+}
+
+// StringList converts a decoded YAML sequence into strings.
+func StringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,21 @@
+package rawobj
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestField(t *testing.T) {
+	res := types.Resource{Raw: map[string]interface{}{"spec": "x"}}
+	assert.Equal(t, "x", Field(res, "spec"))
+	assert.Nil(t, Field(res, "status"))
+	assert.Nil(t, Field(types.Resource{}, "spec"))
+}
+
+func TestStringList(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, StringList([]interface{}{"a", 1, "b"}))
+	assert.Empty(t, StringList(nil))
+	assert.Empty(t, StringList("a"))
+}
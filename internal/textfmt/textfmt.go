@@ -0,0 +1,38 @@
+// Package textfmt formats drift values and text the same way across the
+// drift renderers, notifications, and reports.
+package textfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Value renders a field value on one line, in full: maps and lists as
+// JSON, and an absent value as "(none)".
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// markdownEscaper escapes the characters that would start Markdown markup,
+// break a table cell, or break a line.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`, "[", `\[`, "\n", " ")
+
+// EscapeMarkdown escapes s for use as Markdown text, including in a table
+// cell.
+func EscapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
@@ -0,0 +1,19 @@
+package textfmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	assert.Equal(t, "(none)", Value(nil))
+	assert.Equal(t, "nginx:1.25", Value("nginx:1.25"))
+	assert.Equal(t, "3", Value(3))
+	assert.Equal(t, `{"a":1}`, Value(map[string]interface{}{"a": 1}))
+	assert.Equal(t, `["x","y"]`, Value([]interface{}{"x", "y"}))
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	assert.Equal(t, `a\|b \*c\* \_d\_ \[e] f`, EscapeMarkdown("a|b *c* _d_ [e]\nf"))
+}
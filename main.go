@@ -18,7 +18,7 @@ func main() {
 	cmd.SetVersionInfo(Version, BuildTime)
 
 	if err := cmd.Execute(); err != nil {
-		log.WithError(err).Fatal("execution failed")
-		os.Exit(1)
+		log.WithError(err).Error("execution failed")
+		os.Exit(cmd.ExitCode(err))
 	}
 }
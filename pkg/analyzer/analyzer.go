@@ -2,26 +2,83 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	log "github.com/sirupsen/logrus"
 )
 
+// parallelThreshold is the number of resources present in both snapshots
+// below which they are compared on one goroutine; smaller snapshots are
+// not worth the coordination.
+const parallelThreshold = 2000
+
+// Options tunes how snapshots are compared.
+type Options struct {
+	// Workers is the number of goroutines comparing resources in
+	// parallel. Zero uses GOMAXPROCS.
+	Workers int
+	// Logger receives the analyzer's log output. Nil uses the standard
+	// logrus logger.
+	Logger log.FieldLogger
+	// Ignore, if set, leaves the resources and fields it configures out of
+	// the comparison.
+	Ignore *config.IgnoreConfig
+}
+
 // Analyzer compares infrastructure snapshots and detects drift.
-type Analyzer struct{}
+type Analyzer struct {
+	opts Options
+}
 
 // New creates a new Analyzer.
 func New() *Analyzer {
-	return &Analyzer{}
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions creates a new Analyzer with explicit options.
+func NewWithOptions(opts Options) *Analyzer {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.StandardLogger()
+	}
+	return &Analyzer{opts: opts}
 }
 
 // Compare takes two snapshots and produces a DriftReport.
 func (a *Analyzer) Compare(base, target *types.ResourceSnapshot) *types.DriftReport {
+	// Without a deadline the comparison cannot fail
+	report, _ := a.CompareContext(context.Background(), base, target)
+	return report
+}
+
+// CompareContext is Compare, returning ctx's error if it is cancelled
+// before the comparison finishes.
+func (a *Analyzer) CompareContext(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+	if a.opts.Ignore != nil {
+		var excluded, stripped int
+		base, target, excluded = ignore.Exclude(a.opts.Ignore, base, target)
+		if excluded > 0 {
+			a.opts.Logger.WithField("resources", excluded).Debug("ignoring configured resources")
+		}
+		base, target, stripped = ignore.Apply(a.opts.Ignore, base, target)
+		if stripped > 0 {
+			a.opts.Logger.WithField("resources", stripped).Debug("ignoring configured fields")
+		}
+	}
+
 	report := &types.DriftReport{
 		Timestamp: time.Now().UTC(),
 		BaseRef:   base.Metadata.CommitHash,
@@ -32,6 +89,19 @@ func (a *Analyzer) Compare(base, target *types.ResourceSnapshot) *types.DriftRep
 	baseIndex := indexResources(base.Resources)
 	targetIndex := indexResources(target.Resources)
 
+	// A partial snapshot tells nothing of the resources outside its scope
+	// (their files are carried over from earlier snapshots), so only the
+	// resources both snapshots cover are compared
+	if base.Metadata.Scope != nil || target.Metadata.Scope != nil {
+		report.Scope = &types.ScopeNote{
+			Base:       base.Metadata.Scope,
+			Target:     target.Metadata.Scope,
+			OutOfScope: map[string]int{},
+		}
+		outOfScope(baseIndex, target.Metadata.Scope, report.Scope.OutOfScope)
+		outOfScope(targetIndex, base.Metadata.Scope, report.Scope.OutOfScope)
+	}
+
 	// Find removed resources (in base but not in target)
 	for name, baseRes := range baseIndex {
 		if _, exists := targetIndex[name]; !exists {
@@ -52,19 +122,12 @@ func (a *Analyzer) Compare(base, target *types.ResourceSnapshot) *types.DriftRep
 		}
 	}
 
-	// Find modified resources (in both, but different)
-	for name, baseRes := range baseIndex {
-		if targetRes, exists := targetIndex[name]; exists {
-			diffs := compareResources(baseRes, targetRes)
-			if len(diffs) > 0 {
-				report.Entries = append(report.Entries, types.DriftEntry{
-					Type:       types.DriftModified,
-					Resource:   targetRes,
-					FieldDiffs: diffs,
-				})
-			}
-		}
+	// Find recreated (new UID) and modified resources (in both, but different)
+	common, err := a.compareCommon(ctx, baseIndex, targetIndex)
+	if err != nil {
+		return nil, err
 	}
+	report.Entries = append(report.Entries, common...)
 
 	// Sort entries for deterministic output
 	sort.Slice(report.Entries, func(i, j int) bool {
@@ -74,6 +137,29 @@ func (a *Analyzer) Compare(base, target *types.ResourceSnapshot) *types.DriftRep
 		return report.Entries[i].Resource.FullName() < report.Entries[j].Resource.FullName()
 	})
 
+	// Explain NetworkPolicy changes in terms of allowed traffic
+	for i := range report.Entries {
+		entry := &report.Entries[i]
+		if entry.Resource.Kind != netpolKind {
+			continue
+		}
+		name := entry.Resource.FullName()
+		var baseRes, targetRes *types.Resource
+		if r, ok := baseIndex[name]; ok {
+			baseRes = &r
+		}
+		if r, ok := targetIndex[name]; ok {
+			targetRes = &r
+		}
+		entry.Summaries = summarizeNetworkPolicy(baseRes, targetRes)
+	}
+
+	// Explain workload rollouts by the ConfigMaps and Secrets they use
+	correlateRollouts(report.Entries, baseIndex, targetIndex)
+
+	// CRD changes affect the whole cluster's API, so report them separately
+	report.APIChanges = compareCRDs(baseIndex, targetIndex)
+
 	// Build summary
 	report.Summary = types.DriftSummary{
 		TotalResources: len(targetIndex),
@@ -86,17 +172,28 @@ func (a *Analyzer) Compare(base, target *types.ResourceSnapshot) *types.DriftRep
 			report.Summary.RemovedResources++
 		case types.DriftModified:
 			report.Summary.ModifiedResources++
+		case types.DriftRecreated:
+			report.Summary.RecreatedResources++
+		case types.DriftScaled:
+			report.Summary.ScaledResources++
+		case types.DriftOwnership:
+			report.Summary.OwnershipChanges++
 		}
 	}
-	report.Summary.UnchangedResources = len(baseIndex) - report.Summary.RemovedResources - report.Summary.ModifiedResources
-
-	log.WithFields(log.Fields{
-		"added":    report.Summary.AddedResources,
-		"removed":  report.Summary.RemovedResources,
-		"modified": report.Summary.ModifiedResources,
+	report.Summary.UnchangedResources = len(baseIndex) - report.Summary.RemovedResources -
+		report.Summary.ModifiedResources - report.Summary.RecreatedResources - report.Summary.ScaledResources -
+		report.Summary.OwnershipChanges
+
+	a.opts.Logger.WithFields(log.Fields{
+		"added":     report.Summary.AddedResources,
+		"removed":   report.Summary.RemovedResources,
+		"modified":  report.Summary.ModifiedResources,
+		"recreated": report.Summary.RecreatedResources,
+		"scaled":    report.Summary.ScaledResources,
+		"ownership": report.Summary.OwnershipChanges,
 	}).Info("drift analysis completed")
 
-	return report
+	return report, nil
 }
 
 // HasDrift returns true if the report contains any drift entries.
@@ -115,6 +212,15 @@ func FormatReport(report *types.DriftReport) string {
 	sb.WriteString(fmt.Sprintf("  Added:           %d\n", report.Summary.AddedResources))
 	sb.WriteString(fmt.Sprintf("  Removed:         %d\n", report.Summary.RemovedResources))
 	sb.WriteString(fmt.Sprintf("  Modified:        %d\n", report.Summary.ModifiedResources))
+	if report.Summary.RecreatedResources > 0 {
+		sb.WriteString(fmt.Sprintf("  Recreated:       %d\n", report.Summary.RecreatedResources))
+	}
+	if report.Summary.ScaledResources > 0 {
+		sb.WriteString(fmt.Sprintf("  Scaled:          %d\n", report.Summary.ScaledResources))
+	}
+	if report.Summary.OwnershipChanges > 0 {
+		sb.WriteString(fmt.Sprintf("  Ownership:       %d\n", report.Summary.OwnershipChanges))
+	}
 	sb.WriteString(fmt.Sprintf("  Unchanged:       %d\n\n", report.Summary.UnchangedResources))
 
 	if !HasDrift(report) {
@@ -122,6 +228,15 @@ func FormatReport(report *types.DriftReport) string {
 		return sb.String()
 	}
 
+	if len(report.APIChanges) > 0 {
+		sb.WriteString("  API Changes:\n")
+		for _, change := range report.APIChanges {
+			sb.WriteString(fmt.Sprintf("    %s %s\n", change.Type, change.CRD))
+			sb.WriteString(formatAPIChangeDetails(change, "      "))
+		}
+		sb.WriteString("\n")
+	}
+
 	for _, entry := range report.Entries {
 		switch entry.Type {
 		case types.DriftAdded:
@@ -130,6 +245,17 @@ func FormatReport(report *types.DriftReport) string {
 			sb.WriteString(fmt.Sprintf("  [-] REMOVED  %s\n", entry.Resource.FullName()))
 		case types.DriftModified:
 			sb.WriteString(fmt.Sprintf("  [~] MODIFIED %s\n", entry.Resource.FullName()))
+		case types.DriftRecreated:
+			sb.WriteString(fmt.Sprintf("  [*] RECREATED %s\n", entry.Resource.FullName()))
+		case types.DriftScaled:
+			sb.WriteString(fmt.Sprintf("  [^] SCALED   %s\n", entry.Resource.FullName()))
+		case types.DriftOwnership:
+			sb.WriteString(fmt.Sprintf("  [@] OWNERSHIP %s\n", entry.Resource.FullName()))
+		}
+		for _, summary := range entry.Summaries {
+			sb.WriteString(fmt.Sprintf("      ⇒ %s\n", summary))
+		}
+		if len(entry.FieldDiffs) > 0 {
 			for _, diff := range entry.FieldDiffs {
 				sb.WriteString(fmt.Sprintf("      • %s\n", diff.Path))
 				sb.WriteString(fmt.Sprintf("        old: %v\n", diff.OldValue))
@@ -141,6 +267,104 @@ func FormatReport(report *types.DriftReport) string {
 	return sb.String()
 }
 
+// formatAPIChangeDetails renders the version-level details of an API change.
+func formatAPIChangeDetails(change types.APIChange, indent string) string {
+	var sb strings.Builder
+	if len(change.AddedVersions) > 0 {
+		sb.WriteString(fmt.Sprintf("%sversions added:   %s\n", indent, strings.Join(change.AddedVersions, ", ")))
+	}
+	if len(change.RemovedVersions) > 0 {
+		sb.WriteString(fmt.Sprintf("%sversions removed: %s\n", indent, strings.Join(change.RemovedVersions, ", ")))
+	}
+	if len(change.SchemaChanged) > 0 {
+		sb.WriteString(fmt.Sprintf("%sschema changed:   %s\n", indent, strings.Join(change.SchemaChanged, ", ")))
+	}
+	if change.Type == types.DriftModified && change.OldStorage != change.NewStorage {
+		sb.WriteString(fmt.Sprintf("%sstorage version:  %s → %s\n", indent, change.OldStorage, change.NewStorage))
+	}
+	return sb.String()
+}
+
+// compareCommon compares the resources present in both snapshots. Large
+// snapshots are split into contiguous ranges of names compared in
+// parallel; the results are concatenated in range order, and Compare sorts
+// them afterwards, so the report does not depend on scheduling. Every
+// worker stops at the next resource once ctx is cancelled.
+func (a *Analyzer) compareCommon(ctx context.Context, baseIndex, targetIndex map[string]types.Resource) ([]types.DriftEntry, error) {
+	names := make([]string, 0, len(baseIndex))
+	for name := range baseIndex {
+		if _, exists := targetIndex[name]; exists {
+			names = append(names, name)
+		}
+	}
+
+	workers := a.opts.Workers
+	if len(names) < parallelThreshold {
+		workers = 1
+	}
+	workers = max(min(workers, len(names)), 1)
+
+	chunk := (len(names) + workers - 1) / workers
+	results := make([][]types.DriftEntry, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo, hi := min(w*chunk, len(names)), min((w+1)*chunk, len(names))
+		wg.Add(1)
+		go func(w int, names []string) {
+			defer wg.Done()
+			for _, name := range names {
+				if ctx.Err() != nil {
+					return
+				}
+				if entry, ok := compareEntry(baseIndex[name], targetIndex[name]); ok {
+					results[w] = append(results[w], entry)
+				}
+			}
+		}(w, names[lo:hi])
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var entries []types.DriftEntry
+	for _, r := range results {
+		entries = append(entries, r...)
+	}
+	return entries, nil
+}
+
+// compareEntry classifies the change to a resource present in both
+// snapshots, if it changed.
+func compareEntry(baseRes, targetRes types.Resource) (types.DriftEntry, bool) {
+	if sameContent(baseRes, targetRes) {
+		return types.DriftEntry{}, false
+	}
+	diffs := compareResources(baseRes, targetRes)
+	if baseRes.UID != "" && targetRes.UID != "" && baseRes.UID != targetRes.UID {
+		return types.DriftEntry{
+			Type:       types.DriftRecreated,
+			Resource:   targetRes,
+			FieldDiffs: diffs,
+		}, true
+	}
+	if len(diffs) == 0 {
+		return types.DriftEntry{}, false
+	}
+	driftType := types.DriftModified
+	switch {
+	case onlyPath(diffs, replicasPath):
+		driftType = types.DriftScaled
+	case onlyPath(diffs, managersPath):
+		driftType = types.DriftOwnership
+	}
+	return types.DriftEntry{
+		Type:       driftType,
+		Resource:   targetRes,
+		FieldDiffs: diffs,
+	}, true
+}
+
 // indexResources creates a map of FullName -> Resource for fast lookup.
 func indexResources(resources []types.Resource) map[string]types.Resource {
 	index := make(map[string]types.Resource, len(resources))
@@ -150,6 +374,24 @@ func indexResources(resources []types.Resource) map[string]types.Resource {
 	return index
 }
 
+// outOfScope removes the resources outside scope from index, counting
+// them by kind in excluded.
+func outOfScope(index map[string]types.Resource, scope *types.SnapshotScope, excluded map[string]int) {
+	for name, res := range index {
+		if !scope.Contains(res) {
+			delete(index, name)
+			excluded[res.Kind]++
+		}
+	}
+}
+
+// sameContent reports whether two resources were read from identical files,
+// which skips the deep comparison of unchanged resources. The file content
+// includes the UID, so a recreated resource never matches.
+func sameContent(base, target types.Resource) bool {
+	return base.ContentHash != "" && base.ContentHash == target.ContentHash
+}
+
 // compareResources performs a deep comparison of two resources, returning field diffs.
 func compareResources(base, target types.Resource) []types.FieldDiff {
 	var diffs []types.FieldDiff
@@ -172,6 +414,16 @@ func compareResources(base, target types.Resource) []types.FieldDiff {
 		})
 	}
 
+	// Compare field managers, only when both sides recorded them so that
+	// enabling snapshot.track_field_managers does not flag every resource
+	if base.Managers != nil && target.Managers != nil && !reflect.DeepEqual(base.Managers, target.Managers) {
+		diffs = append(diffs, types.FieldDiff{
+			Path:     managersPath,
+			OldValue: base.Managers,
+			NewValue: target.Managers,
+		})
+	}
+
 	// Compare Spec
 	if !reflect.DeepEqual(base.Spec, target.Spec) {
 		specDiffs := deepCompareMap(".spec", base.Spec, target.Spec)
@@ -195,16 +447,20 @@ func deepCompareMap(prefix string, base, target map[string]interface{}) []types.
 		return nil
 	}
 
-	allKeys := make(map[string]bool)
+	// Visit keys in order so that diffs are listed the same way every time
+	keys := make([]string, 0, len(base)+len(target))
 	for k := range base {
-		allKeys[k] = true
+		keys = append(keys, k)
 	}
 	for k := range target {
-		allKeys[k] = true
+		if _, ok := base[k]; !ok {
+			keys = append(keys, k)
+		}
 	}
+	sort.Strings(keys)
 
-	for k := range allKeys {
-		path := prefix + "." + k
+	for _, k := range keys {
+		path := prefix + ignore.FormatKey(ignore.Key{Name: k})
 		baseVal, baseOk := base[k]
 		targetVal, targetOk := target[k]
 
@@ -217,22 +473,182 @@ func deepCompareMap(prefix string, base, target map[string]interface{}) []types.
 			continue
 		}
 
-		// Recurse into nested maps
-		baseMap, baseIsMap := baseVal.(map[string]interface{})
-		targetMap, targetIsMap := targetVal.(map[string]interface{})
-		if baseIsMap && targetIsMap {
-			diffs = append(diffs, deepCompareMap(path, baseMap, targetMap)...)
-			continue
+		diffs = append(diffs, compareValues(path, baseVal, targetVal)...)
+	}
+
+	return diffs
+}
+
+// compareValues compares two values present on both sides, recursing into
+// maps and lists.
+func compareValues(path string, base, target interface{}) []types.FieldDiff {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	targetMap, targetIsMap := target.(map[string]interface{})
+	if baseIsMap && targetIsMap {
+		return deepCompareMap(path, baseMap, targetMap)
+	}
+	baseList, baseIsList := base.([]interface{})
+	targetList, targetIsList := target.([]interface{})
+	if baseIsList && targetIsList {
+		return compareLists(path, baseList, targetList)
+	}
+	if valuesEqual(base, target) {
+		return nil
+	}
+	return []types.FieldDiff{{Path: path, OldValue: base, NewValue: target}}
+}
+
+// compareLists compares two lists element by element, so that changing
+// one container's image shows as .spec.template.spec.containers[nginx].image
+// rather than as the whole list. Paths are in ignore's path grammar, so
+// they can be pasted into ignore.fields. Elements are matched by name when every
+// element on both sides has a distinct one (containers, ports, env,
+// volumes), and by position otherwise.
+func compareLists(path string, base, target []interface{}) []types.FieldDiff {
+	baseNames, baseNamed := elementNames(base)
+	targetNames, targetNamed := elementNames(target)
+	if !baseNamed || !targetNamed {
+		var diffs []types.FieldDiff
+		for i := 0; i < max(len(base), len(target)); i++ {
+			elemPath := path + ignore.FormatKey(ignore.Key{Name: strconv.Itoa(i), Element: true})
+			switch {
+			case i >= len(base):
+				diffs = append(diffs, types.FieldDiff{Path: elemPath, NewValue: target[i]})
+			case i >= len(target):
+				diffs = append(diffs, types.FieldDiff{Path: elemPath, OldValue: base[i]})
+			default:
+				diffs = append(diffs, compareValues(elemPath, base[i], target[i])...)
+			}
 		}
+		return diffs
+	}
 
-		if !reflect.DeepEqual(baseVal, targetVal) {
-			diffs = append(diffs, types.FieldDiff{
-				Path:     path,
-				OldValue: baseVal,
-				NewValue: targetVal,
-			})
+	baseIndex := make(map[string]int, len(base))
+	for i, name := range baseNames {
+		baseIndex[name] = i
+	}
+	targetIndex := make(map[string]int, len(target))
+	for i, name := range targetNames {
+		targetIndex[name] = i
+	}
+
+	// Removed and changed elements in the base order, then added ones
+	var diffs []types.FieldDiff
+	var baseOrder, targetOrder []string
+	for i, name := range baseNames {
+		elemPath := path + ignore.FormatKey(ignore.Key{Name: name, Element: true})
+		j, ok := targetIndex[name]
+		if !ok {
+			diffs = append(diffs, types.FieldDiff{Path: elemPath, OldValue: base[i]})
+			continue
+		}
+		baseOrder = append(baseOrder, name)
+		diffs = append(diffs, compareValues(elemPath, base[i], target[j])...)
+	}
+	for j, name := range targetNames {
+		if _, ok := baseIndex[name]; !ok {
+			diffs = append(diffs, types.FieldDiff{Path: path + ignore.FormatKey(ignore.Key{Name: name, Element: true}), NewValue: target[j]})
+			continue
 		}
+		targetOrder = append(targetOrder, name)
 	}
 
+	// Reordering changes no element, so it is reported on the list itself
+	if !reflect.DeepEqual(baseOrder, targetOrder) {
+		diffs = append(diffs, types.FieldDiff{Path: path, OldValue: baseOrder, NewValue: targetOrder})
+	}
 	return diffs
 }
+
+// elementNames returns the name of each element of a list, and whether
+// every element is a map with a distinct, non-empty string name.
+func elementNames(list []interface{}) ([]string, bool) {
+	names := make([]string, len(list))
+	seen := make(map[string]bool, len(list))
+	for i, elem := range list {
+		m, ok := elem.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, _ := m["name"].(string)
+		if name == "" || seen[name] {
+			return nil, false
+		}
+		names[i] = name
+		seen[name] = true
+	}
+	return names, true
+}
+
+// replicasPath is the field changed by scaling a workload.
+const replicasPath = ".spec.replicas"
+
+// managersPath is the field diff recorded when a resource's field managers change.
+const managersPath = ".metadata.managedFields"
+
+// onlyPath reports whether path is the only field that changed.
+func onlyPath(diffs []types.FieldDiff, path string) bool {
+	for _, diff := range diffs {
+		if diff.Path != path {
+			return false
+		}
+	}
+	return len(diffs) > 0
+}
+
+// valuesEqual compares decoded values, treating numbers of different Go
+// types as equal when their values are: live objects carry int64 while
+// snapshots read back from YAML carry int.
+func valuesEqual(a, b interface{}) bool {
+	if an, ok := toFloat(a); ok {
+		bn, ok := toFloat(b)
+		return ok && an == bn
+	}
+
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			other, ok := bv[k]
+			if !ok || !valuesEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// toFloat converts any Go numeric type to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
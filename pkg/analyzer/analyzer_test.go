@@ -1,10 +1,16 @@
 package analyzer
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompare_NoChanges(t *testing.T) {
@@ -81,6 +87,52 @@ func TestCompare_RemovedResource(t *testing.T) {
 	assert.True(t, HasDrift(report))
 }
 
+func TestCompare_PartialSnapshot(t *testing.T) {
+	full := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "team-a", Name: "api", Spec: map[string]interface{}{"replicas": 2}},
+			{Kind: "Deployment", Namespace: "team-a", Name: "worker"},
+			{Kind: "ConfigMap", Namespace: "team-a", Name: "settings"},
+			{Kind: "Deployment", Namespace: "team-b", Name: "web"},
+			{Kind: "ClusterRole", Name: "admin"},
+		},
+	}
+	partial := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Scope: &types.SnapshotScope{Namespaces: []string{"team-a"}, Kinds: []string{"Deployment"}}},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "team-a", Name: "api", Spec: map[string]interface{}{"replicas": 3}},
+		},
+	}
+
+	report := New().Compare(full, partial)
+	assert.Equal(t, 1, report.Summary.RemovedResources, "only team-a's Deployments can be missing")
+	assert.Equal(t, 1, report.Summary.ScaledResources)
+	assert.Equal(t, 0, report.Summary.UnchangedResources)
+	require.NotNil(t, report.Scope)
+	assert.Equal(t, map[string]int{"ConfigMap": 1, "Deployment": 1, "ClusterRole": 1}, report.Scope.OutOfScope)
+
+	report = New().Compare(partial, full)
+	assert.Equal(t, 1, report.Summary.AddedResources, "resources outside the partial snapshot are not added")
+	assert.Equal(t, "team-a/Deployment/worker", report.Entries[0].Resource.FullName())
+
+	// Two partial snapshots are compared where their scopes intersect
+	other := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Scope: &types.SnapshotScope{Namespaces: []string{"team-a", "team-b"}}},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "team-a", Name: "api", Spec: map[string]interface{}{"replicas": 3}},
+			{Kind: "ConfigMap", Namespace: "team-a", Name: "settings"},
+			{Kind: "Deployment", Namespace: "team-b", Name: "web"},
+		},
+	}
+	report = New().Compare(partial, other)
+	assert.False(t, HasDrift(report))
+	assert.Equal(t, 2, report.Scope.Excluded())
+	assert.Equal(t, "namespaces team-a; kinds Deployment", report.Scope.Covered())
+
+	report = New().Compare(full, full)
+	assert.Nil(t, report.Scope, "full snapshots are compared in full")
+}
+
 func TestCompare_ModifiedResource(t *testing.T) {
 	base := &types.ResourceSnapshot{
 		Resources: []types.Resource{
@@ -133,7 +185,8 @@ func TestCompare_SpecChanges(t *testing.T) {
 
 	report := New().Compare(base, target)
 
-	assert.Equal(t, 1, report.Summary.ModifiedResources)
+	// A replica-only change is reported as SCALED
+	assert.Equal(t, 1, report.Summary.ScaledResources)
 	assert.Len(t, report.Entries[0].FieldDiffs, 1)
 	assert.Equal(t, ".spec.replicas", report.Entries[0].FieldDiffs[0].Path)
 }
@@ -159,7 +212,7 @@ func TestCompare_ClusterScopedResources(t *testing.T) {
 func TestFormatReport_NoDrift(t *testing.T) {
 	report := &types.DriftReport{
 		Summary: types.DriftSummary{
-			TotalResources:    5,
+			TotalResources:     5,
 			UnchangedResources: 5,
 		},
 	}
@@ -193,3 +246,452 @@ func TestResourceFullName(t *testing.T) {
 	clusterR := types.Resource{Kind: "ClusterRole", Name: "admin"}
 	assert.Equal(t, "ClusterRole/admin", clusterR.FullName())
 }
+
+func crd(name string, versions ...map[string]interface{}) types.Resource {
+	list := make([]interface{}, 0, len(versions))
+	for _, v := range versions {
+		list = append(list, v)
+	}
+	return types.Resource{
+		Kind: "CustomResourceDefinition",
+		Name: name,
+		Spec: map[string]interface{}{"group": "example.com", "versions": list},
+	}
+}
+
+func crdVersionSpec(name string, storage bool, props string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":    name,
+		"served":  true,
+		"storage": storage,
+		"schema": map[string]interface{}{
+			"openAPIV3Schema": map[string]interface{}{"description": props},
+		},
+	}
+}
+
+func TestCompare_CRDAPIChanges(t *testing.T) {
+	base := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			crd("widgets.example.com", crdVersionSpec("v1alpha1", true, "a")),
+			crd("gadgets.example.com", crdVersionSpec("v1", true, "a")),
+		},
+	}
+	target := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			crd("widgets.example.com", crdVersionSpec("v1alpha1", false, "b"), crdVersionSpec("v1", true, "b")),
+			crd("gizmos.example.com", crdVersionSpec("v1", true, "a")),
+		},
+	}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.APIChanges, 3)
+
+	gadgets := report.APIChanges[0]
+	assert.Equal(t, "gadgets.example.com", gadgets.CRD)
+	assert.Equal(t, types.DriftRemoved, gadgets.Type)
+	assert.Equal(t, []string{"v1"}, gadgets.RemovedVersions)
+
+	gizmos := report.APIChanges[1]
+	assert.Equal(t, types.DriftAdded, gizmos.Type)
+
+	widgets := report.APIChanges[2]
+	assert.Equal(t, types.DriftModified, widgets.Type)
+	assert.Equal(t, []string{"v1"}, widgets.AddedVersions)
+	assert.Equal(t, []string{"v1alpha1"}, widgets.SchemaChanged)
+	assert.Equal(t, "v1alpha1", widgets.OldStorage)
+	assert.Equal(t, "v1", widgets.NewStorage)
+
+	assert.Contains(t, FormatReport(report), "API Changes")
+}
+
+func TestCompare_CRDUnchanged(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{
+		Resources: []types.Resource{crd("widgets.example.com", crdVersionSpec("v1", true, "a"))},
+	}
+
+	report := New().Compare(snapshot, snapshot)
+	assert.Empty(t, report.APIChanges)
+}
+
+func netpol(spec map[string]interface{}) types.Resource {
+	return types.Resource{
+		APIVersion: "networking.k8s.io/v1",
+		Kind:       "NetworkPolicy",
+		Namespace:  "prod",
+		Name:       "web",
+		Spec:       spec,
+	}
+}
+
+func TestCompare_NetworkPolicySummaries(t *testing.T) {
+	podSelector := map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}}
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		netpol(map[string]interface{}{
+			"podSelector": podSelector,
+			"ingress": []interface{}{
+				map[string]interface{}{
+					"from": []interface{}{
+						map[string]interface{}{"podSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"role": "frontend"}}},
+					},
+					"ports": []interface{}{map[string]interface{}{"protocol": "TCP", "port": 80}},
+				},
+			},
+		}),
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		netpol(map[string]interface{}{
+			"podSelector": podSelector,
+			"policyTypes": []interface{}{"Ingress", "Egress"},
+			"ingress": []interface{}{
+				map[string]interface{}{
+					"from": []interface{}{
+						map[string]interface{}{"namespaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"team": "ops"}}},
+					},
+					"ports": []interface{}{map[string]interface{}{"protocol": "TCP", "port": 443}},
+				},
+			},
+		}),
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, []string{
+		"pods app=web now isolated for egress",
+		"pods app=web gained ingress from namespaces team=ops on TCP/443",
+		"pods app=web lost ingress from pods role=frontend on TCP/80",
+	}, report.Entries[0].Summaries)
+	assert.Contains(t, FormatReport(report), "⇒ pods app=web gained ingress")
+}
+
+func TestCompare_NetworkPolicyAdded(t *testing.T) {
+	base := &types.ResourceSnapshot{}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		netpol(map[string]interface{}{"podSelector": map[string]interface{}{}}),
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, []string{"all pods now isolated for ingress"}, report.Entries[0].Summaries)
+}
+
+func TestCompare_RecreatedResource(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", UID: "uid-1", Spec: map[string]interface{}{"replicas": 1}},
+		{Kind: "Service", Namespace: "default", Name: "web", UID: "uid-2"},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", UID: "uid-3", Spec: map[string]interface{}{"replicas": 1}},
+		{Kind: "Service", Namespace: "default", Name: "web", UID: "uid-2"},
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, types.DriftRecreated, report.Entries[0].Type)
+	assert.Equal(t, "default/Deployment/web", report.Entries[0].Resource.FullName())
+	assert.Equal(t, 1, report.Summary.RecreatedResources)
+	assert.Equal(t, 1, report.Summary.UnchangedResources)
+	assert.Contains(t, FormatReport(report), "[*] RECREATED default/Deployment/web")
+}
+
+func TestCompare_RecreatedNeedsBothUIDs(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web"},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", UID: "uid-1"},
+	}}
+
+	report := New().Compare(base, target)
+
+	assert.Empty(t, report.Entries)
+}
+
+func TestCompare_ScaledResource(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{"replicas": 2, "paused": false}},
+		{Kind: "Deployment", Namespace: "default", Name: "api", Spec: map[string]interface{}{"replicas": 2, "paused": false}},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{"replicas": 5, "paused": false}},
+		{Kind: "Deployment", Namespace: "default", Name: "api", Spec: map[string]interface{}{"replicas": 5, "paused": true}},
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 2)
+	assert.Equal(t, types.DriftModified, report.Entries[0].Type)
+	assert.Equal(t, "default/Deployment/api", report.Entries[0].Resource.FullName())
+	assert.Equal(t, types.DriftScaled, report.Entries[1].Type)
+	assert.Equal(t, "default/Deployment/web", report.Entries[1].Resource.FullName())
+	assert.Equal(t, 1, report.Summary.ScaledResources)
+	assert.Equal(t, 1, report.Summary.ModifiedResources)
+}
+
+func TestCompare_IgnoreRules(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{"replicas": 2}},
+		{Kind: "Secret", Namespace: "default", Name: "tls", Data: map[string]interface{}{"tls.crt": "a"}},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{"replicas": 5}},
+		{Kind: "Secret", Namespace: "default", Name: "tls", Data: map[string]interface{}{"tls.crt": "b"}},
+	}}
+	rules := &config.IgnoreConfig{
+		Fields:    map[string][]string{"Deployment": {".spec.replicas"}},
+		Resources: []config.IgnoreResource{{Kind: "Secret"}},
+	}
+
+	assert.Len(t, New().Compare(base, target).Entries, 2)
+
+	report := NewWithOptions(Options{Ignore: rules}).Compare(base, target)
+	assert.Empty(t, report.Entries)
+	assert.Equal(t, 2, base.Resources[0].Spec["replicas"], "the snapshots are not modified")
+}
+
+func TestCompare_OwnershipChange(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Managers: []string{"helm"}, Spec: map[string]interface{}{"replicas": 2}},
+		{Kind: "Deployment", Namespace: "default", Name: "api", Managers: []string{"helm"}, Spec: map[string]interface{}{"paused": false}},
+		{Kind: "Deployment", Namespace: "default", Name: "old", Spec: map[string]interface{}{"paused": false}},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Managers: []string{"argocd-controller"}, Spec: map[string]interface{}{"replicas": 2}},
+		{Kind: "Deployment", Namespace: "default", Name: "api", Managers: []string{"helm", "kubectl-edit"}, Spec: map[string]interface{}{"paused": true}},
+		// Managers not recorded in the base snapshot are not a change
+		{Kind: "Deployment", Namespace: "default", Name: "old", Managers: []string{"helm"}, Spec: map[string]interface{}{"paused": false}},
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 2)
+	assert.Equal(t, types.DriftModified, report.Entries[0].Type)
+	assert.Len(t, report.Entries[0].FieldDiffs, 2)
+	assert.Equal(t, types.DriftOwnership, report.Entries[1].Type)
+	assert.Equal(t, "default/Deployment/web", report.Entries[1].Resource.FullName())
+	assert.Equal(t, []types.FieldDiff{{
+		Path:     ".metadata.managedFields",
+		OldValue: []string{"helm"},
+		NewValue: []string{"argocd-controller"},
+	}}, report.Entries[1].FieldDiffs)
+	assert.Equal(t, 1, report.Summary.OwnershipChanges)
+	assert.Equal(t, 1, report.Summary.UnchangedResources)
+}
+
+func TestCompare_NumericTypesEqual(t *testing.T) {
+	// Live objects decode integers as int64, snapshots read from YAML as int
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{
+			"replicas": 3,
+			"ports":    []interface{}{map[string]interface{}{"port": 80}},
+		}},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{
+			"replicas": int64(3),
+			"ports":    []interface{}{map[string]interface{}{"port": int64(80)}},
+		}},
+	}}
+
+	report := New().Compare(base, target)
+
+	assert.Empty(t, report.Entries)
+}
+
+func TestCompare_ListElementsByName(t *testing.T) {
+	container := func(name, image string, env ...string) map[string]interface{} {
+		var vars []interface{}
+		for i := 0; i < len(env); i += 2 {
+			vars = append(vars, map[string]interface{}{"name": env[i], "value": env[i+1]})
+		}
+		return map[string]interface{}{"name": name, "image": image, "env": vars, "args": []interface{}{"--port", "80"}}
+	}
+	deployment := func(containers ...interface{}) types.Resource {
+		return types.Resource{Kind: "Deployment", Namespace: "default", Name: "web", Spec: map[string]interface{}{
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}},
+		}}
+	}
+	base := &types.ResourceSnapshot{Resources: []types.Resource{deployment(
+		container("nginx", "nginx:1.25", "LOG_LEVEL", "info", "WORKERS", "4"),
+		container("sidecar", "envoy:1.29"),
+	)}}
+	changedArgs := container("sidecar", "envoy:1.29")
+	changedArgs["args"] = []interface{}{"--port", "8080", "--verbose"}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{deployment(
+		container("nginx", "nginx:1.26", "LOG_LEVEL", "debug", "WORKERS", "4", "TZ", "UTC"),
+		changedArgs,
+	)}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 1)
+	var paths []string
+	for _, d := range report.Entries[0].FieldDiffs {
+		paths = append(paths, d.Path)
+	}
+	assert.Equal(t, []string{
+		".spec.template.spec.containers[nginx].env[LOG_LEVEL].value",
+		".spec.template.spec.containers[nginx].env[TZ]",
+		".spec.template.spec.containers[nginx].image",
+		".spec.template.spec.containers[sidecar].args[1]",
+		".spec.template.spec.containers[sidecar].args[2]",
+	}, paths)
+	diffs := report.Entries[0].FieldDiffs
+	assert.Equal(t, "nginx:1.25", diffs[2].OldValue)
+	assert.Equal(t, "nginx:1.26", diffs[2].NewValue)
+	assert.Nil(t, diffs[1].OldValue, "an added element has no old value")
+	assert.Equal(t, map[string]interface{}{"name": "TZ", "value": "UTC"}, diffs[1].NewValue)
+	assert.Nil(t, diffs[4].OldValue)
+	assert.Equal(t, "--verbose", diffs[4].NewValue)
+
+	// Reported paths can be pasted into ignore rules
+	cfg := &config.IgnoreConfig{Fields: map[string][]string{"deployment": paths[:3]}}
+	require.NoError(t, ignore.Validate(cfg))
+	b, tg, _ := ignore.Apply(cfg, base, target)
+	report = New().Compare(b, tg)
+	require.Len(t, report.Entries, 1)
+	require.Len(t, report.Entries[0].FieldDiffs, 2)
+	assert.Equal(t, ".spec.template.spec.containers[sidecar].args[1]", report.Entries[0].FieldDiffs[0].Path)
+}
+
+func TestCompare_QuotedKeys(t *testing.T) {
+	configMap := func(props string) types.Resource {
+		return types.Resource{Kind: "ConfigMap", Namespace: "default", Name: "app", Data: map[string]interface{}{"app.properties": props}}
+	}
+	report := New().Compare(
+		&types.ResourceSnapshot{Resources: []types.Resource{configMap("a=1")}},
+		&types.ResourceSnapshot{Resources: []types.Resource{configMap("a=2")}},
+	)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, `.data["app.properties"]`, report.Entries[0].FieldDiffs[0].Path)
+}
+
+func TestCompareLists(t *testing.T) {
+	named := func(names ...string) []interface{} {
+		var list []interface{}
+		for _, n := range names {
+			list = append(list, map[string]interface{}{"name": n})
+		}
+		return list
+	}
+
+	diffs := compareLists(".spec.volumes", named("data", "config"), named("config", "data"))
+	require.Len(t, diffs, 1, "reordering is reported on the list")
+	assert.Equal(t, types.FieldDiff{Path: ".spec.volumes", OldValue: []string{"data", "config"}, NewValue: []string{"config", "data"}}, diffs[0])
+
+	diffs = compareLists(".spec.volumes", named("data", "config"), named("config"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, ".spec.volumes[data]", diffs[0].Path)
+	assert.Nil(t, diffs[0].NewValue)
+
+	// Unnamed or duplicate names fall back to positions
+	ports := []interface{}{map[string]interface{}{"containerPort": 80}, map[string]interface{}{"containerPort": 443}}
+	diffs = compareLists(".spec.ports", ports, []interface{}{map[string]interface{}{"containerPort": 8080}, ports[1]})
+	require.Len(t, diffs, 1)
+	assert.Equal(t, ".spec.ports[0].containerPort", diffs[0].Path)
+	diffs = compareLists(".spec.containers", named("app", "app"), named("app"))
+	require.Len(t, diffs, 1)
+	assert.Equal(t, ".spec.containers[1]", diffs[0].Path)
+
+	assert.Empty(t, compareLists(".spec.args", []interface{}{"a", 1}, []interface{}{"a", int64(1)}))
+}
+
+func TestCompare_ContentHashShortCircuits(t *testing.T) {
+	res := func(hash string, replicas int) types.Resource {
+		return types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", ContentHash: hash,
+			Spec: map[string]interface{}{"replicas": replicas}}
+	}
+
+	// Equal hashes are trusted without looking at the content
+	report := New().Compare(
+		&types.ResourceSnapshot{Resources: []types.Resource{res("abc", 2)}},
+		&types.ResourceSnapshot{Resources: []types.Resource{res("abc", 3)}})
+	assert.False(t, HasDrift(report))
+	assert.Equal(t, 1, report.Summary.UnchangedResources)
+
+	// Different or missing hashes fall back to the deep comparison
+	for _, hashes := range [][2]string{{"abc", "def"}, {"", ""}, {"abc", ""}} {
+		report := New().Compare(
+			&types.ResourceSnapshot{Resources: []types.Resource{res(hashes[0], 2)}},
+			&types.ResourceSnapshot{Resources: []types.Resource{res(hashes[1], 2)}})
+		assert.False(t, HasDrift(report), hashes)
+
+		report = New().Compare(
+			&types.ResourceSnapshot{Resources: []types.Resource{res(hashes[0], 2)}},
+			&types.ResourceSnapshot{Resources: []types.Resource{res(hashes[1], 3)}})
+		assert.Equal(t, 1, report.Summary.ScaledResources, hashes)
+	}
+}
+
+// largeSnapshot builds n Deployments, each with the given content hash.
+func largeSnapshot(n int, hash string) *types.ResourceSnapshot {
+	snapshot := &types.ResourceSnapshot{}
+	for i := 0; i < n; i++ {
+		snapshot.Resources = append(snapshot.Resources, types.Resource{
+			Kind: "Deployment", Namespace: fmt.Sprintf("ns-%d", i%100), Name: fmt.Sprintf("app-%d", i),
+			ContentHash: hash,
+			Labels:      map[string]string{"app": fmt.Sprintf("app-%d", i)},
+			Spec: map[string]interface{}{
+				"replicas": 3,
+				"template": map[string]interface{}{"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{
+						"name": "app", "image": fmt.Sprintf("registry.example.com/app-%d:1.2.3", i),
+						"env": []interface{}{map[string]interface{}{"name": "LOG_LEVEL", "value": "info"}},
+					}},
+				}},
+			},
+		})
+	}
+	return snapshot
+}
+
+func TestCompare_ParallelIsDeterministic(t *testing.T) {
+	log.SetLevel(log.WarnLevel)
+	base, target := largeSnapshot(5000, ""), largeSnapshot(5000, "")
+	for i := 0; i < len(target.Resources); i += 7 {
+		target.Resources[i].Spec = map[string]interface{}{"replicas": 4, "paused": true, "strategy": "Recreate"}
+	}
+	target.Resources = target.Resources[:4990]
+
+	serial := NewWithOptions(Options{Workers: 1}).Compare(base, target)
+	require.NotEmpty(t, serial.Entries)
+	for i := 0; i < 5; i++ {
+		parallel := NewWithOptions(Options{Workers: 8}).Compare(base, target)
+		parallel.Timestamp = serial.Timestamp
+		assert.Equal(t, serial, parallel)
+	}
+}
+
+func TestCompareContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := New().CompareContext(ctx, largeSnapshot(10, ""), largeSnapshot(10, ""))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkCompare(b *testing.B) {
+	log.SetLevel(log.WarnLevel)
+	for _, bc := range []struct {
+		name    string
+		hash    string
+		workers int
+	}{
+		{name: "50k-deep-serial", workers: 1},
+		{name: "50k-deep"},
+		{name: "50k-hashed", hash: "abc"},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			base, target := largeSnapshot(50000, bc.hash), largeSnapshot(50000, bc.hash)
+			a := NewWithOptions(Options{Workers: bc.workers})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				a.Compare(base, target)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// crdKind is the Kind of CustomResourceDefinition objects.
+const crdKind = "CustomResourceDefinition"
+
+// crdVersion summarizes one served version of a CRD.
+type crdVersion struct {
+	storage    bool
+	schemaHash string
+}
+
+// compareCRDs produces API changes for CRDs added, removed, or modified
+// between the two indexes.
+func compareCRDs(baseIndex, targetIndex map[string]types.Resource) []types.APIChange {
+	var changes []types.APIChange
+
+	names := make(map[string]bool)
+	for name, res := range baseIndex {
+		if res.Kind == crdKind {
+			names[name] = true
+		}
+	}
+	for name, res := range targetIndex {
+		if res.Kind == crdKind {
+			names[name] = true
+		}
+	}
+
+	for name := range names {
+		baseRes, inBase := baseIndex[name]
+		targetRes, inTarget := targetIndex[name]
+
+		var baseVersions, targetVersions map[string]crdVersion
+		if inBase {
+			baseVersions = servedVersions(baseRes)
+		}
+		if inTarget {
+			targetVersions = servedVersions(targetRes)
+		}
+
+		change := types.APIChange{Type: types.DriftModified}
+		switch {
+		case !inBase:
+			change.CRD = targetRes.Name
+			change.Type = types.DriftAdded
+		case !inTarget:
+			change.CRD = baseRes.Name
+			change.Type = types.DriftRemoved
+		default:
+			change.CRD = targetRes.Name
+		}
+
+		for version, tv := range targetVersions {
+			bv, ok := baseVersions[version]
+			if !ok {
+				change.AddedVersions = append(change.AddedVersions, version)
+			} else if bv.schemaHash != tv.schemaHash {
+				change.SchemaChanged = append(change.SchemaChanged, version)
+			}
+			if tv.storage {
+				change.NewStorage = version
+			}
+		}
+		for version, bv := range baseVersions {
+			if _, ok := targetVersions[version]; !ok {
+				change.RemovedVersions = append(change.RemovedVersions, version)
+			}
+			if bv.storage {
+				change.OldStorage = version
+			}
+		}
+
+		if change.Type == types.DriftModified &&
+			len(change.AddedVersions) == 0 && len(change.RemovedVersions) == 0 &&
+			len(change.SchemaChanged) == 0 && change.OldStorage == change.NewStorage {
+			continue
+		}
+
+		sort.Strings(change.AddedVersions)
+		sort.Strings(change.RemovedVersions)
+		sort.Strings(change.SchemaChanged)
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].CRD < changes[j].CRD
+	})
+	return changes
+}
+
+// servedVersions returns the served versions of a CRD keyed by name.
+func servedVersions(res types.Resource) map[string]crdVersion {
+	versions := make(map[string]crdVersion)
+
+	list, _ := res.Spec["versions"].([]interface{})
+	for _, item := range list {
+		v, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := v["name"].(string)
+		if served, _ := v["served"].(bool); !served || name == "" {
+			continue
+		}
+		storage, _ := v["storage"].(bool)
+		versions[name] = crdVersion{
+			storage:    storage,
+			schemaHash: hashValue(v["schema"]),
+		}
+	}
+
+	return versions
+}
+
+// hashValue returns a stable digest of a decoded YAML/JSON value.
+func hashValue(v interface{}) string {
+	// encoding/json sorts map keys, so equal values hash equally.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
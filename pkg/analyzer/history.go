@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// ResourceChange is one commit in a resource's history and how the
+// resource differs from its version before that commit.
+type ResourceChange struct {
+	CommitHash string    `json:"commitHash" yaml:"commitHash"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	Message    string    `json:"message" yaml:"message"`
+	// Type is ADDED when the resource appears, REMOVED when it is deleted,
+	// and otherwise how it changed (MODIFIED, SCALED, RECREATED, ...).
+	Type       types.DriftType   `json:"type" yaml:"type"`
+	FieldDiffs []types.FieldDiff `json:"fieldDiffs,omitempty" yaml:"fieldDiffs,omitempty"`
+}
+
+// DiffResource compares two versions of one resource as Compare compares
+// the resources present in both snapshots, and reports whether it changed.
+func DiffResource(base, target types.Resource) (types.DriftEntry, bool) {
+	return compareEntry(base, target)
+}
+
+// History returns the changes between consecutive versions of a resource,
+// oldest first, given its versions as returned by timetravel's Timeline
+// (newest first). Versions that differ from the one before only in fields
+// the analyzer does not compare are left out.
+func History(versions []types.ResourceVersion) []ResourceChange {
+	var changes []ResourceChange
+	var previous *types.Resource
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		change := ResourceChange{CommitHash: v.CommitHash, Timestamp: v.Timestamp, Message: v.Message}
+		switch {
+		case v.Deleted || v.Resource == nil:
+			if previous == nil {
+				continue
+			}
+			change.Type = types.DriftRemoved
+		case previous == nil:
+			change.Type = types.DriftAdded
+		default:
+			entry, changed := compareEntry(*previous, *v.Resource)
+			if !changed {
+				continue
+			}
+			change.Type = entry.Type
+			change.FieldDiffs = entry.FieldDiffs
+		}
+		changes = append(changes, change)
+		previous = v.Resource
+	}
+	return changes
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	version := func(hour int, replicas interface{}, image string) types.ResourceVersion {
+		v := types.ResourceVersion{CommitHash: string(rune('a'+hour)) + "0000000", Timestamp: base.Add(time.Duration(hour) * time.Hour)}
+		if replicas == nil {
+			v.Deleted = true
+			return v
+		}
+		v.Resource = &types.Resource{Kind: "Deployment", Namespace: "default", Name: "api", Spec: map[string]interface{}{
+			"replicas": replicas, "image": image,
+		}}
+		return v
+	}
+	// Newest first, as Timeline returns them
+	versions := []types.ResourceVersion{
+		version(5, 2, "api:2"),
+		version(4, nil, ""),
+		version(3, 3, "api:2"),
+		version(2, 3, "api:2"),
+		version(1, 3, "api:1"),
+		version(0, 2, "api:1"),
+	}
+
+	changes := History(versions)
+
+	var kinds []types.DriftType
+	for _, c := range changes {
+		kinds = append(kinds, c.Type)
+	}
+	assert.Equal(t, []types.DriftType{
+		types.DriftAdded, types.DriftScaled, types.DriftModified, types.DriftRemoved, types.DriftAdded,
+	}, kinds, "a version without compared changes is left out")
+	require.Len(t, changes[2].FieldDiffs, 1)
+	assert.Equal(t, types.FieldDiff{Path: ".spec.image", OldValue: "api:1", NewValue: "api:2"}, changes[2].FieldDiffs[0])
+	assert.Equal(t, base.Add(4*time.Hour), changes[3].Timestamp)
+}
+
+func TestDiffResource(t *testing.T) {
+	a := types.Resource{Kind: "ConfigMap", Name: "cfg", Data: map[string]interface{}{"mode": "a"}}
+	b := types.Resource{Kind: "ConfigMap", Name: "cfg", Data: map[string]interface{}{"mode": "b"}}
+
+	entry, changed := DiffResource(a, b)
+	require.True(t, changed)
+	assert.Equal(t, types.DriftModified, entry.Type)
+	assert.Equal(t, ".data.mode", entry.FieldDiffs[0].Path)
+
+	_, changed = DiffResource(a, a)
+	assert.False(t, changed)
+}
@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// netpolKind is the Kind of NetworkPolicy objects.
+const netpolKind = "NetworkPolicy"
+
+// summarizeNetworkPolicy explains, in terms of allowed traffic, what changed
+// between two versions of a NetworkPolicy. Either side may be nil for
+// policies that were added or removed.
+func summarizeNetworkPolicy(base, target *types.Resource) []string {
+	var baseSpec, targetSpec map[string]interface{}
+	if base != nil {
+		baseSpec = base.Spec
+	}
+	if target != nil {
+		targetSpec = target.Spec
+	}
+
+	current := targetSpec
+	if target == nil {
+		current = baseSpec
+	}
+	subject := "all pods"
+	if sel := describeSelector(current["podSelector"], ""); sel != "" {
+		subject = "pods " + sel
+	}
+
+	var summaries []string
+
+	baseTypes := policyTypes(baseSpec)
+	targetTypes := policyTypes(targetSpec)
+	for _, direction := range []string{"Ingress", "Egress"} {
+		switch {
+		case targetTypes[direction] && !baseTypes[direction]:
+			summaries = append(summaries, fmt.Sprintf("%s now isolated for %s", subject, strings.ToLower(direction)))
+		case baseTypes[direction] && !targetTypes[direction]:
+			summaries = append(summaries, fmt.Sprintf("%s no longer isolated for %s", subject, strings.ToLower(direction)))
+		}
+	}
+
+	baseSelector := describeSelector(baseSpec["podSelector"], "all pods")
+	targetSelector := describeSelector(targetSpec["podSelector"], "all pods")
+	if base != nil && target != nil && baseSelector != targetSelector {
+		summaries = append(summaries, fmt.Sprintf("policy now selects %s (was %s)", targetSelector, baseSelector))
+	}
+
+	baseEdges := policyEdges(baseSpec)
+	targetEdges := policyEdges(targetSpec)
+	for _, edge := range sortedKeys(targetEdges) {
+		if !baseEdges[edge] {
+			summaries = append(summaries, fmt.Sprintf("%s gained %s", subject, edge))
+		}
+	}
+	for _, edge := range sortedKeys(baseEdges) {
+		if !targetEdges[edge] {
+			summaries = append(summaries, fmt.Sprintf("%s lost %s", subject, edge))
+		}
+	}
+
+	return summaries
+}
+
+// policyTypes returns the directions a policy isolates. Without explicit
+// policyTypes, Ingress is always implied and Egress only if egress rules exist.
+func policyTypes(spec map[string]interface{}) map[string]bool {
+	out := make(map[string]bool)
+	if spec == nil {
+		return out
+	}
+	if list, ok := spec["policyTypes"].([]interface{}); ok && len(list) > 0 {
+		for _, t := range list {
+			if s, ok := t.(string); ok {
+				out[s] = true
+			}
+		}
+		return out
+	}
+	out["Ingress"] = true
+	if _, ok := spec["egress"]; ok {
+		out["Egress"] = true
+	}
+	return out
+}
+
+// policyEdges flattens ingress and egress rules into descriptions like
+// "ingress from pods role=frontend on TCP/80".
+func policyEdges(spec map[string]interface{}) map[string]bool {
+	edges := make(map[string]bool)
+	if spec == nil {
+		return edges
+	}
+
+	for _, dir := range []struct{ field, peers, verb string }{
+		{"ingress", "from", "ingress from"},
+		{"egress", "to", "egress to"},
+	} {
+		rules, _ := spec[dir.field].([]interface{})
+		for _, item := range rules {
+			rule, _ := item.(map[string]interface{})
+			peers := describePeers(rule[dir.peers])
+			ports := describePorts(rule["ports"])
+			for _, peer := range peers {
+				for _, port := range ports {
+					edges[fmt.Sprintf("%s %s on %s", dir.verb, peer, port)] = true
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// describePeers renders NetworkPolicyPeer entries; no peers means anywhere.
+func describePeers(v interface{}) []string {
+	list, _ := v.([]interface{})
+	if len(list) == 0 {
+		return []string{"anywhere"}
+	}
+
+	var out []string
+	for _, item := range list {
+		peer, _ := item.(map[string]interface{})
+		if block, ok := peer["ipBlock"].(map[string]interface{}); ok {
+			desc := fmt.Sprint(block["cidr"])
+			if except, ok := block["except"].([]interface{}); ok && len(except) > 0 {
+				desc += fmt.Sprintf(" (except %s)", joinValues(except))
+			}
+			out = append(out, desc)
+			continue
+		}
+
+		_, hasPods := peer["podSelector"]
+		_, hasNamespaces := peer["namespaceSelector"]
+		switch {
+		case hasPods && hasNamespaces:
+			out = append(out, fmt.Sprintf("pods %s in namespaces %s",
+				describeSelector(peer["podSelector"], "(any)"),
+				describeSelector(peer["namespaceSelector"], "(any)")))
+		case hasNamespaces:
+			out = append(out, "namespaces "+describeSelector(peer["namespaceSelector"], "(any)"))
+		default:
+			out = append(out, "pods "+describeSelector(peer["podSelector"], "(any)"))
+		}
+	}
+	return out
+}
+
+// describePorts renders NetworkPolicyPort entries; no ports means all ports.
+func describePorts(v interface{}) []string {
+	list, _ := v.([]interface{})
+	if len(list) == 0 {
+		return []string{"all ports"}
+	}
+
+	var out []string
+	for _, item := range list {
+		port, _ := item.(map[string]interface{})
+		protocol := "TCP"
+		if p, ok := port["protocol"].(string); ok {
+			protocol = p
+		}
+		desc := protocol
+		if p, ok := port["port"]; ok {
+			desc += fmt.Sprintf("/%v", p)
+			if end, ok := port["endPort"]; ok {
+				desc += fmt.Sprintf("-%v", end)
+			}
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+// describeSelector renders a LabelSelector, e.g. "app=web,tier in (a,b)".
+func describeSelector(v interface{}, empty string) string {
+	sel, _ := v.(map[string]interface{})
+
+	var parts []string
+	if labels, ok := sel["matchLabels"].(map[string]interface{}); ok {
+		for _, k := range sortedKeys(labels) {
+			parts = append(parts, fmt.Sprintf("%s=%v", k, labels[k]))
+		}
+	}
+	if exprs, ok := sel["matchExpressions"].([]interface{}); ok {
+		for _, item := range exprs {
+			expr, _ := item.(map[string]interface{})
+			op := strings.ToLower(fmt.Sprint(expr["operator"]))
+			values, _ := expr["values"].([]interface{})
+			if len(values) == 0 {
+				parts = append(parts, fmt.Sprintf("%s %v", op, expr["key"]))
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%v %s (%s)", expr["key"], op, joinValues(values)))
+		}
+	}
+
+	if len(parts) == 0 {
+		return empty
+	}
+	return strings.Join(parts, ",")
+}
+
+// joinValues joins decoded YAML scalars with commas.
+func joinValues(values []interface{}) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, fmt.Sprint(v))
+	}
+	return strings.Join(strs, ",")
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
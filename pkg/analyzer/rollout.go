@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// templatePaths are the pod template field paths of each workload kind.
+var templatePaths = map[string][]string{
+	"Deployment":  {"template"},
+	"StatefulSet": {"template"},
+	"DaemonSet":   {"template"},
+	"ReplicaSet":  {"template"},
+	"Job":         {"template"},
+	"CronJob":     {"jobTemplate", "spec", "template"},
+}
+
+// ContentChecksum returns the SHA-256 of a ConfigMap's or Secret's content
+// (data, binaryData, and stringData), or "" for other kinds.
+func ContentChecksum(res types.Resource) string {
+	if res.Kind != "ConfigMap" && res.Kind != "Secret" {
+		return ""
+	}
+	content := map[string]interface{}{"data": res.Data}
+	for _, key := range []string{"binaryData", "stringData"} {
+		if v, ok := res.Raw[key]; ok {
+			content[key] = v
+		}
+	}
+	// encoding/json sorts map keys, so the encoding is canonical
+	data, err := json.Marshal(content)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// correlateRollouts explains pod template changes of workloads by the
+// ConfigMaps and Secrets they reference whose content changed in the same
+// report, and records the content checksums of those ConfigMaps and Secrets.
+func correlateRollouts(entries []types.DriftEntry, baseIndex, targetIndex map[string]types.Resource) {
+	changed := make(map[string]string)
+	for i := range entries {
+		entry := &entries[i]
+		res := entry.Resource
+		if ContentChecksum(res) == "" || entry.Type == types.DriftRemoved {
+			continue
+		}
+		name := res.FullName()
+		after := ContentChecksum(targetIndex[name])
+		if base, ok := baseIndex[name]; ok {
+			before := ContentChecksum(base)
+			if before == after {
+				continue
+			}
+			changed[name] = fmt.Sprintf("checksum %s → %s", before[:8], after[:8])
+		} else {
+			changed[name] = fmt.Sprintf("checksum %s", after[:8])
+		}
+		entry.Summaries = append(entry.Summaries, "content "+changed[name])
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		path, ok := templatePaths[entry.Resource.Kind]
+		if !ok || entry.Type == types.DriftAdded || entry.Type == types.DriftRemoved {
+			continue
+		}
+		if !touchesTemplate(entry.FieldDiffs, ".spec."+strings.Join(path, ".")) {
+			continue
+		}
+		template, _ := nested(entry.Resource.Spec, path...).(map[string]interface{})
+		podSpec, _ := template["spec"].(map[string]interface{})
+		for _, ref := range configReferences(podSpec) {
+			name := entry.Resource.Namespace + "/" + ref
+			if why, ok := changed[name]; ok {
+				entry.Summaries = append(entry.Summaries, fmt.Sprintf("pod template changed after %s changed (%s)", ref, why))
+			}
+		}
+	}
+}
+
+// touchesTemplate reports whether any field diff is inside the pod template.
+func touchesTemplate(diffs []types.FieldDiff, templatePath string) bool {
+	for _, diff := range diffs {
+		if diff.Path == templatePath || strings.HasPrefix(diff.Path, templatePath+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// configReferences returns the ConfigMaps and Secrets a pod spec mounts or
+// reads environment variables from, as sorted Kind/name.
+func configReferences(podSpec map[string]interface{}) []string {
+	refs := make(map[string]bool)
+	add := func(kind string, name interface{}) {
+		if s, ok := name.(string); ok && s != "" {
+			refs[kind+"/"+s] = true
+		}
+	}
+
+	for _, v := range list(podSpec["volumes"]) {
+		add("ConfigMap", nested(v, "configMap", "name"))
+		add("Secret", nested(v, "secret", "secretName"))
+		for _, source := range list(nested(v, "projected", "sources")) {
+			add("ConfigMap", nested(source, "configMap", "name"))
+			add("Secret", nested(source, "secret", "name"))
+		}
+	}
+	for _, key := range []string{"initContainers", "containers"} {
+		for _, c := range list(podSpec[key]) {
+			for _, from := range list(nested(c, "envFrom")) {
+				add("ConfigMap", nested(from, "configMapRef", "name"))
+				add("Secret", nested(from, "secretRef", "name"))
+			}
+			for _, env := range list(nested(c, "env")) {
+				add("ConfigMap", nested(env, "valueFrom", "configMapKeyRef", "name"))
+				add("Secret", nested(env, "valueFrom", "secretKeyRef", "name"))
+			}
+		}
+	}
+
+	return sortedKeys(refs)
+}
+
+// nested walks a chain of map keys, returning nil if any is missing.
+func nested(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// list returns v as a list, or nil if it is not one.
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func configMap(name, value string) types.Resource {
+	return types.Resource{Kind: "ConfigMap", Namespace: "prod", Name: name, Data: map[string]interface{}{"app.yaml": value}}
+}
+
+func deploymentUsing(name, checksum string) types.Resource {
+	return types.Resource{Kind: "Deployment", Namespace: "prod", Name: name, Spec: map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{"checksum/config": checksum},
+			},
+			"spec": map[string]interface{}{
+				"volumes": []interface{}{
+					map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "app-config"}},
+				},
+				"containers": []interface{}{
+					map[string]interface{}{
+						"name":    "app",
+						"envFrom": []interface{}{map[string]interface{}{"secretRef": map[string]interface{}{"name": "app-secrets"}}},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestContentChecksum(t *testing.T) {
+	a := ContentChecksum(configMap("app-config", "level: info"))
+	assert.Len(t, a, 64)
+	assert.Equal(t, a, ContentChecksum(configMap("other", "level: info")))
+	assert.NotEqual(t, a, ContentChecksum(configMap("app-config", "level: debug")))
+	assert.Empty(t, ContentChecksum(types.Resource{Kind: "Deployment"}))
+}
+
+func TestCompare_CorrelatesRollout(t *testing.T) {
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		configMap("app-config", "level: info"),
+		deploymentUsing("api", "aaa"),
+		deploymentUsing("worker", "aaa"),
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		configMap("app-config", "level: debug"),
+		deploymentUsing("api", "bbb"),
+		// worker was not rolled out, so it is not part of the report
+		deploymentUsing("worker", "aaa"),
+	}}
+
+	report := New().Compare(base, target)
+
+	require.Len(t, report.Entries, 2)
+	cm, api := report.Entries[0], report.Entries[1]
+	require.Equal(t, "prod/ConfigMap/app-config", cm.Resource.FullName())
+	require.Len(t, cm.Summaries, 1)
+	assert.Contains(t, cm.Summaries[0], "content checksum ")
+
+	require.Equal(t, "prod/Deployment/api", api.Resource.FullName())
+	require.Len(t, api.Summaries, 1)
+	assert.Contains(t, api.Summaries[0], "pod template changed after ConfigMap/app-config changed (checksum ")
+}
+
+func TestConfigReferences(t *testing.T) {
+	podSpec := deploymentUsing("api", "x").Spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, []string{"ConfigMap/app-config", "Secret/app-secrets"}, configReferences(podSpec))
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// SurvivalReport tells how much of a past snapshot is still live: which of
+// its resources are unchanged, changed, or gone.
+type SurvivalReport struct {
+	Commit    string    `json:"commit" yaml:"commit"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Unchanged []string  `json:"unchanged" yaml:"unchanged"`
+	// Changed maps each changed resource to how it changed (MODIFIED,
+	// RECREATED, ...).
+	Changed map[string]types.DriftType `json:"changed" yaml:"changed"`
+	Gone    []string                   `json:"gone" yaml:"gone"`
+	// New counts live resources the snapshot did not have.
+	New int `json:"new" yaml:"new"`
+}
+
+// Total returns the number of resources in the past snapshot.
+func (r *SurvivalReport) Total() int {
+	return len(r.Unchanged) + len(r.Changed) + len(r.Gone)
+}
+
+// Survival classifies the resources of the past snapshot base by report,
+// the drift from base to the live state.
+func Survival(base *types.ResourceSnapshot, report *types.DriftReport) *SurvivalReport {
+	survival := &SurvivalReport{
+		Commit:    base.Metadata.CommitHash,
+		Timestamp: base.Metadata.Timestamp,
+		Unchanged: []string{},
+		Changed:   map[string]types.DriftType{},
+		Gone:      []string{},
+	}
+	drifted := make(map[string]bool, len(report.Entries))
+	for _, e := range report.Entries {
+		name := e.Resource.FullName()
+		drifted[name] = true
+		switch e.Type {
+		case types.DriftAdded:
+			survival.New++
+		case types.DriftRemoved:
+			survival.Gone = append(survival.Gone, name)
+		default:
+			survival.Changed[name] = e.Type
+		}
+	}
+	for _, res := range base.Resources {
+		if name := res.FullName(); !drifted[name] {
+			survival.Unchanged = append(survival.Unchanged, name)
+		}
+	}
+	sort.Strings(survival.Unchanged)
+	sort.Strings(survival.Gone)
+	return survival
+}
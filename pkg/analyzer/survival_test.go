@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSurvival(t *testing.T) {
+	base := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{CommitHash: "abc123"},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "shop", Name: "web", Spec: map[string]interface{}{"replicas": 2}},
+			{Kind: "Service", Namespace: "shop", Name: "web"},
+			{Kind: "ConfigMap", Namespace: "shop", Name: "settings", Data: map[string]interface{}{"mode": "a"}},
+			{Kind: "ConfigMap", Namespace: "shop", Name: "legacy"},
+		},
+	}
+	live := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "shop", Name: "web", Spec: map[string]interface{}{"replicas": 2}},
+			{Kind: "Service", Namespace: "shop", Name: "web"},
+			{Kind: "ConfigMap", Namespace: "shop", Name: "settings", Data: map[string]interface{}{"mode": "b"}},
+			{Kind: "ConfigMap", Namespace: "shop", Name: "feature-flags"},
+		},
+	}
+
+	survival := Survival(base, New().Compare(base, live))
+
+	assert.Equal(t, "abc123", survival.Commit)
+	assert.Equal(t, []string{"shop/Deployment/web", "shop/Service/web"}, survival.Unchanged)
+	assert.Equal(t, map[string]types.DriftType{"shop/ConfigMap/settings": types.DriftModified}, survival.Changed)
+	assert.Equal(t, []string{"shop/ConfigMap/legacy"}, survival.Gone)
+	assert.Equal(t, 1, survival.New)
+	assert.Equal(t, 4, survival.Total())
+}
@@ -0,0 +1,170 @@
+// Package anomaly tracks the rolling distribution of snapshot delta sizes and
+// flags deltas that are statistically unusual, e.g. hundreds of Secrets
+// modified at once in a cluster where a handful change per snapshot.
+package anomaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// totalSeries counts every changed resource in a snapshot.
+const totalSeries = "total"
+
+// Validate checks that the anomaly configuration is well-formed.
+func Validate(cfg *config.AnomalyConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Window < 2 {
+		return fmt.Errorf("watch.anomaly.window must be at least 2")
+	}
+	if cfg.MinSamples < 2 || cfg.MinSamples > cfg.Window {
+		return fmt.Errorf("watch.anomaly.min_samples must be between 2 and window (%d)", cfg.Window)
+	}
+	if cfg.Threshold <= 0 {
+		return fmt.Errorf("watch.anomaly.threshold must be positive")
+	}
+	if _, err := policy.ParseSeverity(cfg.Severity); err != nil {
+		return fmt.Errorf("watch.anomaly.severity: %w", err)
+	}
+	return nil
+}
+
+// Detector scores snapshot deltas against persisted rolling history.
+type Detector struct {
+	cfg    *config.AnomalyConfig
+	path   string
+	series map[string][]int
+}
+
+// Load reads the detector state from path; a missing file starts empty.
+func Load(cfg *config.AnomalyConfig, path string) (*Detector, error) {
+	d := &Detector{cfg: cfg, path: path, series: make(map[string][]int)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return d, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anomaly state: %w", err)
+	}
+	if err := json.Unmarshal(data, &d.series); err != nil {
+		return nil, fmt.Errorf("failed to parse anomaly state: %w", err)
+	}
+	return d, nil
+}
+
+// Save writes the detector state back to disk.
+func (d *Detector) Save() error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0755); err != nil {
+		return fmt.Errorf("failed to create anomaly state directory: %w", err)
+	}
+	data, err := json.Marshal(d.series)
+	if err != nil {
+		return fmt.Errorf("failed to encode anomaly state: %w", err)
+	}
+	if err := os.WriteFile(d.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write anomaly state: %w", err)
+	}
+	return nil
+}
+
+// Observe scores the report's change counts against each series' history,
+// then records them. Series that did not change this time record a zero.
+func (d *Detector) Observe(report *types.DriftReport) []types.Anomaly {
+	counts := Counts(report)
+	for name := range d.series {
+		if _, ok := counts[name]; !ok {
+			counts[name] = 0
+		}
+	}
+
+	// A new series has implicitly been quiet for as long as we've watched.
+	watched := len(d.series[totalSeries])
+
+	var anomalies []types.Anomaly
+	for name, changes := range counts {
+		history, known := d.series[name]
+		if !known {
+			history = make([]int, watched)
+		}
+
+		if a, ok := d.score(name, changes, history); ok {
+			anomalies = append(anomalies, a)
+		}
+
+		history = append(history, changes)
+		if len(history) > d.cfg.Window {
+			history = history[len(history)-d.cfg.Window:]
+		}
+		d.series[name] = history
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Score != anomalies[j].Score {
+			return anomalies[i].Score > anomalies[j].Score
+		}
+		return anomalies[i].Series < anomalies[j].Series
+	})
+	return anomalies
+}
+
+// score compares a delta with its series' history.
+func (d *Detector) score(name string, changes int, history []int) (types.Anomaly, bool) {
+	if len(history) < d.cfg.MinSamples || changes < d.cfg.MinChanges {
+		return types.Anomaly{}, false
+	}
+
+	var sum float64
+	for _, v := range history {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(history))
+
+	var variance float64
+	for _, v := range history {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(history)))
+
+	// Perfectly steady series would make any change infinitely unusual;
+	// treat one resource as the smallest meaningful spread.
+	score := (float64(changes) - mean) / math.Max(stddev, 1)
+	if score < d.cfg.Threshold {
+		return types.Anomaly{}, false
+	}
+
+	return types.Anomaly{
+		Series:  name,
+		Changes: changes,
+		Mean:    round(mean),
+		StdDev:  round(stddev),
+		Score:   round(score),
+	}, true
+}
+
+// Counts returns the number of changed resources per series in a report.
+func Counts(report *types.DriftReport) map[string]int {
+	counts := map[string]int{totalSeries: len(report.Entries)}
+	for _, entry := range report.Entries {
+		counts["kind/"+entry.Resource.Kind]++
+		if entry.Resource.Namespace != "" {
+			counts["namespace/"+entry.Resource.Namespace]++
+		}
+	}
+	return counts
+}
+
+// round keeps two decimals for readable output.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
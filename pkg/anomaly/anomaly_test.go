@@ -0,0 +1,88 @@
+package anomaly
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// changes builds a report with n modified resources of the given kind.
+func changes(kind, namespace string, n int) *types.DriftReport {
+	report := &types.DriftReport{}
+	for i := 0; i < n; i++ {
+		report.Entries = append(report.Entries, types.DriftEntry{
+			Type:     types.DriftModified,
+			Resource: types.Resource{Kind: kind, Namespace: namespace, Name: fmt.Sprintf("r%d", i)},
+		})
+	}
+	return report
+}
+
+func testConfig() *config.AnomalyConfig {
+	cfg := config.DefaultConfig().Watch.Anomaly
+	cfg.Enabled = true
+	return &cfg
+}
+
+func TestObserve_FlagsSpike(t *testing.T) {
+	d, err := Load(testConfig(), filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		assert.Empty(t, d.Observe(changes("Deployment", "web", i%3)))
+	}
+
+	anomalies := d.Observe(changes("Secret", "prod", 500))
+
+	require.NotEmpty(t, anomalies)
+	series := make(map[string]types.Anomaly)
+	for _, a := range anomalies {
+		series[a.Series] = a
+	}
+	assert.Contains(t, series, "total")
+	assert.Contains(t, series, "kind/Secret")
+	assert.Contains(t, series, "namespace/prod")
+	assert.Equal(t, 500, series["kind/Secret"].Changes)
+	assert.Greater(t, series["kind/Secret"].Score, 4.0)
+}
+
+func TestObserve_NeedsHistory(t *testing.T) {
+	d, err := Load(testConfig(), filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	assert.Empty(t, d.Observe(changes("Secret", "prod", 500)))
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "anomaly.json")
+	cfg := testConfig()
+
+	d, err := Load(cfg, path)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		d.Observe(changes("Deployment", "web", 1))
+	}
+	require.NoError(t, d.Save())
+
+	reloaded, err := Load(cfg, path)
+	require.NoError(t, err)
+	assert.NotEmpty(t, reloaded.Observe(changes("Deployment", "web", 100)))
+}
+
+func TestObserve_WindowIsBounded(t *testing.T) {
+	cfg := testConfig()
+	cfg.Window = 5
+	cfg.MinSamples = 2
+	d, err := Load(cfg, filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		d.Observe(changes("Deployment", "web", 1))
+	}
+	assert.Len(t, d.series["total"], 5)
+}
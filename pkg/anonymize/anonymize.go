@@ -0,0 +1,340 @@
+// Package anonymize rewrites snapshots with stable pseudonyms so they can be
+// shared outside the organization. Names, namespaces, hostnames, IP
+// addresses, image registries, and Secret values are replaced; the structure
+// of each resource, its kind, and non-identifying values are kept, and every
+// occurrence of the same value maps to the same pseudonym so references
+// between resources still line up.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Categories of pseudonyms; each gets its own prefix.
+const (
+	categoryNamespace = "ns"
+	categoryName      = "name"
+	categoryHost      = "host"
+	categoryCluster   = "cluster"
+	categorySecret    = "secret"
+	categoryUser      = "user"
+	categoryRepo      = "repo"
+	categoryRef       = "ref"
+	categoryCommit    = "commit"
+)
+
+// redactedError replaces collection errors, which quote users, groups,
+// and resource names from the API server's responses.
+const redactedError = "(redacted)"
+
+// pseudonymDomain replaces the real domain of anonymized hostnames.
+const pseudonymDomain = "example"
+
+var (
+	ipv4Pattern     = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	hostnamePattern = regexp.MustCompile(`\b(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}\b`)
+)
+
+// publicDomains are left alone: they name Kubernetes APIs, annotations, and
+// public registries rather than anything internal.
+var publicDomains = []string{
+	"k8s.io", "kubernetes.io", "x-k8s.io", "docker.io", "gcr.io", "ghcr.io",
+	"quay.io", "mcr.microsoft.com", "amazonaws.com", "pkg.dev", "example",
+}
+
+// fileExtensions look like top-level domains but end file names, e.g.
+// nginx.conf, which are not hostnames.
+var fileExtensions = map[string]bool{
+	"conf": true, "cfg": true, "crt": true, "css": true, "html": true, "ini": true,
+	"js": true, "json": true, "key": true, "log": true, "pem": true, "properties": true,
+	"py": true, "sh": true, "toml": true, "txt": true, "xml": true, "yaml": true, "yml": true,
+}
+
+// Anonymizer replaces identifying values with pseudonyms derived from an
+// HMAC of the value, so the same salt always yields the same pseudonyms.
+type Anonymizer struct {
+	salt []byte
+	// names maps the resource names and namespaces seen in the snapshot to
+	// their pseudonyms; string values equal to one of them are references
+	// and are replaced too.
+	names map[string]string
+}
+
+// New creates an Anonymizer. Snapshots anonymized with the same salt can be
+// correlated with each other; keep the salt private.
+func New(salt string) *Anonymizer {
+	return &Anonymizer{salt: []byte(salt)}
+}
+
+// Snapshot returns an anonymized copy of a snapshot.
+func (a *Anonymizer) Snapshot(snapshot *types.ResourceSnapshot) *types.ResourceSnapshot {
+	a.names = make(map[string]string)
+	for _, res := range snapshot.Resources {
+		if res.Namespace != "" {
+			a.names[res.Namespace] = a.pseudonym(categoryNamespace, res.Namespace)
+		}
+	}
+	for _, res := range snapshot.Resources {
+		if _, ok := a.names[res.Name]; !ok {
+			a.names[res.Name] = a.pseudonym(categoryName, res.Name)
+		}
+	}
+
+	out := &types.ResourceSnapshot{
+		APIVersion: snapshot.APIVersion,
+		Metadata:   a.metadata(snapshot.Metadata),
+	}
+	for _, res := range snapshot.Resources {
+		out.Resources = append(out.Resources, a.resource(res))
+	}
+	out.UpdateCounts()
+	return out
+}
+
+// metadata anonymizes snapshot metadata. Fields are copied one by one, so
+// that a new field is left out until it is handled here. The commit, its
+// URL, and the content hash describe the original repository and are
+// dropped; counts are recomputed from the anonymized resources.
+func (a *Anonymizer) metadata(m types.SnapshotMetadata) types.SnapshotMetadata {
+	out := types.SnapshotMetadata{
+		Timestamp:            m.Timestamp,
+		ClusterName:          a.pseudonym(categoryCluster, m.ClusterName),
+		Context:              a.pseudonym(categoryCluster, m.Context),
+		ExpiringCertificates: m.ExpiringCertificates,
+		RBACExposure:         m.RBACExposure,
+		PolicyViolations:     m.PolicyViolations,
+		Timings:              m.Timings,
+	}
+	for _, ns := range m.Namespaces {
+		out.Namespaces = append(out.Namespaces, a.pseudonym(categoryNamespace, ns))
+	}
+	if m.Scope != nil {
+		out.Scope = &types.SnapshotScope{Kinds: m.Scope.Kinds}
+		for _, ns := range m.Scope.Namespaces {
+			out.Scope.Namespaces = append(out.Scope.Namespaces, a.pseudonym(categoryNamespace, ns))
+		}
+	}
+	for _, release := range m.HelmReleases {
+		release.Name = a.pseudonym(categoryName, release.Name)
+		release.Namespace = a.pseudonym(categoryNamespace, release.Namespace)
+		release.ValuesHash = a.pseudonym(categorySecret, release.ValuesHash)
+		out.HelmReleases = append(out.HelmReleases, release)
+	}
+	for _, stats := range m.Collection {
+		stats.Resource = a.text(stats.Resource)
+		if stats.Error != "" {
+			stats.Error = redactedError
+		}
+		out.Collection = append(out.Collection, stats)
+	}
+	for _, cluster := range m.Clusters {
+		cluster.Name = a.pseudonym(categoryCluster, cluster.Name)
+		cluster.Context = a.pseudonym(categoryCluster, cluster.Context)
+		if cluster.Error != "" {
+			cluster.Error = redactedError
+		}
+		out.Clusters = append(out.Clusters, cluster)
+	}
+	if m.CI != nil {
+		out.CI = &types.CIMetadata{
+			Provider:   m.CI.Provider,
+			PipelineID: a.pseudonym(categoryRef, m.CI.PipelineID),
+			Repository: a.pseudonym(categoryRepo, m.CI.Repository),
+			CommitSHA:  a.pseudonym(categoryCommit, m.CI.CommitSHA),
+			Ref:        a.pseudonym(categoryRef, m.CI.Ref),
+			Actor:      a.pseudonym(categoryUser, m.CI.Actor),
+		}
+	}
+	return out
+}
+
+// resource anonymizes one resource.
+func (a *Anonymizer) resource(res types.Resource) types.Resource {
+	out := types.Resource{
+		APIVersion:        res.APIVersion,
+		Kind:              res.Kind,
+		Namespace:         a.names[res.Namespace],
+		Name:              a.names[res.Name],
+		Labels:            a.stringMap(res.Labels),
+		Annotations:       a.stringMap(res.Annotations),
+		UID:               res.UID,
+		Managers:          res.Managers,
+		CreationTimestamp: res.CreationTimestamp,
+	}
+
+	secret := res.Kind == "Secret"
+	if res.Raw != nil {
+		raw := a.value(res.Raw, "").(map[string]interface{})
+		if secret {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := res.Raw[field].(map[string]interface{}); ok {
+					raw[field] = a.secretData(data, field == "data")
+				}
+			}
+		}
+		out.Raw = raw
+		out.Spec, _ = raw["spec"].(map[string]interface{})
+		out.Data, _ = raw["data"].(map[string]interface{})
+		return out
+	}
+
+	if res.Spec != nil {
+		out.Spec = a.value(res.Spec, "").(map[string]interface{})
+	}
+	if res.Data != nil {
+		if secret {
+			out.Data = a.secretData(res.Data, true)
+		} else {
+			out.Data = a.value(res.Data, "").(map[string]interface{})
+		}
+	}
+	return out
+}
+
+// value anonymizes a decoded YAML value. key is the map key it was found
+// under, which selects special handling for images and API identifiers.
+func (a *Anonymizer) value(v interface{}, key string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			out[k] = a.value(child, k)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, child := range val {
+			out[i] = a.value(child, key)
+		}
+		return out
+	case string:
+		switch key {
+		case "apiVersion", "kind", "uid", "resourceVersion", "creationTimestamp":
+			return val
+		case "image":
+			return a.image(val)
+		}
+		return a.text(val)
+	default:
+		return v
+	}
+}
+
+// text anonymizes a free-form string value.
+func (a *Anonymizer) text(s string) string {
+	if pseudonym, ok := a.names[s]; ok {
+		return pseudonym
+	}
+	if ip, ipNet, err := net.ParseCIDR(s); err == nil {
+		ones, _ := ipNet.Mask.Size()
+		return fmt.Sprintf("%s/%d", a.ip(ip), ones)
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		return a.ip(ip)
+	}
+
+	s = ipv4Pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if ip := net.ParseIP(m); ip != nil {
+			return a.ip(ip)
+		}
+		return m
+	})
+	return hostnamePattern.ReplaceAllStringFunc(s, a.host)
+}
+
+// image replaces the registry of a container image reference, keeping the
+// repository path and tag so the workload stays recognizable.
+func (a *Anonymizer) image(ref string) string {
+	registry, rest, ok := strings.Cut(ref, "/")
+	if !ok || !(strings.ContainsAny(registry, ".:") || registry == "localhost") {
+		// Docker Hub short form, e.g. nginx:1.25 or library/nginx
+		return ref
+	}
+	return a.host(registry) + "/" + rest
+}
+
+// host replaces a hostname (optionally with a port) unless it is public.
+func (a *Anonymizer) host(h string) string {
+	name, port, hasPort := strings.Cut(h, ":")
+	lower := strings.ToLower(name)
+	if fileExtensions[lower[strings.LastIndex(lower, ".")+1:]] {
+		return h
+	}
+	for _, domain := range publicDomains {
+		if lower == domain || strings.HasSuffix(lower, "."+domain) {
+			return h
+		}
+	}
+	pseudonym := a.pseudonym(categoryHost, lower) + "." + pseudonymDomain
+	if hasPort {
+		pseudonym += ":" + port
+	}
+	return pseudonym
+}
+
+// ip maps an address into a private range: 10.0.0.0/8 for IPv4 and
+// fd00::/8 for IPv6. Loopback and unspecified addresses are kept.
+func (a *Anonymizer) ip(ip net.IP) string {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return ip.String()
+	}
+	sum := a.digest(categoryHost, ip.String())
+	if ip.To4() != nil {
+		return net.IPv4(10, sum[0], sum[1], sum[2]).String()
+	}
+	mapped := make(net.IP, net.IPv6len)
+	mapped[0] = 0xfd
+	copy(mapped[1:], sum[:net.IPv6len-1])
+	return mapped.String()
+}
+
+// secretData replaces every Secret value, keeping the keys. Values under
+// .data are base64-encoded, as the API server expects.
+func (a *Anonymizer) secretData(data map[string]interface{}, encoded bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		pseudonym := a.pseudonym(categorySecret, fmt.Sprint(v))
+		if encoded {
+			pseudonym = base64.StdEncoding.EncodeToString([]byte(pseudonym))
+		}
+		out[k] = pseudonym
+	}
+	return out
+}
+
+// stringMap anonymizes label or annotation values; keys are kept.
+func (a *Anonymizer) stringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = a.text(v)
+	}
+	return out
+}
+
+// pseudonym returns category-<hash> for a value, or "" for an empty value.
+func (a *Anonymizer) pseudonym(category, value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := a.digest(category, value)
+	return category + "-" + hex.EncodeToString(sum[:4])
+}
+
+// digest is the keyed hash of a value within a category.
+func (a *Anonymizer) digest(category, value string) []byte {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(category + ":" + value))
+	return mac.Sum(nil)
+}
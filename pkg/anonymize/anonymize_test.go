@@ -0,0 +1,179 @@
+package anonymize
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSnapshot() *types.ResourceSnapshot {
+	return &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{ClusterName: "prod-eu", Context: "prod-eu-admin", Namespaces: []string{"payments"}},
+		Resources: []types.Resource{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "payments",
+				Name:       "ledger",
+				Labels:     map[string]string{"app": "ledger"},
+				Raw: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata":   map[string]interface{}{"name": "ledger", "namespace": "payments"},
+					"spec": map[string]interface{}{
+						"replicas": 3,
+						"template": map[string]interface{}{"spec": map[string]interface{}{
+							"containers": []interface{}{map[string]interface{}{
+								"image": "registry.corp.internal:5000/payments/ledger:1.4.2",
+								"env": []interface{}{
+									map[string]interface{}{"name": "DB_URL", "value": "postgres://db.corp.internal:5432/ledger"},
+									map[string]interface{}{"name": "PEER", "value": "10.20.30.40"},
+								},
+								"envFrom": []interface{}{map[string]interface{}{
+									"secretRef": map[string]interface{}{"name": "ledger-creds"},
+								}},
+							}},
+						}},
+					},
+				},
+			},
+			{
+				APIVersion: "v1",
+				Kind:       "Secret",
+				Namespace:  "payments",
+				Name:       "ledger-creds",
+				Raw: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Secret",
+					"metadata":   map[string]interface{}{"name": "ledger-creds", "namespace": "payments"},
+					"type":       "kubernetes.io/basic-auth",
+					"data":       map[string]interface{}{"password": base64.StdEncoding.EncodeToString([]byte("hunter2"))},
+				},
+			},
+		},
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	out := New("salt").Snapshot(testSnapshot())
+	require.Len(t, out.Resources, 2)
+
+	dep, secret := out.Resources[0], out.Resources[1]
+	assert.True(t, strings.HasPrefix(dep.Namespace, "ns-"))
+	assert.True(t, strings.HasPrefix(dep.Name, "name-"))
+	assert.Equal(t, dep.Name, dep.Labels["app"], "label values referencing names stay consistent")
+	assert.Equal(t, []string{dep.Namespace}, out.Metadata.Namespaces)
+	assert.NotContains(t, out.Metadata.ClusterName, "prod")
+
+	meta := dep.Raw["metadata"].(map[string]interface{})
+	assert.Equal(t, dep.Name, meta["name"])
+	assert.Equal(t, 3, dep.Spec["replicas"])
+
+	container := dep.Spec["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+	image := container["image"].(string)
+	assert.True(t, strings.HasPrefix(image, "host-"), image)
+	assert.True(t, strings.HasSuffix(image, ".example:5000/payments/ledger:1.4.2"), image)
+
+	env := container["env"].([]interface{})
+	dbURL := env[0].(map[string]interface{})["value"].(string)
+	assert.NotContains(t, dbURL, "corp.internal")
+	assert.True(t, strings.HasPrefix(dbURL, "postgres://host-"), dbURL)
+	peer := env[1].(map[string]interface{})["value"].(string)
+	assert.True(t, strings.HasPrefix(peer, "10."), peer)
+	assert.NotEqual(t, "10.20.30.40", peer)
+
+	ref := container["envFrom"].([]interface{})[0].(map[string]interface{})["secretRef"].(map[string]interface{})
+	assert.Equal(t, secret.Name, ref["name"], "references follow the renamed Secret")
+
+	assert.Equal(t, "kubernetes.io/basic-auth", secret.Raw["type"])
+	password, err := base64.StdEncoding.DecodeString(secret.Data["password"].(string))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(password), "secret-"))
+}
+
+func TestSnapshot_Metadata(t *testing.T) {
+	snapshot := testSnapshot()
+	snapshot.Metadata.CommitHash = "0123456789abcdef"
+	snapshot.Metadata.CommitURL = "https://git.corp.internal/platform/snapshots/commit/0123456789abcdef"
+	snapshot.Metadata.ContentHash = "feedface"
+	snapshot.Metadata.Scope = &types.SnapshotScope{Namespaces: []string{"payments"}, Kinds: []string{"Deployment"}}
+	snapshot.Metadata.HelmReleases = []types.HelmRelease{{Name: "ledger", Namespace: "payments", Revision: 4, Chart: "postgresql", ValuesHash: "abcdef012345"}}
+	snapshot.Metadata.Collection = []types.CollectionStats{{Resource: "deployments", Count: 1, Pages: 1, Error: `forbidden: User "alice@corp.internal" cannot list`}}
+	snapshot.Metadata.Clusters = []types.ClusterStatus{{Name: "prod-eu", Context: "prod-eu-admin", ResourceCount: 2, Duration: time.Second}}
+	snapshot.Metadata.CI = &types.CIMetadata{
+		Provider:    "github-actions",
+		PipelineID:  "8812",
+		PipelineURL: "https://github.com/corp/ledger/actions/runs/8812",
+		Repository:  "corp/ledger",
+		CommitSHA:   "a1b2c3d4",
+		Ref:         "refs/heads/release-payments",
+		Actor:       "alice",
+	}
+
+	out := New("salt").Snapshot(snapshot)
+	data, err := json.Marshal(out.Metadata)
+	require.NoError(t, err)
+	for _, leak := range []string{"payments", "ledger", "prod-eu", "alice", "corp", "0123456789abcdef", "feedface", "abcdef012345", "a1b2c3d4", "8812"} {
+		assert.NotContains(t, string(data), leak)
+	}
+
+	meta := out.Metadata
+	assert.Equal(t, []string{out.Resources[0].Namespace}, meta.Scope.Namespaces)
+	assert.Equal(t, []string{"Deployment"}, meta.Scope.Kinds)
+	require.Len(t, meta.HelmReleases, 1)
+	assert.Equal(t, out.Resources[0].Name, meta.HelmReleases[0].Name, "releases keep the pseudonyms of their resources")
+	assert.Equal(t, 4, meta.HelmReleases[0].Revision)
+	assert.Equal(t, "postgresql", meta.HelmReleases[0].Chart, "charts are kept, like image repositories")
+	assert.Equal(t, redactedError, meta.Collection[0].Error)
+	assert.Equal(t, 1, meta.Collection[0].Count)
+	assert.Equal(t, meta.ClusterName, meta.Clusters[0].Name)
+	assert.Equal(t, "github-actions", meta.CI.Provider)
+	assert.Empty(t, meta.CI.PipelineURL)
+}
+
+// anonymizedMetadata lists the SnapshotMetadata fields Anonymizer.metadata
+// handles. A field added to SnapshotMetadata fails the test below until
+// the anonymizer decides whether to copy, pseudonymize, or drop it.
+var anonymizedMetadata = []string{
+	"Timestamp", "ClusterName", "Context", "ResourceCount", "Namespaces", "CommitHash",
+	"CommitURL", "KindCounts", "NamespaceCounts", "ExpiringCertificates", "RBACExposure",
+	"PolicyViolations", "Scope", "HelmReleases", "Timings", "Collection", "Clusters",
+	"ContentHash", "CI",
+}
+
+func TestSnapshot_MetadataFieldsHandled(t *testing.T) {
+	var fields []string
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(types.SnapshotMetadata{})) {
+		fields = append(fields, f.Name)
+	}
+	assert.ElementsMatch(t, anonymizedMetadata, fields, "handle new SnapshotMetadata fields in Anonymizer.metadata")
+}
+
+func TestSnapshot_StableForSalt(t *testing.T) {
+	a := New("salt").Snapshot(testSnapshot())
+	b := New("salt").Snapshot(testSnapshot())
+	c := New("other").Snapshot(testSnapshot())
+
+	assert.Equal(t, a.Resources[0].Name, b.Resources[0].Name)
+	assert.NotEqual(t, a.Resources[0].Name, c.Resources[0].Name)
+}
+
+func TestText(t *testing.T) {
+	a := New("salt")
+	a.names = map[string]string{}
+
+	assert.Equal(t, "nginx.conf", a.text("nginx.conf"))
+	assert.Equal(t, "app.kubernetes.io", a.text("app.kubernetes.io"))
+	assert.Equal(t, "127.0.0.1", a.text("127.0.0.1"))
+	assert.Regexp(t, `^10\.\d+\.\d+\.\d+/16$`, a.text("172.16.0.0/16"))
+	assert.Regexp(t, `^fd`, a.text("2001:db8::1"))
+	assert.Equal(t, "nginx:1.25", a.image("nginx:1.25"))
+	assert.Equal(t, "library/nginx:1.25", a.image("library/nginx:1.25"))
+}
@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAppendsJSONLines(t *testing.T) {
+	cfg := &config.AuditConfig{File: filepath.Join(t.TempDir(), "audit.log")}
+
+	require.NoError(t, Record(cfg, Event{Action: "restore", Actor: "web-ui", Source: SourceAPI, Details: map[string]bool{"dryRun": true}}))
+	require.NoError(t, Record(cfg, Event{Action: "restore", Actor: "alice", Source: SourceCLI, Error: "boom"}))
+
+	f, err := os.Open(cfg.File)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		events = append(events, e)
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, "web-ui", events[0].Actor)
+	assert.False(t, events[0].Time.IsZero())
+	assert.Equal(t, map[string]interface{}{"dryRun": true}, events[0].Details)
+	assert.Equal(t, "boom", events[1].Error)
+
+	info, err := os.Stat(cfg.File)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRecordWithoutFile(t *testing.T) {
+	assert.NoError(t, Record(&config.AuditConfig{}, Event{Action: "restore"}))
+}
@@ -0,0 +1,200 @@
+// Package chatops answers Slack slash commands (e.g. "/timemachine drift
+// prod") from the snapshot history, so on-call engineers can query it from
+// an incident channel.
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Backend reads the snapshot history the commands report on.
+type Backend interface {
+	// Drift compares the snapshot as of since ago with the latest,
+	// limited to a namespace.
+	Drift(ctx context.Context, namespace string, since time.Duration) (*types.DriftReport, error)
+	// Resource returns a resource in the latest snapshot, or the snapshot
+	// at a time if at is non-zero, and the commit it was read from.
+	Resource(ctx context.Context, namespace, kind, name string, at time.Time) (*types.Resource, string, error)
+	// History returns the latest snapshots, newest first.
+	History(ctx context.Context, limit int) ([]types.HistoryEntry, error)
+}
+
+// Limits that keep replies readable and within Slack's message size.
+const (
+	defaultSince   = 24 * time.Hour
+	defaultHistory = 10
+	maxHistory     = 50
+	maxDriftLines  = 20
+	maxYAMLBytes   = 2500
+)
+
+// usage is the reply to "help" and to unknown commands.
+const usage = "Usage:\n" +
+	"• `drift <namespace> [since]` — changes in a namespace, e.g. `drift prod 6h` (default 24h)\n" +
+	"• `get <namespace> <kind> <name> [at]` — a resource in the latest snapshot, or at an RFC3339 time; use `_cluster` for cluster-scoped resources\n" +
+	"• `history [n]` — the latest snapshots (default 10)"
+
+// Run executes a command line and returns the reply in Slack mrkdwn.
+// Errors in the command line are replied to, not returned.
+func Run(ctx context.Context, backend Backend, text string) (string, error) {
+	args := strings.Fields(text)
+	if len(args) == 0 {
+		return usage, nil
+	}
+	switch args[0] {
+	case "drift":
+		return drift(ctx, backend, args[1:])
+	case "get":
+		return get(ctx, backend, args[1:])
+	case "history":
+		return history(ctx, backend, args[1:])
+	case "help":
+		return usage, nil
+	default:
+		return fmt.Sprintf("Unknown command `%s`.\n%s", args[0], usage), nil
+	}
+}
+
+func drift(ctx context.Context, backend Backend, args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "Usage: `drift <namespace> [since]`", nil
+	}
+	namespace := args[0]
+	since, sinceText := defaultSince, "24h"
+	if len(args) == 2 {
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return fmt.Sprintf("Invalid duration `%s`; use e.g. `6h` or `30m`.", args[1]), nil
+		}
+		since, sinceText = d, args[1]
+	}
+
+	report, err := backend.Drift(ctx, namespace, since)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Drift in %s over the last %s* (%s → %s)\n", namespace, sinceText, ref(report.BaseRef, report.BaseURL), ref(report.TargetRef, report.TargetURL))
+	if len(report.Entries) == 0 {
+		b.WriteString("No changes.")
+		return b.String(), nil
+	}
+	if report.SuppressedBy != "" {
+		fmt.Fprintf(&b, "_During maintenance window %s._\n", report.SuppressedBy)
+	}
+	for i, entry := range report.Entries {
+		if i == maxDriftLines {
+			fmt.Fprintf(&b, "…and %d more.", len(report.Entries)-maxDriftLines)
+			break
+		}
+		name := entry.Resource.Kind + "/" + entry.Resource.Name
+		if entry.URL != "" {
+			name = "<" + entry.URL + "|" + name + ">"
+		}
+		fmt.Fprintf(&b, "• `%s` %s", entry.Type, name)
+		if entry.Severity != "" {
+			fmt.Fprintf(&b, " [%s]", entry.Severity)
+		}
+		if n := len(entry.FieldDiffs); n > 0 {
+			fmt.Fprintf(&b, " (%d field(s))", n)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func get(ctx context.Context, backend Backend, args []string) (string, error) {
+	if len(args) < 3 || len(args) > 4 {
+		return "Usage: `get <namespace> <kind> <name> [at]`", nil
+	}
+	namespace, kind, name := args[0], args[1], args[2]
+	if namespace == types.ClusterScope {
+		namespace = ""
+	}
+	var at time.Time
+	if len(args) == 4 {
+		t, err := time.Parse(time.RFC3339, args[3])
+		if err != nil {
+			return fmt.Sprintf("Invalid time `%s`; use RFC3339, e.g. `2024-01-15T10:00:00Z`.", args[3]), nil
+		}
+		at = t
+	}
+
+	res, commit, err := backend.Resource(ctx, namespace, kind, name, at)
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return fmt.Sprintf("%s %s/%s is not in snapshot %s.", kind, args[0], name, short(commit)), nil
+	}
+
+	// Replies are posted to the channel, so secret values are masked
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(notifier.MaskResource(*res)); err != nil {
+		return "", fmt.Errorf("failed to encode resource: %w", err)
+	}
+	text := buf.String()
+	if len(text) > maxYAMLBytes {
+		text = text[:maxYAMLBytes] + "\n# …truncated"
+	}
+	return fmt.Sprintf("*%s %s/%s* at %s\n```\n%s\n```", res.Kind, args[0], name, short(commit), strings.TrimSuffix(text, "\n")), nil
+}
+
+func history(ctx context.Context, backend Backend, args []string) (string, error) {
+	limit := defaultHistory
+	if len(args) > 1 {
+		return "Usage: `history [n]`", nil
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Sprintf("Invalid count `%s`.", args[0]), nil
+		}
+		limit = min(n, maxHistory)
+	}
+
+	entries, err := backend.History(ctx, limit)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "No snapshots yet.", nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+
+	var b strings.Builder
+	b.WriteString("*Latest snapshots*\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "• `%s` %s — %d resources\n", short(e.CommitHash), e.Timestamp.UTC().Format(time.RFC3339), e.ResourceCount)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// ref formats a commit, linked if a URL is known.
+func ref(commit, url string) string {
+	if url != "" {
+		return "<" + url + "|" + short(commit) + ">"
+	}
+	return "`" + short(commit) + "`"
+}
+
+// short abbreviates a commit hash.
+func short(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
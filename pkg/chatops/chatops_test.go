@@ -0,0 +1,176 @@
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	delay     time.Duration
+	namespace string
+	since     time.Duration
+}
+
+func (f *fakeBackend) Drift(ctx context.Context, namespace string, since time.Duration) (*types.DriftReport, error) {
+	time.Sleep(f.delay)
+	f.namespace, f.since = namespace, since
+	return &types.DriftReport{
+		BaseRef:   "1111111111111111",
+		TargetRef: "2222222222222222",
+		TargetURL: "https://github.com/acme/snaps/commit/2222222222222222",
+		Entries: []types.DriftEntry{
+			{Type: types.DriftModified, Severity: "high", Resource: types.Resource{Kind: "Deployment", Name: "api"},
+				FieldDiffs: []types.FieldDiff{{Path: "spec.replicas"}}, URL: "https://example.com/api.yaml"},
+			{Type: types.DriftAdded, Resource: types.Resource{Kind: "ConfigMap", Name: "flags"}},
+		},
+	}, nil
+}
+
+func (f *fakeBackend) Resource(ctx context.Context, namespace, kind, name string, at time.Time) (*types.Resource, string, error) {
+	if kind == "Secret" {
+		return &types.Resource{Kind: kind, Namespace: namespace, Name: name, Raw: map[string]interface{}{
+			"kind": kind, "data": map[string]interface{}{"password": "aHVudGVyMg=="},
+		}}, "3333333333333333", nil
+	}
+	if name != "api" {
+		return nil, "3333333333333333", nil
+	}
+	return &types.Resource{Kind: kind, Namespace: namespace, Name: name, Raw: map[string]interface{}{
+		"kind": kind, "spec": map[string]interface{}{"replicas": 3},
+	}}, "3333333333333333", nil
+}
+
+func (f *fakeBackend) History(ctx context.Context, limit int) ([]types.HistoryEntry, error) {
+	return []types.HistoryEntry{
+		{CommitHash: "aaaaaaaaaaaa", Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ResourceCount: 5},
+		{CommitHash: "bbbbbbbbbbbb", Timestamp: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ResourceCount: 6},
+	}[:min(limit, 2)], nil
+}
+
+func TestRunDrift(t *testing.T) {
+	backend := &fakeBackend{}
+	reply, err := Run(context.Background(), backend, "drift prod 6h")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", backend.namespace)
+	assert.Equal(t, 6*time.Hour, backend.since)
+	assert.Contains(t, reply, "*Drift in prod over the last 6h* (`11111111` → <https://github.com/acme/snaps/commit/2222222222222222|22222222>)")
+	assert.Contains(t, reply, "• `MODIFIED` <https://example.com/api.yaml|Deployment/api> [high] (1 field(s))")
+	assert.Contains(t, reply, "• `ADDED` ConfigMap/flags")
+
+	_, err = Run(context.Background(), backend, "drift prod")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, backend.since)
+
+	reply, err = Run(context.Background(), backend, "drift prod yesterday")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Invalid duration")
+}
+
+func TestRunGetAndHistory(t *testing.T) {
+	backend := &fakeBackend{}
+	reply, err := Run(context.Background(), backend, "get prod Deployment api")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "*Deployment prod/api* at 33333333\n```\n")
+	assert.Contains(t, reply, "replicas: 3")
+
+	reply, err = Run(context.Background(), backend, "get prod Secret db")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "password: '[REDACTED]'")
+	assert.NotContains(t, reply, "aHVudGVyMg==")
+
+	reply, err = Run(context.Background(), backend, "get prod Deployment missing")
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment prod/missing is not in snapshot 33333333.", reply)
+
+	reply, err = Run(context.Background(), backend, "get prod Deployment api now")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Invalid time")
+
+	reply, err = Run(context.Background(), backend, "history 5")
+	require.NoError(t, err)
+	// Newest first
+	assert.Less(t, strings.Index(reply, "bbbbbbbb"), strings.Index(reply, "aaaaaaaa"))
+
+	reply, err = Run(context.Background(), backend, "")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Usage:")
+	reply, err = Run(context.Background(), backend, "rollback prod")
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Unknown command `rollback`")
+}
+
+// slashCommand builds a signed slash-command request.
+func slashCommand(t *testing.T, secret, text, responseURL string, ts time.Time) *http.Request {
+	t.Helper()
+	body := url.Values{"text": {text}, "user_name": {"oncall"}, "response_url": {responseURL}}.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(body))
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", stamp, body)
+	req.Header.Set("X-Slack-Request-Timestamp", stamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestHandlerVerifiesSignature(t *testing.T) {
+	h := NewHandler("s3cret", &fakeBackend{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashCommand(t, "s3cret", "history", "", time.Now()))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var reply slackReply
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reply))
+	assert.Equal(t, "in_channel", reply.ResponseType)
+	assert.Contains(t, reply.Text, "Latest snapshots")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, slashCommand(t, "wrong", "history", "", time.Now()))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, slashCommand(t, "s3cret", "history", "", time.Now().Add(-time.Hour)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "stale requests are rejected")
+}
+
+func TestHandlerRepliesLaterToSlowCommands(t *testing.T) {
+	posted := make(chan slackReply, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply slackReply
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reply))
+		posted <- reply
+	}))
+	defer srv.Close()
+
+	h := NewHandler("s3cret", &fakeBackend{delay: 50 * time.Millisecond})
+	h.inline = time.Millisecond
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, slashCommand(t, "s3cret", "drift prod", srv.URL, time.Now()))
+	var ack slackReply
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &ack))
+	assert.Equal(t, "ephemeral", ack.ResponseType)
+
+	select {
+	case reply := <-posted:
+		assert.Equal(t, "in_channel", reply.ResponseType)
+		assert.Contains(t, reply.Text, "Drift in prod")
+	case <-time.After(5 * time.Second):
+		t.Fatal("reply was not posted to response_url")
+	}
+}
@@ -0,0 +1,156 @@
+package chatops
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Slack allows three seconds to answer a slash command; replies that take
+// longer are acknowledged and then posted to the command's response_url.
+const (
+	inlineTimeout  = 2500 * time.Millisecond
+	commandTimeout = time.Minute
+	// maxClockSkew rejects replayed requests, as Slack recommends.
+	maxClockSkew = 5 * time.Minute
+	maxBody      = 1 << 16
+)
+
+// Handler serves Slack slash commands.
+type Handler struct {
+	secret  []byte
+	backend Backend
+	client  *http.Client
+	now     func() time.Time
+	// inline is how long a reply may take to be sent as the response.
+	inline time.Duration
+}
+
+// NewHandler creates a slash-command handler that verifies requests with
+// the Slack app's signing secret.
+func NewHandler(signingSecret string, backend Backend) *Handler {
+	return &Handler{secret: []byte(signingSecret), backend: backend, client: http.DefaultClient, now: time.Now, inline: inlineTimeout}
+}
+
+// slackReply is a slash-command response message.
+type slackReply struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header, body); err != nil {
+		log.WithError(err).Warn("rejected slash command")
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	text := form.Get("text")
+	log.WithFields(log.Fields{
+		"user":    form.Get("user_name"),
+		"channel": form.Get("channel_name"),
+		"command": text,
+	}).Info("slash command received")
+
+	// The command outlives the request if it has to be answered later
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), commandTimeout)
+	done := make(chan slackReply, 1)
+	go func() {
+		defer cancel()
+		done <- h.run(ctx, text)
+	}()
+
+	select {
+	case reply := <-done:
+		writeReply(w, reply)
+	case <-time.After(h.inline):
+		responseURL := form.Get("response_url")
+		writeReply(w, slackReply{ResponseType: "ephemeral", Text: "Looking that up…"})
+		go func() {
+			if err := h.post(responseURL, <-done); err != nil {
+				log.WithError(err).Warn("failed to post slash command reply")
+			}
+		}()
+	}
+}
+
+// run executes a command, turning failures into an ephemeral reply.
+func (h *Handler) run(ctx context.Context, text string) slackReply {
+	reply, err := Run(ctx, h.backend, text)
+	if err != nil {
+		log.WithError(err).WithField("command", text).Warn("slash command failed")
+		return slackReply{ResponseType: "ephemeral", Text: "Sorry, that failed: " + err.Error()}
+	}
+	return slackReply{ResponseType: "in_channel", Text: reply}
+}
+
+// verify checks the request's signature and timestamp; see
+// https://api.slack.com/authentication/verifying-requests-from-slack.
+func (h *Handler) verify(header http.Header, body []byte) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid timestamp")
+	}
+	if skew := h.now().Sub(time.Unix(sec, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("timestamp is %s off", skew.Round(time.Second))
+	}
+
+	mac := hmac.New(sha256.New, h.secret)
+	fmt.Fprintf(mac, "v0:%s:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// post sends a delayed reply to a command's response_url.
+func (h *Handler) post(responseURL string, reply slackReply) error {
+	if responseURL == "" {
+		return fmt.Errorf("no response_url to reply to")
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		return fmt.Errorf("failed to encode reply: %w", err)
+	}
+	resp, err := h.client.Post(responseURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to post reply: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to post reply: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// writeReply writes a reply as the command's immediate response.
+func writeReply(w http.ResponseWriter, reply slackReply) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reply); err != nil {
+		log.WithError(err).Warn("failed to write slash command reply")
+	}
+}
@@ -0,0 +1,164 @@
+// Package ci detects the CI pipeline a snapshot is taken from, so that
+// infrastructure snapshots can be traced to the application deploys that
+// caused them.
+package ci
+
+import (
+	"os"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// provider reads the metadata of one CI system from its well-known
+// environment variables.
+type provider struct {
+	name string
+	// detect reports whether the process runs in this CI system.
+	detect func(env func(string) string) bool
+	read   func(env func(string) string) types.CIMetadata
+}
+
+// providers are checked in order; the first one detected wins.
+var providers = []provider{
+	{
+		name:   "github-actions",
+		detect: func(env func(string) string) bool { return env("GITHUB_ACTIONS") == "true" },
+		read: func(env func(string) string) types.CIMetadata {
+			m := types.CIMetadata{
+				PipelineID: env("GITHUB_RUN_ID"),
+				Repository: env("GITHUB_REPOSITORY"),
+				CommitSHA:  env("GITHUB_SHA"),
+				Ref:        env("GITHUB_REF_NAME"),
+				Actor:      env("GITHUB_ACTOR"),
+			}
+			if server := env("GITHUB_SERVER_URL"); server != "" && m.Repository != "" && m.PipelineID != "" {
+				m.PipelineURL = strings.TrimSuffix(server, "/") + "/" + m.Repository + "/actions/runs/" + m.PipelineID
+			}
+			return m
+		},
+	},
+	{
+		name:   "gitlab",
+		detect: func(env func(string) string) bool { return env("GITLAB_CI") == "true" },
+		read: func(env func(string) string) types.CIMetadata {
+			return types.CIMetadata{
+				PipelineID:  env("CI_PIPELINE_ID"),
+				PipelineURL: env("CI_PIPELINE_URL"),
+				Repository:  env("CI_PROJECT_PATH"),
+				CommitSHA:   env("CI_COMMIT_SHA"),
+				Ref:         env("CI_COMMIT_REF_NAME"),
+				Actor:       env("GITLAB_USER_LOGIN"),
+			}
+		},
+	},
+	{
+		name:   "circleci",
+		detect: func(env func(string) string) bool { return env("CIRCLECI") == "true" },
+		read: func(env func(string) string) types.CIMetadata {
+			m := types.CIMetadata{
+				PipelineID:  env("CIRCLE_WORKFLOW_ID"),
+				PipelineURL: env("CIRCLE_BUILD_URL"),
+				CommitSHA:   env("CIRCLE_SHA1"),
+				Ref:         firstOf(env("CIRCLE_BRANCH"), env("CIRCLE_TAG")),
+				Actor:       env("CIRCLE_USERNAME"),
+			}
+			if owner, repo := env("CIRCLE_PROJECT_USERNAME"), env("CIRCLE_PROJECT_REPONAME"); owner != "" && repo != "" {
+				m.Repository = owner + "/" + repo
+			}
+			return m
+		},
+	},
+	{
+		name:   "azure-pipelines",
+		detect: func(env func(string) string) bool { return strings.EqualFold(env("TF_BUILD"), "true") },
+		read: func(env func(string) string) types.CIMetadata {
+			m := types.CIMetadata{
+				PipelineID: env("BUILD_BUILDID"),
+				Repository: env("BUILD_REPOSITORY_NAME"),
+				CommitSHA:  env("BUILD_SOURCEVERSION"),
+				Ref:        env("BUILD_SOURCEBRANCHNAME"),
+				Actor:      env("BUILD_REQUESTEDFOR"),
+			}
+			if collection, project := env("SYSTEM_COLLECTIONURI"), env("SYSTEM_TEAMPROJECT"); collection != "" && project != "" && m.PipelineID != "" {
+				m.PipelineURL = strings.TrimSuffix(collection, "/") + "/" + project + "/_build/results?buildId=" + m.PipelineID
+			}
+			return m
+		},
+	},
+	{
+		name:   "buildkite",
+		detect: func(env func(string) string) bool { return env("BUILDKITE") == "true" },
+		read: func(env func(string) string) types.CIMetadata {
+			return types.CIMetadata{
+				PipelineID:  env("BUILDKITE_BUILD_ID"),
+				PipelineURL: env("BUILDKITE_BUILD_URL"),
+				Repository:  env("BUILDKITE_REPO"),
+				CommitSHA:   env("BUILDKITE_COMMIT"),
+				Ref:         firstOf(env("BUILDKITE_BRANCH"), env("BUILDKITE_TAG")),
+				Actor:       firstOf(env("BUILDKITE_BUILD_CREATOR_EMAIL"), env("BUILDKITE_BUILD_CREATOR")),
+			}
+		},
+	},
+	{
+		name:   "bitbucket",
+		detect: func(env func(string) string) bool { return env("BITBUCKET_BUILD_NUMBER") != "" },
+		read: func(env func(string) string) types.CIMetadata {
+			m := types.CIMetadata{
+				PipelineID: env("BITBUCKET_BUILD_NUMBER"),
+				Repository: env("BITBUCKET_REPO_FULL_NAME"),
+				CommitSHA:  env("BITBUCKET_COMMIT"),
+				Ref:        firstOf(env("BITBUCKET_BRANCH"), env("BITBUCKET_TAG")),
+				Actor:      env("BITBUCKET_STEP_TRIGGERER_UUID"),
+			}
+			if m.Repository != "" && m.PipelineID != "" {
+				m.PipelineURL = "https://bitbucket.org/" + m.Repository + "/pipelines/results/" + m.PipelineID
+			}
+			return m
+		},
+	},
+	{
+		// Last, since other systems may run on Jenkins agents
+		name:   "jenkins",
+		detect: func(env func(string) string) bool { return env("JENKINS_URL") != "" },
+		read: func(env func(string) string) types.CIMetadata {
+			return types.CIMetadata{
+				PipelineID:  firstOf(env("BUILD_TAG"), env("BUILD_ID")),
+				PipelineURL: env("BUILD_URL"),
+				Repository:  env("GIT_URL"),
+				CommitSHA:   env("GIT_COMMIT"),
+				Ref:         env("GIT_BRANCH"),
+				// Set by the build-user-vars plugin, if installed
+				Actor: env("BUILD_USER_ID"),
+			}
+		},
+	},
+}
+
+// Detect returns the metadata of the CI pipeline run the process runs in,
+// or nil outside CI.
+func Detect() *types.CIMetadata {
+	return detect(os.Getenv)
+}
+
+// detect is Detect with the environment looked up through env.
+func detect(env func(string) string) *types.CIMetadata {
+	for _, p := range providers {
+		if p.detect(env) {
+			m := p.read(env)
+			m.Provider = p.name
+			return &m
+		}
+	}
+	return nil
+}
+
+// firstOf returns the first non-empty value.
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
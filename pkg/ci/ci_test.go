@@ -0,0 +1,78 @@
+package ci
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookup(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestDetect_GitHubActions(t *testing.T) {
+	m := detect(lookup(map[string]string{
+		"CI":                "true",
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_RUN_ID":     "8812345",
+		"GITHUB_SERVER_URL": "https://github.com",
+		"GITHUB_REPOSITORY": "acme/checkout",
+		"GITHUB_SHA":        "4f2a9c1e",
+		"GITHUB_REF_NAME":   "main",
+		"GITHUB_ACTOR":      "alice",
+	}))
+
+	require.NotNil(t, m)
+	assert.Equal(t, types.CIMetadata{
+		Provider:    "github-actions",
+		PipelineID:  "8812345",
+		PipelineURL: "https://github.com/acme/checkout/actions/runs/8812345",
+		Repository:  "acme/checkout",
+		CommitSHA:   "4f2a9c1e",
+		Ref:         "main",
+		Actor:       "alice",
+	}, *m)
+}
+
+func TestDetect_Providers(t *testing.T) {
+	tests := []struct {
+		vars     map[string]string
+		provider string
+		url      string
+	}{
+		{
+			vars:     map[string]string{"GITLAB_CI": "true", "CI_PIPELINE_ID": "42", "CI_PIPELINE_URL": "https://gitlab.com/acme/checkout/-/pipelines/42"},
+			provider: "gitlab",
+			url:      "https://gitlab.com/acme/checkout/-/pipelines/42",
+		},
+		{
+			vars:     map[string]string{"TF_BUILD": "True", "BUILD_BUILDID": "7", "SYSTEM_COLLECTIONURI": "https://dev.azure.com/acme/", "SYSTEM_TEAMPROJECT": "shop"},
+			provider: "azure-pipelines",
+			url:      "https://dev.azure.com/acme/shop/_build/results?buildId=7",
+		},
+		{
+			vars:     map[string]string{"BITBUCKET_BUILD_NUMBER": "12", "BITBUCKET_REPO_FULL_NAME": "acme/checkout"},
+			provider: "bitbucket",
+			url:      "https://bitbucket.org/acme/checkout/pipelines/results/12",
+		},
+		{
+			// Other CI systems on Jenkins agents take precedence
+			vars:     map[string]string{"JENKINS_URL": "https://ci.acme.dev/", "BUILDKITE": "true", "BUILDKITE_BUILD_URL": "https://buildkite.com/acme/checkout/builds/3"},
+			provider: "buildkite",
+			url:      "https://buildkite.com/acme/checkout/builds/3",
+		},
+	}
+	for _, tt := range tests {
+		m := detect(lookup(tt.vars))
+		require.NotNil(t, m, tt.provider)
+		assert.Equal(t, tt.provider, m.Provider)
+		assert.Equal(t, tt.url, m.PipelineURL, tt.provider)
+	}
+}
+
+func TestDetect_NotInCI(t *testing.T) {
+	assert.Nil(t, detect(lookup(nil)))
+	assert.Nil(t, detect(lookup(map[string]string{"CI": "true"})), "an unknown CI system has nothing to record")
+}
@@ -0,0 +1,165 @@
+// Package client embeds GitOps-Time-Machine in other Go programs, such as
+// operators, without the CLI. It logs only through the logger it is given
+// and reports every failure as an error:
+//
+//	import timemachine "github.com/raghu-007/GitOps-Time-Machine/pkg/client"
+//
+//	tm, err := timemachine.New(config.DefaultConfig())
+//	if err != nil {
+//		return err
+//	}
+//	snapshot, err := tm.Snapshot(ctx)
+//	...
+//	report, err := tm.Diff(ctx, "HEAD~1", "HEAD")
+//
+// The client works on one cluster and the whole snapshot repository;
+// fleets, tenancy directories, and hooks are CLI features.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/managedby"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// ClusterReader captures the live state of a cluster.
+// *collector.Collector implements it.
+type ClusterReader interface {
+	Collect(ctx context.Context) (*types.ResourceSnapshot, error)
+}
+
+// Storage keeps the history of snapshots.
+type Storage interface {
+	// Save records a snapshot and returns its commit hash, or "" if
+	// nothing changed since the previous snapshot.
+	Save(ctx context.Context, snapshot *types.ResourceSnapshot) (string, error)
+	// Load returns the snapshot at a commit hash, branch, tag, or
+	// revision expression such as HEAD~3.
+	Load(ctx context.Context, ref string) (*types.ResourceSnapshot, error)
+}
+
+// GitStore versions the files snapshots are written to.
+// *versioner.Versioner implements it.
+type GitStore interface {
+	timetravel.CommitReader
+	// Commit records the files as they are now, returning "" if nothing
+	// changed since the last commit.
+	Commit(metadata *types.SnapshotMetadata) (string, error)
+	// Entry returns the history entry of the commit a ref resolves to.
+	Entry(ref, dir string) (types.HistoryEntry, error)
+}
+
+// Options replaces the parts of a Client that are otherwise built from
+// its configuration.
+type Options struct {
+	// Logger receives log output. Nil discards it.
+	Logger log.FieldLogger
+	// Cluster reads live state. Nil connects to the cluster in the
+	// configuration on the first Snapshot.
+	Cluster ClusterReader
+	// Storage keeps the snapshots. Nil uses the Git repository in
+	// snapshot.output_dir; see NewGitStorage.
+	Storage Storage
+}
+
+// Client takes and compares snapshots. It is safe for concurrent use if
+// its ClusterReader and Storage are; the defaults are.
+type Client struct {
+	cfg     *config.Config
+	opts    Options
+	mu      sync.Mutex
+	cluster ClusterReader
+}
+
+// New creates a Client from a configuration, e.g. config.DefaultConfig().
+func New(cfg *config.Config) (*Client, error) {
+	return NewWithOptions(cfg, Options{})
+}
+
+// NewWithOptions creates a Client with explicit options.
+func NewWithOptions(cfg *config.Config, opts Options) (*Client, error) {
+	if opts.Logger == nil {
+		logger := log.New()
+		logger.SetOutput(io.Discard)
+		opts.Logger = logger
+	}
+	if opts.Storage == nil {
+		storage, err := NewGitStorage(cfg, opts.Logger)
+		if err != nil {
+			return nil, err
+		}
+		opts.Storage = storage
+	}
+	return &Client{cfg: cfg, opts: opts, cluster: opts.Cluster}, nil
+}
+
+// Snapshot captures the cluster's live state and saves it. The returned
+// snapshot's CommitHash is empty if nothing changed. If some resource types
+// could not be collected, the others are still saved and the snapshot is
+// returned with an error wrapping types.ErrPartialCollection.
+func (c *Client) Snapshot(ctx context.Context) (*types.ResourceSnapshot, error) {
+	cluster, err := c.clusterReader()
+	if err != nil {
+		return nil, err
+	}
+	snapshot, collectErr := cluster.Collect(ctx)
+	if collectErr != nil && !errors.Is(collectErr, types.ErrPartialCollection) {
+		return nil, fmt.Errorf("failed to collect resources: %w", collectErr)
+	}
+
+	commitHash, err := c.opts.Storage.Save(ctx, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	snapshot.Metadata.CommitHash = commitHash
+	return snapshot, collectErr
+}
+
+// Diff reports the drift from the snapshot at base to the snapshot at
+// target, leaving out the controllers in ignore_managed and the resources
+// and fields in ignore. Either ref may be anything Storage.Load accepts.
+func (c *Client) Diff(ctx context.Context, base, target string) (*types.DriftReport, error) {
+	baseSnapshot, err := c.opts.Storage.Load(ctx, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", base, err)
+	}
+	targetSnapshot, err := c.opts.Storage.Load(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", target, err)
+	}
+	return c.Compare(ctx, baseSnapshot, targetSnapshot)
+}
+
+// Compare reports the drift between two snapshots, e.g. a saved snapshot
+// and one just collected, with the same exclusions as Diff.
+func (c *Client) Compare(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+	base, target, _ = managedby.Exclude(&c.cfg.IgnoreManaged, base, target)
+	return analyzer.NewWithOptions(analyzer.Options{Logger: c.opts.Logger, Ignore: &c.cfg.Ignore}).CompareContext(ctx, base, target)
+}
+
+// clusterReader returns the configured ClusterReader, connecting to the
+// cluster on first use so that a Client that only diffs needs no cluster.
+func (c *Client) clusterReader() (ClusterReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cluster != nil {
+		return c.cluster, nil
+	}
+	coll, err := collector.New(c.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector: %w", err)
+	}
+	coll.SetLogger(c.opts.Logger)
+	c.cluster = coll
+	return coll, nil
+}
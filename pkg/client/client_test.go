@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tmtesting "github.com/raghu-007/GitOps-Time-Machine/pkg/client/testing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ ClusterReader = (*collector.Collector)(nil)
+	_ GitStore      = (*versioner.Versioner)(nil)
+	_ ClusterReader = (*tmtesting.Cluster)(nil)
+	_ Storage       = (*tmtesting.Storage)(nil)
+	_ GitStore      = (*tmtesting.GitStore)(nil)
+)
+
+func snapshotWithReplicas(replicas int) *types.ResourceSnapshot {
+	return &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC(), ClusterName: "test"},
+		Resources: []types.Resource{{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: "prod", Name: "api",
+			Spec: map[string]interface{}{"replicas": replicas},
+		}},
+	}
+}
+
+func testConfig(t *testing.T) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = filepath.Join(t.TempDir(), "snapshots")
+	return cfg
+}
+
+func TestSnapshotAndDiff(t *testing.T) {
+	cluster := &tmtesting.Cluster{Snapshots: []*types.ResourceSnapshot{
+		snapshotWithReplicas(2), snapshotWithReplicas(3),
+	}}
+	tm, err := NewWithOptions(testConfig(t), Options{Cluster: cluster})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	first, err := tm.Snapshot(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Metadata.CommitHash)
+
+	second, err := tm.Snapshot(ctx)
+	require.NoError(t, err)
+
+	report, err := tm.Diff(ctx, first.Metadata.CommitHash, "HEAD")
+	require.NoError(t, err)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, types.DriftScaled, report.Entries[0].Type)
+	assert.Equal(t, second.Metadata.CommitHash, report.TargetRef)
+
+	_, err = tm.Diff(ctx, "HEAD", "does-not-exist")
+	assert.ErrorIs(t, err, types.ErrCommitNotFound)
+}
+
+func TestSnapshot_SkipsUnchanged(t *testing.T) {
+	cfg := testConfig(t)
+	cluster := &tmtesting.Cluster{Snapshots: []*types.ResourceSnapshot{
+		snapshotWithReplicas(2), snapshotWithReplicas(2),
+	}}
+	tm, err := NewWithOptions(cfg, Options{Cluster: cluster})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	first, err := tm.Snapshot(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Metadata.ContentHash)
+	again, err := tm.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, again.Metadata.CommitHash, "only the timestamp changed")
+
+	// With skipping off the new timestamp is committed
+	cfg.Snapshot.SkipUnchanged = false
+	tm, err = NewWithOptions(cfg, Options{Cluster: cluster})
+	require.NoError(t, err)
+	third, err := tm.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, third.Metadata.CommitHash)
+}
+
+func TestSnapshot_Partial(t *testing.T) {
+	partial := fmt.Errorf("%w: failed to collect secrets", types.ErrPartialCollection)
+	cluster := &tmtesting.Cluster{Snapshots: []*types.ResourceSnapshot{snapshotWithReplicas(2)}, Err: partial}
+	tm, err := NewWithOptions(testConfig(t), Options{Cluster: cluster})
+	require.NoError(t, err)
+
+	snapshot, err := tm.Snapshot(context.Background())
+	assert.ErrorIs(t, err, types.ErrPartialCollection)
+	require.NotNil(t, snapshot)
+	assert.NotEmpty(t, snapshot.Metadata.CommitHash, "the collected types are still saved")
+}
+
+func TestDiff_MemoryStorage(t *testing.T) {
+	storage := tmtesting.NewStorage()
+	cluster := &tmtesting.Cluster{Snapshots: []*types.ResourceSnapshot{snapshotWithReplicas(2), snapshotWithReplicas(5)}}
+	// No snapshot directory is created with a Storage given
+	cfg := testConfig(t)
+	tm, err := NewWithOptions(cfg, Options{Cluster: cluster, Storage: storage})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := tm.Snapshot(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, cluster.Calls())
+	assert.Len(t, storage.Snapshots(), 2)
+	assert.NoDirExists(t, cfg.Snapshot.OutputDir)
+
+	report, err := tm.Diff(ctx, "HEAD~1", "HEAD")
+	require.NoError(t, err)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, types.DriftScaled, report.Entries[0].Type)
+}
+
+func TestGitStorage_FakeGitStore(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewGitStorageWithStore(snapshotter.New(dir), tmtesting.NewGitStore(dir))
+	ctx := context.Background()
+
+	first := snapshotWithReplicas(2)
+	hash, err := storage.Save(ctx, first)
+	require.NoError(t, err)
+	require.NotEmpty(t, hash)
+	unchanged, err := storage.Save(ctx, first)
+	require.NoError(t, err)
+	assert.Empty(t, unchanged)
+	_, err = storage.Save(ctx, snapshotWithReplicas(3))
+	require.NoError(t, err)
+
+	loaded, err := storage.Load(ctx, "HEAD~1")
+	require.NoError(t, err)
+	assert.Equal(t, hash, loaded.Metadata.CommitHash)
+	require.Len(t, loaded.Resources, 1)
+	assert.Equal(t, 2, loaded.Resources[0].Spec["replicas"])
+	assert.NotEmpty(t, loaded.Resources[0].ContentHash)
+
+	_, err = storage.Load(ctx, "HEAD~2")
+	assert.ErrorIs(t, err, types.ErrCommitNotFound)
+}
+
+func TestNew_UnsupportedConfig(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Tenancy.Mode = "directory"
+	_, err := New(cfg)
+	assert.Error(t, err)
+}
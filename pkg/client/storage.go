@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+)
+
+// gitStorage keeps snapshots in the same repository layout as the CLI.
+type gitStorage struct {
+	// mu serializes writes to the working tree with reads of the repository.
+	mu   sync.Mutex
+	snap *snapshotter.Snapshotter
+	git  GitStore
+	// skipUnchanged is snapshot.skip_unchanged.
+	skipUnchanged bool
+}
+
+// NewGitStorage returns the Storage the CLI uses: each snapshot is written
+// to snapshot.output_dir and committed to the Git repository there, which
+// is created if needed. Snapshots are loaded from commits without checking
+// them out. With snapshot.skip_unchanged, a snapshot whose resources are
+// unchanged since the last commit is neither written nor committed. Fleets
+// and tenancy directories are not supported.
+func NewGitStorage(cfg *config.Config, logger log.FieldLogger) (Storage, error) {
+	if len(cfg.Clusters) > 0 {
+		return nil, fmt.Errorf("clusters are not supported by the client")
+	}
+	if cfg.Tenancy.Mode != "" {
+		return nil, fmt.Errorf("tenancy.mode is not supported by the client")
+	}
+	ver, err := versioner.NewWithOptions(cfg.Snapshot.OutputDir, &cfg.Git, versioner.Options{Logger: logger})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	opts := snapshotter.Options{
+		Compression:          cfg.Snapshot.Compression.Algorithm,
+		CompressionThreshold: cfg.Snapshot.Compression.ThresholdBytes,
+		BlobThreshold:        cfg.Snapshot.BlobThresholdBytes,
+		Logger:               logger,
+	}
+	if cfg.Snapshot.IncludeConfig {
+		if opts.Config, err = config.RedactedYAML(cfg); err != nil {
+			return nil, err
+		}
+	}
+	snap := snapshotter.NewWithOptions(cfg.Snapshot.OutputDir, opts)
+	return &gitStorage{snap: snap, git: ver, skipUnchanged: cfg.Snapshot.SkipUnchanged}, nil
+}
+
+// NewGitStorageWithStore returns a Storage that writes snapshots with snap
+// and commits them to store, e.g. a fake GitStore in tests. Snapshots
+// unchanged since the last commit are skipped.
+func NewGitStorageWithStore(snap *snapshotter.Snapshotter, store GitStore) Storage {
+	return &gitStorage{snap: snap, git: store, skipUnchanged: true}
+}
+
+func (g *gitStorage) Save(ctx context.Context, snapshot *types.ResourceSnapshot) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	hash, err := g.snap.ContentHash(snapshot, nil)
+	if err != nil {
+		return "", err
+	}
+	snapshot.Metadata.ContentHash = hash
+	if g.skipUnchanged {
+		if last, err := g.git.Entry("HEAD", ""); err == nil && last.ContentHash == hash {
+			return "", nil
+		}
+	}
+	if err := g.snap.Write(snapshot); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return g.git.Commit(&snapshot.Metadata)
+}
+
+func (g *gitStorage) Load(ctx context.Context, ref string) (*types.ResourceSnapshot, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry, err := g.git.Entry(ref, "")
+	if err != nil {
+		return nil, err
+	}
+	return timetravel.ReadCommit(ctx, g.git, entry, "", nil)
+}
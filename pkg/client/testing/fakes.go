@@ -0,0 +1,317 @@
+// Package testing provides in-memory fakes of the client's ClusterReader,
+// Storage, and GitStore, so that code built on package client can be tested
+// without a cluster, a Git repository, or, for Storage, a filesystem.
+// Import it under another name, e.g.:
+//
+//	import tmtesting "github.com/raghu-007/GitOps-Time-Machine/pkg/client/testing"
+//
+//	tm, err := client.NewWithOptions(cfg, client.Options{
+//		Cluster: &tmtesting.Cluster{Snapshots: snapshots},
+//		Storage: tmtesting.NewStorage(),
+//	})
+package testing
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+)
+
+// Cluster is a ClusterReader that returns prepared snapshots in turn,
+// repeating the last one once they run out.
+type Cluster struct {
+	// Snapshots are returned by successive calls to Collect.
+	Snapshots []*types.ResourceSnapshot
+	// Err is returned with every snapshot, e.g. an error wrapping
+	// types.ErrPartialCollection.
+	Err error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// Collect returns a copy of the next prepared snapshot.
+func (c *Cluster) Collect(ctx context.Context) (*types.ResourceSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.Snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots prepared")
+	}
+	snapshot := *c.Snapshots[min(c.calls, len(c.Snapshots)-1)]
+	c.calls++
+	return &snapshot, c.Err
+}
+
+// Calls returns the number of times Collect was called.
+func (c *Cluster) Calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+// Storage is a Storage that keeps snapshots in memory. Like the Git
+// storage, saving a snapshot equal to the previous one records nothing.
+type Storage struct {
+	mu        sync.Mutex
+	hashes    []string
+	snapshots []types.ResourceSnapshot
+}
+
+// NewStorage creates an empty Storage.
+func NewStorage() *Storage {
+	return &Storage{}
+}
+
+// Save records a copy of the snapshot under a hash of its content.
+func (s *Storage) Save(ctx context.Context, snapshot *types.ResourceSnapshot) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	saved := *snapshot
+	saved.Metadata.CommitHash = ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.snapshots); n > 0 && reflect.DeepEqual(s.snapshots[n-1], saved) {
+		return "", nil
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	sum := sha1.Sum(append([]byte(strconv.Itoa(len(s.hashes))+"\x00"), data...))
+	hash := hex.EncodeToString(sum[:])
+	s.hashes = append(s.hashes, hash)
+	s.snapshots = append(s.snapshots, saved)
+	return hash, nil
+}
+
+// Load returns a copy of the snapshot at a full or abbreviated hash, HEAD,
+// or HEAD~n.
+func (s *Storage) Load(ctx context.Context, ref string) (*types.ResourceSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, err := resolve(s.hashes, ref)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := s.snapshots[i]
+	snapshot.Metadata.CommitHash = s.hashes[i]
+	return &snapshot, nil
+}
+
+// Snapshots returns the saved snapshots, oldest first.
+func (s *Storage) Snapshots() []types.ResourceSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.ResourceSnapshot(nil), s.snapshots...)
+}
+
+// GitStore is a GitStore that keeps commits in memory instead of a Git
+// repository. Each commit records the files under Dir, the directory the
+// snapshots are written to; hashes are computed the way Git computes them.
+type GitStore struct {
+	Dir string
+
+	mu      sync.Mutex
+	hashes  []string
+	commits []fakeCommit
+	blobs   map[string][]byte
+}
+
+// fakeCommit is a commit of a GitStore.
+type fakeCommit struct {
+	metadata types.SnapshotMetadata
+	// files maps slash-separated paths to blob hashes.
+	files map[string]string
+}
+
+// NewGitStore creates an empty GitStore of the files under dir.
+func NewGitStore(dir string) *GitStore {
+	return &GitStore{Dir: dir, blobs: make(map[string][]byte)}
+}
+
+// Commit records the files under Dir, returning "" if they are unchanged.
+func (g *GitStore) Commit(metadata *types.SnapshotMetadata) (string, error) {
+	files := make(map[string]string)
+	blobs := make(map[string][]byte)
+	err := filepath.WalkDir(g.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(g.Dir, p)
+		if err != nil {
+			return err
+		}
+		hash := plumbing.ComputeHash(plumbing.BlobObject, data).String()
+		files[filepath.ToSlash(rel)] = hash
+		blobs[hash] = data
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", g.Dir, err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n := len(g.commits); n > 0 && reflect.DeepEqual(g.commits[n-1].files, files) {
+		return "", nil
+	}
+	for hash, data := range blobs {
+		g.blobs[hash] = data
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sum := sha1.New()
+	fmt.Fprintf(sum, "%d\x00", len(g.hashes))
+	for _, name := range names {
+		fmt.Fprintf(sum, "%s\x00%s\x00", name, files[name])
+	}
+	hash := hex.EncodeToString(sum.Sum(nil))
+	g.hashes = append(g.hashes, hash)
+	g.commits = append(g.commits, fakeCommit{metadata: *metadata, files: files})
+	return hash, nil
+}
+
+// Entry returns the history entry of the commit ref resolves to. The
+// metadata is that passed to Commit, whatever dir is.
+func (g *GitStore) Entry(ref, dir string) (types.HistoryEntry, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	i, err := resolve(g.hashes, ref)
+	if err != nil {
+		return types.HistoryEntry{}, err
+	}
+	m := g.commits[i].metadata
+	return types.HistoryEntry{
+		CommitHash:      g.hashes[i],
+		Timestamp:       m.Timestamp,
+		ResourceCount:   m.ResourceCount,
+		ClusterName:     m.ClusterName,
+		Context:         m.Context,
+		Namespaces:      m.Namespaces,
+		KindCounts:      m.KindCounts,
+		NamespaceCounts: m.NamespaceCounts,
+		ContentHash:     m.ContentHash,
+	}, nil
+}
+
+// TreeFiles lists the files under dir at a commit.
+func (g *GitStore) TreeFiles(commitHash, dir string) ([]versioner.TreeFile, error) {
+	c, err := g.commit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if dir != "" {
+		prefix = strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+	}
+	var files []versioner.TreeFile
+	for name, hash := range c.files {
+		if strings.HasPrefix(name, prefix) {
+			files = append(files, versioner.TreeFile{Path: name, Hash: hash})
+		}
+	}
+	return files, nil
+}
+
+// ReadBlob returns the content of a blob by hash.
+func (g *GitStore) ReadBlob(hash string) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	data, ok := g.blobs[hash]
+	if !ok {
+		return nil, fmt.Errorf("failed to get blob %s: %w", hash, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// ReadFileAt returns the content of a file at a commit.
+func (g *GitStore) ReadFileAt(commitHash, filePath string) ([]byte, error) {
+	c, err := g.commit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	hash, ok := c.files[path.Clean(filepath.ToSlash(filePath))]
+	if !ok {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, commitHash, fs.ErrNotExist)
+	}
+	return g.ReadBlob(hash)
+}
+
+// commit returns the commit with a full hash.
+func (g *GitStore) commit(hash string) (fakeCommit, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, h := range g.hashes {
+		if h == hash {
+			return g.commits[i], nil
+		}
+	}
+	return fakeCommit{}, fmt.Errorf("%w: %s", types.ErrCommitNotFound, hash)
+}
+
+// resolve returns the index in hashes, oldest first, of a full or
+// abbreviated hash, HEAD, or HEAD~n.
+func resolve(hashes []string, ref string) (int, error) {
+	if len(hashes) == 0 {
+		return 0, types.ErrNoSnapshots
+	}
+	if ref == "HEAD" {
+		return len(hashes) - 1, nil
+	}
+	if back, ok := strings.CutPrefix(ref, "HEAD~"); ok {
+		n, err := strconv.Atoi(back)
+		if err != nil || n < 0 || n >= len(hashes) {
+			return 0, fmt.Errorf("%w: %s", types.ErrCommitNotFound, ref)
+		}
+		return len(hashes) - 1 - n, nil
+	}
+	found := -1
+	for i, h := range hashes {
+		if len(ref) >= 4 && strings.HasPrefix(h, ref) {
+			if found >= 0 {
+				return 0, fmt.Errorf("%w: %s is ambiguous", types.ErrCommitNotFound, ref)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("%w: %s", types.ErrCommitNotFound, ref)
+	}
+	return found, nil
+}
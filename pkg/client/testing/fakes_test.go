@@ -0,0 +1,55 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCluster(t *testing.T) {
+	first := &types.ResourceSnapshot{Metadata: types.SnapshotMetadata{ClusterName: "a"}}
+	last := &types.ResourceSnapshot{Metadata: types.SnapshotMetadata{ClusterName: "b"}}
+	c := &Cluster{Snapshots: []*types.ResourceSnapshot{first, last}}
+
+	for _, want := range []string{"a", "b", "b"} {
+		snapshot, err := c.Collect(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, want, snapshot.Metadata.ClusterName)
+	}
+	assert.Equal(t, 3, c.Calls())
+
+	_, err := (&Cluster{}).Collect(context.Background())
+	assert.Error(t, err)
+}
+
+func TestStorage(t *testing.T) {
+	s := NewStorage()
+	ctx := context.Background()
+	_, err := s.Load(ctx, "HEAD")
+	assert.ErrorIs(t, err, types.ErrNoSnapshots)
+
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{{Kind: "Namespace", Name: "prod"}}}
+	first, err := s.Save(ctx, snapshot)
+	require.NoError(t, err)
+	unchanged, err := s.Save(ctx, snapshot)
+	require.NoError(t, err)
+	assert.Empty(t, unchanged)
+
+	snapshot.Resources = nil
+	second, err := s.Save(ctx, snapshot)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	for ref, want := range map[string]string{"HEAD": second, "HEAD~1": first, first[:7]: first, second: second} {
+		loaded, err := s.Load(ctx, ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, loaded.Metadata.CommitHash, ref)
+	}
+	for _, ref := range []string{"HEAD~2", "main", "abc"} {
+		_, err := s.Load(ctx, ref)
+		assert.ErrorIs(t, err, types.ErrCommitNotFound, ref)
+	}
+}
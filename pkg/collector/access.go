@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// collectVerb is the only verb the collector needs on each resource type.
+const collectVerb = "list"
+
+// watchVerb is also needed on each type with watch.enable_watch_events.
+const watchVerb = "watch"
+
+// roleVerbs returns the verbs to grant on each resource type.
+func roleVerbs(watch bool) []string {
+	if watch {
+		return []string{collectVerb, watchVerb}
+	}
+	return []string{collectVerb}
+}
+
+// selfSubjectAccessReviews is used to ask the API server what the current identity may do.
+var selfSubjectAccessReviews = schema.GroupVersionResource{
+	Group: "authorization.k8s.io", Version: "v1", Resource: "selfsubjectaccessreviews",
+}
+
+// Access reports whether the current identity can collect a resource type.
+type Access struct {
+	ResourceType string `json:"resourceType" yaml:"resourceType"`
+	Group        string `json:"group" yaml:"group"`
+	Resource     string `json:"resource" yaml:"resource"`
+	// Known is false for resource types the collector has no mapping for.
+	Known   bool   `json:"known" yaml:"known"`
+	Allowed bool   `json:"allowed" yaml:"allowed"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// CheckAccess asks the API server, via SelfSubjectAccessReview, whether the
+// current identity can list each configured (or, in discovery mode,
+// discovered) resource type cluster-wide. Nothing is collected.
+func (c *Collector) CheckAccess(ctx context.Context) ([]Access, error) {
+	resTypes, err := c.resourceTypes()
+	if err != nil {
+		if resTypes == nil {
+			return nil, err
+		}
+		c.logger.WithError(err).Warn("some API groups could not be discovered, skipping them")
+	}
+
+	var results []Access
+	for _, rt := range resTypes {
+		resType, gvr := rt.name, rt.gvr
+		if !rt.known {
+			results = append(results, Access{ResourceType: resType, Reason: "unknown resource type"})
+			continue
+		}
+
+		review := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "authorization.k8s.io/v1",
+			"kind":       "SelfSubjectAccessReview",
+			"spec": map[string]interface{}{
+				"resourceAttributes": map[string]interface{}{
+					"verb":     collectVerb,
+					"group":    gvr.Group,
+					"version":  gvr.Version,
+					"resource": gvr.Resource,
+				},
+			},
+		}}
+
+		resp, err := c.dynamicClient.Resource(selfSubjectAccessReviews).Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review access to %s: %w", resType, err)
+		}
+
+		allowed, _, _ := unstructured.NestedBool(resp.Object, "status", "allowed")
+		reason, _, _ := unstructured.NestedString(resp.Object, "status", "reason")
+		results = append(results, Access{
+			ResourceType: resType,
+			Group:        gvr.Group,
+			Resource:     gvr.Resource,
+			Known:        true,
+			Allowed:      allowed,
+			Reason:       reason,
+		})
+	}
+	return results, nil
+}
+
+// DiscoveryClusterRole returns a ClusterRole manifest named name that grants
+// list (and with watch, watch) on every resource type, as discovery mode
+// needs: the types to collect are only known once the cluster is asked.
+func DiscoveryClusterRole(name string, watch bool) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata":   map[string]interface{}{"name": name},
+		"rules": []interface{}{map[string]interface{}{
+			"apiGroups": []string{"*"},
+			"resources": []string{"*"},
+			"verbs":     roleVerbs(watch),
+		}},
+	}
+}
+
+// ClusterRole returns a ClusterRole manifest named name that grants exactly
+// the read access needed to collect resourceTypes, and with watch to watch
+// them for event-driven snapshots. Unknown types are skipped.
+func ClusterRole(name string, resourceTypes []string, watch bool) map[string]interface{} {
+	byGroup := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, resType := range resourceTypes {
+		gvr, ok := resourceMapping[resType]
+		if !ok || seen[resType] {
+			continue
+		}
+		seen[resType] = true
+		byGroup[gvr.Group] = append(byGroup[gvr.Group], gvr.Resource)
+	}
+
+	groups := make([]string, 0, len(byGroup))
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	rules := make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		resources := byGroup[group]
+		sort.Strings(resources)
+		rules = append(rules, map[string]interface{}{
+			"apiGroups": []string{group},
+			"resources": resources,
+			"verbs":     roleVerbs(watch),
+		})
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRole",
+		"metadata":   map[string]interface{}{"name": name},
+		"rules":      rules,
+	}
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterRole_GroupsResourcesByAPIGroup(t *testing.T) {
+	role := ClusterRole("reader", []string{"services", "deployments", "configmaps", "statefulsets", "bogus", "services"}, false)
+
+	assert.Equal(t, "ClusterRole", role["kind"])
+	assert.Equal(t, map[string]interface{}{"name": "reader"}, role["metadata"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"apiGroups": []string{""},
+			"resources": []string{"configmaps", "services"},
+			"verbs":     []string{"list"},
+		},
+		map[string]interface{}{
+			"apiGroups": []string{"apps"},
+			"resources": []string{"deployments", "statefulsets"},
+			"verbs":     []string{"list"},
+		},
+	}, role["rules"])
+}
+
+func TestClusterRole_Watch(t *testing.T) {
+	role := ClusterRole("reader", []string{"configmaps"}, true)
+	rules := role["rules"].([]interface{})
+	assert.Equal(t, []string{"list", "watch"}, rules[0].(map[string]interface{})["verbs"])
+
+	role = DiscoveryClusterRole("reader", true)
+	rules = role["rules"].([]interface{})
+	assert.Equal(t, []string{"list", "watch"}, rules[0].(map[string]interface{})["verbs"])
+}
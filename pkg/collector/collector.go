@@ -4,47 +4,87 @@ package collector
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	log "github.com/sirupsen/logrus"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 // resourceMapping maps friendly names to GVR (GroupVersionResource).
 var resourceMapping = map[string]schema.GroupVersionResource{
-	"deployments":            {Group: "apps", Version: "v1", Resource: "deployments"},
-	"statefulsets":           {Group: "apps", Version: "v1", Resource: "statefulsets"},
-	"daemonsets":             {Group: "apps", Version: "v1", Resource: "daemonsets"},
-	"services":               {Group: "", Version: "v1", Resource: "services"},
-	"configmaps":             {Group: "", Version: "v1", Resource: "configmaps"},
-	"secrets":                {Group: "", Version: "v1", Resource: "secrets"},
-	"persistentvolumeclaims": {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
-	"serviceaccounts":        {Group: "", Version: "v1", Resource: "serviceaccounts"},
-	"ingresses":              {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
-	"networkpolicies":        {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
-	"cronjobs":               {Group: "batch", Version: "v1", Resource: "cronjobs"},
-	"roles":                  {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
-	"rolebindings":           {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
-	"clusterroles":           {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
-	"clusterrolebindings":    {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+	"deployments":               {Group: "apps", Version: "v1", Resource: "deployments"},
+	"statefulsets":              {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"daemonsets":                {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	"services":                  {Group: "", Version: "v1", Resource: "services"},
+	"configmaps":                {Group: "", Version: "v1", Resource: "configmaps"},
+	"secrets":                   {Group: "", Version: "v1", Resource: "secrets"},
+	"persistentvolumeclaims":    {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+	"serviceaccounts":           {Group: "", Version: "v1", Resource: "serviceaccounts"},
+	"ingresses":                 {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	"networkpolicies":           {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	"cronjobs":                  {Group: "batch", Version: "v1", Resource: "cronjobs"},
+	"roles":                     {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	"rolebindings":              {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	"clusterroles":              {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
+	"clusterrolebindings":       {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+	"customresourcedefinitions": {Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"},
 }
 
+// resourceKinds maps the friendly names of resourceMapping to their kinds.
+var resourceKinds = map[string]string{
+	"deployments":               "Deployment",
+	"statefulsets":              "StatefulSet",
+	"daemonsets":                "DaemonSet",
+	"services":                  "Service",
+	"configmaps":                "ConfigMap",
+	"secrets":                   "Secret",
+	"persistentvolumeclaims":    "PersistentVolumeClaim",
+	"serviceaccounts":           "ServiceAccount",
+	"ingresses":                 "Ingress",
+	"networkpolicies":           "NetworkPolicy",
+	"cronjobs":                  "CronJob",
+	"roles":                     "Role",
+	"rolebindings":              "RoleBinding",
+	"clusterroles":              "ClusterRole",
+	"clusterrolebindings":       "ClusterRoleBinding",
+	"customresourcedefinitions": "CustomResourceDefinition",
+}
+
+// KindOf returns the kind of a resource type name of snapshot.resource_types.
+func KindOf(name string) (string, bool) {
+	kind, ok := resourceKinds[name]
+	return kind, ok
+}
+
+// ProgressFunc is called after each resource type has been collected.
+type ProgressFunc func(resourceType string, typesDone, typesTotal, resources int)
+
 // Collector connects to a Kubernetes cluster and captures resource state.
 type Collector struct {
 	dynamicClient   dynamic.Interface
 	discoveryClient discovery.DiscoveryInterface
 	config          *config.Config
+	progress        ProgressFunc
+	logger          log.FieldLogger
 }
 
-// New creates a new Collector from the given configuration.
-func New(cfg *config.Config) (*Collector, error) {
+// RESTConfig builds a client configuration from the configured kubeconfig
+// and context, falling back to in-cluster configuration, and applies the
+// client proxy and CA settings.
+func RESTConfig(cfg *config.Config) (*rest.Config, error) {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
 	rules.ExplicitPath = cfg.Kubeconfig
 
@@ -57,6 +97,24 @@ func New(cfg *config.Config) (*Collector, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to build kubeconfig: %w", err)
 	}
+	if err := applyClientConfig(restConfig, &cfg.Client); err != nil {
+		return nil, err
+	}
+	return restConfig, nil
+}
+
+// New creates a new Collector from the given configuration.
+func New(cfg *config.Config) (*Collector, error) {
+	restConfig, err := RESTConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// client-go's default limit of 5 requests per second would serialize
+	// the concurrent lists again
+	if restConfig.QPS == 0 && cfg.Snapshot.Concurrency > 1 {
+		restConfig.QPS = float32(5 * cfg.Snapshot.Concurrency)
+		restConfig.Burst = 10 * cfg.Snapshot.Concurrency
+	}
 
 	dynClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
@@ -72,10 +130,24 @@ func New(cfg *config.Config) (*Collector, error) {
 		dynamicClient:   dynClient,
 		discoveryClient: discoClient,
 		config:          cfg,
+		logger:          log.StandardLogger(),
 	}, nil
 }
 
-// Collect captures the current state of all configured resources.
+// SetProgress registers a callback for collection progress.
+func (c *Collector) SetProgress(fn ProgressFunc) {
+	c.progress = fn
+}
+
+// SetLogger replaces the standard logrus logger for the collector's log output.
+func (c *Collector) SetLogger(logger log.FieldLogger) {
+	c.logger = logger
+}
+
+// Collect captures the current state of all configured resources, or in
+// discovery mode of every discovered resource type. If some resource types
+// (or API groups) fail, the snapshot of the others is returned with an
+// error wrapping types.ErrPartialCollection that names the failed types.
 func (c *Collector) Collect(ctx context.Context) (*types.ResourceSnapshot, error) {
 	snapshot := &types.ResourceSnapshot{
 		Metadata: types.SnapshotMetadata{
@@ -87,96 +159,261 @@ func (c *Collector) Collect(ctx context.Context) (*types.ResourceSnapshot, error
 
 	namespacesSet := make(map[string]bool)
 
-	for _, resType := range c.config.Snapshot.ResourceTypes {
-		gvr, ok := resourceMapping[resType]
-		if !ok {
-			log.WithField("resource", resType).Warn("unknown resource type, skipping")
-			continue
+	var problems []string
+	resTypes, err := c.resourceTypes()
+	if err != nil {
+		if resTypes == nil {
+			return nil, err
 		}
+		c.logger.WithError(err).Warn("some API groups could not be discovered, skipping them")
+		problems = append(problems, err.Error())
+	}
 
-		resources, err := c.collectResource(ctx, gvr)
-		if err != nil {
-			log.WithError(err).WithField("resource", resType).Warn("failed to collect resource")
+	// Types are listed concurrently; their resources are added in the
+	// configured order so that snapshots do not depend on timing
+	results := c.collectTypes(ctx, resTypes)
+	var failed []string
+	for i, rt := range resTypes {
+		if results[i].stats != nil {
+			snapshot.Metadata.Collection = append(snapshot.Metadata.Collection, *results[i].stats)
+		}
+		if !results[i].ok {
+			failed = append(failed, rt.name)
 			continue
 		}
-
-		for _, res := range resources {
-			if c.shouldExcludeNamespace(res.Namespace) {
-				continue
-			}
-			if len(c.config.Snapshot.Namespaces) > 0 && !c.shouldIncludeNamespace(res.Namespace) {
-				continue
-			}
+		for _, res := range results[i].resources {
 			snapshot.Resources = append(snapshot.Resources, res)
 			if res.Namespace != "" {
 				namespacesSet[res.Namespace] = true
 			}
 		}
-
-		log.WithFields(log.Fields{
-			"resource": resType,
-			"count":    len(resources),
-		}).Debug("collected resources")
 	}
 
 	// Build namespace list
 	for ns := range namespacesSet {
 		snapshot.Metadata.Namespaces = append(snapshot.Metadata.Namespaces, ns)
 	}
-	snapshot.Metadata.ResourceCount = len(snapshot.Resources)
+	snapshot.UpdateCounts()
 
-	log.WithFields(log.Fields{
+	c.logger.WithFields(log.Fields{
 		"totalResources": snapshot.Metadata.ResourceCount,
 		"namespaces":     len(snapshot.Metadata.Namespaces),
 	}).Info("snapshot collection completed")
 
+	if len(failed) > 0 {
+		problems = append(problems, "failed to collect "+strings.Join(failed, ", "))
+	}
+	if len(problems) > 0 {
+		return snapshot, fmt.Errorf("%w: %s", types.ErrPartialCollection, strings.Join(problems, "; "))
+	}
 	return snapshot, nil
 }
 
-// collectResource fetches all instances of a specific resource type.
-func (c *Collector) collectResource(ctx context.Context, gvr schema.GroupVersionResource) ([]types.Resource, error) {
-	var resources []types.Resource
+// ValidateConcurrency checks snapshot.concurrency.
+func ValidateConcurrency(n int) error {
+	if n < 1 {
+		return fmt.Errorf("snapshot.concurrency must be at least 1, got %d", n)
+	}
+	return nil
+}
 
-	list, err := c.dynamicClient.Resource(gvr).Namespace("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+// ValidatePageSize checks snapshot.page_size.
+func ValidatePageSize(n int) error {
+	if n < 0 {
+		return fmt.Errorf("snapshot.page_size must not be negative, got %d", n)
 	}
+	return nil
+}
 
-	for _, item := range list.Items {
-		obj := item.Object
+// typeResult is the outcome of collecting one resource type.
+type typeResult struct {
+	resources []types.Resource
+	// stats is nil for types that were not listed.
+	stats *types.CollectionStats
+	ok    bool
+}
 
-		// Strip configured fields
-		c.stripFields(obj)
+// collectTypes lists the resource types with up to snapshot.concurrency
+// workers and returns their results in the order of rts. Progress is
+// reported as types complete.
+func (c *Collector) collectTypes(ctx context.Context, rts []resourceType) []typeResult {
+	workers := c.config.Snapshot.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(rts) {
+		workers = len(rts)
+	}
 
-		res := types.Resource{
-			APIVersion: item.GetAPIVersion(),
-			Kind:       item.GetKind(),
-			Namespace:  item.GetNamespace(),
-			Name:       item.GetName(),
-			Labels:     item.GetLabels(),
-			Annotations: cleanAnnotations(item.GetAnnotations()),
-			Raw:        obj,
+	results := make([]typeResult, len(rts))
+	next := make(chan int)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		done      int
+		collected int
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = c.collectType(ctx, rts[i])
+
+				mu.Lock()
+				done++
+				collected += len(results[i].resources)
+				if c.progress != nil {
+					c.progress(rts[i].name, done, len(rts), collected)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range rts {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// collectType returns all included resources of one configured type with
+// the statistics of listing them. The result is not ok if the type could
+// not be listed; unknown types are skipped with a warning but do not count
+// as failures.
+func (c *Collector) collectType(ctx context.Context, rt resourceType) typeResult {
+	if !rt.known {
+		c.logger.WithField("resource", rt.name).Warn("unknown resource type, skipping")
+		return typeResult{ok: true}
+	}
+
+	stats := &types.CollectionStats{Resource: rt.name}
+	start := time.Now()
+	resources, err := c.collectResource(ctx, rt.gvr, stats)
+	stats.Duration = time.Since(start)
+	if err != nil {
+		c.logger.WithError(err).WithField("resource", rt.name).Warn("failed to collect resource")
+		stats.Error = err.Error()
+		return typeResult{stats: stats}
+	}
+	stats.Count = len(resources)
+
+	c.logger.WithFields(log.Fields{
+		"resource": rt.name,
+		"count":    stats.Count,
+		"pages":    stats.Pages,
+		"retries":  stats.Retries,
+		"duration": stats.Duration.Round(time.Millisecond),
+	}).Debug("collected resources")
+	return typeResult{resources: resources, stats: stats, ok: true}
+}
+
+// collectResource fetches all instances of a specific resource type in
+// included namespaces, snapshot.page_size at a time, counting list calls in
+// stats. Each page is converted before the next is
+// requested, so only one page of raw objects is held at a time. If the
+// server expires the continue token of a long listing, the listing starts
+// over once.
+func (c *Collector) collectResource(ctx context.Context, gvr schema.GroupVersionResource, stats *types.CollectionStats) ([]types.Resource, error) {
+	client := c.dynamicClient.Resource(gvr).Namespace("")
+	var resources []types.Resource
+	opts := metav1.ListOptions{Limit: int64(c.config.Snapshot.PageSize)}
+	for {
+		list, err := client.List(ctx, opts)
+		if err != nil {
+			stats.Errors++
+		}
+		if apierrors.IsResourceExpired(err) && stats.Retries == 0 {
+			c.logger.WithField("resource", gvr.Resource).Warn("listing took too long and expired, starting over")
+			resources, opts.Continue = nil, ""
+			stats.Retries++
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
 		}
+		stats.Pages++
 
-		// Extract spec and data if present
-		if spec, ok := obj["spec"].(map[string]interface{}); ok {
-			res.Spec = spec
+		for i := range list.Items {
+			item := &list.Items[i]
+			if c.shouldExcludeNamespace(item.GetNamespace()) {
+				continue
+			}
+			if len(c.config.Snapshot.Namespaces) > 0 && !c.shouldIncludeNamespace(item.GetNamespace()) {
+				continue
+			}
+			resources = append(resources, c.resource(item))
 		}
-		if data, ok := obj["data"].(map[string]interface{}); ok {
-			res.Data = data
+
+		if opts.Continue = list.GetContinue(); opts.Continue == "" {
+			return resources, nil
 		}
+	}
+}
 
-		resources = append(resources, res)
+// resource converts a listed object, cleaned as configured.
+func (c *Collector) resource(item *unstructured.Unstructured) types.Resource {
+	obj := item.Object
+	annotations := Clean(item, &c.config.Snapshot)
+
+	res := types.Resource{
+		APIVersion:  item.GetAPIVersion(),
+		Kind:        item.GetKind(),
+		Namespace:   item.GetNamespace(),
+		Name:        item.GetName(),
+		Labels:      item.GetLabels(),
+		Annotations: annotations,
+		Raw:         obj,
 	}
 
-	return resources, nil
+	if c.config.Snapshot.TrackLifecycle {
+		res.UID = string(item.GetUID())
+		if created := item.GetCreationTimestamp(); !created.IsZero() {
+			res.CreationTimestamp = created.UTC().Format(time.RFC3339)
+		}
+	}
+	if c.config.Snapshot.TrackFieldManagers {
+		res.Managers = types.FieldManagers(obj)
+	}
+
+	// Extract spec and data if present
+	if spec, ok := obj["spec"].(map[string]interface{}); ok {
+		res.Spec = spec
+	}
+	if data, ok := obj["data"].(map[string]interface{}); ok {
+		res.Data = data
+	}
+
+	return res
+}
+
+// Clean strips and redacts an object as the snapshot configuration asks,
+// and returns its cleaned annotations. The importer cleans manifests with
+// it too, so that a baseline holds what a snapshot of the cluster would.
+func Clean(item *unstructured.Unstructured, cfg *config.SnapshotConfig) map[string]string {
+	obj := item.Object
+	if cfg.TrackFieldManagers {
+		condenseManagedFields(obj)
+	}
+	stripFields(obj, cfg)
+	prune(item, cfg.Prune)
+	redactEnv(obj, cfg.RedactEnv)
+
+	// Keep the stored object consistent with the cleaned annotations
+	annotations := CleanAnnotations(item.GetAnnotations())
+	item.SetAnnotations(annotations)
+	return annotations
 }
 
 // stripFields removes configured fields from the resource object.
-func (c *Collector) stripFields(obj map[string]interface{}) {
-	for _, field := range c.config.Snapshot.StripFields {
+func stripFields(obj map[string]interface{}, cfg *config.SnapshotConfig) {
+	for _, field := range cfg.StripFields {
 		switch field {
 		case ".metadata.managedFields":
+			if cfg.TrackFieldManagers {
+				continue
+			}
 			if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
 				delete(metadata, "managedFields")
 			}
@@ -185,6 +422,9 @@ func (c *Collector) stripFields(obj map[string]interface{}) {
 				delete(metadata, "resourceVersion")
 			}
 		case ".metadata.uid":
+			if cfg.TrackLifecycle {
+				continue
+			}
 			if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
 				delete(metadata, "uid")
 			}
@@ -198,6 +438,45 @@ func (c *Collector) stripFields(obj map[string]interface{}) {
 	}
 }
 
+// condenseManagedFields reduces .metadata.managedFields to the manager and
+// operation of each entry. Field sets and timestamps change on every update
+// and would make each snapshot noisy; entries for the status subresource
+// are dropped because status is not what a manager owns in a snapshot.
+func condenseManagedFields(obj map[string]interface{}) {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	entries, _ := metadata["managedFields"].([]interface{})
+	seen := make(map[string]bool)
+	var condensed []interface{}
+	for _, e := range entries {
+		entry, _ := e.(map[string]interface{})
+		manager, _ := entry["manager"].(string)
+		operation, _ := entry["operation"].(string)
+		if manager == "" || entry["subresource"] == "status" || seen[manager+"/"+operation] {
+			continue
+		}
+		seen[manager+"/"+operation] = true
+		condensed = append(condensed, map[string]interface{}{
+			"manager":   manager,
+			"operation": operation,
+		})
+	}
+	sort.Slice(condensed, func(i, j int) bool {
+		a, b := condensed[i].(map[string]interface{}), condensed[j].(map[string]interface{})
+		if a["manager"] != b["manager"] {
+			return a["manager"].(string) < b["manager"].(string)
+		}
+		return a["operation"].(string) < b["operation"].(string)
+	})
+	if len(condensed) == 0 {
+		delete(metadata, "managedFields")
+		return
+	}
+	metadata["managedFields"] = condensed
+}
+
 // shouldExcludeNamespace checks if a namespace is in the exclusion list.
 func (c *Collector) shouldExcludeNamespace(ns string) bool {
 	for _, excluded := range c.config.Snapshot.ExcludeNamespaces {
@@ -218,8 +497,8 @@ func (c *Collector) shouldIncludeNamespace(ns string) bool {
 	return false
 }
 
-// cleanAnnotations removes noisy annotations from resources.
-func cleanAnnotations(annotations map[string]string) map[string]string {
+// CleanAnnotations removes noisy annotations from resources.
+func CleanAnnotations(annotations map[string]string) map[string]string {
 	if annotations == nil {
 		return nil
 	}
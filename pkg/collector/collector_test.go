@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCondenseManagedFields(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "api",
+			"managedFields": []interface{}{
+				map[string]interface{}{"manager": "kubectl-edit", "operation": "Update", "time": "2024-05-01T10:00:00Z", "fieldsV1": map[string]interface{}{"f:spec": map[string]interface{}{}}},
+				map[string]interface{}{"manager": "helm", "operation": "Update", "time": "2024-04-01T10:00:00Z"},
+				map[string]interface{}{"manager": "kube-controller-manager", "operation": "Update", "subresource": "status"},
+				map[string]interface{}{"manager": "helm", "operation": "Update", "time": "2024-04-02T10:00:00Z"},
+			},
+		},
+	}
+
+	condenseManagedFields(obj)
+
+	metadata := obj["metadata"].(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"manager": "helm", "operation": "Update"},
+		map[string]interface{}{"manager": "kubectl-edit", "operation": "Update"},
+	}, metadata["managedFields"])
+	assert.Equal(t, []string{"helm", "kubectl-edit"}, types.FieldManagers(obj))
+}
+
+func TestCondenseManagedFieldsStatusOnly(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"managedFields": []interface{}{
+				map[string]interface{}{"manager": "kube-controller-manager", "operation": "Update", "subresource": "status"},
+			},
+		},
+	}
+
+	condenseManagedFields(obj)
+
+	assert.NotContains(t, obj["metadata"], "managedFields")
+	assert.Nil(t, types.FieldManagers(obj))
+}
+
+func TestCollect_Partial(t *testing.T) {
+	deploy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "default"},
+	}}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resourceMapping["deployments"]: "DeploymentList",
+		resourceMapping["configmaps"]:  "ConfigMapList",
+	}, deploy)
+	client.PrependReactor("list", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("forbidden")
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Kubeconfig = filepath.Join(t.TempDir(), "missing")
+	cfg.Snapshot.ResourceTypes = []string{"deployments", "configmaps"}
+	c := &Collector{dynamicClient: client, config: cfg, logger: log.StandardLogger()}
+
+	snapshot, err := c.Collect(context.Background())
+	assert.ErrorIs(t, err, types.ErrPartialCollection)
+	assert.ErrorContains(t, err, "configmaps")
+	require.NotNil(t, snapshot)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "default/Deployment/web", snapshot.Resources[0].FullName())
+
+	stats := snapshot.Metadata.Collection
+	require.Len(t, stats, 2)
+	assert.Equal(t, "deployments", stats[0].Resource)
+	assert.Equal(t, 1, stats[0].Count)
+	assert.Equal(t, 1, stats[0].Pages)
+	assert.Empty(t, stats[0].Error)
+	assert.Equal(t, "configmaps", stats[1].Resource)
+	assert.Equal(t, 1, stats[1].Errors)
+	assert.Contains(t, stats[1].Error, "forbidden")
+}
+
+func TestCollect_Concurrent(t *testing.T) {
+	objs := []runtime.Object{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1", "kind": "Deployment",
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "Service",
+			"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+		}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "settings", "namespace": "billing"},
+		}},
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resourceMapping["deployments"]: "DeploymentList",
+		resourceMapping["services"]:    "ServiceList",
+		resourceMapping["configmaps"]:  "ConfigMapList",
+		resourceMapping["secrets"]:     "SecretList",
+	}, objs...)
+
+	cfg := config.DefaultConfig()
+	cfg.Kubeconfig = filepath.Join(t.TempDir(), "missing")
+	cfg.Snapshot.ResourceTypes = []string{"configmaps", "secrets", "deployments", "services", "widgets"}
+	cfg.Snapshot.Concurrency = 4
+	c := &Collector{dynamicClient: client, config: cfg, logger: log.StandardLogger()}
+	var mu sync.Mutex
+	var done []int
+	c.SetProgress(func(_ string, typesDone, typesTotal, _ int) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 5, typesTotal)
+		done = append(done, typesDone)
+	})
+
+	snapshot, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	var names []string
+	for _, r := range snapshot.Resources {
+		names = append(names, r.FullName())
+	}
+	assert.Equal(t, []string{"billing/ConfigMap/settings", "shop/Deployment/web", "shop/Service/web"}, names, "resources follow the configured type order")
+	assert.ElementsMatch(t, []string{"billing", "shop"}, snapshot.Metadata.Namespaces)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, done)
+	var listed []string
+	for _, s := range snapshot.Metadata.Collection {
+		listed = append(listed, s.Resource)
+	}
+	assert.Equal(t, []string{"configmaps", "secrets", "deployments", "services"}, listed, "unknown types are not listed")
+}
+
+func TestKindOf(t *testing.T) {
+	for name := range resourceMapping {
+		_, ok := KindOf(name)
+		assert.True(t, ok, name)
+	}
+	kind, _ := KindOf("networkpolicies")
+	assert.Equal(t, "NetworkPolicy", kind)
+	_, ok := KindOf("widgets")
+	assert.False(t, ok)
+}
+
+func TestValidateConcurrency(t *testing.T) {
+	assert.NoError(t, ValidateConcurrency(1))
+	assert.NoError(t, ValidateConcurrency(16))
+	assert.Error(t, ValidateConcurrency(0))
+}
+
+func TestCollectResource_Pages(t *testing.T) {
+	configMap := func(ns, name string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": name, "namespace": ns},
+		}}
+	}
+	pages := []*unstructured.UnstructuredList{
+		{Items: []unstructured.Unstructured{configMap("shop", "a"), configMap("kube-system", "b")}},
+		{Items: []unstructured.Unstructured{configMap("shop", "c")}},
+	}
+	pages[0].SetContinue("page-2")
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resourceMapping["configmaps"]: "ConfigMapList",
+	})
+	calls := 0
+	client.PrependReactor("list", "configmaps", func(k8stesting.Action) (bool, runtime.Object, error) {
+		calls++
+		switch calls {
+		case 2:
+			// The continue token expired; the listing starts over
+			return true, nil, apierrors.NewResourceExpired("continue token expired")
+		case 1, 3:
+			return true, pages[0], nil
+		default:
+			return true, pages[1], nil
+		}
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.PageSize = 2
+	c := &Collector{dynamicClient: client, config: cfg, logger: log.StandardLogger()}
+
+	var stats types.CollectionStats
+	resources, err := c.collectResource(context.Background(), resourceMapping["configmaps"], &stats)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 3, stats.Pages, "pages listed before the restart count too")
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 1, stats.Retries)
+	var names []string
+	for _, r := range resources {
+		names = append(names, r.FullName())
+	}
+	assert.Equal(t, []string{"shop/ConfigMap/a", "shop/ConfigMap/c"}, names, "excluded namespaces are dropped page by page")
+}
+
+func TestValidatePageSize(t *testing.T) {
+	assert.NoError(t, ValidatePageSize(0))
+	assert.NoError(t, ValidatePageSize(500))
+	assert.Error(t, ValidatePageSize(-1))
+}
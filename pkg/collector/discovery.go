@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// coreGroup names the core ("") API group in discovery patterns.
+const coreGroup = "core"
+
+// resourceType is a resource type to collect, by its configured or
+// discovered name.
+type resourceType struct {
+	name string
+	gvr  schema.GroupVersionResource
+	// known is false for configured names with no mapping.
+	known bool
+}
+
+// ValidateDiscovery checks that the discovery patterns are valid globs of
+// at most group/version/resource.
+func ValidateDiscovery(cfg *config.DiscoveryConfig) error {
+	for _, patterns := range [][]string{cfg.Include, cfg.Exclude} {
+		for _, p := range patterns {
+			if strings.Count(p, "/") > 2 {
+				return fmt.Errorf("invalid snapshot.discovery pattern %q: use group, group/resource, or group/version/resource", p)
+			}
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid snapshot.discovery pattern %q: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceTypes returns the types to collect: those discovered in discovery
+// mode, otherwise the configured ones. With some API groups failing
+// discovery (e.g. an unavailable aggregated API), the types of the others
+// are returned with an error naming the failed groups.
+func (c *Collector) resourceTypes() ([]resourceType, error) {
+	if !c.config.Snapshot.Discovery.Enabled {
+		var list []resourceType
+		for _, name := range c.config.Snapshot.ResourceTypes {
+			gvr, ok := resourceMapping[name]
+			list = append(list, resourceType{name: name, gvr: gvr, known: ok})
+		}
+		return list, nil
+	}
+
+	lists, err := discovery.ServerPreferredResources(c.discoveryClient)
+	var failedGroups []string
+	if err != nil {
+		groups, ok := discovery.GroupDiscoveryFailedErrorGroups(err)
+		if !ok {
+			return nil, fmt.Errorf("failed to discover resource types: %w", err)
+		}
+		for gv := range groups {
+			failedGroups = append(failedGroups, gv.String())
+		}
+		sort.Strings(failedGroups)
+	}
+	list := discoveredTypes(&c.config.Snapshot.Discovery, lists)
+	if len(failedGroups) > 0 {
+		return list, fmt.Errorf("failed to discover %s", strings.Join(failedGroups, ", "))
+	}
+	return list, nil
+}
+
+// discoveredTypes returns the listable, top-level resource types of the
+// discovered lists that the include and exclude patterns select, sorted by
+// name.
+func discoveredTypes(cfg *config.DiscoveryConfig, lists []*metav1.APIResourceList) []resourceType {
+	var list []resourceType
+	seen := make(map[string]bool)
+	for _, l := range lists {
+		gv, err := schema.ParseGroupVersion(l.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range l.APIResources {
+			// Subresources such as deployments/scale are part of their resource
+			if strings.Contains(r.Name, "/") || !containsVerb(r.Verbs, collectVerb) {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			if len(cfg.Include) > 0 && !matchesAnyGVR(cfg.Include, gvr) {
+				continue
+			}
+			if matchesAnyGVR(cfg.Exclude, gvr) {
+				continue
+			}
+			name := typeName(gvr)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			list = append(list, resourceType{name: name, gvr: gvr, known: true})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+// typeName names a discovered type as resource_types does for the types
+// the collector maps, and as resource.group (e.g.
+// certificates.cert-manager.io) otherwise.
+func typeName(gvr schema.GroupVersionResource) string {
+	for name, mapped := range resourceMapping {
+		if mapped.Group == gvr.Group && mapped.Resource == gvr.Resource {
+			return name
+		}
+	}
+	if gvr.Group == "" {
+		return gvr.Resource
+	}
+	return gvr.Resource + "." + gvr.Group
+}
+
+// matchesAnyGVR reports whether any pattern matches a resource type.
+func matchesAnyGVR(patterns []string, gvr schema.GroupVersionResource) bool {
+	for _, p := range patterns {
+		if matchGVR(p, gvr) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGVR matches group, group/resource, or group/version/resource globs.
+func matchGVR(pattern string, gvr schema.GroupVersionResource) bool {
+	group := gvr.Group
+	if group == "" {
+		group = coreGroup
+	}
+	var parts, values []string
+	switch parts = strings.Split(pattern, "/"); len(parts) {
+	case 1:
+		values = []string{group}
+	case 2:
+		values = []string{group, gvr.Resource}
+	case 3:
+		values = []string{group, gvr.Version, gvr.Resource}
+	default:
+		return false
+	}
+	for i := range parts {
+		if ok, err := path.Match(parts[i], values[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
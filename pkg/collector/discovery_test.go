@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	certificates = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	rollouts     = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+)
+
+// servedResources is what the fake API server serves.
+func servedResources() []*metav1.APIResourceList {
+	list := metav1.Verbs{"get", "list", "watch"}
+	return []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: list},
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: list},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+			{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: metav1.Verbs{"create"}},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: list},
+			{Name: "deployments/scale", Kind: "Scale", Namespaced: true, Verbs: metav1.Verbs{"get", "update"}},
+			{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true, Verbs: list},
+		}},
+		{GroupVersion: "cert-manager.io/v1", APIResources: []metav1.APIResource{
+			{Name: "certificates", Kind: "Certificate", Namespaced: true, Verbs: list},
+		}},
+		{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{
+			{Name: "rollouts", Kind: "Rollout", Namespaced: true, Verbs: list},
+		}},
+	}
+}
+
+func typeNames(list []resourceType) []string {
+	var names []string
+	for _, rt := range list {
+		names = append(names, rt.name)
+	}
+	return names
+}
+
+func TestDiscoveredTypes(t *testing.T) {
+	cfg := config.DefaultConfig().Snapshot.Discovery
+	assert.Equal(t, []string{"certificates.cert-manager.io", "configmaps", "deployments", "rollouts.argoproj.io"},
+		typeNames(discoveredTypes(&cfg, servedResources())), "pods and replicasets are excluded by default")
+
+	cfg.Include = []string{"cert-manager.io", "argoproj.io/v1*/rollouts"}
+	assert.Equal(t, []string{"certificates.cert-manager.io", "rollouts.argoproj.io"}, typeNames(discoveredTypes(&cfg, servedResources())))
+
+	cfg.Include = nil
+	cfg.Exclude = []string{"core", "*/deployments"}
+	assert.Equal(t, []string{"certificates.cert-manager.io", "replicasets.apps", "rollouts.argoproj.io"}, typeNames(discoveredTypes(&cfg, servedResources())))
+}
+
+func TestValidateDiscovery(t *testing.T) {
+	assert.NoError(t, ValidateDiscovery(&config.DefaultConfig().Snapshot.Discovery))
+	assert.Error(t, ValidateDiscovery(&config.DiscoveryConfig{Include: []string{"a/b/c/d"}}))
+	assert.Error(t, ValidateDiscovery(&config.DiscoveryConfig{Exclude: []string{"[core"}}))
+}
+
+func TestCollect_Discovery(t *testing.T) {
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1", "kind": "Certificate",
+		"metadata": map[string]interface{}{"name": "api-tls", "namespace": "prod"},
+		"spec":     map[string]interface{}{"secretName": "api-tls"},
+	}}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resourceMapping["configmaps"]:  "ConfigMapList",
+		resourceMapping["deployments"]: "DeploymentList",
+		certificates:                   "CertificateList",
+		rollouts:                       "RolloutList",
+	}, cert)
+	disco := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: servedResources()}}
+
+	cfg := config.DefaultConfig()
+	cfg.Kubeconfig = filepath.Join(t.TempDir(), "missing")
+	cfg.Snapshot.Discovery.Enabled = true
+	c := &Collector{dynamicClient: client, discoveryClient: disco, config: cfg, logger: log.StandardLogger()}
+
+	var collected []string
+	c.SetProgress(func(resourceType string, typesDone, typesTotal, resources int) {
+		collected = append(collected, resourceType)
+	})
+	snapshot, err := c.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, collected, 4)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "prod/Certificate/api-tls", snapshot.Resources[0].FullName())
+	assert.Equal(t, map[string]int{"Certificate": 1}, snapshot.Metadata.KindCounts)
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ValidatePrune checks that every snapshot.prune rule names a kind and
+// that its paths can be parsed and name map fields, not list elements.
+func ValidatePrune(rules []config.PruneRule) error {
+	for i, rule := range rules {
+		if rule.Kind == "" {
+			return fmt.Errorf("snapshot.prune[%d]: kind is required", i)
+		}
+		for _, p := range rule.Paths {
+			keys, err := ignore.ParsePath(p)
+			if err != nil {
+				return fmt.Errorf("snapshot.prune[%d] (%s): %w", i, rule.Kind, err)
+			}
+			if _, ok := fieldNames(keys); !ok {
+				return fmt.Errorf("snapshot.prune[%d] (%s): path %q selects a list element; prune whole fields instead", i, rule.Kind, p)
+			}
+		}
+	}
+	return nil
+}
+
+// prune removes the fields that snapshot.prune rules configure for the
+// item's kind and returns how many were present.
+func prune(item *unstructured.Unstructured, rules []config.PruneRule) int {
+	if len(rules) == 0 {
+		return 0
+	}
+	gvk := item.GroupVersionKind()
+	pruned := 0
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Kind, gvk.Kind) || (rule.Group != "" && !strings.EqualFold(rule.Group, gvk.Group)) {
+			continue
+		}
+		for _, p := range rule.Paths {
+			// Validated when the config was loaded
+			parsed, err := ignore.ParsePath(p)
+			if err != nil {
+				continue
+			}
+			keys, ok := fieldNames(parsed)
+			if !ok {
+				continue
+			}
+			if _, found, _ := unstructured.NestedFieldNoCopy(item.Object, keys...); found {
+				unstructured.RemoveNestedField(item.Object, keys...)
+				pruned++
+			}
+		}
+	}
+	return pruned
+}
+
+// fieldNames returns the names of a path's keys, and false if it selects a
+// list element.
+func fieldNames(keys []ignore.Key) ([]string, bool) {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Element {
+			return nil, false
+		}
+		names[i] = key.Name
+	}
+	return names, true
+}
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrune(t *testing.T) {
+	bundle := func(apiVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion, "kind": "Bundle",
+			"metadata": map[string]interface{}{"name": "ca"},
+			"spec": map[string]interface{}{
+				"sources": []interface{}{map[string]interface{}{"inLine": "-----BEGIN CERTIFICATE-----"}},
+				"target":  map[string]interface{}{"configMap": map[string]interface{}{"key": "ca.crt"}},
+			},
+		}}
+	}
+	rules := []config.PruneRule{{Kind: "bundle", Group: "trust.cert-manager.io", Paths: []string{".spec.sources", ".spec.missing"}}}
+
+	item := bundle("trust.cert-manager.io/v1alpha1")
+	assert.Equal(t, 1, prune(item, rules))
+	assert.Equal(t, map[string]interface{}{
+		"target": map[string]interface{}{"configMap": map[string]interface{}{"key": "ca.crt"}},
+	}, item.Object["spec"])
+
+	other := bundle("example.com/v1")
+	assert.Equal(t, 0, prune(other, rules), "kinds of another group are kept")
+	assert.Contains(t, other.Object["spec"], "sources")
+}
+
+func TestValidatePrune(t *testing.T) {
+	assert.NoError(t, ValidatePrune([]config.PruneRule{{Kind: "Bundle", Paths: []string{".spec.sources", `.spec["ca.crt"]`}}}))
+	assert.Error(t, ValidatePrune([]config.PruneRule{{Paths: []string{".spec.sources"}}}))
+	assert.Error(t, ValidatePrune([]config.PruneRule{{Kind: "Bundle", Paths: []string{"spec"}}}))
+	assert.Error(t, ValidatePrune([]config.PruneRule{{Kind: "Bundle", Paths: []string{".spec.sources[0]"}}}))
+}
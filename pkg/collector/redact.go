@@ -0,0 +1,103 @@
+package collector
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RedactedValue replaces the values of redacted environment variables.
+const RedactedValue = "[REDACTED]"
+
+// ValidateRedactEnv checks that env var redaction patterns are valid globs.
+func ValidateRedactEnv(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid snapshot.redact_env pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// redactEnv replaces the values of env vars whose names match any of the
+// glob patterns (case-insensitively) in every container of the object's pod
+// template, and returns how many were replaced. Values taken from
+// secretKeyRef or configMapKeyRef hold no secret themselves and are kept.
+func redactEnv(obj map[string]interface{}, patterns []string) int {
+	if len(patterns) == 0 {
+		return 0
+	}
+	redacted := 0
+	for _, envVar := range podEnv(obj) {
+		name, _ := envVar["name"].(string)
+		if _, ok := envVar["value"]; ok && matchesAny(name, patterns) {
+			envVar["value"] = RedactedValue
+			redacted++
+		}
+	}
+	return redacted
+}
+
+// HasRedactedEnv reports whether any env var in the object's pod template
+// holds RedactedValue, i.e. the object cannot be applied as stored.
+func HasRedactedEnv(obj map[string]interface{}) bool {
+	for _, envVar := range podEnv(obj) {
+		if envVar["value"] == RedactedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// podEnv returns the env var entries of every container in the object's pod
+// template, for in-place modification.
+func podEnv(obj map[string]interface{}) []map[string]interface{} {
+	var vars []map[string]interface{}
+	for _, podSpec := range podSpecs(obj) {
+		for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, _ := podSpec[field].([]interface{})
+			for _, c := range containers {
+				container, _ := c.(map[string]interface{})
+				env, _ := container["env"].([]interface{})
+				for _, e := range env {
+					if envVar, ok := e.(map[string]interface{}); ok {
+						vars = append(vars, envVar)
+					}
+				}
+			}
+		}
+	}
+	return vars
+}
+
+// podSpecs returns the pod specs embedded in a workload object: the pod's own
+// spec, a pod template (Deployment, StatefulSet, DaemonSet, ReplicaSet, Job),
+// or a CronJob's job template.
+func podSpecs(obj map[string]interface{}) []map[string]interface{} {
+	spec, _ := obj["spec"].(map[string]interface{})
+	if spec == nil {
+		return nil
+	}
+	if kind, _ := obj["kind"].(string); kind == "Pod" {
+		return []map[string]interface{}{spec}
+	}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		spec, _ = jobTemplate["spec"].(map[string]interface{})
+	}
+	template, _ := spec["template"].(map[string]interface{})
+	if podSpec, ok := template["spec"].(map[string]interface{}); ok {
+		return []map[string]interface{}{podSpec}
+	}
+	return nil
+}
+
+// matchesAny reports whether name matches one of the glob patterns, ignoring case.
+func matchesAny(name string, patterns []string) bool {
+	upper := strings.ToUpper(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToUpper(p), upper); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func container(env ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": "app", "env": env}
+}
+
+func TestRedactEnv(t *testing.T) {
+	patterns := []string{"*_PASSWORD", "*_token"}
+	deployment := map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"initContainers": []interface{}{container(
+						map[string]interface{}{"name": "DB_PASSWORD", "value": "hunter2"},
+					)},
+					"containers": []interface{}{container(
+						map[string]interface{}{"name": "API_TOKEN", "value": "abc"},
+						map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+						map[string]interface{}{"name": "REF_PASSWORD", "valueFrom": map[string]interface{}{
+							"secretKeyRef": map[string]interface{}{"name": "db", "key": "password"},
+						}},
+					)},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, 2, redactEnv(deployment, patterns))
+
+	podSpec := podSpecs(deployment)[0]
+	initEnv := podSpec["initContainers"].([]interface{})[0].(map[string]interface{})["env"].([]interface{})
+	assert.Equal(t, RedactedValue, initEnv[0].(map[string]interface{})["value"])
+
+	env := podSpec["containers"].([]interface{})[0].(map[string]interface{})["env"].([]interface{})
+	assert.Equal(t, RedactedValue, env[0].(map[string]interface{})["value"])
+	assert.Equal(t, "debug", env[1].(map[string]interface{})["value"])
+	assert.NotContains(t, env[2], "value")
+
+	assert.True(t, HasRedactedEnv(deployment))
+	assert.False(t, HasRedactedEnv(map[string]interface{}{"kind": "ConfigMap"}))
+}
+
+func TestPodSpecs(t *testing.T) {
+	podSpec := map[string]interface{}{"containers": []interface{}{}}
+
+	cronJob := map[string]interface{}{"kind": "CronJob", "spec": map[string]interface{}{
+		"jobTemplate": map[string]interface{}{"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": podSpec},
+		}},
+	}}
+	assert.Len(t, podSpecs(cronJob), 1)
+
+	pod := map[string]interface{}{"kind": "Pod", "spec": podSpec}
+	assert.Len(t, podSpecs(pod), 1)
+
+	assert.Empty(t, podSpecs(map[string]interface{}{"kind": "ConfigMap"}))
+}
+
+func TestValidateRedactEnv(t *testing.T) {
+	assert.NoError(t, ValidateRedactEnv([]string{"*_PASSWORD", "SECRET_?"}))
+	assert.Error(t, ValidateRedactEnv([]string{"[abc"}))
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"k8s.io/client-go/rest"
+)
+
+// applyClientConfig applies the configured proxy and extra CA bundle to a
+// client configuration built from the kubeconfig. A cluster that relies on
+// the system roots trusts only the extra bundle once one is set.
+func applyClientConfig(restConfig *rest.Config, cfg *config.ClientConfig) error {
+	switch {
+	case cfg.ProxyURL != "":
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("invalid client.proxy_url %q", cfg.ProxyURL)
+		}
+		restConfig.Proxy = http.ProxyURL(proxyURL)
+	case restConfig.Proxy == nil && !cfg.UseEnvProxy:
+		// client-go falls back to the environment when Proxy is unset
+		restConfig.Proxy = func(*http.Request) (*url.URL, error) { return nil, nil }
+	}
+
+	if cfg.CAFile != "" {
+		extra, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client.ca_file: %w", err)
+		}
+
+		tls := &restConfig.TLSClientConfig
+		caData := tls.CAData
+		if len(caData) == 0 && tls.CAFile != "" {
+			caData, err = os.ReadFile(tls.CAFile)
+			if err != nil {
+				return fmt.Errorf("failed to read cluster CA: %w", err)
+			}
+		}
+		if len(caData) > 0 {
+			caData = append(append(caData, '\n'), extra...)
+		} else {
+			caData = extra
+		}
+		tls.CAData = caData
+		tls.CAFile = ""
+	}
+	return nil
+}
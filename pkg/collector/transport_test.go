@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyClientConfig_ProxyURL(t *testing.T) {
+	restConfig := &rest.Config{}
+	require.NoError(t, applyClientConfig(restConfig, &config.ClientConfig{ProxyURL: "http://proxy.corp:3128"}))
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.cluster:6443", nil)
+	proxy, err := restConfig.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "proxy.corp:3128", proxy.Host)
+
+	assert.Error(t, applyClientConfig(&rest.Config{}, &config.ClientConfig{ProxyURL: "proxy.corp"}))
+}
+
+func TestApplyClientConfig_EnvProxy(t *testing.T) {
+	restConfig := &rest.Config{}
+	require.NoError(t, applyClientConfig(restConfig, &config.ClientConfig{UseEnvProxy: true}))
+	assert.Nil(t, restConfig.Proxy, "client-go falls back to the environment")
+
+	require.NoError(t, applyClientConfig(restConfig, &config.ClientConfig{}))
+	require.NotNil(t, restConfig.Proxy)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.cluster:6443", nil)
+	proxy, err := restConfig.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+}
+
+func TestApplyClientConfig_CAFile(t *testing.T) {
+	dir := t.TempDir()
+	extra := filepath.Join(dir, "extra.pem")
+	cluster := filepath.Join(dir, "cluster.pem")
+	require.NoError(t, os.WriteFile(extra, []byte("EXTRA"), 0644))
+	require.NoError(t, os.WriteFile(cluster, []byte("CLUSTER"), 0644))
+
+	restConfig := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAFile: cluster}}
+	require.NoError(t, applyClientConfig(restConfig, &config.ClientConfig{CAFile: extra}))
+	assert.Equal(t, "CLUSTER\nEXTRA", string(restConfig.CAData))
+	assert.Empty(t, restConfig.CAFile)
+
+	restConfig = &rest.Config{}
+	require.NoError(t, applyClientConfig(restConfig, &config.ClientConfig{CAFile: extra}))
+	assert.Equal(t, "EXTRA", string(restConfig.CAData))
+
+	assert.Error(t, applyClientConfig(&rest.Config{}, &config.ClientConfig{CAFile: filepath.Join(dir, "missing.pem")}))
+}
@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Change is an object that was added, deleted, or changed in a field that
+// a snapshot captures.
+type Change struct {
+	Type     types.DriftType
+	Resource types.Resource
+}
+
+// Watch watches the collected resource types and calls onChange for every
+// change to an object a snapshot would capture. Updates that only touch
+// stripped fields (e.g. .status or .metadata.resourceVersion) are ignored,
+// as are the objects that exist when watching starts. onChange is called
+// from informer goroutines and must not block. Watch returns once the
+// informers have synced, or after cluster_timeout, and they run until ctx
+// is cancelled.
+func (c *Collector) Watch(ctx context.Context, onChange func(Change)) error {
+	resTypes, err := c.resourceTypes()
+	if err != nil {
+		if resTypes == nil {
+			return err
+		}
+		c.logger.WithError(err).Warn("some API groups could not be discovered, not watching them")
+	}
+
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(c.dynamicClient, 0)
+	notify := func(t types.DriftType, obj interface{}) {
+		if res, ok := c.watchedResource(obj); ok {
+			onChange(Change{Type: t, Resource: res})
+		}
+	}
+	for _, rt := range resTypes {
+		if !rt.known {
+			c.logger.WithField("resource", rt.name).Warn("unknown resource type, not watching it")
+			continue
+		}
+		_, err := factory.ForResource(rt.gvr).Informer().AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj interface{}, isInInitialList bool) {
+				if !isInInitialList {
+					notify(types.DriftAdded, obj)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				if c.capturedChange(oldObj, newObj) {
+					notify(types.DriftModified, newObj)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				notify(types.DriftRemoved, obj)
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", rt.name, err)
+		}
+	}
+
+	factory.Start(ctx.Done())
+	// Types that cannot be listed keep retrying in the background
+	syncCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.config.ClusterTimeout > 0 {
+		syncCtx, cancel = context.WithTimeout(ctx, c.config.ClusterTimeout)
+	}
+	defer cancel()
+	for gvr, synced := range factory.WaitForCacheSync(syncCtx.Done()) {
+		if !synced {
+			c.logger.WithField("resource", gvr.String()).Warn("watch not synced yet, changes to it are only seen by scheduled snapshots until it is")
+		}
+	}
+	c.logger.WithField("types", len(resTypes)).Info("watching resources for changes")
+	return nil
+}
+
+// watchedResource returns the resource of an informer object, if it is in
+// a collected namespace.
+func (c *Collector) watchedResource(obj interface{}) (types.Resource, bool) {
+	item, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return types.Resource{}, false
+	}
+	ns := item.GetNamespace()
+	if c.shouldExcludeNamespace(ns) {
+		return types.Resource{}, false
+	}
+	if len(c.config.Snapshot.Namespaces) > 0 && !c.shouldIncludeNamespace(ns) {
+		return types.Resource{}, false
+	}
+	return types.Resource{
+		APIVersion: item.GetAPIVersion(),
+		Kind:       item.GetKind(),
+		Namespace:  ns,
+		Name:       item.GetName(),
+	}, true
+}
+
+// capturedChange reports whether an update changed the object as a
+// snapshot captures it. Informer objects are shared, so copies are cleaned.
+func (c *Collector) capturedChange(oldObj, newObj interface{}) bool {
+	oldItem, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	newItem, ok := newObj.(*unstructured.Unstructured)
+	if !ok {
+		return true
+	}
+	if oldItem.GetResourceVersion() == newItem.GetResourceVersion() {
+		return false
+	}
+	oldItem, newItem = oldItem.DeepCopy(), newItem.DeepCopy()
+	Clean(oldItem, &c.config.Snapshot)
+	Clean(newItem, &c.config.Snapshot)
+	for _, item := range []*unstructured.Unstructured{oldItem, newItem} {
+		// Dropped here even when kept in snapshots: it changes on every update
+		unstructured.RemoveNestedField(item.Object, "metadata", "resourceVersion")
+	}
+	return !reflect.DeepEqual(oldItem.Object, newItem.Object)
+}
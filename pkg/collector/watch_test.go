@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func configMap(name, namespace, resourceVersion string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap",
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace, "resourceVersion": resourceVersion},
+		"data":     data,
+	}}
+}
+
+func TestCapturedChange(t *testing.T) {
+	c := &Collector{config: config.DefaultConfig(), logger: log.StandardLogger()}
+	old := configMap("app", "default", "1", map[string]interface{}{"mode": "a"})
+
+	withStatus := configMap("app", "default", "2", map[string]interface{}{"mode": "a"})
+	withStatus.Object["status"] = map[string]interface{}{"observed": int64(2)}
+	assert.False(t, c.capturedChange(old, withStatus))
+
+	assert.True(t, c.capturedChange(old, configMap("app", "default", "3", map[string]interface{}{"mode": "b"})))
+	assert.False(t, c.capturedChange(old, old.DeepCopy()))
+}
+
+func TestWatch(t *testing.T) {
+	gvr := resourceMapping["configmaps"]
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr: "ConfigMapList",
+	}, configMap("existing", "default", "1", nil))
+
+	cfg := config.DefaultConfig()
+	cfg.Kubeconfig = filepath.Join(t.TempDir(), "missing")
+	cfg.Snapshot.ResourceTypes = []string{"configmaps"}
+	c := &Collector{dynamicClient: client, config: cfg, logger: log.StandardLogger()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan Change, 10)
+	require.NoError(t, c.Watch(ctx, func(ch Change) { changes <- ch }))
+
+	_, err := client.Resource(gvr).Namespace("kube-system").Create(ctx, configMap("ignored", "kube-system", "", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = client.Resource(gvr).Namespace("default").Create(ctx, configMap("new", "default", "", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case ch := <-changes:
+		assert.Equal(t, types.DriftAdded, ch.Type)
+		assert.Equal(t, "default/ConfigMap/new", ch.Resource.FullName())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change seen")
+	}
+	select {
+	case ch := <-changes:
+		t.Fatalf("unexpected change %v", ch)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
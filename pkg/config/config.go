@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,19 +15,152 @@ import (
 type Config struct {
 	Kubeconfig string          `mapstructure:"kubeconfig"`
 	Context    string          `mapstructure:"context"`
-	Snapshot   SnapshotConfig  `mapstructure:"snapshot"`
-	Git        GitConfig       `mapstructure:"git"`
-	Watch      WatchConfig     `mapstructure:"watch"`
-	Log        LogConfig       `mapstructure:"log"`
+	Client     ClientConfig    `mapstructure:"client"`
+	Clusters   []ClusterConfig `mapstructure:"clusters"`
+	Profiles   []ProfileConfig `mapstructure:"profiles"`
+	// ClusterTimeout bounds the collection of each fleet cluster.
+	ClusterTimeout time.Duration       `mapstructure:"cluster_timeout"`
+	Snapshot       SnapshotConfig      `mapstructure:"snapshot"`
+	Git            GitConfig           `mapstructure:"git"`
+	Watch          WatchConfig         `mapstructure:"watch"`
+	Log            LogConfig           `mapstructure:"log"`
+	Ownership      OwnershipConfig     `mapstructure:"ownership"`
+	Tenancy        TenancyConfig       `mapstructure:"tenancy"`
+	Suppression    SuppressionConfig   `mapstructure:"suppression"`
+	IgnoreManaged  IgnoreManagedConfig `mapstructure:"ignore_managed"`
+	Ignore         IgnoreConfig        `mapstructure:"ignore"`
+	Hooks          HooksConfig         `mapstructure:"hooks"`
+	Evidence       EvidenceConfig      `mapstructure:"evidence"`
+	Orphans        OrphansConfig       `mapstructure:"orphans"`
+	ImagePolicy    ImagePolicyConfig   `mapstructure:"image_policy"`
+	Expiry         ExpiryConfig        `mapstructure:"expiry"`
+	Report         ReportConfig        `mapstructure:"report"`
+	Notifiers      NotifiersConfig     `mapstructure:"notifiers"`
+	Serve          ServeConfig         `mapstructure:"serve"`
+	Audit          AuditConfig         `mapstructure:"audit"`
+}
+
+// ClientConfig adjusts how the Kubernetes client connects, for networks
+// where the kubeconfig alone is not enough.
+type ClientConfig struct {
+	// ProxyURL sends API requests through this proxy (http, https, or
+	// socks5), overriding the kubeconfig's proxy-url and the environment.
+	ProxyURL string `mapstructure:"proxy_url"`
+	// UseEnvProxy honors HTTP_PROXY, HTTPS_PROXY, and NO_PROXY when no
+	// other proxy is set. Disable it to connect directly.
+	UseEnvProxy bool `mapstructure:"use_env_proxy"`
+	// CAFile is a PEM bundle trusted in addition to the cluster's CA, e.g.
+	// for a TLS-inspecting corporate proxy.
+	CAFile string `mapstructure:"ca_file"`
+}
+
+// ClusterConfig is one cluster of a fleet captured by a single process.
+// Each cluster is written to its own top-level directory named Name.
+type ClusterConfig struct {
+	Name    string `mapstructure:"name"`
+	Context string `mapstructure:"context"`
+	// Kubeconfig overrides the top-level kubeconfig for this cluster.
+	Kubeconfig string `mapstructure:"kubeconfig"`
+}
+
+// ProfileConfig is an environment selected with --profile. Each profile
+// has its own snapshot repository, so environments sharing a config file
+// never commit into each other's history.
+type ProfileConfig struct {
+	Name       string `mapstructure:"name"`
+	Context    string `mapstructure:"context"`
+	Kubeconfig string `mapstructure:"kubeconfig"`
+	// OutputDir replaces snapshot.output_dir.
+	OutputDir string `mapstructure:"output_dir"`
+	// RemoteURL replaces git.remote_url.
+	RemoteURL string `mapstructure:"remote_url"`
 }
 
 // SnapshotConfig configures what resources to capture.
 type SnapshotConfig struct {
-	OutputDir         string   `mapstructure:"output_dir"`
-	ResourceTypes     []string `mapstructure:"resource_types"`
+	OutputDir     string   `mapstructure:"output_dir"`
+	ResourceTypes []string `mapstructure:"resource_types"`
+	// Discovery collects the resource types the API server serves instead
+	// of ResourceTypes, so that Custom Resources are captured too.
+	Discovery DiscoveryConfig `mapstructure:"discovery"`
+	// Concurrency is how many resource types are listed at once.
+	Concurrency int `mapstructure:"concurrency"`
+	// PageSize is how many objects are requested per list call, so that
+	// types with tens of thousands of objects are listed in pages rather
+	// than in one response. Zero lists each type in one call.
+	PageSize          int      `mapstructure:"page_size"`
 	Namespaces        []string `mapstructure:"namespaces"`
 	ExcludeNamespaces []string `mapstructure:"exclude_namespaces"`
 	StripFields       []string `mapstructure:"strip_fields"`
+	// RedactEnv lists glob patterns (e.g. *_PASSWORD) of env var names
+	// whose literal values in pod templates are replaced before writing.
+	RedactEnv []string `mapstructure:"redact_env"`
+	// Prune removes fields at collection, e.g. generated certificates or
+	// encoded bundles that some CRDs embed in their spec, so that they
+	// neither bloat nor churn every snapshot.
+	Prune       []PruneRule       `mapstructure:"prune"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	// BlobThresholdBytes moves ConfigMap/Secret values larger than this
+	// (and all binary values) into content-addressed _blobs/ files.
+	// Zero disables externalization.
+	BlobThresholdBytes int `mapstructure:"blob_threshold_bytes"`
+	// SkipUnchanged skips writing and committing a snapshot whose resources
+	// are the same as in the last commit, so that a stable cluster does not
+	// add a commit, and rewrite every file, on each run.
+	SkipUnchanged bool `mapstructure:"skip_unchanged"`
+	// IncludeConfig writes the effective configuration, with secrets
+	// redacted, to _config/config.yaml in every snapshot.
+	IncludeConfig bool `mapstructure:"include_config"`
+	// TrackLifecycle keeps .metadata.uid and .metadata.creationTimestamp
+	// (even if listed in strip_fields) so that deleted-and-recreated
+	// resources are reported as RECREATED rather than MODIFIED.
+	TrackLifecycle bool `mapstructure:"track_lifecycle"`
+	// TrackFieldManagers keeps the manager and operation of each
+	// .metadata.managedFields entry (even if listed in strip_fields) so
+	// ownership can be reported and changes of owner detected.
+	TrackFieldManagers bool `mapstructure:"track_field_managers"`
+	// AllowEmpty commits snapshots without resources. Otherwise they are
+	// refused, since an empty snapshot usually means the collector lost
+	// access rather than that the cluster is empty.
+	AllowEmpty bool `mapstructure:"allow_empty"`
+	// MaxSizeBytes refuses to commit a snapshot whose files add up to
+	// more than this; zero disables the limit.
+	MaxSizeBytes int64 `mapstructure:"max_size_bytes"`
+}
+
+// PruneRule removes fields from every resource of a kind.
+type PruneRule struct {
+	// Kind matches case-insensitively.
+	Kind string `mapstructure:"kind"`
+	// Group optionally tells apart kinds of the same name, e.g.
+	// trust.cert-manager.io.
+	Group string `mapstructure:"group"`
+	// Paths use the syntax of ignore.fields, e.g. .spec.sources or
+	// .spec["ca.crt"].
+	Paths []string `mapstructure:"paths"`
+}
+
+// DiscoveryConfig selects the resource types collected in discovery mode.
+// Patterns are globs over group/version/resource, the core group being
+// "core": "cert-manager.io" matches a group, "argoproj.io/rollouts" a
+// resource of a group, and "networking.istio.io/v1beta1/*" one version of
+// a group. Only the version the server prefers is collected.
+type DiscoveryConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Include limits collection to types matching any pattern; empty
+	// includes every type that can be listed.
+	Include []string `mapstructure:"include"`
+	// Exclude skips types matching any pattern, e.g. objects owned by
+	// controllers such as pods.
+	Exclude []string `mapstructure:"exclude"`
+}
+
+// CompressionConfig configures compression of large resource files.
+type CompressionConfig struct {
+	// Algorithm is "" (disabled) or "gzip".
+	Algorithm string `mapstructure:"algorithm"`
+	// ThresholdBytes is the encoded size above which a file is compressed.
+	ThresholdBytes int `mapstructure:"threshold_bytes"`
 }
 
 // GitConfig configures the snapshot Git repository.
@@ -35,24 +169,475 @@ type GitConfig struct {
 	AuthorEmail         string `mapstructure:"author_email"`
 	CommitMessagePrefix string `mapstructure:"commit_message_prefix"`
 	Branch              string `mapstructure:"branch"`
+	// RemoteURL, if set, is the URL the snapshot repository's remote
+	// (links.remote) must have. A new repository gets the remote; an
+	// existing one whose remote has another URL is refused.
+	RemoteURL string      `mapstructure:"remote_url"`
+	Links     LinksConfig `mapstructure:"links"`
+	// Push pushes the branch to the remote (links.remote) after every
+	// snapshot commit, rebasing onto commits other writers pushed.
+	Push bool          `mapstructure:"push"`
+	Auth GitAuthConfig `mapstructure:"auth"`
+	// Clone limits what is downloaded when --repo names a URL.
+	Clone CloneConfig `mapstructure:"clone"`
+}
+
+// CloneConfig limits the local copy of a --repo URL, for analysis of a
+// large snapshot repository on a laptop.
+type CloneConfig struct {
+	// Depth fetches only this many of the latest commits; 0 fetches the
+	// whole history. Older commits cannot be read from the copy.
+	Depth int `mapstructure:"depth"`
+	// Paths checks out only these repository directories (a sparse
+	// checkout), e.g. one team's or cluster's; empty checks out everything.
+	// History is read from commits, so it is not limited by Paths.
+	Paths []string `mapstructure:"paths"`
+}
+
+// GitAuthConfig holds the credentials for fetching from and pushing to the
+// remote. At most one of SSHKey, Token, and Password may be set; with none,
+// SSH remotes use the SSH agent.
+type GitAuthConfig struct {
+	// Username defaults to "git" for SSH keys and tokens.
+	Username string `mapstructure:"username"`
+	// SSHKey is the path of a private key file.
+	SSHKey           string `mapstructure:"ssh_key"`
+	SSHKeyPassphrase string `mapstructure:"ssh_key_passphrase" secret:"true"`
+	// Token is an HTTPS access token, e.g. a GitHub personal access token.
+	Token    string `mapstructure:"token" secret:"true"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+// LinksConfig builds links to snapshot commits and files in a Git forge.
+// Templates may use {commit}, {short} (8 characters), and {path}. If
+// unset, they are derived from the remote's URL for GitHub and GitLab.
+type LinksConfig struct {
+	// Remote is the remote of the snapshot repository to derive links from.
+	Remote string `mapstructure:"remote"`
+	// Commit is the URL template of a commit, e.g.
+	// https://github.com/acme/snapshots/commit/{commit}.
+	Commit string `mapstructure:"commit"`
+	// File is the URL template of a file at a commit, e.g.
+	// https://github.com/acme/snapshots/blob/{commit}/{path}.
+	File string `mapstructure:"file"`
 }
 
 // WatchConfig configures scheduled/continuous snapshots.
 type WatchConfig struct {
-	Schedule          string `mapstructure:"schedule"`
-	EnableWatchEvents bool   `mapstructure:"enable_watch_events"`
+	Schedule string `mapstructure:"schedule"`
+	Timezone string `mapstructure:"timezone"`
+	// EnableWatchEvents also takes a snapshot when watched resources
+	// change, once changes have paused for EventDebounce, or at the latest
+	// EventMaxDelay after the first.
+	EnableWatchEvents bool          `mapstructure:"enable_watch_events"`
+	EventDebounce     time.Duration `mapstructure:"event_debounce"`
+	EventMaxDelay     time.Duration `mapstructure:"event_max_delay"`
+	Gate              GateConfig    `mapstructure:"gate"`
+	Anomaly           AnomalyConfig `mapstructure:"anomaly"`
+	Storm             StormConfig   `mapstructure:"storm"`
+	Gaps              GapsConfig    `mapstructure:"gaps"`
+	// MetricsAddr is the host:port to serve Prometheus metrics of the watch
+	// process on, at /metrics. Empty disables the endpoint.
+	MetricsAddr string            `mapstructure:"metrics_addr"`
+	Remediation RemediationConfig `mapstructure:"remediation"`
+	Push        PushConfig        `mapstructure:"push"`
+}
+
+// PushConfig batches the pushes of watch with git.push, so that frequent
+// snapshots and a flaky remote don't push, or fail to push, on every tick.
+type PushConfig struct {
+	// Interval is the least time between pushes. With neither Interval nor
+	// Commits set, every commit is pushed.
+	Interval time.Duration `mapstructure:"interval"`
+	// Commits pushes once this many commits are unpushed, even within
+	// Interval.
+	Commits int `mapstructure:"commits"`
+	// Timeout bounds each push, so that a hanging remote does not hold up
+	// the next snapshot.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RetryBackoff is the wait after a failed push before the next attempt,
+	// doubling with each consecutive failure up to MaxBackoff.
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+}
+
+// RemediationConfig triggers external remediation workflows when drift at
+// or above Severity is detected on Checks consecutive watch ticks.
+type RemediationConfig struct {
+	Severity string `mapstructure:"severity"`
+	Checks   int    `mapstructure:"checks"`
+	// Webhooks are called with a templated body, e.g. to open a Jira or
+	// ServiceNow ticket.
+	Webhooks []RemediationWebhook `mapstructure:"webhooks"`
+	// GitHub dispatches a GitHub Actions workflow.
+	GitHub WorkflowDispatchConfig `mapstructure:"github"`
+}
+
+// RemediationWebhook is an HTTP request made when remediation triggers.
+// Body and header values are Go templates over the remediation event.
+type RemediationWebhook struct {
+	Name   string `mapstructure:"name"`
+	URL    string `mapstructure:"url"`
+	Method string `mapstructure:"method"`
+	// Headers usually carry credentials, so they are redacted as a whole.
+	Headers map[string]string `mapstructure:"headers" secret:"true"`
+	// Body defaults to the event as JSON.
+	Body string `mapstructure:"body"`
+}
+
+// WorkflowDispatchConfig dispatches a GitHub Actions workflow that has a
+// workflow_dispatch trigger.
+type WorkflowDispatchConfig struct {
+	// Repository is owner/name; empty disables the dispatch.
+	Repository string `mapstructure:"repository"`
+	// Workflow is the workflow's file name (e.g. remediate.yml) or ID.
+	Workflow string `mapstructure:"workflow"`
+	Ref      string `mapstructure:"ref"`
+	// Token needs the actions:write permission on the repository.
+	Token string `mapstructure:"token" secret:"true"`
+	// Inputs are templates over the remediation event; the workflow must
+	// declare every input.
+	Inputs map[string]string `mapstructure:"inputs"`
+	// APIURL is the GitHub API base URL, for GitHub Enterprise Server.
+	APIURL string `mapstructure:"api_url"`
+}
+
+// GapsConfig flags windows of the history in which scheduled snapshots
+// are missing, judged against Schedule.
+type GapsConfig struct {
+	// MinMissed is the number of consecutive missed ticks that is a gap.
+	// Zero disables detection.
+	MinMissed int `mapstructure:"min_missed"`
+}
+
+// StormConfig detects commit storms: every tick committing changes, which
+// usually means a field that should be stripped or controllers fighting
+// over a resource.
+type StormConfig struct {
+	// Ticks is the number of consecutive committing ticks that is a storm.
+	// Zero disables detection.
+	Ticks int `mapstructure:"ticks"`
+	// Backoff doubles the interval between snapshots (by skipping ticks)
+	// each time a storm persists for another Ticks, until watch restarts.
+	Backoff bool `mapstructure:"backoff"`
+	// MaxBackoff caps the interval as a multiple of the schedule's.
+	MaxBackoff int `mapstructure:"max_backoff"`
+}
+
+// AnomalyConfig configures detection of statistically unusual snapshot deltas.
+type AnomalyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Window is the number of past snapshots kept per series.
+	Window int `mapstructure:"window"`
+	// MinSamples is the history needed before a series is scored.
+	MinSamples int `mapstructure:"min_samples"`
+	// Threshold is the score (standard deviations above the mean) that flags a delta.
+	Threshold float64 `mapstructure:"threshold"`
+	// MinChanges ignores deltas smaller than this, however unusual.
+	MinChanges int `mapstructure:"min_changes"`
+	// Severity is used when anomalies are reported as watch gate violations.
+	Severity string `mapstructure:"severity"`
+	// StateFile stores the rolling statistics. Defaults to a file inside the
+	// snapshot repository's .git directory.
+	StateFile string `mapstructure:"state_file"`
+}
+
+// GateConfig configures policy checks that a watch-mode snapshot's changes
+// must pass before being committed to the main branch.
+type GateConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// FailOn is the lowest rule severity that rejects a snapshot.
+	FailOn string `mapstructure:"fail_on"`
+	// Rules flag drift entries by kind, namespace, change type, and field.
+	Rules []GateRule `mapstructure:"rules"`
+	// Command, if set, is run with the drift report as JSON on stdin; a
+	// non-zero exit rejects the snapshot (e.g. an `opa eval --fail` call).
+	Command []string `mapstructure:"command"`
+	// MaxChanges rejects snapshots that add, remove, or modify more than
+	// this many resources at once. Zero disables the check.
+	MaxChanges int `mapstructure:"max_changes"`
+	// QuarantineBranch receives rejected snapshots.
+	QuarantineBranch string `mapstructure:"quarantine_branch"`
+}
+
+// GateRule matches drift entries. Empty match fields match everything.
+type GateRule struct {
+	Name     string `mapstructure:"name"`
+	Severity string `mapstructure:"severity"`
+	// Kinds are resource kinds, e.g. Secret or ClusterRoleBinding.
+	Kinds []string `mapstructure:"kinds"`
+	// Namespaces are namespace names or glob patterns.
+	Namespaces []string `mapstructure:"namespaces"`
+	// Types are drift types: ADDED, REMOVED, MODIFIED, RECREATED, SCALED,
+	// or OWNERSHIP.
+	Types []string `mapstructure:"types"`
+	// Fields are field path prefixes, e.g. .spec.template.spec.containers,
+	// which also covers list elements such as .spec.template.spec.containers[nginx].image.
+	Fields []string `mapstructure:"fields"`
+}
+
+// HooksConfig configures external commands run around each snapshot. Each
+// command is an argv list and receives snapshot metadata in GITOPS_TM_*
+// environment variables.
+type HooksConfig struct {
+	// PreSnapshot runs before collection; a non-zero exit aborts the snapshot.
+	PreSnapshot []string `mapstructure:"pre_snapshot"`
+	// PostCommit runs after a snapshot is committed; failures are logged.
+	PostCommit []string `mapstructure:"post_commit"`
+	// Timeout bounds each hook's run time.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// ReportConfig configures the drift and trend report, and its scheduled
+// delivery through the notifiers while watch runs.
+type ReportConfig struct {
+	// Schedule is a cron expression in watch.timezone; empty disables
+	// scheduled reports.
+	Schedule string `mapstructure:"schedule"`
+	// Period is how much recent history the report covers.
+	Period time.Duration `mapstructure:"period"`
+	// Format is "markdown" or "html".
+	Format string `mapstructure:"format"`
+}
+
+// NotifiersConfig configures where reports and notifications are sent.
+type NotifiersConfig struct {
+	Email   EmailConfig   `mapstructure:"email"`
+	Slack   SlackConfig   `mapstructure:"slack"`
+	Webhook WebhookConfig `mapstructure:"webhook"`
+	// OnDrift notifies when watch commits changes or drift finds any,
+	// outside maintenance windows.
+	OnDrift bool `mapstructure:"on_drift"`
+	// DriftTemplate is a Go template for the body of drift notifications,
+	// over the drift report and its detail; empty uses a built-in summary.
+	DriftTemplate string `mapstructure:"drift_template"`
+	// Detail is how much of the drift a notification shows inline:
+	// "summary" (counts only), "resources" (the most severe changed
+	// resources), or "diffs" (also their field diffs, secrets masked).
+	Detail string `mapstructure:"detail"`
+	// MaxEntries is how many of the most severe changes are shown.
+	MaxEntries int `mapstructure:"max_entries"`
+	// MaxFieldDiffs is how many field diffs are shown per change.
+	MaxFieldDiffs int `mapstructure:"max_field_diffs"`
+	// MinSeverity leaves changes below this severity out of the detail;
+	// they are still counted in the summary.
+	MinSeverity string `mapstructure:"min_severity"`
+}
+
+// EmailConfig sends notifications by email over SMTP. STARTTLS is used when
+// the server offers it.
+type EmailConfig struct {
+	SMTPHost string   `mapstructure:"smtp_host"`
+	SMTPPort int      `mapstructure:"smtp_port"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password" secret:"true"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// SlackConfig posts notifications to a Slack channel with a bot token,
+// uploading attachments as files. The bot needs the chat:write and files:write scopes.
+type SlackConfig struct {
+	Token   string `mapstructure:"token" secret:"true"`
+	Channel string `mapstructure:"channel"`
+	// WebhookURL posts to an incoming webhook instead of using a bot
+	// token. Incoming webhooks cannot upload files, so attachments are
+	// left out.
+	WebhookURL string `mapstructure:"webhook_url" secret:"true"`
+}
+
+// WebhookConfig posts notifications to any HTTP endpoint.
+type WebhookConfig struct {
+	// URL is redacted since webhook URLs often embed a token.
+	URL     string            `mapstructure:"url" secret:"true"`
+	Headers map[string]string `mapstructure:"headers" secret:"true"`
+	// Body is a Go template over the message (.Subject, .Body); empty
+	// sends {"subject": ..., "text": ...}.
+	Body string `mapstructure:"body"`
+}
+
+// ExpiryConfig configures the report of expiring certificates.
+type ExpiryConfig struct {
+	// WarnWithin is how close to its expiry a certificate is reported.
+	WarnWithin time.Duration `mapstructure:"warn_within"`
+}
+
+// EvidenceConfig configures compliance evidence archives.
+type EvidenceConfig struct {
+	// SigningKey is a PEM (PKCS#8) Ed25519 private key that signs the
+	// archive manifest.
+	SigningKey string `mapstructure:"signing_key" secret:"true"`
+}
+
+// ServeConfig configures the REST API server.
+type ServeConfig struct {
+	// Tokens authenticate API clients. With none, read endpoints are open
+	// and restores are disabled.
+	Tokens []APIToken `mapstructure:"tokens"`
+	// SlashCommands answers Slack slash commands at /slack/commands.
+	SlashCommands SlashCommandConfig `mapstructure:"slash_commands"`
+}
+
+// SlashCommandConfig configures the Slack slash command (e.g. /timemachine).
+type SlashCommandConfig struct {
+	// SigningSecret is the Slack app's signing secret; empty disables
+	// slash commands.
+	SigningSecret string `mapstructure:"signing_secret" secret:"true"`
+}
+
+// APIToken is a bearer token accepted by the API server and the role it
+// grants: "viewer" reads history, "operator" can also restore.
+type APIToken struct {
+	// Name identifies the client in the audit log, e.g. "web-ui".
+	Name  string `mapstructure:"name"`
+	Token string `mapstructure:"token" secret:"true"`
+	Role  string `mapstructure:"role"`
+}
+
+// AuditConfig configures the audit log of operations that change the
+// cluster, such as restores.
+type AuditConfig struct {
+	// File receives one JSON object per operation, appended; empty records
+	// operations in the application log only.
+	File string `mapstructure:"file"`
 }
 
 // LogConfig configures logging.
 type LogConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
+	// File additionally writes logs to this path; stderr output is kept.
+	File       string `mapstructure:"file"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	MaxBackups int    `mapstructure:"max_backups"`
+}
+
+// OwnershipConfig maps resources to owning teams.
+type OwnershipConfig struct {
+	// Annotation names a resource annotation whose value is the owning team.
+	// It takes precedence over the namespace mapping.
+	Annotation string `mapstructure:"annotation"`
+	// Teams maps a team name to the namespaces (or glob patterns) it owns.
+	Teams map[string][]string `mapstructure:"teams"`
+}
+
+// TenancyConfig controls how snapshots are split between teams.
+type TenancyConfig struct {
+	// Mode is "" for a single tree, "directory" for one top-level
+	// directory per owning team, or "branch" for the directory layout plus
+	// one branch per team holding only that team's directory.
+	Mode string `mapstructure:"mode"`
+	// BranchPrefix names the per-team branches of branch mode: a team's
+	// branch is the prefix followed by its directory name.
+	BranchPrefix string `mapstructure:"branch_prefix"`
+}
+
+// ValidateTenancy checks that the tenancy mode is supported and, in branch
+// mode, that the branch prefix is set.
+func ValidateTenancy(cfg *TenancyConfig) error {
+	switch cfg.Mode {
+	case "", "directory":
+		return nil
+	case "branch":
+		if cfg.BranchPrefix == "" {
+			return fmt.Errorf(`tenancy.branch_prefix must be set in tenancy.mode "branch"`)
+		}
+		return nil
+	default:
+		return fmt.Errorf(`unsupported tenancy.mode %q (use "directory" or "branch")`, cfg.Mode)
+	}
+}
+
+// SuppressionConfig configures windows during which drift is recorded but not alerted.
+type SuppressionConfig struct {
+	Windows []SuppressionWindow `mapstructure:"windows"`
+}
+
+// IgnoreManagedConfig excludes resources owned by controllers that mutate
+// them on purpose (e.g. cluster-autoscaler, cert-manager) from diff, drift,
+// and gate reports.
+type IgnoreManagedConfig struct {
+	// Controllers are glob patterns matched against the
+	// app.kubernetes.io/managed-by label.
+	Controllers []string `mapstructure:"controllers"`
+	// Annotations are annotation keys whose presence marks a resource as
+	// owned by a controller, e.g. cert-manager.io/certificate-name.
+	Annotations []string `mapstructure:"annotations"`
+}
+
+// IgnoreConfig leaves fields that change on their own (timestamps written
+// by controllers, rotated certificates) out of diff, drift, and gate
+// reports. The learn command proposes rules for it from recent history.
+type IgnoreConfig struct {
+	// Fields maps a kind, or "*" for every kind, to the field paths to
+	// ignore, e.g. .metadata.annotations["kubectl.kubernetes.io/restartedAt"].
+	// Kinds match case-insensitively.
+	Fields map[string][]string `mapstructure:"fields"`
+	// Resources leaves whole resources out of comparisons, e.g. Secrets
+	// that cert-manager rotates. A resource matching any rule is ignored.
+	Resources []IgnoreResource `mapstructure:"resources"`
+}
+
+// IgnoreResource matches resources by kind, name, namespace, and labels.
+// Empty fields match everything, but a rule must set at least one.
+type IgnoreResource struct {
+	// Kind matches case-insensitively.
+	Kind string `mapstructure:"kind"`
+	// Namespace and Name are glob patterns, e.g. "cm-acme-http-solver-*".
+	Namespace string `mapstructure:"namespace"`
+	Name      string `mapstructure:"name"`
+	// Selector is a Kubernetes label selector, e.g. "app=cache,tier!=db".
+	Selector string `mapstructure:"selector"`
+}
+
+// OrphansConfig reports resources that no desired-state source accounts
+// for: not deployed by Helm, Argo CD, or Flux, not created by another
+// resource, and not declared in the desired-state manifests.
+type OrphansConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DesiredPaths are files or directories of plain YAML manifests, e.g. a
+	// checkout of the GitOps repository. Resources declared there are
+	// managed; a manifest without a namespace matches any namespace.
+	DesiredPaths []string `mapstructure:"desired_paths"`
+	// Managers are glob patterns matched against the
+	// app.kubernetes.io/managed-by label of other deployment tools.
+	Managers []string `mapstructure:"managers"`
+}
+
+// ImagePolicyConfig flags containers whose images break the image policy
+// in every snapshot, so that violations are reported alongside drift.
+type ImagePolicyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AllowedRegistries are the registries, or registry/path prefixes such
+	// as ghcr.io/acme, images may be pulled from. Images without a registry
+	// are from docker.io. Empty allows every registry.
+	AllowedRegistries []string `mapstructure:"allowed_registries"`
+	// ForbidLatest flags images tagged :latest, or with neither a tag nor a
+	// digest.
+	ForbidLatest bool `mapstructure:"forbid_latest"`
+	// Severity rates the violations, for the watch gate and notifications.
+	Severity string `mapstructure:"severity"`
+}
+
+// SuppressionWindow is either a recurring window (Schedule + Duration) or a
+// fixed window (Start/End in RFC3339), e.g. written by a release pipeline.
+type SuppressionWindow struct {
+	Name     string        `mapstructure:"name"`
+	Schedule string        `mapstructure:"schedule"`
+	Duration time.Duration `mapstructure:"duration"`
+	Start    string        `mapstructure:"start"`
+	End      string        `mapstructure:"end"`
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		Kubeconfig: defaultKubeconfig(),
+		Kubeconfig:     defaultKubeconfig(),
+		ClusterTimeout: 5 * time.Minute,
+		Client: ClientConfig{
+			UseEnvProxy: true,
+		},
 		Snapshot: SnapshotConfig{
 			OutputDir: "./infra-snapshots",
 			ResourceTypes: []string{
@@ -61,6 +646,16 @@ func DefaultConfig() *Config {
 				"persistentvolumeclaims", "networkpolicies",
 				"serviceaccounts", "roles", "rolebindings",
 			},
+			Discovery: DiscoveryConfig{
+				Exclude: []string{
+					// Owned by controllers, or churning on their own
+					"core/pods", "core/events", "core/endpoints", "core/componentstatuses",
+					"apps/replicasets", "apps/controllerrevisions",
+					"events.k8s.io", "discovery.k8s.io", "coordination.k8s.io", "metrics.k8s.io",
+				},
+			},
+			Concurrency: 8,
+			PageSize:    500,
 			ExcludeNamespaces: []string{
 				"kube-system", "kube-public", "kube-node-lease",
 			},
@@ -71,19 +666,86 @@ func DefaultConfig() *Config {
 				".metadata.generation",
 				".status",
 			},
+			Compression: CompressionConfig{
+				ThresholdBytes: 256 * 1024,
+			},
+			SkipUnchanged: true,
+			MaxSizeBytes:  1 << 30,
 		},
 		Git: GitConfig{
 			AuthorName:          "GitOps-Time-Machine",
 			AuthorEmail:         "gitops-tm@automated",
 			CommitMessagePrefix: "[snapshot]",
 			Branch:              "main",
+			Links: LinksConfig{
+				Remote: "origin",
+			},
 		},
 		Watch: WatchConfig{
-			Schedule: "*/5 * * * *",
+			Schedule:      "*/5 * * * *",
+			EventDebounce: 10 * time.Second,
+			EventMaxDelay: time.Minute,
+			Gate: GateConfig{
+				FailOn:           "high",
+				QuarantineBranch: "quarantine",
+			},
+			Anomaly: AnomalyConfig{
+				Window:     288,
+				MinSamples: 12,
+				Threshold:  4,
+				MinChanges: 10,
+				Severity:   "high",
+			},
+			Storm: StormConfig{
+				Ticks:      12,
+				MaxBackoff: 8,
+			},
+			Gaps: GapsConfig{
+				MinMissed: 2,
+			},
+			Remediation: RemediationConfig{
+				Severity: "high",
+				Checks:   3,
+				GitHub: WorkflowDispatchConfig{
+					Ref:    "main",
+					APIURL: "https://api.github.com",
+				},
+			},
+			Push: PushConfig{
+				Timeout:      2 * time.Minute,
+				RetryBackoff: 30 * time.Second,
+				MaxBackoff:   15 * time.Minute,
+			},
+		},
+		Report: ReportConfig{
+			Period: 7 * 24 * time.Hour,
+			Format: "markdown",
+		},
+		Notifiers: NotifiersConfig{
+			Email:         EmailConfig{SMTPPort: 587},
+			Detail:        "diffs",
+			MaxEntries:    5,
+			MaxFieldDiffs: 3,
+			MinSeverity:   "low",
+		},
+		Expiry: ExpiryConfig{
+			WarnWithin: 30 * 24 * time.Hour,
+		},
+		ImagePolicy: ImagePolicyConfig{
+			ForbidLatest: true,
+			Severity:     "high",
+		},
+		Tenancy: TenancyConfig{
+			BranchPrefix: "tenants/",
+		},
+		Hooks: HooksConfig{
+			Timeout: time.Minute,
 		},
 		Log: LogConfig{
-			Level:  "info",
-			Format: "text",
+			Level:      "info",
+			Format:     "text",
+			MaxSizeMB:  100,
+			MaxBackups: 5,
 		},
 	}
 }
@@ -132,3 +794,60 @@ func defaultKubeconfig() string {
 	}
 	return filepath.Join(home, ".kube", "config")
 }
+
+// FileUsed returns the path of the config file read by Load, or "" if none was found.
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// ValidateProfiles checks that every profile has a unique name and its own
+// output directory and remote.
+func ValidateProfiles(profiles []ProfileConfig) error {
+	names := make(map[string]bool, len(profiles))
+	dirs := make(map[string]string, len(profiles))
+	remotes := make(map[string]string, len(profiles))
+	for i, p := range profiles {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("profiles[%d]: name is required", i)
+		case names[p.Name]:
+			return fmt.Errorf("profile %q is listed more than once", p.Name)
+		case p.OutputDir == "":
+			return fmt.Errorf("profile %q: output_dir is required", p.Name)
+		}
+		names[p.Name] = true
+
+		dir := filepath.Clean(p.OutputDir)
+		if other, ok := dirs[dir]; ok {
+			return fmt.Errorf("profiles %q and %q share output_dir %s", other, p.Name, p.OutputDir)
+		}
+		dirs[dir] = p.Name
+		if p.RemoteURL != "" {
+			if other, ok := remotes[p.RemoteURL]; ok {
+				return fmt.Errorf("profiles %q and %q share remote_url %s", other, p.Name, p.RemoteURL)
+			}
+			remotes[p.RemoteURL] = p.Name
+		}
+	}
+	return nil
+}
+
+// ValidateClusters checks that every fleet cluster has a context and a
+// unique name usable as a top-level directory.
+func ValidateClusters(clusters []ClusterConfig) error {
+	seen := make(map[string]bool, len(clusters))
+	for i, c := range clusters {
+		switch {
+		case c.Name == "":
+			return fmt.Errorf("clusters[%d]: name is required", i)
+		case strings.ContainsAny(c.Name, `/\`) || strings.HasPrefix(c.Name, "_") || strings.HasPrefix(c.Name, "."):
+			return fmt.Errorf("cluster %q: name must not contain slashes or start with '_' or '.'", c.Name)
+		case c.Context == "":
+			return fmt.Errorf("cluster %q: context is required", c.Name)
+		case seen[c.Name]:
+			return fmt.Errorf("cluster %q is listed more than once", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
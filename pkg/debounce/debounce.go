@@ -0,0 +1,83 @@
+// Package debounce coalesces bursts of events into one action: the action
+// runs once events have stopped for a quiet period, or once the oldest
+// pending event has waited for a maximum delay, whichever comes first.
+package debounce
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// Validate checks the event debounce settings of watch.
+func Validate(cfg *config.WatchConfig) error {
+	if !cfg.EnableWatchEvents {
+		return nil
+	}
+	if cfg.EventDebounce <= 0 {
+		return fmt.Errorf("watch.event_debounce must be positive")
+	}
+	if cfg.EventMaxDelay < cfg.EventDebounce {
+		return fmt.Errorf("watch.event_max_delay must be at least watch.event_debounce")
+	}
+	return nil
+}
+
+// Debouncer coalesces triggers. It is safe for concurrent use.
+type Debouncer struct {
+	quiet    time.Duration
+	maxDelay time.Duration
+	events   chan struct{}
+}
+
+// New returns a Debouncer that fires quiet after the last trigger, but no
+// later than maxDelay after the first pending one.
+func New(quiet, maxDelay time.Duration) *Debouncer {
+	return &Debouncer{quiet: quiet, maxDelay: maxDelay, events: make(chan struct{}, 1)}
+}
+
+// Trigger records an event. It never blocks.
+func (d *Debouncer) Trigger() {
+	select {
+	case d.events <- struct{}{}:
+	default:
+	}
+}
+
+// Run calls fn for every burst of triggers until ctx is cancelled. Triggers
+// while fn runs start the next burst, so no change goes unseen.
+func (d *Debouncer) Run(ctx context.Context, fn func(context.Context)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.events:
+		}
+
+		deadline := time.NewTimer(d.maxDelay)
+		quiet := time.NewTimer(d.quiet)
+	burst:
+		for {
+			select {
+			case <-ctx.Done():
+				deadline.Stop()
+				quiet.Stop()
+				return
+			case <-d.events:
+				if !quiet.Stop() {
+					<-quiet.C
+				}
+				quiet.Reset(d.quiet)
+			case <-quiet.C:
+				break burst
+			case <-deadline.C:
+				break burst
+			}
+		}
+		deadline.Stop()
+		quiet.Stop()
+		fn(ctx)
+	}
+}
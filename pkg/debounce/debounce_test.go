@@ -0,0 +1,67 @@
+package debounce
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_CoalescesBurst(t *testing.T) {
+	d := New(50*time.Millisecond, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	go d.Run(ctx, func(context.Context) { runs.Add(1) })
+
+	for i := 0; i < 5; i++ {
+		d.Trigger()
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+
+	d.Trigger()
+	assert.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRun_MaxDelay(t *testing.T) {
+	d := New(100*time.Millisecond, 150*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fired := make(chan time.Time, 1)
+	go d.Run(ctx, func(context.Context) { fired <- time.Now() })
+
+	start := time.Now()
+	stop := time.After(400 * time.Millisecond)
+loop:
+	for {
+		select {
+		case at := <-fired:
+			assert.Less(t, at.Sub(start), 300*time.Millisecond)
+			break loop
+		case <-stop:
+			t.Fatal("a steady stream of events never fired")
+		default:
+			d.Trigger()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := config.DefaultConfig().Watch
+	assert.NoError(t, Validate(&cfg))
+	cfg.EnableWatchEvents = true
+	assert.NoError(t, Validate(&cfg))
+	cfg.EventMaxDelay = cfg.EventDebounce / 2
+	assert.Error(t, Validate(&cfg))
+	cfg.EventDebounce = 0
+	assert.Error(t, Validate(&cfg))
+}
@@ -0,0 +1,275 @@
+// Package evidence writes and verifies signed archives of snapshot history,
+// for answering compliance (SOC 2, ISO 27001) evidence requests about
+// configuration management.
+//
+// An archive is a gzipped tarball. Its manifest lists the SHA-256 digest of
+// every other file and is signed with an Ed25519 key, so the signature
+// covers the whole archive.
+package evidence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Names of the files that describe and sign an archive.
+const (
+	ManifestFile  = "manifest.json"
+	SignatureFile = "manifest.sig"
+	PublicKeyFile = "public-key.pem"
+)
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	APIVersion  string    `json:"apiVersion"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Cluster     string    `json:"cluster,omitempty"`
+	// Commits are the snapshot commits in the period, oldest first.
+	Commits []string `json:"commits"`
+	Files   []File   `json:"files"`
+}
+
+// File is a file in an archive and its digest.
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// Writer writes a signed archive. Files are added with Add or AddJSON; Close
+// writes the manifest, its signature, and the public key.
+type Writer struct {
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	key      ed25519.PrivateKey
+	manifest Manifest
+}
+
+// NewWriter starts an archive on w that is signed with key. The manifest's
+// Files are filled in as files are added.
+func NewWriter(w io.Writer, key ed25519.PrivateKey, manifest Manifest) *Writer {
+	gz := gzip.NewWriter(w)
+	manifest.Files = nil
+	return &Writer{gz: gz, tw: tar.NewWriter(gz), key: key, manifest: manifest}
+}
+
+// Add writes a file to the archive and records its digest.
+func (w *Writer) Add(name string, data []byte) error {
+	if err := w.write(name, data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	w.manifest.Files = append(w.manifest.Files, File{Path: name, SHA256: hex.EncodeToString(sum[:]), Size: len(data)})
+	return nil
+}
+
+// AddJSON writes v to the archive as indented JSON.
+func (w *Writer) AddJSON(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return w.Add(name, data)
+}
+
+// Close signs the manifest, writes it with its signature and public key,
+// and finishes the archive. It returns the final manifest.
+func (w *Writer) Close() (*Manifest, error) {
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	publicKey, err := encodePublicKey(w.key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.write(ManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if err := w.write(SignatureFile, ed25519.Sign(w.key, manifest)); err != nil {
+		return nil, err
+	}
+	if err := w.write(PublicKeyFile, publicKey); err != nil {
+		return nil, err
+	}
+	if err := w.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return &w.manifest, nil
+}
+
+// write adds a file to the tarball.
+func (w *Writer) write(name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: w.manifest.GeneratedAt,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Verify checks an archive's signature against publicKey, or against the
+// key embedded in the archive if publicKey is nil, and checks every file
+// against its digest in the manifest. Only a known publicKey proves who
+// signed the archive; the embedded key proves it was not altered since.
+func Verify(r io.Reader, publicKey ed25519.PublicKey) (*Manifest, error) {
+	files, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, ok := files[ManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", ManifestFile)
+	}
+	signature, ok := files[SignatureFile]
+	if !ok {
+		return nil, fmt.Errorf("archive has no %s", SignatureFile)
+	}
+	if publicKey == nil {
+		if publicKey, err = decodePublicKey(files[PublicKeyFile]); err != nil {
+			return nil, fmt.Errorf("failed to read embedded public key: %w", err)
+		}
+	}
+	if !ed25519.Verify(publicKey, manifestData, signature) {
+		return nil, errors.New("manifest signature is invalid")
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, f := range manifest.Files {
+		listed[f.Path] = true
+		data, ok := files[f.Path]
+		if !ok {
+			return nil, fmt.Errorf("%s is listed in the manifest but missing", f.Path)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, fmt.Errorf("%s does not match its digest", f.Path)
+		}
+	}
+	var unlisted []string
+	for name := range files {
+		if !listed[name] && name != ManifestFile && name != SignatureFile && name != PublicKeyFile {
+			unlisted = append(unlisted, name)
+		}
+	}
+	if len(unlisted) > 0 {
+		sort.Strings(unlisted)
+		return nil, fmt.Errorf("%s is not listed in the manifest", unlisted[0])
+	}
+	return &manifest, nil
+}
+
+// readArchive returns the files of a gzipped tarball by name.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, tr); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = buf.Bytes()
+	}
+}
+
+// LoadPrivateKey reads a PEM-encoded PKCS#8 Ed25519 private key, as written
+// by `openssl genpkey -algorithm ed25519`.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM-encoded", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads a PEM-encoded PKIX Ed25519 public key, as written by
+// `openssl pkey -pubout`.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	return decodePublicKey(data)
+}
+
+// encodePublicKey returns a public key as PKIX PEM.
+func encodePublicKey(key ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// decodePublicKey parses a PKIX PEM Ed25519 public key.
+func decodePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key is not PEM-encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an Ed25519 key")
+	}
+	return edKey, nil
+}
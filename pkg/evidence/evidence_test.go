@@ -0,0 +1,115 @@
+package evidence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeArchive(t *testing.T, key ed25519.PrivateKey) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf, key, Manifest{
+		From:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+		Commits:     []string{"abc"},
+	})
+	require.NoError(t, w.Add("snapshots/abc/prod/deployment/api.yaml", []byte("kind: Deployment\n")))
+	require.NoError(t, w.AddJSON("audit-log.json", []string{"abc"}))
+	manifest, err := w.Close()
+	require.NoError(t, err)
+	assert.Len(t, manifest.Files, 2)
+	return buf.Bytes()
+}
+
+// rewrite returns the archive with one file's content replaced.
+func rewrite(t *testing.T, archive []byte, name string, content []byte) []byte {
+	t.Helper()
+	files, err := readArchive(bytes.NewReader(archive))
+	require.NoError(t, err)
+	files[name] = content
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for n, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: n, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	archive := writeArchive(t, key)
+
+	manifest, err := Verify(bytes.NewReader(archive), public)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc"}, manifest.Commits)
+
+	// The embedded key is used when none is given
+	_, err = Verify(bytes.NewReader(archive), nil)
+	assert.NoError(t, err)
+
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = Verify(bytes.NewReader(archive), other)
+	assert.ErrorContains(t, err, "signature is invalid")
+
+	tampered := rewrite(t, archive, "snapshots/abc/prod/deployment/api.yaml", []byte("kind: Pod\n"))
+	_, err = Verify(bytes.NewReader(tampered), public)
+	assert.ErrorContains(t, err, "does not match its digest")
+
+	extra := rewrite(t, archive, "extra.txt", []byte("x"))
+	_, err = Verify(bytes.NewReader(extra), public)
+	assert.ErrorContains(t, err, "not listed")
+}
+
+func TestLoadKeys(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	loaded, err := LoadPrivateKey(keyPath)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(loaded))
+
+	// The archive's embedded public key can be loaded back
+	files, err := readArchive(bytes.NewReader(writeArchive(t, loaded)))
+	require.NoError(t, err)
+	pubPath := filepath.Join(dir, "pub.pem")
+	require.NoError(t, os.WriteFile(pubPath, files[PublicKeyFile], 0644))
+	loadedPublic, err := LoadPublicKey(pubPath)
+	require.NoError(t, err)
+	assert.True(t, public.Equal(loadedPublic))
+
+	require.NoError(t, os.WriteFile(keyPath, []byte("not a key"), 0600))
+	_, err = LoadPrivateKey(keyPath)
+	assert.Error(t, err)
+}
+
+func TestReadArchive_NotGzip(t *testing.T) {
+	_, err := Verify(io.NopCloser(bytes.NewReader([]byte("plain"))), nil)
+	assert.Error(t, err)
+}
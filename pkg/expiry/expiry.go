@@ -0,0 +1,139 @@
+// Package expiry finds the expiry dates of certificates captured in a
+// snapshot: cert-manager Certificates and Secrets of type kubernetes.io/tls.
+package expiry
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// secretTypeTLS is the type of Secrets holding a certificate and its key.
+const secretTypeTLS = "kubernetes.io/tls"
+
+// Certificate is a certificate found in a snapshot and when it expires.
+type Certificate struct {
+	Kind      string    `json:"kind" yaml:"kind"`
+	Namespace string    `json:"namespace" yaml:"namespace"`
+	Name      string    `json:"name" yaml:"name"`
+	Subject   string    `json:"subject,omitempty" yaml:"subject,omitempty"`
+	DNSNames  []string  `json:"dnsNames,omitempty" yaml:"dnsNames,omitempty"`
+	NotAfter  time.Time `json:"notAfter" yaml:"notAfter"`
+}
+
+// Expired reports whether the certificate has expired at now.
+func (c Certificate) Expired(now time.Time) bool {
+	return !now.Before(c.NotAfter)
+}
+
+// Find returns the certificates in a snapshot, soonest expiry first. A
+// cert-manager Certificate whose status records notAfter stands in for the
+// Secret it issues; otherwise the Secret's tls.crt is parsed. Resources
+// whose expiry can't be read (e.g. anonymized Secrets) are skipped.
+func Find(snapshot *types.ResourceSnapshot) []Certificate {
+	var certs []Certificate
+	issued := make(map[string]bool)
+	for _, res := range snapshot.Resources {
+		if cert, ok := fromCertificate(res); ok {
+			certs = append(certs, cert)
+			if secretName, _ := res.Spec["secretName"].(string); secretName != "" {
+				issued[res.Namespace+"/"+secretName] = true
+			}
+		}
+	}
+	for _, res := range snapshot.Resources {
+		if issued[res.Namespace+"/"+res.Name] {
+			continue
+		}
+		if cert, ok := fromSecret(res); ok {
+			certs = append(certs, cert)
+		}
+	}
+
+	sort.SliceStable(certs, func(i, j int) bool {
+		if !certs[i].NotAfter.Equal(certs[j].NotAfter) {
+			return certs[i].NotAfter.Before(certs[j].NotAfter)
+		}
+		return certs[i].Namespace+"/"+certs[i].Name < certs[j].Namespace+"/"+certs[j].Name
+	})
+	return certs
+}
+
+// Expiring returns the certificates that expire within the window after
+// now, including those already expired.
+func Expiring(certs []Certificate, now time.Time, within time.Duration) []Certificate {
+	deadline := now.Add(within)
+	var expiring []Certificate
+	for _, cert := range certs {
+		if cert.NotAfter.Before(deadline) {
+			expiring = append(expiring, cert)
+		}
+	}
+	return expiring
+}
+
+// fromCertificate reads status.notAfter of a cert-manager Certificate.
+// Status is only present when it is not stripped from snapshots.
+func fromCertificate(res types.Resource) (Certificate, bool) {
+	if res.Kind != "Certificate" || !strings.HasPrefix(res.APIVersion, "cert-manager.io/") {
+		return Certificate{}, false
+	}
+	status, _ := res.Raw["status"].(map[string]interface{})
+	var notAfter time.Time
+	switch v := status["notAfter"].(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Certificate{}, false
+		}
+		notAfter = t
+	case time.Time:
+		notAfter = v
+	default:
+		return Certificate{}, false
+	}
+
+	cert := Certificate{Kind: res.Kind, Namespace: res.Namespace, Name: res.Name, NotAfter: notAfter.UTC()}
+	cert.Subject, _ = res.Spec["commonName"].(string)
+	if names, ok := res.Spec["dnsNames"].([]interface{}); ok {
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				cert.DNSNames = append(cert.DNSNames, s)
+			}
+		}
+	}
+	return cert, true
+}
+
+// fromSecret parses the leaf certificate in a TLS Secret's tls.crt.
+func fromSecret(res types.Resource) (Certificate, bool) {
+	if res.Kind != "Secret" || res.Raw["type"] != secretTypeTLS {
+		return Certificate{}, false
+	}
+	encoded, _ := res.Data["tls.crt"].(string)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Certificate{}, false
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return Certificate{}, false
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return Certificate{}, false
+	}
+	return Certificate{
+		Kind:      res.Kind,
+		Namespace: res.Namespace,
+		Name:      res.Name,
+		Subject:   parsed.Subject.CommonName,
+		DNSNames:  parsed.DNSNames,
+		NotAfter:  parsed.NotAfter.UTC(),
+	}, true
+}
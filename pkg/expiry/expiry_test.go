@@ -0,0 +1,80 @@
+package expiry
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tlsSecret returns a TLS Secret holding a self-signed certificate.
+func tlsSecret(t *testing.T, namespace, name string, notAfter time.Time) types.Resource {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name + ".example.com"},
+		DNSNames:     []string{name + ".example.com"},
+		NotBefore:    notAfter.AddDate(0, -3, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	crt := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	return types.Resource{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  namespace,
+		Name:       name,
+		Data:       map[string]interface{}{"tls.crt": base64.StdEncoding.EncodeToString(crt)},
+		Raw:        map[string]interface{}{"type": "kubernetes.io/tls"},
+	}
+}
+
+func TestFind(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		tlsSecret(t, "prod", "web-tls", now.AddDate(0, 2, 0)),
+		tlsSecret(t, "prod", "api-tls", now.AddDate(0, 0, 10)),
+		// Issued by the Certificate below, which stands in for it
+		tlsSecret(t, "prod", "issued-tls", now.AddDate(0, 0, 1)),
+		{
+			APIVersion: "cert-manager.io/v1",
+			Kind:       "Certificate",
+			Namespace:  "prod",
+			Name:       "issued",
+			Spec:       map[string]interface{}{"secretName": "issued-tls", "commonName": "issued.example.com"},
+			Raw:        map[string]interface{}{"status": map[string]interface{}{"notAfter": "2024-05-30T00:00:00Z"}},
+		},
+		{Kind: "Secret", Namespace: "prod", Name: "opaque", Raw: map[string]interface{}{"type": "Opaque"}},
+		{Kind: "Secret", Namespace: "prod", Name: "anonymized", Data: map[string]interface{}{"tls.crt": "c2VjcmV0"}, Raw: map[string]interface{}{"type": "kubernetes.io/tls"}},
+	}}
+
+	certs := Find(snapshot)
+	require.Len(t, certs, 3)
+	assert.Equal(t, "issued", certs[0].Name)
+	assert.Equal(t, "Certificate", certs[0].Kind)
+	assert.Equal(t, "issued.example.com", certs[0].Subject)
+	assert.Equal(t, "api-tls", certs[1].Name)
+	assert.Equal(t, []string{"api-tls.example.com"}, certs[1].DNSNames)
+	assert.Equal(t, "web-tls", certs[2].Name)
+
+	assert.True(t, certs[0].Expired(now))
+	assert.False(t, certs[1].Expired(now))
+
+	expiring := Expiring(certs, now, 30*24*time.Hour)
+	require.Len(t, expiring, 2)
+	assert.Equal(t, "api-tls", expiring[1].Name)
+}
@@ -0,0 +1,125 @@
+// Package fleet compares the same resources across the clusters of a fleet.
+package fleet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// State describes how a cluster's copy of a resource compares with the
+// reference cluster's.
+type State string
+
+const (
+	// StateIdentical means the resource matches the reference.
+	StateIdentical State = "identical"
+	// StateDiffers means the resource exists in both but some fields differ.
+	StateDiffers State = "differs"
+	// StateMissing means the reference has the resource but the cluster does not.
+	StateMissing State = "missing"
+	// StateExtra means the cluster has a resource the reference does not.
+	StateExtra State = "extra"
+)
+
+// Cell is one resource in one cluster.
+type Cell struct {
+	State      State             `json:"state" yaml:"state"`
+	FieldDiffs []types.FieldDiff `json:"fieldDiffs,omitempty" yaml:"fieldDiffs,omitempty"`
+}
+
+// Row is a resource that deviates from the reference in at least one cluster.
+type Row struct {
+	Resource string          `json:"resource" yaml:"resource"`
+	Clusters map[string]Cell `json:"clusters" yaml:"clusters"`
+}
+
+// Matrix shows which clusters deviate from the reference, and where.
+type Matrix struct {
+	Reference string `json:"reference" yaml:"reference"`
+	// Clusters are the compared clusters, in configuration order.
+	Clusters []string `json:"clusters" yaml:"clusters"`
+	Rows     []Row    `json:"rows" yaml:"rows"`
+	// Identical counts the resources that match in every cluster.
+	Identical int `json:"identical" yaml:"identical"`
+}
+
+// Compare builds the drift matrix of every cluster in order against the
+// reference, leaving out the resources and fields the ignore rules
+// configure. snapshots must hold a snapshot for each named cluster.
+func Compare(rules *config.IgnoreConfig, reference string, clusters []string, snapshots map[string]*types.ResourceSnapshot) (*Matrix, error) {
+	base, ok := snapshots[reference]
+	if !ok {
+		return nil, fmt.Errorf("no snapshot for reference cluster %s", reference)
+	}
+	base = withoutIdentity(base)
+
+	comparer := analyzer.NewWithOptions(analyzer.Options{Ignore: rules})
+	matrix := &Matrix{Reference: reference}
+	rows := make(map[string]*Row)
+	names := make(map[string]bool)
+	for _, res := range base.Resources {
+		names[res.FullName()] = true
+	}
+
+	for _, name := range clusters {
+		if name == reference {
+			continue
+		}
+		target, ok := snapshots[name]
+		if !ok {
+			return nil, fmt.Errorf("no snapshot for cluster %s", name)
+		}
+		matrix.Clusters = append(matrix.Clusters, name)
+		for _, res := range target.Resources {
+			names[res.FullName()] = true
+		}
+
+		report := comparer.Compare(base, withoutIdentity(target))
+		for _, entry := range report.Entries {
+			cell := Cell{State: StateDiffers, FieldDiffs: entry.FieldDiffs}
+			switch entry.Type {
+			case types.DriftAdded:
+				cell = Cell{State: StateExtra}
+			case types.DriftRemoved:
+				cell = Cell{State: StateMissing}
+			}
+
+			resource := entry.Resource.FullName()
+			row, ok := rows[resource]
+			if !ok {
+				row = &Row{Resource: resource, Clusters: make(map[string]Cell)}
+				rows[resource] = row
+			}
+			row.Clusters[name] = cell
+		}
+	}
+
+	for _, row := range rows {
+		// Fill in the clusters that agree with the reference
+		for _, name := range matrix.Clusters {
+			if _, ok := row.Clusters[name]; !ok {
+				row.Clusters[name] = Cell{State: StateIdentical}
+			}
+		}
+		matrix.Rows = append(matrix.Rows, *row)
+	}
+	sort.Slice(matrix.Rows, func(i, j int) bool { return matrix.Rows[i].Resource < matrix.Rows[j].Resource })
+	matrix.Identical = len(names) - len(rows)
+	return matrix, nil
+}
+
+// withoutIdentity drops per-cluster identity (UIDs) so that the same
+// resource in two clusters is not reported as recreated.
+func withoutIdentity(snapshot *types.ResourceSnapshot) *types.ResourceSnapshot {
+	stripped := &types.ResourceSnapshot{Metadata: snapshot.Metadata}
+	stripped.Resources = make([]types.Resource, len(snapshot.Resources))
+	for i, res := range snapshot.Resources {
+		res.UID = ""
+		stripped.Resources[i] = res
+	}
+	return stripped
+}
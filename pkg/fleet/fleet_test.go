@@ -0,0 +1,66 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func deployment(name string, replicas int, uid string) types.Resource {
+	return types.Resource{
+		Kind:      "Deployment",
+		Namespace: "prod",
+		Name:      name,
+		UID:       uid,
+		Spec:      map[string]interface{}{"replicas": replicas},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	snapshots := map[string]*types.ResourceSnapshot{
+		"eu": {Resources: []types.Resource{deployment("api", 3, "a"), deployment("web", 2, "b")}},
+		"us": {Resources: []types.Resource{deployment("api", 3, "c"), deployment("web", 4, "d")}},
+		"ap": {Resources: []types.Resource{deployment("api", 3, "e"), deployment("debug", 1, "f")}},
+	}
+
+	matrix, err := Compare(nil, "eu", []string{"eu", "us", "ap"}, snapshots)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"us", "ap"}, matrix.Clusters)
+	// api matches everywhere despite differing UIDs
+	assert.Equal(t, 1, matrix.Identical)
+	require.Len(t, matrix.Rows, 2)
+
+	debug := matrix.Rows[0]
+	assert.Equal(t, "prod/Deployment/debug", debug.Resource)
+	assert.Equal(t, StateExtra, debug.Clusters["ap"].State)
+	assert.Equal(t, StateIdentical, debug.Clusters["us"].State)
+
+	web := matrix.Rows[1]
+	assert.Equal(t, "prod/Deployment/web", web.Resource)
+	assert.Equal(t, StateDiffers, web.Clusters["us"].State)
+	require.Len(t, web.Clusters["us"].FieldDiffs, 1)
+	assert.Equal(t, ".spec.replicas", web.Clusters["us"].FieldDiffs[0].Path)
+	assert.Equal(t, StateMissing, web.Clusters["ap"].State)
+}
+
+func TestCompare_UnknownReference(t *testing.T) {
+	_, err := Compare(nil, "eu", []string{"us"}, map[string]*types.ResourceSnapshot{"us": {}})
+	assert.Error(t, err)
+}
+
+func TestCompare_IgnoreRules(t *testing.T) {
+	snapshots := map[string]*types.ResourceSnapshot{
+		"eu": {Resources: []types.Resource{deployment("web", 2, "a")}},
+		"us": {Resources: []types.Resource{deployment("web", 4, "b")}},
+	}
+	rules := &config.IgnoreConfig{Fields: map[string][]string{"Deployment": {".spec.replicas"}}}
+
+	matrix, err := Compare(rules, "eu", []string{"us"}, snapshots)
+	require.NoError(t, err)
+	assert.Empty(t, matrix.Rows)
+	assert.Equal(t, 1, matrix.Identical)
+}
@@ -0,0 +1,183 @@
+// Package gaps finds windows in the snapshot history where scheduled
+// snapshots are missing, e.g. because the tool was down or the cluster
+// unreachable, so that blind spots in the record are visible.
+//
+// A tick that ran but committed nothing, because nothing changed or a
+// commit storm backoff skipped it, is not a missing snapshot. Such ticks
+// are recorded as checks in a file next to the repository.
+package gaps
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/scheduler"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// maxChecks caps the checks kept; the oldest are dropped.
+const maxChecks = 10000
+
+// maxTicks caps the ticks counted in one window, e.g. a year of a
+// once-a-minute schedule.
+const maxTicks = 100000
+
+// Gap is a window between two snapshots (or the last snapshot and now) in
+// which scheduled snapshots are missing.
+type Gap struct {
+	From time.Time `json:"from" yaml:"from"`
+	To   time.Time `json:"to" yaml:"to"`
+	// Missed is the number of scheduled ticks with no snapshot.
+	Missed int `json:"missed" yaml:"missed"`
+	// Ongoing is set for the window up to now: no snapshot has been taken
+	// since From.
+	Ongoing bool `json:"ongoing,omitempty" yaml:"ongoing,omitempty"`
+}
+
+// Validate checks the gap detection configuration.
+func Validate(cfg *config.GapsConfig) error {
+	if cfg.MinMissed < 0 {
+		return fmt.Errorf("watch.gaps.min_missed must not be negative")
+	}
+	return nil
+}
+
+// ChecksFile returns where the checks of the repository at repoPath are
+// recorded: inside .git, so they survive snapshot rewrites without being
+// committed.
+func ChecksFile(repoPath string) string {
+	return filepath.Join(repoPath, ".git", "gitops-time-machine", "checks")
+}
+
+// RecordCheck records that a tick at t ran without committing a snapshot.
+func RecordCheck(path string, t time.Time) error {
+	checks, err := LoadChecks(path)
+	if err != nil {
+		return err
+	}
+	checks = append(checks, t)
+	if len(checks) > maxChecks {
+		checks = checks[len(checks)-maxChecks:]
+	}
+
+	var b strings.Builder
+	for _, c := range checks {
+		b.WriteString(c.UTC().Format(time.RFC3339) + "\n")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create checks directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write checks: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checks: %w", err)
+	}
+	return nil
+}
+
+// LoadChecks returns the recorded checks, oldest first; none if the file
+// does not exist.
+func LoadChecks(path string) ([]time.Time, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checks: %w", err)
+	}
+	defer f.Close()
+
+	var checks []time.Time
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			continue
+		}
+		checks = append(checks, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checks: %w", err)
+	}
+	return checks, nil
+}
+
+// SnapshotTimes returns the times of the snapshots in a history. Baselines
+// imported from manifests were not taken on the schedule and are left out.
+func SnapshotTimes(entries []types.HistoryEntry) []time.Time {
+	var times []time.Time
+	for _, e := range entries {
+		if e.ClusterName != types.ImportedCluster {
+			times = append(times, e.Timestamp)
+		}
+	}
+	return times
+}
+
+// Find returns the gaps in which at least watch.gaps.min_missed scheduled
+// ticks of watch.schedule have neither a snapshot nor a check, oldest
+// first. Only the time from the first snapshot to now is considered. A
+// tick is expected to produce a snapshot before the next tick, so the most
+// recent tick before now is never counted as missed.
+func Find(cfg *config.WatchConfig, snapshots, checks []time.Time, now time.Time) ([]Gap, error) {
+	if cfg.Gaps.MinMissed == 0 || cfg.Schedule == "" || len(snapshots) == 0 {
+		return nil, nil
+	}
+	sched, err := scheduler.New(cfg.Schedule, cfg.Timezone, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	times := append([]time.Time(nil), snapshots...)
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	first := times[0]
+	for _, c := range checks {
+		if c.After(first) {
+			times = append(times, c)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var gaps []Gap
+	for i, from := range times {
+		to, ongoing := now, true
+		if i+1 < len(times) {
+			to, ongoing = times[i+1], false
+		}
+		// The tick that produced the snapshot at to is expected; every
+		// other tick since from was missed.
+		missed := ticksBetween(sched, from, to) - 1
+		if missed >= cfg.Gaps.MinMissed {
+			gaps = append(gaps, Gap{From: from, To: to, Missed: missed, Ongoing: ongoing})
+		}
+	}
+	return gaps, nil
+}
+
+// Missed returns the total number of missed ticks in gaps.
+func Missed(gaps []Gap) int {
+	n := 0
+	for _, g := range gaps {
+		n += g.Missed
+	}
+	return n
+}
+
+// ticksBetween counts the schedule's ticks strictly between from and to.
+func ticksBetween(sched *scheduler.Scheduler, from, to time.Time) int {
+	n := 0
+	for t := sched.Next(from); !t.IsZero() && t.Before(to) && n < maxTicks; t = sched.Next(t) {
+		n++
+	}
+	return n
+}
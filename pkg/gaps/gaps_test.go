@@ -0,0 +1,87 @@
+package gaps
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hourly is a watch configuration snapshotting at the top of every hour.
+func hourly(minMissed int) *config.WatchConfig {
+	return &config.WatchConfig{Schedule: "0 * * * *", Timezone: "UTC", Gaps: config.GapsConfig{MinMissed: minMissed}}
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 3, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func TestFind(t *testing.T) {
+	// Ticks at 01:00 and 02:00 left no snapshot; 05:00 was a check
+	snapshots := []time.Time{at(0, 1), at(3, 2), at(4, 1), at(6, 1)}
+	checks := []time.Time{at(5, 1)}
+
+	found, err := Find(hourly(2), snapshots, checks, at(6, 30))
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, Gap{From: at(0, 1), To: at(3, 2), Missed: 2}, found[0])
+
+	found, err = Find(hourly(2), snapshots, nil, at(6, 30))
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "one missed tick is below min_missed")
+
+	found, err = Find(hourly(1), snapshots, nil, at(6, 30))
+	require.NoError(t, err)
+	assert.Len(t, found, 2, "05:00 has neither a snapshot nor a check")
+	assert.Equal(t, 3, Missed(found))
+}
+
+func TestFind_Ongoing(t *testing.T) {
+	found, err := Find(hourly(2), []time.Time{at(0, 1)}, nil, at(2, 30))
+	require.NoError(t, err)
+	assert.Empty(t, found, "the 02:00 snapshot may still be running")
+
+	found, err = Find(hourly(2), []time.Time{at(0, 1)}, nil, at(3, 30))
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.True(t, found[0].Ongoing)
+	assert.Equal(t, 2, found[0].Missed)
+}
+
+func TestFind_Disabled(t *testing.T) {
+	snapshots := []time.Time{at(0, 1), at(9, 1)}
+	found, err := Find(hourly(0), snapshots, nil, at(9, 2))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	cfg := hourly(2)
+	cfg.Schedule = "not a schedule"
+	_, err = Find(cfg, snapshots, nil, at(9, 2))
+	assert.Error(t, err)
+}
+
+func TestChecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repo", ".git", "gitops-time-machine", "checks")
+	checks, err := LoadChecks(path)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+
+	require.NoError(t, RecordCheck(path, at(1, 0)))
+	require.NoError(t, RecordCheck(path, at(2, 0).In(time.FixedZone("CET", 3600))))
+	checks, err = LoadChecks(path)
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+	assert.True(t, checks[1].Equal(at(2, 0)))
+}
+
+func TestSnapshotTimes_SkipsImported(t *testing.T) {
+	entries := []types.HistoryEntry{
+		{Timestamp: at(3, 0), ClusterName: "prod"},
+		{Timestamp: at(1, 0), ClusterName: types.ImportedCluster},
+	}
+	assert.Equal(t, []time.Time{at(3, 0)}, SnapshotTimes(entries))
+}
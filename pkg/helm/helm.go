@@ -0,0 +1,215 @@
+// Package helm recognizes resources installed by Helm and decodes the
+// release records Helm keeps in the cluster, so that snapshots and drift
+// reports can speak of releases rather than their individual resources.
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// NoRelease is the group name used for resources not installed by Helm.
+const NoRelease = "(no release)"
+
+// Annotations and labels Helm 3 sets on the resources of a release, and
+// the labels of Helm 2 era charts.
+const (
+	releaseNameAnnotation      = "meta.helm.sh/release-name"
+	releaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	managedByLabel             = "app.kubernetes.io/managed-by"
+	instanceLabel              = "app.kubernetes.io/instance"
+	heritageLabel              = "heritage"
+	releaseLabel               = "release"
+)
+
+// Release records are named sh.helm.release.v1.<release>.v<revision> and
+// stored as Secrets of this type, or ConfigMaps with the storage driver
+// set to configmap.
+const (
+	recordPrefix     = "sh.helm.release.v1."
+	recordSecretType = "helm.sh/release.v1"
+)
+
+// ReleaseOf returns the release that installed a resource, as
+// namespace/name, or "" if Helm did not install it. Release records
+// belong to their own release.
+func ReleaseOf(res types.Resource) string {
+	if name, _, ok := recordName(res); ok {
+		return res.Namespace + "/" + name
+	}
+	if name := res.Annotations[releaseNameAnnotation]; name != "" {
+		ns := res.Annotations[releaseNamespaceAnnotation]
+		if ns == "" {
+			ns = res.Namespace
+		}
+		return ns + "/" + name
+	}
+	// Without the annotations (e.g. Helm 2, or annotations stripped by
+	// snapshot settings) only the release name is known; Helm installs
+	// into the release namespace by default
+	if strings.EqualFold(res.Labels[managedByLabel], "helm") && res.Labels[instanceLabel] != "" {
+		return res.Namespace + "/" + res.Labels[instanceLabel]
+	}
+	if strings.EqualFold(res.Labels[heritageLabel], "helm") && res.Labels[releaseLabel] != "" {
+		return res.Namespace + "/" + res.Labels[releaseLabel]
+	}
+	return ""
+}
+
+// Annotate sets the Release field on every entry of a drift report.
+func Annotate(report *types.DriftReport) {
+	for i := range report.Entries {
+		report.Entries[i].Release = ReleaseOf(report.Entries[i].Resource)
+	}
+}
+
+// ReleaseOfEntry returns the release recorded on an entry, or NoRelease.
+func ReleaseOfEntry(entry types.DriftEntry) string {
+	if entry.Release == "" {
+		return NoRelease
+	}
+	return entry.Release
+}
+
+// Releases decodes the latest revision of every release recorded in a
+// snapshot, sorted by namespace and name. Records that cannot be decoded
+// are skipped.
+func Releases(snapshot *types.ResourceSnapshot) []types.HelmRelease {
+	latest := make(map[string]types.HelmRelease)
+	for _, res := range snapshot.Resources {
+		if _, _, ok := recordName(res); !ok {
+			continue
+		}
+		release, err := decode(res)
+		if err != nil {
+			log.WithError(err).WithField("resource", res.FullName()).Debug("skipping undecodable Helm release record")
+			continue
+		}
+		key := release.Namespace + "/" + release.Name
+		if prev, ok := latest[key]; !ok || release.Revision > prev.Revision {
+			latest[key] = release
+		}
+	}
+
+	releases := make([]types.HelmRelease, 0, len(latest))
+	for _, release := range latest {
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+	return releases
+}
+
+// recordName returns the release name and revision of a release record.
+func recordName(res types.Resource) (string, int, bool) {
+	switch res.Kind {
+	case "Secret":
+		if res.Raw != nil && res.Raw["type"] != recordSecretType {
+			return "", 0, false
+		}
+	case "ConfigMap":
+		if !strings.EqualFold(res.Labels["owner"], "helm") {
+			return "", 0, false
+		}
+	default:
+		return "", 0, false
+	}
+	rest, ok := strings.CutPrefix(res.Name, recordPrefix)
+	if !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndex(rest, ".v")
+	if i <= 0 {
+		return "", 0, false
+	}
+	revision, err := strconv.Atoi(rest[i+2:])
+	if err != nil {
+		return "", 0, false
+	}
+	return rest[:i], revision, true
+}
+
+// record is the part of Helm's release record that is decoded.
+type record struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// decode reads a release record: the release key holds base64 of the
+// gzipped JSON release, and Secrets base64-encode their data once more.
+func decode(res types.Resource) (types.HelmRelease, error) {
+	encoded, ok := res.Data["release"].(string)
+	if !ok {
+		return types.HelmRelease{}, fmt.Errorf("no release data")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return types.HelmRelease{}, fmt.Errorf("failed to decode release data: %w", err)
+	}
+	if res.Kind == "Secret" {
+		if data, err = base64.StdEncoding.DecodeString(string(data)); err != nil {
+			return types.HelmRelease{}, fmt.Errorf("failed to decode release data: %w", err)
+		}
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return types.HelmRelease{}, fmt.Errorf("failed to decompress release: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return types.HelmRelease{}, fmt.Errorf("failed to decompress release: %w", err)
+		}
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return types.HelmRelease{}, fmt.Errorf("failed to parse release: %w", err)
+	}
+	release := types.HelmRelease{
+		Name:         rec.Name,
+		Namespace:    rec.Namespace,
+		Revision:     rec.Version,
+		Chart:        rec.Chart.Metadata.Name,
+		ChartVersion: rec.Chart.Metadata.Version,
+		AppVersion:   rec.Chart.Metadata.AppVersion,
+		Status:       rec.Info.Status,
+	}
+	if release.Namespace == "" {
+		release.Namespace = res.Namespace
+	}
+	if len(rec.Config) > 0 {
+		// Map keys are marshaled sorted, so equal values hash equally
+		values, _ := json.Marshal(rec.Config)
+		sum := sha256.Sum256(values)
+		release.ValuesHash = hex.EncodeToString(sum[:])[:12]
+	}
+	return release, nil
+}
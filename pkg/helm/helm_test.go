@@ -0,0 +1,105 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseSecret encodes a release record as Helm's secret driver stores it.
+func releaseSecret(t *testing.T, name string, revision int, chartVersion string, values map[string]interface{}) types.Resource {
+	t.Helper()
+	rec := map[string]interface{}{
+		"name": name, "namespace": "ingress", "version": revision,
+		"info":   map[string]interface{}{"status": "deployed"},
+		"chart":  map[string]interface{}{"metadata": map[string]interface{}{"name": "ingress-nginx", "version": chartVersion, "appVersion": "1.9.0"}},
+		"config": values,
+	}
+	data, err := json.Marshal(rec)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err = zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	helmEncoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	secretName := "sh.helm.release.v1." + name + ".v" + strconv.Itoa(revision)
+	return types.Resource{
+		Kind: "Secret", Namespace: "ingress", Name: secretName,
+		Labels: map[string]string{"owner": "helm", "name": name},
+		Raw:    map[string]interface{}{"type": "helm.sh/release.v1"},
+		Data:   map[string]interface{}{"release": base64.StdEncoding.EncodeToString([]byte(helmEncoded))},
+	}
+}
+
+func TestReleaseOf(t *testing.T) {
+	tests := []struct {
+		name string
+		res  types.Resource
+		want string
+	}{
+		{"annotations", types.Resource{Kind: "Deployment", Namespace: "ingress", Annotations: map[string]string{
+			"meta.helm.sh/release-name": "ingress-nginx", "meta.helm.sh/release-namespace": "ingress",
+		}}, "ingress/ingress-nginx"},
+		{"cluster-scoped", types.Resource{Kind: "ClusterRole", Annotations: map[string]string{
+			"meta.helm.sh/release-name": "ingress-nginx", "meta.helm.sh/release-namespace": "ingress",
+		}}, "ingress/ingress-nginx"},
+		{"managed-by label", types.Resource{Kind: "Service", Namespace: "web", Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "Helm", "app.kubernetes.io/instance": "shop",
+		}}, "web/shop"},
+		{"heritage label", types.Resource{Kind: "Service", Namespace: "web", Labels: map[string]string{
+			"heritage": "Helm", "release": "legacy",
+		}}, "web/legacy"},
+		{"release record", releaseSecret(t, "ingress-nginx", 3, "4.8.0", nil), "ingress/ingress-nginx"},
+		{"not Helm", types.Resource{Kind: "Service", Namespace: "web", Labels: map[string]string{"release": "x"}}, ""},
+		{"plain secret", types.Resource{Kind: "Secret", Namespace: "web", Name: "sh.helm.release.v1.x.v1", Raw: map[string]interface{}{"type": "Opaque"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReleaseOf(tt.res))
+		})
+	}
+}
+
+func TestReleases(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		releaseSecret(t, "ingress-nginx", 2, "4.7.0", map[string]interface{}{"replicas": 2}),
+		releaseSecret(t, "ingress-nginx", 3, "4.8.0", map[string]interface{}{"replicas": 3}),
+		{Kind: "Secret", Namespace: "ingress", Name: "sh.helm.release.v1.broken.v1", Raw: map[string]interface{}{"type": "helm.sh/release.v1"}, Data: map[string]interface{}{"release": "!!"}},
+		{Kind: "Deployment", Namespace: "ingress", Name: "controller"},
+	}}
+
+	releases := Releases(snapshot)
+	require.Len(t, releases, 1)
+	r := releases[0]
+	assert.Equal(t, "ingress-nginx", r.Name)
+	assert.Equal(t, "ingress", r.Namespace)
+	assert.Equal(t, 3, r.Revision)
+	assert.Equal(t, "ingress-nginx", r.Chart)
+	assert.Equal(t, "4.8.0", r.ChartVersion)
+	assert.Equal(t, "1.9.0", r.AppVersion)
+	assert.Equal(t, "deployed", r.Status)
+	assert.Len(t, r.ValuesHash, 12)
+
+	other := releaseSecret(t, "ingress-nginx", 3, "4.8.0", map[string]interface{}{"replicas": 4})
+	changed := Releases(&types.ResourceSnapshot{Resources: []types.Resource{other}})
+	assert.NotEqual(t, r.ValuesHash, changed[0].ValuesHash)
+}
+
+func TestAnnotate(t *testing.T) {
+	report := &types.DriftReport{Entries: []types.DriftEntry{
+		{Resource: types.Resource{Kind: "Deployment", Namespace: "ingress", Annotations: map[string]string{"meta.helm.sh/release-name": "ingress-nginx"}}},
+		{Resource: types.Resource{Kind: "ConfigMap", Namespace: "ingress"}},
+	}}
+	Annotate(report)
+	assert.Equal(t, "ingress/ingress-nginx", ReleaseOfEntry(report.Entries[0]))
+	assert.Equal(t, NoRelease, ReleaseOfEntry(report.Entries[1]))
+}
@@ -0,0 +1,99 @@
+// Package hooks runs user-configured commands around snapshots.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// envPrefix namespaces hook variables. It differs from the GTM_ prefix used
+// for configuration so that a hook invoking gitops-time-machine is not
+// reconfigured by them.
+const envPrefix = "GITOPS_TM_"
+
+const (
+	// PreSnapshot runs before collection.
+	PreSnapshot = "pre_snapshot"
+	// PostCommit runs after a snapshot was committed.
+	PostCommit = "post_commit"
+)
+
+// Run executes the named hook, if configured, with env added to the
+// process environment. Its output is passed through to stderr.
+func Run(ctx context.Context, cfg *config.HooksConfig, name string, env map[string]string) error {
+	command := commandFor(cfg, name)
+	if len(command) == 0 {
+		return nil
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, envPrefix+"HOOK="+name)
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, envPrefix+k+"="+v)
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	log.WithFields(log.Fields{
+		"hook":     name,
+		"duration": time.Since(start),
+	}).Debug("hook completed")
+	return nil
+}
+
+// commandFor returns the configured command for a hook name.
+func commandFor(cfg *config.HooksConfig, name string) []string {
+	switch name {
+	case PreSnapshot:
+		return cfg.PreSnapshot
+	case PostCommit:
+		return cfg.PostCommit
+	}
+	return nil
+}
+
+// Env describes a snapshot in hook environment variables (without prefix).
+func Env(cfg *config.Config, metadata *types.SnapshotMetadata, branch string) map[string]string {
+	env := map[string]string{
+		"OUTPUT_DIR": cfg.Snapshot.OutputDir,
+		"CONTEXT":    cfg.Context,
+	}
+	if metadata == nil {
+		return env
+	}
+
+	if branch == "" {
+		branch = cfg.Git.Branch
+	}
+	env["BRANCH"] = branch
+	env["COMMIT"] = metadata.CommitHash
+	env["COMMIT_URL"] = metadata.CommitURL
+	env["TIMESTAMP"] = metadata.Timestamp.Format(time.RFC3339)
+	env["CLUSTER"] = metadata.ClusterName
+	env["RESOURCE_COUNT"] = strconv.Itoa(metadata.ResourceCount)
+	env["NAMESPACES"] = strings.Join(metadata.Namespaces, ",")
+	if metadata.Context != "" {
+		env["CONTEXT"] = metadata.Context
+	}
+	return env
+}
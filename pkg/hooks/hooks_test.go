@@ -0,0 +1,46 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_PassesMetadataEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "env")
+	cfg := config.DefaultConfig()
+	cfg.Hooks.PostCommit = []string{"sh", "-c", `echo "$GITOPS_TM_HOOK $GITOPS_TM_COMMIT $GITOPS_TM_RESOURCE_COUNT $GITOPS_TM_BRANCH" > ` + out}
+
+	metadata := &types.SnapshotMetadata{
+		Timestamp:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CommitHash:    "abc123",
+		ResourceCount: 7,
+	}
+	require.NoError(t, Run(context.Background(), &cfg.Hooks, PostCommit, Env(cfg, metadata, "")))
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "post_commit abc123 7 main\n", string(content))
+}
+
+func TestRun_Failure(t *testing.T) {
+	cfg := &config.HooksConfig{PreSnapshot: []string{"sh", "-c", "exit 3"}}
+	err := Run(context.Background(), cfg, PreSnapshot, nil)
+	assert.ErrorContains(t, err, "pre_snapshot hook failed")
+}
+
+func TestRun_Timeout(t *testing.T) {
+	cfg := &config.HooksConfig{PreSnapshot: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond}
+	assert.Error(t, Run(context.Background(), cfg, PreSnapshot, nil))
+}
+
+func TestRun_Unconfigured(t *testing.T) {
+	assert.NoError(t, Run(context.Background(), &config.HooksConfig{}, PostCommit, nil))
+}
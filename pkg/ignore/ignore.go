@@ -0,0 +1,326 @@
+// Package ignore leaves configured fields and resources out of snapshot
+// comparisons, for those that change on their own and would otherwise be
+// reported as drift on every snapshot.
+package ignore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// AnyKind is the key of rules that apply to every kind.
+const AnyKind = "*"
+
+// Validate checks that every configured path can be parsed and names a
+// field that is compared, and that every resource rule is well-formed.
+func Validate(cfg *config.IgnoreConfig) error {
+	for kind, paths := range cfg.Fields {
+		for _, p := range paths {
+			if _, err := ParsePath(p); err != nil {
+				return fmt.Errorf("ignore.fields.%s: %w", kind, err)
+			}
+		}
+	}
+	return validateResources(cfg.Resources)
+}
+
+// Key is one step of a field path: a map key, or with Element set a list
+// element, selected by its name or, in lists whose elements have no
+// distinct names, by its position.
+type Key struct {
+	Name    string
+	Element bool
+}
+
+// MapKeys returns the keys of a path made only of map keys.
+func MapKeys(names ...string) []Key {
+	keys := make([]Key, len(names))
+	for i, name := range names {
+		keys[i] = Key{Name: name}
+	}
+	return keys
+}
+
+// SplitPath splits a field path into its keys, in the grammar the analyzer
+// reports drift in. Map keys are separated by dots; keys containing dots or
+// slashes are quoted in brackets, as in
+// .metadata.annotations["cert-manager.io/issuer"] or .data["tls.crt"].
+// List elements are selected in unquoted brackets, by name or position,
+// as in .spec.template.spec.containers[nginx].image or .spec.ports[0].
+func SplitPath(p string) ([]Key, error) {
+	var keys []Key
+	rest := p
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest[2:], `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated [\"", p)
+			}
+			key, err := strconv.Unquote(rest[1 : end+3])
+			if err != nil || key == "" {
+				return nil, fmt.Errorf("invalid path %q: bad quoted key", p)
+			}
+			keys = append(keys, Key{Name: key})
+			rest = rest[end+4:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unterminated [", p)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid path %q: empty list element", p)
+			}
+			keys = append(keys, Key{Name: rest[1:end], Element: true})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty key", p)
+			}
+			keys = append(keys, Key{Name: rest[:end]})
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected '.' or '['", p)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid path %q: empty", p)
+	}
+	return keys, nil
+}
+
+// ParsePath splits a field path that can be ignored into its keys; see
+// SplitPath. Only labels, annotations, spec, and data are compared, so
+// paths must be within one of them.
+func ParsePath(p string) ([]Key, error) {
+	keys, err := SplitPath(p)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case keys[0].Element:
+	case keys[0].Name == "metadata" && len(keys) == 3 && (keys[1].Name == "labels" || keys[1].Name == "annotations") &&
+		!keys[1].Element && !keys[2].Element:
+		return keys, nil
+	case keys[0].Name == "spec" || keys[0].Name == "data":
+		return keys, nil
+	}
+	return nil, fmt.Errorf("invalid path %q: only .metadata.labels[...], .metadata.annotations[...], .spec, and .data fields are compared", p)
+}
+
+// FormatKey formats one key of a path as SplitPath reads it back, quoting
+// map keys that need it.
+func FormatKey(key Key) string {
+	switch {
+	case key.Element:
+		return "[" + key.Name + "]"
+	case key.Name == "" || strings.ContainsAny(key.Name, `./[]"`):
+		return "[" + strconv.Quote(key.Name) + "]"
+	default:
+		return "." + key.Name
+	}
+}
+
+// FormatPath joins keys into a path that ParsePath reads back.
+func FormatPath(keys []Key) string {
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(FormatKey(key))
+	}
+	return b.String()
+}
+
+// Enabled reports whether any field is ignored.
+func Enabled(cfg *config.IgnoreConfig) bool {
+	for _, paths := range cfg.Fields {
+		if len(paths) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether a path of a kind is already ignored.
+func Covers(cfg *config.IgnoreConfig, kind, path string) bool {
+	for _, p := range paths(cfg, kind) {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns copies of both snapshots with the ignored fields removed
+// from every resource, and the number of resources that had any. The
+// snapshots themselves are not modified.
+func Apply(cfg *config.IgnoreConfig, base, target *types.ResourceSnapshot) (*types.ResourceSnapshot, *types.ResourceSnapshot, int) {
+	if !Enabled(cfg) {
+		return base, target, 0
+	}
+	rules := make(map[string][][]Key)
+	stripped := make(map[string]bool)
+	strip := func(snapshot *types.ResourceSnapshot) *types.ResourceSnapshot {
+		out := *snapshot
+		out.Resources = make([]types.Resource, len(snapshot.Resources))
+		for i, res := range snapshot.Resources {
+			kind := strings.ToLower(res.Kind)
+			if _, ok := rules[kind]; !ok {
+				for _, p := range paths(cfg, res.Kind) {
+					// Validated when the config was loaded
+					if keys, err := ParsePath(p); err == nil {
+						rules[kind] = append(rules[kind], keys)
+					}
+				}
+			}
+			// Both sides lose the same fields, so resources with equal
+			// ContentHash still have equal content afterwards
+			for _, keys := range rules[kind] {
+				if removeField(&res, keys) {
+					stripped[res.FullName()] = true
+				}
+			}
+			out.Resources[i] = res
+		}
+		return &out
+	}
+	return strip(base), strip(target), len(stripped)
+}
+
+// paths returns the paths ignored for a kind, including those for every kind.
+func paths(cfg *config.IgnoreConfig, kind string) []string {
+	var out []string
+	for k, p := range cfg.Fields {
+		if k == AnyKind || strings.EqualFold(k, kind) {
+			out = append(out, p...)
+		}
+	}
+	return out
+}
+
+// removeField removes a field from a resource, copying rather than
+// modifying the maps and lists on its path, and reports whether it was
+// present.
+func removeField(res *types.Resource, keys []Key) bool {
+	switch keys[0].Name {
+	case "metadata":
+		m := &res.Labels
+		if keys[1].Name == "annotations" {
+			m = &res.Annotations
+		}
+		if _, ok := (*m)[keys[2].Name]; !ok {
+			return false
+		}
+		out := make(map[string]string, len(*m))
+		for k, v := range *m {
+			if k != keys[2].Name {
+				out[k] = v
+			}
+		}
+		if len(out) == 0 {
+			out = nil
+		}
+		*m = out
+		return true
+	case "spec":
+		out, ok := removeKey(res.Spec, keys[1:])
+		if ok {
+			res.Spec, _ = out.(map[string]interface{})
+		}
+		return ok
+	case "data":
+		out, ok := removeKey(res.Data, keys[1:])
+		if ok {
+			res.Data, _ = out.(map[string]interface{})
+		}
+		return ok
+	}
+	return false
+}
+
+// removeKey returns a copy of v, a map or list, without the nested key,
+// dropping maps and lists left empty by its removal so they do not show up
+// as a difference of their own. It returns nil when v itself is left empty.
+func removeKey(v interface{}, keys []Key) (interface{}, bool) {
+	if len(keys) == 0 {
+		return v, false
+	}
+	key := keys[0]
+	if key.Element {
+		list, ok := v.([]interface{})
+		if !ok {
+			return v, false
+		}
+		i := elementIndex(list, key.Name)
+		if i < 0 {
+			return v, false
+		}
+		var child interface{}
+		if len(keys) > 1 {
+			if child, ok = removeKey(list[i], keys[1:]); !ok {
+				return v, false
+			}
+		}
+		out := make([]interface{}, 0, len(list))
+		out = append(out, list[:i]...)
+		if child != nil {
+			out = append(out, child)
+		}
+		out = append(out, list[i+1:]...)
+		if len(out) == 0 {
+			return nil, true
+		}
+		return out, true
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v, false
+	}
+	val, ok := m[key.Name]
+	if !ok {
+		return v, false
+	}
+	var child interface{}
+	if len(keys) > 1 {
+		if child, ok = removeKey(val, keys[1:]); !ok {
+			return v, false
+		}
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	if child == nil {
+		delete(out, key.Name)
+	} else {
+		out[key.Name] = child
+	}
+	if len(out) == 0 {
+		return nil, true
+	}
+	return out, true
+}
+
+// elementIndex returns the index of the list element a path selects: the
+// element with that name, or else the element at that position. It returns
+// -1 if there is none.
+func elementIndex(list []interface{}, name string) int {
+	for i, elem := range list {
+		if m, ok := elem.(map[string]interface{}); ok && m["name"] == name {
+			return i
+		}
+	}
+	if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(list) {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,118 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePath(t *testing.T) {
+	keys, err := ParsePath(`.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`)
+	require.NoError(t, err)
+	assert.Equal(t, MapKeys("metadata", "annotations", "kubectl.kubernetes.io/restartedAt"), keys)
+
+	keys, err = ParsePath(`.data["tls.crt"]`)
+	require.NoError(t, err)
+	assert.Equal(t, MapKeys("data", "tls.crt"), keys)
+
+	keys, err = ParsePath(".spec.template.metadata.labels.hash")
+	require.NoError(t, err)
+	assert.Equal(t, MapKeys("spec", "template", "metadata", "labels", "hash"), keys)
+
+	keys, err = ParsePath(".spec.template.spec.containers[nginx].env[LOG_LEVEL].value")
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Name: "spec"}, {Name: "template"}, {Name: "spec"}, {Name: "containers"},
+		{Name: "nginx", Element: true}, {Name: "env"}, {Name: "LOG_LEVEL", Element: true}, {Name: "value"}}, keys)
+
+	for _, bad := range []string{"", "spec", ".spec..replicas", `.data["tls.crt`, ".status.phase", ".metadata.annotations",
+		".spec.ports[", ".spec.ports[]", "[0].spec", `.metadata.labels[app]`} {
+		_, err := ParsePath(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFormatPathRoundTrips(t *testing.T) {
+	for _, keys := range [][]Key{
+		MapKeys("metadata", "annotations", "cert-manager.io/issuer"),
+		MapKeys("data", "tls.crt"),
+		MapKeys("spec", "replicas"),
+		{{Name: "spec"}, {Name: "ports"}, {Name: "0", Element: true}, {Name: "port"}},
+	} {
+		parsed, err := ParsePath(FormatPath(keys))
+		require.NoError(t, err)
+		assert.Equal(t, keys, parsed)
+	}
+	assert.Equal(t, `.data["tls.crt"]`, FormatPath(MapKeys("data", "tls.crt")))
+	assert.Equal(t, `.spec.containers[nginx].image`, FormatPath([]Key{{Name: "spec"}, {Name: "containers"}, {Name: "nginx", Element: true}, {Name: "image"}}))
+}
+
+func TestApply_ListElements(t *testing.T) {
+	deploy := func(image string) types.Resource {
+		return types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Spec: map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": image},
+				map[string]interface{}{"name": "sidecar", "image": "envoy"},
+			},
+			"args": []interface{}{"--port", "80"},
+		}}
+	}
+	cfg := &config.IgnoreConfig{Fields: map[string][]string{"deployment": {".spec.containers[app].image", ".spec.args[1]"}}}
+	require.NoError(t, Validate(cfg))
+
+	_, tg, n := Apply(cfg, &types.ResourceSnapshot{}, &types.ResourceSnapshot{Resources: []types.Resource{deploy("app:2")}})
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "app"},
+		map[string]interface{}{"name": "sidecar", "image": "envoy"},
+	}, tg.Resources[0].Spec["containers"])
+	assert.Equal(t, []interface{}{"--port"}, tg.Resources[0].Spec["args"])
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.IgnoreConfig{Fields: map[string][]string{"deployment": {".spec.replicas"}}}))
+	assert.Error(t, Validate(&config.IgnoreConfig{Fields: map[string][]string{"deployment": {"replicas"}}}))
+}
+
+func TestApply(t *testing.T) {
+	cfg := &config.IgnoreConfig{Fields: map[string][]string{
+		// viper reads kinds in lower case
+		"deployment": {`.spec.template.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`},
+		"*":          {`.metadata.annotations["example.com/heartbeat"]`},
+	}}
+	deploy := func(restarted, heartbeat string) types.Resource {
+		return types.Resource{
+			Kind: "Deployment", Namespace: "prod", Name: "api",
+			Annotations: map[string]string{"example.com/heartbeat": heartbeat},
+			Spec: map[string]interface{}{
+				"replicas": 2,
+				"template": map[string]interface{}{"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{"kubectl.kubernetes.io/restartedAt": restarted},
+				}},
+			},
+		}
+	}
+	base := &types.ResourceSnapshot{Resources: []types.Resource{deploy("t1", "h1")}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{deploy("t2", "h2")}}
+
+	b, tg, n := Apply(cfg, base, target)
+	assert.Equal(t, 1, n)
+	assert.Nil(t, b.Resources[0].Annotations)
+	assert.Equal(t, map[string]interface{}{"replicas": 2}, tg.Resources[0].Spec, "maps emptied by the removal are dropped")
+
+	// The input snapshots are not modified
+	assert.Equal(t, "h1", base.Resources[0].Annotations["example.com/heartbeat"])
+	assert.Contains(t, base.Resources[0].Spec, "template")
+
+	b, _, n = Apply(&config.IgnoreConfig{}, base, target)
+	assert.Same(t, base, b)
+	assert.Zero(t, n)
+}
+
+func TestCovers(t *testing.T) {
+	cfg := &config.IgnoreConfig{Fields: map[string][]string{"secret": {`.data["tls.crt"]`}}}
+	assert.True(t, Covers(cfg, "Secret", `.data["tls.crt"]`))
+	assert.False(t, Covers(cfg, "ConfigMap", `.data["tls.crt"]`))
+}
@@ -0,0 +1,89 @@
+package ignore
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// validateResources checks that every resource rule selects something and
+// that its patterns and selector parse.
+func validateResources(rules []config.IgnoreResource) error {
+	for i, rule := range rules {
+		if rule == (config.IgnoreResource{}) {
+			return fmt.Errorf("ignore.resources[%d] needs a kind, namespace, name, or selector", i)
+		}
+		for _, pattern := range []string{rule.Namespace, rule.Name} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("ignore.resources[%d]: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+		if _, err := labels.Parse(rule.Selector); err != nil {
+			return fmt.Errorf("ignore.resources[%d]: invalid selector %q: %w", i, rule.Selector, err)
+		}
+	}
+	return nil
+}
+
+// resourceRule is an IgnoreResource with its selector parsed.
+type resourceRule struct {
+	config.IgnoreResource
+	selector labels.Selector
+}
+
+// matches reports whether the rule selects the resource.
+func (r resourceRule) matches(res types.Resource) bool {
+	if r.Kind != "" && !strings.EqualFold(r.Kind, res.Kind) {
+		return false
+	}
+	if r.Namespace != "" {
+		if ok, _ := path.Match(r.Namespace, res.Namespace); !ok {
+			return false
+		}
+	}
+	if r.Name != "" {
+		if ok, _ := path.Match(r.Name, res.Name); !ok {
+			return false
+		}
+	}
+	return r.selector.Matches(labels.Set(res.Labels))
+}
+
+// Exclude returns copies of both snapshots without the resources matching
+// an ignore.resources rule, and the number of distinct resources left out.
+// A resource is left out of both sides if it matches on either, so that
+// gaining or losing a label does not show up as an addition or removal.
+func Exclude(cfg *config.IgnoreConfig, base, target *types.ResourceSnapshot) (*types.ResourceSnapshot, *types.ResourceSnapshot, int) {
+	if len(cfg.Resources) == 0 {
+		return base, target, 0
+	}
+	var rules []resourceRule
+	for _, r := range cfg.Resources {
+		// Validated when the config was loaded
+		selector, err := labels.Parse(r.Selector)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, resourceRule{IgnoreResource: r, selector: selector})
+	}
+
+	ignored := make(map[string]bool)
+	for _, snapshot := range []*types.ResourceSnapshot{base, target} {
+		for _, res := range snapshot.Resources {
+			for _, rule := range rules {
+				if rule.matches(res) {
+					ignored[res.FullName()] = true
+					break
+				}
+			}
+		}
+	}
+	if len(ignored) == 0 {
+		return base, target, 0
+	}
+	return base.Without(ignored), target.Without(ignored), len(ignored)
+}
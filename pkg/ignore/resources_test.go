@@ -0,0 +1,72 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateResources(t *testing.T) {
+	valid := []config.IgnoreResource{
+		{Kind: "Secret", Selector: "cert-manager.io/certificate-name"},
+		{Namespace: "ci-*", Name: "runner-?"},
+		{Selector: "app in (cache, queue),tier!=db"},
+	}
+	assert.NoError(t, Validate(&config.IgnoreConfig{Resources: valid}))
+
+	for _, bad := range []config.IgnoreResource{
+		{},
+		{Name: "runner-["},
+		{Kind: "Pod", Selector: "app in (cache"},
+	} {
+		assert.Error(t, Validate(&config.IgnoreConfig{Resources: []config.IgnoreResource{bad}}), "%+v", bad)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	cfg := &config.IgnoreConfig{Resources: []config.IgnoreResource{
+		// Kinds match case-insensitively
+		{Kind: "secret", Selector: "controller.cert-manager.io/fao=true"},
+		{Namespace: "ci-*"},
+		{Kind: "ConfigMap", Name: "*-leader"},
+	}}
+	res := func(kind, namespace, name string, labels map[string]string) types.Resource {
+		return types.Resource{Kind: kind, Namespace: namespace, Name: name, Labels: labels}
+	}
+	certLabel := map[string]string{"controller.cert-manager.io/fao": "true"}
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		res("Secret", "prod", "tls", nil),
+		res("Secret", "prod", "db", nil),
+		res("Pod", "ci-1234", "runner", nil),
+		res("ConfigMap", "prod", "operator-leader", nil),
+		res("ConfigMap", "prod", "settings", nil),
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		res("Secret", "prod", "tls", certLabel),
+		res("Secret", "prod", "db", nil),
+		res("ConfigMap", "prod", "operator-leader", nil),
+		res("ConfigMap", "prod", "settings", nil),
+	}}
+
+	b, tg, n := Exclude(cfg, base, target)
+
+	assert.Equal(t, 3, n)
+	names := func(s *types.ResourceSnapshot) []string {
+		var out []string
+		for _, r := range s.Resources {
+			out = append(out, r.FullName())
+		}
+		return out
+	}
+	assert.Equal(t, []string{"prod/Secret/db", "prod/ConfigMap/settings"}, names(b),
+		"a resource labelled on one side only is left out of both")
+	assert.Equal(t, []string{"prod/Secret/db", "prod/ConfigMap/settings"}, names(tg))
+	require.Len(t, base.Resources, 5, "the input snapshots are not modified")
+
+	b, _, n = Exclude(&config.IgnoreConfig{}, base, target)
+	assert.Same(t, base, b)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,194 @@
+// Package immutable tracks immutable and server-owned fields per resource kind
+// so that replays of historical state do not fail on API validation.
+package immutable
+
+import (
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Action describes how a field should be handled when replaying a resource.
+type Action string
+
+const (
+	// ActionStrip removes the field because the API server owns it.
+	ActionStrip Action = "strip"
+	// ActionWarn keeps the field but flags it, since it is required on
+	// create yet cannot be changed on an existing object.
+	ActionWarn Action = "warn"
+)
+
+// Rule describes a single immutable or server-owned field.
+type Rule struct {
+	Path   string `json:"path" yaml:"path"`
+	Action Action `json:"action" yaml:"action"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// Finding reports a rule that matched a field present on a resource.
+type Finding struct {
+	Rule     Rule        `json:"rule" yaml:"rule"`
+	Resource string      `json:"resource" yaml:"resource"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// commonRules apply to every kind.
+var commonRules = []Rule{
+	{Path: ".metadata.uid", Action: ActionStrip, Reason: "assigned by the API server"},
+	{Path: ".metadata.resourceVersion", Action: ActionStrip, Reason: "assigned by the API server"},
+	{Path: ".metadata.creationTimestamp", Action: ActionStrip, Reason: "assigned by the API server"},
+	{Path: ".metadata.selfLink", Action: ActionStrip, Reason: "assigned by the API server"},
+	{Path: ".metadata.generation", Action: ActionStrip, Reason: "assigned by the API server"},
+	{Path: ".metadata.managedFields", Action: ActionStrip, Reason: "owned by server-side apply"},
+}
+
+// kindRules maps a Kind to its immutable or server-owned fields.
+var kindRules = map[string][]Rule{
+	"Service": {
+		{Path: ".spec.clusterIP", Action: ActionStrip, Reason: "allocated by the API server and immutable"},
+		{Path: ".spec.clusterIPs", Action: ActionStrip, Reason: "allocated by the API server and immutable"},
+		{Path: ".spec.healthCheckNodePort", Action: ActionStrip, Reason: "allocated by the API server"},
+	},
+	"PersistentVolumeClaim": {
+		{Path: ".spec.volumeName", Action: ActionStrip, Reason: "set when the claim is bound"},
+		{Path: ".spec.storageClassName", Action: ActionWarn, Reason: "immutable after creation"},
+		{Path: ".spec.volumeMode", Action: ActionWarn, Reason: "immutable after creation"},
+	},
+	"Deployment": {
+		{Path: ".spec.selector", Action: ActionWarn, Reason: "immutable in apps/v1"},
+	},
+	"ReplicaSet": {
+		{Path: ".spec.selector", Action: ActionWarn, Reason: "immutable in apps/v1"},
+	},
+	"DaemonSet": {
+		{Path: ".spec.selector", Action: ActionWarn, Reason: "immutable in apps/v1"},
+	},
+	"StatefulSet": {
+		{Path: ".spec.selector", Action: ActionWarn, Reason: "immutable in apps/v1"},
+		{Path: ".spec.serviceName", Action: ActionWarn, Reason: "immutable after creation"},
+		{Path: ".spec.volumeClaimTemplates", Action: ActionWarn, Reason: "immutable after creation"},
+		{Path: ".spec.podManagementPolicy", Action: ActionWarn, Reason: "immutable after creation"},
+	},
+	"Job": {
+		{Path: ".spec.selector", Action: ActionStrip, Reason: "generated by the job controller"},
+		{Path: ".spec.template", Action: ActionWarn, Reason: "immutable after creation"},
+	},
+	"ConfigMap": {
+		{Path: ".immutable", Action: ActionWarn, Reason: "immutable ConfigMaps cannot be updated"},
+	},
+	"Secret": {
+		{Path: ".immutable", Action: ActionWarn, Reason: "immutable Secrets cannot be updated"},
+		{Path: ".type", Action: ActionWarn, Reason: "immutable after creation"},
+	},
+}
+
+// RulesFor returns the rules that apply to the given kind, including common rules.
+func RulesFor(kind string) []Rule {
+	rules := make([]Rule, 0, len(commonRules)+len(kindRules[kind]))
+	rules = append(rules, commonRules...)
+	rules = append(rules, kindRules[kind]...)
+	return rules
+}
+
+// Apply removes server-owned fields from the resource and returns a finding for
+// every rule that matched, stripped or not. Both Raw and Spec are updated so the
+// resource stays internally consistent.
+func Apply(res *types.Resource) []Finding {
+	var findings []Finding
+
+	for _, rule := range RulesFor(res.Kind) {
+		value, ok := lookup(res, rule.Path)
+		if !ok {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Rule:     rule,
+			Resource: res.FullName(),
+			Value:    value,
+		})
+
+		if rule.Action == ActionStrip {
+			strip(res, rule.Path)
+		}
+	}
+
+	return findings
+}
+
+// Warnings filters findings down to those that were kept but need attention.
+func Warnings(findings []Finding) []Finding {
+	var warnings []Finding
+	for _, f := range findings {
+		if f.Rule.Action == ActionWarn {
+			warnings = append(warnings, f)
+		}
+	}
+	return warnings
+}
+
+// lookup finds a field in Raw, falling back to Spec for .spec paths.
+func lookup(res *types.Resource, path string) (interface{}, bool) {
+	segments := splitPath(path)
+	if res.Raw != nil {
+		if v, ok := getPath(res.Raw, segments); ok {
+			return v, true
+		}
+	}
+	if segments[0] == "spec" && res.Spec != nil {
+		return getPath(res.Spec, segments[1:])
+	}
+	return nil, false
+}
+
+// strip deletes a field from Raw and, for .spec paths, from Spec.
+func strip(res *types.Resource, path string) {
+	segments := splitPath(path)
+	if res.Raw != nil {
+		deletePath(res.Raw, segments)
+	}
+	if segments[0] == "spec" && res.Spec != nil {
+		deletePath(res.Spec, segments[1:])
+	}
+}
+
+// splitPath turns ".spec.clusterIP" into ["spec", "clusterIP"].
+func splitPath(path string) []string {
+	return strings.Split(strings.TrimPrefix(path, "."), ".")
+}
+
+// getPath walks nested maps following the given segments.
+func getPath(obj map[string]interface{}, segments []string) (interface{}, bool) {
+	if len(segments) == 0 {
+		return nil, false
+	}
+	var current interface{} = obj
+	for _, seg := range segments {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[seg]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// deletePath removes the field at the given segments, if present.
+func deletePath(obj map[string]interface{}, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	current := obj
+	for _, seg := range segments[:len(segments)-1] {
+		next, ok := current[seg].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+	delete(current, segments[len(segments)-1])
+}
@@ -0,0 +1,70 @@
+package immutable
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply_StripsServiceClusterIP(t *testing.T) {
+	res := &types.Resource{
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "web",
+		Spec:      map[string]interface{}{"clusterIP": "10.0.0.12", "type": "ClusterIP"},
+		Raw: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "uid": "abc"},
+			"spec":     map[string]interface{}{"clusterIP": "10.0.0.12", "type": "ClusterIP"},
+		},
+	}
+
+	findings := Apply(res)
+
+	require.Len(t, findings, 2)
+	assert.NotContains(t, res.Spec, "clusterIP")
+	assert.NotContains(t, res.Raw["spec"], "clusterIP")
+	assert.NotContains(t, res.Raw["metadata"], "uid")
+	assert.Equal(t, "ClusterIP", res.Spec["type"])
+	assert.Empty(t, Warnings(findings))
+}
+
+func TestApply_WarnsOnSelector(t *testing.T) {
+	selector := map[string]interface{}{"matchLabels": map[string]interface{}{"app": "api"}}
+	res := &types.Resource{
+		Kind:      "Deployment",
+		Namespace: "prod",
+		Name:      "api",
+		Spec:      map[string]interface{}{"selector": selector},
+	}
+
+	findings := Apply(res)
+
+	warnings := Warnings(findings)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, ".spec.selector", warnings[0].Rule.Path)
+	assert.Equal(t, "prod/Deployment/api", warnings[0].Resource)
+	assert.Contains(t, res.Spec, "selector")
+}
+
+func TestApply_UnknownKind(t *testing.T) {
+	res := &types.Resource{
+		Kind: "Widget",
+		Name: "w",
+		Raw:  map[string]interface{}{"spec": map[string]interface{}{"size": 3}},
+	}
+
+	assert.Empty(t, Apply(res))
+}
+
+func TestRulesFor_IncludesCommon(t *testing.T) {
+	rules := RulesFor("PersistentVolumeClaim")
+
+	var paths []string
+	for _, r := range rules {
+		paths = append(paths, r.Path)
+	}
+	assert.Contains(t, paths, ".metadata.uid")
+	assert.Contains(t, paths, ".spec.storageClassName")
+}
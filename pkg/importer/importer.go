@@ -0,0 +1,297 @@
+// Package importer reads a directory of Kubernetes manifests (e.g. a GitOps
+// repository) into a snapshot, so that the state they declare can seed the
+// history as a baseline before the first snapshot of the cluster.
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultNamespace is given to namespaced resources without a namespace, as
+// kubectl apply would.
+const DefaultNamespace = "default"
+
+// clusterScoped lists the built-in kinds that have no namespace.
+// CustomResourceDefinitions in the imported directory add their own.
+var clusterScoped = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"CustomResourceDefinition":       true,
+	"FlowSchema":                     true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PriorityClass":                  true,
+	"PriorityLevelConfiguration":     true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingAdmissionPolicy":      true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// Options tunes which manifests are imported and how.
+type Options struct {
+	// Namespace is given to namespaced resources without one. Empty uses
+	// DefaultNamespace.
+	Namespace string
+	// Namespaces, if set, limits the import to these namespaces, as
+	// snapshot.namespaces limits collection.
+	Namespaces []string
+	// ExcludeNamespaces leaves these namespaces out, as
+	// snapshot.exclude_namespaces does.
+	ExcludeNamespaces []string
+	// Snapshot, if set, strips, prunes, and redacts resources as the
+	// collector does with this configuration (snapshot.strip_fields,
+	// snapshot.prune, snapshot.redact_env).
+	Snapshot *config.SnapshotConfig
+}
+
+// manifest is one resource read from a file.
+type manifest struct {
+	obj  map[string]interface{}
+	file string
+}
+
+// Load reads every .yaml, .yml, and .json file under dir, skipping hidden
+// directories such as .git, into a snapshot without metadata beyond its
+// namespaces and counts. Documents that are not Kubernetes resources (e.g.
+// Helm values or kustomization.yaml) are skipped with a warning; templates
+// have to be rendered first. A resource defined twice is an error.
+func Load(dir string, opts Options) (*types.ResourceSnapshot, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+
+	var manifests []manifest
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		objs, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		for _, obj := range objs {
+			manifests = append(manifests, manifest{obj: obj, file: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+
+	scoped := clusterScopedKinds(manifests)
+	snapshot := &types.ResourceSnapshot{}
+	defined := make(map[string]string)
+	namespaces := make(map[string]bool)
+	for _, m := range manifests {
+		res, ok := toResource(m, scoped, opts)
+		if !ok {
+			continue
+		}
+		if !included(res.Namespace, opts) {
+			continue
+		}
+		if file, ok := defined[res.FullName()]; ok {
+			return nil, fmt.Errorf("%s is defined in both %s and %s", res.FullName(), file, m.file)
+		}
+		defined[res.FullName()] = m.file
+		snapshot.Resources = append(snapshot.Resources, res)
+		if res.Namespace != "" {
+			namespaces[res.Namespace] = true
+		}
+	}
+
+	for ns := range namespaces {
+		snapshot.Metadata.Namespaces = append(snapshot.Metadata.Namespaces, ns)
+	}
+	sort.Strings(snapshot.Metadata.Namespaces)
+	snapshot.UpdateCounts()
+	return snapshot, nil
+}
+
+// readFile decodes every document of a manifest file, expanding List
+// kinds into their items.
+func readFile(path string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var objs []map[string]interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj map[string]interface{}
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if kind, _ := obj["kind"].(string); strings.HasSuffix(kind, "List") {
+			if items, ok := obj["items"].([]interface{}); ok {
+				for _, item := range items {
+					if m, ok := item.(map[string]interface{}); ok {
+						objs = append(objs, m)
+					}
+				}
+				continue
+			}
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// clusterScopedKinds returns the built-in cluster-scoped kinds and those of
+// the cluster-scoped CustomResourceDefinitions among the manifests.
+func clusterScopedKinds(manifests []manifest) map[string]bool {
+	scoped := make(map[string]bool, len(clusterScoped))
+	for kind := range clusterScoped {
+		scoped[kind] = true
+	}
+	for _, m := range manifests {
+		if m.obj["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+		spec, _ := m.obj["spec"].(map[string]interface{})
+		names, _ := spec["names"].(map[string]interface{})
+		if kind, _ := names["kind"].(string); kind != "" && spec["scope"] == "Cluster" {
+			scoped[kind] = true
+		}
+	}
+	return scoped
+}
+
+// toResource converts a manifest into a resource as the collector would
+// have captured it once applied. It reports false for documents that are
+// not Kubernetes resources.
+func toResource(m manifest, scoped map[string]bool, opts Options) (types.Resource, bool) {
+	obj := m.obj
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	if strings.HasPrefix(apiVersion, "kustomize.config.k8s.io/") {
+		return types.Resource{}, false
+	}
+	if apiVersion == "" || kind == "" || name == "" {
+		log.WithField("file", m.file).Warn("skipping a document that is not a Kubernetes resource")
+		return types.Resource{}, false
+	}
+
+	if scoped[kind] {
+		delete(metadata, "namespace")
+	} else if ns, _ := metadata["namespace"].(string); ns == "" {
+		metadata["namespace"] = opts.Namespace
+	}
+	delete(obj, "status")
+	if kind == "Secret" {
+		encodeStringData(obj)
+	}
+
+	// The API server stores labels and annotations as strings
+	item := &unstructured.Unstructured{Object: obj}
+	item.SetLabels(snapshotter.StringMap(metadata["labels"]))
+	item.SetAnnotations(snapshotter.StringMap(metadata["annotations"]))
+	snapshotCfg := opts.Snapshot
+	if snapshotCfg == nil {
+		snapshotCfg = &config.SnapshotConfig{}
+	}
+	annotations := collector.Clean(item, snapshotCfg)
+
+	ns, _ := metadata["namespace"].(string)
+	res := types.Resource{
+		APIVersion:  apiVersion,
+		Kind:        kind,
+		Namespace:   ns,
+		Name:        name,
+		Labels:      item.GetLabels(),
+		Annotations: annotations,
+		Raw:         obj,
+	}
+	if spec, ok := obj["spec"].(map[string]interface{}); ok {
+		res.Spec = spec
+	}
+	if data, ok := obj["data"].(map[string]interface{}); ok {
+		res.Data = data
+	}
+	return res, true
+}
+
+// encodeStringData moves a Secret's stringData into data, base64-encoded,
+// as the API server stores it.
+func encodeStringData(obj map[string]interface{}) {
+	stringData, ok := obj["stringData"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	data, _ := obj["data"].(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{}, len(stringData))
+	}
+	for k, v := range stringData {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+	}
+	obj["data"] = data
+	delete(obj, "stringData")
+}
+
+// included applies the namespace filters as the collector does, so that
+// the baseline holds what a snapshot of the cluster would.
+func included(namespace string, opts Options) bool {
+	for _, ns := range opts.ExcludeNamespaces {
+		if ns == namespace {
+			return false
+		}
+	}
+	if len(opts.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range opts.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
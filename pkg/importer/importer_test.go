@@ -0,0 +1,190 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates files under a temporary directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"apps/web.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: "{}"
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  mode: prod
+`,
+		"cluster/roles.yaml": `apiVersion: v1
+kind: List
+items:
+  - apiVersion: rbac.authorization.k8s.io/v1
+    kind: ClusterRole
+    metadata:
+      name: reader
+      namespace: ignored
+`,
+		"kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources: [apps/web.yaml]\n",
+		"values.yaml":        "replicas: 3\n",
+		"README.md":          "# manifests\n",
+		".git/config.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hidden\n",
+	})
+
+	snapshot, err := Load(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 3)
+
+	byName := make(map[string]int)
+	for i, res := range snapshot.Resources {
+		byName[res.FullName()] = i
+	}
+	require.Contains(t, byName, "shop/Deployment/web")
+	require.Contains(t, byName, "default/ConfigMap/web-config")
+	require.Contains(t, byName, "ClusterRole/reader")
+
+	web := snapshot.Resources[byName["shop/Deployment/web"]]
+	assert.Nil(t, web.Annotations)
+	assert.Equal(t, 2, web.Spec["replicas"])
+	config := snapshot.Resources[byName["default/ConfigMap/web-config"]]
+	assert.Equal(t, "prod", config.Data["mode"])
+	assert.Equal(t, "default", config.Raw["metadata"].(map[string]interface{})["namespace"])
+
+	assert.Equal(t, []string{"default", "shop"}, snapshot.Metadata.Namespaces)
+	assert.Equal(t, 3, snapshot.Metadata.ResourceCount)
+}
+
+func TestLoad_ClusterScopedCRD(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"crd.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusterissuers.cert-manager.io
+spec:
+  scope: Cluster
+  names:
+    kind: ClusterIssuer
+`,
+		"issuer.yaml": "apiVersion: cert-manager.io/v1\nkind: ClusterIssuer\nmetadata:\n  name: letsencrypt\n",
+	})
+
+	snapshot, err := Load(dir, Options{Namespace: "apps"})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 2)
+	for _, res := range snapshot.Resources {
+		assert.Empty(t, res.Namespace, res.Kind)
+	}
+}
+
+func TestLoad_SecretStringData(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"secret.yaml": "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\nstringData:\n  password: hunter2\n",
+	})
+
+	snapshot, err := Load(dir, Options{})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "aHVudGVyMg==", snapshot.Resources[0].Data["password"])
+	assert.NotContains(t, snapshot.Resources[0].Raw, "stringData")
+}
+
+func TestLoad_NamespaceFilters(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: kube-system\n",
+		"b.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: shop\n",
+	})
+
+	snapshot, err := Load(dir, Options{ExcludeNamespaces: []string{"kube-system"}})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "b", snapshot.Resources[0].Name)
+}
+
+func TestLoad_Duplicate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n",
+		"b.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: default\n",
+	})
+
+	_, err := Load(dir, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default/ConfigMap/web is defined in both a.yaml and b.yaml")
+}
+
+func TestLoad_InvalidYAML(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"bad.yaml": "kind: [unclosed\n"})
+
+	_, err := Load(dir, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.yaml")
+}
+
+func TestLoad_CleansAsCollector(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"web.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+  generation: 3
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: "{}"
+    team: checkout
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+            - name: LOG_LEVEL
+              value: debug
+`,
+	})
+
+	snapshot, err := Load(dir, Options{Snapshot: &config.SnapshotConfig{
+		RedactEnv:   []string{"*_PASSWORD"},
+		StripFields: []string{".metadata.generation"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, map[string]string{"team": "checkout"}, snapshot.Resources[0].Annotations)
+
+	out := t.TempDir()
+	require.NoError(t, snapshotter.New(out).Write(snapshot))
+	data, err := os.ReadFile(filepath.Join(out, "shop", "deployment", "web.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), collector.RedactedValue)
+	assert.Contains(t, string(data), "debug")
+	assert.NotContains(t, string(data), "generation")
+	assert.NotContains(t, string(data), "last-applied-configuration")
+}
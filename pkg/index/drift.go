@@ -0,0 +1,118 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// driftFormat is part of every drift cache key; bump it when the analyzer
+// changes what it reports so that older cached reports are not reused.
+// 2: list elements and quoted keys in field paths, and partial snapshot scopes.
+const driftFormat = "2"
+
+// maxDriftReports bounds the drift cache; the least recently written
+// reports are removed beyond it.
+const maxDriftReports = 256
+
+// DriftDir returns the default drift cache location inside a snapshot
+// repository, next to the index.
+func DriftDir(repoPath string) string {
+	return filepath.Join(repoPath, ".git", "gitops-time-machine", "drift")
+}
+
+// DriftKey identifies the report between two commits. Settings that change
+// the comparison (e.g. ignore rules, the subtree compared) are passed as
+// settings, so that changing them computes a new report.
+func DriftKey(baseCommit, targetCommit string, settings interface{}) (string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode drift cache key: %w", err)
+	}
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%s\x00%s\x00%s\x00", driftFormat, types.SchemaVersion, baseCommit, targetCommit)
+	sum.Write(data)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// DriftCache stores drift reports between commit pairs, one file per
+// pair. Commits never change, so a cached report is valid for as long as
+// the settings in its key are.
+type DriftCache struct {
+	dir string
+}
+
+// OpenDriftCache returns the drift cache in dir, created on first write.
+func OpenDriftCache(dir string) *DriftCache {
+	return &DriftCache{dir: dir}
+}
+
+// Get returns the cached report for key, if any. An unreadable entry is
+// treated as a miss and recomputed.
+func (c *DriftCache) Get(key string) (*types.DriftReport, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var report types.DriftReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, false
+	}
+	return &report, true
+}
+
+// Put stores a report under key.
+func (c *DriftCache) Put(key string, report *types.DriftReport) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create drift cache directory: %w", err)
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode drift report: %w", err)
+	}
+	if err := os.WriteFile(c.path(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write drift cache: %w", err)
+	}
+	return c.prune()
+}
+
+func (c *DriftCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// prune removes the oldest reports beyond maxDriftReports.
+func (c *DriftCache) prune() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read drift cache: %w", err)
+	}
+	if len(entries) <= maxDriftReports {
+		return nil
+	}
+
+	type file struct {
+		name    string
+		modTime int64
+	}
+	files := make([]file, 0, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{e.Name(), info.ModTime().UnixNano()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime < files[j].modTime })
+	for _, f := range files[:max(len(files)-maxDriftReports, 0)] {
+		if err := os.Remove(filepath.Join(c.dir, f.name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to prune drift cache: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriftKey(t *testing.T) {
+	a, err := DriftKey("aaa", "bbb", map[string]string{"scope": ""})
+	require.NoError(t, err)
+	b, err := DriftKey("aaa", "bbb", map[string]string{"scope": ""})
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	swapped, _ := DriftKey("bbb", "aaa", map[string]string{"scope": ""})
+	scoped, _ := DriftKey("aaa", "bbb", map[string]string{"scope": "team-a"})
+	assert.NotEqual(t, a, swapped)
+	assert.NotEqual(t, a, scoped, "settings are part of the key")
+}
+
+func TestDriftCache(t *testing.T) {
+	cache := OpenDriftCache(DriftDir(t.TempDir()))
+
+	_, ok := cache.Get("missing")
+	assert.False(t, ok)
+
+	report := &types.DriftReport{
+		BaseRef: "aaa", TargetRef: "bbb",
+		Summary: types.DriftSummary{ModifiedResources: 1},
+		Entries: []types.DriftEntry{{
+			Type:       types.DriftModified,
+			Resource:   types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api"},
+			FieldDiffs: []types.FieldDiff{{Path: ".spec.replicas", OldValue: 2, NewValue: 3}},
+		}},
+	}
+	require.NoError(t, cache.Put("k", report))
+
+	cached, ok := cache.Get("k")
+	require.True(t, ok)
+	assert.Equal(t, report.Summary, cached.Summary)
+	require.Len(t, cached.Entries, 1)
+	assert.Equal(t, "prod/Deployment/api", cached.Entries[0].Resource.FullName())
+	assert.EqualValues(t, 3, cached.Entries[0].FieldDiffs[0].NewValue)
+
+	// A corrupt entry is a miss
+	require.NoError(t, os.WriteFile(cache.path("bad"), []byte("{"), 0644))
+	_, ok = cache.Get("bad")
+	assert.False(t, ok)
+}
+
+func TestDriftCache_Prunes(t *testing.T) {
+	cache := OpenDriftCache(t.TempDir())
+	for i := 0; i < maxDriftReports+5; i++ {
+		require.NoError(t, cache.Put(fmt.Sprint(i), &types.DriftReport{}))
+	}
+	entries, err := os.ReadDir(cache.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, maxDriftReports)
+	_, ok := cache.Get(fmt.Sprint(maxDriftReports + 4))
+	assert.True(t, ok, "the latest report is kept")
+}
@@ -0,0 +1,159 @@
+// Package index caches data derived from resource files in the snapshot
+// repository, keyed by git blob hash. Unchanged files share a blob across
+// snapshots, so history-wide queries decode each distinct version once and
+// later queries reuse the cached result. Drift reports between commit pairs
+// are cached alongside, since commits never change either.
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// File returns the default index location inside a snapshot repository.
+// It lives in .git so it is never committed.
+func File(repoPath string) string {
+	return filepath.Join(repoPath, ".git", "gitops-time-machine", "index.json")
+}
+
+// Field is a scalar value at a field path, e.g. .spec.replicas = 3.
+type Field struct {
+	Path  string `json:"p"`
+	Value string `json:"v"`
+}
+
+// Entry is the cached view of one resource file version.
+type Entry struct {
+	Resource string  `json:"r"`
+	Fields   []Field `json:"f"`
+}
+
+// Index is a persistent blob-hash to Entry cache.
+type Index struct {
+	path    string
+	entries map[string]Entry
+	dirty   bool
+}
+
+// Open loads the index at path; a missing file starts empty.
+func Open(path string) (*Index, error) {
+	idx := &Index{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+	if err := json.Unmarshal(data, &idx.entries); err != nil {
+		// A corrupt cache is rebuilt rather than failing the query
+		return &Index{path: path, entries: make(map[string]Entry), dirty: true}, nil
+	}
+	return idx, nil
+}
+
+// Save writes the index back to disk if it changed.
+func (idx *Index) Save() error {
+	if !idx.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	data, err := json.Marshal(idx.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := os.WriteFile(idx.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	idx.dirty = false
+	return nil
+}
+
+// Lookup returns the entry for a blob, calling load to build it on a miss.
+func (idx *Index) Lookup(blobHash string, load func() (types.Resource, error)) (Entry, error) {
+	if entry, ok := idx.entries[blobHash]; ok {
+		return entry, nil
+	}
+	res, err := load()
+	if err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{Resource: res.FullName(), Fields: Flatten(res)}
+	idx.entries[blobHash] = entry
+	idx.dirty = true
+	return entry, nil
+}
+
+// Flatten lists every scalar value of a resource with its field path,
+// sorted by path. List elements are addressed as path[i].
+func Flatten(res types.Resource) []Field {
+	var fields []Field
+	obj := res.Raw
+	if obj == nil {
+		obj = map[string]interface{}{}
+		if res.Spec != nil {
+			obj["spec"] = res.Spec
+		}
+		if res.Data != nil {
+			obj["data"] = res.Data
+		}
+	}
+	flatten("", obj, &fields)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
+
+// flatten appends the scalars under v to fields.
+func flatten(prefix string, v interface{}, fields *[]Field) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			flatten(prefix+"."+k, child, fields)
+		}
+	case []interface{}:
+		for i, child := range val {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, fields)
+		}
+	case nil:
+	default:
+		*fields = append(*fields, Field{Path: prefix, Value: fmt.Sprint(val)})
+	}
+}
+
+// MatchPath reports whether a flattened field path falls under prefix,
+// ignoring list indexes: .spec.containers.image matches
+// .spec.containers[0].image.
+func MatchPath(path, prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	path = stripIndexes(path)
+	prefix = stripIndexes(prefix)
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
+
+// stripIndexes removes [i] list subscripts from a field path.
+func stripIndexes(path string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range path {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package index
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlatten(t *testing.T) {
+	res := types.Resource{Raw: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"containers": []interface{}{
+				map[string]interface{}{"image": "app:1"},
+			},
+		},
+	}}
+	assert.Equal(t, []Field{
+		{Path: ".spec.containers[0].image", Value: "app:1"},
+		{Path: ".spec.replicas", Value: "2"},
+	}, Flatten(res))
+}
+
+func TestMatchPath(t *testing.T) {
+	assert.True(t, MatchPath(".spec.containers[0].image", ".spec.containers.image"))
+	assert.True(t, MatchPath(".spec.containers[0].image", ".spec.containers"))
+	assert.True(t, MatchPath(".spec.containers[0].image", ""))
+	assert.False(t, MatchPath(".spec.containersX", ".spec.containers"))
+}
+
+func TestLookup_CachesAcrossOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	idx, err := Open(path)
+	require.NoError(t, err)
+
+	loads := 0
+	load := func() (types.Resource, error) {
+		loads++
+		return types.Resource{Kind: "ConfigMap", Namespace: "a", Name: "b", Data: map[string]interface{}{"k": "v"}}, nil
+	}
+	entry, err := idx.Lookup("abc", load)
+	require.NoError(t, err)
+	assert.Equal(t, "a/ConfigMap/b", entry.Resource)
+	require.NoError(t, idx.Save())
+
+	idx, err = Open(path)
+	require.NoError(t, err)
+	entry, err = idx.Lookup("abc", func() (types.Resource, error) { return types.Resource{}, errors.New("not cached") })
+	require.NoError(t, err)
+	assert.Equal(t, []Field{{Path: ".data.k", Value: "v"}}, entry.Fields)
+	assert.Equal(t, 1, loads)
+}
@@ -0,0 +1,155 @@
+// Package install generates Kubernetes manifests for running
+// GitOps-Time-Machine in-cluster in watch mode.
+package install
+
+import (
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+)
+
+const (
+	// configDir is where the ConfigMap is mounted in the container.
+	configDir = "/etc/gitops-time-machine"
+	// dataDir is where the snapshot volume is mounted in the container.
+	dataDir = "/data"
+	// runAsUser matches the non-root user created in the Dockerfile.
+	runAsUser = 1000
+)
+
+// Options describes the deployment to generate.
+type Options struct {
+	Name        string
+	Namespace   string
+	Image       string
+	StorageSize string
+	// ResourceTypes are the collected types the ClusterRole must grant.
+	ResourceTypes []string
+	// Discovery grants list on every type instead, for
+	// snapshot.discovery.
+	Discovery bool
+	// Watch also grants watch, for watch.enable_watch_events.
+	Watch bool
+	// Config is the content of config.yaml stored in the ConfigMap.
+	Config string
+}
+
+// Manifests returns the ServiceAccount, RBAC, ConfigMap, PVC, and Deployment
+// for a single watch-mode replica, in apply order.
+func Manifests(opts Options) []map[string]interface{} {
+	role := collector.ClusterRole(opts.Name, opts.ResourceTypes, opts.Watch)
+	if opts.Discovery {
+		role = collector.DiscoveryClusterRole(opts.Name, opts.Watch)
+	}
+	role["metadata"] = metadata(opts.Name, "")
+
+	return []map[string]interface{}{
+		{
+			"apiVersion": "v1",
+			"kind":       "ServiceAccount",
+			"metadata":   metadata(opts.Name, opts.Namespace),
+		},
+		role,
+		{
+			"apiVersion": "rbac.authorization.k8s.io/v1",
+			"kind":       "ClusterRoleBinding",
+			"metadata":   metadata(opts.Name, ""),
+			"roleRef": map[string]interface{}{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "ClusterRole",
+				"name":     opts.Name,
+			},
+			"subjects": []interface{}{
+				map[string]interface{}{
+					"kind":      "ServiceAccount",
+					"name":      opts.Name,
+					"namespace": opts.Namespace,
+				},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   metadata(opts.Name, opts.Namespace),
+			"data":       map[string]interface{}{"config.yaml": opts.Config},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata":   metadata(opts.Name, opts.Namespace),
+			"spec": map[string]interface{}{
+				"accessModes": []string{"ReadWriteOnce"},
+				"resources": map[string]interface{}{
+					"requests": map[string]interface{}{"storage": opts.StorageSize},
+				},
+			},
+		},
+		deployment(opts),
+	}
+}
+
+// deployment runs a single watcher; Recreate avoids two pods sharing the
+// ReadWriteOnce volume and the snapshot repository during a rollout.
+func deployment(opts Options) map[string]interface{} {
+	labels := map[string]interface{}{"app.kubernetes.io/name": opts.Name}
+
+	container := map[string]interface{}{
+		"name":  opts.Name,
+		"image": opts.Image,
+		"args":  []string{"watch", "--config", configDir + "/config.yaml", "--no-progress"},
+		"env": []interface{}{
+			map[string]interface{}{"name": "GTM_SNAPSHOT_OUTPUT_DIR", "value": dataDir + "/snapshots"},
+		},
+		"volumeMounts": []interface{}{
+			map[string]interface{}{"name": "config", "mountPath": configDir, "readOnly": true},
+			map[string]interface{}{"name": "data", "mountPath": dataDir},
+		},
+		"securityContext": map[string]interface{}{
+			"allowPrivilegeEscalation": false,
+			"readOnlyRootFilesystem":   true,
+		},
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   metadata(opts.Name, opts.Namespace),
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"strategy": map[string]interface{}{"type": "Recreate"},
+			"selector": map[string]interface{}{"matchLabels": labels},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": labels},
+				"spec": map[string]interface{}{
+					"serviceAccountName": opts.Name,
+					"securityContext": map[string]interface{}{
+						"runAsNonRoot": true,
+						"runAsUser":    runAsUser,
+						"fsGroup":      runAsUser,
+					},
+					"containers": []interface{}{container},
+					"volumes": []interface{}{
+						map[string]interface{}{
+							"name":      "config",
+							"configMap": map[string]interface{}{"name": opts.Name},
+						},
+						map[string]interface{}{
+							"name":                  "data",
+							"persistentVolumeClaim": map[string]interface{}{"claimName": opts.Name},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// metadata returns object metadata; an empty namespace means cluster-scoped.
+func metadata(name, namespace string) map[string]interface{} {
+	meta := map[string]interface{}{
+		"name":   name,
+		"labels": map[string]interface{}{"app.kubernetes.io/name": name},
+	}
+	if namespace != "" {
+		meta["namespace"] = namespace
+	}
+	return meta
+}
@@ -0,0 +1,63 @@
+package install
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() Options {
+	return Options{
+		Name:          "gtm",
+		Namespace:     "ops",
+		Image:         "gitops-time-machine:v1",
+		StorageSize:   "5Gi",
+		ResourceTypes: []string{"deployments"},
+		Config:        "watch:\n  schedule: \"*/5 * * * *\"\n",
+	}
+}
+
+func TestManifests_Kinds(t *testing.T) {
+	var kinds []string
+	for _, m := range Manifests(testOptions()) {
+		kinds = append(kinds, m["kind"].(string))
+	}
+	assert.Equal(t, []string{
+		"ServiceAccount", "ClusterRole", "ClusterRoleBinding",
+		"ConfigMap", "PersistentVolumeClaim", "Deployment",
+	}, kinds)
+}
+
+func TestManifests_WiresServiceAccountAndConfig(t *testing.T) {
+	manifests := Manifests(testOptions())
+
+	role := manifests[1]
+	assert.NotContains(t, role["metadata"], "namespace", "ClusterRole is cluster-scoped")
+	require.Len(t, role["rules"], 1)
+
+	subjects := manifests[2]["subjects"].([]interface{})
+	assert.Equal(t, "ops", subjects[0].(map[string]interface{})["namespace"])
+
+	data := manifests[3]["data"].(map[string]interface{})
+	assert.Equal(t, testOptions().Config, data["config.yaml"])
+
+	spec := manifests[5]["spec"].(map[string]interface{})
+	pod := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, "gtm", pod["serviceAccountName"])
+	container := pod["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "gitops-time-machine:v1", container["image"])
+	assert.Equal(t, "watch", container["args"].([]string)[0])
+}
+
+func TestManifests_DiscoveryRole(t *testing.T) {
+	opts := testOptions()
+	opts.Discovery = true
+	role := Manifests(opts)[1]
+	assert.Equal(t, "gtm", role["metadata"].(map[string]interface{})["name"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"apiGroups": []string{"*"},
+		"resources": []string{"*"},
+		"verbs":     []string{"list"},
+	}}, role["rules"])
+}
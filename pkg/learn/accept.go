@@ -0,0 +1,141 @@
+package learn
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Accept adds rules to the ignore.fields section of a YAML config file,
+// creating the file or section if needed. Rules already present are left
+// as they are, and comments elsewhere in the file are kept. It returns the
+// number of paths added.
+func Accept(path string, rules map[string][]string) (int, error) {
+	mode := os.FileMode(0644)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return 0, fmt.Errorf("failed to read config: %w", err)
+	default:
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return 0, fmt.Errorf("failed to update config: top level is not a mapping")
+	}
+
+	section, err := mapping(root, "ignore")
+	if err != nil {
+		return 0, err
+	}
+	fields, err := mapping(section, "fields")
+	if err != nil {
+		return 0, err
+	}
+
+	kinds := make([]string, 0, len(rules))
+	for kind := range rules {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	added := 0
+	for _, kind := range kinds {
+		seq, err := sequence(fields, kind)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range rules[kind] {
+			if contains(seq, p) {
+				continue
+			}
+			seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: p})
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return 0, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), mode); err != nil {
+		return 0, fmt.Errorf("failed to write config: %w", err)
+	}
+	return added, nil
+}
+
+// mapping returns the mapping under key, adding an empty one if it is
+// missing or null.
+func mapping(parent *yaml.Node, key string) (*yaml.Node, error) {
+	value := lookup(parent, key, false)
+	switch {
+	case value == nil:
+		value = &yaml.Node{Kind: yaml.MappingNode}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	case value.Tag == "!!null":
+		*value = yaml.Node{Kind: yaml.MappingNode}
+	case value.Kind != yaml.MappingNode:
+		return nil, fmt.Errorf("failed to update config: %s is not a mapping", key)
+	}
+	return value, nil
+}
+
+// sequence returns the block sequence of a kind in ignore.fields, adding
+// an empty one if it is missing or null. Kinds match case-insensitively,
+// as viper reads them.
+func sequence(fields *yaml.Node, kind string) (*yaml.Node, error) {
+	value := lookup(fields, kind, true)
+	switch {
+	case value == nil:
+		value = &yaml.Node{Kind: yaml.SequenceNode}
+		fields.Content = append(fields.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: kind}, value)
+	case value.Tag == "!!null":
+		*value = yaml.Node{Kind: yaml.SequenceNode}
+	case value.Kind != yaml.SequenceNode:
+		return nil, fmt.Errorf("failed to update config: ignore.fields.%s is not a list", kind)
+	}
+	value.Style = 0
+	return value, nil
+}
+
+// lookup returns the value of key in a mapping node, or nil.
+func lookup(m *yaml.Node, key string, fold bool) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k := m.Content[i].Value
+		if k == key || (fold && strings.EqualFold(k, key)) {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// contains reports whether a sequence node has a scalar value.
+func contains(seq *yaml.Node, value string) bool {
+	for _, n := range seq.Content {
+		if n.Value == value {
+			return true
+		}
+	}
+	return false
+}
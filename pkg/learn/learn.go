@@ -0,0 +1,197 @@
+// Package learn finds fields that change on nearly every snapshot, such as
+// timestamps written by controllers or frequently rotated certificates, and
+// proposes ignore rules for them.
+package learn
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Options tune what counts as a flapping field.
+type Options struct {
+	// Threshold is the share of a resource's snapshots (0-1] in which the
+	// field must change.
+	Threshold float64
+	// MinChanges is the number of changes a field must have had, so that
+	// a short history does not propose rules.
+	MinChanges int
+	// Ignore holds the rules already configured, which are not proposed again.
+	Ignore *config.IgnoreConfig
+}
+
+// Proposal is an ignore rule for a field that flaps in some resources of a kind.
+type Proposal struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Path string `json:"path" yaml:"path"`
+	// Flapping is the number of resources in which the field flaps, out
+	// of Resources that have it.
+	Flapping  int `json:"flapping" yaml:"flapping"`
+	Resources int `json:"resources" yaml:"resources"`
+	// Changes and Observed count the consecutive snapshot pairs in which
+	// the field changed, and in which it was present, in the resource
+	// where it flaps most.
+	Changes  int `json:"changes" yaml:"changes"`
+	Observed int `json:"observed" yaml:"observed"`
+	// Example names that resource.
+	Example string `json:"example" yaml:"example"`
+}
+
+// Rate is the share of snapshots in which the field changed.
+func (p Proposal) Rate() float64 {
+	if p.Observed == 0 {
+		return 0
+	}
+	return float64(p.Changes) / float64(p.Observed)
+}
+
+// fieldKey identifies a field of one resource.
+type fieldKey struct {
+	resource string
+	path     string
+}
+
+// counts tallies how often a field was present and changed.
+type counts struct {
+	kind     string
+	changes  int
+	observed int
+}
+
+// Learn compares each snapshot with the next (oldest first) and returns
+// rules for the fields that changed in at least opts.Threshold of the
+// pairs in which they were present, most frequent first.
+func Learn(snapshots []*types.ResourceSnapshot, opts Options) []Proposal {
+	fields := make(map[fieldKey]*counts)
+	for i := 1; i < len(snapshots); i++ {
+		base := indexFields(snapshots[i-1])
+		for name, target := range indexFields(snapshots[i]) {
+			prev, ok := base[name]
+			if !ok {
+				continue
+			}
+			for path := range union(prev.values, target.values) {
+				key := fieldKey{resource: name, path: path}
+				c := fields[key]
+				if c == nil {
+					c = &counts{kind: target.kind}
+					fields[key] = c
+				}
+				c.observed++
+				if !reflect.DeepEqual(prev.values[path], target.values[path]) {
+					c.changes++
+				}
+			}
+		}
+	}
+
+	// Group per kind and path, keeping the resource that flaps most
+	proposals := make(map[[2]string]*Proposal)
+	for key, c := range fields {
+		id := [2]string{c.kind, key.path}
+		p := proposals[id]
+		if p == nil {
+			p = &Proposal{Kind: c.kind, Path: key.path}
+			proposals[id] = p
+		}
+		p.Resources++
+		rate := float64(c.changes) / float64(c.observed)
+		if c.changes < opts.MinChanges || rate < opts.Threshold {
+			continue
+		}
+		p.Flapping++
+		if c.changes > p.Changes || (c.changes == p.Changes && key.resource < p.Example) {
+			p.Changes, p.Observed, p.Example = c.changes, c.observed, key.resource
+		}
+	}
+
+	var out []Proposal
+	for _, p := range proposals {
+		if p.Flapping == 0 {
+			continue
+		}
+		if opts.Ignore != nil && ignore.Covers(opts.Ignore, p.Kind, p.Path) {
+			continue
+		}
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Rate() != out[j].Rate() {
+			return out[i].Rate() > out[j].Rate()
+		}
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// resourceFields are the compared fields of a resource, by path.
+type resourceFields struct {
+	kind   string
+	values map[string]interface{}
+}
+
+// indexFields flattens the compared fields of each resource in a snapshot.
+func indexFields(snapshot *types.ResourceSnapshot) map[string]resourceFields {
+	index := make(map[string]resourceFields, len(snapshot.Resources))
+	for _, res := range snapshot.Resources {
+		values := make(map[string]interface{})
+		for k, v := range res.Labels {
+			values[ignore.FormatPath(ignore.MapKeys("metadata", "labels", k))] = v
+		}
+		for k, v := range res.Annotations {
+			values[ignore.FormatPath(ignore.MapKeys("metadata", "annotations", k))] = v
+		}
+		flatten([]string{"spec"}, res.Spec, values)
+		flatten([]string{"data"}, res.Data, values)
+		index[res.FullName()] = resourceFields{kind: res.Kind, values: values}
+	}
+	return index
+}
+
+// flatten records the leaves of nested maps by path. Lists are not
+// descended into: ignoring a list as a whole would hide far more than the
+// field that changes, and elements are best ignored from the paths drift
+// reports for them.
+func flatten(prefix []string, m map[string]interface{}, values map[string]interface{}) {
+	for k, v := range m {
+		keys := append(append([]string(nil), prefix...), k)
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(keys, v, values)
+		case []interface{}:
+		default:
+			values[ignore.FormatPath(ignore.MapKeys(keys...))] = v
+		}
+	}
+}
+
+// union returns the keys present in either map.
+func union(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// Rules groups proposals into the ignore.fields entries that accept them.
+func Rules(proposals []Proposal) map[string][]string {
+	rules := make(map[string][]string)
+	for _, p := range proposals {
+		rules[p.Kind] = append(rules[p.Kind], p.Path)
+	}
+	for kind := range rules {
+		sort.Strings(rules[kind])
+	}
+	return rules
+}
@@ -0,0 +1,104 @@
+package learn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// history builds n snapshots in which api's restartedAt annotation changes
+// every time, web's replicas change once, and the cert rotates every other time.
+func history(n int) []*types.ResourceSnapshot {
+	var snapshots []*types.ResourceSnapshot
+	for i := 0; i < n; i++ {
+		replicas := 2
+		if i == n-1 {
+			replicas = 3
+		}
+		snapshots = append(snapshots, &types.ResourceSnapshot{Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "prod", Name: "api",
+				Annotations: map[string]string{"kubectl.kubernetes.io/restartedAt": fmt.Sprint(i)},
+				Spec:        map[string]interface{}{"replicas": 2, "containers": []interface{}{fmt.Sprint(i)}}},
+			{Kind: "Deployment", Namespace: "prod", Name: "web",
+				Annotations: map[string]string{"kubectl.kubernetes.io/restartedAt": "0"},
+				Spec:        map[string]interface{}{"replicas": replicas}},
+			{Kind: "Secret", Namespace: "prod", Name: "tls",
+				Data: map[string]interface{}{"tls.crt": fmt.Sprint(i / 2)}},
+		}})
+	}
+	return snapshots
+}
+
+func TestLearn(t *testing.T) {
+	proposals := Learn(history(11), Options{Threshold: 0.9, MinChanges: 5})
+	require.Len(t, proposals, 1, "lists, occasional changes, and every-other-time rotations are not proposed")
+	p := proposals[0]
+	assert.Equal(t, "Deployment", p.Kind)
+	assert.Equal(t, `.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`, p.Path)
+	assert.Equal(t, 1, p.Flapping)
+	assert.Equal(t, 2, p.Resources)
+	assert.Equal(t, 10, p.Changes)
+	assert.Equal(t, 10, p.Observed)
+	assert.Equal(t, "prod/Deployment/api", p.Example)
+
+	proposals = Learn(history(11), Options{Threshold: 0.5, MinChanges: 5})
+	require.Len(t, proposals, 2)
+	assert.Equal(t, `.data["tls.crt"]`, proposals[1].Path)
+
+	assert.Empty(t, Learn(history(4), Options{Threshold: 0.9, MinChanges: 5}), "too few changes")
+
+	ignored := &config.IgnoreConfig{Fields: map[string][]string{
+		"deployment": {`.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`},
+	}}
+	assert.Empty(t, Learn(history(11), Options{Threshold: 0.9, MinChanges: 5, Ignore: ignored}))
+}
+
+func TestAccept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`# Snapshot settings
+snapshot:
+  output_dir: ./s   # kept
+ignore:
+  fields:
+    deployment: [.spec.replicas]
+`), 0600))
+
+	added, err := Accept(path, map[string][]string{
+		"Deployment": {".spec.replicas", `.metadata.annotations["kubectl.kubernetes.io/restartedAt"]`},
+		"Secret":     {`.data["tls.crt"]`},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Snapshot settings")
+	assert.Contains(t, string(data), "output_dir: ./s # kept")
+	assert.Contains(t, string(data), `    deployment:
+      - .spec.replicas
+      - .metadata.annotations["kubectl.kubernetes.io/restartedAt"]
+    Secret:
+      - .data["tls.crt"]`)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	added, err = Accept(path, map[string][]string{"Secret": {`.data["tls.crt"]`}})
+	require.NoError(t, err)
+	assert.Zero(t, added)
+
+	fresh := filepath.Join(t.TempDir(), "new.yaml")
+	added, err = Accept(fresh, map[string][]string{"Secret": {`.data["tls.crt"]`}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	data, err = os.ReadFile(fresh)
+	require.NoError(t, err)
+	assert.Equal(t, "ignore:\n  fields:\n    Secret:\n      - .data[\"tls.crt\"]\n", string(data))
+}
@@ -0,0 +1,157 @@
+// Package links builds URLs to snapshot commits and files in a Git forge,
+// so summaries and reports can be clicked through to the stored YAML.
+package links
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Template placeholders.
+const (
+	placeholderCommit = "{commit}"
+	placeholderShort  = "{short}"
+	placeholderPath   = "{path}"
+)
+
+// Links renders commit and file URLs. The zero value renders none.
+type Links struct {
+	commit string
+	file   string
+}
+
+// Validate checks that configured templates reference the commit.
+func Validate(cfg *config.LinksConfig) error {
+	for name, tmpl := range map[string]string{"commit": cfg.Commit, "file": cfg.File} {
+		if tmpl != "" && !strings.Contains(tmpl, placeholderCommit) && !strings.Contains(tmpl, placeholderShort) {
+			return fmt.Errorf("git.links.%s must contain {commit} or {short}", name)
+		}
+	}
+	if cfg.File != "" && !strings.Contains(cfg.File, placeholderPath) {
+		return fmt.Errorf("git.links.file must contain {path}")
+	}
+	return nil
+}
+
+// New returns the links for the configured templates, falling back to
+// templates derived from remoteURL for GitHub and GitLab remotes.
+func New(cfg *config.LinksConfig, remoteURL string) *Links {
+	commit, file := Templates(remoteURL)
+	if cfg.Commit != "" {
+		commit = cfg.Commit
+	}
+	if cfg.File != "" {
+		file = cfg.File
+	}
+	return &Links{commit: commit, file: file}
+}
+
+// Templates derives commit and file URL templates from a remote URL, or
+// returns empty templates if the forge is not recognized.
+func Templates(remoteURL string) (commit, file string) {
+	base := webURL(remoteURL)
+	switch {
+	case base == "":
+		return "", ""
+	case strings.Contains(base, "github"):
+		return base + "/commit/{commit}", base + "/blob/{commit}/{path}"
+	case strings.Contains(base, "gitlab"):
+		return base + "/-/commit/{commit}", base + "/-/blob/{commit}/{path}"
+	default:
+		return "", ""
+	}
+}
+
+// Enabled reports whether commit links can be rendered.
+func (l *Links) Enabled() bool {
+	return l != nil && l.commit != ""
+}
+
+// Commit returns the URL of a commit, or "" if no template is known.
+func (l *Links) Commit(hash string) string {
+	if l == nil || l.commit == "" || hash == "" {
+		return ""
+	}
+	return expand(l.commit, hash, "")
+}
+
+// File returns the URL of a file at a commit, or "" if no template is known.
+func (l *Links) File(hash, filePath string) string {
+	if l == nil || l.file == "" || hash == "" {
+		return ""
+	}
+	return expand(l.file, hash, filePath)
+}
+
+// Annotate links a drift report to its base and target commits, and each
+// entry to its resource file under scope: at the target commit, or at the
+// base commit for removed resources and when the target is not a commit
+// (e.g. live state).
+func (l *Links) Annotate(report *types.DriftReport, baseHash, targetHash, scope string) {
+	if !l.Enabled() {
+		return
+	}
+	report.BaseURL = l.Commit(baseHash)
+	report.TargetURL = l.Commit(targetHash)
+	for i := range report.Entries {
+		entry := &report.Entries[i]
+		hash := targetHash
+		if hash == "" || entry.Type == types.DriftRemoved {
+			hash = baseHash
+		}
+		if entry.Type == types.DriftAdded && hash != targetHash {
+			// not in any commit yet
+			continue
+		}
+		res := entry.Resource
+		entry.URL = l.File(hash, path.Join(scope, snapshotter.ResourcePath(res.Namespace, res.Kind, res.Name)))
+	}
+}
+
+// expand fills in the placeholders of a template.
+func expand(tmpl, hash, filePath string) string {
+	short := hash
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	return strings.NewReplacer(
+		placeholderCommit, hash,
+		placeholderShort, short,
+		placeholderPath, filePath,
+	).Replace(tmpl)
+}
+
+// webURL converts an https, ssh, or scp-style remote URL to the forge's
+// https URL of the repository.
+func webURL(remoteURL string) string {
+	remoteURL = strings.TrimSuffix(strings.TrimSpace(remoteURL), "/")
+	remoteURL = strings.TrimSuffix(remoteURL, ".git")
+	if remoteURL == "" {
+		return ""
+	}
+
+	var host, repoPath string
+	if u, err := url.Parse(remoteURL); err == nil && u.Host != "" {
+		host, repoPath = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+	} else if at := strings.Index(remoteURL, "@"); at >= 0 {
+		// scp-style: git@github.com:acme/snapshots
+		rest := remoteURL[at+1:]
+		colon := strings.Index(rest, ":")
+		if colon < 0 {
+			return ""
+		}
+		host, repoPath = rest[:colon], rest[colon+1:]
+	} else {
+		return ""
+	}
+	if host == "" || repoPath == "" {
+		return ""
+	}
+	return "https://" + host + "/" + repoPath
+}
@@ -0,0 +1,69 @@
+// Package managedby recognizes resources owned by controllers that mutate
+// them on purpose, so their expected churn can be left out of reports.
+package managedby
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Label is the well-known label naming the tool that manages a resource.
+const Label = "app.kubernetes.io/managed-by"
+
+// Validate checks that every controller pattern is a valid glob.
+func Validate(cfg *config.IgnoreManagedConfig) error {
+	for _, pattern := range cfg.Controllers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ignore_managed.controllers: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether any controller or annotation is configured.
+func Enabled(cfg *config.IgnoreManagedConfig) bool {
+	return len(cfg.Controllers) > 0 || len(cfg.Annotations) > 0
+}
+
+// Controller returns the configured controller that owns the resource, if any.
+func Controller(cfg *config.IgnoreManagedConfig, res types.Resource) (string, bool) {
+	if manager, ok := res.Labels[Label]; ok {
+		for _, pattern := range cfg.Controllers {
+			if matched, _ := path.Match(pattern, manager); matched {
+				return manager, true
+			}
+		}
+	}
+	for _, key := range cfg.Annotations {
+		if _, ok := res.Annotations[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// Exclude returns copies of both snapshots without the resources owned by
+// a configured controller, and the number of distinct resources left out.
+// A resource is left out of both sides if it is owned on either, so that
+// gaining or losing the marker does not show up as an addition or removal.
+func Exclude(cfg *config.IgnoreManagedConfig, base, target *types.ResourceSnapshot) (*types.ResourceSnapshot, *types.ResourceSnapshot, int) {
+	if !Enabled(cfg) {
+		return base, target, 0
+	}
+
+	ignored := make(map[string]bool)
+	for _, snapshot := range []*types.ResourceSnapshot{base, target} {
+		for _, res := range snapshot.Resources {
+			if _, ok := Controller(cfg, res); ok {
+				ignored[res.FullName()] = true
+			}
+		}
+	}
+	if len(ignored) == 0 {
+		return base, target, 0
+	}
+	return base.Without(ignored), target.Without(ignored), len(ignored)
+}
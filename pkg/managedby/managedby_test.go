@@ -0,0 +1,69 @@
+package managedby
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestController(t *testing.T) {
+	cfg := &config.IgnoreManagedConfig{
+		Controllers: []string{"cluster-autoscaler", "cert-*"},
+		Annotations: []string{"cert-manager.io/certificate-name"},
+	}
+
+	manager, ok := Controller(cfg, types.Resource{Labels: map[string]string{Label: "cert-manager"}})
+	assert.True(t, ok)
+	assert.Equal(t, "cert-manager", manager)
+
+	manager, ok = Controller(cfg, types.Resource{Annotations: map[string]string{"cert-manager.io/certificate-name": "web"}})
+	assert.True(t, ok)
+	assert.Equal(t, "cert-manager.io/certificate-name", manager)
+
+	_, ok = Controller(cfg, types.Resource{Labels: map[string]string{Label: "Helm"}})
+	assert.False(t, ok)
+}
+
+func TestExclude(t *testing.T) {
+	cfg := &config.IgnoreManagedConfig{Controllers: []string{"cluster-autoscaler"}}
+	managed := map[string]string{Label: "cluster-autoscaler"}
+
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "ConfigMap", Namespace: "kube-ops", Name: "cluster-autoscaler-status", Labels: managed},
+		{Kind: "Deployment", Namespace: "prod", Name: "api"},
+		{Kind: "Deployment", Namespace: "prod", Name: "worker"},
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "ConfigMap", Namespace: "kube-ops", Name: "cluster-autoscaler-status", Labels: managed},
+		{Kind: "Deployment", Namespace: "prod", Name: "api"},
+		// Only marked in the target: left out of both sides
+		{Kind: "Deployment", Namespace: "prod", Name: "worker", Labels: managed},
+	}}
+
+	b, tg, n := Exclude(cfg, base, target)
+
+	assert.Equal(t, 2, n)
+	require.Len(t, b.Resources, 1)
+	require.Len(t, tg.Resources, 1)
+	assert.Equal(t, "prod/Deployment/api", b.Resources[0].FullName())
+	assert.Len(t, base.Resources, 3, "input is not modified")
+}
+
+func TestExcludeDisabled(t *testing.T) {
+	base := &types.ResourceSnapshot{}
+	target := &types.ResourceSnapshot{}
+
+	b, tg, n := Exclude(&config.IgnoreManagedConfig{}, base, target)
+
+	assert.Same(t, base, b)
+	assert.Same(t, target, tg)
+	assert.Zero(t, n)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.IgnoreManagedConfig{Controllers: []string{"cert-*"}}))
+	assert.Error(t, Validate(&config.IgnoreManagedConfig{Controllers: []string{"["}}))
+}
@@ -0,0 +1,104 @@
+// Package managers reports which field managers (helm, kubectl, argocd,
+// controllers...) own the resources in a snapshot.
+package managers
+
+import (
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Unmanaged is the manager under which resources without recorded field
+// managers are counted.
+const Unmanaged = "(none)"
+
+// Usage is the number of resources in a namespace a manager owns fields of.
+type Usage struct {
+	Manager   string `json:"manager" yaml:"manager"`
+	Resources int    `json:"resources" yaml:"resources"`
+}
+
+// Namespace lists the managers of one namespace, most resources first.
+// Cluster-scoped resources are reported under types.ClusterScope.
+type Namespace struct {
+	Namespace string  `json:"namespace" yaml:"namespace"`
+	Resources int     `json:"resources" yaml:"resources"`
+	Managers  []Usage `json:"managers" yaml:"managers"`
+}
+
+// Report is the field manager ownership of one snapshot.
+type Report struct {
+	CommitHash string      `json:"commitHash,omitempty" yaml:"commitHash,omitempty"`
+	Namespaces []Namespace `json:"namespaces" yaml:"namespaces"`
+	// Totals counts resources per manager across all namespaces.
+	Totals []Usage `json:"totals" yaml:"totals"`
+}
+
+// Summarize builds the ownership report of a snapshot. A resource with
+// several managers counts towards each of them.
+func Summarize(snapshot *types.ResourceSnapshot) *Report {
+	perNamespace := make(map[string]map[string]int)
+	resources := make(map[string]int)
+	totals := make(map[string]int)
+
+	for _, res := range snapshot.Resources {
+		ns := res.Namespace
+		if ns == "" {
+			ns = types.ClusterScope
+		}
+		if perNamespace[ns] == nil {
+			perNamespace[ns] = make(map[string]int)
+		}
+		resources[ns]++
+
+		managers := res.Managers
+		if len(managers) == 0 {
+			managers = []string{Unmanaged}
+		}
+		for _, m := range managers {
+			perNamespace[ns][m]++
+			totals[m]++
+		}
+	}
+
+	report := &Report{
+		CommitHash: snapshot.Metadata.CommitHash,
+		Totals:     usages(totals),
+	}
+	for ns, counts := range perNamespace {
+		report.Namespaces = append(report.Namespaces, Namespace{
+			Namespace: ns,
+			Resources: resources[ns],
+			Managers:  usages(counts),
+		})
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+	return report
+}
+
+// Tracked reports whether any resource in the snapshot has recorded managers.
+func Tracked(snapshot *types.ResourceSnapshot) bool {
+	for _, res := range snapshot.Resources {
+		if len(res.Managers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// usages sorts manager counts by resources, then name.
+func usages(counts map[string]int) []Usage {
+	out := make([]Usage, 0, len(counts))
+	for m, n := range counts {
+		out = append(out, Usage{Manager: m, Resources: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Resources != out[j].Resources {
+			return out[i].Resources > out[j].Resources
+		}
+		return out[i].Manager < out[j].Manager
+	})
+	return out
+}
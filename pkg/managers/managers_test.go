@@ -0,0 +1,46 @@
+package managers
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{CommitHash: "abc123"},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "prod", Name: "api", Managers: []string{"argocd-controller", "kube-controller-manager"}},
+			{Kind: "Deployment", Namespace: "prod", Name: "web", Managers: []string{"argocd-controller"}},
+			{Kind: "ConfigMap", Namespace: "prod", Name: "debug", Managers: []string{"kubectl-edit"}},
+			{Kind: "ClusterRole", Name: "viewer"},
+		},
+	}
+
+	report := Summarize(snapshot)
+	assert.Equal(t, "abc123", report.CommitHash)
+	require.Len(t, report.Namespaces, 2)
+
+	cluster := report.Namespaces[0]
+	assert.Equal(t, types.ClusterScope, cluster.Namespace)
+	assert.Equal(t, []Usage{{Manager: Unmanaged, Resources: 1}}, cluster.Managers)
+
+	prod := report.Namespaces[1]
+	assert.Equal(t, "prod", prod.Namespace)
+	assert.Equal(t, 3, prod.Resources)
+	assert.Equal(t, []Usage{
+		{Manager: "argocd-controller", Resources: 2},
+		{Manager: "kube-controller-manager", Resources: 1},
+		{Manager: "kubectl-edit", Resources: 1},
+	}, prod.Managers)
+
+	assert.Equal(t, Usage{Manager: "argocd-controller", Resources: 2}, report.Totals[0])
+	assert.Len(t, report.Totals, 4)
+}
+
+func TestTracked(t *testing.T) {
+	assert.False(t, Tracked(&types.ResourceSnapshot{Resources: []types.Resource{{Name: "a"}}}))
+	assert.True(t, Tracked(&types.ResourceSnapshot{Resources: []types.Resource{{Name: "a", Managers: []string{"helm"}}}}))
+}
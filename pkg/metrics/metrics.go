@@ -0,0 +1,237 @@
+// Package metrics records what watch mode does on each tick and exposes it
+// in the Prometheus text format, so a long-running watch can be scraped and
+// alerted on without reading its logs.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prefix is the namespace of every metric.
+const prefix = "gitops_time_machine_watch_"
+
+// Results of a watch tick.
+const (
+	ResultCommitted = "committed"
+	ResultUnchanged = "unchanged"
+	ResultFailed    = "failed"
+)
+
+// Results of a push.
+const (
+	PushSucceeded = "succeeded"
+	PushFailed    = "failed"
+)
+
+// Validate checks that addr is a host:port to listen on. An empty address
+// disables the metrics endpoint.
+func Validate(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("watch.metrics_addr: %w", err)
+	}
+	return nil
+}
+
+// Watch holds the metrics of one watch process. It is safe for concurrent
+// use: scheduled and event-driven ticks record while scrapes read. All
+// methods do nothing on a nil *Watch, so callers need not check whether
+// metrics are enabled.
+type Watch struct {
+	mu sync.Mutex
+
+	runs             map[string]int
+	durationSum      float64
+	durationCount    int
+	lastDuration     float64
+	lastSuccess      time.Time
+	resources        map[string]int
+	commits          int
+	quarantined      int
+	driftEntries     int
+	lastDrift        int
+	collectionErrors int
+	pushes           map[string]int
+	unpushed         int
+}
+
+// New creates an empty set of watch metrics.
+func New() *Watch {
+	return &Watch{runs: make(map[string]int), resources: make(map[string]int), pushes: make(map[string]int)}
+}
+
+// ObserveTick records a finished tick: how long it took and whether it
+// committed, found nothing to commit, or failed.
+func (w *Watch) ObserveTick(d time.Duration, committed bool, err error) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := ResultUnchanged
+	switch {
+	case err != nil:
+		result = ResultFailed
+	case committed:
+		result = ResultCommitted
+		w.commits++
+	}
+	w.runs[result]++
+	w.durationSum += d.Seconds()
+	w.durationCount++
+	w.lastDuration = d.Seconds()
+	if err == nil {
+		w.lastSuccess = time.Now()
+	}
+}
+
+// SetResources records the number of resources of each kind in the latest
+// snapshot.
+func (w *Watch) SetResources(kindCounts map[string]int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resources = make(map[string]int, len(kindCounts))
+	for kind, n := range kindCounts {
+		w.resources[kind] = n
+	}
+}
+
+// AddDrift records the number of drift entries between the latest snapshot
+// and the previous one.
+func (w *Watch) AddDrift(entries int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.driftEntries += entries
+	w.lastDrift = entries
+}
+
+// Quarantined records a snapshot committed to the quarantine branch.
+func (w *Watch) Quarantined() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.quarantined++
+}
+
+// CollectionError records a collection that failed or was only partial,
+// counting each failed cluster of a fleet.
+func (w *Watch) CollectionError() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.collectionErrors++
+}
+
+// ObservePush records a push of the snapshot repository, and the commits
+// still unpushed after it.
+func (w *Watch) ObservePush(err error, unpushed int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.pushes[PushFailed]++
+	} else {
+		w.pushes[PushSucceeded]++
+	}
+	w.unpushed = unpushed
+}
+
+// SetUnpushed records the number of commits waiting to be pushed.
+func (w *Watch) SetUnpushed(n int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unpushed = n
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (w *Watch) Write(out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var b strings.Builder
+	header := func(name, help, kind string) {
+		fmt.Fprintf(&b, "# HELP %s%s %s\n# TYPE %s%s %s\n", prefix, name, help, prefix, name, kind)
+	}
+	sample := func(name, labels string, value float64) {
+		fmt.Fprintf(&b, "%s%s%s %g\n", prefix, name, labels, value)
+	}
+
+	header("ticks_total", "Snapshot ticks by result.", "counter")
+	for _, result := range []string{ResultCommitted, ResultUnchanged, ResultFailed} {
+		sample("ticks_total", fmt.Sprintf("{result=%q}", result), float64(w.runs[result]))
+	}
+	header("snapshot_duration_seconds", "Time taken by snapshot ticks.", "summary")
+	sample("snapshot_duration_seconds_sum", "", w.durationSum)
+	sample("snapshot_duration_seconds_count", "", float64(w.durationCount))
+	header("last_snapshot_duration_seconds", "Time taken by the latest snapshot tick.", "gauge")
+	sample("last_snapshot_duration_seconds", "", w.lastDuration)
+	if !w.lastSuccess.IsZero() {
+		header("last_snapshot_timestamp_seconds", "Time the latest successful snapshot tick finished.", "gauge")
+		sample("last_snapshot_timestamp_seconds", "", float64(w.lastSuccess.Unix()))
+	}
+
+	header("resources", "Resources of each kind in the latest snapshot.", "gauge")
+	kinds := make([]string, 0, len(w.resources))
+	for kind := range w.resources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		sample("resources", fmt.Sprintf("{kind=%q}", kind), float64(w.resources[kind]))
+	}
+
+	header("commits_total", "Snapshots committed.", "counter")
+	sample("commits_total", "", float64(w.commits))
+	header("quarantined_total", "Snapshots committed to the quarantine branch.", "counter")
+	sample("quarantined_total", "", float64(w.quarantined))
+	header("drift_entries_total", "Drift entries detected between consecutive snapshots.", "counter")
+	sample("drift_entries_total", "", float64(w.driftEntries))
+	header("last_drift_entries", "Drift entries detected in the latest snapshot.", "gauge")
+	sample("last_drift_entries", "", float64(w.lastDrift))
+	header("collection_errors_total", "Collections that failed or were partial.", "counter")
+	sample("collection_errors_total", "", float64(w.collectionErrors))
+	header("pushes_total", "Pushes of the snapshot repository by result.", "counter")
+	for _, result := range []string{PushSucceeded, PushFailed} {
+		sample("pushes_total", fmt.Sprintf("{result=%q}", result), float64(w.pushes[result]))
+	}
+	header("unpushed_commits", "Snapshot commits not yet pushed to the remote.", "gauge")
+	sample("unpushed_commits", "", float64(w.unpushed))
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// Handler serves the metrics at /metrics.
+func (w *Watch) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(rw)
+	})
+	return mux
+}
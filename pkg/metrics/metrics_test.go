@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	w := New()
+	w.SetResources(map[string]int{"Service": 3, "Deployment": 2})
+	w.AddDrift(4)
+	w.ObserveTick(2*time.Second, true, nil)
+	w.AddDrift(0)
+	w.ObserveTick(time.Second, false, nil)
+	w.CollectionError()
+	w.ObserveTick(500*time.Millisecond, false, errors.New("cluster unreachable"))
+	w.Quarantined()
+	w.ObservePush(errors.New("connection reset"), 2)
+	w.SetUnpushed(3)
+
+	var b strings.Builder
+	require.NoError(t, w.Write(&b))
+	out := b.String()
+
+	for _, line := range []string{
+		`gitops_time_machine_watch_ticks_total{result="committed"} 1`,
+		`gitops_time_machine_watch_ticks_total{result="unchanged"} 1`,
+		`gitops_time_machine_watch_ticks_total{result="failed"} 1`,
+		"gitops_time_machine_watch_snapshot_duration_seconds_sum 3.5",
+		"gitops_time_machine_watch_snapshot_duration_seconds_count 3",
+		"gitops_time_machine_watch_last_snapshot_duration_seconds 0.5",
+		"gitops_time_machine_watch_commits_total 1",
+		"gitops_time_machine_watch_quarantined_total 1",
+		"gitops_time_machine_watch_drift_entries_total 4",
+		"gitops_time_machine_watch_last_drift_entries 0",
+		"gitops_time_machine_watch_collection_errors_total 1",
+		`gitops_time_machine_watch_pushes_total{result="succeeded"} 0`,
+		`gitops_time_machine_watch_pushes_total{result="failed"} 1`,
+		"gitops_time_machine_watch_unpushed_commits 3",
+		"# TYPE gitops_time_machine_watch_snapshot_duration_seconds summary",
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+	assert.Less(t, strings.Index(out, `{kind="Deployment"} 2`), strings.Index(out, `{kind="Service"} 3`), "kinds are sorted")
+	assert.Contains(t, out, "gitops_time_machine_watch_last_snapshot_timestamp_seconds ")
+}
+
+func TestWatch_NoSuccess(t *testing.T) {
+	w := New()
+	w.ObserveTick(time.Second, false, errors.New("boom"))
+
+	var b strings.Builder
+	require.NoError(t, w.Write(&b))
+	assert.NotContains(t, b.String(), "last_snapshot_timestamp_seconds", "there is no successful snapshot to report")
+}
+
+func TestWatch_Nil(t *testing.T) {
+	var w *Watch
+	assert.NotPanics(t, func() {
+		w.ObserveTick(time.Second, true, nil)
+		w.SetResources(map[string]int{"Pod": 1})
+		w.AddDrift(1)
+		w.Quarantined()
+		w.CollectionError()
+		w.ObservePush(nil, 0)
+		w.SetUnpushed(1)
+	})
+}
+
+func TestWatch_Concurrent(t *testing.T) {
+	w := New()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.ObserveTick(time.Millisecond, true, nil)
+			w.Write(&strings.Builder{})
+		}()
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	require.NoError(t, w.Write(&b))
+	assert.Contains(t, b.String(), "gitops_time_machine_watch_commits_total 10\n")
+}
+
+func TestHandler(t *testing.T) {
+	w := New()
+	w.ObserveTick(time.Second, true, nil)
+
+	rec := httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "gitops_time_machine_watch_commits_total 1\n")
+
+	rec = httptest.NewRecorder()
+	w.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(""))
+	assert.NoError(t, Validate(":9090"))
+	assert.NoError(t, Validate("127.0.0.1:9090"))
+	assert.Error(t, Validate("9090"))
+}
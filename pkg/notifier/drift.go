@@ -0,0 +1,326 @@
+package notifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/textfmt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ignore"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Levels of drift detail in notifications.
+const (
+	DetailSummary   = "summary"
+	DetailResources = "resources"
+	DetailDiffs     = "diffs"
+)
+
+// maskedValue replaces secret values in field diffs.
+const maskedValue = "[REDACTED]"
+
+// maxValueLength truncates long values, such as whole container lists.
+const maxValueLength = 80
+
+// secretWords mark field and env var names whose values are masked.
+var secretWords = []string{"password", "passwd", "secret", "token", "credential", "apikey", "api_key", "private"}
+
+// validateDetail checks the drift detail settings.
+func validateDetail(cfg *config.NotifiersConfig) error {
+	switch cfg.Detail {
+	case DetailSummary, DetailResources, DetailDiffs:
+	default:
+		return fmt.Errorf("notifiers.detail must be summary, resources, or diffs, got %q", cfg.Detail)
+	}
+	if cfg.MaxEntries < 0 || cfg.MaxFieldDiffs < 0 {
+		return fmt.Errorf("notifiers.max_entries and notifiers.max_field_diffs must not be negative")
+	}
+	if _, err := policy.ParseSeverity(cfg.MinSeverity); err != nil {
+		return fmt.Errorf("notifiers.min_severity: %w", err)
+	}
+	return nil
+}
+
+// DriftDetail renders the most severe changes of a drift report as plain
+// text for a notification body, as much as notifiers.detail asks for:
+// nothing at "summary", the changed resources at "resources", and their
+// field diffs at "diffs". The values of Secrets, and of fields and env vars
+// whose names look secret, are masked. Policy violations follow the
+// changes. It returns "" if there is nothing to show.
+func DriftDetail(cfg *config.NotifiersConfig, drift *types.DriftReport) string {
+	if drift == nil || cfg.Detail == DetailSummary || cfg.MaxEntries == 0 {
+		return ""
+	}
+	minSeverity, _ := policy.ParseSeverity(cfg.MinSeverity)
+	var shown []types.DriftEntry
+	for _, e := range drift.Entries {
+		if e.Severity == "" || policy.Rank(e.Severity) >= minSeverity {
+			shown = append(shown, e)
+		}
+	}
+	var violations []types.PolicyViolation
+	for _, v := range drift.PolicyViolations {
+		if policy.Rank(v.Severity) >= minSeverity {
+			violations = append(violations, v)
+		}
+	}
+	if len(shown) == 0 && len(violations) == 0 {
+		return ""
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		return policy.Rank(shown[i].Severity) > policy.Rank(shown[j].Severity)
+	})
+
+	var b strings.Builder
+	if len(shown) > 0 {
+		changeDetail(&b, cfg, shown)
+	}
+	if len(violations) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "Policy violations:\n")
+		for _, v := range violations[:min(len(violations), cfg.MaxEntries)] {
+			res := types.Resource{Kind: v.Resource.Kind, Namespace: v.Resource.Namespace, Name: v.Resource.Name}
+			fmt.Fprintf(&b, "• %s (%s): %s\n", res.DisplayName(), v.Severity, v.Message)
+		}
+		if n := len(violations) - cfg.MaxEntries; n > 0 {
+			fmt.Fprintf(&b, "… and %d more violations\n", n)
+		}
+	}
+	return b.String()
+}
+
+// changeDetail writes the most severe of the shown changes, and their
+// field diffs at "diffs".
+func changeDetail(b *strings.Builder, cfg *config.NotifiersConfig, shown []types.DriftEntry) {
+	fmt.Fprintf(b, "Most severe changes:\n")
+	for _, e := range shown[:min(len(shown), cfg.MaxEntries)] {
+		fmt.Fprintf(b, "• %s %s", e.Type, e.Resource.DisplayName())
+		if e.Severity != "" {
+			fmt.Fprintf(b, " (%s)", e.Severity)
+		}
+		b.WriteString("\n")
+		if cfg.Detail != DetailDiffs {
+			continue
+		}
+		for _, d := range e.FieldDiffs[:min(len(e.FieldDiffs), cfg.MaxFieldDiffs)] {
+			d = MaskFieldDiff(e.Resource, d)
+			fmt.Fprintf(b, "    %s: %s → %s\n", d.Path, formatValue(d.OldValue), formatValue(d.NewValue))
+		}
+		if n := len(e.FieldDiffs) - cfg.MaxFieldDiffs; n > 0 {
+			fmt.Fprintf(b, "    … and %d more fields\n", n)
+		}
+	}
+	if n := len(shown) - cfg.MaxEntries; n > 0 {
+		fmt.Fprintf(b, "… and %d more changes\n", n)
+	}
+}
+
+// MaskFieldDiff returns a copy of a field diff of res with its values
+// masked if they are secret: every value of a Secret, the values of fields
+// whose names look secret, and such fields and env vars nested in them.
+func MaskFieldDiff(res types.Resource, d types.FieldDiff) types.FieldDiff {
+	if res.Kind == "Secret" || secretPath(d.Path) {
+		if d.OldValue != nil {
+			d.OldValue = maskedValue
+		}
+		if d.NewValue != nil {
+			d.NewValue = maskedValue
+		}
+		return d
+	}
+	d.OldValue, d.NewValue = mask(d.OldValue), mask(d.NewValue)
+	return d
+}
+
+// MaskResource returns a copy of the raw object of res with the values of
+// a Secret's data and stringData, and of secret-looking fields and env
+// vars, masked.
+func MaskResource(res types.Resource) map[string]interface{} {
+	obj, _ := mask(res.Raw).(map[string]interface{})
+	if res.Kind != "Secret" {
+		return obj
+	}
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := obj[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		masked := make(map[string]interface{}, len(data))
+		for k := range data {
+			masked[k] = maskedValue
+		}
+		obj[field] = masked
+	}
+	return obj
+}
+
+// MaskedResource returns a copy of res with every field that carries
+// resource content masked as MaskResource masks the raw object, for
+// serializing whole resources (e.g. over an API).
+func MaskedResource(res types.Resource) types.Resource {
+	if res.Raw != nil {
+		res.Raw = MaskResource(res)
+	}
+	res.Spec, _ = mask(res.Spec).(map[string]interface{})
+	if res.Kind == "Secret" {
+		data := make(map[string]interface{}, len(res.Data))
+		for k := range res.Data {
+			data[k] = maskedValue
+		}
+		res.Data = data
+	} else {
+		res.Data, _ = mask(res.Data).(map[string]interface{})
+	}
+	return res
+}
+
+// MaskedEntry returns a copy of a drift entry with its resource and field
+// diffs masked.
+func MaskedEntry(e types.DriftEntry) types.DriftEntry {
+	diffs := make([]types.FieldDiff, len(e.FieldDiffs))
+	for i, d := range e.FieldDiffs {
+		diffs[i] = MaskFieldDiff(e.Resource, d)
+	}
+	e.FieldDiffs = diffs
+	e.Resource = MaskedResource(e.Resource)
+	return e
+}
+
+// formatValue renders a field value on one line, truncated.
+func formatValue(v interface{}) string {
+	s := textfmt.Value(v)
+	if r := []rune(s); len(r) > maxValueLength {
+		s = string(r[:maxValueLength]) + "…"
+	}
+	return s
+}
+
+// mask returns a copy of a value with the secret-looking fields in it
+// masked, including the values of env vars such as {name: DB_PASSWORD,
+// value: ...}.
+func mask(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		name, _ := v["name"].(string)
+		for k, val := range v {
+			if secretName(k) || (k == "value" && secretName(name)) {
+				out[k] = maskedValue
+				continue
+			}
+			out[k] = mask(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = mask(v[i])
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// secretPath reports whether a field diff path names a secret-looking
+// field or list element, or the value of a list element, such as an env
+// var, whose name looks secret: .env[DB_PASSWORD].value.
+func secretPath(path string) bool {
+	keys, err := ignore.SplitPath(path)
+	if err != nil {
+		return false
+	}
+	last := keys[len(keys)-1]
+	if secretName(last.Name) {
+		return true
+	}
+	if last.Name != "value" || last.Element || len(keys) < 2 {
+		return false
+	}
+	element := keys[len(keys)-2]
+	return element.Element && secretName(element.Name)
+}
+
+// secretName reports whether a field or env var name looks like it holds
+// a secret.
+func secretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, w := range secretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// DriftData is what notifiers.drift_template is executed over.
+type DriftData struct {
+	// Source names what detected the drift, e.g. "watch" or "drift".
+	Source  string
+	Summary types.DriftSummary
+	Report  *types.DriftReport
+	// Counts is the built-in one-line summary, e.g. "1 added, 2 modified".
+	Counts string
+	// Detail is the most severe changes, as notifiers.detail asks.
+	Detail string
+}
+
+// DriftMessage builds the notification for drift found by source: the
+// counts as the subject, and notifiers.drift_template or the counts and
+// detail as the body.
+func DriftMessage(cfg *config.NotifiersConfig, source string, drift *types.DriftReport) (*Message, error) {
+	data := DriftData{
+		Source:  source,
+		Summary: drift.Summary,
+		Report:  drift,
+		Counts:  driftCounts(drift.Summary),
+		Detail:  DriftDetail(cfg, drift),
+	}
+	msg := &Message{Subject: fmt.Sprintf("Drift detected by %s: %s", source, data.Counts)}
+	if cfg.DriftTemplate == "" {
+		msg.Body = data.Counts
+		if data.Detail != "" {
+			msg.Body += "\n\n" + data.Detail
+		}
+		return msg, nil
+	}
+	tmpl, err := parseTemplate("drift", cfg.DriftTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render drift template: %w", err)
+	}
+	msg.Body = b.String()
+	return msg, nil
+}
+
+// driftCounts lists the non-zero change counts of a summary.
+func driftCounts(s types.DriftSummary) string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{
+		{s.AddedResources, "added"},
+		{s.RemovedResources, "removed"},
+		{s.ModifiedResources, "modified"},
+		{s.RecreatedResources, "recreated"},
+		{s.ScaledResources, "scaled"},
+		{s.OwnershipChanges, "ownership changes"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
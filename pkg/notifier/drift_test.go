@@ -0,0 +1,170 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDrift() *types.DriftReport {
+	return &types.DriftReport{Entries: []types.DriftEntry{
+		{
+			Type: types.DriftScaled, Severity: "low",
+			Resource:   types.Resource{Kind: "Deployment", Namespace: "prod", Name: "web"},
+			FieldDiffs: []types.FieldDiff{{Path: ".spec.replicas", OldValue: 2, NewValue: 3}},
+		},
+		{
+			Type: types.DriftModified, Severity: "critical",
+			Resource: types.Resource{Kind: "Secret", Namespace: "prod", Name: "db"},
+			FieldDiffs: []types.FieldDiff{
+				{Path: ".data.password", OldValue: "aHVudGVyMg==", NewValue: "c3dvcmRmaXNo"},
+			},
+		},
+		{
+			Type: types.DriftModified, Severity: "high",
+			Resource: types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api"},
+			FieldDiffs: []types.FieldDiff{
+				{Path: ".spec.template.spec.containers", NewValue: []interface{}{map[string]interface{}{
+					"name": "api",
+					"env":  []interface{}{map[string]interface{}{"name": "DB_PASSWORD", "value": "hunter2"}},
+				}}},
+				{Path: ".spec.template.metadata.annotations.apiToken", OldValue: "abc", NewValue: "def"},
+				{Path: ".spec.template.spec.serviceAccountName", OldValue: "default", NewValue: "api"},
+			},
+		},
+	}}
+}
+
+func TestDriftDetail(t *testing.T) {
+	cfg := config.DefaultConfig().Notifiers
+	cfg.MaxEntries = 2
+	cfg.MaxFieldDiffs = 2
+	detail := DriftDetail(&cfg, testDrift())
+
+	assert.Contains(t, detail, "• MODIFIED Secret/prod/db (critical)\n    .data.password: [REDACTED] → [REDACTED]")
+	assert.Contains(t, detail, "• MODIFIED Deployment/prod/api (high)")
+	assert.Less(t, strings.Index(detail, "Secret/prod/db"), strings.Index(detail, "Deployment/prod/api"), "most severe first")
+	assert.Contains(t, detail, `"value":"[REDACTED]"`)
+	assert.Contains(t, detail, ".spec.template.metadata.annotations.apiToken: [REDACTED] → [REDACTED]")
+	assert.Contains(t, detail, "… and 1 more fields")
+	assert.Contains(t, detail, "… and 1 more changes")
+	assert.NotContains(t, detail, "hunter2")
+	assert.NotContains(t, detail, "aHVudGVyMg==")
+	assert.NotContains(t, detail, "Deployment/prod/web")
+}
+
+func TestDriftDetail_Levels(t *testing.T) {
+	cfg := config.DefaultConfig().Notifiers
+
+	cfg.MinSeverity = "critical"
+	detail := DriftDetail(&cfg, testDrift())
+	assert.Contains(t, detail, "Secret/prod/db")
+	assert.NotContains(t, detail, "Deployment/prod/api")
+
+	cfg.MinSeverity = "low"
+	cfg.Detail = DetailResources
+	detail = DriftDetail(&cfg, testDrift())
+	assert.Contains(t, detail, "• SCALED Deployment/prod/web (low)")
+	assert.NotContains(t, detail, ".spec.replicas")
+
+	cfg.Detail = DetailSummary
+	assert.Empty(t, DriftDetail(&cfg, testDrift()))
+	cfg.Detail = DetailDiffs
+	assert.Empty(t, DriftDetail(&cfg, nil))
+}
+
+func TestDriftDetail_PolicyViolations(t *testing.T) {
+	cfg := config.DefaultConfig().Notifiers
+	drift := &types.DriftReport{PolicyViolations: []types.PolicyViolation{
+		{Rule: "image-latest", Severity: "high", Resource: types.ResourceRef{Kind: "Deployment", Namespace: "prod", Name: "web"},
+			Message: "container app runs nginx, which is not pinned to a tag or digest"},
+		{Rule: "image-latest", Severity: "low", Resource: types.ResourceRef{Kind: "Pod", Namespace: "dev", Name: "debug"},
+			Message: "container app runs busybox, which is not pinned to a tag or digest"},
+	}}
+
+	cfg.MinSeverity = "medium"
+	detail := DriftDetail(&cfg, drift)
+	assert.Equal(t, "Policy violations:\n• Deployment/prod/web (high): container app runs nginx, which is not pinned to a tag or digest\n", detail)
+
+	drift.Entries = testDrift().Entries
+	detail = DriftDetail(&cfg, drift)
+	assert.Less(t, strings.Index(detail, "Most severe changes:"), strings.Index(detail, "\n\nPolicy violations:"))
+}
+
+func TestMaskResource(t *testing.T) {
+	secret := types.Resource{Kind: "Secret", Raw: map[string]interface{}{
+		"kind": "Secret",
+		"data": map[string]interface{}{"password": "aHVudGVyMg=="},
+	}}
+	masked := MaskResource(secret)
+	assert.Equal(t, map[string]interface{}{"password": "[REDACTED]"}, masked["data"])
+	assert.Equal(t, "aHVudGVyMg==", secret.Raw["data"].(map[string]interface{})["password"], "the resource is not modified")
+
+	deployment := types.Resource{Kind: "Deployment", Raw: map[string]interface{}{
+		"env": []interface{}{map[string]interface{}{"name": "API_TOKEN", "value": "abc"}},
+	}}
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "API_TOKEN", "value": "[REDACTED]"}}, MaskResource(deployment)["env"])
+}
+
+func TestMaskedResource(t *testing.T) {
+	secret := types.Resource{
+		Kind: "Secret",
+		Data: map[string]interface{}{"password": "aHVudGVyMg=="},
+		Raw:  map[string]interface{}{"kind": "Secret", "data": map[string]interface{}{"password": "aHVudGVyMg=="}},
+	}
+	masked := MaskedResource(secret)
+	assert.Equal(t, map[string]interface{}{"password": maskedValue}, masked.Data)
+	assert.Equal(t, map[string]interface{}{"password": maskedValue}, masked.Raw["data"])
+	assert.Equal(t, "aHVudGVyMg==", secret.Data["password"], "the resource is not modified")
+
+	deployment := types.Resource{Kind: "Deployment", Spec: map[string]interface{}{
+		"env": []interface{}{map[string]interface{}{"name": "API_TOKEN", "value": "abc"}},
+	}}
+	masked = MaskedResource(deployment)
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "API_TOKEN", "value": maskedValue}}, masked.Spec["env"])
+	assert.Nil(t, masked.Raw)
+
+	entry := MaskedEntry(types.DriftEntry{Resource: secret, FieldDiffs: []types.FieldDiff{{Path: ".data.password", OldValue: "a", NewValue: "b"}}})
+	assert.Equal(t, maskedValue, entry.FieldDiffs[0].NewValue)
+	assert.Equal(t, maskedValue, entry.Resource.Data["password"])
+}
+
+func TestMaskFieldDiff_ListElements(t *testing.T) {
+	deployment := types.Resource{Kind: "Deployment"}
+
+	d := MaskFieldDiff(deployment, types.FieldDiff{Path: ".spec.template.spec.containers[api].env[DB_PASSWORD].value", OldValue: "hunter2", NewValue: "swordfish"})
+	assert.Equal(t, maskedValue, d.OldValue)
+	assert.Equal(t, maskedValue, d.NewValue)
+
+	d = MaskFieldDiff(deployment, types.FieldDiff{Path: ".spec.template.spec.containers[api].env[LOG_LEVEL].value", OldValue: "info", NewValue: "debug"})
+	assert.Equal(t, "debug", d.NewValue)
+
+	d = MaskFieldDiff(deployment, types.FieldDiff{Path: ".spec.template.spec.containers[api].env[API_TOKEN]", NewValue: map[string]interface{}{"name": "API_TOKEN", "value": "abc"}})
+	assert.Equal(t, maskedValue, d.NewValue)
+	assert.Nil(t, d.OldValue)
+}
+
+func TestDriftMessage(t *testing.T) {
+	cfg := &config.DefaultConfig().Notifiers
+	cfg.Detail = DetailResources
+	drift := testDrift()
+	drift.Summary = types.DriftSummary{ModifiedResources: 2, ScaledResources: 1}
+
+	msg, err := DriftMessage(cfg, "watch", drift)
+	require.NoError(t, err)
+	assert.Equal(t, "Drift detected by watch: 2 modified, 1 scaled", msg.Subject)
+	assert.True(t, strings.HasPrefix(msg.Body, "2 modified, 1 scaled\n\nMost severe changes:\n• MODIFIED Secret/prod/db (critical)\n"), msg.Body)
+
+	cfg.DriftTemplate = "{{ .Source }}: {{ .Summary.ModifiedResources }} modified of {{ len .Report.Entries }}"
+	msg, err = DriftMessage(cfg, "drift", drift)
+	require.NoError(t, err)
+	assert.Equal(t, "drift: 2 modified of 3", msg.Body)
+
+	cfg.DriftTemplate = "{{ .Missing }}"
+	_, err = DriftMessage(cfg, "drift", drift)
+	assert.Error(t, err)
+}
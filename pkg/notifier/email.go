@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// Email sends messages over SMTP, with attachments as MIME parts.
+type Email struct {
+	cfg *config.EmailConfig
+	// send is smtp.SendMail, replaced in tests.
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmail creates an SMTP notifier.
+func NewEmail(cfg *config.EmailConfig) *Email {
+	return &Email{cfg: cfg, send: smtp.SendMail}
+}
+
+// Name implements Notifier.
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send implements Notifier. The context is not observed by net/smtp; the
+// server's own timeouts apply.
+func (e *Email) Send(ctx context.Context, msg *Message) error {
+	data, err := buildMail(e.cfg.From, e.cfg.To, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.SMTPHost)
+	}
+	addr := net.JoinHostPort(e.cfg.SMTPHost, strconv.Itoa(e.cfg.SMTPPort))
+	if err := e.send(addr, auth, e.cfg.From, e.cfg.To, data); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// buildMail encodes a message as a multipart/mixed email.
+func buildMail(from string, to []string, msg *Message, date time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+	if err := writeBase64(text, []byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %w", err)
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines, as MIME requires.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:n]); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Package notifier delivers messages, such as scheduled reports, to the
+// configured sinks.
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Message is a notification with optional file attachments.
+type Message struct {
+	Subject     string
+	Body        string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent along with a message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Notifier sends messages to one sink.
+type Notifier interface {
+	// Name identifies the sink in logs and errors.
+	Name() string
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns a notifier for every configured sink.
+func New(cfg *config.NotifiersConfig) []Notifier {
+	var notifiers []Notifier
+	if cfg.Email.SMTPHost != "" && len(cfg.Email.To) > 0 {
+		notifiers = append(notifiers, NewEmail(&cfg.Email))
+	}
+	if cfg.Slack.Token != "" && cfg.Slack.Channel != "" {
+		notifiers = append(notifiers, NewSlack(&cfg.Slack))
+	} else if cfg.Slack.WebhookURL != "" {
+		notifiers = append(notifiers, NewSlackWebhook(&cfg.Slack))
+	}
+	if cfg.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhook(&cfg.Webhook))
+	}
+	return notifiers
+}
+
+// Validate checks that partially configured sinks are complete, so a typo
+// doesn't silently disable delivery.
+func Validate(cfg *config.NotifiersConfig) error {
+	email := cfg.Email
+	if email.SMTPHost != "" || len(email.To) > 0 {
+		if email.SMTPHost == "" || len(email.To) == 0 || email.From == "" {
+			return fmt.Errorf("notifiers.email needs smtp_host, from, and to")
+		}
+	}
+	slack := cfg.Slack
+	if (slack.Token == "") != (slack.Channel == "") {
+		return fmt.Errorf("notifiers.slack needs both token and channel")
+	}
+	if slack.Token != "" && slack.WebhookURL != "" {
+		return fmt.Errorf("notifiers.slack needs either token and channel or webhook_url, not both")
+	}
+	webhook := cfg.Webhook
+	if webhook.URL == "" && (webhook.Body != "" || len(webhook.Headers) > 0) {
+		return fmt.Errorf("notifiers.webhook needs a url")
+	}
+	if _, err := parseTemplate("webhook", webhook.Body); err != nil {
+		return fmt.Errorf("notifiers.webhook.body: %w", err)
+	}
+	if _, err := parseTemplate("drift", cfg.DriftTemplate); err != nil {
+		return fmt.Errorf("notifiers.drift_template: %w", err)
+	}
+	if cfg.OnDrift && len(New(cfg)) == 0 {
+		return fmt.Errorf("notifiers.on_drift needs a configured notifier")
+	}
+	return validateDetail(cfg)
+}
+
+// SendAll sends the message to every notifier, continuing past failures,
+// and returns the failures joined.
+func SendAll(ctx context.Context, notifiers []Notifier, msg *Message) error {
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+			continue
+		}
+		log.WithField("notifier", n.Name()).Info("notification sent")
+	}
+	return errors.Join(errs...)
+}
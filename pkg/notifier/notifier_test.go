@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	name string
+	err  error
+	sent int
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Send(ctx context.Context, msg *Message) error {
+	f.sent++
+	return f.err
+}
+
+func TestNewAndValidate(t *testing.T) {
+	cfg := &config.DefaultConfig().Notifiers
+	assert.NoError(t, Validate(cfg))
+	assert.Empty(t, New(cfg))
+
+	cfg.Email = config.EmailConfig{SMTPHost: "smtp.example.com", To: []string{"ops@example.com"}}
+	assert.Error(t, Validate(cfg), "from is required")
+	cfg.Email.From = "gtm@example.com"
+	assert.NoError(t, Validate(cfg))
+
+	cfg.Slack = config.SlackConfig{Token: "xoxb-1"}
+	assert.Error(t, Validate(cfg), "channel is required")
+	cfg.Slack.Channel = "C123"
+	require.NoError(t, Validate(cfg))
+
+	cfg.Detail = "everything"
+	assert.Error(t, Validate(cfg), "unknown detail")
+	cfg.Detail = DetailDiffs
+	cfg.MinSeverity = "urgent"
+	assert.Error(t, Validate(cfg), "unknown severity")
+	cfg.MinSeverity = "low"
+
+	notifiers := New(cfg)
+	require.Len(t, notifiers, 2)
+	assert.Equal(t, "email", notifiers[0].Name())
+	assert.Equal(t, "slack", notifiers[1].Name())
+}
+
+func TestSendAllContinuesPastFailures(t *testing.T) {
+	failing := &fakeNotifier{name: "failing", err: errors.New("boom")}
+	ok := &fakeNotifier{name: "ok"}
+
+	err := SendAll(context.Background(), []Notifier{failing, ok}, &Message{Subject: "s"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failing: boom")
+	assert.Equal(t, 1, ok.sent)
+}
+
+func TestEmailSend(t *testing.T) {
+	cfg := &config.EmailConfig{
+		SMTPHost: "smtp.example.com",
+		SMTPPort: 587,
+		Username: "user",
+		Password: "pass",
+		From:     "gtm@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+	}
+	e := NewEmail(cfg)
+	var gotAddr string
+	var gotTo []string
+	var gotMsg []byte
+	e.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, msg
+		assert.NotNil(t, a)
+		return nil
+	}
+
+	err := e.Send(context.Background(), &Message{
+		Subject:     "Weekly drift report",
+		Body:        "3 changes",
+		Attachments: []Attachment{{Name: "report.md", ContentType: "text/markdown", Data: []byte("# Report")}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, cfg.To, gotTo)
+
+	mail := string(gotMsg)
+	assert.Contains(t, mail, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, mail, "Subject: Weekly drift report\r\n")
+	assert.Contains(t, mail, `filename=report.md`)
+	assert.Contains(t, mail, "IyBSZXBvcnQ=") // base64 of "# Report"
+}
+
+func TestWriteBase64WrapsLines(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, writeBase64(&sb, make([]byte, 120)))
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n")
+	require.Len(t, lines, 3)
+	assert.Len(t, lines[0], 76)
+}
+
+func TestBuildMailDate(t *testing.T) {
+	date := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	data, err := buildMail("a@example.com", []string{"b@example.com"}, &Message{Subject: "s"}, date)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Date: Mon, 03 Jun 2024 09:00:00 +0000\r\n")
+}
+
+func TestSlackSend(t *testing.T) {
+	var calls []string
+	var completed map[string]interface{}
+	var uploaded []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/files.getUploadURLExternal":
+			assert.Equal(t, "Bearer xoxb-1", r.Header.Get("Authorization"))
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "report.html", r.Form.Get("filename"))
+			assert.Equal(t, "4", r.Form.Get("length"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok": true, "upload_url": "http://" + r.Host + "/upload", "file_id": "F1",
+			})
+		case "/upload":
+			uploaded, _ = io.ReadAll(r.Body)
+		case "/api/files.completeUploadExternal":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&completed))
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "unknown_method"})
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSlack(&config.SlackConfig{Token: "xoxb-1", Channel: "C123"})
+	s.api = srv.URL + "/api/"
+	err := s.Send(context.Background(), &Message{
+		Subject:     "Weekly drift report",
+		Body:        "3 changes",
+		Attachments: []Attachment{{Name: "report.html", Data: []byte("<h1>")}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/files.getUploadURLExternal", "/upload", "/api/files.completeUploadExternal"}, calls)
+	assert.Equal(t, "<h1>", string(uploaded))
+	assert.Equal(t, "C123", completed["channel_id"])
+	assert.Equal(t, "*Weekly drift report*\n3 changes", completed["initial_comment"])
+}
+
+func TestSlackSendReportsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "channel_not_found"})
+	}))
+	defer srv.Close()
+
+	s := NewSlack(&config.SlackConfig{Token: "xoxb-1", Channel: "C404"})
+	s.api = srv.URL + "/"
+	err := s.Send(context.Background(), &Message{Body: "hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chat.postMessage failed: channel_not_found")
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	cfg := &config.DefaultConfig().Notifiers
+
+	cfg.Slack = config.SlackConfig{WebhookURL: "https://hooks.slack.com/services/T0/B0/x"}
+	require.NoError(t, Validate(cfg))
+	cfg.Slack.Token, cfg.Slack.Channel = "xoxb-1", "C123"
+	assert.Error(t, Validate(cfg), "token and webhook_url are exclusive")
+	cfg.Slack = config.SlackConfig{}
+
+	cfg.Webhook = config.WebhookConfig{Body: `{"text": {{ json .Body }}}`}
+	assert.Error(t, Validate(cfg), "url is required")
+	cfg.Webhook.URL = "https://hooks.example.com/gtm"
+	require.NoError(t, Validate(cfg))
+	cfg.Webhook.Body = "{{ .Body"
+	assert.Error(t, Validate(cfg), "body must parse")
+	cfg.Webhook.Body = ""
+
+	cfg.DriftTemplate = "{{ range }}"
+	assert.Error(t, Validate(cfg), "drift_template must parse")
+	cfg.DriftTemplate = ""
+
+	cfg.OnDrift = true
+	cfg.Webhook = config.WebhookConfig{}
+	assert.Error(t, Validate(cfg), "on_drift needs a notifier")
+	cfg.Webhook.URL = "https://hooks.example.com/gtm"
+	require.NoError(t, Validate(cfg))
+
+	cfg.Slack.WebhookURL = "https://hooks.slack.com/services/T0/B0/x"
+	notifiers := New(cfg)
+	require.Len(t, notifiers, 2)
+	assert.Equal(t, "slack", notifiers[0].Name())
+	assert.Equal(t, "webhook", notifiers[1].Name())
+}
+
+func TestSlackWebhookSend(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	s := NewSlackWebhook(&config.SlackConfig{WebhookURL: srv.URL})
+	err := s.Send(context.Background(), &Message{
+		Subject:     "Drift detected",
+		Body:        "2 modified",
+		Attachments: []Attachment{{Name: "report.md", Data: []byte("# Report")}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "*Drift detected*\n2 modified"}, got)
+}
+
+func TestWebhookSend(t *testing.T) {
+	var body, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, auth = string(data), r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+	msg := &Message{Subject: "Drift detected", Body: "1 \"added\""}
+
+	w := NewWebhook(&config.WebhookConfig{URL: srv.URL})
+	require.NoError(t, w.Send(context.Background(), msg))
+	assert.JSONEq(t, `{"subject": "Drift detected", "text": "1 \"added\""}`, body)
+
+	w = NewWebhook(&config.WebhookConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer t0ken"},
+		Body:    `{"title": {{ json .Subject }}, "message": {{ json .Body }}}`,
+	})
+	require.NoError(t, w.Send(context.Background(), msg))
+	assert.JSONEq(t, `{"title": "Drift detected", "message": "1 \"added\""}`, body)
+	assert.Equal(t, "Bearer t0ken", auth)
+}
+
+func TestWebhookSendReportsHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := NewWebhook(&config.WebhookConfig{URL: srv.URL}).Send(context.Background(), &Message{Body: "x"})
+
+	require.Error(t, err)
+	assert.Equal(t, "HTTP 404: no such hook", err.Error())
+}
@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// slackAPI is the base URL of the Slack Web API.
+const slackAPI = "https://slack.com/api/"
+
+// Slack posts messages to a channel with a bot token. A message with
+// attachments is posted as a file upload whose comment is the message.
+type Slack struct {
+	cfg    *config.SlackConfig
+	client *http.Client
+	api    string
+}
+
+// NewSlack creates a Slack notifier.
+func NewSlack(cfg *config.SlackConfig) *Slack {
+	return &Slack{cfg: cfg, client: http.DefaultClient, api: slackAPI}
+}
+
+// Name implements Notifier.
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// slackResponse is the envelope of every Slack Web API response.
+type slackResponse struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error"`
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// slackFile is a file to share in files.completeUploadExternal.
+type slackFile struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Send implements Notifier.
+func (s *Slack) Send(ctx context.Context, msg *Message) error {
+	text := msg.Body
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n" + text
+	}
+	if len(msg.Attachments) == 0 {
+		return s.call(ctx, "chat.postMessage", map[string]interface{}{
+			"channel": s.cfg.Channel,
+			"text":    text,
+		}, nil)
+	}
+
+	// Files are uploaded in three steps: get an upload URL per file, upload
+	// the content, then share all files in the channel with the message.
+	var files []slackFile
+	for _, a := range msg.Attachments {
+		var resp slackResponse
+		params := url.Values{"filename": {a.Name}, "length": {strconv.Itoa(len(a.Data))}}
+		if err := s.form(ctx, "files.getUploadURLExternal", params, &resp); err != nil {
+			return err
+		}
+		if err := s.upload(ctx, resp.UploadURL, a.Data); err != nil {
+			return err
+		}
+		files = append(files, slackFile{ID: resp.FileID, Title: a.Name})
+	}
+	return s.call(ctx, "files.completeUploadExternal", map[string]interface{}{
+		"files":           files,
+		"channel_id":      s.cfg.Channel,
+		"initial_comment": text,
+	}, nil)
+}
+
+// call invokes a Web API method with a JSON body.
+func (s *Slack) call(ctx context.Context, method string, body interface{}, out *slackResponse) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.api+method, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return s.do(req, method, out)
+}
+
+// form invokes a Web API method with form-encoded parameters, which some
+// methods require.
+func (s *Slack) form(ctx context.Context, method string, params url.Values, out *slackResponse) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.api+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return s.do(req, method, out)
+}
+
+// do sends an authenticated Web API request and checks its "ok" field.
+func (s *Slack) do(req *http.Request, method string, out *slackResponse) error {
+	req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var result slackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode %s response (HTTP %d): %w", method, resp.StatusCode, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("%s failed: %s", method, result.Error)
+	}
+	if out != nil {
+		*out = result
+	}
+	return nil
+}
+
+// upload sends file content to an upload URL from files.getUploadURLExternal.
+func (s *Slack) upload(ctx context.Context, uploadURL string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create upload request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload file: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/httpstatus"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// templateFuncs are available in message templates: json encodes a value,
+// e.g. to quote a string inside a JSON body.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseTemplate parses a message template.
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// SlackWebhook posts messages to a Slack incoming webhook. Attachments are
+// left out, since incoming webhooks cannot upload files.
+type SlackWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackWebhook creates a Slack incoming webhook notifier.
+func NewSlackWebhook(cfg *config.SlackConfig) *SlackWebhook {
+	return &SlackWebhook{url: cfg.WebhookURL, client: http.DefaultClient}
+}
+
+// Name implements Notifier.
+func (s *SlackWebhook) Name() string {
+	return "slack"
+}
+
+// Send implements Notifier.
+func (s *SlackWebhook) Send(ctx context.Context, msg *Message) error {
+	text := msg.Body
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n" + text
+	}
+	data, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return post(ctx, s.client, s.url, nil, data)
+}
+
+// Webhook posts messages to an HTTP endpoint, with a templated body.
+type Webhook struct {
+	cfg    *config.WebhookConfig
+	client *http.Client
+}
+
+// NewWebhook creates a generic webhook notifier.
+func NewWebhook(cfg *config.WebhookConfig) *Webhook {
+	return &Webhook{cfg: cfg, client: http.DefaultClient}
+}
+
+// Name implements Notifier.
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Send implements Notifier. Attachments are not sent.
+func (w *Webhook) Send(ctx context.Context, msg *Message) error {
+	var body []byte
+	if w.cfg.Body == "" {
+		data, err := json.Marshal(map[string]string{"subject": msg.Subject, "text": msg.Body})
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		body = data
+	} else {
+		tmpl, err := parseTemplate("webhook", w.cfg.Body)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, msg); err != nil {
+			return fmt.Errorf("failed to render webhook body: %w", err)
+		}
+		body = b.Bytes()
+	}
+	return post(ctx, w.client, w.cfg.URL, w.cfg.Headers, body)
+}
+
+// post sends a JSON body and fails on a non-2xx response.
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	return httpstatus.Check(resp)
+}
@@ -0,0 +1,232 @@
+// Package orphans finds resources that no desired-state source accounts
+// for, such as objects created by hand with kubectl and since forgotten.
+package orphans
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/managedby"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Labels and annotations set by the deployment tools that are recognized
+// as desired-state sources.
+const (
+	helmReleaseAnnotation  = "meta.helm.sh/release-name"
+	argoTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+	argoInstanceLabel      = "argocd.argoproj.io/instance"
+	fluxKustomizationLabel = "kustomize.toolkit.fluxcd.io/name"
+	fluxHelmReleaseLabel   = "helm.toolkit.fluxcd.io/name"
+)
+
+// Sources a resource can be accounted for by.
+const (
+	SourceHelm    = "helm"
+	SourceArgoCD  = "argocd"
+	SourceFlux    = "flux"
+	SourceManager = "manager"
+	SourceOwner   = "owner"
+	SourceDesired = "desired"
+	SourceSystem  = "system"
+)
+
+// fieldManagers maps the field managers of the recognized deployment tools
+// to their source, for snapshots taken with snapshot.track_field_managers.
+var fieldManagers = map[string]string{
+	"helm":                 SourceHelm,
+	"argocd-controller":    SourceArgoCD,
+	"kustomize-controller": SourceFlux,
+	"helm-controller":      SourceFlux,
+}
+
+// systemResources are created by Kubernetes itself in every namespace.
+var systemResources = map[string]bool{
+	"ConfigMap/kube-root-ca.crt": true,
+	"ServiceAccount/default":     true,
+}
+
+// Validate checks that every manager pattern is a valid glob.
+func Validate(cfg *config.OrphansConfig) error {
+	for _, pattern := range cfg.Managers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("orphans.managers: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Finder recognizes the desired-state source of resources.
+type Finder struct {
+	managers []string
+	// desired holds the FullName of declared resources; declarations
+	// without a namespace are held as Kind/name and match any namespace.
+	desired map[string]bool
+}
+
+// New creates a Finder, reading the configured desired-state manifests.
+func New(cfg *config.OrphansConfig) (*Finder, error) {
+	f := &Finder{managers: cfg.Managers, desired: make(map[string]bool)}
+	for _, p := range cfg.DesiredPaths {
+		if err := f.load(p); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Source returns what accounts for a resource, or "" if nothing does.
+func (f *Finder) Source(res types.Resource) string {
+	switch {
+	case res.Labels[managedby.Label] == "Helm" || res.Annotations[helmReleaseAnnotation] != "":
+		return SourceHelm
+	case res.Annotations[argoTrackingAnnotation] != "" || res.Labels[argoInstanceLabel] != "":
+		return SourceArgoCD
+	case res.Labels[fluxKustomizationLabel] != "" || res.Labels[fluxHelmReleaseLabel] != "":
+		return SourceFlux
+	case managerSource(res) != "":
+		return managerSource(res)
+	case f.matchesManager(res.Labels[managedby.Label]):
+		return SourceManager
+	case hasOwner(res):
+		return SourceOwner
+	case f.desired[res.FullName()] || f.desired[res.Kind+"/"+res.Name]:
+		return SourceDesired
+	case systemResources[res.Kind+"/"+res.Name]:
+		return SourceSystem
+	default:
+		return ""
+	}
+}
+
+// Find returns the snapshot's resources that nothing accounts for, sorted
+// by namespace, kind, and name.
+func (f *Finder) Find(snapshot *types.ResourceSnapshot) []types.ResourceRef {
+	var refs []types.ResourceRef
+	for _, res := range snapshot.Resources {
+		if f.Source(res) == "" {
+			refs = append(refs, types.ResourceRef{Kind: res.Kind, Namespace: res.Namespace, Name: res.Name})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return refs
+}
+
+// matchesManager reports whether a managed-by value matches a configured pattern.
+func (f *Finder) matchesManager(manager string) bool {
+	if manager == "" {
+		return false
+	}
+	for _, pattern := range f.managers {
+		if matched, _ := path.Match(pattern, manager); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// managerSource returns the source of the first recognized field manager.
+func managerSource(res types.Resource) string {
+	for _, manager := range res.Managers {
+		if source, ok := fieldManagers[manager]; ok {
+			return source
+		}
+	}
+	return ""
+}
+
+// hasOwner reports whether another resource created this one, e.g. a
+// ReplicaSet of a Deployment.
+func hasOwner(res types.Resource) bool {
+	metadata, _ := res.Raw["metadata"].(map[string]interface{})
+	owners, _ := metadata["ownerReferences"].([]interface{})
+	return len(owners) > 0
+}
+
+// load reads the resources declared in a manifest file or directory.
+// Files that are not plain YAML, such as Helm templates, are skipped.
+func (f *Finder) load(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read desired state %s: %w", p, err)
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read desired state %s: %w", p, err)
+		}
+		if err := f.loadManifests(data); err != nil {
+			log.WithError(err).WithField("file", p).Warn("skipping desired-state file that is not plain YAML")
+		}
+		return nil
+	})
+}
+
+// manifest is the part of a Kubernetes manifest that identifies it.
+type manifest struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Items []manifest `yaml:"items"`
+}
+
+// loadManifests records the resources declared in a multi-document YAML file.
+func (f *Finder) loadManifests(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var m manifest
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.declare(m)
+	}
+}
+
+// declare records a manifest, and the items of a List.
+func (f *Finder) declare(m manifest) {
+	if strings.HasSuffix(m.Kind, "List") && m.Items != nil {
+		for _, item := range m.Items {
+			f.declare(item)
+		}
+		return
+	}
+	if m.Kind == "" || m.Metadata.Name == "" {
+		return
+	}
+	res := types.Resource{Kind: m.Kind, Namespace: m.Metadata.Namespace, Name: m.Metadata.Name}
+	f.desired[res.FullName()] = true
+}
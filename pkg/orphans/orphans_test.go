@@ -0,0 +1,82 @@
+package orphans
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+`
+
+func TestSource(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(manifests), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "chart", "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "chart", "templates", "cm.yaml"), []byte("kind: {{ .Values.kind }\n\tname: x"), 0644))
+
+	f, err := New(&config.OrphansConfig{DesiredPaths: []string{dir}, Managers: []string{"deployer-*"}})
+	require.NoError(t, err)
+
+	cases := []struct {
+		res  types.Resource
+		want string
+	}{
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Labels: map[string]string{"app.kubernetes.io/managed-by": "Helm"}}, SourceHelm},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Annotations: map[string]string{"argocd.argoproj.io/tracking-id": "app:apps/Deployment:prod/api"}}, SourceArgoCD},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Labels: map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"}}, SourceFlux},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Managers: []string{"kubectl-edit", "kustomize-controller"}}, SourceFlux},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Labels: map[string]string{"app.kubernetes.io/managed-by": "deployer-v2"}}, SourceManager},
+		{types.Resource{Kind: "ReplicaSet", Namespace: "prod", Name: "api-5d8f", Raw: map[string]interface{}{
+			"metadata": map[string]interface{}{"ownerReferences": []interface{}{map[string]interface{}{"kind": "Deployment", "name": "api"}}},
+		}}, SourceOwner},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "web"}, SourceDesired},
+		{types.Resource{Kind: "Service", Namespace: "staging", Name: "web"}, SourceDesired},
+		{types.Resource{Kind: "ConfigMap", Namespace: "prod", Name: "kube-root-ca.crt"}, SourceSystem},
+		{types.Resource{Kind: "Deployment", Namespace: "staging", Name: "web"}, ""},
+		{types.Resource{Kind: "Deployment", Namespace: "prod", Name: "api", Labels: map[string]string{"app.kubernetes.io/managed-by": "kubectl"}}, ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, f.Source(c.res), c.res.FullName())
+	}
+}
+
+func TestFind(t *testing.T) {
+	f, err := New(&config.OrphansConfig{})
+	require.NoError(t, err)
+
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "Secret", Namespace: "prod", Name: "manual"},
+		{Kind: "ConfigMap", Namespace: "prod", Name: "debug"},
+		{Kind: "ClusterRole", Name: "hotfix"},
+		{Kind: "Deployment", Namespace: "prod", Name: "api", Annotations: map[string]string{"meta.helm.sh/release-name": "api"}},
+	}}
+	assert.Equal(t, []types.ResourceRef{
+		{Kind: "ClusterRole", Name: "hotfix"},
+		{Kind: "ConfigMap", Namespace: "prod", Name: "debug"},
+		{Kind: "Secret", Namespace: "prod", Name: "manual"},
+	}, f.Find(snapshot))
+}
+
+func TestNew_MissingPath(t *testing.T) {
+	_, err := New(&config.OrphansConfig{DesiredPaths: []string{filepath.Join(t.TempDir(), "missing")}})
+	assert.Error(t, err)
+	assert.Error(t, Validate(&config.OrphansConfig{Managers: []string{"["}}))
+}
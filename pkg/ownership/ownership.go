@@ -0,0 +1,87 @@
+// Package ownership maps resources and drift entries to the teams that own them.
+package ownership
+
+import (
+	"path"
+	"sort"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Unowned is the group name used for resources without a team.
+const Unowned = "(unowned)"
+
+// Resolver determines the owning team for a resource.
+type Resolver struct {
+	annotation string
+	teams      map[string][]string
+	names      []string
+}
+
+// New creates a Resolver from the ownership configuration.
+func New(cfg *config.OwnershipConfig) *Resolver {
+	names := make([]string, 0, len(cfg.Teams))
+	for team := range cfg.Teams {
+		names = append(names, team)
+	}
+	// Sort so overlapping patterns resolve deterministically.
+	sort.Strings(names)
+
+	return &Resolver{
+		annotation: cfg.Annotation,
+		teams:      cfg.Teams,
+		names:      names,
+	}
+}
+
+// Enabled reports whether any ownership source is configured.
+func (r *Resolver) Enabled() bool {
+	return r.annotation != "" || len(r.teams) > 0
+}
+
+// Teams returns the configured team names in sorted order.
+func (r *Resolver) Teams() []string {
+	return r.names
+}
+
+// TeamFor returns the owning team of a resource, or "" if none matches.
+// The ownership annotation wins over the namespace mapping.
+func (r *Resolver) TeamFor(res types.Resource) string {
+	if r.annotation != "" {
+		if team := res.Annotations[r.annotation]; team != "" {
+			return team
+		}
+	}
+	return r.TeamForNamespace(res.Namespace)
+}
+
+// TeamForNamespace returns the team whose namespace patterns match ns.
+func (r *Resolver) TeamForNamespace(ns string) string {
+	if ns == "" {
+		return ""
+	}
+	for _, team := range r.names {
+		for _, pattern := range r.teams[team] {
+			if matched, _ := path.Match(pattern, ns); matched {
+				return team
+			}
+		}
+	}
+	return ""
+}
+
+// Annotate sets the Team field on every entry of a drift report.
+func (r *Resolver) Annotate(report *types.DriftReport) {
+	for i := range report.Entries {
+		report.Entries[i].Team = r.TeamFor(report.Entries[i].Resource)
+	}
+}
+
+// TeamOf returns the team recorded on an entry, or Unowned.
+func TeamOf(entry types.DriftEntry) string {
+	if entry.Team == "" {
+		return Unowned
+	}
+	return entry.Team
+}
@@ -0,0 +1,60 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testResolver() *Resolver {
+	return New(&config.OwnershipConfig{
+		Annotation: "gitops-time-machine/team",
+		Teams: map[string][]string{
+			"payments": {"payments", "payments-*"},
+			"platform": {"ingress-nginx", "monitoring"},
+		},
+	})
+}
+
+func TestTeamFor_Namespace(t *testing.T) {
+	r := testResolver()
+
+	assert.Equal(t, "payments", r.TeamFor(types.Resource{Namespace: "payments"}))
+	assert.Equal(t, "payments", r.TeamFor(types.Resource{Namespace: "payments-staging"}))
+	assert.Equal(t, "platform", r.TeamFor(types.Resource{Namespace: "monitoring"}))
+	assert.Equal(t, "", r.TeamFor(types.Resource{Namespace: "default"}))
+	assert.Equal(t, "", r.TeamFor(types.Resource{Kind: "ClusterRole", Name: "admin"}))
+}
+
+func TestTeamFor_AnnotationWins(t *testing.T) {
+	r := testResolver()
+
+	res := types.Resource{
+		Namespace:   "payments",
+		Annotations: map[string]string{"gitops-time-machine/team": "fraud"},
+	}
+	assert.Equal(t, "fraud", r.TeamFor(res))
+}
+
+func TestAnnotate(t *testing.T) {
+	r := testResolver()
+	report := &types.DriftReport{
+		Entries: []types.DriftEntry{
+			{Type: types.DriftAdded, Resource: types.Resource{Namespace: "monitoring", Name: "grafana"}},
+			{Type: types.DriftRemoved, Resource: types.Resource{Namespace: "default", Name: "tmp"}},
+		},
+	}
+
+	r.Annotate(report)
+
+	assert.Equal(t, "platform", report.Entries[0].Team)
+	assert.Equal(t, "platform", TeamOf(report.Entries[0]))
+	assert.Equal(t, Unowned, TeamOf(report.Entries[1]))
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, New(&config.OwnershipConfig{}).Enabled())
+	assert.True(t, testResolver().Enabled())
+}
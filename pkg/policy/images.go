@@ -0,0 +1,167 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Rules of the image policy.
+const (
+	RuleImageRegistry = "image-registry"
+	RuleImageLatest   = "image-latest"
+)
+
+// defaultRegistry is the registry of images that do not name one.
+const defaultRegistry = "docker.io"
+
+// podSpecPaths locate the pod spec of each workload kind.
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// ValidateImages checks that the image policy configuration is well-formed.
+func ValidateImages(cfg *config.ImagePolicyConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if _, err := ParseSeverity(cfg.Severity); err != nil {
+		return fmt.Errorf("image_policy.severity: %w", err)
+	}
+	for _, registry := range cfg.AllowedRegistries {
+		if registry == "" || strings.HasSuffix(registry, "/") {
+			return fmt.Errorf("image_policy.allowed_registries: %q is not a registry or registry/path prefix", registry)
+		}
+	}
+	if len(cfg.AllowedRegistries) == 0 && !cfg.ForbidLatest {
+		return fmt.Errorf("image_policy is enabled without allowed_registries or forbid_latest")
+	}
+	return nil
+}
+
+// CheckImages evaluates the image policy on every container of the
+// workloads in a snapshot, in resource order. It returns nothing if the
+// policy is disabled.
+func CheckImages(cfg *config.ImagePolicyConfig, snapshot *types.ResourceSnapshot) []types.PolicyViolation {
+	if !cfg.Enabled {
+		return nil
+	}
+	var violations []types.PolicyViolation
+	for _, res := range snapshot.Resources {
+		path, ok := podSpecPaths[res.Kind]
+		if !ok {
+			continue
+		}
+		ref := types.ResourceRef{Kind: res.Kind, Namespace: res.Namespace, Name: res.Name}
+		for _, c := range containers(res.Raw, path) {
+			image := parseImage(c.image)
+			if len(cfg.AllowedRegistries) > 0 && !image.allowed(cfg.AllowedRegistries) {
+				violations = append(violations, types.PolicyViolation{
+					Rule:     RuleImageRegistry,
+					Severity: cfg.Severity,
+					Resource: ref,
+					Message:  fmt.Sprintf("container %s pulls %s from %s, which is not an allowed registry", c.name, c.image, image.registry),
+				})
+			}
+			if cfg.ForbidLatest && image.latest() {
+				violations = append(violations, types.PolicyViolation{
+					Rule:     RuleImageLatest,
+					Severity: cfg.Severity,
+					Resource: ref,
+					Message:  fmt.Sprintf("container %s runs %s, which is not pinned to a tag or digest", c.name, c.image),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// container is a container name and image read from a pod spec.
+type container struct {
+	name  string
+	image string
+}
+
+// containers returns the init, regular, and ephemeral containers of the pod
+// spec at path in obj.
+func containers(obj map[string]interface{}, path []string) []container {
+	spec := obj
+	for _, key := range path {
+		spec, _ = spec[key].(map[string]interface{})
+	}
+	var out []container
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		list, _ := spec[field].([]interface{})
+		for _, item := range list {
+			c, _ := item.(map[string]interface{})
+			name, _ := c["name"].(string)
+			image, _ := c["image"].(string)
+			if image != "" {
+				out = append(out, container{name: name, image: image})
+			}
+		}
+	}
+	return out
+}
+
+// imageRef is a container image reference split into its parts.
+type imageRef struct {
+	registry string
+	// repository is the path within the registry, e.g. library/nginx.
+	repository string
+	tag        string
+	digest     string
+}
+
+// parseImage splits an image reference such as ghcr.io/acme/api:1.2 or
+// nginx@sha256:... The first path component is the registry if it looks
+// like a host name; otherwise the image is from docker.io.
+func parseImage(image string) imageRef {
+	var ref imageRef
+	if i := strings.Index(image, "@"); i >= 0 {
+		image, ref.digest = image[:i], image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image, ref.tag = image[:i], image[i+1:]
+	}
+
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.registry, ref.repository = first, rest
+	} else {
+		ref.registry, ref.repository = defaultRegistry, image
+		if !found {
+			ref.repository = "library/" + image
+		}
+	}
+	return ref
+}
+
+// allowed reports whether the image is from one of the registries or
+// registry/path prefixes.
+func (r imageRef) allowed(registries []string) bool {
+	name := r.registry + "/" + r.repository
+	for _, registry := range registries {
+		if name == registry || strings.HasPrefix(name, registry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// latest reports whether the image floats: tagged latest, or with neither
+// a tag nor a digest, which pulls latest.
+func (r imageRef) latest() bool {
+	if r.digest != "" {
+		return false
+	}
+	return r.tag == "" || r.tag == "latest"
+}
@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  imageRef
+	}{
+		{"nginx", imageRef{registry: "docker.io", repository: "library/nginx"}},
+		{"acme/api:1.2", imageRef{registry: "docker.io", repository: "acme/api", tag: "1.2"}},
+		{"ghcr.io/acme/api:latest", imageRef{registry: "ghcr.io", repository: "acme/api", tag: "latest"}},
+		{"localhost:5000/api", imageRef{registry: "localhost:5000", repository: "api"}},
+		{"registry.example.com/api@sha256:abc", imageRef{registry: "registry.example.com", repository: "api", digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseImage(tt.image), tt.image)
+	}
+}
+
+func workload(kind, namespace, name string, path []string, images ...string) types.Resource {
+	var list []interface{}
+	for i, image := range images {
+		list = append(list, map[string]interface{}{"name": []string{"app", "sidecar"}[i], "image": image})
+	}
+	obj := map[string]interface{}{"containers": list}
+	for i := len(path) - 1; i >= 0; i-- {
+		obj = map[string]interface{}{path[i]: obj}
+	}
+	return types.Resource{Kind: kind, Namespace: namespace, Name: name, Raw: obj}
+}
+
+func TestCheckImages(t *testing.T) {
+	cfg := &config.ImagePolicyConfig{
+		Enabled:           true,
+		AllowedRegistries: []string{"ghcr.io/acme", "docker.io/library"},
+		ForbidLatest:      true,
+		Severity:          "high",
+	}
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		workload("Deployment", "prod", "web", podSpecPaths["Deployment"], "ghcr.io/acme/web:1.4", "nginx:1.27"),
+		workload("CronJob", "prod", "backup", podSpecPaths["CronJob"], "ghcr.io/other/backup:2"),
+		workload("Pod", "dev", "debug", podSpecPaths["Pod"], "busybox"),
+		{Kind: "ConfigMap", Namespace: "prod", Name: "settings"},
+	}}
+
+	violations := CheckImages(cfg, snapshot)
+
+	require.Len(t, violations, 2)
+	assert.Equal(t, RuleImageRegistry, violations[0].Rule)
+	assert.Equal(t, types.ResourceRef{Kind: "CronJob", Namespace: "prod", Name: "backup"}, violations[0].Resource)
+	assert.Equal(t, "container app pulls ghcr.io/other/backup:2 from ghcr.io, which is not an allowed registry", violations[0].Message)
+	assert.Equal(t, RuleImageLatest, violations[1].Rule)
+	assert.Equal(t, "high", violations[1].Severity)
+	assert.Equal(t, "container app runs busybox, which is not pinned to a tag or digest", violations[1].Message)
+
+	cfg.Enabled = false
+	assert.Empty(t, CheckImages(cfg, snapshot))
+}
+
+func TestValidateImages(t *testing.T) {
+	assert.NoError(t, ValidateImages(&config.ImagePolicyConfig{}))
+	assert.NoError(t, ValidateImages(&config.ImagePolicyConfig{Enabled: true, ForbidLatest: true, Severity: "high"}))
+	assert.Error(t, ValidateImages(&config.ImagePolicyConfig{Enabled: true, ForbidLatest: true, Severity: "urgent"}))
+	assert.Error(t, ValidateImages(&config.ImagePolicyConfig{Enabled: true, Severity: "high"}))
+	assert.Error(t, ValidateImages(&config.ImagePolicyConfig{Enabled: true, Severity: "high", AllowedRegistries: []string{"ghcr.io/"}}))
+}
+
+func TestEvaluate_PolicyViolations(t *testing.T) {
+	cfg := &config.GateConfig{FailOn: "high"}
+	r := report()
+	r.PolicyViolations = []types.PolicyViolation{{
+		Rule: RuleImageLatest, Severity: "high",
+		Resource: types.ResourceRef{Kind: "Deployment", Namespace: "prod", Name: "web"},
+		Message:  "container app runs nginx, which is not pinned to a tag or digest",
+	}}
+
+	result, err := Evaluate(context.Background(), cfg, r)
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "prod/Deployment/web", result.Violations[0].Resource)
+	assert.True(t, result.Rejected)
+}
@@ -0,0 +1,298 @@
+// Package policy evaluates drift reports against configured gate rules to
+// decide whether a snapshot's changes may be committed to the main branch.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Severity orders how serious a violation is.
+type Severity int
+
+const (
+	SeverityLow Severity = iota + 1
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+var severityNames = map[string]Severity{
+	"low":      SeverityLow,
+	"medium":   SeverityMedium,
+	"high":     SeverityHigh,
+	"critical": SeverityCritical,
+}
+
+// ParseSeverity parses low, medium, high, or critical (case-insensitive).
+func ParseSeverity(s string) (Severity, error) {
+	sev, ok := severityNames[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown severity %q (use low, medium, high, or critical)", s)
+	}
+	return sev, nil
+}
+
+// Rank orders severity names for sorting. Unknown and empty names, such as
+// those of unclassified drift entries, rank 0, below low.
+func Rank(s string) Severity {
+	sev, _ := ParseSeverity(s)
+	return sev
+}
+
+// String returns the lower-case severity name.
+func (s Severity) String() string {
+	for name, sev := range severityNames {
+		if sev == s {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// MarshalText renders the severity by name in JSON and YAML output.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Violation is a drift entry (or external check) that matched a rule.
+type Violation struct {
+	Rule     string   `json:"rule" yaml:"rule"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Resource string   `json:"resource,omitempty" yaml:"resource,omitempty"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+// Result is the outcome of evaluating a drift report.
+type Result struct {
+	Violations []Violation
+	// Rejected is true if any violation is at or above the gate's fail_on severity.
+	Rejected bool
+}
+
+// Validate checks that the gate configuration is well-formed.
+func Validate(cfg *config.GateConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if _, err := ParseSeverity(cfg.FailOn); err != nil {
+		return fmt.Errorf("watch.gate.fail_on: %w", err)
+	}
+	for i, rule := range cfg.Rules {
+		if _, err := ParseSeverity(rule.Severity); err != nil {
+			return fmt.Errorf("watch.gate rule %d (%s): %w", i, rule.Name, err)
+		}
+		for _, t := range rule.Types {
+			switch types.DriftType(strings.ToUpper(t)) {
+			case types.DriftAdded, types.DriftRemoved, types.DriftModified, types.DriftRecreated, types.DriftScaled, types.DriftOwnership:
+			default:
+				return fmt.Errorf("watch.gate rule %d (%s): unknown type %q", i, rule.Name, t)
+			}
+		}
+	}
+	if cfg.MaxChanges < 0 {
+		return fmt.Errorf("watch.gate.max_changes must not be negative")
+	}
+	if cfg.QuarantineBranch == "" {
+		return fmt.Errorf("watch.gate.quarantine_branch must not be empty")
+	}
+	return nil
+}
+
+// Evaluate checks every drift entry against the gate rules, adds the
+// report's policy violations (e.g. of the image policy), and, if
+// configured, runs the external policy command.
+func Evaluate(ctx context.Context, cfg *config.GateConfig, report *types.DriftReport) (*Result, error) {
+	failOn, err := ParseSeverity(cfg.FailOn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, rule := range cfg.Rules {
+		severity, err := ParseSeverity(rule.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		for _, entry := range report.Entries {
+			if field, ok := matches(rule, entry); ok {
+				message := fmt.Sprintf("%s %s", entry.Type, entry.Resource.Kind)
+				if field != "" {
+					message += " " + field
+				}
+				result.Violations = append(result.Violations, Violation{
+					Rule:     rule.Name,
+					Severity: severity,
+					Resource: entry.Resource.FullName(),
+					Message:  message,
+				})
+			}
+		}
+	}
+
+	for _, v := range report.PolicyViolations {
+		severity, err := ParseSeverity(v.Severity)
+		if err != nil {
+			severity = SeverityMedium
+		}
+		res := types.Resource{Kind: v.Resource.Kind, Namespace: v.Resource.Namespace, Name: v.Resource.Name}
+		result.Violations = append(result.Violations, Violation{
+			Rule:     v.Rule,
+			Severity: severity,
+			Resource: res.FullName(),
+			Message:  v.Message,
+		})
+	}
+
+	if changed := len(report.Entries); cfg.MaxChanges > 0 && changed > cfg.MaxChanges {
+		result.Violations = append(result.Violations, Violation{
+			Rule:     "max-changes",
+			Severity: SeverityCritical,
+			Message:  fmt.Sprintf("%d resources changed (limit %d)", changed, cfg.MaxChanges),
+		})
+	}
+
+	if len(cfg.Command) > 0 {
+		violation, err := runCommand(ctx, cfg.Command, report)
+		if err != nil {
+			return nil, err
+		}
+		if violation != nil {
+			result.Violations = append(result.Violations, *violation)
+		}
+	}
+
+	for _, v := range result.Violations {
+		if v.Severity >= failOn {
+			result.Rejected = true
+			break
+		}
+	}
+	return result, nil
+}
+
+// defaultSeverities rate drift entries that no gate rule matches.
+var defaultSeverities = map[types.DriftType]Severity{
+	types.DriftRemoved:   SeverityHigh,
+	types.DriftRecreated: SeverityHigh,
+	types.DriftModified:  SeverityMedium,
+	types.DriftAdded:     SeverityLow,
+	types.DriftScaled:    SeverityLow,
+	types.DriftOwnership: SeverityLow,
+}
+
+// Classify rates a drift entry: the highest severity of the gate rules that
+// match it, or a default for its drift type if none do. Rules are used
+// whether or not the gate is enabled.
+func Classify(cfg *config.GateConfig, entry types.DriftEntry) Severity {
+	var severity Severity
+	for _, rule := range cfg.Rules {
+		sev, err := ParseSeverity(rule.Severity)
+		if err != nil || sev <= severity {
+			continue
+		}
+		if _, ok := matches(rule, entry); ok {
+			severity = sev
+		}
+	}
+	if severity == 0 {
+		severity = defaultSeverities[entry.Type]
+	}
+	if severity == 0 {
+		severity = SeverityMedium
+	}
+	return severity
+}
+
+// Annotate sets the severity of every entry in the report.
+func Annotate(cfg *config.GateConfig, report *types.DriftReport) {
+	for i := range report.Entries {
+		report.Entries[i].Severity = Classify(cfg, report.Entries[i]).String()
+	}
+}
+
+// matches reports whether a rule applies to an entry, returning the first
+// matching field path when the rule filters on fields.
+func matches(rule config.GateRule, entry types.DriftEntry) (string, bool) {
+	if len(rule.Kinds) > 0 && !containsFold(rule.Kinds, entry.Resource.Kind) {
+		return "", false
+	}
+	if len(rule.Types) > 0 && !containsFold(rule.Types, string(entry.Type)) {
+		return "", false
+	}
+	if len(rule.Namespaces) > 0 && !matchesNamespace(rule.Namespaces, entry.Resource.Namespace) {
+		return "", false
+	}
+	if len(rule.Fields) == 0 {
+		return "", true
+	}
+	for _, diff := range entry.FieldDiffs {
+		for _, prefix := range rule.Fields {
+			if diff.Path == prefix || strings.HasPrefix(diff.Path, prefix+".") || strings.HasPrefix(diff.Path, prefix+"[") {
+				return diff.Path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// runCommand pipes the report to an external policy engine. A non-zero exit
+// is reported as a critical violation carrying the command's output.
+func runCommand(ctx context.Context, command []string, report *types.DriftReport) (*Violation, error) {
+	input, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode drift report: %w", err)
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if err == nil {
+		return nil, nil
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return nil, fmt.Errorf("failed to run policy command: %w", err)
+	}
+
+	message := strings.TrimSpace(output.String())
+	if message == "" {
+		message = err.Error()
+	}
+	return &Violation{
+		Rule:     path.Base(command[0]),
+		Severity: SeverityCritical,
+		Message:  message,
+	}, nil
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNamespace reports whether ns matches any of the glob patterns.
+func matchesNamespace(patterns []string, ns string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, ns); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func report(entries ...types.DriftEntry) *types.DriftReport {
+	return &types.DriftReport{Entries: entries}
+}
+
+func entry(t types.DriftType, kind, namespace, name string, paths ...string) types.DriftEntry {
+	e := types.DriftEntry{
+		Type:     t,
+		Resource: types.Resource{Kind: kind, Namespace: namespace, Name: name},
+	}
+	for _, p := range paths {
+		e.FieldDiffs = append(e.FieldDiffs, types.FieldDiff{Path: p})
+	}
+	return e
+}
+
+func TestEvaluate_Rules(t *testing.T) {
+	cfg := &config.GateConfig{
+		FailOn: "high",
+		Rules: []config.GateRule{
+			{Name: "no-secret-deletes", Severity: "critical", Kinds: []string{"Secret"}, Types: []string{"removed"}},
+			{Name: "image-changes", Severity: "medium", Kinds: []string{"Deployment"}, Fields: []string{".spec.template.spec.containers"}},
+			{Name: "prod-only", Severity: "high", Namespaces: []string{"prod-*"}},
+		},
+	}
+
+	result, err := Evaluate(context.Background(), cfg, report(
+		entry(types.DriftModified, "Deployment", "staging", "web", ".spec.template.spec.containers.image"),
+		entry(types.DriftModified, "Deployment", "staging", "api", ".spec.replicas"),
+	))
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "image-changes", result.Violations[0].Rule)
+	assert.Equal(t, SeverityMedium, result.Violations[0].Severity)
+	assert.False(t, result.Rejected)
+
+	result, err = Evaluate(context.Background(), cfg, report(
+		entry(types.DriftRemoved, "Secret", "prod-eu", "db"),
+	))
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 2)
+	assert.True(t, result.Rejected)
+}
+
+func TestMatches_ListElements(t *testing.T) {
+	rule := config.GateRule{Fields: []string{".spec.template.spec.containers"}}
+
+	path, ok := matches(rule, entry(types.DriftModified, "Deployment", "prod", "web", ".spec.template.spec.containers[nginx].image"))
+	assert.True(t, ok, "a prefix covers the list's elements")
+	assert.Equal(t, ".spec.template.spec.containers[nginx].image", path)
+
+	_, ok = matches(rule, entry(types.DriftModified, "Deployment", "prod", "web", ".spec.template.spec.containersPolicy"))
+	assert.False(t, ok)
+}
+
+func TestEvaluate_Command(t *testing.T) {
+	cfg := &config.GateConfig{FailOn: "critical", Command: []string{"sh", "-c", "echo denied; exit 1"}}
+
+	result, err := Evaluate(context.Background(), cfg, report())
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "denied", result.Violations[0].Message)
+	assert.True(t, result.Rejected)
+
+	cfg.Command = []string{"sh", "-c", "cat >/dev/null"}
+	result, err = Evaluate(context.Background(), cfg, report())
+	require.NoError(t, err)
+	assert.Empty(t, result.Violations)
+	assert.False(t, result.Rejected)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := config.DefaultConfig().Watch.Gate
+	cfg.Enabled = true
+	assert.NoError(t, Validate(&cfg))
+
+	cfg.Rules = []config.GateRule{{Name: "bad", Severity: "urgent"}}
+	assert.Error(t, Validate(&cfg))
+
+	cfg.Rules = []config.GateRule{{Name: "bad", Severity: "low", Types: []string{"renamed"}}}
+	assert.Error(t, Validate(&cfg))
+}
+
+func TestEvaluate_MaxChanges(t *testing.T) {
+	cfg := &config.GateConfig{FailOn: "high", MaxChanges: 1}
+
+	result, err := Evaluate(context.Background(), cfg, report(
+		entry(types.DriftModified, "Secret", "prod", "a"),
+		entry(types.DriftModified, "Secret", "prod", "b"),
+	))
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "max-changes", result.Violations[0].Rule)
+	assert.Equal(t, "2 resources changed (limit 1)", result.Violations[0].Message)
+	assert.True(t, result.Rejected)
+}
+
+func TestRank(t *testing.T) {
+	assert.Equal(t, SeverityCritical, Rank("Critical"))
+	assert.Greater(t, Rank("low"), Rank(""), "unclassified ranks below low")
+	assert.Zero(t, Rank("urgent"))
+}
+
+func TestClassify(t *testing.T) {
+	cfg := &config.GateConfig{
+		Rules: []config.GateRule{
+			{Name: "no-secret-deletes", Severity: "critical", Kinds: []string{"Secret"}, Types: []string{"removed"}},
+			{Name: "dev-is-cheap", Severity: "low", Namespaces: []string{"dev"}},
+		},
+	}
+
+	assert.Equal(t, SeverityCritical, Classify(cfg, entry(types.DriftRemoved, "Secret", "prod", "db")))
+	assert.Equal(t, SeverityHigh, Classify(cfg, entry(types.DriftRemoved, "Deployment", "prod", "api")))
+	assert.Equal(t, SeverityMedium, Classify(cfg, entry(types.DriftModified, "Deployment", "prod", "api")))
+	// A matching rule takes precedence over the default, even if lower
+	assert.Equal(t, SeverityLow, Classify(cfg, entry(types.DriftRemoved, "Deployment", "dev", "api")))
+
+	r := report(entry(types.DriftScaled, "Deployment", "prod", "api"))
+	Annotate(cfg, r)
+	assert.Equal(t, "low", r.Entries[0].Severity)
+}
@@ -0,0 +1,106 @@
+// Package pushbatch decides when watch pushes its snapshot commits: at
+// most every interval or once enough commits are waiting, and after a
+// failed push only once an exponential backoff has passed, so that frequent
+// snapshots and a flaky remote don't push, or fail to push, on every tick.
+package pushbatch
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// Validate checks the push batching settings of watch.
+func Validate(cfg *config.PushConfig) error {
+	switch {
+	case cfg.Interval < 0:
+		return fmt.Errorf("watch.push.interval must not be negative")
+	case cfg.Commits < 0:
+		return fmt.Errorf("watch.push.commits must not be negative")
+	case cfg.Timeout <= 0:
+		return fmt.Errorf("watch.push.timeout must be positive")
+	case cfg.RetryBackoff <= 0:
+		return fmt.Errorf("watch.push.retry_backoff must be positive")
+	case cfg.MaxBackoff < cfg.RetryBackoff:
+		return fmt.Errorf("watch.push.max_backoff must be at least watch.push.retry_backoff")
+	}
+	return nil
+}
+
+// Batcher tracks the unpushed commits and the outcome of pushes. It is
+// safe for concurrent use.
+type Batcher struct {
+	cfg *config.PushConfig
+	now func() time.Time
+
+	mu          sync.Mutex
+	pending     int
+	lastPush    time.Time
+	failures    int
+	nextAttempt time.Time
+}
+
+// New creates a Batcher with pending commits already waiting, e.g. those
+// a previous run failed to push.
+func New(cfg *config.PushConfig, pending int) *Batcher {
+	return &Batcher{cfg: cfg, now: time.Now, pending: pending}
+}
+
+// Committed records a new commit to push.
+func (b *Batcher) Committed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending++
+}
+
+// Pending returns the number of commits waiting to be pushed.
+func (b *Batcher) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending
+}
+
+// Due reports whether the pending commits should be pushed now.
+func (b *Batcher) Due() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	switch {
+	case b.pending == 0 || now.Before(b.nextAttempt):
+		return false
+	case b.cfg.Interval == 0 && b.cfg.Commits == 0:
+		return true
+	case b.cfg.Commits > 0 && b.pending >= b.cfg.Commits:
+		return true
+	default:
+		return b.cfg.Interval > 0 && now.Sub(b.lastPush) >= b.cfg.Interval
+	}
+}
+
+// Pushed records a successful push of every pending commit.
+func (b *Batcher) Pushed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = 0
+	b.failures = 0
+	b.lastPush = b.now()
+	b.nextAttempt = time.Time{}
+}
+
+// Failed records a failed push and returns how long to wait before the
+// next attempt: retry_backoff, doubled for each consecutive failure up to
+// max_backoff.
+func (b *Batcher) Failed() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	backoff := b.cfg.RetryBackoff
+	for i := 1; i < b.failures && backoff < b.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, b.cfg.MaxBackoff)
+	b.nextAttempt = b.now().Add(backoff)
+	return backoff
+}
@@ -0,0 +1,102 @@
+package pushbatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// clock is a fake time source advanced by the test.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestBatcher(cfg config.PushConfig, pending int) (*Batcher, *clock) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New(&cfg, pending)
+	b.now = c.now
+	return b, c
+}
+
+func testConfig() config.PushConfig {
+	return config.DefaultConfig().Watch.Push
+}
+
+func TestDue_EveryCommit(t *testing.T) {
+	b, _ := newTestBatcher(testConfig(), 0)
+	assert.False(t, b.Due(), "nothing to push")
+
+	b.Committed()
+	assert.True(t, b.Due())
+	b.Pushed()
+	assert.False(t, b.Due())
+	assert.Zero(t, b.Pending())
+}
+
+func TestDue_IntervalAndCommits(t *testing.T) {
+	cfg := testConfig()
+	cfg.Interval = 10 * time.Minute
+	cfg.Commits = 3
+	b, c := newTestBatcher(cfg, 0)
+
+	b.Committed()
+	assert.True(t, b.Due(), "the first push is not held back")
+	b.Pushed()
+
+	c.t = c.t.Add(time.Minute)
+	b.Committed()
+	b.Committed()
+	assert.False(t, b.Due(), "within the interval")
+	b.Committed()
+	assert.True(t, b.Due(), "enough commits are waiting")
+	b.Pushed()
+
+	b.Committed()
+	c.t = c.t.Add(9 * time.Minute)
+	assert.False(t, b.Due())
+	c.t = c.t.Add(time.Minute)
+	assert.True(t, b.Due(), "the interval has passed")
+}
+
+func TestFailed_BacksOff(t *testing.T) {
+	cfg := testConfig()
+	cfg.RetryBackoff = time.Minute
+	cfg.MaxBackoff = 5 * time.Minute
+	b, c := newTestBatcher(cfg, 2)
+	assert.True(t, b.Due(), "commits left unpushed by a previous run")
+
+	var backoffs []time.Duration
+	for i := 0; i < 5; i++ {
+		backoffs = append(backoffs, b.Failed())
+	}
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}, backoffs)
+
+	b.Committed()
+	assert.False(t, b.Due(), "backing off")
+	c.t = c.t.Add(5 * time.Minute)
+	assert.True(t, b.Due())
+	assert.Equal(t, 3, b.Pending())
+
+	b.Pushed()
+	b.Committed()
+	assert.Equal(t, time.Minute, b.Failed(), "a successful push resets the backoff")
+}
+
+func TestValidate(t *testing.T) {
+	cfg := testConfig()
+	assert.NoError(t, Validate(&cfg))
+
+	for _, mutate := range []func(*config.PushConfig){
+		func(c *config.PushConfig) { c.Interval = -time.Minute },
+		func(c *config.PushConfig) { c.Commits = -1 },
+		func(c *config.PushConfig) { c.Timeout = 0 },
+		func(c *config.PushConfig) { c.RetryBackoff = 0 },
+		func(c *config.PushConfig) { c.MaxBackoff = time.Second },
+	} {
+		bad := testConfig()
+		mutate(&bad)
+		assert.Error(t, Validate(&bad), "%+v", bad)
+	}
+}
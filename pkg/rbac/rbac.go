@@ -0,0 +1,343 @@
+// Package rbac flattens captured RBAC objects into effective permissions so
+// that drift can be reviewed as permission changes rather than rule diffs.
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/rawobj"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Kinds are the kinds of the RBAC objects effective permissions are
+// computed from.
+var Kinds = []string{"Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding"}
+
+// Permission is a single verb granted to a subject on a resource.
+type Permission struct {
+	Subject   string `json:"subject" yaml:"subject"`
+	Verb      string `json:"verb" yaml:"verb"`
+	Resource  string `json:"resource" yaml:"resource"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Via names the binding and role that grant the permission.
+	Via string `json:"via" yaml:"via"`
+}
+
+// key identifies a permission independently of how it is granted.
+func (p Permission) key() string {
+	return p.Subject + "|" + p.Verb + "|" + p.Resource + "|" + p.Namespace
+}
+
+// scope renders the namespace part of a permission.
+func (p Permission) scope() string {
+	if p.Namespace == "" {
+		return "cluster-wide"
+	}
+	return "in " + p.Namespace
+}
+
+// String renders the permission as "<subject> can <verb> <resource> <scope>".
+func (p Permission) String() string {
+	return fmt.Sprintf("%s can %s %s %s", p.Subject, p.Verb, p.Resource, p.scope())
+}
+
+// Change is a permission gained or lost between two snapshots.
+type Change struct {
+	Permission `yaml:",inline"`
+	Gained     bool `json:"gained" yaml:"gained"`
+}
+
+// String renders the change as "<subject> gained <verb> on <resource> <scope>".
+func (c Change) String() string {
+	action := "lost"
+	if c.Gained {
+		action = "gained"
+	}
+	return fmt.Sprintf("%s %s %s on %s %s", c.Subject, action, c.Verb, c.Resource, c.scope())
+}
+
+// rule is a single PolicyRule decoded from a Role or ClusterRole.
+type rule struct {
+	apiGroups       []string
+	resources       []string
+	resourceNames   []string
+	nonResourceURLs []string
+	verbs           []string
+}
+
+// Permissions returns the effective permissions granted by the RBAC objects
+// in a snapshot, one entry per subject/verb/resource/namespace.
+func Permissions(snapshot *types.ResourceSnapshot) []Permission {
+	roles := make(map[string][]rule)
+	for _, res := range snapshot.Resources {
+		switch res.Kind {
+		case "Role":
+			roles[roleKey("Role", res.Namespace, res.Name)] = decodeRules(res)
+		case "ClusterRole":
+			roles[roleKey("ClusterRole", "", res.Name)] = decodeRules(res)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var perms []Permission
+	for _, res := range snapshot.Resources {
+		if res.Kind != "RoleBinding" && res.Kind != "ClusterRoleBinding" {
+			continue
+		}
+
+		roleRef, _ := rawobj.Field(res, "roleRef").(map[string]interface{})
+		refKind, _ := roleRef["kind"].(string)
+		refName, _ := roleRef["name"].(string)
+		refNamespace := ""
+		if refKind == "Role" {
+			refNamespace = res.Namespace
+		}
+		rules := roles[roleKey(refKind, refNamespace, refName)]
+		via := fmt.Sprintf("%s %s → %s %s", res.Kind, res.FullName(), refKind, refName)
+
+		for _, subject := range decodeSubjects(res) {
+			for _, r := range rules {
+				for _, target := range r.targets() {
+					for _, verb := range r.verbs {
+						p := Permission{
+							Subject:   subject,
+							Verb:      verb,
+							Resource:  target,
+							Namespace: res.Namespace,
+							Via:       via,
+						}
+						if !seen[p.key()] {
+							seen[p.key()] = true
+							perms = append(perms, p)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sortPermissions(perms)
+	return perms
+}
+
+// Diff compares the effective permissions of two snapshots.
+func Diff(base, target *types.ResourceSnapshot) []Change {
+	return DiffPermissions(Permissions(base), Permissions(target))
+}
+
+// DiffPermissions compares two flattened permission sets.
+func DiffPermissions(base, target []Permission) []Change {
+	baseSet := make(map[string]bool, len(base))
+	for _, p := range base {
+		baseSet[p.key()] = true
+	}
+	targetSet := make(map[string]bool, len(target))
+	for _, p := range target {
+		targetSet[p.key()] = true
+	}
+
+	var changes []Change
+	for _, p := range target {
+		if !baseSet[p.key()] {
+			changes = append(changes, Change{Permission: p, Gained: true})
+		}
+	}
+	for _, p := range base {
+		if !targetSet[p.key()] {
+			changes = append(changes, Change{Permission: p, Gained: false})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Subject != changes[j].Subject {
+			return changes[i].Subject < changes[j].Subject
+		}
+		if changes[i].Gained != changes[j].Gained {
+			return changes[i].Gained
+		}
+		return changes[i].key() < changes[j].key()
+	})
+	return changes
+}
+
+// FilterSubject keeps only changes whose subject contains the given substring.
+func FilterSubject(changes []Change, subject string) []Change {
+	if subject == "" {
+		return changes
+	}
+	var out []Change
+	for _, c := range changes {
+		if strings.Contains(c.Subject, subject) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// FilterPermissions keeps only permissions whose subject contains the given
+// substring.
+func FilterPermissions(perms []Permission, subject string) []Permission {
+	if subject == "" {
+		return perms
+	}
+	var out []Permission
+	for _, p := range perms {
+		if strings.Contains(p.Subject, subject) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Exposure summarizes a set of effective permissions: how many subjects
+// hold how many permissions, and how many of them are granted cluster-wide
+// or through wildcards.
+func Exposure(perms []Permission) *types.RBACExposure {
+	exposure := &types.RBACExposure{Permissions: len(perms)}
+	subjects := make(map[string]bool)
+	for _, p := range perms {
+		subjects[p.Subject] = true
+		if p.Namespace == "" {
+			exposure.ClusterWide++
+		}
+		if p.Verb == "*" || strings.HasPrefix(p.Resource, "*") {
+			exposure.Wildcards++
+		}
+	}
+	exposure.Subjects = len(subjects)
+	return exposure
+}
+
+// Point is the effective permissions at one snapshot.
+type Point struct {
+	CommitHash  string
+	Timestamp   time.Time
+	Permissions []Permission
+}
+
+// Step is a snapshot at which effective permissions changed.
+type Step struct {
+	CommitHash string    `json:"commitHash" yaml:"commitHash"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	// Exposure summarizes the permissions of the followed subjects after
+	// the changes.
+	Exposure types.RBACExposure `json:"exposure" yaml:"exposure"`
+	Changes  []Change           `json:"changes" yaml:"changes"`
+}
+
+// History follows the effective permissions of the subjects containing
+// subject (all subjects if empty) through points, oldest first, and returns
+// the steps at which they changed. The first point is the baseline the
+// second is compared with; it is not a step itself.
+func History(points []Point, subject string) []Step {
+	var steps []Step
+	for i := 1; i < len(points); i++ {
+		before := FilterPermissions(points[i-1].Permissions, subject)
+		after := FilterPermissions(points[i].Permissions, subject)
+		changes := DiffPermissions(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+		steps = append(steps, Step{
+			CommitHash: points[i].CommitHash,
+			Timestamp:  points[i].Timestamp,
+			Exposure:   *Exposure(after),
+			Changes:    changes,
+		})
+	}
+	return steps
+}
+
+// targets expands a rule into resource (or non-resource URL) names.
+func (r rule) targets() []string {
+	out := append([]string(nil), r.nonResourceURLs...)
+
+	groups := r.apiGroups
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	for _, group := range groups {
+		for _, resource := range r.resources {
+			name := resource
+			if group != "" {
+				name = resource + "." + group
+			}
+			if len(r.resourceNames) == 0 {
+				out = append(out, name)
+				continue
+			}
+			for _, rn := range r.resourceNames {
+				out = append(out, name+"/"+rn)
+			}
+		}
+	}
+	return out
+}
+
+// decodeRules reads the rules of a Role or ClusterRole.
+func decodeRules(res types.Resource) []rule {
+	list, _ := rawobj.Field(res, "rules").([]interface{})
+	rules := make([]rule, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rules = append(rules, rule{
+			apiGroups:       rawobj.StringList(m["apiGroups"]),
+			resources:       rawobj.StringList(m["resources"]),
+			resourceNames:   rawobj.StringList(m["resourceNames"]),
+			nonResourceURLs: rawobj.StringList(m["nonResourceURLs"]),
+			verbs:           rawobj.StringList(m["verbs"]),
+		})
+	}
+	return rules
+}
+
+// decodeSubjects renders the subjects of a binding, e.g. "ServiceAccount ci/deployer".
+func decodeSubjects(res types.Resource) []string {
+	list, _ := rawobj.Field(res, "subjects").([]interface{})
+	var subjects []string
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		kind, _ := m["kind"].(string)
+		name, _ := m["name"].(string)
+		namespace, _ := m["namespace"].(string)
+		if kind == "ServiceAccount" {
+			if namespace == "" {
+				namespace = res.Namespace
+			}
+			name = namespace + "/" + name
+		}
+		subjects = append(subjects, kind+" "+name)
+	}
+	return subjects
+}
+
+// roleKey identifies a Role or ClusterRole.
+func roleKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// sortPermissions orders permissions by subject, namespace, resource, and verb.
+func sortPermissions(perms []Permission) {
+	sort.Slice(perms, func(i, j int) bool {
+		a, b := perms[i], perms[j]
+		if a.Subject != b.Subject {
+			return a.Subject < b.Subject
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Verb < b.Verb
+	})
+}
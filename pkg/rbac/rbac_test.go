@@ -0,0 +1,131 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func role(kind, namespace, name string, rules ...map[string]interface{}) types.Resource {
+	list := make([]interface{}, 0, len(rules))
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	return types.Resource{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Raw:       map[string]interface{}{"rules": list},
+	}
+}
+
+func policyRule(groups, resources, verbs []interface{}) map[string]interface{} {
+	return map[string]interface{}{"apiGroups": groups, "resources": resources, "verbs": verbs}
+}
+
+func binding(kind, namespace, name, roleKind, roleName string, subjects ...map[string]interface{}) types.Resource {
+	list := make([]interface{}, 0, len(subjects))
+	for _, s := range subjects {
+		list = append(list, s)
+	}
+	return types.Resource{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Raw: map[string]interface{}{
+			"roleRef":  map[string]interface{}{"kind": roleKind, "name": roleName},
+			"subjects": list,
+		},
+	}
+}
+
+var deployer = map[string]interface{}{"kind": "ServiceAccount", "name": "deployer", "namespace": "ci"}
+
+func TestPermissions_RoleBinding(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			role("Role", "prod", "secret-reader", policyRule([]interface{}{""}, []interface{}{"secrets"}, []interface{}{"get", "list"})),
+			binding("RoleBinding", "prod", "ci-secrets", "Role", "secret-reader", deployer),
+		},
+	}
+
+	perms := Permissions(snapshot)
+
+	require.Len(t, perms, 2)
+	assert.Equal(t, "ServiceAccount ci/deployer can get secrets in prod", perms[0].String())
+	assert.Equal(t, "list", perms[1].Verb)
+}
+
+func TestPermissions_ClusterRoleBinding(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{
+		Resources: []types.Resource{
+			role("ClusterRole", "", "deployer", policyRule([]interface{}{"apps"}, []interface{}{"deployments"}, []interface{}{"update"})),
+			binding("ClusterRoleBinding", "", "deployer", "ClusterRole", "deployer",
+				map[string]interface{}{"kind": "Group", "name": "release-managers"}),
+		},
+	}
+
+	perms := Permissions(snapshot)
+
+	require.Len(t, perms, 1)
+	assert.Equal(t, "Group release-managers can update deployments.apps cluster-wide", perms[0].String())
+}
+
+func TestDiff(t *testing.T) {
+	reader := role("ClusterRole", "", "edit", policyRule([]interface{}{""}, []interface{}{"configmaps"}, []interface{}{"get"}))
+	writer := role("ClusterRole", "", "edit",
+		policyRule([]interface{}{""}, []interface{}{"secrets"}, []interface{}{"create"}))
+
+	base := &types.ResourceSnapshot{Resources: []types.Resource{
+		reader,
+		binding("RoleBinding", "prod", "ci-edit", "ClusterRole", "edit", deployer),
+	}}
+	target := &types.ResourceSnapshot{Resources: []types.Resource{
+		writer,
+		binding("RoleBinding", "prod", "ci-edit", "ClusterRole", "edit", deployer),
+	}}
+
+	changes := Diff(base, target)
+
+	require.Len(t, changes, 2)
+	assert.Equal(t, "ServiceAccount ci/deployer gained create on secrets in prod", changes[0].String())
+	assert.Equal(t, "ServiceAccount ci/deployer lost get on configmaps in prod", changes[1].String())
+
+	assert.Len(t, FilterSubject(changes, "deployer"), 2)
+	assert.Empty(t, FilterSubject(changes, "someone-else"))
+}
+
+func TestExposure(t *testing.T) {
+	exposure := Exposure([]Permission{
+		{Subject: "Group admins", Verb: "*", Resource: "*.*"},
+		{Subject: "Group admins", Verb: "get", Resource: "pods", Namespace: "prod"},
+		{Subject: "ServiceAccount ci/deployer", Verb: "update", Resource: "deployments.apps", Namespace: "prod"},
+	})
+	assert.Equal(t, types.RBACExposure{Subjects: 2, Permissions: 3, ClusterWide: 1, Wildcards: 1}, *exposure)
+}
+
+func TestHistory(t *testing.T) {
+	get := Permission{Subject: "ServiceAccount ci/deployer", Verb: "get", Resource: "secrets", Namespace: "prod"}
+	create := Permission{Subject: "ServiceAccount ci/deployer", Verb: "create", Resource: "secrets", Namespace: "prod"}
+	other := Permission{Subject: "Group ops", Verb: "get", Resource: "pods"}
+	points := []Point{
+		{CommitHash: "a", Permissions: []Permission{get}},
+		{CommitHash: "b", Permissions: []Permission{get, other}},
+		{CommitHash: "c", Permissions: []Permission{get, create, other}},
+		{CommitHash: "d", Permissions: []Permission{create, other}},
+	}
+
+	steps := History(points, "ci/deployer")
+	require.Len(t, steps, 2)
+	assert.Equal(t, "c", steps[0].CommitHash)
+	require.Len(t, steps[0].Changes, 1)
+	assert.Equal(t, "ServiceAccount ci/deployer gained create on secrets in prod", steps[0].Changes[0].String())
+	assert.Equal(t, 2, steps[0].Exposure.Permissions)
+	assert.Equal(t, "d", steps[1].CommitHash)
+	assert.False(t, steps[1].Changes[0].Gained)
+
+	assert.Len(t, History(points, ""), 3, "every subject")
+	assert.Empty(t, History(points[:1], ""))
+}
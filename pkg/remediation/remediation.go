@@ -0,0 +1,306 @@
+// Package remediation triggers external remediation workflows, such as
+// opening a ticket or dispatching a GitHub Actions workflow, when drift of
+// a configured severity persists across consecutive watch ticks.
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/httpstatus"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxDetailEntries is how many entries Event.Details lists.
+const maxDetailEntries = 20
+
+// Enabled reports whether any remediation workflow is configured.
+func Enabled(cfg *config.RemediationConfig) bool {
+	return len(cfg.Webhooks) > 0 || cfg.GitHub.Repository != ""
+}
+
+// Validate checks that the remediation configuration is well-formed and
+// that its templates parse.
+func Validate(cfg *config.RemediationConfig) error {
+	if !Enabled(cfg) {
+		return nil
+	}
+	if _, err := policy.ParseSeverity(cfg.Severity); err != nil {
+		return fmt.Errorf("watch.remediation.severity: %w", err)
+	}
+	if cfg.Checks < 1 {
+		return fmt.Errorf("watch.remediation.checks must be at least 1")
+	}
+	for i, hook := range cfg.Webhooks {
+		if hook.Name == "" || hook.URL == "" {
+			return fmt.Errorf("watch.remediation.webhooks[%d] needs a name and a url", i)
+		}
+		switch strings.ToUpper(hook.Method) {
+		case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			return fmt.Errorf("watch.remediation.webhooks[%d] (%s): method must be POST, PUT, or PATCH", i, hook.Name)
+		}
+		if _, err := parse(hook.Name, hook.Body); err != nil {
+			return fmt.Errorf("watch.remediation.webhooks[%d] (%s): %w", i, hook.Name, err)
+		}
+		for name, value := range hook.Headers {
+			if _, err := parse(name, value); err != nil {
+				return fmt.Errorf("watch.remediation.webhooks[%d] (%s) header %s: %w", i, hook.Name, name, err)
+			}
+		}
+	}
+	gh := cfg.GitHub
+	if gh.Repository != "" {
+		if owner, name, ok := strings.Cut(gh.Repository, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("watch.remediation.github.repository must be owner/name, got %q", gh.Repository)
+		}
+		if gh.Workflow == "" || gh.Ref == "" || gh.Token == "" {
+			return fmt.Errorf("watch.remediation.github needs workflow, ref, and token")
+		}
+		for name, value := range gh.Inputs {
+			if _, err := parse(name, value); err != nil {
+				return fmt.Errorf("watch.remediation.github input %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Event is the persistent drift that triggered remediation. Webhook bodies
+// and headers and workflow inputs are templates over it.
+type Event struct {
+	// Cluster is the fleet cluster the drift is in, if any.
+	Cluster string `json:"cluster,omitempty"`
+	// Checks is the number of consecutive ticks the drift was seen on.
+	Checks int `json:"checks"`
+	// Severity is the highest severity of the drift in the latest tick;
+	// Threshold is the configured minimum.
+	Severity  string    `json:"severity"`
+	Threshold string    `json:"threshold"`
+	Time      time.Time `json:"time"`
+	// Summary is a one-line description, e.g. for a ticket title.
+	Summary string `json:"summary"`
+	// Details lists the entries one per line, e.g. for a ticket description.
+	Details string `json:"details"`
+	// Entries are the latest tick's drift entries at or above Threshold,
+	// most severe first.
+	Entries []types.DriftEntry `json:"-"`
+	// Report is the latest tick's whole drift report.
+	Report *types.DriftReport `json:"-"`
+}
+
+// Remediator counts consecutive ticks with drift at or above the
+// configured severity and triggers the configured workflows. It is not
+// safe for concurrent use; watch runs one tick at a time.
+type Remediator struct {
+	cfg       *config.RemediationConfig
+	threshold policy.Severity
+	// streaks counts consecutive drifting ticks per cluster ("" outside a
+	// fleet).
+	streaks map[string]int
+	client  *http.Client
+}
+
+// New creates a remediator with no drift seen yet.
+func New(cfg *config.RemediationConfig) *Remediator {
+	threshold, _ := policy.ParseSeverity(cfg.Severity)
+	return &Remediator{
+		cfg:       cfg,
+		threshold: threshold,
+		streaks:   make(map[string]int),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Observe records the drift report of a cluster's tick, nil if there was
+// nothing to compare with, and returns an event when the drift has
+// persisted for the configured number of ticks. A tick without drift at
+// or above the threshold ends the streak, and a streak triggers only once.
+// Drift in a maintenance window does not count.
+func (r *Remediator) Observe(cluster string, report *types.DriftReport) (*Event, bool) {
+	var entries []types.DriftEntry
+	if report != nil && report.SuppressedBy == "" {
+		for _, e := range report.Entries {
+			if sev, err := policy.ParseSeverity(e.Severity); err == nil && sev >= r.threshold {
+				entries = append(entries, e)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		r.streaks[cluster] = 0
+		return nil, false
+	}
+	r.streaks[cluster]++
+	if r.streaks[cluster] != r.cfg.Checks {
+		return nil, false
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, _ := policy.ParseSeverity(entries[i].Severity)
+		b, _ := policy.ParseSeverity(entries[j].Severity)
+		return a > b
+	})
+	event := &Event{
+		Cluster:   cluster,
+		Checks:    r.cfg.Checks,
+		Severity:  entries[0].Severity,
+		Threshold: r.threshold.String(),
+		Time:      time.Now().UTC(),
+		Entries:   entries,
+		Report:    report,
+	}
+	event.Summary = fmt.Sprintf("%d drift entries at or above %s severity persisted for %d consecutive checks",
+		len(entries), event.Threshold, event.Checks)
+	if cluster != "" {
+		event.Summary = cluster + ": " + event.Summary
+	}
+	var b strings.Builder
+	for _, e := range entries[:min(len(entries), maxDetailEntries)] {
+		fmt.Fprintf(&b, "%s %s (%s)\n", strings.ToLower(string(e.Type)), e.Resource.FullName(), e.Severity)
+	}
+	if n := len(entries) - maxDetailEntries; n > 0 {
+		fmt.Fprintf(&b, "… and %d more\n", n)
+	}
+	event.Details = b.String()
+	return event, true
+}
+
+// Trigger calls every configured webhook and dispatches the configured
+// workflow, continuing past failures, and returns the failures joined.
+func (r *Remediator) Trigger(ctx context.Context, event *Event) error {
+	var errs []error
+	for _, hook := range r.cfg.Webhooks {
+		if err := r.webhook(ctx, hook, event); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.Name, err))
+			continue
+		}
+		log.WithField("webhook", hook.Name).Info("remediation webhook called")
+	}
+	if r.cfg.GitHub.Repository != "" {
+		if err := r.dispatch(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("github workflow %s: %w", r.cfg.GitHub.Workflow, err))
+		} else {
+			log.WithFields(log.Fields{
+				"repository": r.cfg.GitHub.Repository,
+				"workflow":   r.cfg.GitHub.Workflow,
+			}).Info("remediation workflow dispatched")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// webhook makes a webhook's request with its rendered body and headers.
+func (r *Remediator) webhook(ctx context.Context, hook config.RemediationWebhook, event *Event) error {
+	var body []byte
+	if hook.Body == "" {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		body = data
+	} else {
+		rendered, err := render(hook.Name, hook.Body, event)
+		if err != nil {
+			return err
+		}
+		body = []byte(rendered)
+	}
+	method := strings.ToUpper(hook.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		rendered, err := render(name, value, event)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(name, rendered)
+	}
+	return r.do(req)
+}
+
+// dispatch creates a workflow_dispatch event for the configured workflow.
+func (r *Remediator) dispatch(ctx context.Context, event *Event) error {
+	gh := r.cfg.GitHub
+	inputs := make(map[string]string, len(gh.Inputs))
+	for name, value := range gh.Inputs {
+		rendered, err := render(name, value, event)
+		if err != nil {
+			return err
+		}
+		inputs[name] = rendered
+	}
+	body, err := json.Marshal(map[string]interface{}{"ref": gh.Ref, "inputs": inputs})
+	if err != nil {
+		return fmt.Errorf("failed to encode dispatch: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/actions/workflows/%s/dispatches",
+		strings.TrimSuffix(gh.APIURL, "/"), gh.Repository, url.PathEscape(gh.Workflow))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+gh.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+	return r.do(req)
+}
+
+// do sends a request and fails on a non-2xx response.
+func (r *Remediator) do(req *http.Request) error {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	return httpstatus.Check(resp)
+}
+
+// funcs are available in templates: json encodes a value, e.g. to quote
+// a string inside a JSON body.
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parse parses a template.
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// render executes a template over the event.
+func render(name, text string, event *Event) (string, error) {
+	tmpl, err := parse(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, event); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return b.String(), nil
+}
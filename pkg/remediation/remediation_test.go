@@ -0,0 +1,182 @@
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drift(severities ...string) *types.DriftReport {
+	report := &types.DriftReport{}
+	for i, sev := range severities {
+		report.Entries = append(report.Entries, types.DriftEntry{
+			Type:     types.DriftModified,
+			Resource: types.Resource{Kind: "Deployment", Namespace: "prod", Name: []string{"api", "web", "worker"}[i]},
+			Severity: sev,
+		})
+	}
+	return report
+}
+
+func TestObserve(t *testing.T) {
+	r := New(&config.RemediationConfig{Severity: "high", Checks: 3})
+
+	_, ok := r.Observe("", drift("high"))
+	assert.False(t, ok)
+	_, ok = r.Observe("", drift("low"))
+	assert.False(t, ok, "drift below the threshold ends the streak")
+	_, ok = r.Observe("", drift("high"))
+	assert.False(t, ok)
+	_, ok = r.Observe("", drift("critical", "low"))
+	assert.False(t, ok)
+
+	event, ok := r.Observe("", drift("high", "low", "critical"))
+	require.True(t, ok)
+	assert.Equal(t, 3, event.Checks)
+	assert.Equal(t, "critical", event.Severity)
+	assert.Equal(t, "high", event.Threshold)
+	require.Len(t, event.Entries, 2, "entries below the threshold are left out")
+	assert.Equal(t, "worker", event.Entries[0].Resource.Name, "most severe first")
+	assert.Equal(t, "2 drift entries at or above high severity persisted for 3 consecutive checks", event.Summary)
+	assert.Equal(t, "modified prod/Deployment/worker (critical)\nmodified prod/Deployment/api (high)\n", event.Details)
+
+	_, ok = r.Observe("", drift("high"))
+	assert.False(t, ok, "a streak triggers once")
+}
+
+func TestObserve_Clusters(t *testing.T) {
+	r := New(&config.RemediationConfig{Severity: "medium", Checks: 2})
+
+	_, ok := r.Observe("eu", drift("high"))
+	assert.False(t, ok)
+	_, ok = r.Observe("us", nil)
+	assert.False(t, ok)
+	event, ok := r.Observe("eu", drift("medium"))
+	require.True(t, ok, "streaks are kept per cluster")
+	assert.Equal(t, "eu: 1 drift entries at or above medium severity persisted for 2 consecutive checks", event.Summary)
+
+	suppressed := drift("critical")
+	suppressed.SuppressedBy = "weekly-upgrade"
+	_, ok = r.Observe("us", suppressed)
+	assert.False(t, ok)
+	_, ok = r.Observe("us", drift("critical"))
+	assert.False(t, ok, "drift in a maintenance window does not count")
+}
+
+func TestTrigger(t *testing.T) {
+	var ticket map[string]interface{}
+	var auth string
+	tickets := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&ticket))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer tickets.Close()
+
+	var dispatch struct {
+		Ref    string            `json:"ref"`
+		Inputs map[string]string `json:"inputs"`
+	}
+	var path string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.Equal(t, "Bearer ghp_test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&dispatch))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer github.Close()
+
+	cfg := &config.RemediationConfig{
+		Severity: "high",
+		Checks:   1,
+		Webhooks: []config.RemediationWebhook{{
+			Name:    "jira",
+			URL:     tickets.URL,
+			Headers: map[string]string{"Authorization": "Basic dGVzdA=="},
+			Body:    `{"fields": {"summary": {{ json .Summary }}, "description": {{ json .Details }}, "labels": ["{{ .Severity }}"]}}`,
+		}},
+		GitHub: config.WorkflowDispatchConfig{
+			Repository: "acme/platform",
+			Workflow:   "remediate.yml",
+			Ref:        "main",
+			Token:      "ghp_test",
+			Inputs:     map[string]string{"severity": "{{ .Severity }}", "checks": "{{ .Checks }}"},
+			APIURL:     github.URL + "/",
+		},
+	}
+	require.NoError(t, Validate(cfg))
+	r := New(cfg)
+	event, ok := r.Observe("", drift("critical"))
+	require.True(t, ok)
+
+	require.NoError(t, r.Trigger(context.Background(), event))
+
+	assert.Equal(t, "Basic dGVzdA==", auth)
+	fields := ticket["fields"].(map[string]interface{})
+	assert.Equal(t, event.Summary, fields["summary"])
+	assert.Equal(t, "modified prod/Deployment/api (critical)\n", fields["description"])
+	assert.Equal(t, []interface{}{"critical"}, fields["labels"])
+
+	assert.Equal(t, "/repos/acme/platform/actions/workflows/remediate.yml/dispatches", path)
+	assert.Equal(t, "main", dispatch.Ref)
+	assert.Equal(t, map[string]string{"severity": "critical", "checks": "1"}, dispatch.Inputs)
+}
+
+func TestTrigger_DefaultBodyAndFailure(t *testing.T) {
+	var body []byte
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+
+	r := New(&config.RemediationConfig{Severity: "low", Checks: 1, Webhooks: []config.RemediationWebhook{
+		{Name: "servicenow", URL: failing.URL},
+		{Name: "hook", URL: ok.URL},
+	}})
+	event, _ := r.Observe("", drift("medium"))
+
+	err := r.Trigger(context.Background(), event)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook servicenow: HTTP 401: invalid credentials")
+	var sent map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &sent), "later webhooks are still called")
+	assert.Equal(t, event.Summary, sent["summary"])
+	assert.Equal(t, "medium", sent["severity"])
+}
+
+func TestValidate(t *testing.T) {
+	hook := config.RemediationWebhook{Name: "jira", URL: "https://acme.atlassian.net/rest/api/2/issue"}
+	gh := config.WorkflowDispatchConfig{Repository: "acme/platform", Workflow: "remediate.yml", Ref: "main", Token: "t"}
+
+	assert.NoError(t, Validate(&config.RemediationConfig{}), "nothing configured")
+	assert.NoError(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 3, Webhooks: []config.RemediationWebhook{hook}, GitHub: gh}))
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "urgent", Checks: 3, GitHub: gh}))
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 0, GitHub: gh}))
+
+	bad := hook
+	bad.Body = "{{ .Summary"
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 1, Webhooks: []config.RemediationWebhook{bad}}))
+	bad = hook
+	bad.Method = "GET"
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 1, Webhooks: []config.RemediationWebhook{bad}}))
+
+	badGH := gh
+	badGH.Repository = "platform"
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 1, GitHub: badGH}))
+	badGH = gh
+	badGH.Token = ""
+	assert.Error(t, Validate(&config.RemediationConfig{Severity: "high", Checks: 1, GitHub: badGH}))
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/textfmt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+var htmlTemplate = template.Must(template.New("drift").Funcs(template.FuncMap{
+	"apiDetail": apiChangeDetail,
+	"ref":       refName,
+	"resource":  types.Resource.DisplayName,
+	"value":     textfmt.Value,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+td.n { text-align: right; }
+ul.diffs { margin: 0; padding-left: 1.2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- with .Report.Summary}}
+<table>
+<tr><th>Added</th><th>Removed</th><th>Modified</th><th>Recreated</th><th>Scaled</th><th>Ownership</th><th>Unchanged</th></tr>
+<tr><td class="n">{{.AddedResources}}</td><td class="n">{{.RemovedResources}}</td><td class="n">{{.ModifiedResources}}</td><td class="n">{{.RecreatedResources}}</td><td class="n">{{.ScaledResources}}</td><td class="n">{{.OwnershipChanges}}</td><td class="n">{{.UnchangedResources}}</td></tr>
+</table>
+{{- end}}
+{{- if or .Report.BaseURL .Report.TargetURL}}
+<p><a href="{{.Report.BaseURL}}">Base</a> · <a href="{{.Report.TargetURL}}">Target</a></p>
+{{- end}}
+{{- with .Report.SuppressedBy}}
+<p>Recorded during maintenance window {{.}}.</p>
+{{- end}}
+{{- with .Report.Scope}}
+<p>{{.Describe}}</p>
+{{- end}}
+{{- if .Entries}}
+<h2>Changes</h2>
+<table>
+<tr><th>Change</th><th>Severity</th><th>Resource</th><th>Team</th><th>Details</th></tr>
+{{- range .Entries}}
+<tr><td>{{.Entry.Type}}</td><td>{{.Entry.Severity}}</td><td>{{if .Entry.URL}}<a href="{{.Entry.URL}}">{{resource .Entry.Resource}}</a>{{else}}{{resource .Entry.Resource}}{{end}}</td><td>{{.Entry.Team}}</td><td>
+{{- if or .Entry.Summaries .Diffs}}
+<ul class="diffs">
+{{- range .Entry.Summaries}}
+<li>⇒ {{.}}</li>
+{{- end}}
+{{- range .Diffs}}
+<li><code>{{.Path}}</code>: {{value .OldValue}} → {{value .NewValue}}</li>
+{{- end}}
+</ul>
+{{- end -}}
+</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No drift detected.</p>
+{{- end}}
+{{- with .Report.APIChanges}}
+<h2>API changes</h2>
+<ul>
+{{- range .}}
+<li>{{.Type}} {{.CRD}}{{apiDetail .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Report.Unmanaged}}
+<h2>Unmanaged resources</h2>
+<ul>
+{{- range .}}
+<li>{{ref .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with .Report.PolicyViolations}}
+<h2>Policy violations</h2>
+<table>
+<tr><th>Severity</th><th>Resource</th><th>Rule</th><th>Violation</th></tr>
+{{- range .}}
+<tr><td>{{.Severity}}</td><td>{{ref .Resource}}</td><td>{{.Rule}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders a report as a standalone HTML page with the same content as
+// Markdown.
+type HTML struct{}
+
+// Render implements Renderer.
+func (HTML) Render(w io.Writer, report *types.DriftReport) error {
+	type entry struct {
+		Entry types.DriftEntry
+		Diffs []types.FieldDiff
+	}
+	data := struct {
+		Title   string
+		Report  *types.DriftReport
+		Entries []entry
+	}{Title: title(report), Report: report}
+	for _, e := range sortedEntries(report) {
+		data.Entries = append(data.Entries, entry{Entry: e, Diffs: maskedDiffs(e)})
+	}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render drift report: %w", err)
+	}
+	return nil
+}
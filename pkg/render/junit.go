@@ -0,0 +1,119 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/textfmt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnit renders a report as JUnit XML, so that CI systems show drift as
+// failed tests: each changed resource is a failing test case with its field
+// diffs, secrets masked. A report without drift is one passing test case,
+// and drift during a maintenance window is reported as skipped.
+type JUnit struct{}
+
+// Render implements Renderer.
+func (JUnit) Render(w io.Writer, report *types.DriftReport) error {
+	suite := junitSuite{Name: title(report)}
+	if !report.Timestamp.IsZero() {
+		suite.Timestamp = report.Timestamp.UTC().Format("2006-01-02T15:04:05")
+	}
+	for _, e := range sortedEntries(report) {
+		res := e.Resource
+		class := res.Kind
+		if res.Namespace != "" {
+			class = res.Namespace + "." + res.Kind
+		}
+		c := junitCase{ClassName: class, Name: res.Name}
+		message := string(e.Type)
+		if e.Severity != "" {
+			message += " (" + e.Severity + ")"
+		}
+		if report.SuppressedBy != "" {
+			c.Skipped = &junitSkipped{Message: fmt.Sprintf("%s during maintenance window %s", message, report.SuppressedBy)}
+			suite.Skipped++
+		} else {
+			c.Failure = &junitFailure{Message: message, Type: string(e.Type), Text: junitText(e)}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	if len(suite.Cases) == 0 {
+		suite.Cases = append(suite.Cases, junitCase{ClassName: "drift", Name: "no drift"})
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{
+		Name:     "gitops-time-machine",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitSuite{suite},
+	}); err != nil {
+		return fmt.Errorf("failed to render drift report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitText lists the summaries and field diffs of a change, one per line.
+func junitText(e types.DriftEntry) string {
+	var lines []string
+	if e.Team != "" {
+		lines = append(lines, "team: "+e.Team)
+	}
+	if e.URL != "" {
+		lines = append(lines, e.URL)
+	}
+	for _, summary := range e.Summaries {
+		lines = append(lines, "⇒ "+summary)
+	}
+	for _, d := range maskedDiffs(e) {
+		lines = append(lines, fmt.Sprintf("%s: %s → %s", d.Path, textfmt.Value(d.OldValue), textfmt.Value(d.NewValue)))
+	}
+	return strings.Join(lines, "\n")
+}
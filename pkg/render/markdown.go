@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/textfmt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Markdown renders a report as a Markdown document, e.g. for a pull request
+// comment: the summary counts, then every change, most severe first, with
+// its field diffs. Secret values are masked as in notifications.
+type Markdown struct{}
+
+// Render implements Renderer.
+func (Markdown) Render(w io.Writer, report *types.DriftReport) error {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", title(report))
+	s := report.Summary
+	b.WriteString("| Added | Removed | Modified | Recreated | Scaled | Ownership | Unchanged |\n|---:|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d | %d | %d | %d |\n", s.AddedResources, s.RemovedResources, s.ModifiedResources,
+		s.RecreatedResources, s.ScaledResources, s.OwnershipChanges, s.UnchangedResources)
+	if report.BaseURL != "" || report.TargetURL != "" {
+		fmt.Fprintf(&b, "\n[Base](%s) · [Target](%s)\n", report.BaseURL, report.TargetURL)
+	}
+	if report.SuppressedBy != "" {
+		fmt.Fprintf(&b, "\n> Recorded during maintenance window %q.\n", report.SuppressedBy)
+	}
+	if report.Scope != nil {
+		fmt.Fprintf(&b, "\n> %s\n", textfmt.EscapeMarkdown(report.Scope.Describe()))
+	}
+	if len(report.Entries) == 0 {
+		b.WriteString("\nNo drift detected.\n")
+	} else {
+		b.WriteString("\n## Changes\n")
+	}
+
+	for _, e := range sortedEntries(report) {
+		fmt.Fprintf(&b, "\n### %s %s\n\n", e.Type, textfmt.EscapeMarkdown(e.Resource.DisplayName()))
+		var facts []string
+		if e.Severity != "" {
+			facts = append(facts, "Severity: "+e.Severity)
+		}
+		if e.Team != "" {
+			facts = append(facts, "Team: "+textfmt.EscapeMarkdown(e.Team))
+		}
+		if e.URL != "" {
+			facts = append(facts, "[File]("+e.URL+")")
+		}
+		if len(facts) > 0 {
+			b.WriteString(strings.Join(facts, " · ") + "\n\n")
+		}
+		for _, summary := range e.Summaries {
+			fmt.Fprintf(&b, "- ⇒ %s\n", textfmt.EscapeMarkdown(summary))
+		}
+		for _, d := range maskedDiffs(e) {
+			fmt.Fprintf(&b, "- %s: %s → %s\n", mdCode(d.Path), mdCode(textfmt.Value(d.OldValue)), mdCode(textfmt.Value(d.NewValue)))
+		}
+	}
+
+	if len(report.APIChanges) > 0 {
+		b.WriteString("\n## API changes\n\n")
+		for _, c := range report.APIChanges {
+			fmt.Fprintf(&b, "- %s %s%s\n", c.Type, c.CRD, apiChangeDetail(c))
+		}
+	}
+	if len(report.Unmanaged) > 0 {
+		b.WriteString("\n## Unmanaged resources\n\n")
+		for _, ref := range report.Unmanaged {
+			fmt.Fprintf(&b, "- %s\n", textfmt.EscapeMarkdown(refName(ref)))
+		}
+	}
+	if len(report.PolicyViolations) > 0 {
+		b.WriteString("\n## Policy violations\n\n| Severity | Resource | Rule | Violation |\n|---|---|---|---|\n")
+		for _, v := range report.PolicyViolations {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", v.Severity, textfmt.EscapeMarkdown(refName(v.Resource)), v.Rule, textfmt.EscapeMarkdown(v.Message))
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// maskedDiffs returns the field diffs of an entry with secret values masked.
+func maskedDiffs(e types.DriftEntry) []types.FieldDiff {
+	diffs := make([]types.FieldDiff, len(e.FieldDiffs))
+	for i, d := range e.FieldDiffs {
+		diffs[i] = notifier.MaskFieldDiff(e.Resource, d)
+	}
+	return diffs
+}
+
+// apiChangeDetail describes the versions and schemas an API change touched,
+// e.g. ": versions added v2; storage v1 → v2".
+func apiChangeDetail(c types.APIChange) string {
+	var parts []string
+	if len(c.AddedVersions) > 0 {
+		parts = append(parts, "versions added "+strings.Join(c.AddedVersions, ", "))
+	}
+	if len(c.RemovedVersions) > 0 {
+		parts = append(parts, "versions removed "+strings.Join(c.RemovedVersions, ", "))
+	}
+	if len(c.SchemaChanged) > 0 {
+		parts = append(parts, "schema changed in "+strings.Join(c.SchemaChanged, ", "))
+	}
+	if c.Type == types.DriftModified && c.OldStorage != c.NewStorage {
+		parts = append(parts, "storage "+c.OldStorage+" → "+c.NewStorage)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(parts, "; ")
+}
+
+// refName formats a resource reference as Kind/namespace/name.
+func refName(ref types.ResourceRef) string {
+	return types.Resource{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}.DisplayName()
+}
+
+// mdCode formats s as inline code, with a longer fence if it contains
+// backticks.
+func mdCode(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,168 @@
+// Package render writes drift reports in the output formats selectable with
+// --format: colored text for the terminal, Markdown and HTML documents, JSON
+// and YAML, SARIF for code scanning dashboards, and JUnit XML for CI test reports.
+//
+// Formats are looked up by name, so a new one only has to implement
+// Renderer and be added to the registry.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/printer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/helm"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/ownership"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the output formats.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatSARIF    = "sarif"
+	FormatJUnit    = "junit"
+)
+
+// Values of Options.GroupBy.
+const (
+	GroupByNone    = ""
+	GroupByTeam    = "team"
+	GroupByRelease = "release"
+)
+
+// Renderer writes a drift report in one output format.
+type Renderer interface {
+	Render(w io.Writer, report *types.DriftReport) error
+}
+
+// Options tune how a report is rendered. Formats ignore the options that do
+// not apply to them.
+type Options struct {
+	// Expand shows field changes even for large reports (text).
+	Expand bool
+	// Width is the line width values are cut to; 0 prints them in full
+	// (text).
+	Width int
+	// GroupBy groups entries by owning team or Helm release instead of
+	// severity (text).
+	GroupBy string
+}
+
+// renderers maps each format to the constructor of its Renderer.
+var renderers = map[string]func(Options) Renderer{
+	FormatText:     func(opts Options) Renderer { return &Text{Options: opts} },
+	FormatMarkdown: func(Options) Renderer { return Markdown{} },
+	FormatHTML:     func(Options) Renderer { return HTML{} },
+	FormatJSON:     func(Options) Renderer { return JSON{} },
+	FormatYAML:     func(Options) Renderer { return YAML{} },
+	FormatSARIF:    func(Options) Renderer { return SARIF{} },
+	FormatJUnit:    func(Options) Renderer { return JUnit{} },
+}
+
+// Formats returns the names of the supported formats, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the renderer of a format.
+func New(format string, opts Options) (Renderer, error) {
+	newRenderer, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %q (use %s)", format, strings.Join(Formats(), ", "))
+	}
+	switch opts.GroupBy {
+	case GroupByNone, GroupByTeam, GroupByRelease:
+	default:
+		return nil, fmt.Errorf("unsupported --group-by value %q (use team or release)", opts.GroupBy)
+	}
+	return newRenderer(opts), nil
+}
+
+// Text renders a report as the colored, indented summary printed to
+// terminals.
+type Text struct {
+	Options Options
+}
+
+// Render implements Renderer.
+func (t *Text) Render(w io.Writer, report *types.DriftReport) error {
+	opts := printer.DriftOptions{Expand: t.Options.Expand, Width: t.Options.Width}
+	switch t.Options.GroupBy {
+	case GroupByTeam:
+		printer.DriftSummaryGrouped(w, report, "Team", ownership.TeamOf, opts)
+		return nil
+	case GroupByRelease:
+		printer.DriftSummaryGrouped(w, report, "Release", helm.ReleaseOfEntry, opts)
+		return nil
+	}
+	printer.DriftSummary(w, report, opts)
+	return nil
+}
+
+// JSON renders a report as indented JSON, in the layout of the drift reports
+// the snapshot repository stores.
+type JSON struct{}
+
+// Render implements Renderer.
+func (JSON) Render(w io.Writer, report *types.DriftReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// YAML renders a report as YAML with the same fields as JSON.
+type YAML struct{}
+
+// Render implements Renderer.
+func (YAML) Render(w io.Writer, report *types.DriftReport) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to render drift report: %w", err)
+	}
+	return enc.Close()
+}
+
+// title names the compared states, e.g. "Drift from HEAD to live".
+func title(report *types.DriftReport) string {
+	if report.BaseRef == "" || report.TargetRef == "" {
+		return "Drift report"
+	}
+	return fmt.Sprintf("Drift from %s to %s", shortRef(report.BaseRef), shortRef(report.TargetRef))
+}
+
+// shortRef abbreviates full commit hashes.
+func shortRef(ref string) string {
+	if len(ref) == 40 && strings.Trim(ref, "0123456789abcdef") == "" {
+		return ref[:8]
+	}
+	return ref
+}
+
+// sortedEntries returns the entries ordered by severity, most severe first,
+// then by name.
+func sortedEntries(report *types.DriftReport) []types.DriftEntry {
+	list := append([]types.DriftEntry(nil), report.Entries...)
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := policy.Rank(list[i].Severity), policy.Rank(list[j].Severity)
+		if a != b {
+			return a > b
+		}
+		return list[i].Resource.FullName() < list[j].Resource.FullName()
+	})
+	return list
+}
@@ -0,0 +1,172 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testReport() *types.DriftReport {
+	return &types.DriftReport{
+		BaseRef:   "0123456789abcdef0123456789abcdef01234567",
+		TargetRef: "live",
+		Summary:   types.DriftSummary{AddedResources: 1, ModifiedResources: 2, UnchangedResources: 7},
+		Entries: []types.DriftEntry{
+			{
+				Type: types.DriftScaled, Severity: "low",
+				Resource:   types.Resource{Kind: "Deployment", Namespace: "prod", Name: "web"},
+				FieldDiffs: []types.FieldDiff{{Path: ".spec.replicas", OldValue: 2, NewValue: 3}},
+			},
+			{
+				Type: types.DriftModified, Severity: "critical", Team: "payments",
+				Resource:   types.Resource{Kind: "Secret", Namespace: "prod", Name: "db"},
+				FieldDiffs: []types.FieldDiff{{Path: ".data.password", OldValue: "aHVudGVyMg==", NewValue: "c3dvcmRmaXNo"}},
+			},
+			{Type: types.DriftAdded, Resource: types.Resource{Kind: "ClusterRole", Name: "reader"}},
+		},
+	}
+}
+
+var violation = types.PolicyViolation{
+	Rule: "image-latest", Severity: "high",
+	Resource: types.ResourceRef{Kind: "Deployment", Namespace: "prod", Name: "web"},
+	Message:  "container app runs nginx, which is not pinned to a tag or digest",
+}
+
+func render(t *testing.T, format string, report *types.DriftReport) string {
+	t.Helper()
+	r, err := New(format, Options{})
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, r.Render(&b, report))
+	return b.String()
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, []string{"html", "json", "junit", "markdown", "sarif", "text", "yaml"}, Formats())
+
+	_, err := New("pdf", Options{})
+	assert.EqualError(t, err, `unsupported format "pdf" (use html, json, junit, markdown, sarif, text, yaml)`)
+	_, err = New(FormatText, Options{GroupBy: "owner"})
+	assert.Error(t, err)
+}
+
+func TestText(t *testing.T) {
+	out := render(t, FormatText, testReport())
+	assert.Contains(t, out, "Drift Analysis")
+	assert.Contains(t, out, ".spec.replicas")
+	assert.Contains(t, out, "aHVudGVyMg==", "the terminal shows values unmasked, as before")
+
+	r, err := New(FormatText, Options{GroupBy: GroupByTeam})
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, r.Render(&b, testReport()))
+	assert.Contains(t, b.String(), "payments")
+
+	report := testReport()
+	report.Entries[0].Release = "prod/web"
+	r, err = New(FormatText, Options{GroupBy: GroupByRelease})
+	require.NoError(t, err)
+	b.Reset()
+	require.NoError(t, r.Render(&b, report))
+	assert.Contains(t, b.String(), "prod/web")
+	assert.Contains(t, b.String(), "(no release)")
+}
+
+func TestJSON(t *testing.T) {
+	var decoded types.DriftReport
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatJSON, testReport())), &decoded))
+	assert.Equal(t, testReport().Entries[0].Resource.Name, decoded.Entries[0].Resource.Name)
+}
+
+func TestYAML(t *testing.T) {
+	var decoded types.DriftReport
+	require.NoError(t, yaml.Unmarshal([]byte(render(t, FormatYAML, testReport())), &decoded))
+	assert.Equal(t, testReport().Summary, decoded.Summary)
+	assert.Equal(t, ".spec.replicas", decoded.Entries[0].FieldDiffs[0].Path)
+}
+
+func TestMarkdown(t *testing.T) {
+	out := render(t, FormatMarkdown, testReport())
+	assert.Contains(t, out, "# Drift from 01234567 to live\n")
+	assert.Contains(t, out, "| 1 | 0 | 2 | 0 | 0 | 0 | 7 |")
+	assert.Contains(t, out, "### MODIFIED Secret/prod/db\n\nSeverity: critical · Team: payments\n\n- `.data.password`: `[REDACTED]` → `[REDACTED]`")
+	assert.Contains(t, out, "- `.spec.replicas`: `2` → `3`")
+	assert.Less(t, bytes.Index([]byte(out), []byte("Secret/prod/db")), bytes.Index([]byte(out), []byte("Deployment/prod/web")), "most severe first")
+	assert.NotContains(t, out, "aHVudGVyMg==")
+
+	assert.Contains(t, render(t, FormatMarkdown, &types.DriftReport{}), "No drift detected.")
+
+	out = render(t, FormatMarkdown, &types.DriftReport{PolicyViolations: []types.PolicyViolation{violation}})
+	assert.Contains(t, out, "## Policy violations\n\n| Severity | Resource | Rule | Violation |\n|---|---|---|---|\n"+
+		"| high | Deployment/prod/web | image-latest | container app runs nginx, which is not pinned to a tag or digest |\n")
+
+	scoped := testReport()
+	scoped.Scope = &types.ScopeNote{Target: &types.SnapshotScope{Namespaces: []string{"prod"}}, OutOfScope: map[string]int{"ConfigMap": 4}}
+	assert.Contains(t, render(t, FormatMarkdown, scoped), "\n> Compared only namespaces prod; 4 resources out of scope were not compared (4 ConfigMap).\n")
+}
+
+func TestHTML(t *testing.T) {
+	out := render(t, FormatHTML, testReport())
+	assert.Contains(t, out, "<title>Drift from 01234567 to live</title>")
+	assert.Contains(t, out, "<li><code>.data.password</code>: [REDACTED] → [REDACTED]</li>")
+	assert.NotContains(t, out, "aHVudGVyMg==")
+}
+
+func TestSARIF(t *testing.T) {
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatSARIF, testReport())), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, len(sarifRules)+len(sarifPolicyRules))
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 3)
+	assert.Equal(t, "drift/modified", results[0].RuleID)
+	assert.Equal(t, "error", results[0].Level)
+	assert.Equal(t, "Secret/prod/db modified (team payments): .data.password", results[0].Message.Text)
+	assert.Equal(t, "prod/secret/db.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, "note", results[1].Level)
+	assert.Equal(t, "warning", results[2].Level, "unrated")
+	assert.Equal(t, "_cluster/clusterrole/reader.yaml", results[2].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+
+	report := testReport()
+	report.PolicyViolations = []types.PolicyViolation{violation}
+	require.NoError(t, json.Unmarshal([]byte(render(t, FormatSARIF, report)), &log))
+	results = log.Runs[0].Results
+	require.Len(t, results, 4)
+	assert.Equal(t, "policy/image-latest", results[3].RuleID)
+	assert.Equal(t, "error", results[3].Level)
+	assert.Equal(t, "Deployment/prod/web: container app runs nginx, which is not pinned to a tag or digest", results[3].Message.Text)
+	assert.Equal(t, "prod/deployment/web.yaml", results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestJUnit(t *testing.T) {
+	var suites junitSuites
+	require.NoError(t, xml.Unmarshal([]byte(render(t, FormatJUnit, testReport())), &suites))
+	assert.Equal(t, 3, suites.Tests)
+	assert.Equal(t, 3, suites.Failures)
+	require.Len(t, suites.Suites, 1)
+	c := suites.Suites[0].Cases[0]
+	assert.Equal(t, "prod.Secret", c.ClassName)
+	assert.Equal(t, "db", c.Name)
+	require.NotNil(t, c.Failure)
+	assert.Equal(t, "MODIFIED (critical)", c.Failure.Message)
+	assert.Equal(t, "team: payments\n.data.password: [REDACTED] → [REDACTED]", c.Failure.Text)
+
+	suppressed := testReport()
+	suppressed.SuppressedBy = "release"
+	require.NoError(t, xml.Unmarshal([]byte(render(t, FormatJUnit, suppressed)), &suites))
+	assert.Equal(t, 0, suites.Failures)
+	assert.Equal(t, 3, suites.Skipped)
+
+	require.NoError(t, xml.Unmarshal([]byte(render(t, FormatJUnit, &types.DriftReport{})), &suites))
+	assert.Equal(t, 1, suites.Tests)
+	assert.Equal(t, 0, suites.Failures)
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// sarifSchema and sarifVersion identify the SARIF version written.
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// sarifRules describes each drift type as a SARIF rule.
+var sarifRules = []struct {
+	Type        types.DriftType
+	Name        string
+	Description string
+}{
+	{types.DriftAdded, "ResourceAdded", "A resource was added."},
+	{types.DriftRemoved, "ResourceRemoved", "A resource was removed."},
+	{types.DriftModified, "ResourceModified", "A resource was modified."},
+	{types.DriftRecreated, "ResourceRecreated", "A resource was deleted and created again."},
+	{types.DriftScaled, "ResourceScaled", "A resource's replica count changed."},
+	{types.DriftOwnership, "OwnershipChanged", "The field managers owning a resource changed."},
+}
+
+// sarifPolicyRules describes the policy rules a report's violations may
+// break.
+var sarifPolicyRules = []struct {
+	ID          string
+	Name        string
+	Description string
+}{
+	{policy.RuleImageRegistry, "ImageRegistry", "A container image is pulled from a registry the image policy does not allow."},
+	{policy.RuleImageLatest, "ImageLatest", "A container image is not pinned to a tag or digest."},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// SARIF renders a report as a SARIF 2.1.0 log, for code scanning dashboards
+// such as GitHub's: each change is a result of the rule for its drift type,
+// and each policy violation of its policy rule, located at the resource's
+// file in the snapshot repository. Results name
+// the changed fields but not their values.
+type SARIF struct{}
+
+// Render implements Renderer.
+func (SARIF) Render(w io.Writer, report *types.DriftReport) error {
+	driver := sarifDriver{
+		Name:           "gitops-time-machine",
+		InformationURI: "https://github.com/raghu-007/GitOps-Time-Machine",
+	}
+	for _, r := range sarifRules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               sarifRuleID(r.Type),
+			Name:             r.Name,
+			ShortDescription: sarifMessage{Text: r.Description},
+		})
+	}
+	for _, r := range sarifPolicyRules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               "policy/" + r.ID,
+			Name:             r.Name,
+			ShortDescription: sarifMessage{Text: r.Description},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(report.Entries))
+	for _, e := range sortedEntries(report) {
+		res := e.Resource
+		results = append(results, sarifResult{
+			RuleID:    sarifRuleID(e.Type),
+			Level:     sarifLevel(e.Severity),
+			Message:   sarifMessage{Text: sarifText(e)},
+			Locations: sarifLocations(res),
+		})
+	}
+	for _, v := range report.PolicyViolations {
+		res := types.Resource{Kind: v.Resource.Kind, Namespace: v.Resource.Namespace, Name: v.Resource.Name}
+		results = append(results, sarifResult{
+			RuleID:    "policy/" + v.Rule,
+			Level:     sarifLevel(v.Severity),
+			Message:   sarifMessage{Text: res.DisplayName() + ": " + v.Message},
+			Locations: sarifLocations(res),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// sarifLocations locates a resource at its file in the snapshot repository.
+func sarifLocations(res types.Resource) []sarifLocation {
+	return []sarifLocation{{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: snapshotter.ResourcePath(res.Namespace, res.Kind, res.Name)},
+		},
+		LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: res.FullName(), Kind: "resource"}},
+	}}
+}
+
+// sarifRuleID returns the rule ID of a drift type, e.g. drift/modified.
+func sarifRuleID(t types.DriftType) string {
+	return "drift/" + strings.ToLower(string(t))
+}
+
+// sarifLevel maps a severity to a SARIF level; unrated changes are
+// warnings.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "low":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// sarifText describes a change without field values, which may be secret.
+func sarifText(e types.DriftEntry) string {
+	text := fmt.Sprintf("%s %s", e.Resource.DisplayName(), strings.ToLower(string(e.Type)))
+	if e.Team != "" {
+		text += " (team " + e.Team + ")"
+	}
+	if len(e.FieldDiffs) > 0 {
+		paths := make([]string, len(e.FieldDiffs))
+		for i, d := range e.FieldDiffs {
+			paths[i] = d.Path
+		}
+		text += ": " + strings.Join(paths, ", ")
+	}
+	for _, summary := range e.Summaries {
+		text += ". " + summary
+	}
+	return text
+}
@@ -0,0 +1,320 @@
+// Package report builds the periodic drift and trend report from recent
+// snapshot history, rendered as Markdown or HTML.
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/textfmt"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/policy"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Formats of a rendered report.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// maxEntries caps the drift entries listed in a report; the summary still
+// counts all of them.
+const maxEntries = 50
+
+// Validate checks the report configuration.
+func Validate(cfg *config.ReportConfig) error {
+	if cfg.Format != FormatMarkdown && cfg.Format != FormatHTML {
+		return fmt.Errorf("report.format must be markdown or html, got %q", cfg.Format)
+	}
+	if cfg.Period <= 0 {
+		return fmt.Errorf("report.period must be positive")
+	}
+	return nil
+}
+
+// Report summarizes how a cluster changed over a period.
+type Report struct {
+	Cluster string
+	From    time.Time
+	To      time.Time
+	// Snapshots is the number of snapshots taken in the period.
+	Snapshots int
+	Days      []Day
+	Kinds     []KindTrend
+	// Drift compares the snapshot at the start of the period with the
+	// latest; nil if the period has fewer than two snapshots to compare.
+	Drift *types.DriftReport
+}
+
+// Day is the snapshot activity of one calendar day (UTC).
+type Day struct {
+	Date      time.Time
+	Snapshots int
+	// Resources is the resource count of the day's last snapshot.
+	Resources int
+}
+
+// KindTrend is the number of resources of a kind at the start and end of
+// the period.
+type KindTrend struct {
+	Kind  string
+	Start int
+	End   int
+}
+
+// Delta is the change in the kind's count over the period.
+func (k KindTrend) Delta() int {
+	return k.End - k.Start
+}
+
+// Build summarizes the snapshot history (newest first, as returned by the
+// versioner) between from and to. The snapshot before from, if any, is the
+// start of the kind trend; drift may be nil.
+func Build(history []types.HistoryEntry, from, to time.Time, drift *types.DriftReport) *Report {
+	r := &Report{From: from, To: to, Drift: drift}
+
+	var start, end *types.HistoryEntry
+	days := make(map[time.Time]*Day)
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := &history[i]
+		if !entry.Timestamp.Before(to) {
+			break
+		}
+		if entry.Timestamp.Before(from) {
+			start = entry
+			continue
+		}
+		if start == nil {
+			start = entry
+		}
+		end = entry
+		r.Snapshots++
+
+		date := entry.Timestamp.UTC().Truncate(24 * time.Hour)
+		day, ok := days[date]
+		if !ok {
+			day = &Day{Date: date}
+			days[date] = day
+		}
+		day.Snapshots++
+		day.Resources = entry.ResourceCount
+	}
+	for _, day := range days {
+		r.Days = append(r.Days, *day)
+	}
+	sort.Slice(r.Days, func(i, j int) bool { return r.Days[i].Date.Before(r.Days[j].Date) })
+
+	if end == nil {
+		return r
+	}
+	r.Cluster = end.ClusterName
+	kinds := make(map[string]*KindTrend)
+	trend := func(kind string) *KindTrend {
+		if _, ok := kinds[kind]; !ok {
+			kinds[kind] = &KindTrend{Kind: kind}
+		}
+		return kinds[kind]
+	}
+	for kind, n := range start.KindCounts {
+		trend(kind).Start = n
+	}
+	for kind, n := range end.KindCounts {
+		trend(kind).End = n
+	}
+	for _, k := range kinds {
+		r.Kinds = append(r.Kinds, *k)
+	}
+	// Biggest changes first, then by name
+	sort.Slice(r.Kinds, func(i, j int) bool {
+		di, dj := abs(r.Kinds[i].Delta()), abs(r.Kinds[j].Delta())
+		if di != dj {
+			return di > dj
+		}
+		return r.Kinds[i].Kind < r.Kinds[j].Kind
+	})
+	return r
+}
+
+// Title is the report's headline, also used as the notification subject.
+func (r *Report) Title() string {
+	title := fmt.Sprintf("Drift report %s to %s", r.From.UTC().Format("2006-01-02"), r.To.UTC().Format("2006-01-02"))
+	if r.Cluster != "" {
+		title += " (" + r.Cluster + ")"
+	}
+	return title
+}
+
+// Headline is a one-line summary of the report, for notification bodies.
+func (r *Report) Headline() string {
+	if r.Drift == nil {
+		return fmt.Sprintf("%d snapshots; not enough history to compare.", r.Snapshots)
+	}
+	s := r.Drift.Summary
+	return fmt.Sprintf("%d snapshots; %d of %d resources changed (%d added, %d removed).",
+		r.Snapshots, len(r.Drift.Entries), s.TotalResources, s.AddedResources, s.RemovedResources)
+}
+
+// Render renders the report in the given format.
+func Render(r *Report, format string) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(r), nil
+	case FormatHTML:
+		return HTML(r)
+	default:
+		return nil, fmt.Errorf("unsupported report format %q (use markdown or html)", format)
+	}
+}
+
+// Markdown renders the report as GitHub-flavored Markdown.
+func Markdown(r *Report) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n%s\n", r.Title(), r.Headline())
+
+	if len(r.Days) > 0 {
+		b.WriteString("\n## Snapshots per day\n\n| Date | Snapshots | Resources |\n|---|---:|---:|\n")
+		for _, day := range r.Days {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", day.Date.Format("2006-01-02"), day.Snapshots, day.Resources)
+		}
+	}
+
+	if len(r.Kinds) > 0 {
+		b.WriteString("\n## Resources by kind\n\n| Kind | Start | End | Change |\n|---|---:|---:|---:|\n")
+		for _, k := range r.Kinds {
+			fmt.Fprintf(&b, "| %s | %d | %d | %+d |\n", k.Kind, k.Start, k.End, k.Delta())
+		}
+	}
+
+	if r.Drift != nil && len(r.Drift.Entries) > 0 {
+		b.WriteString("\n## Changes\n\n| Change | Severity | Resource | Team |\n|---|---|---|---|\n")
+		for _, e := range entries(r.Drift) {
+			name := textfmt.EscapeMarkdown(e.Resource.DisplayName())
+			if e.URL != "" {
+				name = "[" + name + "](" + e.URL + ")"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", e.Type, e.Severity, name, textfmt.EscapeMarkdown(e.Team))
+		}
+		if more := len(r.Drift.Entries) - maxEntries; more > 0 {
+			fmt.Fprintf(&b, "\n…and %d more.\n", more)
+		}
+	}
+
+	if r.Drift != nil && len(r.Drift.Anomalies) > 0 {
+		b.WriteString("\n## Anomalies\n\n")
+		for _, a := range r.Drift.Anomalies {
+			fmt.Fprintf(&b, "- %s\n", textfmt.EscapeMarkdown(anomaly(a)))
+		}
+	}
+	return b.Bytes()
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"anomaly":  anomaly,
+	"date":     func(t time.Time) string { return t.Format("2006-01-02") },
+	"resource": types.Resource.DisplayName,
+	"signed":   func(n int) string { return fmt.Sprintf("%+d", n) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Report.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Report.Title}}</h1>
+<p>{{.Report.Headline}}</p>
+{{- with .Report.Days}}
+<h2>Snapshots per day</h2>
+<table>
+<tr><th>Date</th><th>Snapshots</th><th>Resources</th></tr>
+{{- range .}}
+<tr><td>{{date .Date}}</td><td class="n">{{.Snapshots}}</td><td class="n">{{.Resources}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Report.Kinds}}
+<h2>Resources by kind</h2>
+<table>
+<tr><th>Kind</th><th>Start</th><th>End</th><th>Change</th></tr>
+{{- range .}}
+<tr><td>{{.Kind}}</td><td class="n">{{.Start}}</td><td class="n">{{.End}}</td><td class="n">{{signed .Delta}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Entries}}
+<h2>Changes</h2>
+<table>
+<tr><th>Change</th><th>Severity</th><th>Resource</th><th>Team</th></tr>
+{{- range .}}
+<tr><td>{{.Type}}</td><td>{{.Severity}}</td><td>{{if .URL}}<a href="{{.URL}}">{{resource .Resource}}</a>{{else}}{{resource .Resource}}{{end}}</td><td>{{.Team}}</td></tr>
+{{- end}}
+</table>
+{{- if $.More}}
+<p>…and {{$.More}} more.</p>
+{{- end}}
+{{- end}}
+{{- with .Anomalies}}
+<h2>Anomalies</h2>
+<ul>
+{{- range .}}
+<li>{{anomaly .}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML page.
+func HTML(r *Report) ([]byte, error) {
+	data := struct {
+		Report    *Report
+		Entries   []types.DriftEntry
+		More      int
+		Anomalies []types.Anomaly
+	}{Report: r}
+	if r.Drift != nil {
+		data.Entries = entries(r.Drift)
+		data.More = max(0, len(r.Drift.Entries)-maxEntries)
+		data.Anomalies = r.Drift.Anomalies
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// entries returns the drift entries to list, most severe first.
+func entries(drift *types.DriftReport) []types.DriftEntry {
+	list := append([]types.DriftEntry(nil), drift.Entries...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return policy.Rank(list[i].Severity) > policy.Rank(list[j].Severity)
+	})
+	if len(list) > maxEntries {
+		list = list[:maxEntries]
+	}
+	return list
+}
+
+// anomaly formats an anomaly as the printer does, without color.
+func anomaly(a types.Anomaly) string {
+	return fmt.Sprintf("%s: %d changes (score %.1f, usually %.1f ± %.1f)", a.Series, a.Changes, a.Score, a.Mean, a.StdDev)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
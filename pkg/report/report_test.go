@@ -0,0 +1,106 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(d, hour int) time.Time {
+	return time.Date(2024, 6, d, hour, 0, 0, 0, time.UTC)
+}
+
+// testHistory is newest first, as the versioner returns it.
+func testHistory() []types.HistoryEntry {
+	return []types.HistoryEntry{
+		{CommitHash: "e", Timestamp: day(9, 1), ResourceCount: 99},
+		{CommitHash: "d", Timestamp: day(4, 18), ResourceCount: 12, ClusterName: "prod", KindCounts: map[string]int{"Deployment": 5, "Service": 4, "ConfigMap": 3}},
+		{CommitHash: "c", Timestamp: day(4, 9), ResourceCount: 11},
+		{CommitHash: "b", Timestamp: day(3, 9), ResourceCount: 10},
+		{CommitHash: "a", Timestamp: day(1, 9), ResourceCount: 9, KindCounts: map[string]int{"Deployment": 2, "Service": 4, "Secret": 3}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.ReportConfig{Format: "html", Period: time.Hour}))
+	assert.Error(t, Validate(&config.ReportConfig{Format: "pdf", Period: time.Hour}))
+	assert.Error(t, Validate(&config.ReportConfig{Format: "markdown"}))
+}
+
+func TestBuild(t *testing.T) {
+	r := Build(testHistory(), day(2, 0), day(8, 0), nil)
+
+	assert.Equal(t, 3, r.Snapshots)
+	assert.Equal(t, "prod", r.Cluster)
+	require.Len(t, r.Days, 2)
+	assert.Equal(t, Day{Date: day(3, 0), Snapshots: 1, Resources: 10}, r.Days[0])
+	assert.Equal(t, Day{Date: day(4, 0), Snapshots: 2, Resources: 12}, r.Days[1])
+
+	// The trend starts from the snapshot before the period
+	assert.Equal(t, []KindTrend{
+		{Kind: "ConfigMap", Start: 0, End: 3},
+		{Kind: "Deployment", Start: 2, End: 5},
+		{Kind: "Secret", Start: 3, End: 0},
+		{Kind: "Service", Start: 4, End: 4},
+	}, r.Kinds)
+	assert.Equal(t, "Drift report 2024-06-02 to 2024-06-08 (prod)", r.Title())
+	assert.Equal(t, "3 snapshots; not enough history to compare.", r.Headline())
+}
+
+func TestBuildEmptyPeriod(t *testing.T) {
+	r := Build(testHistory(), day(5, 0), day(8, 0), nil)
+	assert.Zero(t, r.Snapshots)
+	assert.Empty(t, r.Days)
+	assert.Empty(t, r.Kinds)
+}
+
+func TestRender(t *testing.T) {
+	drift := &types.DriftReport{
+		Summary: types.DriftSummary{TotalResources: 12, AddedResources: 1, ModifiedResources: 1},
+		Entries: []types.DriftEntry{
+			{Type: types.DriftAdded, Severity: "low", Resource: types.Resource{Kind: "ConfigMap", Namespace: "prod", Name: "a|b"}},
+			{Type: types.DriftModified, Severity: "critical", Resource: types.Resource{Kind: "Deployment", Namespace: "prod", Name: "<web>"},
+				URL: "https://github.com/acme/snaps/blob/abc/prod/deployment/web.yaml"},
+		},
+		Anomalies: []types.Anomaly{{Series: "kind/Deployment", Changes: 9, Mean: 1, StdDev: 0.5, Score: 16}},
+	}
+	r := Build(testHistory(), day(2, 0), day(8, 0), drift)
+
+	md, err := Render(r, FormatMarkdown)
+	require.NoError(t, err)
+	out := string(md)
+	assert.Contains(t, out, "# Drift report 2024-06-02 to 2024-06-08 (prod)\n\n3 snapshots; 2 of 12 resources changed (1 added, 0 removed).\n")
+	assert.Contains(t, out, "| Deployment | 2 | 5 | +3 |")
+	assert.Contains(t, out, `| ADDED | low | ConfigMap/prod/a\|b |  |`)
+	assert.Contains(t, out, "- kind/Deployment: 9 changes (score 16.0, usually 1.0 ± 0.5)")
+	// The most severe change is listed first
+	assert.Less(t, strings.Index(out, "MODIFIED"), strings.Index(out, "ADDED"))
+
+	page, err := Render(r, FormatHTML)
+	require.NoError(t, err)
+	html := string(page)
+	assert.Contains(t, html, "<title>Drift report 2024-06-02 to 2024-06-08 (prod)</title>")
+	assert.Contains(t, html, `<a href="https://github.com/acme/snaps/blob/abc/prod/deployment/web.yaml">Deployment/prod/&lt;web&gt;</a>`)
+	assert.Contains(t, html, `<td class="n">&#43;3</td>`)
+
+	_, err = Render(r, "pdf")
+	assert.Error(t, err)
+}
+
+func TestRenderTruncatesEntries(t *testing.T) {
+	drift := &types.DriftReport{}
+	for i := 0; i < maxEntries+5; i++ {
+		drift.Entries = append(drift.Entries, types.DriftEntry{Type: types.DriftAdded, Resource: types.Resource{Kind: "ConfigMap", Name: "cm"}})
+	}
+	r := Build(testHistory(), day(2, 0), day(8, 0), drift)
+
+	assert.Contains(t, string(Markdown(r)), "…and 5 more.")
+	page, err := HTML(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "…and 5 more.")
+}
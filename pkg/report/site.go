@@ -0,0 +1,398 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Site is the snapshot history published as a static website by
+// WriteSite: a timeline of snapshots, a page per snapshot with its
+// changes, and a page per resource with its history.
+type Site struct {
+	Title     string
+	Generated time.Time
+	// Snapshots are ordered newest first.
+	Snapshots []SiteSnapshot
+	// Latest is the state of the newest snapshot, shown on the resource
+	// pages; nil if there are no snapshots.
+	Latest *types.ResourceSnapshot
+}
+
+// SiteSnapshot is one snapshot on the timeline.
+type SiteSnapshot struct {
+	Entry types.HistoryEntry
+	// Drift is the change from the previous snapshot; nil for the oldest.
+	Drift *types.DriftReport
+}
+
+// sitePage is a resource's page: its latest manifest and every change to
+// it, newest first.
+type sitePage struct {
+	Resource types.Resource
+	File     string
+	// Manifest is the resource in the latest snapshot, with secrets
+	// masked; empty if it has since been removed.
+	Manifest string
+	Changes  []siteChange
+}
+
+// siteChange is a change to a resource in one snapshot.
+type siteChange struct {
+	Snapshot types.HistoryEntry
+	Entry    types.DriftEntry
+}
+
+// unsafeFileChars are replaced in page file names, which have to be valid
+// on any file system and object store.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// WriteSite renders a site into dir as plain HTML files with relative
+// links, ready to be served from any static host. Values of Secrets and of
+// secret-looking fields are masked as in notifications. Existing files in
+// dir are overwritten but not removed.
+func WriteSite(dir string, s *Site) error {
+	for _, sub := range []string{"snapshots", "resources"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return fmt.Errorf("failed to create site directory: %w", err)
+		}
+	}
+
+	snapshots := make([]SiteSnapshot, len(s.Snapshots))
+	for i, snap := range s.Snapshots {
+		snapshots[i] = SiteSnapshot{Entry: snap.Entry, Drift: maskDrift(snap.Drift)}
+	}
+	pages := sitePages(snapshots, s.Latest)
+	files := make(map[string]string, len(pages))
+	for _, page := range pages {
+		files[page.Resource.FullName()] = page.File
+	}
+	funcs := template.FuncMap{
+		"page": func(res types.Resource) string { return files[res.FullName()] },
+	}
+	tmpl, err := siteTemplate.Clone()
+	if err != nil {
+		return fmt.Errorf("failed to render site: %w", err)
+	}
+	tmpl.Funcs(funcs)
+
+	write := func(name, page string, data interface{}) error {
+		f, err := os.Create(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		defer f.Close()
+		if err := tmpl.ExecuteTemplate(f, page, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		return f.Close()
+	}
+	type pageData struct {
+		Site *Site
+		// Root is the relative path from the page to the site's root.
+		Root      string
+		Snapshots []SiteSnapshot
+		Snapshot  SiteSnapshot
+		Pages     []*sitePage
+		Page      *sitePage
+	}
+
+	if err := write("index.html", "index", pageData{Site: s, Snapshots: snapshots}); err != nil {
+		return err
+	}
+	if err := write("resources.html", "resources", pageData{Site: s, Pages: pages}); err != nil {
+		return err
+	}
+	for _, snap := range snapshots {
+		name := "snapshots/" + snap.Entry.CommitHash + ".html"
+		if err := write(name, "snapshot", pageData{Site: s, Root: "../", Snapshot: snap}); err != nil {
+			return err
+		}
+	}
+	for _, page := range pages {
+		if err := write(page.File, "resource", pageData{Site: s, Root: "../", Page: page}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sitePages collects the resource pages: one for every resource of the
+// latest snapshot or changed in any snapshot, sorted by name.
+func sitePages(snapshots []SiteSnapshot, latest *types.ResourceSnapshot) []*sitePage {
+	byName := make(map[string]*sitePage)
+	get := func(res types.Resource) *sitePage {
+		page, ok := byName[res.FullName()]
+		if !ok {
+			page = &sitePage{Resource: res}
+			byName[res.FullName()] = page
+		}
+		return page
+	}
+	if latest != nil {
+		for _, res := range latest.Resources {
+			page := get(res)
+			page.Resource = res
+			page.Manifest = manifest(res)
+		}
+	}
+	for _, snap := range snapshots {
+		if snap.Drift == nil {
+			continue
+		}
+		for _, e := range snap.Drift.Entries {
+			page := get(e.Resource)
+			page.Changes = append(page.Changes, siteChange{Snapshot: snap.Entry, Entry: e})
+		}
+	}
+
+	pages := make([]*sitePage, 0, len(byName))
+	for _, page := range byName {
+		pages = append(pages, page)
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].Resource.FullName() < pages[j].Resource.FullName()
+	})
+	used := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		res := page.Resource
+		ns := res.Namespace
+		if ns == "" {
+			ns = "_cluster"
+		}
+		base := unsafeFileChars.ReplaceAllString(ns+"_"+res.Kind+"_"+res.Name, "-")
+		file := "resources/" + base + ".html"
+		// Names that only differ in unsafe characters get numbered pages
+		for n := 2; used[file]; n++ {
+			file = fmt.Sprintf("resources/%s-%d.html", base, n)
+		}
+		used[file] = true
+		page.File = file
+	}
+	return pages
+}
+
+// maskDrift returns a copy of a drift report with the secret values in its
+// field diffs masked.
+func maskDrift(drift *types.DriftReport) *types.DriftReport {
+	if drift == nil {
+		return nil
+	}
+	masked := *drift
+	masked.Entries = make([]types.DriftEntry, len(drift.Entries))
+	for i, e := range drift.Entries {
+		diffs := make([]types.FieldDiff, len(e.FieldDiffs))
+		for j, d := range e.FieldDiffs {
+			diffs[j] = notifier.MaskFieldDiff(e.Resource, d)
+		}
+		e.FieldDiffs = diffs
+		masked.Entries[i] = e
+	}
+	return &masked
+}
+
+// manifest renders a resource as YAML with its secrets masked. Resources
+// without a raw object are rendered in the flat layout they are stored in.
+func manifest(res types.Resource) string {
+	if res.Raw == nil {
+		data, err := yaml.Marshal(res)
+		if err != nil || yaml.Unmarshal(data, &res.Raw) != nil {
+			return ""
+		}
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(notifier.MaskResource(res)); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// siteValue renders a field diff value in full.
+func siteValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// changeCounts summarizes a drift report as e.g. "2 added, 1 modified".
+func changeCounts(drift *types.DriftReport) string {
+	if drift == nil {
+		return "first snapshot"
+	}
+	counts := make(map[types.DriftType]int)
+	for _, e := range drift.Entries {
+		counts[e.Type]++
+	}
+	var parts []string
+	for _, t := range []types.DriftType{types.DriftAdded, types.DriftModified, types.DriftRemoved, types.DriftRecreated, types.DriftScaled, types.DriftOwnership} {
+		if n := counts[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, strings.ToLower(string(t))))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+var siteTemplate = template.Must(template.New("site").Funcs(template.FuncMap{
+	"counts":   changeCounts,
+	"page":     func(types.Resource) string { return "" },
+	"resource": types.Resource.DisplayName,
+	"short":    func(hash string) string { return hash[:min(len(hash), 8)] },
+	"time":     func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 UTC") },
+	"value":    siteValue,
+}).Parse(`
+{{- define "head" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+td.n { text-align: right; }
+ul.diffs { margin: 0; padding-left: 1.2em; }
+pre { background: #f6f6f6; padding: 1em; overflow-x: auto; }
+nav a { margin-right: 1em; }
+</style>
+</head>
+<body>
+{{- end}}
+
+{{- define "nav"}}
+<nav><a href="{{.Root}}index.html">Timeline</a><a href="{{.Root}}resources.html">Resources</a></nav>
+{{- end}}
+
+{{- define "foot"}}
+<p><small>Generated {{time .Site.Generated}} by gitops-time-machine.</small></p>
+</body>
+</html>
+{{end}}
+
+{{- define "diffs"}}
+{{- if or .Summaries .FieldDiffs}}
+<ul class="diffs">
+{{- range .Summaries}}
+<li>{{.}}</li>
+{{- end}}
+{{- range .FieldDiffs}}
+<li><code>{{.Path}}</code>: {{value .OldValue}} → {{value .NewValue}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+
+{{- define "index"}}
+{{- template "head" .Site}}
+{{- template "nav" .}}
+<h1>{{.Site.Title}}</h1>
+{{- if .Snapshots}}
+<table>
+<tr><th>Time</th><th>Snapshot</th><th>Cluster</th><th>Resources</th><th>Changes</th></tr>
+{{- range .Snapshots}}
+<tr><td>{{time .Entry.Timestamp}}</td><td><a href="snapshots/{{.Entry.CommitHash}}.html">{{short .Entry.CommitHash}}</a></td><td>{{.Entry.ClusterName}}</td><td class="n">{{.Entry.ResourceCount}}</td><td>{{counts .Drift}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No snapshots yet.</p>
+{{- end}}
+{{- template "foot" .}}
+{{- end}}
+
+{{- define "resources"}}
+{{- template "head" .Site}}
+{{- template "nav" .}}
+<h1>Resources</h1>
+<table>
+<tr><th>Resource</th><th>Changes</th><th>State</th></tr>
+{{- range .Pages}}
+<tr><td><a href="{{.File}}">{{resource .Resource}}</a></td><td class="n">{{len .Changes}}</td><td>{{if .Manifest}}present{{else}}removed{{end}}</td></tr>
+{{- end}}
+</table>
+{{- template "foot" .}}
+{{- end}}
+
+{{- define "snapshot"}}
+{{- template "head" .Site}}
+{{- template "nav" .}}
+{{- $root := .Root}}
+{{- with .Snapshot}}
+<h1>Snapshot {{short .Entry.CommitHash}}</h1>
+<table>
+<tr><th>Time</th><td>{{time .Entry.Timestamp}}</td></tr>
+<tr><th>Commit</th><td><code>{{.Entry.CommitHash}}</code></td></tr>
+{{- with .Entry.ClusterName}}
+<tr><th>Cluster</th><td>{{.}}</td></tr>
+{{- end}}
+<tr><th>Resources</th><td>{{.Entry.ResourceCount}}</td></tr>
+<tr><th>Message</th><td>{{.Entry.Message}}</td></tr>
+</table>
+<h2>Changes</h2>
+{{- if and .Drift .Drift.Entries}}
+<table>
+<tr><th>Change</th><th>Severity</th><th>Resource</th><th>Team</th><th>Details</th></tr>
+{{- range .Drift.Entries}}
+<tr><td>{{.Type}}</td><td>{{.Severity}}</td><td><a href="{{$root}}{{page .Resource}}">{{resource .Resource}}</a>{{with .URL}} (<a href="{{.}}">source</a>){{end}}</td><td>{{.Team}}</td><td>{{template "diffs" .}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>{{counts .Drift}}</p>
+{{- end}}
+{{- end}}
+{{- template "foot" .}}
+{{- end}}
+
+{{- define "resource"}}
+{{- template "head" .Site}}
+{{- template "nav" .}}
+{{- $root := .Root}}
+{{- with .Page}}
+<h1>{{resource .Resource}}</h1>
+<h2>History</h2>
+{{- if .Changes}}
+<table>
+<tr><th>Time</th><th>Snapshot</th><th>Change</th><th>Severity</th><th>Details</th></tr>
+{{- range .Changes}}
+<tr><td>{{time .Snapshot.Timestamp}}</td><td><a href="{{$root}}snapshots/{{.Snapshot.CommitHash}}.html">{{short .Snapshot.CommitHash}}</a></td><td>{{.Entry.Type}}</td><td>{{.Entry.Severity}}</td><td>{{template "diffs" .Entry}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>Unchanged since the oldest snapshot shown.</p>
+{{- end}}
+<h2>Latest manifest</h2>
+{{- if .Manifest}}
+<pre>{{.Manifest}}</pre>
+{{- else}}
+<p>Not in the latest snapshot.</p>
+{{- end}}
+{{- end}}
+{{- template "foot" .}}
+{{- end}}
+`))
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSite() *Site {
+	web := types.Resource{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "prod", Name: "web", Raw: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+		"spec":     map[string]interface{}{"replicas": 3},
+	}}
+	secret := types.Resource{APIVersion: "v1", Kind: "Secret", Namespace: "prod", Name: "db", Raw: map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret",
+		"metadata": map[string]interface{}{"name": "db", "namespace": "prod"},
+		"data":     map[string]interface{}{"password": "c3dvcmRmaXNo"},
+	}}
+	role := types.Resource{Kind: "ClusterRole", Name: "system:reader"}
+	return &Site{
+		Title:     "Snapshot history of prod",
+		Generated: day(5, 0),
+		Snapshots: []SiteSnapshot{
+			{
+				Entry: types.HistoryEntry{CommitHash: "bbbbbbbbbbbb", Timestamp: day(4, 9), ResourceCount: 2},
+				Drift: &types.DriftReport{Entries: []types.DriftEntry{
+					{Type: types.DriftScaled, Severity: "low", Resource: web, FieldDiffs: []types.FieldDiff{{Path: ".spec.replicas", OldValue: 2, NewValue: 3}}},
+					{Type: types.DriftModified, Resource: secret, FieldDiffs: []types.FieldDiff{{Path: ".data.password", OldValue: "aHVudGVyMg==", NewValue: "c3dvcmRmaXNo"}}},
+					{Type: types.DriftRemoved, Resource: role},
+				}},
+			},
+			{Entry: types.HistoryEntry{CommitHash: "aaaaaaaaaaaa", Timestamp: day(3, 9), ResourceCount: 3}},
+		},
+		Latest: &types.ResourceSnapshot{Resources: []types.Resource{web, secret}},
+	}
+}
+
+func readSiteFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWriteSite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, WriteSite(dir, testSite()))
+
+	index := readSiteFile(t, dir, "index.html")
+	assert.Contains(t, index, "<h1>Snapshot history of prod</h1>")
+	assert.Contains(t, index, `<a href="snapshots/bbbbbbbbbbbb.html">bbbbbbbb</a>`)
+	assert.Contains(t, index, "1 modified, 1 removed, 1 scaled")
+	assert.Contains(t, index, "first snapshot")
+
+	snapshot := readSiteFile(t, dir, "snapshots/bbbbbbbbbbbb.html")
+	assert.Contains(t, snapshot, `<a href="../resources/prod_Deployment_web.html">Deployment/prod/web</a>`)
+	assert.Contains(t, snapshot, "<code>.spec.replicas</code>: 2 → 3")
+	assert.Contains(t, snapshot, "<code>.data.password</code>: [REDACTED] → [REDACTED]")
+	assert.NotContains(t, snapshot, "aHVudGVyMg==")
+	readSiteFile(t, dir, "snapshots/aaaaaaaaaaaa.html")
+
+	web := readSiteFile(t, dir, "resources/prod_Deployment_web.html")
+	assert.Contains(t, web, `<a href="../snapshots/bbbbbbbbbbbb.html">bbbbbbbb</a>`)
+	assert.Contains(t, web, "spec:\n  replicas: 3")
+
+	secret := readSiteFile(t, dir, "resources/prod_Secret_db.html")
+	assert.Contains(t, secret, "password: &#39;[REDACTED]&#39;")
+	assert.NotContains(t, secret, "c3dvcmRmaXNo")
+
+	role := readSiteFile(t, dir, "resources/_cluster_ClusterRole_system-reader.html")
+	assert.Contains(t, role, "Not in the latest snapshot.")
+	assert.Contains(t, readSiteFile(t, dir, "resources.html"), `<a href="resources/_cluster_ClusterRole_system-reader.html">ClusterRole/system:reader</a>`)
+}
+
+func TestSitePages_FileNames(t *testing.T) {
+	latest := &types.ResourceSnapshot{Resources: []types.Resource{
+		{Kind: "ClusterRole", Name: "a:b"},
+		{Kind: "ClusterRole", Name: "a/b"},
+	}}
+	pages := sitePages(nil, latest)
+	require.Len(t, pages, 2)
+	assert.Equal(t, "resources/_cluster_ClusterRole_a-b.html", pages[0].File)
+	assert.Equal(t, "resources/_cluster_ClusterRole_a-b-2.html", pages[1].File)
+}
@@ -0,0 +1,159 @@
+package restorer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/immutable"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// applyOrder ranks kinds that others depend on, so that applying a plan in
+// order creates namespaces, CRDs, and configuration before the workloads
+// that use them. Kinds not listed come last.
+var applyOrder = []string{
+	"Namespace", "CustomResourceDefinition", "PriorityClass", "StorageClass",
+	"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding",
+	"Secret", "ConfigMap", "PersistentVolume", "PersistentVolumeClaim",
+	"Service", "DaemonSet", "Deployment", "StatefulSet", "Job", "CronJob",
+	"Ingress", "NetworkPolicy", "HorizontalPodAutoscaler", "PodDisruptionBudget",
+}
+
+// Plan is an apply-ready bundle of a past snapshot's resources, for a
+// human to review and apply where the tool may not change the cluster.
+type Plan struct {
+	Commit    string
+	Manifests []Manifest
+	// Skipped are resources that cannot be restored from the snapshot,
+	// with the reason.
+	Skipped map[string]string
+}
+
+// Manifest is the apply body of one resource.
+type Manifest struct {
+	Resource types.Resource
+	Object   map[string]interface{}
+	// Warnings are immutable fields that were kept and may be rejected.
+	Warnings []immutable.Finding
+}
+
+// NewPlan prepares the resources of the snapshot at commit as they would
+// be restored, with server-owned fields and status removed, in apply order.
+func NewPlan(commit string, resources []types.Resource) *Plan {
+	plan := &Plan{Commit: commit, Skipped: map[string]string{}}
+	for _, res := range resources {
+		obj, findings := prepare(res)
+		if collector.HasRedactedEnv(obj) {
+			plan.Skipped[res.FullName()] = "env values were redacted in the snapshot (snapshot.redact_env)"
+			continue
+		}
+		plan.Manifests = append(plan.Manifests, Manifest{Resource: res, Object: obj, Warnings: immutable.Warnings(findings)})
+	}
+	sort.SliceStable(plan.Manifests, func(i, j int) bool {
+		a, b := plan.Manifests[i].Resource, plan.Manifests[j].Resource
+		if ra, rb := applyRank(a.Kind), applyRank(b.Kind); ra != rb {
+			return ra < rb
+		}
+		return a.FullName() < b.FullName()
+	})
+	return plan
+}
+
+// applyRank returns a kind's position in applyOrder.
+func applyRank(kind string) int {
+	for i, k := range applyOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(applyOrder)
+}
+
+// Bundle renders the plan as one multi-document YAML stream, each document
+// preceded by a comment naming the resource and any warnings.
+func (p *Plan) Bundle() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Rollback plan to snapshot %s: %d resources, in apply order.\n", p.Commit, len(p.Manifests))
+	for _, m := range p.Manifests {
+		doc, err := p.document(m)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(doc)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteDir writes one file per resource to dir, named so that their
+// lexical order is the apply order, as kubectl apply -f dir reads them.
+func (p *Plan) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	width := len(fmt.Sprint(len(p.Manifests)))
+	for i, m := range p.Manifests {
+		doc, err := p.document(m)
+		if err != nil {
+			return err
+		}
+		ns := m.Resource.Namespace
+		if ns == "" {
+			ns = types.ClusterScope
+		}
+		name := fmt.Sprintf("%0*d-%s-%s-%s.yaml", width, i+1, ns, strings.ToLower(m.Resource.Kind), fileSafe.Replace(m.Resource.Name))
+		if err := os.WriteFile(filepath.Join(dir, name), doc, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// document renders one manifest with its comment header.
+func (p *Plan) document(m Manifest) ([]byte, error) {
+	data, err := yaml.Marshal(m.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", m.Resource.FullName(), err)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", m.Resource.FullName())
+	for _, w := range m.Warnings {
+		fmt.Fprintf(&buf, "# WARNING: %s is %s\n", w.Rule.Path, w.Rule.Reason)
+	}
+	buf.Write(data)
+	return buf.Bytes(), nil
+}
+
+// Script returns a shell script that applies the plan at path (the bundle
+// file or directory, relative to the script) with server-side apply, as
+// restore would, after a server-side dry run.
+func (p *Plan) Script(path, kubeContext, fieldManager string) string {
+	kubectl := "kubectl"
+	if kubeContext != "" {
+		kubectl += " --context " + shellQuote(kubeContext)
+	}
+	apply := fmt.Sprintf("%s apply --server-side --field-manager %s -f %s", kubectl, shellQuote(fieldManager), shellQuote(path))
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	fmt.Fprintf(&b, "# Restores %d resources to snapshot %s. Review the plan before running.\n", len(p.Manifests), p.Commit)
+	b.WriteString("set -eu\n")
+	b.WriteString(`cd "$(dirname "$0")"` + "\n\n")
+	b.WriteString(apply + " --dry-run=server\n")
+	b.WriteString(apply + "\n")
+	return b.String()
+}
+
+// fileSafe replaces characters that resource names (e.g. system:node
+// ClusterRoles) may contain but file names should not.
+var fileSafe = strings.NewReplacer("/", "_", "\\", "_", ":", "_")
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,84 @@
+package restorer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planResources() []types.Resource {
+	redacted := deployment()
+	redacted.Name = "worker"
+	redacted.Raw = map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{
+				"name": "worker",
+				"env":  []interface{}{map[string]interface{}{"name": "TOKEN", "value": collector.RedactedValue}},
+			}},
+		}}},
+	}
+	return []types.Resource{
+		deployment(),
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "settings", Data: map[string]interface{}{"mode": "a"}},
+		{APIVersion: "v1", Kind: "Namespace", Name: "default"},
+		redacted,
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	plan := NewPlan("abc123", planResources())
+
+	require.Len(t, plan.Manifests, 3)
+	assert.Equal(t, "Namespace", plan.Manifests[0].Resource.Kind)
+	assert.Equal(t, "ConfigMap", plan.Manifests[1].Resource.Kind)
+	assert.Equal(t, "Deployment", plan.Manifests[2].Resource.Kind)
+	assert.Contains(t, plan.Skipped, "default/Deployment/worker")
+
+	web := plan.Manifests[2].Object
+	assert.NotContains(t, web, "status")
+	assert.NotContains(t, web["metadata"], "uid")
+	assert.NotContains(t, web["metadata"], "resourceVersion")
+}
+
+func TestPlan_Bundle(t *testing.T) {
+	bundle, err := NewPlan("abc123", planResources()).Bundle()
+	require.NoError(t, err)
+
+	out := string(bundle)
+	assert.Contains(t, out, "# Rollback plan to snapshot abc123: 3 resources")
+	assert.Contains(t, out, "---\n# default/ConfigMap/settings\n")
+	assert.Contains(t, out, "mode: a")
+	assert.NotContains(t, out, "worker")
+	assert.Less(t, strings.Index(out, "Namespace/default"), strings.Index(out, "Deployment/web"))
+}
+
+func TestPlan_WriteDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plan")
+	require.NoError(t, NewPlan("abc123", planResources()).WriteDir(dir))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{
+		"1-" + types.ClusterScope + "-namespace-default.yaml",
+		"2-default-configmap-settings.yaml",
+		"3-default-deployment-web.yaml",
+	}, names)
+}
+
+func TestPlan_Script(t *testing.T) {
+	script := NewPlan("abc123", planResources()).Script("rollback plan.yaml", "prod", DefaultFieldManager)
+
+	assert.Contains(t, script, "set -eu\n")
+	assert.Contains(t, script, "kubectl --context 'prod' apply --server-side --field-manager '"+DefaultFieldManager+"' -f 'rollback plan.yaml' --dry-run=server\n")
+	assert.Contains(t, script, "-f 'rollback plan.yaml'\n")
+}
@@ -0,0 +1,104 @@
+package restorer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Request describes a restore made through the API: the snapshot to
+// restore from, which of its resources, and how to apply them.
+type Request struct {
+	// Commit is a commit, branch, tag, or revision; At is an RFC3339 time.
+	// Exactly one is required.
+	Commit string `json:"commit,omitempty"`
+	At     string `json:"at,omitempty"`
+	// Namespace, Kind, and Name limit the restore to matching resources.
+	Namespace      string `json:"namespace,omitempty"`
+	Kind           string `json:"kind,omitempty"`
+	Name           string `json:"name,omitempty"`
+	DryRun         bool   `json:"dryRun,omitempty"`
+	ForceConflicts bool   `json:"forceConflicts,omitempty"`
+	SkipConflicts  bool   `json:"skipConflicts,omitempty"`
+	// NoVerify skips the verification snapshot after restoring.
+	NoVerify bool `json:"noVerify,omitempty"`
+}
+
+// Validate checks that the request names one snapshot and consistent options.
+func (r Request) Validate() error {
+	switch {
+	case r.Commit == "" && r.At == "":
+		return fmt.Errorf("commit or at is required")
+	case r.Commit != "" && r.At != "":
+		return fmt.Errorf("commit and at are mutually exclusive")
+	}
+	if r.At != "" {
+		if _, err := time.Parse(time.RFC3339, r.At); err != nil {
+			return fmt.Errorf("invalid at time (use RFC3339): %w", err)
+		}
+	}
+	return r.Options().Validate()
+}
+
+// Options returns the apply options of the request.
+func (r Request) Options() Options {
+	return Options{
+		FieldManager:   DefaultFieldManager,
+		ForceConflicts: r.ForceConflicts,
+		SkipConflicts:  r.SkipConflicts,
+		DryRun:         r.DryRun,
+	}
+}
+
+// Matches reports whether a resource is within the request's scope. Kind
+// is matched case-insensitively, and may also be the plural resource name
+// (e.g. deployments).
+func (r Request) Matches(res types.Resource) bool {
+	return (r.Namespace == "" || res.Namespace == r.Namespace) &&
+		(r.Kind == "" || kindMatches(res.Kind, r.Kind)) &&
+		(r.Name == "" || res.Name == r.Name)
+}
+
+// kindMatches reports whether kind is named by filter, as the kind or as
+// its plural resource name.
+func kindMatches(kind, filter string) bool {
+	kind, filter = strings.ToLower(kind), strings.ToLower(filter)
+	return filter == kind || filter == plural(kind)
+}
+
+// plural returns the resource name of a lower-case kind the way the API
+// server derives it, e.g. networkpolicies, ingresses.
+func plural(kind string) string {
+	switch {
+	case strings.HasSuffix(kind, "s") || strings.HasSuffix(kind, "x") ||
+		strings.HasSuffix(kind, "ch") || strings.HasSuffix(kind, "sh"):
+		return kind + "es"
+	case strings.HasSuffix(kind, "y") && !strings.HasSuffix(kind, "ey") && !strings.HasSuffix(kind, "ay"):
+		return strings.TrimSuffix(kind, "y") + "ies"
+	default:
+		return kind + "s"
+	}
+}
+
+// Report is the outcome of a restore.
+type Report struct {
+	// Commit is the snapshot restored from.
+	Commit  string   `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Results []Result `json:"results" yaml:"results"`
+	// Verified is set when a verification snapshot was compared with the target.
+	Verified    bool         `json:"verified" yaml:"verified"`
+	Unconverged []Divergence `json:"unconverged,omitempty" yaml:"unconverged,omitempty"`
+}
+
+// Count returns how many resources ended with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package restorer
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestValidate(t *testing.T) {
+	assert.NoError(t, Request{Commit: "HEAD~1"}.Validate())
+	assert.NoError(t, Request{At: "2024-01-15T10:00:00Z", DryRun: true}.Validate())
+
+	assert.Error(t, Request{}.Validate())
+	assert.Error(t, Request{Commit: "HEAD", At: "2024-01-15T10:00:00Z"}.Validate())
+	assert.Error(t, Request{At: "yesterday"}.Validate())
+	assert.Error(t, Request{Commit: "HEAD", ForceConflicts: true, SkipConflicts: true}.Validate())
+}
+
+func TestRequestMatches(t *testing.T) {
+	res := deployment()
+	assert.True(t, Request{}.Matches(res))
+	assert.True(t, Request{Namespace: "default", Kind: "Deployment", Name: "web"}.Matches(res))
+	assert.False(t, Request{Namespace: "prod"}.Matches(res))
+	assert.False(t, Request{Kind: "Service"}.Matches(res))
+	assert.True(t, Request{Kind: "deployments"}.Matches(res))
+	assert.True(t, Request{Kind: "deployment"}.Matches(res))
+	assert.True(t, Request{Kind: "networkpolicies"}.Matches(types.Resource{Kind: "NetworkPolicy"}))
+	assert.True(t, Request{Kind: "ingresses"}.Matches(types.Resource{Kind: "Ingress"}))
+	assert.True(t, Request{Kind: "endpoints"}.Matches(types.Resource{Kind: "Endpoints"}))
+}
+
+func TestReportCount(t *testing.T) {
+	report := &Report{Results: []Result{{Status: StatusApplied}, {Status: StatusFailed}, {Status: StatusApplied}}}
+	assert.Equal(t, 2, report.Count(StatusApplied))
+	assert.Equal(t, 0, report.Count(StatusSkipped))
+}
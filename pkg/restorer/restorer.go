@@ -0,0 +1,272 @@
+// Package restorer re-applies resources from a historical snapshot to the
+// live cluster using server-side apply.
+package restorer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/immutable"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	log "github.com/sirupsen/logrus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// DefaultFieldManager owns the fields set by restores, so they can be told
+// apart from fields managed by ArgoCD, Flux, or kubectl.
+const DefaultFieldManager = "gitops-time-machine"
+
+// Status is the outcome of restoring a single resource.
+type Status string
+
+const (
+	// StatusApplied means the resource was applied (or would be, in a dry run).
+	StatusApplied Status = "applied"
+	// StatusSkipped means the resource was left alone because another field
+	// manager owns fields the restore would change.
+	StatusSkipped Status = "skipped"
+	// StatusFailed means the API server rejected the resource.
+	StatusFailed Status = "failed"
+)
+
+// Options controls how resources are applied.
+type Options struct {
+	FieldManager string
+	// ForceConflicts takes ownership of fields managed by other field managers.
+	ForceConflicts bool
+	// SkipConflicts leaves resources with conflicting fields untouched
+	// instead of reporting them as failures.
+	SkipConflicts bool
+	// DryRun asks the API server to validate the apply without persisting it.
+	DryRun bool
+}
+
+// Validate checks that the options are consistent.
+func (o Options) Validate() error {
+	if o.ForceConflicts && o.SkipConflicts {
+		return fmt.Errorf("--force-conflicts and --skip-conflicts are mutually exclusive")
+	}
+	if o.FieldManager == "" {
+		return fmt.Errorf("field manager must not be empty")
+	}
+	return nil
+}
+
+// Result reports what happened to one resource.
+type Result struct {
+	Resource string `json:"resource" yaml:"resource"`
+	Status   Status `json:"status" yaml:"status"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Warnings are immutable fields that were kept and may be rejected.
+	Warnings []immutable.Finding `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Restorer applies snapshot resources to a cluster.
+type Restorer struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+	opts   Options
+}
+
+// New creates a Restorer for the configured cluster.
+func New(cfg *config.Config, opts Options) (*Restorer, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := collector.RESTConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dynClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	discoClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoClient))
+	return newRestorer(dynClient, mapper, opts), nil
+}
+
+// newRestorer creates a Restorer from explicit clients.
+func newRestorer(client dynamic.Interface, mapper meta.RESTMapper, opts Options) *Restorer {
+	return &Restorer{client: client, mapper: mapper, opts: opts}
+}
+
+// Restore applies each resource and reports the outcome per resource. It
+// stops early only if ctx is cancelled.
+func (r *Restorer) Restore(ctx context.Context, resources []types.Resource) ([]Result, error) {
+	results := make([]Result, 0, len(resources))
+	for _, res := range resources {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := r.restoreOne(ctx, res)
+
+		log.WithFields(log.Fields{
+			"resource": result.Resource,
+			"status":   result.Status,
+		}).Debug("restored resource")
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// restoreOne server-side applies a single resource.
+func (r *Restorer) restoreOne(ctx context.Context, res types.Resource) Result {
+	result := Result{Resource: res.FullName()}
+
+	obj, findings := prepare(res)
+	result.Warnings = immutable.Warnings(findings)
+	if collector.HasRedactedEnv(obj) {
+		return failed(result, "env values were redacted in the snapshot (snapshot.redact_env); restoring would overwrite them with "+collector.RedactedValue)
+	}
+
+	gv, err := schema.ParseGroupVersion(res.APIVersion)
+	if err != nil {
+		return failed(result, fmt.Sprintf("invalid apiVersion %q: %v", res.APIVersion, err))
+	}
+	mapping, err := r.mapper.RESTMapping(gv.WithKind(res.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return failed(result, fmt.Sprintf("unknown kind: %v", err))
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return failed(result, fmt.Sprintf("failed to encode resource: %v", err))
+	}
+
+	force := r.opts.ForceConflicts
+	patchOpts := metav1.PatchOptions{FieldManager: r.opts.FieldManager, Force: &force}
+	if r.opts.DryRun {
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	client := r.client.Resource(mapping.Resource)
+	var target dynamic.ResourceInterface = client
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		target = client.Namespace(res.Namespace)
+	}
+
+	_, err = target.Patch(ctx, res.Name, k8stypes.ApplyPatchType, data, patchOpts)
+	switch {
+	case err == nil:
+		result.Status = StatusApplied
+	case apierrors.IsConflict(err) && r.opts.SkipConflicts:
+		result.Status = StatusSkipped
+		result.Message = conflictMessage(err)
+	case apierrors.IsConflict(err):
+		return failed(result, conflictMessage(err)+" (use --force-conflicts to take ownership or --skip-conflicts to leave it)")
+	default:
+		return failed(result, err.Error())
+	}
+	return result
+}
+
+// prepare builds the apply body for a resource: server-owned fields and
+// status are removed so the restore only declares desired state.
+func prepare(res types.Resource) (map[string]interface{}, []immutable.Finding) {
+	if res.Raw != nil {
+		// Spec aliases Raw's spec; stripping Raw is enough.
+		res.Raw = deepCopy(res.Raw)
+		res.Spec = nil
+	} else {
+		res.Spec = deepCopy(res.Spec)
+	}
+	findings := immutable.Apply(&res)
+
+	obj := res.Raw
+	if obj == nil {
+		obj = map[string]interface{}{}
+		if res.Spec != nil {
+			obj["spec"] = res.Spec
+		}
+		if res.Data != nil {
+			obj["data"] = res.Data
+		}
+	}
+	delete(obj, "status")
+
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["name"] = res.Name
+	if res.Namespace != "" {
+		metadata["namespace"] = res.Namespace
+	}
+	obj["metadata"] = metadata
+	obj["apiVersion"] = res.APIVersion
+	obj["kind"] = res.Kind
+	return obj, findings
+}
+
+// conflictMessage lists the field managers a conflict is with.
+func conflictMessage(err error) string {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return "conflicts with another field manager"
+	}
+	var causes []string
+	for _, cause := range status.Status().Details.Causes {
+		causes = append(causes, cause.Message)
+	}
+	if len(causes) == 0 {
+		return "conflicts with another field manager"
+	}
+	return "conflicts: " + strings.Join(causes, "; ")
+}
+
+// failed marks a result as failed with a message.
+func failed(result Result, message string) Result {
+	result.Status = StatusFailed
+	result.Message = message
+	return result
+}
+
+// deepCopy copies nested maps and slices so that preparing a resource does
+// not modify the snapshot it came from.
+func deepCopy(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		out[k] = deepCopyValue(v)
+	}
+	return out
+}
+
+// deepCopyValue copies a single decoded value.
+func deepCopyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return deepCopy(val)
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = deepCopyValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,138 @@
+package restorer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/collector"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func deployment() types.Resource {
+	return types.Resource{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "default",
+		Name:       "web",
+		Raw: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "web",
+				"namespace":       "default",
+				"uid":             "abc",
+				"resourceVersion": "42",
+			},
+			"spec": map[string]interface{}{
+				"replicas": 3,
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+			},
+			"status": map[string]interface{}{"readyReplicas": 3},
+		},
+	}
+}
+
+// newTestRestorer returns a Restorer over a fake client and the patches it receives.
+func newTestRestorer(opts Options, patchErr error) (*Restorer, *[]k8stesting.PatchAction) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	var patches []k8stesting.PatchAction
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patches = append(patches, action.(k8stesting.PatchAction))
+		return true, nil, patchErr
+	})
+	return newRestorer(client, mapper, opts), &patches
+}
+
+func TestRestore_ServerSideAppliesPreparedObject(t *testing.T) {
+	r, patches := newTestRestorer(Options{FieldManager: DefaultFieldManager}, nil)
+	original := deployment()
+
+	results, err := r.Restore(context.Background(), []types.Resource{original})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, StatusApplied, results[0].Status)
+	assert.Len(t, results[0].Warnings, 1, "selector is immutable")
+
+	require.Len(t, *patches, 1)
+	patch := (*patches)[0]
+	assert.Equal(t, k8stypes.ApplyPatchType, patch.GetPatchType())
+	assert.Equal(t, "default", patch.GetNamespace())
+	assert.Equal(t, "deployments", patch.GetResource().Resource)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(patch.GetPatch(), &body))
+	assert.NotContains(t, body, "status")
+	assert.NotContains(t, body["metadata"], "uid")
+	assert.NotContains(t, body["metadata"], "resourceVersion")
+
+	// The snapshot itself is not modified
+	assert.Contains(t, original.Raw, "status")
+	assert.Contains(t, original.Raw["metadata"], "uid")
+}
+
+func TestRestore_Conflicts(t *testing.T) {
+	conflict := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "argocd-controller": .spec.replicas`},
+	}, "Apply failed with 1 conflict")
+
+	r, _ := newTestRestorer(Options{FieldManager: DefaultFieldManager}, conflict)
+	results, err := r.Restore(context.Background(), []types.Resource{deployment()})
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Contains(t, results[0].Message, "argocd-controller")
+	assert.Contains(t, results[0].Message, "--force-conflicts")
+
+	r, _ = newTestRestorer(Options{FieldManager: DefaultFieldManager, SkipConflicts: true}, conflict)
+	results, err = r.Restore(context.Background(), []types.Resource{deployment()})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSkipped, results[0].Status)
+}
+
+func TestRestore_UnknownKind(t *testing.T) {
+	r, patches := newTestRestorer(Options{FieldManager: DefaultFieldManager}, nil)
+	res := types.Resource{APIVersion: "example.com/v1", Kind: "Widget", Name: "w"}
+
+	results, err := r.Restore(context.Background(), []types.Resource{res})
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Empty(t, *patches)
+}
+
+func TestRestore_RefusesRedactedEnv(t *testing.T) {
+	r, patches := newTestRestorer(Options{FieldManager: DefaultFieldManager}, nil)
+	res := deployment()
+	res.Raw["spec"].(map[string]interface{})["template"] = map[string]interface{}{
+		"spec": map[string]interface{}{"containers": []interface{}{
+			map[string]interface{}{"name": "web", "env": []interface{}{
+				map[string]interface{}{"name": "DB_PASSWORD", "value": collector.RedactedValue},
+			}},
+		}},
+	}
+
+	results, err := r.Restore(context.Background(), []types.Resource{res})
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Contains(t, results[0].Message, "redact_env")
+	assert.Empty(t, *patches)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, Options{FieldManager: DefaultFieldManager}.Validate())
+	assert.Error(t, Options{FieldManager: DefaultFieldManager, ForceConflicts: true, SkipConflicts: true}.Validate())
+	assert.Error(t, Options{}.Validate())
+}
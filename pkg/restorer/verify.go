@@ -0,0 +1,93 @@
+package restorer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/analyzer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/immutable"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Divergence is a restored resource whose live state does not match the
+// restore target.
+type Divergence struct {
+	Resource   string            `json:"resource" yaml:"resource"`
+	Reason     string            `json:"reason" yaml:"reason"`
+	FieldDiffs []types.FieldDiff `json:"fieldDiffs,omitempty" yaml:"fieldDiffs,omitempty"`
+}
+
+// Verify compares the restored resources with a fresh snapshot of the live
+// state and reports those that did not converge. Differences in fields the
+// API server owns (see package immutable) are expected and ignored, as are
+// the resources and fields the ignore rules leave out of drift.
+func Verify(rules *config.IgnoreConfig, restored []types.Resource, live *types.ResourceSnapshot) []Divergence {
+	wanted := make(map[string]bool, len(restored))
+	for _, res := range restored {
+		wanted[res.FullName()] = true
+	}
+	var current []types.Resource
+	for _, res := range live.Resources {
+		if wanted[res.FullName()] {
+			current = append(current, res)
+		}
+	}
+
+	report := analyzer.NewWithOptions(analyzer.Options{Ignore: rules}).Compare(
+		&types.ResourceSnapshot{Resources: restored},
+		&types.ResourceSnapshot{Resources: current},
+	)
+
+	var divergences []Divergence
+	for _, entry := range report.Entries {
+		name := entry.Resource.FullName()
+		if entry.Type == types.DriftRemoved {
+			divergences = append(divergences, Divergence{Resource: name, Reason: "not found in the live state"})
+			continue
+		}
+
+		diffs := unexpectedDiffs(entry.Resource.Kind, entry.FieldDiffs)
+		if len(diffs) == 0 {
+			continue
+		}
+		divergences = append(divergences, Divergence{
+			Resource:   name,
+			Reason:     divergenceReason(entry.Resource.Kind, diffs),
+			FieldDiffs: diffs,
+		})
+	}
+	return divergences
+}
+
+// unexpectedDiffs drops differences in fields the API server assigns.
+func unexpectedDiffs(kind string, diffs []types.FieldDiff) []types.FieldDiff {
+	var kept []types.FieldDiff
+	for _, diff := range diffs {
+		if rule, ok := matchRule(kind, diff.Path); ok && rule.Action == immutable.ActionStrip {
+			continue
+		}
+		kept = append(kept, diff)
+	}
+	return kept
+}
+
+// divergenceReason explains why a resource most likely did not converge.
+func divergenceReason(kind string, diffs []types.FieldDiff) string {
+	for _, diff := range diffs {
+		if rule, ok := matchRule(kind, diff.Path); ok && rule.Action == immutable.ActionWarn {
+			return fmt.Sprintf("immutable field %s kept its live value (%s)", rule.Path, rule.Reason)
+		}
+	}
+	return "changed after apply, e.g. by an admission webhook or controller"
+}
+
+// matchRule finds the immutable rule covering a field path, if any.
+func matchRule(kind, path string) (immutable.Rule, bool) {
+	for _, rule := range immutable.RulesFor(kind) {
+		if path == rule.Path || strings.HasPrefix(path, rule.Path+".") || strings.HasPrefix(path, rule.Path+"[") {
+			return rule, true
+		}
+	}
+	return immutable.Rule{}, false
+}
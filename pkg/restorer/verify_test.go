@@ -0,0 +1,41 @@
+package restorer
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSpec(kind, name string, spec map[string]interface{}) types.Resource {
+	return types.Resource{APIVersion: "v1", Kind: kind, Namespace: "default", Name: name, Spec: spec}
+}
+
+func TestVerify(t *testing.T) {
+	restored := []types.Resource{
+		withSpec("Service", "converged", map[string]interface{}{"clusterIP": "10.0.0.1", "port": 80}),
+		withSpec("Deployment", "mutated", map[string]interface{}{"replicas": 3}),
+		withSpec("StatefulSet", "immutable", map[string]interface{}{"serviceName": "old"}),
+		withSpec("ConfigMap", "missing", nil),
+	}
+	live := &types.ResourceSnapshot{Resources: []types.Resource{
+		// A new clusterIP is assigned by the server and is not a divergence
+		withSpec("Service", "converged", map[string]interface{}{"clusterIP": "10.0.0.9", "port": 80}),
+		withSpec("Deployment", "mutated", map[string]interface{}{"replicas": 1}),
+		withSpec("StatefulSet", "immutable", map[string]interface{}{"serviceName": "new"}),
+		withSpec("ConfigMap", "unrelated", nil),
+	}}
+
+	divergences := Verify(nil, restored, live)
+	require.Len(t, divergences, 3)
+
+	byName := make(map[string]Divergence)
+	for _, d := range divergences {
+		byName[d.Resource] = d
+	}
+	assert.Equal(t, "not found in the live state", byName["default/ConfigMap/missing"].Reason)
+	assert.Contains(t, byName["default/Deployment/mutated"].Reason, "admission webhook")
+	assert.Equal(t, ".spec.replicas", byName["default/Deployment/mutated"].FieldDiffs[0].Path)
+	assert.Contains(t, byName["default/StatefulSet/immutable"].Reason, ".spec.serviceName")
+}
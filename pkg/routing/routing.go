@@ -0,0 +1,387 @@
+// Package routing derives a normalized routing table from the Ingresses and
+// Gateway API HTTPRoutes in a snapshot, so that traffic routing at two
+// points in time can be compared as host/path → service → workload rather
+// than as diffs of the routing objects.
+package routing
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/internal/rawobj"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Kinds are the kinds of the objects a routing table is derived from:
+// the routing objects themselves, and the Services and workloads their
+// backends resolve to.
+var Kinds = []string{"Ingress", "HTTPRoute", "Service", "Deployment", "StatefulSet", "DaemonSet"}
+
+// workloadKinds are the kinds whose pod templates Service selectors are
+// matched against.
+var workloadKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true}
+
+// AnyHost is the host of routes that match every host.
+const AnyHost = "*"
+
+// Backend is a service traffic for a route is sent to.
+type Backend struct {
+	// Service is the namespace/name of the backend Service.
+	Service string `json:"service" yaml:"service"`
+	Port    string `json:"port,omitempty" yaml:"port,omitempty"`
+	// Weight is the share of traffic of an HTTPRoute backend, if set.
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
+	// Workloads are the workloads selected by the Service, e.g.
+	// "Deployment prod/web".
+	Workloads []string `json:"workloads,omitempty" yaml:"workloads,omitempty"`
+}
+
+// String renders the backend as "<service>:<port> (weight) → <workloads>".
+func (b Backend) String() string {
+	s := b.Service
+	if b.Port != "" {
+		s += ":" + b.Port
+	}
+	if b.Weight != nil {
+		s += fmt.Sprintf(" (weight %d)", *b.Weight)
+	}
+	if len(b.Workloads) > 0 {
+		s += " → " + strings.Join(b.Workloads, ", ")
+	}
+	return s
+}
+
+// Route sends requests for a host and path prefix to its backends.
+type Route struct {
+	Host string `json:"host" yaml:"host"`
+	// Path is the path matched, or empty for an Ingress's default backend.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Source names the object defining the route, e.g. "Ingress prod/web".
+	Source   string    `json:"source" yaml:"source"`
+	Backends []Backend `json:"backends" yaml:"backends"`
+}
+
+// key identifies a route independently of where it sends traffic.
+func (r Route) key() string {
+	return r.Host + "|" + r.Path + "|" + r.Source
+}
+
+// Target renders the host and path of the route, e.g. "shop.example.com/api".
+func (r Route) Target() string {
+	if r.Path == "" {
+		return r.Host + " (default)"
+	}
+	return r.Host + r.Path
+}
+
+// Change is a route added, removed, or sent to different backends between
+// two snapshots.
+type Change struct {
+	Type   types.DriftType `json:"type" yaml:"type"`
+	Host   string          `json:"host" yaml:"host"`
+	Path   string          `json:"path,omitempty" yaml:"path,omitempty"`
+	Source string          `json:"source" yaml:"source"`
+	// Before and After are the backends in the base and target snapshots;
+	// Before is empty for added routes and After for removed ones.
+	Before []Backend `json:"before,omitempty" yaml:"before,omitempty"`
+	After  []Backend `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+// Target renders the host and path of the changed route.
+func (c Change) Target() string {
+	return Route{Host: c.Host, Path: c.Path}.Target()
+}
+
+// Table returns the routing table of a snapshot, ordered by host, path,
+// and source.
+func Table(snapshot *types.ResourceSnapshot) []Route {
+	services := make(map[string]types.Resource)
+	var workloads []types.Resource
+	for _, res := range snapshot.Resources {
+		switch {
+		case res.Kind == "Service":
+			services[res.Namespace+"/"+res.Name] = res
+		case workloadKinds[res.Kind]:
+			workloads = append(workloads, res)
+		}
+	}
+	resolve := func(namespace, name, port string, weight *int) Backend {
+		b := Backend{Service: namespace + "/" + name, Port: port, Weight: weight}
+		if svc, ok := services[b.Service]; ok {
+			b.Workloads = selectedWorkloads(svc, workloads)
+		}
+		return b
+	}
+
+	var routes []Route
+	for _, res := range snapshot.Resources {
+		switch res.Kind {
+		case "Ingress":
+			routes = append(routes, ingressRoutes(res, resolve)...)
+		case "HTTPRoute":
+			routes = append(routes, httpRoutes(res, resolve)...)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		return less(a.Host, a.Path, a.Source, b.Host, b.Path, b.Source)
+	})
+	return routes
+}
+
+// Diff compares the routing tables of two snapshots.
+func Diff(base, target *types.ResourceSnapshot) []Change {
+	return DiffRoutes(Table(base), Table(target))
+}
+
+// DiffRoutes compares two routing tables.
+func DiffRoutes(base, target []Route) []Change {
+	baseRoutes := make(map[string]Route, len(base))
+	for _, r := range base {
+		baseRoutes[r.key()] = r
+	}
+	targetRoutes := make(map[string]Route, len(target))
+	for _, r := range target {
+		targetRoutes[r.key()] = r
+	}
+
+	var changes []Change
+	for _, r := range target {
+		before, ok := baseRoutes[r.key()]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: types.DriftAdded, Host: r.Host, Path: r.Path, Source: r.Source, After: r.Backends})
+		case !reflect.DeepEqual(before.Backends, r.Backends):
+			changes = append(changes, Change{Type: types.DriftModified, Host: r.Host, Path: r.Path, Source: r.Source, Before: before.Backends, After: r.Backends})
+		}
+	}
+	for _, r := range base {
+		if _, ok := targetRoutes[r.key()]; !ok {
+			changes = append(changes, Change{Type: types.DriftRemoved, Host: r.Host, Path: r.Path, Source: r.Source, Before: r.Backends})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		return less(a.Host, a.Path, a.Source, b.Host, b.Path, b.Source)
+	})
+	return changes
+}
+
+// FilterHost keeps only routes whose host contains the given substring.
+func FilterHost(routes []Route, host string) []Route {
+	if host == "" {
+		return routes
+	}
+	var out []Route
+	for _, r := range routes {
+		if strings.Contains(r.Host, host) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// FilterChanges keeps only changes whose host contains the given substring.
+func FilterChanges(changes []Change, host string) []Change {
+	if host == "" {
+		return changes
+	}
+	var out []Change
+	for _, c := range changes {
+		if strings.Contains(c.Host, host) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// resolver turns a backend reference into a Backend with its workloads.
+type resolver func(namespace, name, port string, weight *int) Backend
+
+// ingressRoutes reads the rules and default backend of an Ingress.
+func ingressRoutes(res types.Resource, resolve resolver) []Route {
+	spec, _ := rawobj.Field(res, "spec").(map[string]interface{})
+	source := "Ingress " + namespacedName(res)
+
+	var routes []Route
+	if backend, ok := ingressBackend(res.Namespace, spec["defaultBackend"], resolve); ok {
+		routes = append(routes, Route{Host: AnyHost, Source: source, Backends: []Backend{backend}})
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, item := range rules {
+		rule, _ := item.(map[string]interface{})
+		host, _ := rule["host"].(string)
+		if host == "" {
+			host = AnyHost
+		}
+		http, _ := rule["http"].(map[string]interface{})
+		paths, _ := http["paths"].([]interface{})
+		for _, p := range paths {
+			path, _ := p.(map[string]interface{})
+			backend, ok := ingressBackend(res.Namespace, path["backend"], resolve)
+			if !ok {
+				continue
+			}
+			value, _ := path["path"].(string)
+			if value == "" {
+				value = "/"
+			}
+			routes = append(routes, Route{Host: host, Path: value, Source: source, Backends: []Backend{backend}})
+		}
+	}
+	return routes
+}
+
+// ingressBackend reads an Ingress backend, in the networking.k8s.io/v1
+// form or the older serviceName/servicePort form.
+func ingressBackend(namespace string, v interface{}, resolve resolver) (Backend, bool) {
+	backend, ok := v.(map[string]interface{})
+	if !ok {
+		return Backend{}, false
+	}
+	if svc, ok := backend["service"].(map[string]interface{}); ok {
+		name, _ := svc["name"].(string)
+		port, _ := svc["port"].(map[string]interface{})
+		portName := scalar(port["number"])
+		if portName == "" {
+			portName = scalar(port["name"])
+		}
+		return resolve(namespace, name, portName, nil), name != ""
+	}
+	if name, ok := backend["serviceName"].(string); ok {
+		return resolve(namespace, name, scalar(backend["servicePort"]), nil), true
+	}
+	return Backend{}, false
+}
+
+// httpRoutes reads the rules of a Gateway API HTTPRoute. Rules without a
+// path match route every path ("/"); backends other than Services are
+// skipped.
+func httpRoutes(res types.Resource, resolve resolver) []Route {
+	spec, _ := rawobj.Field(res, "spec").(map[string]interface{})
+	source := "HTTPRoute " + namespacedName(res)
+
+	hosts := rawobj.StringList(spec["hostnames"])
+	if len(hosts) == 0 {
+		hosts = []string{AnyHost}
+	}
+
+	var routes []Route
+	rules, _ := spec["rules"].([]interface{})
+	for _, item := range rules {
+		rule, _ := item.(map[string]interface{})
+
+		var backends []Backend
+		refs, _ := rule["backendRefs"].([]interface{})
+		for _, r := range refs {
+			ref, _ := r.(map[string]interface{})
+			if kind, ok := ref["kind"].(string); ok && kind != "Service" {
+				continue
+			}
+			name, _ := ref["name"].(string)
+			namespace, _ := ref["namespace"].(string)
+			if namespace == "" {
+				namespace = res.Namespace
+			}
+			var weight *int
+			switch w := ref["weight"].(type) {
+			case int:
+				weight = &w
+			case float64:
+				n := int(w)
+				weight = &n
+			}
+			backends = append(backends, resolve(namespace, name, scalar(ref["port"]), weight))
+		}
+		if len(backends) == 0 {
+			continue
+		}
+
+		var paths []string
+		matches, _ := rule["matches"].([]interface{})
+		for _, m := range matches {
+			match, _ := m.(map[string]interface{})
+			path, _ := match["path"].(map[string]interface{})
+			if value, ok := path["value"].(string); ok {
+				paths = append(paths, value)
+			}
+		}
+		if len(paths) == 0 {
+			paths = []string{"/"}
+		}
+
+		for _, host := range hosts {
+			for _, path := range paths {
+				routes = append(routes, Route{Host: host, Path: path, Source: source, Backends: backends})
+			}
+		}
+	}
+	return routes
+}
+
+// selectedWorkloads returns the workloads in the Service's namespace whose
+// pod template labels match its selector, e.g. "Deployment prod/web".
+func selectedWorkloads(svc types.Resource, workloads []types.Resource) []string {
+	spec, _ := rawobj.Field(svc, "spec").(map[string]interface{})
+	selector, _ := spec["selector"].(map[string]interface{})
+	if len(selector) == 0 {
+		return nil
+	}
+
+	var out []string
+	for _, w := range workloads {
+		if w.Namespace != svc.Namespace {
+			continue
+		}
+		spec, _ := rawobj.Field(w, "spec").(map[string]interface{})
+		template, _ := spec["template"].(map[string]interface{})
+		metadata, _ := template["metadata"].(map[string]interface{})
+		labels, _ := metadata["labels"].(map[string]interface{})
+		if selects(selector, labels) {
+			out = append(out, w.Kind+" "+namespacedName(w))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// selects reports whether every selector label is set to the same value.
+func selects(selector, labels map[string]interface{}) bool {
+	for k, v := range selector {
+		if scalar(labels[k]) != scalar(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// less orders routes by host, path, and source.
+func less(hostA, pathA, sourceA, hostB, pathB, sourceB string) bool {
+	if hostA != hostB {
+		return hostA < hostB
+	}
+	if pathA != pathB {
+		return pathA < pathB
+	}
+	return sourceA < sourceB
+}
+
+// namespacedName renders a resource as namespace/name.
+func namespacedName(res types.Resource) string {
+	if res.Namespace == "" {
+		return res.Name
+	}
+	return res.Namespace + "/" + res.Name
+}
+
+// scalar renders a decoded YAML scalar, or "" if absent.
+func scalar(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
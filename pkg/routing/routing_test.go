@@ -0,0 +1,130 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ingress(namespace, name string, spec map[string]interface{}) types.Resource {
+	return types.Resource{Kind: "Ingress", Namespace: namespace, Name: name, Raw: map[string]interface{}{"spec": spec}}
+}
+
+func ingressPath(path, service string, port int) map[string]interface{} {
+	return map[string]interface{}{
+		"path": path,
+		"backend": map[string]interface{}{
+			"service": map[string]interface{}{"name": service, "port": map[string]interface{}{"number": port}},
+		},
+	}
+}
+
+func service(namespace, name string, selector map[string]interface{}) types.Resource {
+	return types.Resource{Kind: "Service", Namespace: namespace, Name: name, Raw: map[string]interface{}{
+		"spec": map[string]interface{}{"selector": selector},
+	}}
+}
+
+func deployment(namespace, name string, labels map[string]interface{}) types.Resource {
+	return types.Resource{Kind: "Deployment", Namespace: namespace, Name: name, Raw: map[string]interface{}{
+		"spec": map[string]interface{}{"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": labels},
+		}},
+	}}
+}
+
+func shop(backend string) *types.ResourceSnapshot {
+	return &types.ResourceSnapshot{Resources: []types.Resource{
+		ingress("prod", "shop", map[string]interface{}{
+			"defaultBackend": map[string]interface{}{"service": map[string]interface{}{"name": "fallback", "port": map[string]interface{}{"name": "http"}}},
+			"rules": []interface{}{map[string]interface{}{
+				"host": "shop.example.com",
+				"http": map[string]interface{}{"paths": []interface{}{
+					ingressPath("/api", backend, 8080),
+					ingressPath("/", "web", 80),
+				}},
+			}},
+		}),
+		service("prod", "web", map[string]interface{}{"app": "web"}),
+		service("prod", "api", map[string]interface{}{"app": "api"}),
+		service("prod", "api-v2", map[string]interface{}{"app": "api", "track": "v2"}),
+		deployment("prod", "web", map[string]interface{}{"app": "web", "tier": "frontend"}),
+		deployment("prod", "api", map[string]interface{}{"app": "api"}),
+		deployment("prod", "api-v2", map[string]interface{}{"app": "api", "track": "v2"}),
+		deployment("staging", "web", map[string]interface{}{"app": "web"}),
+	}}
+}
+
+func TestTable_Ingress(t *testing.T) {
+	routes := Table(shop("api"))
+
+	require.Len(t, routes, 3)
+	assert.Equal(t, "* (default)", routes[0].Target())
+	assert.Equal(t, "prod/fallback:http", routes[0].Backends[0].String(), "unknown services have no workloads")
+
+	assert.Equal(t, "shop.example.com/", routes[1].Target())
+	assert.Equal(t, "Ingress prod/shop", routes[1].Source)
+	assert.Equal(t, "prod/web:80 → Deployment prod/web", routes[1].Backends[0].String(), "selectors match within the namespace")
+
+	assert.Equal(t, "shop.example.com/api", routes[2].Target())
+	assert.Equal(t, []string{"Deployment prod/api", "Deployment prod/api-v2"}, routes[2].Backends[0].Workloads)
+}
+
+func TestTable_HTTPRoute(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{{
+		Kind: "HTTPRoute", Namespace: "prod", Name: "checkout",
+		Raw: map[string]interface{}{"spec": map[string]interface{}{
+			"hostnames": []interface{}{"checkout.example.com"},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"matches": []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/pay"}}},
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": "pay", "port": 80, "weight": 90},
+						map[string]interface{}{"name": "pay-canary", "namespace": "canary", "port": 80, "weight": 10},
+						map[string]interface{}{"kind": "Bucket", "name": "assets"},
+					},
+				},
+				map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "web"}}},
+			},
+		}},
+	}}}
+
+	routes := Table(snapshot)
+
+	require.Len(t, routes, 2)
+	assert.Equal(t, "checkout.example.com/", routes[0].Target())
+	assert.Equal(t, "checkout.example.com/pay", routes[1].Target())
+	assert.Equal(t, "HTTPRoute prod/checkout", routes[1].Source)
+	require.Len(t, routes[1].Backends, 2, "non-Service backends are skipped")
+	assert.Equal(t, "prod/pay:80 (weight 90)", routes[1].Backends[0].String())
+	assert.Equal(t, "canary/pay-canary:80 (weight 10)", routes[1].Backends[1].String())
+}
+
+func TestDiff(t *testing.T) {
+	base := shop("api")
+	target := shop("api-v2")
+	target.Resources[0].Raw["spec"].(map[string]interface{})["defaultBackend"] = nil
+	target.Resources = append(target.Resources, ingress("prod", "admin", map[string]interface{}{
+		"rules": []interface{}{map[string]interface{}{
+			"host": "admin.example.com",
+			"http": map[string]interface{}{"paths": []interface{}{ingressPath("", "web", 80)}},
+		}},
+	}))
+
+	changes := Diff(base, target)
+
+	require.Len(t, changes, 3)
+	assert.Equal(t, types.DriftRemoved, changes[0].Type)
+	assert.Equal(t, "* (default)", changes[0].Target())
+	assert.Equal(t, types.DriftAdded, changes[1].Type)
+	assert.Equal(t, "admin.example.com/", changes[1].Target(), "an empty Ingress path routes every path")
+	assert.Equal(t, types.DriftModified, changes[2].Type)
+	assert.Equal(t, "shop.example.com/api", changes[2].Target())
+	assert.Equal(t, "prod/api", changes[2].Before[0].Service)
+	assert.Equal(t, []string{"Deployment prod/api-v2"}, changes[2].After[0].Workloads)
+
+	assert.Len(t, FilterChanges(changes, "shop"), 1)
+	assert.Empty(t, Diff(base, shop("api")))
+}
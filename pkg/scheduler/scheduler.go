@@ -5,6 +5,9 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
+	// Embed the zone database so time zones resolve in minimal containers.
+	_ "time/tzdata"
 
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
@@ -17,27 +20,49 @@ type SnapshotFunc func(ctx context.Context) error
 type Scheduler struct {
 	cron       *cron.Cron
 	schedule   string
+	location   *time.Location
 	snapshotFn SnapshotFunc
 	mu         sync.Mutex
 	running    bool
 	cancelFn   context.CancelFunc
 }
 
-// New creates a new Scheduler with the given cron schedule.
-func New(schedule string, fn SnapshotFunc) (*Scheduler, error) {
+// New creates a new Scheduler with the given cron schedule, evaluated in the
+// named IANA time zone (empty means the process's local zone). A CRON_TZ= or
+// TZ= prefix on the schedule itself takes precedence over timezone.
+func New(schedule, timezone string, fn SnapshotFunc) (*Scheduler, error) {
+	loc := time.Local
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+	}
+
 	// Validate the cron expression
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 	if _, err := parser.Parse(schedule); err != nil {
 		return nil, fmt.Errorf("invalid cron schedule %q: %w", schedule, err)
 	}
 
 	return &Scheduler{
-		cron:       cron.New(),
+		cron:       cron.New(cron.WithLocation(loc)),
 		schedule:   schedule,
+		location:   loc,
 		snapshotFn: fn,
 	}, nil
 }
 
+// Next returns the next activation time after t, in the scheduler's time zone.
+func (s *Scheduler) Next(t time.Time) time.Time {
+	sched, err := cron.ParseStandard(s.schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	return sched.Next(t.In(s.location))
+}
+
 // Start begins the scheduled snapshot execution.
 func (s *Scheduler) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -65,7 +90,10 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	}
 
 	s.cron.Start()
-	log.WithField("schedule", s.schedule).Info("scheduler started")
+	log.WithFields(log.Fields{
+		"schedule": s.schedule,
+		"timezone": s.location.String(),
+	}).Info("scheduler started")
 
 	// Block until context is cancelled
 	<-childCtx.Done()
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noop(ctx context.Context) error { return nil }
+
+func TestNew_InvalidSchedule(t *testing.T) {
+	_, err := New("not a schedule", "", noop)
+	assert.Error(t, err)
+}
+
+func TestNew_InvalidTimezone(t *testing.T) {
+	_, err := New("0 2 * * *", "Mars/Olympus_Mons", noop)
+	assert.Error(t, err)
+}
+
+func TestNext_Timezone(t *testing.T) {
+	s, err := New("0 2 * * *", "Europe/Berlin", noop)
+	require.NoError(t, err)
+
+	// 02:00 in Berlin during winter is 01:00 UTC
+	next := s.Next(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 10, 1, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestNext_CronTZPrefix(t *testing.T) {
+	s, err := New("CRON_TZ=America/New_York 0 2 * * *", "Europe/Berlin", noop)
+	require.NoError(t, err)
+
+	// The prefix wins: 02:00 in New York during winter is 07:00 UTC
+	next := s.Next(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC), next.UTC())
+}
@@ -0,0 +1,181 @@
+// Package search finds where a value appears across snapshot history.
+package search
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/index"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+)
+
+// Query selects the value to look for. When Field is set, only values under
+// that field path are considered (e.g. .spec.template.spec.containers.image).
+type Query struct {
+	Value string `json:"value" yaml:"value"`
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+}
+
+// Point identifies a snapshot.
+type Point struct {
+	CommitHash string    `json:"commitHash" yaml:"commitHash"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+}
+
+// Span is a run of consecutive snapshots in which a resource held the value.
+type Span struct {
+	First Point `json:"first" yaml:"first"`
+	Last  Point `json:"last" yaml:"last"`
+	// Removed is the first snapshot without the value, or nil if it is
+	// still present in the latest snapshot.
+	Removed *Point `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+// Appearance lists when and where one resource held the value.
+type Appearance struct {
+	Resource string   `json:"resource" yaml:"resource"`
+	Paths    []string `json:"paths" yaml:"paths"`
+	Spans    []Span   `json:"spans" yaml:"spans"`
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	Query       Query        `json:"query" yaml:"query"`
+	Snapshots   int          `json:"snapshots" yaml:"snapshots"`
+	Appearances []Appearance `json:"appearances" yaml:"appearances"`
+}
+
+// Searcher scans snapshot history, using an index to avoid decoding
+// unchanged resource files more than once.
+type Searcher struct {
+	versioner *versioner.Versioner
+	index     *index.Index
+	dir       string
+}
+
+// New creates a Searcher over the history of dir ("" for the whole repository).
+func New(v *versioner.Versioner, idx *index.Index, dir string) *Searcher {
+	return &Searcher{versioner: v, index: idx, dir: dir}
+}
+
+// Search scans every snapshot from oldest to newest for the query value.
+func (s *Searcher) Search(q Query) (*Result, error) {
+	if q.Value == "" {
+		return nil, fmt.Errorf("search value must not be empty")
+	}
+	if q.Field != "" && !strings.HasPrefix(q.Field, ".") {
+		q.Field = "." + q.Field
+	}
+
+	history, err := s.versioner.HistoryIn(s.dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+
+	found := make(map[string]*Appearance)
+	paths := make(map[string]map[string]bool)
+	var order []string
+
+	// History is newest first; walk it oldest first so spans open and close in order
+	for i := len(history) - 1; i >= 0; i-- {
+		point := Point{CommitHash: history[i].CommitHash, Timestamp: history[i].Timestamp}
+		matched, err := s.scan(point.CommitHash, q)
+		if err != nil {
+			return nil, err
+		}
+
+		for resource, fieldPaths := range matched {
+			app, ok := found[resource]
+			if !ok {
+				app = &Appearance{Resource: resource}
+				found[resource] = app
+				paths[resource] = make(map[string]bool)
+				order = append(order, resource)
+			}
+			for _, p := range fieldPaths {
+				paths[resource][p] = true
+			}
+			if n := len(app.Spans); n > 0 && app.Spans[n-1].Removed == nil {
+				app.Spans[n-1].Last = point
+			} else {
+				app.Spans = append(app.Spans, Span{First: point, Last: point})
+			}
+		}
+
+		for resource, app := range found {
+			n := len(app.Spans)
+			if _, ok := matched[resource]; !ok && app.Spans[n-1].Removed == nil {
+				removed := point
+				app.Spans[n-1].Removed = &removed
+			}
+		}
+	}
+
+	if err := s.index.Save(); err != nil {
+		log.WithError(err).Warn("failed to save search index")
+	}
+
+	result := &Result{Query: q, Snapshots: len(history), Appearances: make([]Appearance, 0, len(order))}
+	for _, resource := range order {
+		app := found[resource]
+		for p := range paths[resource] {
+			app.Paths = append(app.Paths, p)
+		}
+		sort.Strings(app.Paths)
+		result.Appearances = append(result.Appearances, *app)
+	}
+	return result, nil
+}
+
+// scan returns the resources holding the value at a commit, with the field
+// paths where it was found.
+func (s *Searcher) scan(commitHash string, q Query) (map[string][]string, error) {
+	files, err := s.versioner.TreeFiles(commitHash, s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string][]string)
+	for _, file := range files {
+		if !snapshotter.IsResourcePath(file.Path) {
+			continue
+		}
+		file := file
+		entry, err := s.index.Lookup(file.Hash, func() (types.Resource, error) {
+			return s.decode(commitHash, file)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range entry.Fields {
+			if field.Value == q.Value && index.MatchPath(field.Path, q.Field) {
+				matched[entry.Resource] = append(matched[entry.Resource], field.Path)
+			}
+		}
+	}
+	return matched, nil
+}
+
+// decode reads a resource file from a commit, resolving externalized values
+// from the _blobs directory of its snapshot root.
+func (s *Searcher) decode(commitHash string, file versioner.TreeFile) (types.Resource, error) {
+	data, err := s.versioner.ReadBlob(file.Hash)
+	if err != nil {
+		return types.Resource{}, err
+	}
+	root := snapshotter.BlobRoot(file.Path)
+	readBlob := func(digest string) ([]byte, error) {
+		return s.versioner.ReadFileAt(commitHash, path.Join(root, snapshotter.BlobPath(digest)))
+	}
+	res, err := snapshotter.DecodeFile(file.Path, data, readBlob)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("failed to decode %s at %s: %w", file.Path, commitHash[:8], err)
+	}
+	return res, nil
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/index"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitSnapshot writes and commits a snapshot of the given resources.
+func commitSnapshot(t *testing.T, ver *versioner.Versioner, dir string, when time.Time, resources ...types.Resource) string {
+	t.Helper()
+	snapshot := &types.ResourceSnapshot{
+		Metadata:  types.SnapshotMetadata{Timestamp: when, ClusterName: "test"},
+		Resources: resources,
+	}
+	snapshot.UpdateCounts()
+	require.NoError(t, snapshotter.New(dir).Write(snapshot))
+
+	hash, err := ver.Commit(&snapshot.Metadata)
+	require.NoError(t, err)
+	return hash
+}
+
+func deployment(name, image string) types.Resource {
+	return types.Resource{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "prod",
+		Name:       name,
+		Raw: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "prod"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "app", "image": image},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSearch_TracksAppearanceAndRemoval(t *testing.T) {
+	dir := t.TempDir()
+	ver, err := versioner.New(dir, &config.DefaultConfig().Git)
+	require.NoError(t, err)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	const bad = "registry.internal/app:1.4.2"
+	commitSnapshot(t, ver, dir, base, deployment("api", "registry.internal/app:1.4.1"))
+	introduced := commitSnapshot(t, ver, dir, base.Add(time.Hour), deployment("api", bad), deployment("worker", bad))
+	still := commitSnapshot(t, ver, dir, base.Add(2*time.Hour), deployment("api", bad), deployment("worker", "registry.internal/app:1.4.3"))
+	fixed := commitSnapshot(t, ver, dir, base.Add(3*time.Hour), deployment("api", "registry.internal/app:1.4.3"), deployment("worker", "registry.internal/app:1.4.3"))
+
+	idx, err := index.Open(index.File(dir))
+	require.NoError(t, err)
+	result, err := New(ver, idx, "").Search(Query{Value: bad, Field: "spec.template.spec.containers.image"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, result.Snapshots)
+	require.Len(t, result.Appearances, 2)
+
+	byName := map[string]Appearance{}
+	for _, app := range result.Appearances {
+		byName[app.Resource] = app
+	}
+
+	api := byName["prod/Deployment/api"]
+	assert.Equal(t, []string{".spec.template.spec.containers[0].image"}, api.Paths)
+	require.Len(t, api.Spans, 1)
+	assert.Equal(t, introduced, api.Spans[0].First.CommitHash)
+	assert.Equal(t, still, api.Spans[0].Last.CommitHash)
+	require.NotNil(t, api.Spans[0].Removed)
+	assert.Equal(t, fixed, api.Spans[0].Removed.CommitHash)
+
+	worker := byName["prod/Deployment/worker"]
+	require.Len(t, worker.Spans, 1)
+	assert.Equal(t, introduced, worker.Spans[0].Last.CommitHash)
+	assert.Equal(t, still, worker.Spans[0].Removed.CommitHash)
+}
+
+func TestSearch_FieldFilterAndOpenSpan(t *testing.T) {
+	dir := t.TempDir()
+	ver, err := versioner.New(dir, &config.DefaultConfig().Git)
+	require.NoError(t, err)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	only := commitSnapshot(t, ver, dir, base, deployment("api", "app:1"))
+
+	idx, err := index.Open(index.File(dir))
+	require.NoError(t, err)
+	s := New(ver, idx, "")
+
+	result, err := s.Search(Query{Value: "app:1"})
+	require.NoError(t, err)
+	require.Len(t, result.Appearances, 1)
+	assert.Equal(t, only, result.Appearances[0].Spans[0].First.CommitHash)
+	assert.Nil(t, result.Appearances[0].Spans[0].Removed)
+
+	result, err = s.Search(Query{Value: "app:1", Field: ".metadata"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Appearances)
+
+	_, err = s.Search(Query{})
+	assert.Error(t, err)
+}
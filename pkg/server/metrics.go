@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/gaps"
+)
+
+// handleMetrics reports the snapshot history in the Prometheus text format,
+// including the scheduled snapshots that are missing (see package gaps).
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	ver, err := s.versioner()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	entries, err := ver.HistoryIn(s.scope, 0)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get history: %w", err))
+		return
+	}
+	checks, err := gaps.LoadChecks(gaps.ChecksFile(s.cfg.Snapshot.OutputDir))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var last time.Time
+	for _, e := range entries {
+		if e.Timestamp.After(last) {
+			last = e.Timestamp
+		}
+	}
+	found, err := gaps.Find(&s.cfg.Watch, gaps.SnapshotTimes(entries), checks, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	ongoing := 0
+	if len(found) > 0 && found[len(found)-1].Ongoing {
+		ongoing = 1
+	}
+
+	var b strings.Builder
+	metric := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+	metric("gitops_time_machine_snapshots", "Snapshots in the history.", float64(len(entries)))
+	if !last.IsZero() {
+		metric("gitops_time_machine_last_snapshot_timestamp_seconds", "Time of the latest snapshot.", float64(last.Unix()))
+	}
+	metric("gitops_time_machine_snapshot_gaps", "Windows of the history in which scheduled snapshots are missing.", float64(len(found)))
+	metric("gitops_time_machine_missed_snapshots", "Scheduled snapshots missing from the history.", float64(gaps.Missed(found)))
+	metric("gitops_time_machine_snapshot_gap_ongoing", "1 if scheduled snapshots have been missing since the latest one.", float64(ongoing))
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,407 @@
+// Package server exposes the snapshot history, snapshots, and drift between
+// them over a REST API, and restores to clients holding an operator token.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/notifier"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/timetravel"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	log "github.com/sirupsen/logrus"
+)
+
+// Roles an API token can grant, in increasing order of privilege.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleOperator: 2}
+
+// anonymous is the actor of unauthenticated requests, when no tokens are
+// configured.
+const anonymous = "anonymous"
+
+// maxRequestBody bounds the size of request bodies.
+const maxRequestBody = 1 << 20
+
+// ErrInvalidRestore marks restore errors caused by the request, such as an
+// unknown commit or a scope matching no resources; they are reported as
+// 400 rather than 500.
+var ErrInvalidRestore = errors.New("invalid restore request")
+
+// RestoreFunc runs a restore requested through the API on behalf of actor,
+// the name of the client's token.
+type RestoreFunc func(ctx context.Context, actor string, req restorer.Request) (*restorer.Report, error)
+
+// DriftFunc compares two snapshots read from their commits, as the diff
+// command does, and returns the annotated report.
+type DriftFunc func(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error)
+
+// Server serves the snapshot repository's history.
+type Server struct {
+	cfg *config.Config
+	// scope is the snapshot directory within the repository, e.g. a team
+	// directory in directory tenancy mode; "" is the repository root.
+	scope   string
+	mux     *http.ServeMux
+	restore RestoreFunc
+	drift   DriftFunc
+	// restoring allows one restore at a time.
+	restoring sync.Mutex
+}
+
+// New creates a Server for the configured snapshot repository.
+func New(cfg *config.Config, scope string) *Server {
+	s := &Server{cfg: cfg, scope: scope, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /api/history", s.authorize(RoleViewer, s.handleHistory))
+	s.mux.HandleFunc("GET /api/snapshots", s.authorize(RoleViewer, s.handleHistory))
+	s.mux.HandleFunc("GET /api/snapshots/at", s.authorize(RoleViewer, s.handleSnapshotAt))
+	s.mux.HandleFunc("GET /api/drift", s.authorize(RoleViewer, s.handleDrift))
+	s.mux.HandleFunc("GET /api/resources/{namespace}/{kind}/{name}/timeline", s.authorize(RoleViewer, s.handleTimeline))
+	s.mux.HandleFunc("POST /api/restore", s.authorize(RoleOperator, s.handleRestore))
+	s.mux.HandleFunc("GET /metrics", s.authorize(RoleViewer, s.handleMetrics))
+	return s
+}
+
+// Mount serves an additional handler, such as the Slack slash-command
+// endpoint, which authenticates its own requests.
+func (s *Server) Mount(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// SetRestore enables POST /api/restore, which runs fn.
+func (s *Server) SetRestore(fn RestoreFunc) {
+	s.restore = fn
+}
+
+// SetDrift enables GET /api/drift, which compares snapshots with fn.
+func (s *Server) SetDrift(fn DriftFunc) {
+	s.drift = fn
+}
+
+// Validate checks the API tokens.
+func Validate(cfg *config.ServeConfig) error {
+	for i, token := range cfg.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return fmt.Errorf("serve.tokens[%d] needs a name and a token", i)
+		}
+		if _, ok := roleRanks[token.Role]; !ok {
+			return fmt.Errorf("serve.tokens[%d] (%s) has unknown role %q (use %s or %s)", i, token.Name, token.Role, RoleViewer, RoleOperator)
+		}
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// timeline is the response of the timeline endpoint.
+type timeline struct {
+	Namespace string                  `json:"namespace"`
+	Kind      string                  `json:"kind"`
+	Name      string                  `json:"name"`
+	Versions  []types.ResourceVersion `json:"versions"`
+}
+
+// handleHistory lists snapshots, newest first, with their composition.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+
+	ver, err := s.versioner()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	entries, err := ver.HistoryIn(s.scope, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get history: %w", err))
+		return
+	}
+	if entries == nil {
+		entries = []types.HistoryEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// handleTimeline returns every version of one resource. Cluster-scoped
+// resources use the namespace "_cluster". Secret values are masked.
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	kind := r.PathValue("kind")
+	name := r.PathValue("name")
+
+	ver, err := s.versioner()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	engine := timetravel.New(ver, snapshotter.New(filepath.Join(s.cfg.Snapshot.OutputDir, s.scope)), s.cfg.Snapshot.OutputDir)
+
+	lookupNamespace := namespace
+	if namespace == types.ClusterScope {
+		lookupNamespace = ""
+	}
+	versions, err := engine.Timeline(r.Context(), s.scope, lookupNamespace, kind, name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(versions) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no history for %s/%s/%s", namespace, kind, name))
+		return
+	}
+
+	for i := range versions {
+		if versions[i].Resource != nil {
+			masked := notifier.MaskedResource(*versions[i].Resource)
+			versions[i].Resource = &masked
+		}
+	}
+	writeJSON(w, http.StatusOK, timeline{Namespace: namespace, Kind: kind, Name: name, Versions: versions})
+}
+
+// handleSnapshotAt returns the snapshot at ?time= (RFC3339), or at
+// ?commit= (a commit, branch, tag, or revision such as HEAD~1). Secret
+// values are masked.
+func (s *Server) handleSnapshotAt(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	at, commit := query.Get("time"), query.Get("commit")
+	if (at == "") == (commit == "") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("specify either time or commit"))
+		return
+	}
+	ref := commit
+	if at != "" {
+		ref = at
+	}
+
+	ver, err := s.versioner()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	snapshot, status, err := s.readSnapshot(r.Context(), ver, ref)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	for i := range snapshot.Resources {
+		snapshot.Resources[i] = notifier.MaskedResource(snapshot.Resources[i])
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// handleDrift returns the drift report between the snapshots at ?from= and
+// ?to= (the latest snapshot if omitted). Each is a commit, branch, tag, or
+// revision, or an RFC3339 time. Secret values are masked.
+func (s *Server) handleDrift(w http.ResponseWriter, r *http.Request) {
+	if s.drift == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("drift is not available on this server"))
+		return
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("from is required"))
+		return
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	ver, err := s.versioner()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	base, status, err := s.readSnapshot(r.Context(), ver, from)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	target, status, err := s.readSnapshot(r.Context(), ver, to)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	report, err := s.drift(r.Context(), base, target)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range report.Entries {
+		report.Entries[i] = notifier.MaskedEntry(report.Entries[i])
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// readSnapshot reads the snapshot at a ref or RFC3339 time from its commit,
+// without checking it out. On error it also returns the HTTP status: 404
+// when there is no such snapshot.
+func (s *Server) readSnapshot(ctx context.Context, ver *versioner.Versioner, ref string) (*types.ResourceSnapshot, int, error) {
+	if at, err := time.Parse(time.RFC3339, ref); err == nil {
+		if ref, err = ver.FindCommitByTime(ctx, at); err != nil {
+			return nil, notFoundStatus(err), err
+		}
+	}
+	entry, err := ver.Entry(ref, s.scope)
+	if err != nil {
+		return nil, notFoundStatus(err), err
+	}
+	snapshot, err := timetravel.ReadCommit(ctx, ver, entry, s.scope, nil)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return snapshot, http.StatusOK, nil
+}
+
+// notFoundStatus returns 404 for errors meaning a snapshot does not exist,
+// and 500 otherwise.
+func notFoundStatus(err error) int {
+	if errors.Is(err, types.ErrNoSnapshots) || errors.Is(err, types.ErrCommitNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// handleRestore restores resources from a snapshot, as the restore command
+// does. The response is the restore report; results with status "failed"
+// or unconverged resources do not change the HTTP status.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if s.restore == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("restore is not available on this server"))
+		return
+	}
+
+	var req restorer.Request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !s.restoring.TryLock() {
+		writeError(w, http.StatusConflict, fmt.Errorf("a restore is already in progress"))
+		return
+	}
+	defer s.restoring.Unlock()
+
+	// A client disconnecting must not abort a restore halfway
+	report, err := s.restore(context.WithoutCancel(r.Context()), actorFrom(r.Context()), req)
+	if errors.Is(err, ErrInvalidRestore) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// actorKey is the context key of the authenticated token's name.
+type actorKey struct{}
+
+// actorFrom returns the name of the token that authenticated the request.
+func actorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok {
+		return actor
+	}
+	return anonymous
+}
+
+// authorize requires a bearer token granting at least role. Without
+// configured tokens, viewer endpoints are open and others are forbidden.
+func (s *Server) authorize(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokens := s.cfg.Serve.Tokens
+		if len(tokens) == 0 {
+			if role != RoleViewer {
+				writeError(w, http.StatusForbidden, fmt.Errorf("this endpoint requires an API token with the %s role (serve.tokens)", role))
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		var match *config.APIToken
+		if ok {
+			// Compare against every token so timing does not reveal which matched
+			for i := range tokens {
+				if subtle.ConstantTimeCompare([]byte(presented), []byte(tokens[i].Token)) == 1 {
+					match = &tokens[i]
+				}
+			}
+		}
+		if match == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("a valid API token is required"))
+			return
+		}
+		if roleRanks[match.Role] < roleRanks[role] {
+			writeError(w, http.StatusForbidden, fmt.Errorf("token %q lacks the %s role", match.Name, role))
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), actorKey{}, match.Name)))
+	}
+}
+
+// versioner opens the snapshot repository.
+func (s *Server) versioner() (*versioner.Versioner, error) {
+	ver, err := versioner.New(s.cfg.Snapshot.OutputDir, &s.cfg.Git)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize versioner: %w", err)
+	}
+	return ver, nil
+}
+
+// writeJSON writes v as an indented JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.WithError(err).Warn("failed to write response")
+	}
+}
+
+// writeError writes an error as {"error": "..."}.
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		log.WithError(err).Warn("API request failed")
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
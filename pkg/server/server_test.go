@@ -0,0 +1,307 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/restorer"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/versioner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitSnapshot writes and commits a snapshot of the given resources.
+func commitSnapshot(t *testing.T, cfg *config.Config, when time.Time, resources ...types.Resource) string {
+	t.Helper()
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: when, ClusterName: "test", Collection: []types.CollectionStats{
+			{Resource: "deployments", Duration: time.Second, Count: len(resources), Pages: 1},
+		}},
+		Resources: resources,
+	}
+	snapshot.UpdateCounts()
+	require.NoError(t, snapshotter.New(cfg.Snapshot.OutputDir).Write(snapshot))
+
+	ver, err := versioner.New(cfg.Snapshot.OutputDir, &cfg.Git)
+	require.NoError(t, err)
+	hash, err := ver.Commit(&snapshot.Metadata)
+	require.NoError(t, err)
+	return hash
+}
+
+func deployment(replicas int) types.Resource {
+	return types.Resource{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  "prod",
+		Name:       "web",
+		Raw: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "prod"},
+			"spec":       map[string]interface{}{"replicas": replicas},
+		},
+	}
+}
+
+func get(t *testing.T, s *Server, url string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	return rec.Code
+}
+
+func TestTimeline(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := commitSnapshot(t, cfg, base, deployment(1))
+	second := commitSnapshot(t, cfg, base.Add(time.Hour), deployment(3))
+	other := types.Resource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "prod", Name: "cfg"}
+	deleted := commitSnapshot(t, cfg, base.Add(2*time.Hour), other)
+
+	s := New(cfg, "")
+	var body timeline
+	require.Equal(t, http.StatusOK, get(t, s, "/api/resources/prod/Deployment/web/timeline", &body))
+
+	require.Len(t, body.Versions, 3)
+	assert.Equal(t, deleted, body.Versions[0].CommitHash)
+	assert.True(t, body.Versions[0].Deleted)
+	assert.Equal(t, second, body.Versions[1].CommitHash)
+	assert.EqualValues(t, 3, body.Versions[1].Resource.Spec["replicas"])
+	assert.Equal(t, first, body.Versions[2].CommitHash)
+	assert.EqualValues(t, 1, body.Versions[2].Resource.Spec["replicas"])
+
+	commitSnapshot(t, cfg, base.Add(3*time.Hour), other, secret("aHVudGVyMg=="))
+	var secretBody timeline
+	require.Equal(t, http.StatusOK, get(t, s, "/api/resources/prod/Secret/db/timeline", &secretBody))
+	require.Len(t, secretBody.Versions, 1)
+	assert.Equal(t, map[string]interface{}{"password": "[REDACTED]"}, secretBody.Versions[0].Resource.Data)
+	assert.Equal(t, map[string]interface{}{"password": "[REDACTED]"}, secretBody.Versions[0].Resource.Raw["data"])
+
+	var notFound map[string]string
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/resources/prod/Deployment/missing/timeline", &notFound))
+	assert.Contains(t, notFound["error"], "no history")
+}
+
+func TestHistory(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	commitSnapshot(t, cfg, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), deployment(1))
+
+	var entries []types.HistoryEntry
+	require.Equal(t, http.StatusOK, get(t, New(cfg, ""), "/api/history?limit=5", &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, map[string]int{"Deployment": 1}, entries[0].KindCounts)
+	assert.Equal(t, map[string]int{"prod": 1}, entries[0].NamespaceCounts)
+	assert.Equal(t, []types.CollectionStats{{Resource: "deployments", Duration: time.Second, Count: 1, Pages: 1}}, entries[0].Collection)
+
+	var errBody map[string]string
+	assert.Equal(t, http.StatusBadRequest, get(t, New(cfg, ""), "/api/history?limit=x", &errBody))
+}
+
+func secret(password string) types.Resource {
+	return types.Resource{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Namespace:  "prod",
+		Name:       "db",
+		Data:       map[string]interface{}{"password": password},
+		Raw: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "db", "namespace": "prod"},
+			"data":       map[string]interface{}{"password": password},
+		},
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := commitSnapshot(t, cfg, base, deployment(1))
+	second := commitSnapshot(t, cfg, base.Add(time.Hour), deployment(3))
+
+	s := New(cfg, "")
+	var snapshot types.ResourceSnapshot
+	require.Equal(t, http.StatusOK, get(t, s, "/api/snapshots/at?time=2024-01-01T00:30:00Z", &snapshot))
+	assert.Equal(t, first, snapshot.Metadata.CommitHash)
+	require.Len(t, snapshot.Resources, 1)
+	assert.EqualValues(t, 1, snapshot.Resources[0].Spec["replicas"])
+
+	snapshot = types.ResourceSnapshot{}
+	require.Equal(t, http.StatusOK, get(t, s, "/api/snapshots/at?commit=HEAD", &snapshot))
+	assert.Equal(t, second, snapshot.Metadata.CommitHash)
+
+	var entries []types.HistoryEntry
+	require.Equal(t, http.StatusOK, get(t, s, "/api/snapshots", &entries))
+	assert.Len(t, entries, 2)
+
+	commitSnapshot(t, cfg, base.Add(2*time.Hour), deployment(3), secret("aHVudGVyMg=="))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/snapshots/at?commit=HEAD", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "aHVudGVyMg==", "secret values are masked")
+	assert.Contains(t, rec.Body.String(), "[REDACTED]")
+
+	var errBody map[string]string
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/snapshots/at?time=2023-01-01T00:00:00Z", &errBody))
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/snapshots/at?commit=nosuchbranch", &errBody))
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/snapshots/at", &errBody))
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/snapshots/at?time=x&commit=HEAD", &errBody))
+}
+
+func TestDrift(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := commitSnapshot(t, cfg, base, deployment(1))
+	second := commitSnapshot(t, cfg, base.Add(time.Hour), deployment(3))
+
+	s := New(cfg, "")
+	var errBody map[string]string
+	assert.Equal(t, http.StatusNotImplemented, get(t, s, "/api/drift?from=HEAD~1", &errBody))
+
+	var gotBase, gotTarget string
+	s.SetDrift(func(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+		gotBase, gotTarget = base.Metadata.CommitHash, target.Metadata.CommitHash
+		return &types.DriftReport{BaseRef: gotBase, TargetRef: gotTarget}, nil
+	})
+
+	var report types.DriftReport
+	require.Equal(t, http.StatusOK, get(t, s, "/api/drift?from=HEAD~1", &report))
+	assert.Equal(t, first, gotBase)
+	assert.Equal(t, second, gotTarget, "to defaults to the latest snapshot")
+	assert.Equal(t, first, report.BaseRef)
+
+	require.Equal(t, http.StatusOK, get(t, s, "/api/drift?from=2024-01-01T00:00:00Z&to="+first, &report))
+	assert.Equal(t, first, gotTarget)
+
+	s.SetDrift(func(ctx context.Context, base, target *types.ResourceSnapshot) (*types.DriftReport, error) {
+		return &types.DriftReport{Entries: []types.DriftEntry{{
+			Type:       types.DriftModified,
+			Resource:   secret("c3dvcmRmaXNo"),
+			FieldDiffs: []types.FieldDiff{{Path: ".data.password", OldValue: "aHVudGVyMg==", NewValue: "c3dvcmRmaXNo"}},
+		}}}, nil
+	})
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/drift?from=HEAD~1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "aHVudGVyMg==")
+	assert.NotContains(t, rec.Body.String(), "c3dvcmRmaXNo")
+
+	assert.Equal(t, http.StatusBadRequest, get(t, s, "/api/drift", &errBody))
+	assert.Equal(t, http.StatusNotFound, get(t, s, "/api/drift?from=nosuchbranch", &errBody))
+}
+
+func do(t *testing.T, s *Server, method, url, token, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestRestoreRequiresOperatorToken(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	var gotActor string
+	var gotReq restorer.Request
+	restore := func(ctx context.Context, actor string, req restorer.Request) (*restorer.Report, error) {
+		gotActor, gotReq = actor, req
+		if req.Commit == "unknown" {
+			return nil, fmt.Errorf("%w: no such commit", ErrInvalidRestore)
+		}
+		return &restorer.Report{Commit: "abc", Results: []restorer.Result{{Resource: "Deployment/prod/web", Status: restorer.StatusApplied}}}, nil
+	}
+
+	// Without tokens, restores are forbidden
+	s := New(cfg, "")
+	s.SetRestore(restore)
+	code, _ := do(t, s, http.MethodPost, "/api/restore", "", `{"commit":"HEAD"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	cfg.Serve.Tokens = []config.APIToken{
+		{Name: "dashboard", Token: "view-token", Role: RoleViewer},
+		{Name: "chatops", Token: "op-token", Role: RoleOperator},
+	}
+	require.NoError(t, Validate(&cfg.Serve))
+	s = New(cfg, "")
+	s.SetRestore(restore)
+
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "", `{"commit":"HEAD"}`)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "wrong", `{"commit":"HEAD"}`)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "view-token", `{"commit":"HEAD"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, resp := do(t, s, http.MethodPost, "/api/restore", "op-token", `{"commit":"HEAD~1","namespace":"prod","dryRun":true}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "abc", resp["commit"])
+	assert.Equal(t, "chatops", gotActor)
+	assert.Equal(t, restorer.Request{Commit: "HEAD~1", Namespace: "prod", DryRun: true}, gotReq)
+
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "op-token", `{"commit":"unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "op-token", `{"revision":"HEAD"}`)
+	assert.Equal(t, http.StatusBadRequest, code, "unknown fields are rejected")
+	code, _ = do(t, s, http.MethodPost, "/api/restore", "op-token", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code, "a snapshot is required")
+
+	// Read endpoints need a token once tokens are configured
+	commitSnapshot(t, cfg, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), deployment(1))
+	code, _ = do(t, s, http.MethodGet, "/api/resources/prod/Deployment/web/timeline", "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = do(t, s, http.MethodGet, "/api/resources/prod/Deployment/web/timeline", "view-token", "")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestRestoreNotAvailable(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Serve.Tokens = []config.APIToken{{Name: "chatops", Token: "op-token", Role: RoleOperator}}
+	code, _ := do(t, New(cfg, ""), http.MethodPost, "/api/restore", "op-token", `{"commit":"HEAD"}`)
+	assert.Equal(t, http.StatusNotImplemented, code)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.ServeConfig{}))
+	assert.Error(t, Validate(&config.ServeConfig{Tokens: []config.APIToken{{Name: "x", Token: "t", Role: "admin"}}}))
+	assert.Error(t, Validate(&config.ServeConfig{Tokens: []config.APIToken{{Name: "x", Role: RoleViewer}}}))
+}
+
+func TestMetrics(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Snapshot.OutputDir = t.TempDir()
+	cfg.Watch.Schedule = "0 * * * *"
+	cfg.Watch.Timezone = "UTC"
+	start := time.Now().UTC().Truncate(time.Hour).Add(-10 * time.Hour)
+	commitSnapshot(t, cfg, start.Add(time.Minute), deployment(1))
+	commitSnapshot(t, cfg, start.Add(time.Hour+time.Minute), deployment(2))
+
+	rec := httptest.NewRecorder()
+	New(cfg, "").Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "gitops_time_machine_snapshots 2\n")
+	assert.Contains(t, body, "gitops_time_machine_snapshot_gaps 1\n")
+	assert.Contains(t, body, "gitops_time_machine_snapshot_gap_ongoing 1\n")
+	assert.Regexp(t, `gitops_time_machine_missed_snapshots (8|9)\n`, body)
+}
@@ -0,0 +1,193 @@
+package snapshotter
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+const (
+	// blobDir holds externalized values, named by their SHA-256 digest.
+	blobDir = "_blobs"
+	// blobRefPrefix marks a manifest value that lives in blobDir.
+	blobRefPrefix = "gtm-blob:sha256:"
+)
+
+// blobFields are the value maps eligible for externalization, per kind.
+var blobFields = map[string][]string{
+	"ConfigMap": {"data", "binaryData"},
+	"Secret":    {"data", "stringData"},
+}
+
+// externalizeBlobs moves oversized or binary values of ConfigMaps and Secrets
+// into content-addressed files and returns a copy of the resource whose
+// manifest references them. The input resource is not modified.
+func (s *Snapshotter) externalizeBlobs(resource types.Resource) (types.Resource, error) {
+	fields, ok := blobFields[resource.Kind]
+	if !ok || s.opts.BlobThreshold <= 0 {
+		return resource, nil
+	}
+
+	if resource.Raw != nil {
+		raw := make(map[string]interface{}, len(resource.Raw))
+		for k, v := range resource.Raw {
+			raw[k] = v
+		}
+		resource.Raw = raw
+	}
+
+	for _, field := range fields {
+		var values map[string]interface{}
+		if resource.Raw != nil {
+			values, _ = resource.Raw[field].(map[string]interface{})
+		} else if field == "data" {
+			values = resource.Data
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		// Secret data is base64, so its values are decoded to tell
+		// whether they are binary
+		encoded := resource.Kind == "Secret" && field == "data"
+		replaced, err := s.externalizeValues(values, field == "binaryData", encoded)
+		if err != nil {
+			return resource, err
+		}
+
+		if resource.Raw != nil {
+			resource.Raw[field] = replaced
+		}
+		if field == "data" {
+			resource.Data = replaced
+		}
+	}
+
+	return resource, nil
+}
+
+// externalizeValues writes qualifying values to blob files and returns a copy
+// of values with references in their place. Values are stored as they
+// appear in the manifest; encoded values are only decoded for the binary
+// check.
+func (s *Snapshotter) externalizeValues(values map[string]interface{}, binary, encoded bool) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		str, ok := v.(string)
+		if !ok || !(binary || len(str) > s.opts.BlobThreshold || isBinary(str, encoded)) {
+			out[k] = v
+			continue
+		}
+
+		ref, err := s.writeBlob([]byte(str))
+		if err != nil {
+			return nil, err
+		}
+		out[k] = ref
+	}
+	return out, nil
+}
+
+// isBinary reports whether a value is not valid UTF-8, after base64 decoding
+// it if encoded. An encoded value that does not decode is checked as is.
+func isBinary(value string, encoded bool) bool {
+	if encoded {
+		if decoded, err := base64.StdEncoding.DecodeString(value); err == nil {
+			return !utf8.Valid(decoded)
+		}
+	}
+	return !utf8.ValidString(value)
+}
+
+// writeBlob stores content under its digest and returns the reference string.
+func (s *Snapshotter) writeBlob(content []byte) (string, error) {
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	dir := filepath.Join(s.outputDir, blobDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	path := filepath.Join(dir, digest)
+	if _, err := os.Stat(path); err == nil {
+		return blobRefPrefix + digest, nil
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write blob %s: %w", digest, err)
+	}
+	return blobRefPrefix + digest, nil
+}
+
+// BlobReader returns the content of an externalized value by its digest.
+type BlobReader func(digest string) ([]byte, error)
+
+// BlobPath returns the slash-separated path of a blob relative to the snapshot root.
+func BlobPath(digest string) string {
+	return blobDir + "/" + digest
+}
+
+// BlobRoot returns the snapshot root whose blobs a resource file references,
+// given the file's slash-separated path. Resource files live at
+// <root>/<namespace>/<kind>/<name>.yaml, and partitioned and fleet snapshots
+// nest whole snapshots, blobs included, in directories.
+func BlobRoot(filePath string) string {
+	return path.Dir(path.Dir(path.Dir(filePath)))
+}
+
+// blobReader returns a reader for the blobs of the snapshot holding the
+// resource file at filePath, which is inside the snapshot directory.
+func (s *Snapshotter) blobReader(filePath string) BlobReader {
+	root := filepath.Dir(filepath.Dir(filepath.Dir(filePath)))
+	return func(digest string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(BlobPath(digest))))
+	}
+}
+
+// resolveBlobs replaces blob references in a decoded resource with their content.
+func resolveBlobs(resource *types.Resource, readBlob BlobReader) error {
+	fields, ok := blobFields[resource.Kind]
+	if !ok {
+		return nil
+	}
+
+	for _, field := range fields {
+		if resource.Raw != nil {
+			if values, ok := resource.Raw[field].(map[string]interface{}); ok {
+				if err := resolveValues(values, readBlob); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if resource.Data != nil {
+		return resolveValues(resource.Data, readBlob)
+	}
+	return nil
+}
+
+// resolveValues replaces references in values, in place.
+func resolveValues(values map[string]interface{}, readBlob BlobReader) error {
+	for k, v := range values {
+		str, ok := v.(string)
+		if !ok || !strings.HasPrefix(str, blobRefPrefix) {
+			continue
+		}
+
+		digest := strings.TrimPrefix(str, blobRefPrefix)
+		content, err := readBlob(digest)
+		if err != nil {
+			return fmt.Errorf("failed to read blob %s: %w", digest, err)
+		}
+		values[k] = string(content)
+	}
+	return nil
+}
@@ -2,27 +2,88 @@
 package snapshotter
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
+// CompressionGzip compresses large resource files with gzip.
+const CompressionGzip = "gzip"
+
+// gzipSuffix is appended to the file name of compressed resources.
+const gzipSuffix = ".gz"
+
+// configDir holds the configuration the snapshot was taken with.
+const configDir = "_config"
+
+// ConfigPath is the slash-separated path of the configuration written with
+// Options.Config, relative to the snapshot root.
+const ConfigPath = configDir + "/config.yaml"
+
+// Options tunes how resources are serialized to disk.
+type Options struct {
+	// Compression is "" (none) or "gzip".
+	Compression string
+	// CompressionThreshold is the encoded size in bytes above which a
+	// resource file is compressed.
+	CompressionThreshold int
+	// BlobThreshold is the value size in bytes above which ConfigMap and
+	// Secret values are moved into _blobs/. Binary values are always moved.
+	// Zero disables externalization.
+	BlobThreshold int
+	// OnWrite, if set, is called after each resource file is written.
+	OnWrite func()
+	// Config, if set, is written to ConfigPath at the snapshot root.
+	Config []byte
+	// Logger receives the snapshotter's log output. Nil uses the standard
+	// logrus logger.
+	Logger log.FieldLogger
+}
+
 // Snapshotter writes resource snapshots to disk in an organized directory structure.
 type Snapshotter struct {
 	outputDir string
+	opts      Options
 }
 
 // New creates a new Snapshotter that writes to the given directory.
 func New(outputDir string) *Snapshotter {
-	return &Snapshotter{outputDir: outputDir}
+	return NewWithOptions(outputDir, Options{})
+}
+
+// NewWithOptions creates a new Snapshotter with explicit serialization options.
+func NewWithOptions(outputDir string, opts Options) *Snapshotter {
+	if opts.Logger == nil {
+		opts.Logger = log.StandardLogger()
+	}
+	return &Snapshotter{outputDir: outputDir, opts: opts}
 }
 
-// Write persists a ResourceSnapshot to disk.
+// Write persists a ResourceSnapshot to disk. A partial snapshot (one with
+// Metadata.Scope) replaces only the resource files in its scope; the files
+// of other resources are kept as the last snapshot that covered them left
+// them, so history does not show them as removed.
 //
 // Directory structure:
 //
@@ -34,11 +95,13 @@ func New(outputDir string) *Snapshotter {
 //	  _cluster/
 //	    <kind>/
 //	      <name>.yaml
+//	  _blobs/
+//	    <sha256>
 func (s *Snapshotter) Write(snapshot *types.ResourceSnapshot) error {
-	log.WithField("outputDir", s.outputDir).Info("writing snapshot to disk")
+	s.opts.Logger.WithField("outputDir", s.outputDir).Info("writing snapshot to disk")
 
 	// Clean the output directory (except .git)
-	if err := s.cleanDirectory(); err != nil {
+	if err := s.clean(snapshot.Metadata.Scope); err != nil {
 		return fmt.Errorf("failed to clean output directory: %w", err)
 	}
 
@@ -46,49 +109,205 @@ func (s *Snapshotter) Write(snapshot *types.ResourceSnapshot) error {
 	if err := s.writeMetadata(snapshot); err != nil {
 		return fmt.Errorf("failed to write metadata: %w", err)
 	}
+	if err := s.writeConfig(); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
 
 	// Write each resource
 	for _, resource := range snapshot.Resources {
 		if err := s.writeResource(resource); err != nil {
-			log.WithError(err).WithField("resource", resource.FullName()).Warn("failed to write resource")
+			s.opts.Logger.WithError(err).WithField("resource", resource.FullName()).Warn("failed to write resource")
 			continue
 		}
+		if s.opts.OnWrite != nil {
+			s.opts.OnWrite()
+		}
+	}
+
+	s.opts.Logger.WithField("resources", len(snapshot.Resources)).Info("snapshot written to disk")
+	return nil
+}
+
+// WritePartitioned persists a snapshot split into top-level directories, one
+// per partition key returned by partitionOf. Each partition gets its own
+// _metadata.yaml, and the root keeps metadata for the whole snapshot so
+// history continues to report totals.
+//
+// Directory structure:
+//
+//	<outputDir>/
+//	  _metadata.yaml
+//	  <partition>/
+//	    _metadata.yaml
+//	    <namespace>/<kind>/<name>.yaml
+func (s *Snapshotter) WritePartitioned(snapshot *types.ResourceSnapshot, partitionOf func(types.Resource) string) error {
+	s.opts.Logger.WithField("outputDir", s.outputDir).Info("writing partitioned snapshot to disk")
+
+	if err := s.clean(snapshot.Metadata.Scope); err != nil {
+		return fmt.Errorf("failed to clean output directory: %w", err)
+	}
+	if err := s.writeMetadata(snapshot); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	if err := s.writeConfig(); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	partitions := make(map[string]*types.ResourceSnapshot)
+	var keys []string
+	for _, resource := range snapshot.Resources {
+		key := PartitionDir(partitionOf(resource))
+		part, ok := partitions[key]
+		if !ok {
+			part = &types.ResourceSnapshot{Metadata: snapshot.Metadata}
+			part.Metadata.Namespaces = nil
+			part.Metadata.ContentHash = ""
+			partitions[key] = part
+			keys = append(keys, key)
+		}
+		part.Resources = append(part.Resources, resource)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		part := partitions[key]
+		part.UpdateCounts()
+		part.Metadata.Namespaces = namespacesOf(part.Resources)
+
+		if err := s.nested(key).Write(part); err != nil {
+			return fmt.Errorf("failed to write partition %s: %w", key, err)
+		}
+	}
+
+	s.opts.Logger.WithField("partitions", len(keys)).Info("partitioned snapshot written to disk")
+	return nil
+}
+
+// ContentHash returns a digest of what Write, or WritePartitioned with
+// partitionOf if it is not nil, would write for the snapshot, leaving out
+// the timestamps and counts in its metadata. Equal hashes mean writing one
+// snapshot over the other changes nothing but _metadata.yaml.
+func (s *Snapshotter) ContentHash(snapshot *types.ResourceSnapshot, partitionOf func(types.Resource) string) (string, error) {
+	files := make([]string, len(snapshot.Resources))
+	order := make([]int, len(snapshot.Resources))
+	for i, res := range snapshot.Resources {
+		files[i] = ResourcePath(res.Namespace, res.Kind, res.Name)
+		if partitionOf != nil {
+			files[i] = path.Join(PartitionDir(partitionOf(res)), files[i])
+		}
+		order[i] = i
+	}
+	// Later resources overwrite earlier ones with the same path, as in Write
+	sort.SliceStable(order, func(a, b int) bool { return files[order[a]] < files[order[b]] })
+
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s\x00%s\x00%s\x00%s\x00%d\x00%d\x00", types.SchemaVersion,
+		snapshot.Metadata.ClusterName, snapshot.Metadata.Context,
+		s.opts.Compression, s.opts.CompressionThreshold, s.opts.BlobThreshold)
+	fmt.Fprintf(sum, "%d\x00", len(s.opts.Config))
+	sum.Write(s.opts.Config)
+	enc := json.NewEncoder(sum)
+	for _, i := range order {
+		fmt.Fprintf(sum, "%s\x00", files[i])
+		if err := enc.Encode(snapshot.Resources[i]); err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", snapshot.Resources[i].FullName(), err)
+		}
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// WriteFleet persists one snapshot per cluster, each in a top-level directory
+// named after the cluster, and root metadata with the fleet totals. The
+// directories of the clusters listed in keep (e.g. ones that could not be
+// collected) are left as they are.
+//
+// Directory structure:
+//
+//	<outputDir>/
+//	  _metadata.yaml
+//	  <cluster>/
+//	    _metadata.yaml
+//	    <namespace>/<kind>/<name>.yaml
+func (s *Snapshotter) WriteFleet(fleet *types.ResourceSnapshot, clusters map[string]*types.ResourceSnapshot, keep []string) error {
+	s.opts.Logger.WithField("outputDir", s.outputDir).Info("writing fleet snapshot to disk")
+
+	if err := s.cleanDirectory(keep...); err != nil {
+		return fmt.Errorf("failed to clean output directory: %w", err)
+	}
+	if err := s.writeMetadata(fleet); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	if err := s.writeConfig(); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+
+	names := make([]string, 0, len(clusters))
+	for name := range clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := s.nested(name).Write(clusters[name]); err != nil {
+			return fmt.Errorf("failed to write cluster %s: %w", name, err)
+		}
 	}
 
-	log.WithField("resources", len(snapshot.Resources)).Info("snapshot written to disk")
+	s.opts.Logger.WithField("clusters", len(names)).Info("fleet snapshot written to disk")
 	return nil
 }
 
 // Read loads a snapshot from the disk directory structure.
 func (s *Snapshotter) Read() (*types.ResourceSnapshot, error) {
+	return s.ReadContext(context.Background())
+}
+
+// ReadContext is Read, stopping before the next file once ctx is cancelled.
+func (s *Snapshotter) ReadContext(ctx context.Context) (*types.ResourceSnapshot, error) {
 	metadataPath := filepath.Join(s.outputDir, "_metadata.yaml")
 	data, err := os.ReadFile(metadataPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read metadata: %w in %s", types.ErrNoSnapshots, s.outputDir)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
 
-	snapshot := &types.ResourceSnapshot{}
-	if err := yaml.Unmarshal(data, &snapshot.Metadata); err != nil {
+	var metadata metadataFile
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
+	if err := types.CheckSchemaVersion(metadata.APIVersion); err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	snapshot := &types.ResourceSnapshot{
+		APIVersion: types.SchemaVersion,
+		Metadata:   metadata.SnapshotMetadata,
+	}
 
-	// Walk the directory and read all resource files
-	err = filepath.Walk(s.outputDir, func(path string, info os.FileInfo, err error) error {
+	// Walk the directory and read all resource files, reusing one reader's
+	// buffers across them
+	var reader resourceReader
+	err = filepath.WalkDir(s.outputDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() || info.Name() == "_metadata.yaml" || !strings.HasSuffix(info.Name(), ".yaml") {
+		if d.IsDir() && d.Name() == configDir {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || d.Name() == "_metadata.yaml" || !isResourceFile(d.Name()) {
 			return nil
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-		resData, err := os.ReadFile(path)
+		resource, err := reader.read(path)
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", path, err)
+			return err
 		}
-
-		var resource types.Resource
-		if err := yaml.Unmarshal(resData, &resource); err != nil {
-			return fmt.Errorf("failed to parse %s: %w", path, err)
+		if err := resolveBlobs(&resource, s.blobReader(path)); err != nil {
+			return fmt.Errorf("failed to resolve blobs for %s: %w", path, err)
 		}
 
 		snapshot.Resources = append(snapshot.Resources, resource)
@@ -99,13 +318,84 @@ func (s *Snapshotter) Read() (*types.ResourceSnapshot, error) {
 		return nil, fmt.Errorf("failed to read snapshot: %w", err)
 	}
 
-	snapshot.Metadata.ResourceCount = len(snapshot.Resources)
+	snapshot.UpdateCounts()
 	return snapshot, nil
 }
 
+// Check inspects a written snapshot before it is committed: its metadata
+// must be present and readable, and every file must belong to the
+// snapshot layout (metadata, configuration, blobs, or a resource file
+// under namespace/kind/). It returns the total size of the files.
+func (s *Snapshotter) Check() (int64, error) {
+	data, err := os.ReadFile(filepath.Join(s.outputDir, "_metadata.yaml"))
+	if err != nil {
+		return 0, fmt.Errorf("missing snapshot metadata: %w", err)
+	}
+	var metadata metadataFile
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return 0, fmt.Errorf("unreadable snapshot metadata: %w", err)
+	}
+	if metadata.APIVersion == "" || metadata.Timestamp.IsZero() {
+		return 0, fmt.Errorf("incomplete snapshot metadata: apiVersion and timestamp are required")
+	}
+
+	var size int64
+	err = filepath.WalkDir(s.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.outputDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !inLayout(rel) {
+			return fmt.Errorf("unexpected file %s in the snapshot", rel)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// inLayout reports whether a slash-separated path inside a snapshot is one
+// the snapshotter writes. Partitioned and fleet snapshots nest whole
+// snapshots in directories, so the layout is matched from the end.
+func inLayout(p string) bool {
+	parts := strings.Split(p, "/")
+	name := parts[len(parts)-1]
+	switch {
+	case name == "_metadata.yaml":
+		return true
+	case slices.Contains(parts[:len(parts)-1], blobDir), slices.Contains(parts[:len(parts)-1], configDir):
+		return true
+	default:
+		return IsResourcePath(p) && len(parts) >= 3
+	}
+}
+
+// metadataFile is the on-disk layout of _metadata.yaml.
+type metadataFile struct {
+	APIVersion             string `yaml:"apiVersion"`
+	types.SnapshotMetadata `yaml:",inline"`
+}
+
 // writeMetadata writes the snapshot metadata file.
 func (s *Snapshotter) writeMetadata(snapshot *types.ResourceSnapshot) error {
-	data, err := yaml.Marshal(snapshot.Metadata)
+	data, err := yaml.Marshal(metadataFile{
+		APIVersion:       types.SchemaVersion,
+		SnapshotMetadata: snapshot.Metadata,
+	})
 	if err != nil {
 		return err
 	}
@@ -114,28 +404,43 @@ func (s *Snapshotter) writeMetadata(snapshot *types.ResourceSnapshot) error {
 	return os.WriteFile(metadataPath, data, 0644)
 }
 
+// writeConfig writes Options.Config, if set.
+func (s *Snapshotter) writeConfig() error {
+	if s.opts.Config == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(s.outputDir, configDir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(s.outputDir, filepath.FromSlash(ConfigPath)), s.opts.Config, 0644)
+}
+
+// nested returns a Snapshotter for a partition or cluster directory, which
+// does not get its own copy of the configuration.
+func (s *Snapshotter) nested(dir string) *Snapshotter {
+	opts := s.opts
+	opts.Config = nil
+	return NewWithOptions(filepath.Join(s.outputDir, dir), opts)
+}
+
 // writeResource writes a single resource to its appropriate file path.
 func (s *Snapshotter) writeResource(resource types.Resource) error {
-	// Determine directory: namespace-scoped vs cluster-scoped
-	var dir string
-	if resource.Namespace == "" {
-		dir = filepath.Join(s.outputDir, "_cluster", strings.ToLower(resource.Kind))
-	} else {
-		dir = filepath.Join(s.outputDir, resource.Namespace, strings.ToLower(resource.Kind))
+	resource, err := s.externalizeBlobs(resource)
+	if err != nil {
+		return err
 	}
 
+	// Namespace-scoped and cluster-scoped resources live in separate trees
+	filePath := filepath.Join(s.outputDir, filepath.FromSlash(ResourcePath(resource.Namespace, resource.Kind, resource.Name)))
+	dir := filepath.Dir(filePath)
+
 	// Create directory structure
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	// Sanitize name for filename
-	filename := sanitizeFilename(resource.Name) + ".yaml"
-	filePath := filepath.Join(dir, filename)
-
 	// Marshal to YAML (use Raw if available for fidelity, otherwise struct)
 	var data []byte
-	var err error
 	if resource.Raw != nil {
 		data, err = yaml.Marshal(resource.Raw)
 	} else {
@@ -145,11 +450,292 @@ func (s *Snapshotter) writeResource(resource types.Resource) error {
 		return fmt.Errorf("failed to marshal resource: %w", err)
 	}
 
+	if s.shouldCompress(data) {
+		data, err = compress(s.opts.Compression, data)
+		if err != nil {
+			return fmt.Errorf("failed to compress resource: %w", err)
+		}
+		filePath += gzipSuffix
+	}
+
 	return os.WriteFile(filePath, data, 0644)
 }
 
-// cleanDirectory removes all content except .git directory.
-func (s *Snapshotter) cleanDirectory() error {
+// shouldCompress reports whether an encoded resource exceeds the compression threshold.
+func (s *Snapshotter) shouldCompress(data []byte) bool {
+	return s.opts.Compression != "" && len(data) > s.opts.CompressionThreshold
+}
+
+// compress encodes data with the given algorithm.
+func compress(algorithm string, data []byte) ([]byte, error) {
+	if algorithm != CompressionGzip {
+		return nil, fmt.Errorf("unsupported compression %q (use %q)", algorithm, CompressionGzip)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isResourceFile reports whether a file name holds a (possibly compressed) resource.
+func isResourceFile(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yaml"+gzipSuffix)
+}
+
+// IsResourcePath reports whether a slash-separated path inside a snapshot
+// holds a resource, as opposed to metadata, configuration, or an
+// externalized blob.
+func IsResourcePath(p string) bool {
+	name := path.Base(p)
+	return name != "_metadata.yaml" && isResourceFile(name) &&
+		!strings.Contains("/"+p, "/"+blobDir+"/") && !strings.Contains("/"+p, "/"+configDir+"/")
+}
+
+// ResourcePath returns the slash-separated path of a resource's file relative
+// to the snapshot root, without the compression suffix.
+func ResourcePath(namespace, kind, name string) string {
+	if namespace == "" {
+		namespace = types.ClusterScope
+	}
+	return path.Join(namespace, strings.ToLower(kind), sanitizeFilename(name)+".yaml")
+}
+
+// ResourcePaths returns the possible file paths of a resource: plain and compressed.
+func ResourcePaths(namespace, kind, name string) []string {
+	p := ResourcePath(namespace, kind, name)
+	return []string{p, p + gzipSuffix}
+}
+
+// DecodeFile parses resource file content that was read from somewhere other
+// than the working tree, such as a past commit. The file path selects
+// decompression; readBlob resolves externalized values.
+func DecodeFile(filePath string, data []byte, readBlob BlobReader) (types.Resource, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if strings.HasSuffix(filePath, gzipSuffix) {
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			return types.Resource{}, fmt.Errorf("failed to decompress %s: %w", filePath, err)
+		}
+		defer zr.Close()
+		src = zr
+	}
+	resource, err := decodeResource(src)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if err := resolveBlobs(&resource, readBlob); err != nil {
+		return types.Resource{}, fmt.Errorf("failed to resolve blobs for %s: %w", filePath, err)
+	}
+	return resource, nil
+}
+
+// resourceReader streams resource files from disk into the decoder. Its
+// read and gzip buffers are kept between files, which matters when reading
+// thousands of them. The zero value is ready to use; it is not safe for
+// concurrent use.
+type resourceReader struct {
+	br *bufio.Reader
+	zr *gzip.Reader
+	h  hash.Hash
+}
+
+// read decodes the resource file at path, transparently decompressing it.
+// The file's git blob hash is computed on the way, so that a resource read
+// from the working tree matches the same file read from a commit.
+func (r *resourceReader) read(path string) (types.Resource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if r.h == nil {
+		r.h = sha1.New()
+	}
+	r.h.Reset()
+	fmt.Fprintf(r.h, "blob %d\x00", info.Size())
+	hashed := io.TeeReader(f, r.h)
+	if r.br == nil {
+		r.br = bufio.NewReaderSize(hashed, 32<<10)
+	} else {
+		r.br.Reset(hashed)
+	}
+	var src io.Reader = r.br
+	if strings.HasSuffix(path, gzipSuffix) {
+		if r.zr == nil {
+			r.zr, err = gzip.NewReader(r.br)
+		} else {
+			err = r.zr.Reset(r.br)
+		}
+		if err != nil {
+			return types.Resource{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		src = r.zr
+	}
+
+	resource, err := decodeResource(src)
+	if err != nil {
+		return types.Resource{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// The decoder stops after the first document; hash the rest too
+	if _, err := io.Copy(io.Discard, r.br); err != nil {
+		return types.Resource{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	resource.ContentHash = hex.EncodeToString(r.h.Sum(nil))
+	return resource, nil
+}
+
+// decodeResource parses a resource file. Files written from a live object
+// hold the raw Kubernetes layout (name and namespace under metadata), while
+// resources without a raw object are written in the flat Resource layout.
+//
+// The file is parsed once into a node tree, which is then converted by
+// hand: yaml.v3 decodes into interface{} values through reflection, which
+// dominates the cost for large objects such as CRDs.
+func decodeResource(r io.Reader) (types.Resource, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return types.Resource{}, nil
+		}
+		return types.Resource{}, err
+	}
+
+	obj, err := decodeObject(&doc)
+	if err != nil {
+		return types.Resource{}, err
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		var resource types.Resource
+		err := doc.Decode(&resource)
+		return resource, err
+	}
+
+	resource := types.Resource{
+		APIVersion:  stringValue(obj["apiVersion"]),
+		Kind:        stringValue(obj["kind"]),
+		Namespace:   stringValue(metadata["namespace"]),
+		Name:        stringValue(metadata["name"]),
+		Labels:      StringMap(metadata["labels"]),
+		Annotations: StringMap(metadata["annotations"]),
+		Raw:         obj,
+		UID:         stringValue(metadata["uid"]),
+		Managers:    types.FieldManagers(obj),
+	}
+	switch created := metadata["creationTimestamp"].(type) {
+	case string:
+		resource.CreationTimestamp = created
+	case time.Time:
+		resource.CreationTimestamp = created.UTC().Format(time.RFC3339)
+	}
+	if spec, ok := obj["spec"].(map[string]interface{}); ok {
+		resource.Spec = spec
+	}
+	if data, ok := obj["data"].(map[string]interface{}); ok {
+		resource.Data = data
+	}
+	return resource, nil
+}
+
+// errSlowPath is returned by decodeNode for documents it leaves to yaml.v3.
+var errSlowPath = errors.New("node needs the yaml decoder")
+
+// decodeObject converts a parsed document to a map, as yaml.Unmarshal would.
+func decodeObject(doc *yaml.Node) (map[string]interface{}, error) {
+	v, err := decodeNode(doc)
+	if err == errSlowPath {
+		var obj map[string]interface{}
+		err := doc.Decode(&obj)
+		return obj, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := v.(map[string]interface{})
+	return obj, nil
+}
+
+// decodeNode converts mappings, sequences and plain strings directly and
+// leaves other scalars to yaml.v3, so that their values (ints, bools,
+// timestamps) match what it would produce. Aliases, merge keys and
+// non-string keys return errSlowPath.
+func decodeNode(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return decodeNode(n.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if key.Kind != yaml.ScalarNode || key.Tag != "!!str" {
+				return nil, errSlowPath
+			}
+			v, err := decodeNode(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			v, err := decodeNode(item)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = v
+		}
+		return s, nil
+	case yaml.ScalarNode:
+		if n.Tag == "!!str" {
+			return n.Value, nil
+		}
+		var v interface{}
+		err := n.Decode(&v)
+		return v, err
+	default:
+		return nil, errSlowPath
+	}
+}
+
+// stringValue returns v as a string, or "" if it is not one.
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// StringMap converts a decoded YAML mapping, such as labels or
+// annotations, into a map of strings.
+func StringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		out[k] = fmt.Sprint(val)
+	}
+	return out
+}
+
+// cleanDirectory removes all content except the .git directory and the
+// entries named in keep.
+func (s *Snapshotter) cleanDirectory(keep ...string) error {
 	if err := os.MkdirAll(s.outputDir, 0755); err != nil {
 		return err
 	}
@@ -160,7 +746,7 @@ func (s *Snapshotter) cleanDirectory() error {
 	}
 
 	for _, entry := range entries {
-		if entry.Name() == ".git" {
+		if entry.Name() == ".git" || slices.Contains(keep, entry.Name()) {
 			continue
 		}
 		path := filepath.Join(s.outputDir, entry.Name())
@@ -172,6 +758,70 @@ func (s *Snapshotter) cleanDirectory() error {
 	return nil
 }
 
+// clean empties the output directory for a snapshot, or for a partial
+// snapshot removes only the resource files in its scope, in every
+// partition.
+func (s *Snapshotter) clean(scope *types.SnapshotScope) error {
+	if scope == nil {
+		return s.cleanDirectory()
+	}
+	return filepath.WalkDir(s.outputDir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == s.outputDir {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == blobDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.outputDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !IsResourcePath(rel) {
+			return nil
+		}
+		// Resource files live at [<partition>/]<namespace>/<kind>/<name>.yaml
+		kindDir := path.Dir(rel)
+		namespace := path.Base(path.Dir(kindDir))
+		if namespace == types.ClusterScope {
+			namespace = ""
+		}
+		if !scope.Covers(namespace, path.Base(kindDir)) {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// namespacesOf returns the sorted, distinct namespaces of the given resources.
+func namespacesOf(resources []types.Resource) []string {
+	seen := make(map[string]bool)
+	var namespaces []string
+	for _, res := range resources {
+		if res.Namespace != "" && !seen[res.Namespace] {
+			seen[res.Namespace] = true
+			namespaces = append(namespaces, res.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// PartitionDir returns the top-level directory WritePartitioned writes a
+// partition key to.
+func PartitionDir(key string) string {
+	return sanitizeFilename(key)
+}
+
 // sanitizeFilename replaces characters that are invalid in filenames.
 func sanitizeFilename(name string) string {
 	replacer := strings.NewReplacer(
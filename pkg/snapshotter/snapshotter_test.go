@@ -1,14 +1,20 @@
 package snapshotter
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestWriteAndRead(t *testing.T) {
@@ -62,6 +68,8 @@ func TestWriteAndRead(t *testing.T) {
 
 	assert.Equal(t, "test-cluster", readSnap.Metadata.ClusterName)
 	assert.Equal(t, 2, readSnap.Metadata.ResourceCount)
+	assert.Equal(t, map[string]int{"Deployment": 1, "Service": 1}, readSnap.Metadata.KindCounts)
+	assert.Equal(t, map[string]int{"default": 1, "monitoring": 1}, readSnap.Metadata.NamespaceCounts)
 }
 
 func TestWriteClusterScopedResources(t *testing.T) {
@@ -129,3 +137,553 @@ func TestSanitizeFilename(t *testing.T) {
 		assert.Equal(t, tc.expected, result, "sanitizeFilename(%q)", tc.input)
 	}
 }
+
+func TestWritePartitioned(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := New(tmpDir)
+
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{
+			Timestamp:     time.Now().UTC(),
+			ClusterName:   "test-cluster",
+			ResourceCount: 2,
+		},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "payments", Name: "api"},
+			{Kind: "Service", Namespace: "monitoring", Name: "grafana"},
+		},
+	}
+
+	teams := map[string]string{"payments": "payments", "monitoring": "platform"}
+	err := snap.WritePartitioned(snapshot, func(r types.Resource) string {
+		return teams[r.Namespace]
+	})
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(tmpDir, "_metadata.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "payments", "_metadata.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "payments", "payments", "deployment", "api.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "platform", "monitoring", "service", "grafana.yaml"))
+
+	// Each partition reads back as its own snapshot
+	team, err := New(filepath.Join(tmpDir, "platform")).Read()
+	require.NoError(t, err)
+	assert.Equal(t, 1, team.Metadata.ResourceCount)
+	assert.Equal(t, []string{"monitoring"}, team.Metadata.Namespaces)
+
+	// Partition metadata records its own composition
+	metadata, err := os.ReadFile(filepath.Join(tmpDir, "platform", "_metadata.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "kindCounts:\n    Service: 1")
+
+	// The root still reads the whole snapshot
+	all, err := snap.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 2, all.Metadata.ResourceCount)
+}
+
+func TestWrite_PartialKeepsOutOfScope(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := New(tmpDir)
+
+	full := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{Kind: "Deployment", Namespace: "team-a", Name: "api"},
+			{Kind: "Deployment", Namespace: "team-a", Name: "old"},
+			{Kind: "ConfigMap", Namespace: "team-a", Name: "settings"},
+			{Kind: "Deployment", Namespace: "team-b", Name: "web"},
+			{Kind: "ClusterRole", Name: "reader"},
+		},
+	}
+	require.NoError(t, snap.Write(full))
+
+	partial := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{
+			Timestamp: time.Now().UTC(),
+			Scope:     &types.SnapshotScope{Namespaces: []string{"team-a"}, Kinds: []string{"Deployment"}},
+		},
+		Resources: []types.Resource{{Kind: "Deployment", Namespace: "team-a", Name: "api"}},
+	}
+	require.NoError(t, snap.Write(partial))
+
+	assert.FileExists(t, filepath.Join(tmpDir, "team-a", "deployment", "api.yaml"))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "team-a", "deployment", "old.yaml"), "in-scope resources that are gone are removed")
+	assert.FileExists(t, filepath.Join(tmpDir, "team-a", "configmap", "settings.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "team-b", "deployment", "web.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "_cluster", "clusterrole", "reader.yaml"))
+
+	// Partitioned snapshots keep out-of-scope files in every partition
+	partitionOf := func(r types.Resource) string { return r.Namespace }
+	require.NoError(t, snap.WritePartitioned(full, partitionOf))
+	require.NoError(t, snap.WritePartitioned(partial, partitionOf))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "team-a", "team-a", "deployment", "old.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "team-a", "team-a", "configmap", "settings.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "team-b", "team-b", "deployment", "web.yaml"))
+}
+
+func TestWriteFleet(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := New(tmpDir)
+
+	// A stale single-cluster layout is replaced; a failed cluster's directory is kept
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "default", "service"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "ap", "prod", "deployment"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "ap", "prod", "deployment", "api.yaml"), []byte("kind: Deployment\n"), 0644))
+
+	now := time.Now().UTC()
+	clusters := map[string]*types.ResourceSnapshot{
+		"eu": {
+			Metadata:  types.SnapshotMetadata{Timestamp: now, Context: "prod-eu"},
+			Resources: []types.Resource{{Kind: "Deployment", Namespace: "prod", Name: "api"}},
+		},
+		"us": {
+			Metadata:  types.SnapshotMetadata{Timestamp: now, Context: "prod-us"},
+			Resources: []types.Resource{{Kind: "Deployment", Namespace: "prod", Name: "api"}},
+		},
+	}
+	fleet := &types.ResourceSnapshot{Metadata: types.SnapshotMetadata{Timestamp: now, ResourceCount: 2}}
+
+	require.NoError(t, snap.WriteFleet(fleet, clusters, []string{"ap"}))
+
+	assert.NoDirExists(t, filepath.Join(tmpDir, "default"))
+	assert.FileExists(t, filepath.Join(tmpDir, "ap", "prod", "deployment", "api.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "eu", "prod", "deployment", "api.yaml"))
+	assert.FileExists(t, filepath.Join(tmpDir, "us", "prod", "deployment", "api.yaml"))
+
+	eu, err := New(filepath.Join(tmpDir, "eu")).Read()
+	require.NoError(t, err)
+	assert.Equal(t, "prod-eu", eu.Metadata.Context)
+	assert.Len(t, eu.Resources, 1)
+
+	// The root reads every cluster, including the kept one
+	all, err := snap.Read()
+	require.NoError(t, err)
+	assert.Equal(t, 3, all.Metadata.ResourceCount)
+}
+
+func TestContentHash(t *testing.T) {
+	resources := func(replicas int) []types.Resource {
+		return []types.Resource{
+			{Kind: "Deployment", Namespace: "prod", Name: "api", Spec: map[string]interface{}{"replicas": replicas}},
+			{Kind: "Namespace", Name: "prod"},
+		}
+	}
+	s := New(t.TempDir())
+	hash := func(snapshot *types.ResourceSnapshot, partitionOf func(types.Resource) string) string {
+		h, err := s.ContentHash(snapshot, partitionOf)
+		require.NoError(t, err)
+		return h
+	}
+
+	base := hash(&types.ResourceSnapshot{Resources: resources(2)}, nil)
+	later := &types.ResourceSnapshot{
+		Metadata:  types.SnapshotMetadata{Timestamp: time.Now(), ResourceCount: 2},
+		Resources: resources(2),
+	}
+	assert.Equal(t, base, hash(later, nil), "timestamps and counts are left out")
+
+	later.Resources[0], later.Resources[1] = later.Resources[1], later.Resources[0]
+	assert.Equal(t, base, hash(later, nil), "the order resources are collected in does not matter")
+
+	assert.NotEqual(t, base, hash(&types.ResourceSnapshot{Resources: resources(3)}, nil))
+	assert.NotEqual(t, base, hash(&types.ResourceSnapshot{Resources: resources(2)}, func(types.Resource) string { return "team" }))
+
+	compressed := NewWithOptions(t.TempDir(), Options{Compression: CompressionGzip})
+	h, err := compressed.ContentHash(&types.ResourceSnapshot{Resources: resources(2)}, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, base, h, "serialization options change the files")
+}
+
+func TestWrite_Config(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := []byte("snapshot:\n  include_config: true\n")
+	s := NewWithOptions(tmpDir, Options{Config: config})
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "prod", Name: "settings"},
+	}}
+
+	require.NoError(t, s.WritePartitioned(snapshot, func(types.Resource) string { return "team" }))
+	data, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(ConfigPath)))
+	require.NoError(t, err)
+	assert.Equal(t, config, data)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "team", filepath.FromSlash(ConfigPath)), "only at the root")
+
+	read, err := s.Read()
+	require.NoError(t, err)
+	assert.Len(t, read.Resources, 1, "the configuration is not a resource")
+	assert.False(t, IsResourcePath("team/"+ConfigPath))
+	assert.False(t, IsResourcePath(ConfigPath))
+
+	without, err := New(tmpDir).ContentHash(snapshot, nil)
+	require.NoError(t, err)
+	with, err := s.ContentHash(snapshot, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, without, with, "a configuration change is written")
+}
+
+func TestWriteAndRead_Compressed(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := NewWithOptions(tmpDir, Options{Compression: CompressionGzip, CompressionThreshold: 64})
+
+	large := strings.Repeat("x", 1024)
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "big", Data: map[string]interface{}{"blob": large}},
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "small"},
+		},
+	}
+
+	require.NoError(t, snap.Write(snapshot))
+
+	assert.FileExists(t, filepath.Join(tmpDir, "default", "configmap", "big.yaml.gz"))
+	assert.FileExists(t, filepath.Join(tmpDir, "default", "configmap", "small.yaml"))
+
+	readSnap, err := snap.Read()
+	require.NoError(t, err)
+	require.Len(t, readSnap.Resources, 2)
+
+	for _, res := range readSnap.Resources {
+		if res.Name == "big" {
+			assert.Equal(t, large, res.Data["blob"])
+		}
+
+		// The content hash is the file's git blob hash, compressed or not
+		for _, p := range ResourcePaths(res.Namespace, res.Kind, res.Name) {
+			if data, err := os.ReadFile(filepath.Join(tmpDir, p)); err == nil {
+				assert.Equal(t, plumbing.ComputeHash(plumbing.BlobObject, data).String(), res.ContentHash, p)
+			}
+		}
+	}
+}
+
+func TestRead_RawLayout(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := New(tmpDir)
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{
+				Kind:      "Deployment",
+				Namespace: "prod",
+				Name:      "api",
+				Raw: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata": map[string]interface{}{
+						"name":              "api",
+						"namespace":         "prod",
+						"labels":            map[string]interface{}{"app": "api"},
+						"uid":               "6f1c2d3e",
+						"creationTimestamp": "2024-01-01T00:00:00Z",
+					},
+					"spec": map[string]interface{}{"replicas": 3},
+				},
+			},
+		},
+	}
+	require.NoError(t, snap.Write(snapshot))
+
+	readSnap, err := snap.Read()
+	require.NoError(t, err)
+	require.Len(t, readSnap.Resources, 1)
+
+	res := readSnap.Resources[0]
+	assert.Equal(t, "prod/Deployment/api", res.FullName())
+	assert.Equal(t, "apps/v1", res.APIVersion)
+	assert.Equal(t, map[string]string{"app": "api"}, res.Labels)
+	assert.Equal(t, 3, res.Spec["replicas"])
+	assert.Equal(t, "6f1c2d3e", res.UID)
+	assert.Equal(t, "2024-01-01T00:00:00Z", res.CreationTimestamp)
+}
+
+func TestDecodeResource_MatchesUnmarshal(t *testing.T) {
+	for _, doc := range []string{
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\ndata:\n  a: \"1\"\n  b: 2\n  c: true\n  d: null\n  e: [1, x, 2.5]\n",
+		"kind: Pod\nmetadata:\n  name: p\n  creationTimestamp: 2024-01-01T00:00:00Z\nspec:\n  base: &b {cpu: 1}\n  merged:\n    <<: *b\n    mem: 2\n",
+		"kind: Pod\nmetadata:\n  name: p\nspec:\n  ports:\n    80: http\n",
+		"kind: Role\nname: flat\nnamespace: prod\n",
+		"",
+	} {
+		var want map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(doc), &want))
+
+		res, err := decodeResource(strings.NewReader(doc))
+		require.NoError(t, err, doc)
+		if _, raw := want["metadata"]; raw {
+			assert.Equal(t, want, res.Raw, doc)
+		} else {
+			assert.Nil(t, res.Raw, doc)
+		}
+	}
+}
+
+func TestWriteAndRead_Blobs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := NewWithOptions(tmpDir, Options{BlobThreshold: 32})
+
+	large := strings.Repeat("certificate-bytes-", 8)
+	raw := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "bundle", "namespace": "default"},
+		"data":       map[string]interface{}{"ca.crt": large, "mode": "strict"},
+	}
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{Kind: "ConfigMap", Namespace: "default", Name: "bundle", Data: raw["data"].(map[string]interface{}), Raw: raw},
+		},
+	}
+
+	require.NoError(t, snap.Write(snapshot))
+
+	// The in-memory snapshot is left untouched
+	assert.Equal(t, large, snapshot.Resources[0].Data["ca.crt"])
+
+	manifest, err := os.ReadFile(filepath.Join(tmpDir, "default", "configmap", "bundle.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manifest), blobRefPrefix)
+	assert.NotContains(t, string(manifest), large)
+
+	entries, err := os.ReadDir(filepath.Join(tmpDir, blobDir))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	readSnap, err := snap.Read()
+	require.NoError(t, err)
+	require.Len(t, readSnap.Resources, 1)
+	assert.Equal(t, large, readSnap.Resources[0].Data["ca.crt"])
+	assert.Equal(t, "strict", readSnap.Resources[0].Data["mode"])
+}
+
+func TestWriteAndRead_BinarySecretData(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := NewWithOptions(tmpDir, Options{BlobThreshold: 1024})
+
+	keystore := base64.StdEncoding.EncodeToString([]byte{0xfe, 0xed, 0xfe, 0xed, 0x00, 0x02})
+	password := base64.StdEncoding.EncodeToString([]byte("changeit"))
+	data := map[string]interface{}{"keystore.jks": keystore, "password": password}
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{Kind: "Secret", Namespace: "default", Name: "tls", Data: data},
+		},
+	}
+
+	require.NoError(t, snap.Write(snapshot))
+
+	manifest, err := os.ReadFile(filepath.Join(tmpDir, "default", "secret", "tls.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(manifest), keystore, "decoded binary values are externalized")
+	assert.Contains(t, string(manifest), password, "decoded text values stay inline")
+
+	readSnap, err := snap.Read()
+	require.NoError(t, err)
+	require.Len(t, readSnap.Resources, 1)
+	assert.Equal(t, keystore, readSnap.Resources[0].Data["keystore.jks"])
+}
+
+func TestWritePartitioned_Blobs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	snap := NewWithOptions(tmpDir, Options{BlobThreshold: 32})
+
+	large := strings.Repeat("certificate-bytes-", 8)
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{Kind: "ConfigMap", Namespace: "payments", Name: "bundle", Data: map[string]interface{}{"ca.crt": large}},
+		},
+	}
+	require.NoError(t, snap.WritePartitioned(snapshot, func(types.Resource) string { return "payments" }))
+	assert.DirExists(t, filepath.Join(tmpDir, "payments", blobDir))
+
+	// Blobs resolve both from the root and from the partition
+	for _, dir := range []string{tmpDir, filepath.Join(tmpDir, "payments")} {
+		readSnap, err := NewWithOptions(dir, Options{BlobThreshold: 32}).Read()
+		require.NoError(t, err, dir)
+		require.Len(t, readSnap.Resources, 1, dir)
+		assert.Equal(t, large, readSnap.Resources[0].Data["ca.crt"], dir)
+	}
+}
+
+func TestRead_SchemaVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := New(tmpDir)
+
+	require.NoError(t, snap.Write(&types.ResourceSnapshot{}))
+	metadata, err := os.ReadFile(filepath.Join(tmpDir, "_metadata.yaml"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(metadata), "apiVersion: gitops-tm/v1\n"))
+
+	// Metadata written before versioning still reads
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "_metadata.yaml"), []byte("clusterName: old\n"), 0644))
+	readSnap, err := snap.Read()
+	require.NoError(t, err)
+	assert.Equal(t, "old", readSnap.Metadata.ClusterName)
+
+	// Metadata from a newer release is refused rather than misread
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "_metadata.yaml"), []byte("apiVersion: gitops-tm/v9\n"), 0644))
+	_, err = snap.Read()
+	assert.ErrorContains(t, err, "unsupported apiVersion")
+}
+
+// benchmarkSnapshot writes a snapshot of n Deployments in raw layout, plus
+// CRDs of about crdBytes each, and returns its directory.
+func benchmarkSnapshot(b *testing.B, n, crds, crdBytes int, opts Options) string {
+	b.Helper()
+	dir := b.TempDir()
+	snapshot := &types.ResourceSnapshot{Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()}}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("app-%d", i)
+		snapshot.Resources = append(snapshot.Resources, types.Resource{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: fmt.Sprintf("ns-%d", i%50), Name: name,
+			Raw: map[string]interface{}{
+				"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]interface{}{
+					"name": name, "namespace": fmt.Sprintf("ns-%d", i%50),
+					"labels":      map[string]interface{}{"app": name, "team": "payments"},
+					"annotations": map[string]interface{}{"deployment.kubernetes.io/revision": "3"},
+				},
+				"spec": map[string]interface{}{
+					"replicas": 3,
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": name}},
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": name}},
+						"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{
+							"name": "app", "image": "registry.example.com/" + name + ":1.2.3",
+							"ports": []interface{}{map[string]interface{}{"containerPort": 8080}},
+							"env": []interface{}{
+								map[string]interface{}{"name": "LOG_LEVEL", "value": "info"},
+								map[string]interface{}{"name": "REGION", "value": "eu-west-1"},
+							},
+						}}},
+					},
+				},
+			},
+		})
+	}
+	for i := 0; i < crds; i++ {
+		properties := map[string]interface{}{}
+		for j := 0; j < crdBytes/200; j++ {
+			properties[fmt.Sprintf("field%d", j)] = map[string]interface{}{
+				"type":        "string",
+				"description": strings.Repeat("Configures the behavior of this field. ", 4),
+			}
+		}
+		name := fmt.Sprintf("widgets%d.example.com", i)
+		snapshot.Resources = append(snapshot.Resources, types.Resource{
+			APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: name,
+			Raw: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition",
+				"metadata": map[string]interface{}{"name": name},
+				"spec": map[string]interface{}{"versions": []interface{}{map[string]interface{}{
+					"name": "v1", "schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+						"type": "object", "properties": properties,
+					}},
+				}}},
+			},
+		})
+	}
+	require.NoError(b, NewWithOptions(dir, opts).Write(snapshot))
+	return dir
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		n    int
+		crds int
+		opts Options
+	}{
+		{name: "10k-files", n: 10000},
+		{name: "10k-files-gzip", n: 10000, opts: Options{Compression: "gzip", CompressionThreshold: 1}},
+		{name: "large-crds", crds: 5},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			dir := benchmarkSnapshot(b, bc.n, bc.crds, 4<<20, bc.opts)
+			snap := New(dir)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				snapshot, err := snap.Read()
+				require.NoError(b, err)
+				require.Len(b, snapshot.Resources, bc.n+bc.crds)
+			}
+		})
+	}
+}
+
+func TestRead_NoSnapshot(t *testing.T) {
+	_, err := New(t.TempDir()).Read()
+	assert.ErrorIs(t, err, types.ErrNoSnapshots)
+}
+
+func TestReadContext_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := New(tmpDir)
+	require.NoError(t, snap.Write(&types.ResourceSnapshot{
+		Metadata:  types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{{Kind: "ConfigMap", Namespace: "default", Name: "app"}},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := snap.ReadContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := NewWithOptions(tmpDir, Options{BlobThreshold: 8})
+	snapshot := &types.ResourceSnapshot{
+		Metadata: types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{
+			{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "nginx"},
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "large", Data: map[string]interface{}{"big": strings.Repeat("x", 64)}},
+			{APIVersion: "v1", Kind: "Namespace", Name: "default"},
+		},
+	}
+	require.NoError(t, snap.Write(snapshot))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+
+	size, err := snap.Check()
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(64), "blobs count towards the size")
+
+	stray := filepath.Join(tmpDir, "default", "notes.txt")
+	require.NoError(t, os.WriteFile(stray, []byte("todo"), 0644))
+	_, err = snap.Check()
+	assert.ErrorContains(t, err, "default/notes.txt")
+	require.NoError(t, os.Remove(stray))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "stray.yaml"), []byte("kind: Pod\n"), 0644))
+	_, err = snap.Check()
+	assert.ErrorContains(t, err, "stray.yaml", "resource files live under namespace/kind/")
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "stray.yaml")))
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, "_metadata.yaml")))
+	_, err = snap.Check()
+	assert.ErrorContains(t, err, "missing snapshot metadata")
+}
+
+func TestCheck_Partitioned(t *testing.T) {
+	tmpDir := t.TempDir()
+	snap := New(tmpDir)
+	snapshot := &types.ResourceSnapshot{
+		Metadata:  types.SnapshotMetadata{Timestamp: time.Now().UTC()},
+		Resources: []types.Resource{{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "shop", Name: "api"}},
+	}
+	require.NoError(t, snap.WritePartitioned(snapshot, func(types.Resource) string { return "payments" }))
+
+	_, err := snap.Check()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+// Package spool keeps a collected snapshot that could not be written or
+// committed (e.g. the disk was full or the repository locked), so that it
+// can be committed later without collecting it from the cluster again.
+package spool
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+)
+
+// Path returns where the snapshot of the repository at repoPath is spooled.
+// It is outside the repository, whose working tree is rewritten by every
+// snapshot, in the user's cache directory if there is one.
+func Path(repoPath string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		abs = repoPath
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, "gitops-time-machine", "spool", hex.EncodeToString(sum[:8])+".json.gz")
+}
+
+// Save spools a snapshot to path, replacing any spooled before.
+func Save(path string, snapshot *types.ResourceSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	// Write next to the spool and rename, so a failed save leaves the
+	// previous spool intact
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".spool-*")
+	if err != nil {
+		return fmt.Errorf("failed to create spool: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	return nil
+}
+
+// Load reads the snapshot spooled at path. The error wraps fs.ErrNotExist
+// if nothing is spooled.
+func Load(path string) (*types.ResourceSnapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+	defer zr.Close()
+
+	// Decoded without ResourceSnapshot.UnmarshalJSON, which would not keep
+	// the numbers; a spool is always of the current schema
+	var spooled struct {
+		APIVersion string                 `json:"apiVersion"`
+		Metadata   types.SnapshotMetadata `json:"metadata"`
+		Resources  []types.Resource       `json:"resources"`
+	}
+	dec := json.NewDecoder(zr)
+	dec.UseNumber()
+	if err := dec.Decode(&spooled); err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+	if spooled.APIVersion != types.SchemaVersion {
+		return nil, fmt.Errorf("spool has schema %q, expected %q", spooled.APIVersion, types.SchemaVersion)
+	}
+	snapshot := types.ResourceSnapshot(spooled)
+	// Numbers come back as the collector's int64 and float64, so the
+	// snapshot is written exactly as it would have been
+	for i := range snapshot.Resources {
+		res := &snapshot.Resources[i]
+		res.Spec = numbers(res.Spec)
+		res.Data = numbers(res.Data)
+		res.Raw = numbers(res.Raw)
+	}
+	return &snapshot, nil
+}
+
+// Remove discards the snapshot spooled at path, if any.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove spool: %w", err)
+	}
+	return nil
+}
+
+// numbers replaces the json.Numbers in a decoded object.
+func numbers(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		m[k] = number(v)
+	}
+	return m
+}
+
+func number(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		return numbers(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = number(v[i])
+		}
+	}
+	return v
+}
@@ -0,0 +1,45 @@
+package spool
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool", "snapshot.json.gz")
+	_, err := Load(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	snapshot := &types.ResourceSnapshot{
+		APIVersion: types.SchemaVersion,
+		Metadata:   types.SnapshotMetadata{Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), ClusterName: "prod"},
+		Resources: []types.Resource{{
+			APIVersion: "apps/v1", Kind: "Deployment", Namespace: "prod", Name: "api",
+			Spec: map[string]interface{}{"replicas": int64(3), "ratio": 0.5},
+			Raw: map[string]interface{}{
+				"spec": map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": int64(8080)}}},
+			},
+		}},
+	}
+	require.NoError(t, Save(path, snapshot))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded, "numbers keep their collected types")
+
+	require.NoError(t, Remove(path))
+	require.NoError(t, Remove(path), "removing nothing is not an error")
+	_, err = Load(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, Path("./snapshots"), Path("snapshots"))
+	assert.NotEqual(t, Path("a"), Path("b"))
+}
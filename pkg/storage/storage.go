@@ -0,0 +1,283 @@
+// Package storage follows the storage requested by PersistentVolumeClaims
+// through the snapshot history, per namespace and storage class.
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Kinds are the kinds storage is read from.
+var Kinds = []string{"PersistentVolumeClaim"}
+
+// DefaultClass names the storage class of claims that do not set one and
+// so get the cluster's default class.
+const DefaultClass = "(default)"
+
+// Claim is the storage requested by one PersistentVolumeClaim.
+type Claim struct {
+	Namespace    string `json:"namespace" yaml:"namespace"`
+	Name         string `json:"name" yaml:"name"`
+	StorageClass string `json:"storageClass" yaml:"storageClass"`
+	// Requested is spec.resources.requests.storage in bytes.
+	Requested int64 `json:"requested" yaml:"requested"`
+}
+
+// Claims returns the claims of a snapshot, sorted by namespace and name.
+// Claims without a parseable storage request count as requesting nothing.
+func Claims(snapshot *types.ResourceSnapshot) []Claim {
+	var claims []Claim
+	for _, res := range snapshot.Resources {
+		if res.Kind != "PersistentVolumeClaim" {
+			continue
+		}
+		spec, _ := res.Raw["spec"].(map[string]interface{})
+		class, _ := spec["storageClassName"].(string)
+		if class == "" {
+			class = DefaultClass
+		}
+		resources, _ := spec["resources"].(map[string]interface{})
+		requests, _ := resources["requests"].(map[string]interface{})
+		claims = append(claims, Claim{
+			Namespace:    res.Namespace,
+			Name:         res.Name,
+			StorageClass: class,
+			Requested:    parseBytes(requests["storage"]),
+		})
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if claims[i].Namespace != claims[j].Namespace {
+			return claims[i].Namespace < claims[j].Namespace
+		}
+		return claims[i].Name < claims[j].Name
+	})
+	return claims
+}
+
+// parseBytes reads a Kubernetes quantity such as 10Gi or 500M, which YAML
+// may also have decoded as a plain number.
+func parseBytes(v interface{}) int64 {
+	switch q := v.(type) {
+	case string:
+		parsed, err := resource.ParseQuantity(q)
+		if err != nil {
+			return 0
+		}
+		return parsed.Value()
+	case int:
+		return int64(q)
+	case int64:
+		return q
+	case float64:
+		return int64(q)
+	}
+	return 0
+}
+
+// FormatBytes renders a byte count as a binary quantity, e.g. 10Gi.
+func FormatBytes(n int64) string {
+	return resource.NewQuantity(n, resource.BinarySI).String()
+}
+
+// Point is the claims of one snapshot in the history.
+type Point struct {
+	CommitHash string
+	Timestamp  time.Time
+	Claims     []Claim
+}
+
+// ClaimChange is a claim added, removed, resized, or moved to another
+// storage class between two snapshots. Before is zero for added claims and
+// After for removed ones.
+type ClaimChange struct {
+	Name        string `json:"name" yaml:"name"`
+	BeforeClass string `json:"beforeClass,omitempty" yaml:"beforeClass,omitempty"`
+	AfterClass  string `json:"afterClass,omitempty" yaml:"afterClass,omitempty"`
+	Before      int64  `json:"before" yaml:"before"`
+	After       int64  `json:"after" yaml:"after"`
+}
+
+// Step is a snapshot at which a namespace's claims changed.
+type Step struct {
+	CommitHash string    `json:"commitHash" yaml:"commitHash"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	Namespace  string    `json:"namespace" yaml:"namespace"`
+	// Before and After are the namespace's total requested bytes.
+	Before int64 `json:"before" yaml:"before"`
+	After  int64 `json:"after" yaml:"after"`
+	// Doubled is set at the step where the namespace's requested storage
+	// first reached twice its first non-zero total in the period.
+	Doubled bool          `json:"doubled,omitempty" yaml:"doubled,omitempty"`
+	Changes []ClaimChange `json:"changes" yaml:"changes"`
+}
+
+// Summary is a namespace's requested storage over the whole period.
+type Summary struct {
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Start and End are the total requested bytes at the first and last
+	// point.
+	Start int64 `json:"start" yaml:"start"`
+	End   int64 `json:"end" yaml:"end"`
+	// Claims is the number of claims at the last point.
+	Claims int `json:"claims" yaml:"claims"`
+	// ByClass is the requested bytes per storage class at the last point.
+	ByClass map[string]int64 `json:"byClass,omitempty" yaml:"byClass,omitempty"`
+	// DoubledAt is when the requested storage first reached twice its
+	// first non-zero total in the period; unset if it never did.
+	DoubledAt     *time.Time `json:"doubledAt,omitempty" yaml:"doubledAt,omitempty"`
+	DoubledCommit string     `json:"doubledCommit,omitempty" yaml:"doubledCommit,omitempty"`
+}
+
+// Growth is End as a multiple of Start, or zero if Start is zero.
+func (s Summary) Growth() float64 {
+	if s.Start == 0 {
+		return 0
+	}
+	return float64(s.End) / float64(s.Start)
+}
+
+// Report is the storage history of a period.
+type Report struct {
+	Namespaces []Summary `json:"namespaces" yaml:"namespaces"`
+	Steps      []Step    `json:"steps" yaml:"steps"`
+}
+
+// History follows the claims of namespace (all namespaces if empty)
+// through points, oldest first. The first point is the baseline the second
+// is compared with; it is not a step itself.
+func History(points []Point, namespace string) Report {
+	var report Report
+	if len(points) == 0 {
+		return report
+	}
+
+	first := byNamespace(points[0].Claims, namespace)
+	summaries := make(map[string]*Summary)
+	// base is the first non-zero total of each namespace, which doubling
+	// is measured against
+	base := make(map[string]int64)
+	summary := func(ns string) *Summary {
+		s, ok := summaries[ns]
+		if !ok {
+			s = &Summary{Namespace: ns, Start: total(first[ns])}
+			summaries[ns] = s
+			base[ns] = s.Start
+		}
+		return s
+	}
+	for ns := range first {
+		summary(ns)
+	}
+
+	for i := 1; i < len(points); i++ {
+		before := byNamespace(points[i-1].Claims, namespace)
+		after := byNamespace(points[i].Claims, namespace)
+		for _, ns := range namespaces(before, after) {
+			changes := diffClaims(before[ns], after[ns])
+			if len(changes) == 0 {
+				continue
+			}
+			s := summary(ns)
+			step := Step{
+				CommitHash: points[i].CommitHash,
+				Timestamp:  points[i].Timestamp,
+				Namespace:  ns,
+				Before:     total(before[ns]),
+				After:      total(after[ns]),
+				Changes:    changes,
+			}
+			switch {
+			case base[ns] == 0:
+				base[ns] = step.After
+			case s.DoubledAt == nil && step.After >= 2*base[ns]:
+				step.Doubled = true
+				t := step.Timestamp
+				s.DoubledAt, s.DoubledCommit = &t, step.CommitHash
+			}
+			report.Steps = append(report.Steps, step)
+		}
+	}
+
+	last := byNamespace(points[len(points)-1].Claims, namespace)
+	for ns, claims := range last {
+		s := summary(ns)
+		s.Claims = len(claims)
+		s.ByClass = make(map[string]int64)
+		for _, c := range claims {
+			s.ByClass[c.StorageClass] += c.Requested
+		}
+	}
+	for _, s := range summaries {
+		s.End = total(last[s.Namespace])
+		report.Namespaces = append(report.Namespaces, *s)
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		return report.Namespaces[i].Namespace < report.Namespaces[j].Namespace
+	})
+	return report
+}
+
+// byNamespace groups claims by namespace, keeping only namespace if set.
+func byNamespace(claims []Claim, namespace string) map[string][]Claim {
+	out := make(map[string][]Claim)
+	for _, c := range claims {
+		if namespace == "" || c.Namespace == namespace {
+			out[c.Namespace] = append(out[c.Namespace], c)
+		}
+	}
+	return out
+}
+
+// namespaces returns the namespaces of either grouping, sorted.
+func namespaces(a, b map[string][]Claim) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, m := range []map[string][]Claim{a, b} {
+		for ns := range m {
+			if !seen[ns] {
+				seen[ns] = true
+				out = append(out, ns)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// total sums the requested bytes of claims.
+func total(claims []Claim) int64 {
+	var n int64
+	for _, c := range claims {
+		n += c.Requested
+	}
+	return n
+}
+
+// diffClaims compares the claims of one namespace, sorted by claim name.
+func diffClaims(before, after []Claim) []ClaimChange {
+	old := make(map[string]Claim, len(before))
+	for _, c := range before {
+		old[c.Name] = c
+	}
+	var changes []ClaimChange
+	for _, c := range after {
+		prev, ok := old[c.Name]
+		delete(old, c.Name)
+		if ok && prev == c {
+			continue
+		}
+		change := ClaimChange{Name: c.Name, AfterClass: c.StorageClass, After: c.Requested}
+		if ok {
+			change.BeforeClass, change.Before = prev.StorageClass, prev.Requested
+		}
+		changes = append(changes, change)
+	}
+	for _, c := range old {
+		changes = append(changes, ClaimChange{Name: c.Name, BeforeClass: c.StorageClass, Before: c.Requested})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pvc(namespace, name, class string, request interface{}) types.Resource {
+	spec := map[string]interface{}{
+		"resources": map[string]interface{}{"requests": map[string]interface{}{"storage": request}},
+	}
+	if class != "" {
+		spec["storageClassName"] = class
+	}
+	return types.Resource{Kind: "PersistentVolumeClaim", Namespace: namespace, Name: name, Raw: map[string]interface{}{"spec": spec}}
+}
+
+func TestClaims(t *testing.T) {
+	snapshot := &types.ResourceSnapshot{Resources: []types.Resource{
+		pvc("prod", "data", "fast", "10Gi"),
+		pvc("dev", "scratch", "", "500M"),
+		pvc("prod", "logs", "standard", 1024),
+		pvc("prod", "broken", "standard", "lots"),
+		{Kind: "ConfigMap", Namespace: "prod", Name: "settings"},
+	}}
+
+	claims := Claims(snapshot)
+
+	require.Len(t, claims, 4)
+	assert.Equal(t, Claim{Namespace: "dev", Name: "scratch", StorageClass: DefaultClass, Requested: 500_000_000}, claims[0])
+	assert.Equal(t, int64(0), claims[1].Requested, "unparseable requests count as nothing")
+	assert.Equal(t, int64(10<<30), claims[2].Requested)
+	assert.Equal(t, int64(1024), claims[3].Requested)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "10Gi", FormatBytes(10<<30))
+	assert.Equal(t, "1536Mi", FormatBytes(1536<<20))
+	assert.Equal(t, "0", FormatBytes(0))
+}
+
+func point(hash string, day int, resources ...types.Resource) Point {
+	return Point{
+		CommitHash: hash,
+		Timestamp:  time.Date(2024, 6, day, 0, 0, 0, 0, time.UTC),
+		Claims:     Claims(&types.ResourceSnapshot{Resources: resources}),
+	}
+}
+
+func TestHistory(t *testing.T) {
+	points := []Point{
+		point("aaaaaaaa", 1, pvc("prod", "data", "fast", "10Gi"), pvc("dev", "scratch", "", "1Gi")),
+		point("bbbbbbbb", 2, pvc("prod", "data", "fast", "15Gi"), pvc("dev", "scratch", "", "1Gi")),
+		point("cccccccc", 3, pvc("prod", "data", "fast", "15Gi"), pvc("dev", "scratch", "", "1Gi")),
+		point("dddddddd", 4, pvc("prod", "data", "fast", "15Gi"), pvc("prod", "logs", "standard", "5Gi")),
+		point("eeeeeeee", 5, pvc("prod", "data", "fast", "30Gi"), pvc("prod", "logs", "standard", "5Gi")),
+	}
+
+	report := History(points, "")
+
+	require.Len(t, report.Steps, 4)
+	assert.Equal(t, "prod", report.Steps[0].Namespace)
+	assert.Equal(t, []ClaimChange{{Name: "data", BeforeClass: "fast", AfterClass: "fast", Before: 10 << 30, After: 15 << 30}}, report.Steps[0].Changes)
+
+	assert.Equal(t, "dev", report.Steps[1].Namespace, "namespaces changing in one snapshot are sorted")
+	assert.Equal(t, []ClaimChange{{Name: "scratch", BeforeClass: DefaultClass, Before: 1 << 30}}, report.Steps[1].Changes)
+	assert.Equal(t, "prod", report.Steps[2].Namespace)
+	assert.True(t, report.Steps[2].Doubled, "15Gi + 5Gi is twice the 10Gi at the start")
+	assert.False(t, report.Steps[3].Doubled, "only the first doubling is marked")
+
+	require.Len(t, report.Namespaces, 2)
+	dev, prod := report.Namespaces[0], report.Namespaces[1]
+	assert.Equal(t, int64(0), dev.End)
+	assert.Nil(t, dev.DoubledAt)
+	assert.Equal(t, int64(35<<30), prod.End)
+	assert.Equal(t, 3.5, prod.Growth())
+	assert.Equal(t, 2, prod.Claims)
+	assert.Equal(t, map[string]int64{"fast": 30 << 30, "standard": 5 << 30}, prod.ByClass)
+	require.NotNil(t, prod.DoubledAt)
+	assert.Equal(t, "dddddddd", prod.DoubledCommit)
+
+	filtered := History(points, "dev")
+	assert.Len(t, filtered.Steps, 1)
+	assert.Len(t, filtered.Namespaces, 1)
+}
+
+func TestHistory_NewNamespace(t *testing.T) {
+	points := []Point{
+		point("aaaaaaaa", 1),
+		point("bbbbbbbb", 2, pvc("qa", "db", "fast", "1Gi")),
+		point("cccccccc", 3, pvc("qa", "db", "fast", "2Gi")),
+	}
+
+	report := History(points, "")
+
+	require.Len(t, report.Namespaces, 1)
+	assert.Equal(t, int64(0), report.Namespaces[0].Start)
+	assert.Equal(t, float64(0), report.Namespaces[0].Growth())
+	require.Len(t, report.Steps, 2)
+	assert.False(t, report.Steps[0].Doubled, "growth from nothing is not doubling")
+	assert.True(t, report.Steps[1].Doubled, "doubling is measured from the first claims")
+	assert.Equal(t, "cccccccc", report.Namespaces[0].DoubledCommit)
+	assert.Empty(t, History(nil, "").Steps)
+}
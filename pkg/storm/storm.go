@@ -0,0 +1,84 @@
+// Package storm detects commit storms in watch mode: every tick committing
+// changes for a long run of ticks, which usually means a field that changes
+// on its own is captured, or two controllers are fighting over a resource.
+package storm
+
+import (
+	"fmt"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+)
+
+// Validate checks that the storm configuration is well-formed.
+func Validate(cfg *config.StormConfig) error {
+	if cfg.Ticks < 0 {
+		return fmt.Errorf("watch.storm.ticks must not be negative")
+	}
+	if cfg.Backoff && cfg.MaxBackoff < 2 {
+		return fmt.Errorf("watch.storm.max_backoff must be at least 2 with backoff enabled")
+	}
+	return nil
+}
+
+// Event describes a storm when it is detected or persists.
+type Event struct {
+	// Streak is the number of consecutive ticks that committed.
+	Streak int
+	// Factor is the interval between snapshots as a multiple of the
+	// schedule's, after any backoff.
+	Factor int
+	// First is set when the streak has just become a storm.
+	First bool
+}
+
+// Detector counts consecutive committing ticks. It is not safe for
+// concurrent use; watch runs one tick at a time.
+type Detector struct {
+	cfg     *config.StormConfig
+	streak  int
+	factor  int
+	skipped int
+}
+
+// New creates a detector with no backoff.
+func New(cfg *config.StormConfig) *Detector {
+	return &Detector{cfg: cfg, factor: 1}
+}
+
+// Skip reports whether a tick should be skipped to keep to the backed-off
+// interval: with a factor of n, one tick in n runs.
+func (d *Detector) Skip() bool {
+	if d.factor <= 1 {
+		return false
+	}
+	d.skipped++
+	if d.skipped < d.factor {
+		return true
+	}
+	d.skipped = 0
+	return false
+}
+
+// Record records whether a tick committed, and returns an event each time
+// the streak reaches another multiple of the configured ticks. A tick that
+// commits nothing ends the streak, but the backoff stays until restart so
+// that a storm that comes and goes is still looked at.
+func (d *Detector) Record(committed bool) (Event, bool) {
+	if !committed {
+		d.streak = 0
+		return Event{}, false
+	}
+	d.streak++
+	if d.cfg.Ticks == 0 || d.streak%d.cfg.Ticks != 0 {
+		return Event{}, false
+	}
+	if d.cfg.Backoff && d.factor < d.cfg.MaxBackoff {
+		d.factor = min(d.factor*2, d.cfg.MaxBackoff)
+	}
+	return Event{Streak: d.streak, Factor: d.factor, First: d.streak == d.cfg.Ticks}, true
+}
+
+// Factor is the current interval between snapshots as a multiple of the schedule's.
+func (d *Detector) Factor() int {
+	return d.factor
+}
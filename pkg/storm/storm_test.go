@@ -0,0 +1,68 @@
+package storm
+
+import (
+	"testing"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	d := New(&config.StormConfig{Ticks: 3})
+
+	for i := 0; i < 2; i++ {
+		_, storm := d.Record(true)
+		assert.False(t, storm)
+	}
+	// A quiet tick ends the streak
+	_, storm := d.Record(false)
+	assert.False(t, storm)
+
+	var events []Event
+	for i := 0; i < 6; i++ {
+		if e, storm := d.Record(true); storm {
+			events = append(events, e)
+		}
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, Event{Streak: 3, Factor: 1, First: true}, events[0])
+	assert.Equal(t, Event{Streak: 6, Factor: 1}, events[1])
+	assert.False(t, d.Skip(), "no backoff unless enabled")
+}
+
+func TestBackoff(t *testing.T) {
+	d := New(&config.StormConfig{Ticks: 2, Backoff: true, MaxBackoff: 3})
+
+	d.Record(true)
+	e, storm := d.Record(true)
+	require.True(t, storm)
+	assert.Equal(t, 2, e.Factor)
+
+	// One tick in two runs
+	assert.True(t, d.Skip())
+	assert.False(t, d.Skip())
+	assert.True(t, d.Skip())
+
+	d.Record(true)
+	e, _ = d.Record(true)
+	assert.Equal(t, 3, e.Factor, "capped at max_backoff")
+
+	// The backoff outlasts the streak
+	d.Record(false)
+	assert.Equal(t, 3, d.Factor())
+}
+
+func TestDisabled(t *testing.T) {
+	d := New(&config.StormConfig{})
+	for i := 0; i < 100; i++ {
+		_, storm := d.Record(true)
+		assert.False(t, storm)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.StormConfig{Ticks: 12, MaxBackoff: 8}))
+	assert.Error(t, Validate(&config.StormConfig{Ticks: -1}))
+	assert.Error(t, Validate(&config.StormConfig{Ticks: 12, Backoff: true, MaxBackoff: 1}))
+}
@@ -0,0 +1,90 @@
+// Package suppression decides whether drift falls inside a maintenance window
+// where it should be recorded but not alerted on.
+package suppression
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/robfig/cron/v3"
+)
+
+// parser accepts standard 5-field cron expressions, descriptors, and CRON_TZ prefixes.
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Validate checks that every configured window is well-formed.
+func Validate(cfg *config.SuppressionConfig) error {
+	for i, w := range cfg.Windows {
+		if _, _, err := check(w, time.Time{}); err != nil {
+			return fmt.Errorf("suppression window %d (%s): %w", i, w.Name, err)
+		}
+	}
+	return nil
+}
+
+// ActiveWindow returns the name of the first window that contains now.
+func ActiveWindow(cfg *config.SuppressionConfig, now time.Time) (string, bool, error) {
+	for i, w := range cfg.Windows {
+		active, name, err := check(w, now)
+		if err != nil {
+			return "", false, fmt.Errorf("suppression window %d (%s): %w", i, w.Name, err)
+		}
+		if active {
+			return name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// Apply marks the report as suppressed if a window is active at the report's timestamp.
+func Apply(cfg *config.SuppressionConfig, report *types.DriftReport) error {
+	name, active, err := ActiveWindow(cfg, report.Timestamp)
+	if err != nil {
+		return err
+	}
+	if active {
+		report.SuppressedBy = name
+	}
+	return nil
+}
+
+// check reports whether a single window contains now.
+func check(w config.SuppressionWindow, now time.Time) (bool, string, error) {
+	name := w.Name
+	if name == "" {
+		name = w.Schedule
+	}
+
+	switch {
+	case w.Schedule != "":
+		if w.Duration <= 0 {
+			return false, "", fmt.Errorf("recurring window needs a positive duration")
+		}
+		sched, err := parser.Parse(w.Schedule)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid schedule %q: %w", w.Schedule, err)
+		}
+		// The window is open if a start time occurred within the last Duration.
+		next := sched.Next(now.Add(-w.Duration))
+		return !next.After(now), name, nil
+
+	case w.Start != "" || w.End != "":
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid start (use RFC3339): %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, w.End)
+		if err != nil {
+			return false, "", fmt.Errorf("invalid end (use RFC3339): %w", err)
+		}
+		if name == "" {
+			name = w.Start + "/" + w.End
+		}
+		return !now.Before(start) && now.Before(end), name, nil
+
+	default:
+		return false, "", fmt.Errorf("window needs either schedule+duration or start+end")
+	}
+}
@@ -0,0 +1,72 @@
+package suppression
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveWindow_Recurring(t *testing.T) {
+	cfg := &config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{
+			{Name: "tuesday-release", Schedule: "0 22 * * 2", Duration: 2 * time.Hour},
+		},
+	}
+
+	// Tuesday 2024-01-02 22:30 UTC is inside the window
+	name, active, err := ActiveWindow(cfg, time.Date(2024, 1, 2, 22, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, "tuesday-release", name)
+
+	// Wednesday 00:30 is past the two hour window
+	_, active, err = ActiveWindow(cfg, time.Date(2024, 1, 3, 0, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestActiveWindow_Fixed(t *testing.T) {
+	cfg := &config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{
+			{Name: "migration", Start: "2024-05-01T10:00:00Z", End: "2024-05-01T12:00:00Z"},
+		},
+	}
+
+	_, active, err := ActiveWindow(cfg, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	_, active, err = ActiveWindow(cfg, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.SuppressionConfig{}))
+
+	assert.Error(t, Validate(&config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{{Name: "no-duration", Schedule: "0 22 * * *"}},
+	}))
+	assert.Error(t, Validate(&config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{{Name: "bad-cron", Schedule: "nope", Duration: time.Hour}},
+	}))
+	assert.Error(t, Validate(&config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{{Name: "empty"}},
+	}))
+}
+
+func TestApply(t *testing.T) {
+	cfg := &config.SuppressionConfig{
+		Windows: []config.SuppressionWindow{
+			{Name: "always", Schedule: "* * * * *", Duration: 2 * time.Minute},
+		},
+	}
+	report := &types.DriftReport{Timestamp: time.Now().UTC()}
+
+	require.NoError(t, Apply(cfg, report))
+	assert.Equal(t, "always", report.SuppressedBy)
+}
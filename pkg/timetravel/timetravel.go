@@ -2,7 +2,12 @@
 package timetravel
 
 import (
+	"context"
 	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
@@ -16,6 +21,7 @@ type Engine struct {
 	versioner   *versioner.Versioner
 	snapshotter *snapshotter.Snapshotter
 	repoPath    string
+	logger      log.FieldLogger
 }
 
 // New creates a new time-travel Engine.
@@ -24,25 +30,45 @@ func New(v *versioner.Versioner, s *snapshotter.Snapshotter, repoPath string) *E
 		versioner:   v,
 		snapshotter: s,
 		repoPath:    repoPath,
+		logger:      log.StandardLogger(),
 	}
 }
 
+// SetLogger replaces the standard logrus logger for the engine's log output.
+func (e *Engine) SetLogger(logger log.FieldLogger) {
+	e.logger = logger
+}
+
 // SnapshotAt retrieves the infrastructure state at a given time.
-func (e *Engine) SnapshotAt(target time.Time) (*types.ResourceSnapshot, error) {
-	log.WithField("target", target.Format(time.RFC3339)).Info("time-travel: looking up snapshot")
+func (e *Engine) SnapshotAt(ctx context.Context, target time.Time) (*types.ResourceSnapshot, error) {
+	e.logger.WithField("target", target.Format(time.RFC3339)).Info("time-travel: looking up snapshot")
 
 	// Find the commit closest to the target time
-	commitHash, err := e.versioner.FindCommitByTime(target)
+	commitHash, err := e.versioner.FindCommitByTime(ctx, target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find snapshot at %s: %w", target.Format(time.RFC3339), err)
 	}
 
-	return e.SnapshotByCommit(commitHash)
+	return e.SnapshotByCommit(ctx, commitHash)
 }
 
 // SnapshotByCommit retrieves the infrastructure state at a specific commit.
-func (e *Engine) SnapshotByCommit(commitHash string) (*types.ResourceSnapshot, error) {
-	log.WithField("commit", commitHash[:8]).Info("time-travel: checking out snapshot")
+// The ref may be a full or abbreviated hash, a branch, a tag, or a revision
+// expression such as HEAD~3. If ctx is cancelled while the snapshot is read,
+// the branch is still checked out again before returning.
+func (e *Engine) SnapshotByCommit(ctx context.Context, ref string) (*types.ResourceSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	commitHash, err := e.versioner.ResolveRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.WithFields(log.Fields{
+		"ref":    ref,
+		"commit": commitHash[:8],
+	}).Info("time-travel: checking out snapshot")
 
 	// Checkout the commit
 	if err := e.versioner.CheckoutAt(commitHash); err != nil {
@@ -52,12 +78,12 @@ func (e *Engine) SnapshotByCommit(commitHash string) (*types.ResourceSnapshot, e
 	// Ensure we return to the branch when done
 	defer func() {
 		if err := e.versioner.CheckoutBranch(); err != nil {
-			log.WithError(err).Warn("failed to return to branch")
+			e.logger.WithError(err).Warn("failed to return to branch")
 		}
 	}()
 
 	// Read the snapshot at this commit
-	snapshot, err := e.snapshotter.Read()
+	snapshot, err := e.snapshotter.ReadContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read snapshot at commit %s: %w", commitHash, err)
 	}
@@ -66,19 +92,106 @@ func (e *Engine) SnapshotByCommit(commitHash string) (*types.ResourceSnapshot, e
 	return snapshot, nil
 }
 
+// CommitReader reads the files of a commit without checking it out.
+// *versioner.Versioner implements it.
+type CommitReader interface {
+	TreeFiles(commitHash, dir string) ([]versioner.TreeFile, error)
+	ReadBlob(hash string) ([]byte, error)
+	ReadFileAt(commitHash, filePath string) ([]byte, error)
+}
+
+// ReadCommit decodes the snapshot under scope at a history entry's
+// commit from the repository, without checking it out. Each file is also
+// passed to add, if set, with its path relative to scope. Decoding stops
+// before the next file once ctx is cancelled.
+func ReadCommit(ctx context.Context, ver CommitReader, entry types.HistoryEntry, scope string, add func(name string, data []byte) error) (*types.ResourceSnapshot, error) {
+	return readCommit(ctx, ver, entry, scope, add, nil)
+}
+
+// ReadCommitKinds is ReadCommit for the resources of the given kinds only,
+// e.g. to follow RBAC objects through many snapshots without decoding
+// everything else. Other files are not even read.
+func ReadCommitKinds(ctx context.Context, ver CommitReader, entry types.HistoryEntry, scope string, kinds []string) (*types.ResourceSnapshot, error) {
+	dirs := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		dirs[strings.ToLower(kind)] = true
+	}
+	return readCommit(ctx, ver, entry, scope, nil, func(file string) bool {
+		return snapshotter.IsResourcePath(file) && dirs[path.Base(path.Dir(file))]
+	})
+}
+
+// readCommit implements ReadCommit, reading only the files keep accepts, if
+// set.
+func readCommit(ctx context.Context, ver CommitReader, entry types.HistoryEntry, scope string, add func(name string, data []byte) error, keep func(file string) bool) (*types.ResourceSnapshot, error) {
+	commit := entry.CommitHash
+	files, err := ver.TreeFiles(commit, scope)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	snapshot := &types.ResourceSnapshot{
+		APIVersion: types.SchemaVersion,
+		Metadata: types.SnapshotMetadata{
+			Timestamp:   entry.Timestamp,
+			ClusterName: entry.ClusterName,
+			Context:     entry.Context,
+			Namespaces:  entry.Namespaces,
+			CommitHash:  commit,
+			Scope:       entry.Scope,
+		},
+	}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if keep != nil && !keep(file.Path) {
+			continue
+		}
+		data, err := ver.ReadBlob(file.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if add != nil {
+			name := strings.TrimPrefix(strings.TrimPrefix(file.Path, scope), "/")
+			if err := add(name, data); err != nil {
+				return nil, err
+			}
+		}
+		if !snapshotter.IsResourcePath(file.Path) {
+			continue
+		}
+
+		root := snapshotter.BlobRoot(file.Path)
+		readBlob := func(digest string) ([]byte, error) {
+			return ver.ReadFileAt(commit, path.Join(root, snapshotter.BlobPath(digest)))
+		}
+		res, err := snapshotter.DecodeFile(file.Path, data, readBlob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s at %s: %w", file.Path, commit[:8], err)
+		}
+		res.ContentHash = file.Hash
+		snapshot.Resources = append(snapshot.Resources, res)
+	}
+	snapshot.Metadata.ResourceCount = len(snapshot.Resources)
+	snapshot.UpdateCounts()
+	return snapshot, nil
+}
+
 // CompareTimeRange compares infrastructure state between two points in time.
-func (e *Engine) CompareTimeRange(from, to time.Time) (*types.ResourceSnapshot, *types.ResourceSnapshot, error) {
-	log.WithFields(log.Fields{
+func (e *Engine) CompareTimeRange(ctx context.Context, from, to time.Time) (*types.ResourceSnapshot, *types.ResourceSnapshot, error) {
+	e.logger.WithFields(log.Fields{
 		"from": from.Format(time.RFC3339),
 		"to":   to.Format(time.RFC3339),
 	}).Info("time-travel: comparing time range")
 
-	fromSnapshot, err := e.SnapshotAt(from)
+	fromSnapshot, err := e.SnapshotAt(ctx, from)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get snapshot at 'from' time: %w", err)
 	}
 
-	toSnapshot, err := e.SnapshotAt(to)
+	toSnapshot, err := e.SnapshotAt(ctx, to)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get snapshot at 'to' time: %w", err)
 	}
@@ -87,8 +200,8 @@ func (e *Engine) CompareTimeRange(from, to time.Time) (*types.ResourceSnapshot,
 }
 
 // ListResources returns all resources at a given time matching optional filters.
-func (e *Engine) ListResources(target time.Time, kind string, namespace string) ([]types.Resource, error) {
-	snapshot, err := e.SnapshotAt(target)
+func (e *Engine) ListResources(ctx context.Context, target time.Time, kind string, namespace string) ([]types.Resource, error) {
+	snapshot, err := e.SnapshotAt(ctx, target)
 	if err != nil {
 		return nil, err
 	}
@@ -106,3 +219,66 @@ func (e *Engine) ListResources(target time.Time, kind string, namespace string)
 
 	return filtered, nil
 }
+
+// Timeline returns every version of a resource across history, newest
+// first. dir is the snapshot directory within the repository ("" for the
+// root); namespace is empty for cluster-scoped resources.
+func (e *Engine) Timeline(ctx context.Context, dir, namespace, kind, name string) ([]types.ResourceVersion, error) {
+	paths := snapshotter.ResourcePaths(namespace, kind, name)
+	for i, p := range paths {
+		paths[i] = path.Join(filepath.ToSlash(dir), p)
+	}
+
+	fileVersions, err := e.versioner.FileVersions(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource history: %w", err)
+	}
+
+	versions := make([]types.ResourceVersion, 0, len(fileVersions))
+	for _, fv := range fileVersions {
+		version := types.ResourceVersion{
+			CommitHash: fv.CommitHash,
+			Timestamp:  fv.Timestamp,
+			Message:    fv.Message,
+		}
+		if fv.Path == "" {
+			version.Deleted = true
+			versions = append(versions, version)
+			continue
+		}
+
+		commit := fv.CommitHash
+		readBlob := func(digest string) ([]byte, error) {
+			return e.versioner.ReadFileAt(commit, path.Join(filepath.ToSlash(dir), snapshotter.BlobPath(digest)))
+		}
+		res, err := snapshotter.DecodeFile(fv.Path, fv.Content, readBlob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode version at %s: %w", commit[:8], err)
+		}
+		version.Resource = &res
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Lifespans returns the periods during which a resource existed, oldest
+// first, given its versions as returned by Timeline (newest first). A
+// resource that was deleted and re-created has several lifespans.
+func Lifespans(versions []types.ResourceVersion) []types.Lifespan {
+	var spans []types.Lifespan
+	open := false
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		v.Resource = nil
+		switch {
+		case !v.Deleted && !open:
+			spans = append(spans, types.Lifespan{Appeared: v})
+			open = true
+		case v.Deleted && open:
+			removed := v
+			spans[len(spans)-1].Removed = &removed
+			open = false
+		}
+	}
+	return spans
+}
@@ -0,0 +1,89 @@
+package timetravel
+
+import (
+	"context"
+	"testing"
+
+	tmtesting "github.com/raghu-007/GitOps-Time-Machine/pkg/client/testing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/snapshotter"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifespans(t *testing.T) {
+	res := &types.Resource{Kind: "Deployment", Name: "api"}
+	// Newest first, as returned by Timeline
+	versions := []types.ResourceVersion{
+		{CommitHash: "f", Resource: res},
+		{CommitHash: "e", Resource: res},
+		{CommitHash: "d", Deleted: true},
+		{CommitHash: "c", Resource: res},
+		{CommitHash: "b", Resource: res},
+		{CommitHash: "a", Deleted: true},
+	}
+
+	spans := Lifespans(versions)
+	require.Len(t, spans, 2)
+	assert.Equal(t, "b", spans[0].Appeared.CommitHash)
+	assert.Nil(t, spans[0].Appeared.Resource)
+	require.NotNil(t, spans[0].Removed)
+	assert.Equal(t, "d", spans[0].Removed.CommitHash)
+	assert.Equal(t, "e", spans[1].Appeared.CommitHash)
+	assert.Nil(t, spans[1].Removed)
+
+	assert.Empty(t, Lifespans(nil))
+}
+
+func TestReadCommit(t *testing.T) {
+	dir := t.TempDir()
+	snap := snapshotter.NewWithOptions(dir, snapshotter.Options{BlobThreshold: 8})
+	require.NoError(t, snap.Write(&types.ResourceSnapshot{Resources: []types.Resource{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "prod", Name: "settings",
+			Data: map[string]interface{}{"big": "a value over the blob threshold"}},
+		{APIVersion: "v1", Kind: "Namespace", Name: "prod"},
+	}}))
+	store := tmtesting.NewGitStore(dir)
+	hash, err := store.Commit(&types.SnapshotMetadata{ClusterName: "test"})
+	require.NoError(t, err)
+	entry, err := store.Entry(hash, "")
+	require.NoError(t, err)
+
+	var added []string
+	snapshot, err := ReadCommit(context.Background(), store, entry, "", func(name string, _ []byte) error {
+		added = append(added, name)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hash, snapshot.Metadata.CommitHash)
+	assert.Equal(t, "test", snapshot.Metadata.ClusterName)
+	require.Len(t, snapshot.Resources, 2)
+	assert.Equal(t, "a value over the blob threshold", snapshot.Resources[1].Data["big"], "externalized values are read back")
+	assert.Contains(t, added, "_metadata.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ReadCommit(ctx, store, entry, "", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestReadCommitKinds(t *testing.T) {
+	dir := t.TempDir()
+	snap := snapshotter.New(dir)
+	require.NoError(t, snap.Write(&types.ResourceSnapshot{Resources: []types.Resource{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "prod", Name: "settings"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "reader"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding", Namespace: "prod", Name: "readers"},
+	}}))
+	store := tmtesting.NewGitStore(dir)
+	hash, err := store.Commit(&types.SnapshotMetadata{})
+	require.NoError(t, err)
+	entry, err := store.Entry(hash, "")
+	require.NoError(t, err)
+
+	snapshot, err := ReadCommitKinds(context.Background(), store, entry, "", []string{"ClusterRole", "RoleBinding"})
+	require.NoError(t, err)
+	require.Len(t, snapshot.Resources, 2)
+	assert.Equal(t, "ClusterRole", snapshot.Resources[0].Kind)
+	assert.Equal(t, "RoleBinding", snapshot.Resources[1].Kind)
+}
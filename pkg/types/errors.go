@@ -0,0 +1,30 @@
+package types
+
+import "errors"
+
+// Errors shared across packages, so that callers (and the CLI's exit codes)
+// can tell failures apart with errors.Is rather than by their messages.
+// They are wrapped with details where they occur.
+var (
+	// ErrNoSnapshots means there is no snapshot to read: nothing has been
+	// captured yet, or none at the requested time or in the requested period.
+	ErrNoSnapshots = errors.New("no snapshots")
+	// ErrCommitNotFound means a commit, branch, tag, or revision does not
+	// resolve to a commit of the snapshot repository.
+	ErrCommitNotFound = errors.New("commit not found")
+	// ErrRepoLocked means another process is changing the snapshot repository.
+	ErrRepoLocked = errors.New("snapshot repository is locked")
+	// ErrPartialCollection means some resource types could not be collected.
+	// It is returned along with the snapshot of the types that were.
+	ErrPartialCollection = errors.New("partial collection")
+	// ErrDriftDetected means a comparison found drift and the caller asked
+	// for it to be reported as a failure, e.g. with --exit-code.
+	ErrDriftDetected = errors.New("drift detected")
+	// ErrPolicyViolation means a compared snapshot breaks a configured
+	// policy and the caller asked for it to be reported as a failure.
+	ErrPolicyViolation = errors.New("policy violation")
+	// ErrSnapshotRejected means a snapshot failed the checks run before it
+	// is committed (empty, incomplete, or too large), so that a broken
+	// snapshot is not committed over good history.
+	ErrSnapshotRejected = errors.New("snapshot rejected")
+)
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion identifies the serialization schema of snapshots and drift
+// reports. Bump it, and teach upgradeSchema the old version, whenever a
+// change to these structs would misread previously stored documents.
+const SchemaVersion = "gitops-tm/v1"
+
+// upgradeSchema checks a decoded document's apiVersion and brings it up to
+// SchemaVersion. Documents written before versioning have no apiVersion and
+// share the v1 layout.
+func upgradeSchema(apiVersion *string) error {
+	switch *apiVersion {
+	case SchemaVersion, "":
+		*apiVersion = SchemaVersion
+		return nil
+	default:
+		return fmt.Errorf("unsupported apiVersion %q (this build reads up to %s)", *apiVersion, SchemaVersion)
+	}
+}
+
+// CheckSchemaVersion validates an apiVersion read from a stored file that is
+// not decoded through the types in this package, e.g. snapshot metadata.
+func CheckSchemaVersion(apiVersion string) error {
+	return upgradeSchema(&apiVersion)
+}
+
+// The plain* aliases drop the methods below so encoding doesn't recurse.
+type (
+	plainSnapshot    ResourceSnapshot
+	plainDriftReport DriftReport
+)
+
+// MarshalJSON stamps the current SchemaVersion.
+func (s ResourceSnapshot) MarshalJSON() ([]byte, error) {
+	s.APIVersion = SchemaVersion
+	return json.Marshal(plainSnapshot(s))
+}
+
+// MarshalYAML stamps the current SchemaVersion.
+func (s ResourceSnapshot) MarshalYAML() (interface{}, error) {
+	s.APIVersion = SchemaVersion
+	return plainSnapshot(s), nil
+}
+
+// UnmarshalJSON decodes a snapshot of the current or an older schema.
+func (s *ResourceSnapshot) UnmarshalJSON(data []byte) error {
+	var p plainSnapshot
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if err := upgradeSchema(&p.APIVersion); err != nil {
+		return err
+	}
+	*s = ResourceSnapshot(p)
+	return nil
+}
+
+// UnmarshalYAML decodes a snapshot of the current or an older schema.
+func (s *ResourceSnapshot) UnmarshalYAML(node *yaml.Node) error {
+	var p plainSnapshot
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	if err := upgradeSchema(&p.APIVersion); err != nil {
+		return err
+	}
+	*s = ResourceSnapshot(p)
+	return nil
+}
+
+// MarshalJSON stamps the current SchemaVersion.
+func (r DriftReport) MarshalJSON() ([]byte, error) {
+	r.APIVersion = SchemaVersion
+	return json.Marshal(plainDriftReport(r))
+}
+
+// MarshalYAML stamps the current SchemaVersion.
+func (r DriftReport) MarshalYAML() (interface{}, error) {
+	r.APIVersion = SchemaVersion
+	return plainDriftReport(r), nil
+}
+
+// UnmarshalJSON decodes a report of the current or an older schema.
+func (r *DriftReport) UnmarshalJSON(data []byte) error {
+	var p plainDriftReport
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if err := upgradeSchema(&p.APIVersion); err != nil {
+		return err
+	}
+	*r = DriftReport(p)
+	return nil
+}
+
+// UnmarshalYAML decodes a report of the current or an older schema.
+func (r *DriftReport) UnmarshalYAML(node *yaml.Node) error {
+	var p plainDriftReport
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	if err := upgradeSchema(&p.APIVersion); err != nil {
+		return err
+	}
+	*r = DriftReport(p)
+	return nil
+}
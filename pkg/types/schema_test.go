@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDriftReport_StampsSchemaVersion(t *testing.T) {
+	report := DriftReport{BaseRef: "a", TargetRef: "b"}
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"apiVersion":"gitops-tm/v1"`)
+
+	data, err = yaml.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "apiVersion: gitops-tm/v1")
+}
+
+func TestResourceSnapshot_RoundTrip(t *testing.T) {
+	original := ResourceSnapshot{
+		Metadata:  SnapshotMetadata{ClusterName: "prod", ResourceCount: 1},
+		Resources: []Resource{{Kind: "Service", Namespace: "default", Name: "web"}},
+	}
+
+	data, err := yaml.Marshal(original)
+	require.NoError(t, err)
+
+	var decoded ResourceSnapshot
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+	assert.Equal(t, SchemaVersion, decoded.APIVersion)
+	assert.Equal(t, "prod", decoded.Metadata.ClusterName)
+	assert.Equal(t, "web", decoded.Resources[0].Name)
+}
+
+func TestDecode_LegacyDocuments(t *testing.T) {
+	var report DriftReport
+	require.NoError(t, json.Unmarshal([]byte(`{"baseRef":"abc","entries":[]}`), &report))
+	assert.Equal(t, SchemaVersion, report.APIVersion)
+	assert.Equal(t, "abc", report.BaseRef)
+
+	var snapshot ResourceSnapshot
+	require.NoError(t, yaml.Unmarshal([]byte("metadata:\n  clusterName: prod\n"), &snapshot))
+	assert.Equal(t, SchemaVersion, snapshot.APIVersion)
+	assert.Equal(t, "prod", snapshot.Metadata.ClusterName)
+}
+
+func TestDecode_FutureVersionRejected(t *testing.T) {
+	var report DriftReport
+	err := json.Unmarshal([]byte(`{"apiVersion":"gitops-tm/v9"}`), &report)
+	assert.ErrorContains(t, err, "unsupported apiVersion")
+
+	var snapshot ResourceSnapshot
+	err = yaml.Unmarshal([]byte("apiVersion: gitops-tm/v9\n"), &snapshot)
+	assert.ErrorContains(t, err, "unsupported apiVersion")
+}
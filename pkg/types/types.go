@@ -1,19 +1,36 @@
 // Package types defines shared data structures used across GitOps-Time-Machine.
 package types
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Resource represents a single Kubernetes resource's captured state.
 type Resource struct {
-	APIVersion string                 `json:"apiVersion" yaml:"apiVersion"`
-	Kind       string                 `json:"kind" yaml:"kind"`
-	Namespace  string                 `json:"namespace" yaml:"namespace"`
-	Name       string                 `json:"name" yaml:"name"`
-	Labels     map[string]string      `json:"labels,omitempty" yaml:"labels,omitempty"`
-	Annotations map[string]string     `json:"annotations,omitempty" yaml:"annotations,omitempty"`
-	Spec       map[string]interface{} `json:"spec,omitempty" yaml:"spec,omitempty"`
-	Data       map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
-	Raw        map[string]interface{} `json:"raw,omitempty" yaml:"-"`
+	APIVersion  string                 `json:"apiVersion" yaml:"apiVersion"`
+	Kind        string                 `json:"kind" yaml:"kind"`
+	Namespace   string                 `json:"namespace" yaml:"namespace"`
+	Name        string                 `json:"name" yaml:"name"`
+	Labels      map[string]string      `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string      `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Spec        map[string]interface{} `json:"spec,omitempty" yaml:"spec,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Raw         map[string]interface{} `json:"raw,omitempty" yaml:"-"`
+	// UID and CreationTimestamp are only kept with snapshot.track_lifecycle;
+	// a changed UID means the resource was deleted and recreated.
+	UID               string `json:"uid,omitempty" yaml:"uid,omitempty"`
+	CreationTimestamp string `json:"creationTimestamp,omitempty" yaml:"creationTimestamp,omitempty"`
+	// Managers lists the field managers that own the resource, sorted. It
+	// is only kept with snapshot.track_field_managers.
+	Managers []string `json:"managers,omitempty" yaml:"managers,omitempty"`
+	// ContentHash is the git blob hash of the file the resource was read
+	// from; resources with equal hashes have equal content. It is empty for
+	// resources collected from a cluster and is never serialized.
+	ContentHash string `json:"-" yaml:"-"`
 }
 
 // FullName returns namespace/kind/name identifier for the resource.
@@ -24,10 +41,60 @@ func (r Resource) FullName() string {
 	return r.Namespace + "/" + r.Kind + "/" + r.Name
 }
 
+// DisplayName returns the Kind/namespace/name identifier that drift reports
+// and notifications show, or Kind/name for cluster-scoped resources.
+func (r Resource) DisplayName() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// FieldManagers returns the sorted, distinct managers recorded in an
+// object's .metadata.managedFields.
+func FieldManagers(obj map[string]interface{}) []string {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	entries, _ := metadata["managedFields"].([]interface{})
+	seen := make(map[string]bool)
+	var managers []string
+	for _, e := range entries {
+		entry, _ := e.(map[string]interface{})
+		manager, _ := entry["manager"].(string)
+		if manager == "" || seen[manager] {
+			continue
+		}
+		seen[manager] = true
+		managers = append(managers, manager)
+	}
+	sort.Strings(managers)
+	return managers
+}
+
+// ParseFullName splits a name produced by FullName: namespace/Kind/name, or
+// Kind/name for cluster-scoped resources.
+func ParseFullName(s string) (namespace, kind, name string, err error) {
+	parts := strings.Split(s, "/")
+	for _, p := range parts {
+		if p == "" {
+			return "", "", "", fmt.Errorf("invalid resource %q (use namespace/Kind/name or Kind/name)", s)
+		}
+	}
+	switch len(parts) {
+	case 2:
+		return "", parts[0], parts[1], nil
+	case 3:
+		return parts[0], parts[1], parts[2], nil
+	default:
+		return "", "", "", fmt.Errorf("invalid resource %q (use namespace/Kind/name or Kind/name)", s)
+	}
+}
+
 // ResourceSnapshot represents a complete point-in-time capture of cluster state.
 type ResourceSnapshot struct {
-	Metadata  SnapshotMetadata `json:"metadata" yaml:"metadata"`
-	Resources []Resource       `json:"resources" yaml:"resources"`
+	// APIVersion is the serialization schema; see SchemaVersion.
+	APIVersion string           `json:"apiVersion" yaml:"apiVersion"`
+	Metadata   SnapshotMetadata `json:"metadata" yaml:"metadata"`
+	Resources  []Resource       `json:"resources" yaml:"resources"`
 }
 
 // SnapshotMetadata holds information about when and how a snapshot was taken.
@@ -38,23 +105,352 @@ type SnapshotMetadata struct {
 	ResourceCount int       `json:"resourceCount" yaml:"resourceCount"`
 	Namespaces    []string  `json:"namespaces" yaml:"namespaces"`
 	CommitHash    string    `json:"commitHash,omitempty" yaml:"commitHash,omitempty"`
+	// CommitURL links to the commit in the snapshot repo's forge, when
+	// git.links are configured or derived from the remote.
+	CommitURL string `json:"commitURL,omitempty" yaml:"commitURL,omitempty"`
+	// KindCounts and NamespaceCounts describe the snapshot's composition so
+	// it can be charted over time without reading every resource file.
+	// Cluster-scoped resources are counted under ClusterScope.
+	KindCounts      map[string]int `json:"kindCounts,omitempty" yaml:"kindCounts,omitempty"`
+	NamespaceCounts map[string]int `json:"namespaceCounts,omitempty" yaml:"namespaceCounts,omitempty"`
+	// ExpiringCertificates counts the certificates that had expired or
+	// were within expiry.warn_within of expiring when the snapshot was taken.
+	ExpiringCertificates int `json:"expiringCertificates,omitempty" yaml:"expiringCertificates,omitempty"`
+	// RBACExposure summarizes the effective permissions the snapshot's RBAC
+	// objects grant, so exposure can be followed over time.
+	RBACExposure *RBACExposure `json:"rbacExposure,omitempty" yaml:"rbacExposure,omitempty"`
+	// PolicyViolations counts the resources breaking the image policy
+	// when the snapshot was taken.
+	PolicyViolations int `json:"policyViolations,omitempty" yaml:"policyViolations,omitempty"`
+	// Scope is set on partial snapshots, which hold only some namespaces
+	// or kinds; resources outside it were not collected, and their files
+	// are carried over unchanged from the snapshot before.
+	Scope *SnapshotScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// HelmReleases are the latest revisions of the Helm releases recorded
+	// in the cluster when the snapshot was taken.
+	HelmReleases []HelmRelease `json:"helmReleases,omitempty" yaml:"helmReleases,omitempty"`
+	// Timings records how long each phase of the snapshot run took. Phases
+	// that run after _metadata.yaml is written are only known in memory.
+	Timings *PhaseTimings `json:"timings,omitempty" yaml:"timings,omitempty"`
+	// Collection records how listing each resource type went, for capacity
+	// planning and reviews of the load snapshots put on the API server.
+	Collection []CollectionStats `json:"collection,omitempty" yaml:"collection,omitempty"`
+	// Clusters records the outcome of each cluster of a fleet snapshot.
+	Clusters []ClusterStatus `json:"clusters,omitempty" yaml:"clusters,omitempty"`
+	// ContentHash is the snapshotter's digest of the resources written, so
+	// the next snapshot can tell without writing whether anything changed.
+	ContentHash string `json:"contentHash,omitempty" yaml:"contentHash,omitempty"`
+	// CI describes the CI pipeline run that took the snapshot, if any, so
+	// the snapshot can be traced to the deploy that caused it.
+	CI *CIMetadata `json:"ci,omitempty" yaml:"ci,omitempty"`
+}
+
+// CIMetadata identifies a CI pipeline run and the commit it built; see
+// ci.Detect.
+type CIMetadata struct {
+	// Provider names the CI system, e.g. github-actions or gitlab.
+	Provider    string `json:"provider" yaml:"provider"`
+	PipelineID  string `json:"pipelineID,omitempty" yaml:"pipelineID,omitempty"`
+	PipelineURL string `json:"pipelineURL,omitempty" yaml:"pipelineURL,omitempty"`
+	// Repository, CommitSHA, and Ref are those of the repository the
+	// pipeline runs for, usually the application being deployed.
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	CommitSHA  string `json:"commitSHA,omitempty" yaml:"commitSHA,omitempty"`
+	Ref        string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// Actor is the user who triggered the pipeline.
+	Actor string `json:"actor,omitempty" yaml:"actor,omitempty"`
+}
+
+// RBACExposure summarizes the effective permissions granted by RBAC
+// objects; see rbac.Exposure.
+type RBACExposure struct {
+	// Subjects counts the users, groups, and service accounts granted
+	// anything.
+	Subjects int `json:"subjects" yaml:"subjects"`
+	// Permissions counts the distinct subject, verb, resource, and
+	// namespace combinations granted.
+	Permissions int `json:"permissions" yaml:"permissions"`
+	// ClusterWide counts the permissions granted in every namespace.
+	ClusterWide int `json:"clusterWide" yaml:"clusterWide"`
+	// Wildcards counts the permissions granting any verb or any resource.
+	Wildcards int `json:"wildcards" yaml:"wildcards"`
+}
+
+// ClusterStatus is the outcome of collecting one cluster of a fleet.
+type ClusterStatus struct {
+	Name    string `json:"name" yaml:"name"`
+	Context string `json:"context" yaml:"context"`
+	// Error is why collection failed; the cluster's previous snapshot is kept.
+	Error         string        `json:"error,omitempty" yaml:"error,omitempty"`
+	ResourceCount int           `json:"resourceCount" yaml:"resourceCount"`
+	Duration      time.Duration `json:"duration" yaml:"duration"`
+}
+
+// Failed reports whether the cluster could not be collected.
+func (s ClusterStatus) Failed() bool {
+	return s.Error != ""
+}
+
+// PhaseTimings is the time spent in each phase of a snapshot run.
+type PhaseTimings struct {
+	Collection    time.Duration `json:"collection" yaml:"collection"`
+	Serialization time.Duration `json:"serialization" yaml:"serialization"`
+	Staging       time.Duration `json:"staging" yaml:"staging"`
+	Commit        time.Duration `json:"commit" yaml:"commit"`
+}
+
+// SnapshotScope is what a partial snapshot covers. An empty list covers
+// everything; with namespaces, cluster-scoped resources are not covered.
+type SnapshotScope struct {
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Kinds      []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`
+}
+
+// Contains reports whether a resource is in the scope. A nil scope
+// contains every resource.
+func (s *SnapshotScope) Contains(res Resource) bool {
+	return s.Covers(res.Namespace, res.Kind)
+}
+
+// Covers reports whether resources of a kind in a namespace ("" for
+// cluster-scoped ones) are in the scope. Kinds match case-insensitively,
+// as they appear in lower case in snapshot paths.
+func (s *SnapshotScope) Covers(namespace, kind string) bool {
+	if s == nil {
+		return true
+	}
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, namespace) {
+		return false
+	}
+	return len(s.Kinds) == 0 || slices.ContainsFunc(s.Kinds, func(k string) bool { return strings.EqualFold(k, kind) })
+}
+
+// String describes the scope, e.g. "namespaces team-a; kinds Deployment".
+func (s *SnapshotScope) String() string {
+	if s == nil {
+		return "everything"
+	}
+	var parts []string
+	if len(s.Namespaces) > 0 {
+		parts = append(parts, "namespaces "+strings.Join(s.Namespaces, ", "))
+	}
+	if len(s.Kinds) > 0 {
+		parts = append(parts, "kinds "+strings.Join(s.Kinds, ", "))
+	}
+	if len(parts) == 0 {
+		return "everything"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// HelmRelease is one revision of a Helm release, decoded from the release
+// record Helm stores in the cluster.
+type HelmRelease struct {
+	Name         string `json:"name" yaml:"name"`
+	Namespace    string `json:"namespace" yaml:"namespace"`
+	Revision     int    `json:"revision" yaml:"revision"`
+	Chart        string `json:"chart" yaml:"chart"`
+	ChartVersion string `json:"chartVersion" yaml:"chartVersion"`
+	AppVersion   string `json:"appVersion,omitempty" yaml:"appVersion,omitempty"`
+	// Status is Helm's status of the revision, e.g. deployed or failed.
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	// ValuesHash is a digest of the user-supplied values, so that a
+	// values-only upgrade is visible without storing the values.
+	ValuesHash string `json:"valuesHash,omitempty" yaml:"valuesHash,omitempty"`
+}
+
+// CollectionStats is how listing one resource type went in a snapshot run.
+type CollectionStats struct {
+	// Resource is the resource type as configured, e.g. deployments.
+	Resource string        `json:"resource" yaml:"resource"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	// Count is the number of resources kept, after namespace filters.
+	Count int `json:"count" yaml:"count"`
+	// Pages is the number of list calls that succeeded.
+	Pages int `json:"pages" yaml:"pages"`
+	// Errors is the number of list calls the API server failed, and
+	// Retries the number of times the listing started over after one.
+	Errors  int `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+	// Error is why the type could not be collected, if it could not.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Total returns the time spent across all phases.
+func (t PhaseTimings) Total() time.Duration {
+	return t.Collection + t.Serialization + t.Staging + t.Commit
+}
+
+// ClusterScope is the namespace key under which cluster-scoped resources are counted.
+const ClusterScope = "_cluster"
+
+// ImportedCluster is the cluster name of baseline snapshots imported from
+// manifests rather than collected from a cluster.
+const ImportedCluster = "imported"
+
+// UpdateCounts recomputes the resource counts in the metadata from Resources.
+func (s *ResourceSnapshot) UpdateCounts() {
+	s.Metadata.ResourceCount = len(s.Resources)
+	s.Metadata.KindCounts = make(map[string]int)
+	s.Metadata.NamespaceCounts = make(map[string]int)
+	for _, res := range s.Resources {
+		s.Metadata.KindCounts[res.Kind]++
+		ns := res.Namespace
+		if ns == "" {
+			ns = ClusterScope
+		}
+		s.Metadata.NamespaceCounts[ns]++
+	}
+}
+
+// Without returns a copy of the snapshot without the resources whose
+// FullName is in names.
+func (s *ResourceSnapshot) Without(names map[string]bool) *ResourceSnapshot {
+	out := *s
+	out.Resources = nil
+	for _, res := range s.Resources {
+		if !names[res.FullName()] {
+			out.Resources = append(out.Resources, res)
+		}
+	}
+	return &out
 }
 
 // DriftReport represents the results of comparing two snapshots.
 type DriftReport struct {
-	Timestamp time.Time    `json:"timestamp" yaml:"timestamp"`
-	BaseRef   string       `json:"baseRef" yaml:"baseRef"`
-	TargetRef string       `json:"targetRef" yaml:"targetRef"`
-	Summary   DriftSummary `json:"summary" yaml:"summary"`
-	Entries   []DriftEntry `json:"entries" yaml:"entries"`
+	// APIVersion is the serialization schema; see SchemaVersion.
+	APIVersion string    `json:"apiVersion" yaml:"apiVersion"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	BaseRef    string    `json:"baseRef" yaml:"baseRef"`
+	TargetRef  string    `json:"targetRef" yaml:"targetRef"`
+	// BaseURL and TargetURL link to the compared commits in the snapshot
+	// repository's forge, when links are configured.
+	BaseURL    string       `json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+	TargetURL  string       `json:"targetURL,omitempty" yaml:"targetURL,omitempty"`
+	Summary    DriftSummary `json:"summary" yaml:"summary"`
+	Entries    []DriftEntry `json:"entries" yaml:"entries"`
+	APIChanges []APIChange  `json:"apiChanges,omitempty" yaml:"apiChanges,omitempty"`
+	Anomalies  []Anomaly    `json:"anomalies,omitempty" yaml:"anomalies,omitempty"`
+	// Unmanaged lists the target's resources that no desired-state source
+	// accounts for, when orphans reporting is enabled.
+	Unmanaged []ResourceRef `json:"unmanaged,omitempty" yaml:"unmanaged,omitempty"`
+	// SuppressedBy names the maintenance window active when the report was
+	// produced; drift is still recorded but should not be alerted on.
+	SuppressedBy string `json:"suppressedBy,omitempty" yaml:"suppressedBy,omitempty"`
+	// PolicyViolations lists the target's resources that break a configured
+	// policy, such as the image policy, whether or not they drifted.
+	PolicyViolations []PolicyViolation `json:"policyViolations,omitempty" yaml:"policyViolations,omitempty"`
+	// Scope is set when either snapshot is partial: only the resources both
+	// snapshots cover were compared.
+	Scope *ScopeNote `json:"scope,omitempty" yaml:"scope,omitempty"`
+}
+
+// ScopeNote records the scopes of the compared snapshots and what was left
+// out of the comparison for being outside either.
+type ScopeNote struct {
+	Base   *SnapshotScope `json:"base,omitempty" yaml:"base,omitempty"`
+	Target *SnapshotScope `json:"target,omitempty" yaml:"target,omitempty"`
+	// OutOfScope counts, by kind, the resources of either snapshot that
+	// were not compared.
+	OutOfScope map[string]int `json:"outOfScope,omitempty" yaml:"outOfScope,omitempty"`
+}
+
+// Covered describes the intersection of both scopes, what was compared.
+func (n *ScopeNote) Covered() string {
+	var base, target SnapshotScope
+	if n.Base != nil {
+		base = *n.Base
+	}
+	if n.Target != nil {
+		target = *n.Target
+	}
+	namespaces, okNamespaces := intersect(base.Namespaces, target.Namespaces)
+	kinds, okKinds := intersect(base.Kinds, target.Kinds)
+	if !okNamespaces || !okKinds {
+		return "nothing, the scopes do not overlap"
+	}
+	return (&SnapshotScope{Namespaces: namespaces, Kinds: kinds}).String()
+}
+
+// Excluded returns the number of resources that were not compared.
+func (n *ScopeNote) Excluded() int {
+	total := 0
+	for _, count := range n.OutOfScope {
+		total += count
+	}
+	return total
+}
+
+// Describe explains the partial comparison in one sentence, naming what
+// was compared and counting what was not, e.g. "Compared only namespaces
+// team-a; 40 resources out of scope were not compared (30 ConfigMap, 10
+// Service)."
+func (n *ScopeNote) Describe() string {
+	text := "Compared only " + n.Covered()
+	excluded := n.Excluded()
+	if excluded == 0 {
+		return text + "."
+	}
+	kinds := make([]string, 0, len(n.OutOfScope))
+	for kind := range n.OutOfScope {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if n.OutOfScope[kinds[i]] != n.OutOfScope[kinds[j]] {
+			return n.OutOfScope[kinds[i]] > n.OutOfScope[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	counts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		counts[i] = fmt.Sprintf("%d %s", n.OutOfScope[kind], kind)
+	}
+	return fmt.Sprintf("%s; %d resources out of scope were not compared (%s).", text, excluded, strings.Join(counts, ", "))
+}
+
+// intersect intersects two scope lists, where an empty list covers
+// everything. It returns false if the lists have nothing in common.
+func intersect(a, b []string) ([]string, bool) {
+	if len(a) == 0 {
+		return b, true
+	}
+	if len(b) == 0 {
+		return a, true
+	}
+	var common []string
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			common = append(common, v)
+		}
+	}
+	return common, len(common) > 0
+}
+
+// PolicyViolation is a resource that breaks a configured policy.
+type PolicyViolation struct {
+	// Rule names the broken rule, e.g. image-registry.
+	Rule     string      `json:"rule" yaml:"rule"`
+	Severity string      `json:"severity" yaml:"severity"`
+	Resource ResourceRef `json:"resource" yaml:"resource"`
+	Message  string      `json:"message" yaml:"message"`
+}
+
+// ResourceRef identifies a resource without its content.
+type ResourceRef struct {
+	Kind      string `json:"kind" yaml:"kind"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Name      string `json:"name" yaml:"name"`
 }
 
 // DriftSummary provides a high-level overview of the drift.
 type DriftSummary struct {
-	TotalResources    int `json:"totalResources" yaml:"totalResources"`
-	AddedResources    int `json:"addedResources" yaml:"addedResources"`
-	RemovedResources  int `json:"removedResources" yaml:"removedResources"`
-	ModifiedResources int `json:"modifiedResources" yaml:"modifiedResources"`
+	TotalResources     int `json:"totalResources" yaml:"totalResources"`
+	AddedResources     int `json:"addedResources" yaml:"addedResources"`
+	RemovedResources   int `json:"removedResources" yaml:"removedResources"`
+	ModifiedResources  int `json:"modifiedResources" yaml:"modifiedResources"`
+	RecreatedResources int `json:"recreatedResources" yaml:"recreatedResources"`
+	ScaledResources    int `json:"scaledResources" yaml:"scaledResources"`
+	OwnershipChanges   int `json:"ownershipChanges" yaml:"ownershipChanges"`
 	UnchangedResources int `json:"unchangedResources" yaml:"unchangedResources"`
 }
 
@@ -65,13 +461,52 @@ const (
 	DriftAdded    DriftType = "ADDED"
 	DriftRemoved  DriftType = "REMOVED"
 	DriftModified DriftType = "MODIFIED"
+	// DriftRecreated is a resource deleted and recreated under the same name.
+	DriftRecreated DriftType = "RECREATED"
+	// DriftScaled is a resource whose replica count is the only change.
+	DriftScaled DriftType = "SCALED"
+	// DriftOwnership is a resource whose field managers are the only change.
+	DriftOwnership DriftType = "OWNERSHIP"
 )
 
 // DriftEntry represents a single drift item between two snapshots.
 type DriftEntry struct {
-	Type       DriftType              `json:"type" yaml:"type"`
-	Resource   Resource               `json:"resource" yaml:"resource"`
-	FieldDiffs []FieldDiff            `json:"fieldDiffs,omitempty" yaml:"fieldDiffs,omitempty"`
+	Type       DriftType   `json:"type" yaml:"type"`
+	Resource   Resource    `json:"resource" yaml:"resource"`
+	FieldDiffs []FieldDiff `json:"fieldDiffs,omitempty" yaml:"fieldDiffs,omitempty"`
+	Team       string      `json:"team,omitempty" yaml:"team,omitempty"`
+	// Release is the Helm release that installed the resource, as
+	// namespace/name; see helm.ReleaseOf.
+	Release string `json:"release,omitempty" yaml:"release,omitempty"`
+	// Severity is low, medium, high, or critical; see policy.Classify.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Summaries explain the semantic effect of the change, for kinds where
+	// field paths alone are hard to reason about (e.g. NetworkPolicy).
+	Summaries []string `json:"summaries,omitempty" yaml:"summaries,omitempty"`
+	// URL links to the resource's file in the snapshot repository's forge.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+}
+
+// APIChange describes a change to the API served by a CustomResourceDefinition.
+type APIChange struct {
+	CRD             string    `json:"crd" yaml:"crd"`
+	Type            DriftType `json:"type" yaml:"type"`
+	AddedVersions   []string  `json:"addedVersions,omitempty" yaml:"addedVersions,omitempty"`
+	RemovedVersions []string  `json:"removedVersions,omitempty" yaml:"removedVersions,omitempty"`
+	SchemaChanged   []string  `json:"schemaChanged,omitempty" yaml:"schemaChanged,omitempty"`
+	OldStorage      string    `json:"oldStorageVersion,omitempty" yaml:"oldStorageVersion,omitempty"`
+	NewStorage      string    `json:"newStorageVersion,omitempty" yaml:"newStorageVersion,omitempty"`
+}
+
+// Anomaly flags a snapshot delta that is statistically unusual for its series.
+type Anomaly struct {
+	// Series is "total", "kind/<Kind>", or "namespace/<namespace>".
+	Series  string  `json:"series" yaml:"series"`
+	Changes int     `json:"changes" yaml:"changes"`
+	Mean    float64 `json:"mean" yaml:"mean"`
+	StdDev  float64 `json:"stddev" yaml:"stddev"`
+	// Score is the number of standard deviations above the mean.
+	Score float64 `json:"score" yaml:"score"`
 }
 
 // FieldDiff represents a change in a specific field of a resource.
@@ -83,9 +518,39 @@ type FieldDiff struct {
 
 // HistoryEntry represents a single entry in the snapshot history.
 type HistoryEntry struct {
-	CommitHash    string    `json:"commitHash" yaml:"commitHash"`
-	Timestamp     time.Time `json:"timestamp" yaml:"timestamp"`
-	Message       string    `json:"message" yaml:"message"`
-	ResourceCount int       `json:"resourceCount" yaml:"resourceCount"`
-	Author        string    `json:"author" yaml:"author"`
+	CommitHash      string         `json:"commitHash" yaml:"commitHash"`
+	Timestamp       time.Time      `json:"timestamp" yaml:"timestamp"`
+	Message         string         `json:"message" yaml:"message"`
+	ResourceCount   int            `json:"resourceCount" yaml:"resourceCount"`
+	Author          string         `json:"author" yaml:"author"`
+	ClusterName     string         `json:"clusterName,omitempty" yaml:"clusterName,omitempty"`
+	Context         string         `json:"context,omitempty" yaml:"context,omitempty"`
+	Namespaces      []string       `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	KindCounts      map[string]int `json:"kindCounts,omitempty" yaml:"kindCounts,omitempty"`
+	NamespaceCounts map[string]int `json:"namespaceCounts,omitempty" yaml:"namespaceCounts,omitempty"`
+	ContentHash     string         `json:"contentHash,omitempty" yaml:"contentHash,omitempty"`
+	RBACExposure    *RBACExposure  `json:"rbacExposure,omitempty" yaml:"rbacExposure,omitempty"`
+	CI              *CIMetadata    `json:"ci,omitempty" yaml:"ci,omitempty"`
+	// Scope is set on partial snapshots.
+	Scope *SnapshotScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// Collection is the snapshot run's per-type collection statistics.
+	Collection []CollectionStats `json:"collection,omitempty" yaml:"collection,omitempty"`
+}
+
+// ResourceVersion is one version of a resource in the snapshot history.
+type ResourceVersion struct {
+	CommitHash string    `json:"commitHash" yaml:"commitHash"`
+	Timestamp  time.Time `json:"timestamp" yaml:"timestamp"`
+	Message    string    `json:"message" yaml:"message"`
+	// Deleted marks the commit that removed the resource; Resource is nil.
+	Deleted  bool      `json:"deleted,omitempty" yaml:"deleted,omitempty"`
+	Resource *Resource `json:"resource,omitempty" yaml:"resource,omitempty"`
+}
+
+// Lifespan is a period during which a resource existed in the snapshots.
+type Lifespan struct {
+	// Appeared is the first snapshot containing the resource.
+	Appeared ResourceVersion `json:"appeared" yaml:"appeared"`
+	// Removed is the snapshot that deleted it, or nil if it still exists.
+	Removed *ResourceVersion `json:"removed,omitempty" yaml:"removed,omitempty"`
 }
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFullName(t *testing.T) {
+	ns, kind, name, err := ParseFullName("prod/Deployment/api")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"prod", "Deployment", "api"}, []string{ns, kind, name})
+
+	ns, kind, name, err = ParseFullName("ClusterRole/admin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "ClusterRole", "admin"}, []string{ns, kind, name})
+
+	for _, bad := range []string{"api", "a/b/c/d", "prod//api", ""} {
+		_, _, _, err := ParseFullName(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	assert.Equal(t, "Deployment/prod/api", Resource{Kind: "Deployment", Namespace: "prod", Name: "api"}.DisplayName())
+	assert.Equal(t, "ClusterRole/admin", Resource{Kind: "ClusterRole", Name: "admin"}.DisplayName())
+}
+
+func TestWithout(t *testing.T) {
+	api := Resource{Kind: "Deployment", Namespace: "prod", Name: "api"}
+	web := Resource{Kind: "Deployment", Namespace: "prod", Name: "web"}
+	snap := &ResourceSnapshot{Resources: []Resource{api, web}}
+
+	out := snap.Without(map[string]bool{"prod/Deployment/api": true})
+	assert.Equal(t, []Resource{web}, out.Resources)
+	assert.Len(t, snap.Resources, 2)
+}
+
+func TestSnapshotScope(t *testing.T) {
+	deploy := Resource{Kind: "Deployment", Namespace: "team-a", Name: "api"}
+	other := Resource{Kind: "Deployment", Namespace: "team-b", Name: "api"}
+	cm := Resource{Kind: "ConfigMap", Namespace: "team-a", Name: "settings"}
+	role := Resource{Kind: "ClusterRole", Name: "admin"}
+
+	var all *SnapshotScope
+	assert.True(t, all.Contains(role))
+	assert.Equal(t, "everything", all.String())
+
+	scope := &SnapshotScope{Namespaces: []string{"team-a"}, Kinds: []string{"Deployment"}}
+	assert.True(t, scope.Contains(deploy))
+	assert.False(t, scope.Contains(other))
+	assert.False(t, scope.Contains(cm))
+	assert.False(t, scope.Contains(role), "cluster-scoped resources are outside a namespace scope")
+	assert.Equal(t, "namespaces team-a; kinds Deployment", scope.String())
+
+	kinds := &SnapshotScope{Kinds: []string{"ClusterRole"}}
+	assert.True(t, kinds.Contains(role))
+	assert.False(t, kinds.Contains(deploy))
+}
+
+func TestScopeNote(t *testing.T) {
+	note := &ScopeNote{
+		Base:       &SnapshotScope{Namespaces: []string{"team-a", "team-b"}},
+		Target:     &SnapshotScope{Namespaces: []string{"team-b", "team-c"}, Kinds: []string{"Deployment"}},
+		OutOfScope: map[string]int{"Service": 2, "ConfigMap": 5, "Secret": 2},
+	}
+	assert.Equal(t, "namespaces team-b; kinds Deployment", note.Covered())
+	assert.Equal(t, 9, note.Excluded())
+	assert.Equal(t, "Compared only namespaces team-b; kinds Deployment; 9 resources out of scope were not compared (5 ConfigMap, 2 Secret, 2 Service).", note.Describe())
+
+	full := &ScopeNote{Target: &SnapshotScope{Kinds: []string{"Deployment"}}}
+	assert.Equal(t, "Compared only kinds Deployment.", full.Describe())
+
+	disjoint := &ScopeNote{Base: &SnapshotScope{Namespaces: []string{"a"}}, Target: &SnapshotScope{Namespaces: []string{"b"}}}
+	assert.Equal(t, "nothing, the scopes do not overlap", disjoint.Covered())
+}
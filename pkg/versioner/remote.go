@@ -0,0 +1,405 @@
+package versioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// ValidateAuth checks that at most one kind of credentials is configured.
+func ValidateAuth(cfg *config.GitAuthConfig) error {
+	set := 0
+	for _, v := range []string{cfg.SSHKey, cfg.Token, cfg.Password} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("git.auth: set only one of ssh_key, token, and password")
+	}
+	if cfg.Password != "" && cfg.Username == "" {
+		return fmt.Errorf("git.auth.password requires git.auth.username")
+	}
+	if cfg.SSHKeyPassphrase != "" && cfg.SSHKey == "" {
+		return fmt.Errorf("git.auth.ssh_key_passphrase requires git.auth.ssh_key")
+	}
+	return nil
+}
+
+// authMethod returns the transport credentials of cfg, or nil to use the
+// transport's defaults (the SSH agent for SSH remotes).
+func authMethod(cfg *config.GitAuthConfig) (transport.AuthMethod, error) {
+	username := cfg.Username
+	if username == "" {
+		username = "git"
+	}
+	switch {
+	case cfg.SSHKey != "":
+		auth, err := gitssh.NewPublicKeysFromFile(username, cfg.SSHKey, cfg.SSHKeyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSH key %s: %w", cfg.SSHKey, err)
+		}
+		return auth, nil
+	case cfg.Token != "":
+		return &githttp.BasicAuth{Username: username, Password: cfg.Token}, nil
+	case cfg.Password != "":
+		return &githttp.BasicAuth{Username: cfg.Username, Password: cfg.Password}, nil
+	}
+	return nil, nil
+}
+
+// IsURL reports whether repo names a remote repository (e.g.
+// https://github.com/acme/snapshots.git or git@github.com:acme/snapshots)
+// rather than a local path.
+func IsURL(repo string) bool {
+	return strings.Contains(repo, "://") || (strings.Contains(repo, "@") && strings.Contains(repo, ":") && !filepath.IsAbs(repo))
+}
+
+// ValidateClone checks the clone settings used for --repo URLs.
+func ValidateClone(cfg *config.CloneConfig) error {
+	if cfg.Depth < 0 {
+		return fmt.Errorf("git.clone.depth must not be negative")
+	}
+	for _, p := range cfg.Paths {
+		if p == "" || path.IsAbs(p) || strings.HasPrefix(p, "..") || strings.Contains(p, `\`) {
+			return fmt.Errorf("git.clone.paths: %q must be a slash-separated directory inside the repository", p)
+		}
+	}
+	return nil
+}
+
+// Clone makes dir a copy of the configured branch of the repository at
+// url: it is cloned on first use and fetched and reset to the remote
+// branch afterwards, so that a snapshot repository pushed from elsewhere
+// (e.g. by in-cluster watch) can be read locally. Local changes in dir are
+// discarded. With git.clone.depth, only the latest commits are fetched;
+// with git.clone.paths, only those directories are checked out.
+func Clone(ctx context.Context, url, dir string, cfg *config.GitConfig) error {
+	auth, err := authMethod(&cfg.Auth)
+	if err != nil {
+		return err
+	}
+	ref := plumbing.NewBranchReferenceName(cfg.Branch)
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL:           url,
+			Auth:          auth,
+			ReferenceName: ref,
+			SingleBranch:  true,
+			Depth:         cfg.Clone.Depth,
+		})
+		if err == nil && len(cfg.Clone.Paths) > 0 {
+			err = checkoutSparse(repo, ref, cfg.Clone.Paths)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return fmt.Errorf("failed to clone %s: %w", url, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open clone of %s: %w", url, err)
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{RemoteName: "origin", Auth: auth, Force: true, Depth: cfg.Clone.Depth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	remote, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", cfg.Branch), true)
+	if err != nil {
+		return fmt.Errorf("failed to find branch %s of %s: %w", cfg.Branch, url, err)
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if len(cfg.Clone.Paths) > 0 {
+		// go-git applies a sparse checkout reliably only over a full one,
+		// so the files skipped last time are checked out again first
+		if err := repo.Storer.SetIndex(&index.Index{Version: 2}); err != nil {
+			return fmt.Errorf("failed to clear index of %s: %w", dir, err)
+		}
+	}
+	if err := w.Checkout(&git.CheckoutOptions{Branch: ref, Force: true}); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", cfg.Branch, err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: remote.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to update clone of %s: %w", url, err)
+	}
+	if len(cfg.Clone.Paths) > 0 {
+		if err := checkoutSparse(repo, ref, cfg.Clone.Paths); err != nil {
+			return fmt.Errorf("failed to update clone of %s: %w", url, err)
+		}
+	}
+	return nil
+}
+
+// checkoutSparse narrows the checkout of branch ref to dirs.
+func checkoutSparse(repo *git.Repository, ref plumbing.ReferenceName, dirs []string) error {
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	return w.Checkout(&git.CheckoutOptions{Branch: ref, Force: true, SparseCheckoutDirectories: dirs})
+}
+
+// Push pushes the configured branch to the remote named by links.remote.
+// If the remote branch has commits the local one lacks, e.g. from another
+// writer or a push that failed halfway, the local commits are first
+// rebased onto it: each is replayed as the changes it made, so files only
+// the remote changed are kept and files both changed take the local
+// snapshot's version. The local branches matching the given names, which
+// may end in a "*" wildcard (e.g. "tenants/*"), are pushed too, as they are.
+func (v *Versioner) Push(ctx context.Context, branches ...string) error {
+	unlock, err := v.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	name := v.remoteName()
+	if url, err := v.RemoteURL(name); err != nil {
+		return err
+	} else if url == "" {
+		return fmt.Errorf("snapshot repository %s has no remote %s (set git.remote_url)", v.repoPath, name)
+	}
+	auth, err := authMethod(&v.config.Auth)
+	if err != nil {
+		return err
+	}
+
+	branch := plumbing.NewBranchReferenceName(v.config.Branch)
+	err = v.repo.FetchContext(ctx, &git.FetchOptions{RemoteName: name, Auth: auth})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return fmt.Errorf("failed to fetch from %s: %w", name, err)
+	}
+	if remote, err := v.repo.Reference(plumbing.NewRemoteReferenceName(name, v.config.Branch), true); err == nil {
+		if err := v.rebaseOnto(remote.Hash()); err != nil {
+			return err
+		}
+	}
+
+	refSpecs := []gitconfig.RefSpec{gitconfig.RefSpec(branch + ":" + branch)}
+	for _, b := range branches {
+		ref := plumbing.NewBranchReferenceName(b)
+		refSpecs = append(refSpecs, gitconfig.RefSpec(ref+":"+ref))
+	}
+	err = v.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: name,
+		Auth:       auth,
+		RefSpecs:   refSpecs,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to push to %s: %w", name, err)
+	}
+	v.logger.WithFields(log.Fields{"remote": name, "branch": v.config.Branch}).Info("snapshots pushed")
+	return nil
+}
+
+// remoteName is the remote pushed to: links.remote, or origin.
+func (v *Versioner) remoteName() string {
+	if v.config.Links.Remote != "" {
+		return v.config.Links.Remote
+	}
+	return "origin"
+}
+
+// Unpushed counts the commits of the configured branch, following first
+// parents, that the remote branch lacked when it was last fetched or
+// pushed. If it never was, every commit is unpushed.
+func (v *Versioner) Unpushed() (int, error) {
+	head, err := v.repo.Reference(plumbing.NewBranchReferenceName(v.config.Branch), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve branch %s: %w", v.config.Branch, err)
+	}
+	local, err := v.repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	base := make(map[plumbing.Hash]bool)
+	if remote, err := v.repo.Reference(plumbing.NewRemoteReferenceName(v.remoteName(), v.config.Branch), true); err == nil {
+		upstream, err := v.repo.CommitObject(remote.Hash())
+		if err != nil {
+			return 0, fmt.Errorf("failed to get commit object: %w", err)
+		}
+		bases, err := local.MergeBase(upstream)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find merge base: %w", err)
+		}
+		for _, b := range bases {
+			base[b.Hash] = true
+		}
+	}
+
+	n := 0
+	for c := local; !base[c.Hash]; n++ {
+		if c.NumParents() == 0 {
+			return n + 1, nil
+		}
+		if c, err = c.Parent(0); err != nil {
+			return 0, fmt.Errorf("failed to read parent: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// rebaseOnto replays the commits of the configured branch that upstream
+// lacks on top of upstream. It does nothing if the branch already contains
+// upstream, and fast-forwards if upstream contains the branch. The branch
+// must be checked out. If replaying fails, the branch and working tree are
+// reset to where they were, so no unpushed snapshot is lost.
+func (v *Versioner) rebaseOnto(upstream plumbing.Hash) (err error) {
+	branch := plumbing.NewBranchReferenceName(v.config.Branch)
+	head, err := v.repo.Reference(branch, true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve branch %s: %w", v.config.Branch, err)
+	}
+	// Resets move the checked-out branch, so it must be the one rebased
+	if current, err := v.repo.Reference(plumbing.HEAD, false); err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	} else if current.Type() != plumbing.SymbolicReference || current.Target() != branch {
+		return fmt.Errorf("cannot rebase %s onto the remote: it is not checked out", v.config.Branch)
+	}
+	local, err := v.repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get commit object: %w", err)
+	}
+	onto, err := v.repo.CommitObject(upstream)
+	if err != nil {
+		return fmt.Errorf("failed to get commit object: %w", err)
+	}
+	if contains, err := onto.IsAncestor(local); err != nil || contains {
+		return err
+	}
+
+	bases, err := local.MergeBase(onto)
+	if err != nil {
+		return fmt.Errorf("failed to find merge base: %w", err)
+	}
+	base := make(map[plumbing.Hash]bool)
+	for _, b := range bases {
+		base[b.Hash] = true
+	}
+	// The local commits, oldest first, following first parents
+	var commits []*object.Commit
+	for c := local; !base[c.Hash]; {
+		commits = append([]*object.Commit{c}, commits...)
+		if c.NumParents() == 0 {
+			break
+		}
+		if c, err = c.Parent(0); err != nil {
+			return fmt.Errorf("failed to read parent of %s: %w", commits[0].Hash.String()[:8], err)
+		}
+	}
+
+	w, err := v.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	// go-git keeps no reflog, so the original commits are only reachable
+	// through this hash once the branch is reset
+	original := head.Hash()
+	defer func() {
+		if err == nil {
+			return
+		}
+		if resetErr := w.Reset(&git.ResetOptions{Commit: original, Mode: git.HardReset}); resetErr != nil {
+			err = fmt.Errorf("%w; restoring %s to %s also failed: %v", err, v.config.Branch, original.String()[:8], resetErr)
+		}
+	}()
+	if err := w.Reset(&git.ResetOptions{Commit: upstream, Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", upstream.String()[:8], err)
+	}
+	for _, c := range commits {
+		if err := v.replay(w, c); err != nil {
+			return err
+		}
+	}
+	if len(commits) > 0 {
+		v.logger.WithFields(log.Fields{
+			"commits": len(commits),
+			"onto":    upstream.String()[:8],
+		}).Info("rebased snapshots onto the remote")
+	}
+	return nil
+}
+
+// replay applies the changes c made to its first parent to the working
+// tree and commits them with c's message and author.
+func (v *Versioner) replay(w *git.Worktree, c *object.Commit) error {
+	tree, err := c.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to read tree of %s: %w", c.Hash.String()[:8], err)
+	}
+	parentTree := &object.Tree{}
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return fmt.Errorf("failed to read parent of %s: %w", c.Hash.String()[:8], err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return fmt.Errorf("failed to read tree of %s: %w", parent.Hash.String()[:8], err)
+		}
+	}
+	changes, err := parentTree.Diff(tree)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s: %w", c.Hash.String()[:8], err)
+	}
+
+	for _, change := range changes {
+		if change.To.Name == "" {
+			if err := os.Remove(filepath.Join(v.repoPath, filepath.FromSlash(change.From.Name))); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", change.From.Name, err)
+			}
+			continue
+		}
+		file, err := tree.TreeEntryFile(&change.To.TreeEntry)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", change.To.Name, err)
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", change.To.Name, err)
+		}
+		path := filepath.Join(v.repoPath, filepath.FromSlash(change.To.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", change.To.Name, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", change.To.Name, err)
+		}
+	}
+
+	if err := w.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+	author := c.Author
+	if _, err := w.Commit(c.Message, &git.CommitOptions{Author: &author, AllowEmptyCommits: true}); err != nil {
+		return fmt.Errorf("failed to replay %s: %w", c.Hash.String()[:8], err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package versioner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRemote creates a bare repository and a versioner pushing to it.
+func newTestRemote(t *testing.T) (string, *config.GitConfig) {
+	t.Helper()
+	remote := t.TempDir()
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+	cfg := config.DefaultConfig().Git
+	cfg.RemoteURL = "file://" + remote
+	return remote, &cfg
+}
+
+func TestClone_ClonesAndUpdates(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	first := commitFile(t, v, dir, "_metadata.yaml", "resourceCount: 1\n", time.Now().UTC())
+	cfg := config.DefaultConfig().Git
+	clone := filepath.Join(t.TempDir(), "clone")
+
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+	cloned, err := New(clone, &cfg)
+	require.NoError(t, err)
+	head, err := cloned.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, first, head)
+
+	second := commitFile(t, v, dir, "_metadata.yaml", "resourceCount: 2\n", time.Now().UTC())
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+	cloned, err = New(clone, &cfg)
+	require.NoError(t, err)
+	head, err = cloned.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, second, head)
+	data, err := os.ReadFile(filepath.Join(clone, "_metadata.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "resourceCount: 2\n", string(data))
+}
+
+func TestClone_Shallow(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	for _, ns := range []string{"prod", "staging"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ns, "deployment"), 0755))
+	}
+	commitFile(t, v, dir, "prod/deployment/api.yaml", "replicas: 1\n", time.Now().UTC())
+	commitFile(t, v, dir, "staging/deployment/api.yaml", "replicas: 1\n", time.Now().UTC())
+	latest := commitFile(t, v, dir, "_metadata.yaml", "resourceCount: 2\n", time.Now().UTC())
+	cfg := config.DefaultConfig().Git
+	cfg.Clone = config.CloneConfig{Depth: 1}
+	clone := filepath.Join(t.TempDir(), "clone")
+
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+
+	assert.FileExists(t, filepath.Join(clone, "staging", "deployment", "api.yaml"))
+	cloned, err := New(clone, &cfg)
+	require.NoError(t, err)
+	entries, err := cloned.History(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only the latest commit is fetched")
+	assert.Equal(t, latest, entries[0].CommitHash)
+	assert.Equal(t, 2, entries[0].ResourceCount)
+
+	next := commitFile(t, v, dir, "prod/deployment/api.yaml", "replicas: 2\n", time.Now().UTC())
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+	cloned, err = New(clone, &cfg)
+	require.NoError(t, err)
+	head, err := cloned.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, next, head)
+	data, err := os.ReadFile(filepath.Join(clone, "prod", "deployment", "api.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 2\n", string(data))
+}
+
+func TestClone_Sparse(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	for _, ns := range []string{"prod", "staging"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ns, "deployment"), 0755))
+	}
+	commitFile(t, v, dir, "prod/deployment/api.yaml", "replicas: 1\n", time.Now().UTC())
+	commitFile(t, v, dir, "staging/deployment/api.yaml", "replicas: 1\n", time.Now().UTC())
+	cfg := config.DefaultConfig().Git
+	cfg.Clone = config.CloneConfig{Paths: []string{"prod"}}
+	clone := filepath.Join(t.TempDir(), "clone")
+
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+
+	assert.FileExists(t, filepath.Join(clone, "prod", "deployment", "api.yaml"))
+	assert.NoFileExists(t, filepath.Join(clone, "staging", "deployment", "api.yaml"))
+	cloned, err := New(clone, &cfg)
+	require.NoError(t, err)
+	entries, err := cloned.History(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "history is not limited to the checked out paths")
+
+	commitFile(t, v, dir, "staging/deployment/api.yaml", "replicas: 2\n", time.Now().UTC())
+	next := commitFile(t, v, dir, "prod/deployment/api.yaml", "replicas: 2\n", time.Now().UTC())
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+	cloned, err = New(clone, &cfg)
+	require.NoError(t, err)
+	head, err := cloned.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, next, head)
+	data, err := os.ReadFile(filepath.Join(clone, "prod", "deployment", "api.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 2\n", string(data))
+	assert.NoFileExists(t, filepath.Join(clone, "staging", "deployment", "api.yaml"))
+
+	// Files added outside the paths stay out, and unchanged ones stay away
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "staging", "service"), 0755))
+	commitFile(t, v, dir, "staging/service/web.yaml", "port: 80\n", time.Now().UTC())
+	commitFile(t, v, dir, "prod/deployment/api.yaml", "replicas: 3\n", time.Now().UTC())
+	require.NoError(t, Clone(context.Background(), "file://"+dir, clone, &cfg))
+	data, err = os.ReadFile(filepath.Join(clone, "prod", "deployment", "api.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "replicas: 3\n", string(data))
+	assert.NoDirExists(t, filepath.Join(clone, "staging"))
+}
+
+func TestValidateClone(t *testing.T) {
+	assert.NoError(t, ValidateClone(&config.CloneConfig{Depth: 50}))
+	assert.NoError(t, ValidateClone(&config.CloneConfig{Paths: []string{"payments", "eu/payments"}}))
+	assert.Error(t, ValidateClone(&config.CloneConfig{Depth: -1}))
+	for _, bad := range []string{"", "/srv/snapshots", "../other"} {
+		assert.Error(t, ValidateClone(&config.CloneConfig{Paths: []string{bad}}), bad)
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	assert.True(t, IsURL("https://github.com/acme/snapshots.git"))
+	assert.True(t, IsURL("git@github.com:acme/snapshots.git"))
+	assert.True(t, IsURL("file:///srv/snapshots"))
+	assert.False(t, IsURL("./infra-snapshots"))
+	assert.False(t, IsURL("/srv/snapshots"))
+}
+
+func TestPush(t *testing.T) {
+	remote, cfg := newTestRemote(t)
+	dir := t.TempDir()
+	v, err := New(dir, cfg)
+	require.NoError(t, err)
+	hash := commitFile(t, v, dir, "a.yaml", "a: 1\n", time.Now().UTC())
+
+	require.NoError(t, v.Push(context.Background()))
+	// Nothing new to push
+	require.NoError(t, v.Push(context.Background()))
+
+	repo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(cfg.Branch), true)
+	require.NoError(t, err)
+	assert.Equal(t, hash, ref.Hash().String())
+}
+
+func TestPush_Branches(t *testing.T) {
+	remote, cfg := newTestRemote(t)
+	dir := t.TempDir()
+	v, err := New(dir, cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team-a"), 0755))
+	hash := commitFile(t, v, dir, "team-a/a.yaml", "a: 1\n", time.Now().UTC())
+	mirrored, err := v.MirrorDir(hash, "team-a", "tenants/team-a")
+	require.NoError(t, err)
+
+	require.NoError(t, v.Push(context.Background(), "tenants/*"))
+
+	repo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName("tenants/team-a"), true)
+	require.NoError(t, err)
+	assert.Equal(t, mirrored, ref.Hash().String())
+}
+
+func TestUnpushed(t *testing.T) {
+	_, cfg := newTestRemote(t)
+	dir := t.TempDir()
+	v, err := New(dir, cfg)
+	require.NoError(t, err)
+
+	n, err := v.Unpushed()
+	require.NoError(t, err)
+	assert.Zero(t, n, "an empty repository has nothing to push")
+
+	commitFile(t, v, dir, "a.yaml", "a: 1\n", time.Now().UTC())
+	commitFile(t, v, dir, "a.yaml", "a: 2\n", time.Now().UTC())
+	n, err = v.Unpushed()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "nothing was ever pushed")
+
+	require.NoError(t, v.Push(context.Background()))
+	n, err = v.Unpushed()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	commitFile(t, v, dir, "a.yaml", "a: 3\n", time.Now().UTC())
+	n, err = v.Unpushed()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPush_RebasesOntoRemote(t *testing.T) {
+	_, cfg := newTestRemote(t)
+	now := time.Now().UTC()
+
+	// Two writers start from the same pushed history
+	dirA := t.TempDir()
+	a, err := New(dirA, cfg)
+	require.NoError(t, err)
+	commitFile(t, a, dirA, "shared.yaml", "v: 0\n", now)
+	require.NoError(t, a.Push(context.Background()))
+	dirB := filepath.Join(t.TempDir(), "b")
+	require.NoError(t, Clone(context.Background(), cfg.RemoteURL, dirB, cfg))
+	b, err := New(dirB, cfg)
+	require.NoError(t, err)
+
+	// Both commit; A pushes first
+	require.NoError(t, os.WriteFile(filepath.Join(dirA, "a.yaml"), []byte("a: 1\n"), 0644))
+	commitFile(t, a, dirA, "shared.yaml", "v: a\n", now.Add(time.Minute))
+	require.NoError(t, a.Push(context.Background()))
+	require.NoError(t, os.WriteFile(filepath.Join(dirB, "b.yaml"), []byte("b: 1\n"), 0644))
+	commitFile(t, b, dirB, "shared.yaml", "v: b\n", now.Add(2*time.Minute))
+
+	require.NoError(t, b.Push(context.Background()))
+
+	for name, want := range map[string]string{"a.yaml": "a: 1\n", "b.yaml": "b: 1\n", "shared.yaml": "v: b\n"} {
+		data, err := os.ReadFile(filepath.Join(dirB, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data), name)
+	}
+	entries, err := b.History(0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// A fast-forwards to B's rebased commit
+	require.NoError(t, a.Push(context.Background()))
+	data, err := os.ReadFile(filepath.Join(dirA, "b.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "b: 1\n", string(data))
+}
+
+func TestPush_RestoresBranchWhenReplayFails(t *testing.T) {
+	_, cfg := newTestRemote(t)
+	now := time.Now().UTC()
+
+	dirA := t.TempDir()
+	a, err := New(dirA, cfg)
+	require.NoError(t, err)
+	commitFile(t, a, dirA, "shared.yaml", "v: 0\n", now)
+	require.NoError(t, a.Push(context.Background()))
+	dirB := filepath.Join(t.TempDir(), "b")
+	require.NoError(t, Clone(context.Background(), cfg.RemoteURL, dirB, cfg))
+	b, err := New(dirB, cfg)
+	require.NoError(t, err)
+
+	// A pushes a file where B's commit needs a directory
+	commitFile(t, a, dirA, "team", "not a directory\n", now.Add(time.Minute))
+	require.NoError(t, a.Push(context.Background()))
+	require.NoError(t, os.MkdirAll(filepath.Join(dirB, "team"), 0755))
+	local := commitFile(t, b, dirB, "team/app.yaml", "v: b\n", now.Add(2*time.Minute))
+
+	require.Error(t, b.Push(context.Background()))
+
+	head, err := b.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, local, head.Hash().String(), "the branch is back on the unpushed commit")
+	data, err := os.ReadFile(filepath.Join(dirB, "team", "app.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "v: b\n", string(data))
+	n, err := b.Unpushed()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPush_NoRemote(t *testing.T) {
+	v, _ := newTestVersioner(t)
+	err := v.Push(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no remote origin")
+}
+
+func TestValidateAuth(t *testing.T) {
+	assert.NoError(t, ValidateAuth(&config.GitAuthConfig{}))
+	assert.NoError(t, ValidateAuth(&config.GitAuthConfig{Token: "t"}))
+	assert.Error(t, ValidateAuth(&config.GitAuthConfig{Token: "t", SSHKey: "/key"}))
+	assert.Error(t, ValidateAuth(&config.GitAuthConfig{Password: "p"}))
+	assert.Error(t, ValidateAuth(&config.GitAuthConfig{SSHKeyPassphrase: "p"}))
+}
@@ -2,8 +2,14 @@
 package versioner
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
@@ -13,7 +19,9 @@ import (
 	"github.com/go-git/go-git/v5"
 	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"gopkg.in/yaml.v3"
 )
 
 // Versioner manages Git versioning of infrastructure snapshots.
@@ -21,13 +29,30 @@ type Versioner struct {
 	repoPath string
 	config   *config.GitConfig
 	repo     *git.Repository
+	logger   log.FieldLogger
+}
+
+// Options tunes a Versioner beyond its Git configuration.
+type Options struct {
+	// Logger receives the versioner's log output. Nil uses the standard
+	// logrus logger.
+	Logger log.FieldLogger
 }
 
 // New creates a new Versioner for the given repository path.
 func New(repoPath string, cfg *config.GitConfig) (*Versioner, error) {
+	return NewWithOptions(repoPath, cfg, Options{})
+}
+
+// NewWithOptions creates a new Versioner with explicit options.
+func NewWithOptions(repoPath string, cfg *config.GitConfig, opts Options) (*Versioner, error) {
+	if opts.Logger == nil {
+		opts.Logger = log.StandardLogger()
+	}
 	v := &Versioner{
 		repoPath: repoPath,
 		config:   cfg,
+		logger:   opts.Logger,
 	}
 
 	if err := v.initRepo(); err != nil {
@@ -52,7 +77,7 @@ func (v *Versioner) initRepo() error {
 		if err != nil {
 			return fmt.Errorf("failed to initialize git repo: %w", err)
 		}
-		log.WithField("path", v.repoPath).Info("initialized new git repository")
+		v.logger.WithField("path", v.repoPath).Info("initialized new git repository")
 
 		// Create the configured branch
 		if v.config.Branch != "master" {
@@ -61,23 +86,105 @@ func (v *Versioner) initRepo() error {
 				plumbing.NewBranchReferenceName(v.config.Branch),
 			)
 			if err := repo.Storer.SetReference(headRef); err != nil {
-				log.WithError(err).Warn("failed to set default branch name")
+				v.logger.WithError(err).Warn("failed to set default branch name")
 			}
 		}
 	}
 
 	v.repo = repo
+	return v.checkRemote()
+}
+
+// checkRemote makes sure the repository's remote is git.remote_url, if set,
+// adding it to a repository without one. A remote pointing elsewhere means
+// the output directory belongs to another environment, so it is refused.
+func (v *Versioner) checkRemote() error {
+	if v.config.RemoteURL == "" {
+		return nil
+	}
+	name := v.config.Links.Remote
+	if name == "" {
+		name = "origin"
+	}
+	current, err := v.RemoteURL(name)
+	if err != nil {
+		return err
+	}
+	if current == "" {
+		if _, err := v.repo.CreateRemote(&gitconfig.RemoteConfig{Name: name, URLs: []string{v.config.RemoteURL}}); err != nil {
+			return fmt.Errorf("failed to add remote %s: %w", name, err)
+		}
+		v.logger.WithFields(log.Fields{"remote": name, "url": v.config.RemoteURL}).Info("added remote to snapshot repository")
+		return nil
+	}
+	if normalizeRemote(current) != normalizeRemote(v.config.RemoteURL) {
+		return fmt.Errorf("snapshot repository %s has remote %s at %s, not %s; refusing to use another environment's repository",
+			v.repoPath, name, current, v.config.RemoteURL)
+	}
 	return nil
 }
 
+// normalizeRemote drops the differences that do not change which
+// repository a URL names: a trailing slash or .git suffix.
+func normalizeRemote(url string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+}
+
+// lockFile is created in .git while the repository is being changed, so
+// that two runs (e.g. watch and a manual snapshot) don't commit at once.
+const lockFile = "gitops-time-machine.lock"
+
+// lock takes the repository lock, returning ErrRepoLocked if another
+// process holds it or git's own index.lock exists. A lock left by a
+// process that was killed has to be removed by hand, as with git.
+func (v *Versioner) lock() (func(), error) {
+	gitDir := filepath.Join(v.repoPath, ".git")
+	if indexLock := filepath.Join(gitDir, "index.lock"); fileExists(indexLock) {
+		return nil, fmt.Errorf("%w: %s exists; is a git command running?", types.ErrRepoLocked, indexLock)
+	}
+
+	path := filepath.Join(gitDir, lockFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return nil, fmt.Errorf("%w: %s exists; remove it if no other gitops-time-machine process is running", types.ErrRepoLocked, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	f.Close()
+	return func() {
+		if err := os.Remove(path); err != nil {
+			v.logger.WithError(err).Warn("failed to remove repository lock")
+		}
+	}, nil
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 // Commit stages all changes and creates a commit with snapshot metadata.
 func (v *Versioner) Commit(metadata *types.SnapshotMetadata) (string, error) {
+	unlock, err := v.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+	return v.commit(metadata)
+}
+
+// commit is Commit with the repository lock held.
+func (v *Versioner) commit(metadata *types.SnapshotMetadata) (string, error) {
 	w, err := v.repo.Worktree()
 	if err != nil {
 		return "", fmt.Errorf("failed to get worktree: %w", err)
 	}
 
 	// Stage all changes
+	stageStart := time.Now()
 	if err := w.AddWithOptions(&git.AddOptions{All: true}); err != nil {
 		return "", fmt.Errorf("failed to stage changes: %w", err)
 	}
@@ -87,22 +194,18 @@ func (v *Versioner) Commit(metadata *types.SnapshotMetadata) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get status: %w", err)
 	}
+	if metadata.Timings != nil {
+		metadata.Timings.Staging = time.Since(stageStart)
+	}
 
 	if status.IsClean() {
-		log.Info("no changes detected, skipping commit")
+		v.logger.Info("no changes detected, skipping commit")
 		return "", nil
 	}
 
-	// Build commit message
-	message := fmt.Sprintf("%s %s — %d resources across %d namespaces",
-		v.config.CommitMessagePrefix,
-		metadata.Timestamp.Format(time.RFC3339),
-		metadata.ResourceCount,
-		len(metadata.Namespaces),
-	)
-
 	// Create commit
-	commit, err := w.Commit(message, &git.CommitOptions{
+	commitStart := time.Now()
+	commit, err := w.Commit(v.commitMessage(metadata), &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  v.config.AuthorName,
 			Email: v.config.AuthorEmail,
@@ -112,6 +215,9 @@ func (v *Versioner) Commit(metadata *types.SnapshotMetadata) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to create commit: %w", err)
 	}
+	if metadata.Timings != nil {
+		metadata.Timings.Commit = time.Since(commitStart)
+	}
 
 	commitObj, err := v.repo.CommitObject(commit)
 	if err != nil {
@@ -119,7 +225,7 @@ func (v *Versioner) Commit(metadata *types.SnapshotMetadata) (string, error) {
 	}
 
 	hash := commitObj.Hash.String()
-	log.WithFields(log.Fields{
+	v.logger.WithFields(log.Fields{
 		"commit":    hash[:8],
 		"resources": metadata.ResourceCount,
 	}).Info("snapshot committed")
@@ -127,13 +233,195 @@ func (v *Versioner) Commit(metadata *types.SnapshotMetadata) (string, error) {
 	return hash, nil
 }
 
+// commitMessage describes a snapshot, e.g. "[snapshot] 2024-01-01T00:00:00Z
+// — 12 resources across 3 namespaces".
+func (v *Versioner) commitMessage(metadata *types.SnapshotMetadata) string {
+	message := fmt.Sprintf("%s %s — %d resources across %d namespaces",
+		v.config.CommitMessagePrefix,
+		metadata.Timestamp.Format(time.RFC3339),
+		metadata.ResourceCount,
+		len(metadata.Namespaces),
+	)
+	if metadata.Scope != nil {
+		message += " (partial: " + metadata.Scope.String() + ")"
+	}
+	if metadata.CI != nil {
+		message += "\n\n" + ciTrailers(metadata.CI)
+	}
+	return message
+}
+
+// CommitToBranch commits the working tree on top of branch instead of the
+// configured branch, creating branch from HEAD if needed. Afterwards HEAD and
+// the working tree are restored to the configured branch, or to the commit a
+// detached HEAD was on, so the main history is left untouched.
+func (v *Versioner) CommitToBranch(branch string, metadata *types.SnapshotMetadata) (string, error) {
+	unlock, err := v.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	head, err := v.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if _, err := v.repo.Reference(branchRef, false); err == plumbing.ErrReferenceNotFound {
+		if err := v.repo.Storer.SetReference(plumbing.NewHashReference(branchRef, head.Hash())); err != nil {
+			return "", fmt.Errorf("failed to create branch %s: %w", branch, err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+	}
+
+	// Point HEAD at the branch without touching the working tree, so the
+	// commit records the new snapshot on top of the branch's tip.
+	if err := v.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+		return "", fmt.Errorf("failed to switch to branch %s: %w", branch, err)
+	}
+
+	hash, commitErr := v.commit(metadata)
+
+	// A detached HEAD resolves to a reference named HEAD; pointing HEAD at
+	// itself would leave the repository without a resolvable HEAD
+	restore := plumbing.NewSymbolicReference(plumbing.HEAD, head.Name())
+	if head.Name() == plumbing.HEAD {
+		restore = plumbing.NewHashReference(plumbing.HEAD, head.Hash())
+	}
+	if err := v.repo.Storer.SetReference(restore); err != nil {
+		return "", fmt.Errorf("failed to switch back to %s: %w", head.Name().Short(), err)
+	}
+	w, err := v.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); err != nil {
+		return "", fmt.Errorf("failed to restore worktree: %w", err)
+	}
+
+	if commitErr != nil {
+		return "", commitErr
+	}
+	return hash, nil
+}
+
+// MirrorDir commits the top-level directory dir of commit commitHash, and
+// nothing else, on top of branch, with the commit's author and a message
+// describing dir's own snapshot metadata.
+// A missing branch is created without history, so it holds no trace of
+// the other directories; a commit without dir empties the branch. It
+// returns "" if branch already holds the same content or if dir was never
+// mirrored. HEAD and the working tree are not touched.
+func (v *Versioner) MirrorDir(commitHash, dir, branch string) (string, error) {
+	unlock, err := v.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	if err := branchRef.Validate(); err != nil {
+		return "", fmt.Errorf("invalid branch name %q: %w", branch, err)
+	}
+	c, err := v.commitObject(commitHash)
+	if err != nil {
+		return "", err
+	}
+	root, err := c.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", commitHash, err)
+	}
+
+	metadata, err := readMetadata(c, dir)
+	if err != nil {
+		metadata = &types.SnapshotMetadata{Timestamp: c.Author.When}
+	}
+	tree := &object.Tree{}
+	if sub, err := root.Tree(dir); err == nil {
+		tree.Entries = []object.TreeEntry{{Name: dir, Mode: filemode.Dir, Hash: sub.Hash}}
+	} else if !errors.Is(err, object.ErrDirectoryNotFound) {
+		return "", fmt.Errorf("failed to read %s at %s: %w", dir, commitHash, err)
+	}
+	treeHash, err := v.storeObject(tree)
+	if err != nil {
+		return "", err
+	}
+
+	var parents []plumbing.Hash
+	tip, err := v.repo.Reference(branchRef, true)
+	switch {
+	case err == nil:
+		parent, err := v.repo.CommitObject(tip.Hash())
+		if err != nil {
+			return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+		}
+		if parent.TreeHash == treeHash {
+			return "", nil
+		}
+		parents = []plumbing.Hash{parent.Hash}
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		if len(tree.Entries) == 0 {
+			return "", nil
+		}
+	default:
+		return "", fmt.Errorf("failed to read branch %s: %w", branch, err)
+	}
+
+	hash, err := v.storeObject(&object.Commit{
+		Author:       c.Author,
+		Committer:    c.Committer,
+		Message:      v.commitMessage(metadata),
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := v.repo.Storer.SetReference(plumbing.NewHashReference(branchRef, hash)); err != nil {
+		return "", fmt.Errorf("failed to update branch %s: %w", branch, err)
+	}
+	return hash.String(), nil
+}
+
+// storeObject encodes a tree or commit into the repository.
+func (v *Versioner) storeObject(o interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	obj := v.repo.Storer.NewEncodedObject()
+	if err := o.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode object: %w", err)
+	}
+	hash, err := v.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store object: %w", err)
+	}
+	return hash, nil
+}
+
 // History returns the commit log as a list of HistoryEntry.
 func (v *Versioner) History(limit int) ([]types.HistoryEntry, error) {
-	iter, err := v.repo.Log(&git.LogOptions{
+	return v.HistoryIn("", limit)
+}
+
+// HistoryIn returns the commits that touched the given directory of the
+// snapshot repo, reading snapshot metadata from <dir>/_metadata.yaml.
+// An empty dir means the whole repository.
+func (v *Versioner) HistoryIn(dir string, limit int) ([]types.HistoryEntry, error) {
+	opts := &git.LogOptions{
 		Order: git.LogOrderCommitterTime,
-	})
+	}
+	if dir != "" {
+		prefix := strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+		opts.PathFilter = func(p string) bool {
+			return strings.HasPrefix(p, prefix)
+		}
+	}
+
+	iter, err := v.log(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get log: %w", err)
+		return nil, logError(err)
 	}
 
 	var entries []types.HistoryEntry
@@ -144,12 +432,7 @@ func (v *Versioner) History(limit int) ([]types.HistoryEntry, error) {
 			return fmt.Errorf("limit reached")
 		}
 
-		entries = append(entries, types.HistoryEntry{
-			CommitHash: c.Hash.String(),
-			Timestamp:  c.Author.When,
-			Message:    c.Message,
-			Author:     c.Author.Name,
-		})
+		entries = append(entries, v.historyEntry(c, dir))
 		count++
 		return nil
 	})
@@ -162,6 +445,401 @@ func (v *Versioner) History(limit int) ([]types.HistoryEntry, error) {
 	return entries, nil
 }
 
+// log walks commits like Repository.Log, except that in a shallow clone
+// (git.clone.depth) the walk ends at the oldest fetched commits instead of
+// failing on their missing parents.
+func (v *Versioner) log(opts *git.LogOptions) (object.CommitIter, error) {
+	shallow, err := v.repo.Storer.Shallow()
+	if err != nil || len(shallow) == 0 {
+		return v.repo.Log(opts)
+	}
+
+	from := opts.From
+	if from == plumbing.ZeroHash {
+		head, err := v.repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		from = head.Hash()
+	}
+	c, err := v.repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	var missing []plumbing.Hash
+	for _, h := range shallow {
+		if b, err := v.repo.CommitObject(h); err == nil {
+			missing = append(missing, b.ParentHashes...)
+		}
+	}
+
+	var iter object.CommitIter
+	if opts.Order == git.LogOrderCommitterTime {
+		iter = object.NewCommitIterCTime(c, nil, missing)
+	} else {
+		iter = object.NewCommitPreorderIter(c, nil, missing)
+	}
+	if opts.PathFilter != nil {
+		iter = object.NewCommitPathIterFromIter(opts.PathFilter, iter, false)
+	}
+	return iter, nil
+}
+
+// FileVersion is the content of a file at a commit that changed it.
+type FileVersion struct {
+	CommitHash string
+	Timestamp  time.Time
+	Message    string
+	// Path is where the file was found, or "" if the commit deleted it.
+	Path    string
+	Content []byte
+}
+
+// FileVersions returns, newest first, every commit that changed any of the
+// given repo-relative paths, with the file's content at that commit. The
+// paths are alternative names for one file (e.g. plain and compressed), so
+// at most one is expected to exist per commit. The walk stops once ctx is
+// cancelled.
+func (v *Versioner) FileVersions(ctx context.Context, paths []string) ([]FileVersion, error) {
+	wanted := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wanted[filepath.ToSlash(p)] = true
+	}
+
+	iter, err := v.log(&git.LogOptions{
+		Order:      git.LogOrderCommitterTime,
+		PathFilter: func(p string) bool { return wanted[p] },
+	})
+	if err != nil {
+		return nil, logError(err)
+	}
+
+	var versions []FileVersion
+	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		version := FileVersion{
+			CommitHash: c.Hash.String(),
+			Timestamp:  c.Author.When,
+			Message:    c.Message,
+		}
+		for _, p := range paths {
+			file, err := c.File(filepath.ToSlash(p))
+			if err == object.ErrFileNotFound {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read %s at %s: %w", p, c.Hash.String()[:8], err)
+			}
+			contents, err := file.Contents()
+			if err != nil {
+				return fmt.Errorf("failed to read %s at %s: %w", p, c.Hash.String()[:8], err)
+			}
+			version.Path = filepath.ToSlash(p)
+			version.Content = []byte(contents)
+			break
+		}
+		versions = append(versions, version)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// ReadFileAt returns the content of a repo-relative file at a commit.
+func (v *Versioner) ReadFileAt(commitHash, filePath string) ([]byte, error) {
+	c, err := v.commitObject(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	file, err := c.File(filepath.ToSlash(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, commitHash[:8], err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", filePath, commitHash[:8], err)
+	}
+	return []byte(contents), nil
+}
+
+// TreeFile is a file in a commit's tree.
+type TreeFile struct {
+	Path string
+	// Hash is the git blob hash; files with equal content share it.
+	Hash string
+}
+
+// TreeFiles lists the files under dir (or the whole tree if dir is empty)
+// at the given commit.
+func (v *Versioner) TreeFiles(commitHash, dir string) ([]TreeFile, error) {
+	c, err := v.commitObject(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	prefix := ""
+	if dir != "" {
+		prefix = strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+	}
+
+	var files []TreeFile
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if strings.HasPrefix(f.Name, prefix) {
+			files = append(files, TreeFile{Path: f.Name, Hash: f.Hash.String()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", commitHash[:8], err)
+	}
+	return files, nil
+}
+
+// ReadBlob returns the content of a git blob by hash.
+func (v *Versioner) ReadBlob(hash string) ([]byte, error) {
+	blob, err := v.repo.BlobObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", hash, err)
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+	return data, nil
+}
+
+// ciTrailers describes the CI run that took a snapshot as git trailers,
+// so that git log links the snapshot to the pipeline and commit it ran for.
+func ciTrailers(ci *types.CIMetadata) string {
+	var b strings.Builder
+	for _, t := range []struct{ key, value string }{
+		{"CI-Provider", ci.Provider},
+		{"CI-Pipeline", ci.PipelineID},
+		{"CI-Pipeline-URL", ci.PipelineURL},
+		{"CI-Repository", ci.Repository},
+		{"CI-Commit", ci.CommitSHA},
+		{"CI-Ref", ci.Ref},
+		{"CI-Actor", ci.Actor},
+	} {
+		if t.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", t.key, t.value)
+		}
+	}
+	return b.String()
+}
+
+// historyEntry builds a HistoryEntry from a commit and the metadata under dir.
+func (v *Versioner) historyEntry(c *object.Commit, dir string) types.HistoryEntry {
+	entry := types.HistoryEntry{
+		CommitHash: c.Hash.String(),
+		Timestamp:  c.Author.When,
+		Message:    c.Message,
+		Author:     c.Author.Name,
+	}
+	if metadata, err := readMetadata(c, dir); err == nil {
+		entry.ResourceCount = metadata.ResourceCount
+		entry.ClusterName = metadata.ClusterName
+		entry.Context = metadata.Context
+		entry.Namespaces = metadata.Namespaces
+		entry.KindCounts = metadata.KindCounts
+		entry.NamespaceCounts = metadata.NamespaceCounts
+		entry.ContentHash = metadata.ContentHash
+		entry.RBACExposure = metadata.RBACExposure
+		entry.CI = metadata.CI
+		entry.Collection = metadata.Collection
+		entry.Scope = metadata.Scope
+	} else {
+		v.logger.WithError(err).WithField("commit", c.Hash.String()[:8]).Debug("no snapshot metadata in commit")
+	}
+	return entry
+}
+
+// Pending returns the commits on branch that have not been merged into the
+// configured branch, newest first. A missing branch has no pending commits.
+func (v *Versioner) Pending(branch string) ([]types.HistoryEntry, error) {
+	tip, err := v.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch %s: %w", branch, err)
+	}
+
+	merged := make(map[plumbing.Hash]bool)
+	if head, err := v.repo.Head(); err == nil {
+		iter, err := v.log(&git.LogOptions{From: head.Hash()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get log: %w", err)
+		}
+		if err := iter.ForEach(func(c *object.Commit) error {
+			merged[c.Hash] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	iter, err := v.log(&git.LogOptions{From: tip.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+
+	var entries []types.HistoryEntry
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !merged[c.Hash] {
+			entries = append(entries, v.historyEntry(c, ""))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Merge records the snapshot at ref as the latest state of the configured
+// branch. Snapshots are complete states, so the merge commit takes ref's
+// tree as-is; ref becomes its second parent so it is no longer pending.
+func (v *Versioner) Merge(ref, message string) (string, error) {
+	unlock, err := v.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	hash, err := v.ResolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+	source, err := v.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit object: %w", err)
+	}
+	head, err := v.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	signature := object.Signature{
+		Name:  v.config.AuthorName,
+		Email: v.config.AuthorEmail,
+		When:  time.Now(),
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     source.TreeHash,
+		ParentHashes: []plumbing.Hash{head.Hash(), source.Hash},
+	}
+
+	obj := v.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return "", fmt.Errorf("failed to encode merge commit: %w", err)
+	}
+	mergeHash, err := v.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to store merge commit: %w", err)
+	}
+
+	if err := v.repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), mergeHash)); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", head.Name().Short(), err)
+	}
+	w, err := v.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: mergeHash, Mode: git.HardReset}); err != nil {
+		return "", fmt.Errorf("failed to update worktree: %w", err)
+	}
+
+	v.logger.WithFields(log.Fields{
+		"commit": mergeHash.String()[:8],
+		"source": hash[:8],
+	}).Info("snapshot merged")
+	return mergeHash.String(), nil
+}
+
+// ResetBranch discards the pending commits on branch by pointing it at the
+// configured branch's HEAD.
+func (v *Versioner) ResetBranch(branch string) error {
+	unlock, err := v.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	head, err := v.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())
+	if err := v.repo.Storer.SetReference(ref); err != nil {
+		return fmt.Errorf("failed to reset branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// Discard drops uncommitted changes, resetting the working tree to HEAD and
+// removing untracked files, e.g. a written snapshot that was rejected. In a
+// repository without commits it only removes untracked files.
+func (v *Versioner) Discard() error {
+	unlock, err := v.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	w, err := v.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	head, err := v.repo.Head()
+	switch {
+	case err == nil:
+		if err := w.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); err != nil {
+			return fmt.Errorf("failed to reset to HEAD: %w", err)
+		}
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if err := w.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	return nil
+}
+
+// readMetadata parses the _metadata.yaml file stored under dir in a commit's tree.
+func readMetadata(c *object.Commit, dir string) (*types.SnapshotMetadata, error) {
+	file, err := c.File(path.Join(filepath.ToSlash(dir), "_metadata.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &types.SnapshotMetadata{}
+	if err := yaml.Unmarshal([]byte(contents), metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return metadata, nil
+}
+
 // CheckoutAt checks out the snapshot repo at a given commit hash.
 func (v *Versioner) CheckoutAt(commitHash string) error {
 	w, err := v.repo.Worktree()
@@ -186,19 +864,62 @@ func (v *Versioner) CheckoutBranch() error {
 	})
 }
 
-// FindCommitByTime returns the commit hash closest to (but not after) the given time.
-func (v *Versioner) FindCommitByTime(target time.Time) (string, error) {
-	iter, err := v.repo.Log(&git.LogOptions{
+// ResolveRef resolves a commit hash or revision expression (e.g. HEAD~3) to a full commit hash.
+func (v *Versioner) ResolveRef(ref string) (string, error) {
+	hash, err := v.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w: %w", ref, types.ErrCommitNotFound, err)
+	}
+	return hash.String(), nil
+}
+
+// Entry returns the history entry of the commit a ref resolves to, with
+// the snapshot metadata under dir.
+func (v *Versioner) Entry(ref, dir string) (types.HistoryEntry, error) {
+	hash, err := v.ResolveRef(ref)
+	if err != nil {
+		return types.HistoryEntry{}, err
+	}
+	c, err := v.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return types.HistoryEntry{}, fmt.Errorf("failed to read commit %s: %w", hash[:8], err)
+	}
+	return v.historyEntry(c, dir), nil
+}
+
+// RemoteURL returns the first URL of the named remote, or "" if the
+// repository has no such remote.
+func (v *Versioner) RemoteURL(name string) (string, error) {
+	remote, err := v.repo.Remote(name)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read remote %s: %w", name, err)
+	}
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", nil
+}
+
+// FindCommitByTime returns the commit hash closest to (but not after) the
+// given time. The walk stops once ctx is cancelled.
+func (v *Versioner) FindCommitByTime(ctx context.Context, target time.Time) (string, error) {
+	iter, err := v.log(&git.LogOptions{
 		Order: git.LogOrderCommitterTime,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get log: %w", err)
+		return "", logError(err)
 	}
 
 	var bestHash string
 	var bestTime time.Time
 
 	err = iter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		commitTime := c.Author.When
 		if commitTime.Before(target) || commitTime.Equal(target) {
 			if bestHash == "" || commitTime.After(bestTime) {
@@ -213,15 +934,36 @@ func (v *Versioner) FindCommitByTime(target time.Time) (string, error) {
 	}
 
 	if bestHash == "" {
-		return "", fmt.Errorf("no snapshot found at or before %s", target.Format(time.RFC3339))
+		return "", fmt.Errorf("%w at or before %s", types.ErrNoSnapshots, target.Format(time.RFC3339))
 	}
 
 	return bestHash, nil
 }
 
+// logError wraps the error of starting a log walk. A repository without
+// commits has no HEAD, which means no snapshots have been taken yet.
+func logError(err error) error {
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return fmt.Errorf("failed to get log: %w", types.ErrNoSnapshots)
+	}
+	return fmt.Errorf("failed to get log: %w", err)
+}
+
+// commitObject reads a commit by full hash.
+func (v *Versioner) commitObject(hash string) (*object.Commit, error) {
+	c, err := v.repo.CommitObject(plumbing.NewHash(hash))
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("failed to get commit object %s: %w", hash, types.ErrCommitNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object: %w", err)
+	}
+	return c, nil
+}
+
 // GetCommitCount returns the total number of commits in the repository.
 func (v *Versioner) GetCommitCount() (int, error) {
-	iter, err := v.repo.Log(&git.LogOptions{})
+	iter, err := v.log(&git.LogOptions{})
 	if err != nil {
 		// New repo with no commits
 		if err == plumbing.ErrReferenceNotFound {
@@ -0,0 +1,384 @@
+package versioner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/config"
+	"github.com/raghu-007/GitOps-Time-Machine/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVersioner creates a Versioner over a temporary repository.
+func newTestVersioner(t *testing.T) (*Versioner, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := config.DefaultConfig().Git
+	v, err := New(dir, &cfg)
+	require.NoError(t, err)
+	return v, dir
+}
+
+// commitFile writes a file and commits it, returning the commit hash.
+func commitFile(t *testing.T, v *Versioner, dir, name, content string, when time.Time) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	hash, err := v.Commit(&types.SnapshotMetadata{Timestamp: when})
+	require.NoError(t, err)
+	require.NotEmpty(t, hash)
+	return hash
+}
+
+func TestResolveRef(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := commitFile(t, v, dir, "a.yaml", "a: 1", base)
+	second := commitFile(t, v, dir, "a.yaml", "a: 2", base.Add(time.Hour))
+
+	_, err := v.repo.CreateTag("v1", plumbing.NewHash(first), nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		ref      string
+		expected string
+	}{
+		{first, first},
+		{first[:7], first},
+		{"HEAD", second},
+		{"HEAD~1", first},
+		{"main", second},
+		{"v1", first},
+	}
+
+	for _, tc := range tests {
+		hash, err := v.ResolveRef(tc.ref)
+		require.NoError(t, err, "ResolveRef(%q)", tc.ref)
+		assert.Equal(t, tc.expected, hash, "ResolveRef(%q)", tc.ref)
+	}
+}
+
+func TestResolveRef_Unknown(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	commitFile(t, v, dir, "a.yaml", "a: 1", time.Now().UTC())
+
+	_, err := v.ResolveRef("does-not-exist")
+	assert.ErrorIs(t, err, types.ErrCommitNotFound)
+	_, err = v.TreeFiles("0123456789abcdef0123456789abcdef01234567", "")
+	assert.ErrorIs(t, err, types.ErrCommitNotFound)
+}
+
+func TestHistory_EmptyRepo(t *testing.T) {
+	v, _ := newTestVersioner(t)
+	_, err := v.History(0)
+	assert.ErrorIs(t, err, types.ErrNoSnapshots)
+	_, err = v.FindCommitByTime(context.Background(), time.Now())
+	assert.ErrorIs(t, err, types.ErrNoSnapshots)
+}
+
+func TestCommit_Locked(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	commitFile(t, v, dir, "a.yaml", "a: 1", time.Now().UTC())
+	require.NoFileExists(t, filepath.Join(dir, ".git", lockFile), "released after committing")
+
+	for _, name := range []string{lockFile, "index.lock"} {
+		lock := filepath.Join(dir, ".git", name)
+		require.NoError(t, os.WriteFile(lock, nil, 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 2"), 0644))
+		_, err := v.Commit(&types.SnapshotMetadata{Timestamp: time.Now().UTC()})
+		assert.ErrorIs(t, err, types.ErrRepoLocked, name)
+		require.NoError(t, os.Remove(lock))
+	}
+}
+
+func TestHistory_ReadsMetadata(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	metadata := "clusterName: prod\nresourceCount: 42\nnamespaces:\n  - default\n"
+
+	hash := commitFile(t, v, dir, "_metadata.yaml", metadata, time.Now().UTC())
+
+	entries, err := v.History(0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, hash, entries[0].CommitHash)
+	assert.Equal(t, 42, entries[0].ResourceCount)
+	assert.Equal(t, "prod", entries[0].ClusterName)
+	assert.Equal(t, []string{"default"}, entries[0].Namespaces)
+}
+
+func TestEntry(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	first := commitFile(t, v, dir, "_metadata.yaml", "clusterName: prod\nresourceCount: 1\n", time.Now().UTC())
+	commitFile(t, v, dir, "_metadata.yaml", "clusterName: prod\nresourceCount: 2\n", time.Now().UTC())
+
+	entry, err := v.Entry("HEAD~1", "")
+	require.NoError(t, err)
+	assert.Equal(t, first, entry.CommitHash)
+	assert.Equal(t, 1, entry.ResourceCount)
+
+	_, err = v.Entry("does-not-exist", "")
+	assert.Error(t, err)
+}
+
+func TestCommit_RecordsPhaseTimings(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 1"), 0644))
+
+	metadata := &types.SnapshotMetadata{
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Timings:   &types.PhaseTimings{Collection: time.Second},
+	}
+	_, err := v.Commit(metadata)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Second, metadata.Timings.Collection)
+	assert.Positive(t, metadata.Timings.Staging)
+	assert.Positive(t, metadata.Timings.Commit)
+	assert.Greater(t, metadata.Timings.Total(), time.Second)
+}
+
+func TestCommit_CITrailers(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 1"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_metadata.yaml"), []byte("ci:\n  provider: gitlab\n  commitSHA: 4f2a9c1e\n"), 0644))
+
+	_, err := v.Commit(&types.SnapshotMetadata{
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		CI:        &types.CIMetadata{Provider: "gitlab", PipelineID: "42", CommitSHA: "4f2a9c1e"},
+	})
+	require.NoError(t, err)
+
+	entry, err := v.Entry("HEAD", "")
+	require.NoError(t, err)
+	assert.Contains(t, entry.Message, "\n\nCI-Provider: gitlab\nCI-Pipeline: 42\nCI-Commit: 4f2a9c1e\n")
+	require.NotNil(t, entry.CI)
+	assert.Equal(t, "4f2a9c1e", entry.CI.CommitSHA)
+}
+
+func TestCommitToBranch_LeavesMainUntouched(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mainHash := commitFile(t, v, dir, "a.yaml", "a: 1", base)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("b: 1"), 0644))
+	quarantined, err := v.CommitToBranch("quarantine", &types.SnapshotMetadata{Timestamp: base.Add(time.Hour)})
+	require.NoError(t, err)
+	require.NotEmpty(t, quarantined)
+
+	head, err := v.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, mainHash, head)
+
+	branchTip, err := v.ResolveRef("quarantine")
+	require.NoError(t, err)
+	assert.Equal(t, quarantined, branchTip)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "b.yaml"))
+
+	// The next main commit is unaffected by the quarantined one
+	next := commitFile(t, v, dir, "a.yaml", "a: 3", base.Add(2*time.Hour))
+	parent, err := v.ResolveRef(next + "~1")
+	require.NoError(t, err)
+	assert.Equal(t, mainHash, parent)
+}
+
+func TestMirrorDir(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team-a"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team-b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-b", "b.yaml"), []byte("b: 1"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a", "_metadata.yaml"),
+		[]byte("timestamp: 2024-01-01T00:00:00Z\nresourceCount: 1\nnamespaces: [shop]\n"), 0644))
+	first := commitFile(t, v, dir, "team-a/a.yaml", "a: 1", base)
+
+	mirrored, err := v.MirrorDir(first, "team-a", "tenants/team-a")
+	require.NoError(t, err)
+	require.NotEmpty(t, mirrored)
+	files, err := v.TreeFiles(mirrored, "")
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "team-a/_metadata.yaml", files[0].Path)
+	assert.Equal(t, "team-a/a.yaml", files[1].Path)
+	c, err := v.commitObject(mirrored)
+	require.NoError(t, err)
+	assert.Empty(t, c.ParentHashes, "the branch starts without the main history")
+	assert.Contains(t, c.Message, "1 resources across 1 namespaces", "the message describes the team's slice")
+
+	// Only changes to the directory make a new commit
+	second := commitFile(t, v, dir, "team-b/b.yaml", "b: 2", base.Add(time.Hour))
+	unchanged, err := v.MirrorDir(second, "team-a", "tenants/team-a")
+	require.NoError(t, err)
+	assert.Empty(t, unchanged)
+
+	third := commitFile(t, v, dir, "team-a/a.yaml", "a: 2", base.Add(2*time.Hour))
+	next, err := v.MirrorDir(third, "team-a", "tenants/team-a")
+	require.NoError(t, err)
+	parent, err := v.ResolveRef(next + "~1")
+	require.NoError(t, err)
+	assert.Equal(t, mirrored, parent)
+	entries, err := v.HistoryIn("team-a", 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "main history is unchanged")
+
+	head, err := v.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, third, head)
+
+	// A directory that was never mirrored does not create a branch
+	none, err := v.MirrorDir(third, "team-c", "tenants/team-c")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+	_, err = v.ResolveRef("tenants/team-c")
+	assert.Error(t, err)
+}
+
+func TestPendingAndMerge(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commitFile(t, v, dir, "a.yaml", "a: 1", base)
+
+	pending, err := v.Pending("quarantine")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 2"), 0644))
+	quarantined, err := v.CommitToBranch("quarantine", &types.SnapshotMetadata{Timestamp: base.Add(time.Hour)})
+	require.NoError(t, err)
+
+	pending, err = v.Pending("quarantine")
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, quarantined, pending[0].CommitHash)
+
+	merged, err := v.Merge(quarantined, "accept")
+	require.NoError(t, err)
+
+	head, err := v.ResolveRef("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, merged, head)
+
+	content, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "a: 2", string(content))
+
+	pending, err = v.Pending("quarantine")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestCommitToBranch_DetachedHead(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := commitFile(t, v, dir, "a.yaml", "a: 1", base)
+	commitFile(t, v, dir, "a.yaml", "a: 2", base.Add(time.Hour))
+	require.NoError(t, v.CheckoutAt(first))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 3"), 0644))
+	quarantined, err := v.CommitToBranch("quarantine", &types.SnapshotMetadata{Timestamp: base.Add(2 * time.Hour)})
+	require.NoError(t, err)
+
+	head, err := v.repo.Reference(plumbing.HEAD, false)
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.HashReference, head.Type(), "HEAD stays detached")
+	assert.Equal(t, first, head.Hash().String())
+	parent, err := v.ResolveRef(quarantined + "~1")
+	require.NoError(t, err)
+	assert.Equal(t, first, parent)
+}
+
+func TestResetBranch_DiscardsPending(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commitFile(t, v, dir, "a.yaml", "a: 1", base)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 2"), 0644))
+	_, err := v.CommitToBranch("quarantine", &types.SnapshotMetadata{Timestamp: base.Add(time.Hour)})
+	require.NoError(t, err)
+
+	require.NoError(t, v.ResetBranch("quarantine"))
+
+	pending, err := v.Pending("quarantine")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestDiscard(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	commitFile(t, v, dir, "a.yaml", "a: 1", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 2"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "default", "configmap"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "default", "configmap", "b.yaml"), []byte("b: 1"), 0644))
+
+	require.NoError(t, v.Discard())
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "a: 1", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "default", "configmap", "b.yaml"))
+	assert.DirExists(t, filepath.Join(dir, ".git"))
+}
+
+func TestDiscard_NoCommits(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 1"), 0644))
+
+	require.NoError(t, v.Discard())
+
+	assert.NoFileExists(t, filepath.Join(dir, "a.yaml"))
+}
+
+func TestNew_ChecksRemote(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig().Git
+	cfg.RemoteURL = "git@github.com:acme/staging-snapshots.git"
+
+	// A new repository gets the remote
+	v, err := New(dir, &cfg)
+	require.NoError(t, err)
+	url, err := v.RemoteURL("origin")
+	require.NoError(t, err)
+	assert.Equal(t, cfg.RemoteURL, url)
+
+	_, err = New(dir, &cfg)
+	require.NoError(t, err)
+
+	cfg.RemoteURL = "git@github.com:acme/staging-snapshots"
+	_, err = New(dir, &cfg)
+	require.NoError(t, err, "a .git suffix names the same repository")
+
+	cfg.RemoteURL = "git@github.com:acme/prod-snapshots.git"
+	_, err = New(dir, &cfg)
+	assert.ErrorContains(t, err, "another environment's repository")
+}
+
+func TestWalks_StopWhenCancelled(t *testing.T) {
+	v, dir := newTestVersioner(t)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := commitFile(t, v, dir, "a.yaml", "a: 1", base)
+	commitFile(t, v, dir, "a.yaml", "a: 2", base.Add(time.Hour))
+
+	hash, err := v.FindCommitByTime(context.Background(), base.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, first, hash)
+	versions, err := v.FileVersions(context.Background(), []string{"a.yaml"})
+	require.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = v.FindCommitByTime(ctx, base.Add(time.Minute))
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = v.FileVersions(ctx, []string{"a.yaml"})
+	assert.ErrorIs(t, err, context.Canceled)
+}